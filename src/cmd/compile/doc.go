@@ -36,12 +36,27 @@ Flags:
 		Show complete file path in error messages.
 	-N
 		Disable optimizations.
+	-O0, -O1, -O2, -Os
+		Set the optimization level. -O0 disables optimizations and
+		inlining, like -N -l. -O1 optimizes, but only inlines functions
+		little bigger than a call. -O2, the default, optimizes and
+		inlines fully. -Os optimizes for code size rather than speed.
+		Neither -O1 nor -Os makes extra copies of functions or loops:
+		there are no specialized copies for constant arguments, no
+		//go:cpu variants, no instantiations of their own for hot calls
+		with -pgoprofile, and no fused loops with -d=loopfusion.
+		At most one level may be given, and -O1 and -O2 may not be
+		combined with -N or -l.
 	-S
 		Print assembly listing to standard output (code only).
 	-S -S
 		Print assembly listing to standard output (code and data).
 	-V
 		Print compiler version and exit.
+	-apisummary file
+		Write a summary of the exported API of the package to file,
+		one declaration, field, or method per line, in a format like
+		that of the api/*.txt files checked by cmd/api.
 	-asmhdr file
 		Write assembly header to file.
 	-asan
@@ -56,6 +71,10 @@ Flags:
 		Assume package has no non-Go components.
 	-cpuprofile file
 		Write a CPU profile for the compilation to file.
+	-deprecated level
+		Report uses of declarations of other packages whose doc
+		comments have a "Deprecated:" paragraph, quoting it.
+		Level 1 reports them as warnings, level 2 as errors.
 	-dynlink
 		Allow references to Go symbols in shared libraries (experimental).
 	-e
@@ -84,6 +103,16 @@ Flags:
 		object to usual output file (as specified by -o).
 		Without this flag, the -o output is a combination of both
 		linker and compiler input.
+	-linknameallow file
+		Reject //go:linkname directives whose target is in a package
+		other than the one being compiled, unless that package is
+		listed in file. The file lists one import path per line;
+		a path ending in "/..." also allows all packages below it.
+		Blank lines and text after # are ignored.
+	-linknamelog file
+		Append a JSON object describing each //go:linkname directive
+		to file, one per line, with the fields package, pos, local,
+		target, and target_package.
 	-m
 		Print optimization decisions. Higher values or repetition
 		produce more detail.
@@ -104,6 +133,14 @@ Flags:
 		and diagnose imports that would cause a circular dependency.
 	-pack
 		Write a package (archive) file rather than an object file
+	-pgoprofile file
+		Read a CPU profile of the program from file, in pprof format,
+		and use it to lay out basic blocks, to mark hot functions,
+		which the linker places together, and to give hot calls to
+		generic functions an instantiation of their own rather than
+		one shared by types of the same shape (see -d=pgostencil).
+		The profile is matched to the source even if it has been
+		edited since; -d=pgomatch reports how much of it matched.
 	-race
 		Compile with race detector enabled.
 	-s
@@ -199,6 +236,11 @@ directive can skip over a directive like any other comment.
 //
 // Line directives typically appear in machine-generated code, so that compilers and debuggers
 // will report positions in the original input to the generator.
+// With -L, the compiler reports the position in the generated file as well, followed
+// by how it was generated if it has a "// Code generated ... DO NOT EDIT." header, as in
+// "tmpl.txt:10[gen.go:25:2, generated by gen from tmpl.txt]".
+// The -d=genlines flag makes tracebacks and debuggers use the positions in the
+// generated file instead.
 /*
 The line directive is a historical special case; all other directives are of the form
 //go:name, indicating that they are defined by the Go toolchain.
@@ -246,6 +288,106 @@ It specifies that the function must omit its usual stack overflow check.
 This is most commonly used by low-level runtime code invoked
 at times when it is unsafe for the calling goroutine to be preempted.
 
+	//go:fastminmax
+	//go:strictminmax
+
+The compiler turns floating-point minimum, maximum and clamp operations
+written as a comparison and a choice of operand, such as
+``if x < y { return x }; return y'', into branch-free code. By default it only
+does so when the generated code returns the same value as the source for
+NaNs and for zeros of different sign. The //go:fastminmax directive must be
+followed by a function declaration. It allows the function's min and max
+operations to use native instructions even when they handle NaNs and signed
+zeros differently, as when the comparison is written with <=. The -d=fastminmax
+flag does the same for every function in the package, and the
+//go:strictminmax directive exempts a function from it. Inlined code
+follows the directive of the function it is inlined into.
+
+	//go:fastmath
+
+The //go:fastmath directive must be followed by a function declaration.
+It allows the compiler to rewrite the function's floating-point arithmetic
+in ways that can change its results: additions and multiplications may be
+reassociated, division by a constant may become multiplication by its
+reciprocal, and the sign of zero results may be ignored. It also implies
+//go:fastminmax. Code inlined into the function from other functions is not
+affected. The -d=ssa/fastmath/debug=1 flag reports each rewrite.
+
+	//go:cpu level...
+
+The //go:cpu directive must be followed by the declaration of a function
+with a body that is neither a method nor generic. Each level, one of
+amd64.v2, amd64.v3 or amd64.v4, asks the compiler to compile an extra copy
+of the function as if GOAMD64 were set to that level, and to start the
+function with a check that calls the copy for the highest level the CPU
+supports. Levels at or below the package's own GOAMD64 level are ignored,
+as is the whole directive with -Os or -O1 and on other architectures.
+Functions marked //go:cpu are not inlined, and closures in them are
+compiled only once, at the package's own level.
+
+	//go:pure
+
+The //go:pure directive must be followed by a function declaration.
+It promises that the function has no effect visible to its caller other
+than its results, and the compiler reports an error if, after inlining,
+the function assigns to a package-level variable, stores to memory it did
+not allocate itself, operates on a channel, starts a goroutine, or calls a
+function that is not itself marked //go:pure.
+
+	//go:noalloc
+
+The //go:noalloc directive must be followed by a function declaration.
+The compiler reports an error if the optimized function still allocates
+heap memory, or calls a function that is not itself marked //go:noalloc.
+Both directives are recorded in export data, so the called function may
+be in another package.
+
+The -d=noallocpackage flag is a coarser check for a whole package: it makes
+it an error for any value in the package to escape to the heap, and explains
+how each one escapes, as -m=2 does.
+
+	//go:stacklocals
+	//go:noheaplocals
+
+Values that do not escape are allocated on the stack unless they are larger
+than a limit, 10 MB for variables and 64 KB for values created with new, &T{}
+or make. Larger ones are moved to the heap without notice, which the
+-d=largelocals flag reports. The //go:stacklocals directive must be followed
+by a function declaration. It keeps the function's non-escaping values on the
+stack whatever their size, letting the goroutine's stack grow to hold them.
+The //go:noheaplocals directive, also followed by a function declaration,
+makes it an error instead for a non-escaping value in the function to be
+moved to the heap because of its size. Both apply to code inlined into the
+function.
+
+	//go:soa
+
+The //go:soa directive is experimental. It must be followed by the
+declaration of a local variable whose type is a slice of structs, and asks
+the compiler to store the variable as one slice per field of the struct, so
+that loops reading only some of the fields of each element use the cache
+better. This is only possible if the variable is not captured by a closure
+and is only used one field at a time: in elements' fields such as v[i].f,
+len(v), for i := range v, and assignments of nil, make, append with
+individual elements, or a slice expression of v itself that does not
+extend past its length. The compiler reports the variables it cannot
+convert, and with -m, those it converts.
+
+	//go:cachealign
+
+The //go:cachealign directive must be followed by the declaration of a
+package-level struct type that is not generic, or of a package-level
+variable. It gives the variable, and struct fields and package-level
+variables of the type, whole cache lines of the target architecture, so
+that they share no cache line with other values, as hand-written padding
+arrays would. Structs with such fields are padded to whole cache lines, and
+start on a cache line boundary when heap-allocated where the cache line is
+at most 64 bytes; variables on the stack may not. The padding is outside
+the values of the type itself: their size and alignment, as reported by
+unsafe.Sizeof and unsafe.Alignof, are those of the underlying type, the
+elements of arrays of the type are not padded, and values convert to and
+from other types with the same underlying type as usual.
+
 	//go:linkname localname [importpath.name]
 
 This special directive does not apply to the Go code that follows it.
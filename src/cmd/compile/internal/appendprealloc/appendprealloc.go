@@ -0,0 +1,205 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package appendprealloc rewrites loops that append one element per
+// iteration of a range over a slice or array to a local slice that
+// starts out empty, like
+//
+//	var r []T
+//	for i, x := range xs {
+//		r = append(r, f(x))
+//	}
+//
+// to allocate r once, with room for all the elements, and store them
+// by index, saving the allocations and copies as r grows:
+//
+//	var r []T
+//	if len(xs) > 0 {
+//		r = make([]T, len(xs))
+//	}
+//	for i, x := range xs {
+//		r[i] = f(x)
+//	}
+package appendprealloc
+
+import (
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+)
+
+// Func rewrites appending loops within fn.
+// It must run after inlining and before escape analysis.
+func Func(fn *ir.Func) {
+	if base.Flag.N != 0 {
+		return
+	}
+
+	// Find variables captured by closures. Their values can be
+	// observed outside of the loop, so they aren't rewritten.
+	captured := make(map[*ir.Name]bool)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		if n.Op() == ir.OCLOSURE {
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				captured[cv.Canonical()] = true
+			}
+		}
+	})
+
+	ir.WithFunc(fn, func() {
+		rewriteList(&fn.Body, captured)
+		var visit func(n ir.Node) bool
+		visit = func(n ir.Node) bool {
+			switch n := n.(type) {
+			case *ir.ClosureExpr:
+				// Closures are rewritten on their own.
+				return false
+			case *ir.BlockStmt:
+				rewriteList(&n.List, captured)
+			case *ir.IfStmt:
+				rewriteList(&n.Body, captured)
+				rewriteList(&n.Else, captured)
+			case *ir.ForStmt:
+				rewriteList(&n.Body, captured)
+			case *ir.RangeStmt:
+				rewriteList(&n.Body, captured)
+			case *ir.CaseClause:
+				rewriteList(&n.Body, captured)
+			case *ir.CommClause:
+				rewriteList(&n.Body, captured)
+			case *ir.InlinedCallExpr:
+				rewriteList(&n.Body, captured)
+			}
+			return ir.DoChildren(n, visit)
+		}
+		for _, n := range fn.Body {
+			visit(n)
+		}
+	})
+}
+
+// rewriteList rewrites the appending loops in the statement list l
+// that follow the statement making their slice empty.
+func rewriteList(l *ir.Nodes, captured map[*ir.Name]bool) {
+	var out []ir.Node
+	prev := -1 // index in out of the last statement other than a declaration
+	for _, n := range *l {
+		if loop, ok := n.(*ir.RangeStmt); ok && prev >= 0 {
+			out = append(out, rewriteLoop(loop, out[prev], captured)...)
+		}
+		if n.Op() != ir.ODCL {
+			prev = len(out)
+		}
+		out = append(out, n)
+	}
+	*l = out
+}
+
+// rewriteLoop rewrites loop, if it appends one element per iteration
+// to a local slice that init makes empty. It returns the statements
+// to insert before loop, which allocate the slice.
+func rewriteLoop(loop *ir.RangeStmt, init ir.Node, captured map[*ir.Name]bool) []ir.Node {
+	t := loop.X.Type()
+	if len(loop.Body) != 1 || !t.IsSlice() && (!t.IsArray() || t.NumElem() == 0) {
+		return nil
+	}
+	as, ok := loop.Body[0].(*ir.AssignStmt)
+	if !ok || as.Op() != ir.OAS || as.Y == nil || as.Y.Op() != ir.OAPPEND {
+		return nil
+	}
+	call := as.Y.(*ir.CallExpr)
+	r, ok := as.X.(*ir.Name)
+	if !ok || r.Class != ir.PAUTO || r.Addrtaken() || captured[r] || call.IsDDD || len(call.Args) != 2 || call.Args[0] != r {
+		return nil
+	}
+	if !isEmpty(init, r) {
+		return nil
+	}
+
+	// The loop must not use r other than to append to it, or the
+	// elements not stored yet would show.
+	refs := 0
+	ir.Visit(loop, func(n ir.Node) {
+		if n == r {
+			refs++
+		}
+	})
+	if refs != 2 {
+		return nil
+	}
+
+	// The index to store at is the loop's key, which must not
+	// change other than by the loop.
+	key := loop.Key
+	if key == nil || ir.IsBlank(key) {
+		key = typecheck.Temp(types.Types[types.TINT])
+	} else if k, ok := key.(*ir.Name); !ok || k.Class != ir.PAUTO || k.Addrtaken() || captured[k] {
+		return nil
+	}
+
+	pos := loop.Pos()
+	var pre []ir.Node
+	var length ir.Node
+	if t.IsArray() {
+		length = ir.NewInt(t.NumElem())
+	} else {
+		if loop.X.Op() != ir.ONAME {
+			// Evaluate the slice once.
+			tmp := typecheck.Temp(t)
+			pre = append(pre, typecheck.Stmt(ir.NewAssignStmt(pos, tmp, loop.X)))
+			loop.X = tmp
+		}
+		length = ir.NewUnaryExpr(pos, ir.OLEN, loop.X)
+	}
+
+	if base.Flag.LowerM != 0 {
+		base.WarnfAt(pos, "append to %v in loop preallocated with make", r)
+	}
+
+	mk := ir.NewCallExpr(pos, ir.OMAKE, nil, []ir.Node{ir.TypeNode(r.Type()), length})
+	var alloc ir.Node = ir.NewAssignStmt(pos, r, mk)
+	if length.Op() != ir.OLITERAL {
+		// If r is nil, it must stay nil with no elements.
+		cond := ir.NewBinaryExpr(pos, ir.OGT, ir.NewUnaryExpr(pos, ir.OLEN, loop.X), ir.NewInt(0))
+		alloc = ir.NewIfStmt(pos, cond, []ir.Node{alloc}, nil)
+	}
+	pre = append(pre, typecheck.Stmt(alloc))
+
+	loop.Key = key
+	index := typecheck.Expr(ir.NewIndexExpr(as.Pos(), r, key)).(*ir.IndexExpr)
+	index.SetBounded(true)
+	store := ir.NewAssignStmt(as.Pos(), index, call.Args[1])
+	store.SetInit(as.Init())
+	loop.Body[0] = typecheck.Stmt(store)
+	return pre
+}
+
+// isEmpty reports whether the statement n makes the slice r empty,
+// either nil or newly made, like
+//
+//	var r []T
+//	r = nil
+//	r = []T{}
+//	r = make([]T, 0, n)
+func isEmpty(n ir.Node, r *ir.Name) bool {
+	as, ok := n.(*ir.AssignStmt)
+	if !ok || as.Op() != ir.OAS || as.X != r {
+		return false
+	}
+	if as.Y == nil {
+		return true
+	}
+	switch y := as.Y.(type) {
+	case *ir.NilExpr:
+		return true
+	case *ir.CompLitExpr:
+		return y.Op() == ir.OSLICELIT && len(y.List) == 0
+	case *ir.MakeExpr:
+		return y.Op() == ir.OMAKESLICE && ir.IsConst(y.Len, constant.Int) && ir.Int64Val(y.Len) == 0
+	}
+	return false
+}
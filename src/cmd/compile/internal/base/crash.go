@@ -0,0 +1,117 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package base
+
+import (
+	"bytes"
+	"fmt"
+	"internal/buildcfg"
+	"io/ioutil"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"cmd/internal/src"
+)
+
+// crash describes the function the compiler was working on, for the
+// crash report written on an internal compiler error.
+var crash struct {
+	sync.Mutex
+	fn   string   // name of the function
+	pos  src.XPos // position of its declaration
+	ir   string   // dump of its IR
+	pass string   // name of the pass compiling it
+}
+
+// CrashFunc, if set, returns the function the frontend is working on,
+// for crash reports of internal compiler errors that SetCrashFunc was
+// not told about.
+var CrashFunc func() (name string, pos src.XPos, ir string)
+
+// Minimize, if set, is called with -d=minimize after a crash report
+// for the function declared at pos has been written. It returns the
+// name of a file holding a smaller reproducer for the crash, or "".
+var Minimize func(pos src.XPos) string
+
+// SetCrashFunc records that the compiler is about to report an internal
+// error compiling the function name declared at pos, whose IR is dumped
+// in ir.
+func SetCrashFunc(name string, pos src.XPos, ir string) {
+	crash.Lock()
+	crash.fn, crash.pos, crash.ir = name, pos, ir
+	crash.Unlock()
+}
+
+// SetCrashPass records that the compiler is about to report an internal
+// error in the named pass.
+func SetCrashPass(pass string) {
+	crash.Lock()
+	crash.pass = pass
+	crash.Unlock()
+}
+
+// WriteCrashReport writes the internal compiler error msg at pos, the
+// function and pass in which it happened, the compiler flags and the
+// stack to a file in the temporary directory, and prints its name.
+// With -d=minimize, it also tries to reduce the function to the
+// statements needed to reproduce the error.
+func WriteCrashReport(pos src.XPos, msg string) {
+	if Debug.Minimize < 0 {
+		// This is a compile run by Minimize; the report would be
+		// the same as the one of the compile that started it.
+		return
+	}
+
+	crash.Lock()
+	fn, fnpos, ir, pass := crash.fn, crash.pos, crash.ir, crash.pass
+	crash.Unlock()
+	if fn == "" && CrashFunc != nil {
+		fn, fnpos, ir = CrashFunc()
+	}
+	if pass == "" {
+		pass = Timer.Phase()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%v: internal compiler error: %s\n\n", FmtPos(pos), msg)
+	fmt.Fprintf(&buf, "compiler: %s %s/%s", buildcfg.Version, buildcfg.GOOS, buildcfg.GOARCH)
+	if exp := buildcfg.GOEXPERIMENT(); exp != "" {
+		fmt.Fprintf(&buf, " GOEXPERIMENT=%s", exp)
+	}
+	fmt.Fprintf(&buf, "\ncommand: %s\n", strings.Join(os.Args, " "))
+	if pass != "" {
+		fmt.Fprintf(&buf, "pass: %s\n", pass)
+	}
+	if fn != "" {
+		fmt.Fprintf(&buf, "function: %s at %v\n", fn, FmtPos(fnpos))
+	}
+	fmt.Fprintf(&buf, "\n%s", debug.Stack())
+	if ir != "" {
+		fmt.Fprintf(&buf, "\nIR of %s:\n%s\n", fn, ir)
+	}
+
+	f, err := ioutil.TempFile("", "compile-crash-*.txt")
+	if err != nil {
+		fmt.Printf("writing crash report: %v\n", err)
+		return
+	}
+	_, err = f.Write(buf.Bytes())
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		fmt.Printf("writing crash report: %v\n", err)
+		return
+	}
+	fmt.Printf("crash report written to %s\n", f.Name())
+
+	if Debug.Minimize > 0 && Minimize != nil && fn != "" {
+		if name := Minimize(fnpos); name != "" {
+			fmt.Printf("reproducer with %s minimized written to %s\n", fn, name)
+		}
+	}
+}
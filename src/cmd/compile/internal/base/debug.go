@@ -17,30 +17,66 @@ var Debug DebugFlags
 // Each setting is name=value; for ints, name is short for name=1.
 type DebugFlags struct {
 	Append               int    `help:"print information about append compilation"`
+	AppendAlias          int    `help:"report appends that may overwrite elements another append to the same slice added, and copies between overlapping parts of a slice"`
+	ArgLiveness          int    `help:"print which register argument spill slots tracebacks show as valid at each call"`
+	Capture              string `help:"write the type-checked package to a file in the named directory, for -d=replay"`
+	CgoCheck             int    `help:"report cgo calls and stores into C memory that obviously violate the cgo pointer passing rules"`
+	ChanFlags            int    `help:"report channel operations compiled without nil and closed checks, on channels made locally and never closed"`
 	Checkptr             int    `help:"instrument unsafe pointer conversions\n0: instrumentation disabled\n1: conversions involving unsafe.Pointer are instrumented\n2: conversions to unsafe.Pointer force heap allocation"`
 	Closure              int    `help:"print information about closure compilation"`
 	DclStack             int    `help:"run internal dclstack check"`
+	Deadlock             int    `help:"report channel operations and mutex locks certain to block forever along straight-line code"`
+	DedupFuncs           int    `help:"emit functions with identical code as jumps to the first of them\n>1: also report them"`
 	Defer                int    `help:"print information about defer compilation"`
 	DisableNil           int    `help:"disable nil checks"`
 	DumpPtrs             int    `help:"show Node pointers values in dump output"`
 	DwarfInl             int    `help:"print information about DWARF inlined function creation"`
+	Effects              int    `help:"print function effect summaries, and calls removed because of them"`
+	EqSize               int    `help:"report == comparisons and map keys whose equality algorithm compares at least this many bytes"`
+	ErrChecks            int    `help:"report comparisons of errors with nil that are always true or always false after inlining"`
+	Exhaustive           int    `help:"report switches on enum-like types without a default case that miss some of the type's constants"`
 	Export               int    `help:"print export data"`
+	FastMinMax           int    `help:"compile float min/max to native instructions ignoring NaN and signed zero semantics\n(//go:strictminmax opts a function out)"`
 	GCProg               int    `help:"print dump of GC programs"`
+	GenLines             int    `help:"record the lines of generated files, rather than those given by their line directives, in PC-line tables"`
+	IfaceAlloc           int    `help:"report interface conversions that allocate, with the converted type and size, and how many each function has"`
 	InlFuncsWithClosures int    `help:"allow functions with closures to be inlined"`
+	InlImported          int    `help:"inlining budget for instantiations of generic functions from other packages (default 80, as for local functions)\n0: don't inline them"`
+	Intrinsics           int    `help:"report calls that are not intrinsified but would be on other architectures or with other flags"`
+	KeepAlive            int    `help:"report calls passed a uintptr derived from a pointer that may be garbage collected during the call"`
+	LargeLocals          int    `help:"report non-escaping values moved to the heap because they are too large for the stack"`
 	Libfuzzer            int    `help:"enable coverage instrumentation for libfuzzer"`
 	LocationLists        int    `help:"print information about DWARF location list creation"`
+	LoopFusion           int    `help:"fuse adjacent loops over the same index range and interchange simple loop nests (experimental)\n>1: also report fused, interchanged and rejected loops"`
+	LoopAlias            int    `help:"report loop variables referenced after their iteration ends, and appends to slices being ranged over"`
+	Minimize             int    `help:"on an internal compiler error, also write a copy of the source file that keeps only the statements of the crashing function needed to reproduce it"`
 	Nil                  int    `help:"print information about nil checks"`
+	NoAllocPackage       int    `help:"make it an error for any value in the package to escape to the heap, and explain why it does"`
 	NoOpenDefer          int    `help:"disable open-coded defers"`
 	PCTab                string `help:"print named pc-value table\nOne of: pctospadj, pctofile, pctoline, pctoinline, pctopcdata"`
+	PGOMatch             int    `help:"report how many of the -pgoprofile samples for the package matched its source, after adjusting for edits made since the profile was taken\n>1: also report how each function was matched"`
+	PGOStencil           int    `help:"report calls to generic functions that are compiled with their own instantiation, rather than one shared by types with the same shape, because -pgoprofile shows they are hot\n>1: also report hot calls whose instantiation is still shared"`
 	Panic                int    `help:"show all compiler panics"`
+	Prefetch             int    `help:"prefetch the next element in loops that follow a chain of pointers, like for p := l; p != nil; p = p.next\n>1: also report them"`
+	Printf               int    `help:"report calls to Printf-like functions whose format does not match their arguments"`
+	Replay               int    `help:"compile the package written by -d=capture to the file given as the only argument, instead of Go source files"`
 	Slice                int    `help:"print information about slice compilation"`
 	SoftFloat            int    `help:"force compiler to emit soft-float code"`
+	StaticPanic          int    `help:"report writes to nil maps and constant array indexes out of range that are certain to panic"`
+	StringSwitch         int    `help:"in string switches, dispatch each length's cases on the byte that best tells them apart when there are at least this many of them (default 4)\n0: compare whole strings only"`
 	SyncFrames           int    `help:"how many writer stack frames to include at sync points in unified export data"`
+	TypeSet              string `help:"print the type set and core type of the named constraint, given as Name or pkg.Name, or as F.T for type parameter T of generic function or type F"`
+	TypeSwitch           string `help:"lower type switch cases on concrete types with the named strategy (with -m, report the one used)\nOne of: binary (the default), linear"`
 	TypeAssert           int    `help:"print information about type assertion inlining"`
 	TypecheckInl         int    `help:"eager typechecking of inline function bodies"`
+	Unassigned           int    `help:"report reads of struct and array variables of at least this many bytes that may not have been assigned"`
 	Unified              int    `help:"enable unified IR construction"`
+	UnifiedDump          int    `help:"print the unified IR export data of the package in a readable form"`
 	UnifiedQuirks        int    `help:"enable unified IR construction's quirks mode"`
+	UnsafePtr            int    `help:"report conversions between unsafe.Pointer and uintptr that may violate the unsafe package's rules"`
 	WB                   int    `help:"print information about write barriers"`
+	WalkDump             string `help:"print the statements of the named function before and after order and walk, grouped by source line"`
+	WhyAsm               string `help:"explain the calls in the named function's code: which were inlined, intrinsified or left as calls, including runtime calls"`
 	ABIWrap              int    `help:"print information about ABI wrapper generation"`
 	MayMoreStack         string `help:"call named function before all stack growth checks"`
 
@@ -82,8 +82,14 @@ type CmdFlags struct {
 	Percent          int  "flag:\"%\" help:\"debug non-static initializers\""
 	CompilingRuntime bool "flag:\"+\" help:\"compiling runtime\""
 
+	// Optimization levels
+	O0 bool "flag:\"O0\" help:\"disable optimizations and inlining (same as -N -l)\""
+	O1 bool "flag:\"O1\" help:\"optimize, but only inline functions little bigger than a call\""
+	O2 bool "flag:\"O2\" help:\"optimize and inline fully (the default)\""
+
 	// Longer names
 	AsmHdr             string       "help:\"write assembly header to `file`\""
+	APISummary         string       "help:\"write a summary of the exported API to `file`\""
 	ASan               bool         "help:\"build code compatible with C/C++ address sanitizer\""
 	Bench              string       "help:\"append benchmark times to `file`\""
 	BlockProfile       string       "help:\"write block profile to `file`\""
@@ -92,6 +98,7 @@ type CmdFlags struct {
 	Complete           bool         "help:\"compiling complete package (no C or assembly)\""
 	ClobberDead        bool         "help:\"clobber dead stack slots (for debugging)\""
 	ClobberDeadReg     bool         "help:\"clobber dead registers (for debugging)\""
+	Deprecated         int          "help:\"report uses of deprecated declarations of other packages (1 warns, 2 fails)\""
 	Dwarf              bool         "help:\"generate DWARF symbols\""
 	DwarfBASEntries    *bool        "help:\"use base address selection entries in DWARF\""                        // &Ctxt.UseBASEntries, set below
 	DwarfLocationLists *bool        "help:\"add location lists to DWARF in optimized mode\""                      // &Ctxt.Flag_locationlists, set below
@@ -105,6 +112,8 @@ type CmdFlags struct {
 	JSON               string       "help:\"version,file for JSON compiler/optimizer detail output\""
 	Lang               string       "help:\"Go language version source code expects\""
 	LinkObj            string       "help:\"write linker-specific object to `file`\""
+	LinknameAllow      func(string) "help:\"reject //go:linkname targets in packages not listed in `file`\""
+	LinknameLog        string       "help:\"append a JSON record of each //go:linkname directive to `file`\""
 	LinkShared         *bool        "help:\"generate code that will be linked against Go shared libraries\"" // &Ctxt.Flag_linkshared, set below
 	Live               CountFlag    "help:\"debug liveness analysis\""
 	MSan               bool         "help:\"build code compatible with C/C++ memory sanitizer\""
@@ -112,7 +121,9 @@ type CmdFlags struct {
 	MemProfileRate     int          "help:\"set runtime.MemProfileRate to `rate`\""
 	MutexProfile       string       "help:\"write mutex profile to `file`\""
 	NoLocalImports     bool         "help:\"reject local (relative) imports\""
+	OptSize            bool         "flag:\"Os\" help:\"optimize for code size rather than speed\""
 	Pack               bool         "help:\"write to file.a instead of file.o\""
+	PGOProfile         string       "help:\"read profile for profile-guided optimization from `file`\""
 	Race               bool         "help:\"enable race detector\""
 	Shared             *bool        "help:\"generate code that can be linked into a shared library\"" // &Ctxt.Flag_shared, set below
 	SmallFrames        bool         "help:\"reduce the size limit for stack allocated objects\""      // small stacks, to diagnose GC latency; see golang.org/issue/27732
@@ -129,10 +140,12 @@ type CmdFlags struct {
 			Patterns map[string][]string
 			Files    map[string]string
 		}
-		ImportDirs   []string          // appended to by -I
-		ImportMap    map[string]string // set by -importmap OR -importcfg
-		PackageFile  map[string]string // set by -importcfg; nil means not in use
-		SpectreIndex bool              // set by -spectre=index or -spectre=all
+		ImportDirs    []string          // appended to by -I
+		ImportMap     map[string]string // set by -importmap OR -importcfg
+		OptLevel      int               // set by -O0, -O1 or -O2, and by -N; 2 by default
+		LinknameAllow []string          // set by -linknameallow; nil means not in use
+		PackageFile   map[string]string // set by -importcfg; nil means not in use
+		SpectreIndex  bool              // set by -spectre=index or -spectre=all
 		// Whether we are adding any sort of code instrumentation, such as
 		// when the race detector is enabled.
 		Instrumenting bool
@@ -159,10 +172,13 @@ func ParseFlags() {
 	Flag.ImportCfg = readImportCfg
 	Flag.ImportMap = addImportMap
 	Flag.LinkShared = &Ctxt.Flag_linkshared
+	Flag.LinknameAllow = readLinknameAllow
 	Flag.Shared = &Ctxt.Flag_shared
 	Flag.WB = true
 
 	Debug.InlFuncsWithClosures = 1
+	Debug.InlImported = 80 // the inliner's budget for local functions
+	Debug.StringSwitch = 4
 	if buildcfg.Experiment.Unified {
 		Debug.Unified = 1
 	}
@@ -188,11 +204,13 @@ func ParseFlags() {
 		log.Fatalf("%s/%s does not support -shared", buildcfg.GOOS, buildcfg.GOARCH)
 	}
 	parseSpectre(Flag.Spectre) // left as string for RecordFlags
+	parseOptLevel()
 
 	Ctxt.Flag_shared = Ctxt.Flag_dynlink || Ctxt.Flag_shared
 	Ctxt.Flag_optimize = Flag.N == 0
 	Ctxt.Debugasm = int(Flag.S)
 	Ctxt.Flag_maymorestack = Debug.MayMoreStack
+	Ctxt.Flag_genlines = Debug.GenLines != 0
 
 	if flag.NArg() < 1 {
 		usage()
@@ -259,10 +277,65 @@ func ParseFlags() {
 		Debug.Checkptr = 0
 	}
 
+	switch Debug.TypeSwitch {
+	case "", "binary", "linear":
+	default:
+		log.Fatalf("unknown setting -d=typeswitch=%s", Debug.TypeSwitch)
+	}
+
 	// set via a -d flag
 	Ctxt.Debugpcln = Debug.PCTab
 }
 
+// parseOptLevel sets Flag.Cfg.OptLevel from the -O flags, and sets the
+// individual flags that -O0 stands for.
+func parseOptLevel() {
+	var levels []string
+	for _, l := range []struct {
+		set  bool
+		name string
+	}{
+		{Flag.O0, "O0"},
+		{Flag.O1, "O1"},
+		{Flag.O2, "O2"},
+		{Flag.OptSize, "Os"},
+	} {
+		if l.set {
+			levels = append(levels, "-"+l.name)
+		}
+	}
+	if len(levels) > 1 {
+		log.Fatalf("cannot use more than one of %s", strings.Join(levels, ", "))
+	}
+	if (Flag.O1 || Flag.O2) && (Flag.N != 0 || Flag.LowerL != 0) {
+		log.Fatalf("cannot use %s with -N or -l", levels[0])
+	}
+
+	switch {
+	case Flag.O0:
+		if Flag.N == 0 {
+			Flag.N = 1
+		}
+		if Flag.LowerL == 0 {
+			Flag.LowerL = 1
+		}
+		Flag.Cfg.OptLevel = 0
+	case Flag.O1:
+		Flag.Cfg.OptLevel = 1
+	case Flag.N != 0:
+		Flag.Cfg.OptLevel = 0
+	default:
+		Flag.Cfg.OptLevel = 2
+	}
+}
+
+// SmallCode reports whether the compiler should keep code small: with
+// -Os, and with -O1, which inlines as little as -Os does. Passes that
+// make copies of code to speed it up are skipped then.
+func SmallCode() bool {
+	return Flag.OptSize || Flag.Cfg.OptLevel == 1
+}
+
 // registerFlags adds flag registrations for all the fields in Flag.
 // See the comment on type CmdFlags for the rules.
 func registerFlags() {
@@ -459,6 +532,22 @@ func readEmbedCfg(file string) {
 	}
 }
 
+func readLinknameAllow(file string) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatalf("-linknameallow: %v", err)
+	}
+	Flag.Cfg.LinknameAllow = []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			Flag.Cfg.LinknameAllow = append(Flag.Cfg.LinknameAllow, line)
+		}
+	}
+}
+
 // parseSpectre parses the spectre configuration from the string s.
 func parseSpectre(s string) {
 	for _, f := range strings.Split(s, ",") {
@@ -189,7 +189,8 @@ func WarnfAt(pos src.XPos, format string, args ...interface{}) {
 // But if no errors have been printed, or if -d panic has been specified,
 // Fatalf prints the error as an "internal compiler error". In a released build,
 // it prints an error asking to file a bug report. In development builds, it
-// prints a stack trace.
+// prints a stack trace. Either way, it writes a crash report with the
+// details of the error to a temporary file; see WriteCrashReport.
 //
 // If -h has been specified, Fatalf panics to force the usual runtime info dump.
 func Fatalf(format string, args ...interface{}) {
@@ -205,7 +206,8 @@ func Fatalf(format string, args ...interface{}) {
 // But if no errors have been printed, or if -d panic has been specified,
 // FatalfAt prints the error as an "internal compiler error". In a released build,
 // it prints an error asking to file a bug report. In development builds, it
-// prints a stack trace.
+// prints a stack trace. Either way, it writes a crash report with the
+// details of the error to a temporary file; see WriteCrashReport.
 //
 // If -h has been specified, FatalfAt panics to force the usual runtime info dump.
 func FatalfAt(pos src.XPos, format string, args ...interface{}) {
@@ -227,6 +229,7 @@ func FatalfAt(pos src.XPos, format string, args ...interface{}) {
 			os.Stdout.Write(debug.Stack())
 			fmt.Println()
 		}
+		WriteCrashReport(pos, fmt.Sprintf(format, args...))
 	}
 
 	hcrash()
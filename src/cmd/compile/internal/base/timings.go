@@ -48,6 +48,14 @@ func (t *Timings) Stop(labels ...string) {
 	t.append(labels, false)
 }
 
+// Phase returns the name of the most recently started or stopped phase.
+func (t *Timings) Phase() string {
+	if len(t.list) == 0 {
+		return ""
+	}
+	return t.list[len(t.list)-1].label
+}
+
 // AddEvent associates an event, i.e., a count, or an amount of data,
 // with the most recently started or stopped phase; or the very first
 // phase if Start or Stop hasn't been called yet. The unit specifies
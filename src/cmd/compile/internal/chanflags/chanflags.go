@@ -0,0 +1,422 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chanflags finds channel operations on channels that are
+// never nil and never closed, so that the runtime can skip checking
+// for either. These are channels made by the function operating on
+// them, like
+//
+//	ch := make(chan int, 16)
+//	go produce(ch)
+//	for {
+//		consume(<-ch)
+//	}
+//
+// whose variable is assigned once, and which are only used to send,
+// receive, range over, take the length or capacity of, compare, and
+// pass to functions that in turn only use them that way. Such
+// functions are said to keep their channel parameters open; which
+// ones do is recorded in Func.KeepsChansOpen, and in export data for
+// calls from other packages.
+package chanflags
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+)
+
+// Flags passed to the runtime's chansend1 and chanrecv1. Keep in sync
+// with runtime/chan.go.
+const (
+	NotNil    = 1 << iota // the channel is not nil
+	NotClosed             // the channel is never closed
+)
+
+// proven maps channel operations to the flags that hold for them.
+var proven = make(map[ir.Node]int64)
+
+// Flags returns the flags that hold for channel operation n, a send,
+// receive or range statement.
+func Flags(n ir.Node) int64 {
+	return proven[n]
+}
+
+// SetFlags records that flags hold for channel operation n. It is
+// used for the operations created to implement another one.
+func SetFlags(n ir.Node, flags int64) {
+	if flags != 0 {
+		proven[n] = flags
+	}
+}
+
+// Funcs computes which channel parameters the functions of all keep
+// open, and finds the operations on channels they make that need no
+// nil or closed checks. It must run after inlining and escape
+// analysis, which can rewrite function bodies, and before walk.
+func Funcs(all []ir.Node) {
+	if base.Flag.N != 0 {
+		return
+	}
+	ir.VisitFuncsBottomUp(all, func(list []*ir.Func, recursive bool) {
+		var fns []*ir.Func
+		for _, fn := range list {
+			// Closures are analyzed as part of their outer
+			// function, and have no summary of their own.
+			if !fn.IsHiddenClosure() && len(fn.Body) != 0 {
+				fns = append(fns, fn)
+			}
+		}
+
+		// Start from the optimistic assumption that the functions
+		// keep all their parameters open, and remove those they
+		// don't until nothing changes.
+		as := make([]*analysis, len(fns))
+		for i, fn := range fns {
+			fn.KeepsChansOpen = ^uint32(0)
+			as[i] = analyze(fn)
+		}
+		for changed := true; changed; {
+			changed = false
+			for _, a := range as {
+				a.solve()
+				if keeps := a.params(); keeps != a.fn.KeepsChansOpen {
+					a.fn.KeepsChansOpen = keeps
+					changed = true
+				}
+			}
+		}
+		for _, a := range as {
+			a.record()
+		}
+	})
+}
+
+// An analysis records how the channel variables of a function and its
+// closures are used.
+type analysis struct {
+	fn *ir.Func
+
+	// uses counts the occurrences of each variable, and safe those
+	// that neither close the channel nor let it escape the analysis.
+	uses map[*ir.Name]int
+	safe map[*ir.Name]int
+
+	// defs counts the assignments to each variable, and made
+	// records the variables assigned a channel made by make.
+	defs map[*ir.Name]int
+	made map[*ir.Name]bool
+
+	// src maps each variable assigned another one to that variable.
+	// The use of the other variable is safe if the assigned one is.
+	src map[*ir.Name]*ir.Name
+
+	// args are the uses of variables as arguments to static calls,
+	// which are safe if the callee keeps that parameter open.
+	args []arg
+
+	// ops are the channel operations, by the variable holding the
+	// channel.
+	ops []op
+
+	// good holds the variables whose uses are all safe.
+	good map[*ir.Name]bool
+
+	// inSelect is set while visiting the cases of a select statement.
+	inSelect bool
+}
+
+type arg struct {
+	v      *ir.Name
+	callee *ir.Func
+	i      int
+}
+
+type op struct {
+	n ir.Node
+	v *ir.Name
+}
+
+func analyze(fn *ir.Func) *analysis {
+	a := &analysis{
+		fn:   fn,
+		uses: make(map[*ir.Name]int),
+		safe: make(map[*ir.Name]int),
+		defs: make(map[*ir.Name]int),
+		made: make(map[*ir.Name]bool),
+		src:  make(map[*ir.Name]*ir.Name),
+	}
+	a.visitList(fn.Body)
+	return a
+}
+
+// chanVar returns the channel variable n refers to, if any, looking
+// through conversions between channel types, and from the variables
+// closures capture to the captured ones.
+func chanVar(n ir.Node) *ir.Name {
+	for n.Op() == ir.OCONVNOP {
+		n = n.(*ir.ConvExpr).X
+	}
+	if n.Op() != ir.ONAME {
+		return nil
+	}
+	v := n.(*ir.Name).Canonical()
+	if v.Class != ir.PAUTO && v.Class != ir.PPARAM || !v.Type().IsChan() {
+		return nil
+	}
+	return v
+}
+
+// markSafe records that the use of a channel variable as n, if it is
+// one, is safe.
+func (a *analysis) markSafe(n ir.Node) *ir.Name {
+	v := chanVar(n)
+	if v != nil {
+		a.safe[v]++
+	}
+	return v
+}
+
+func (a *analysis) visitList(list ir.Nodes) {
+	for _, n := range list {
+		if n != nil {
+			a.visit(n)
+		}
+	}
+}
+
+func (a *analysis) visit(n ir.Node) {
+	switch n.Op() {
+	case ir.ONAME:
+		if v := chanVar(n); v != nil {
+			a.uses[v]++
+		}
+		return
+
+	case ir.OCLOSURE:
+		a.visitList(n.(*ir.ClosureExpr).Func.Body)
+
+	case ir.OSELECT:
+		// The channel operations of a select are checked by the
+		// runtime's selectgo, so only their operands are recorded.
+		n := n.(*ir.SelectStmt)
+		a.visitList(n.Init())
+		for _, cas := range n.Cases {
+			a.visitList(cas.Init())
+			if cas.Comm != nil {
+				inSelect := a.inSelect
+				a.inSelect = true
+				a.visit(cas.Comm)
+				a.inSelect = inSelect
+			}
+			a.visitList(cas.Body)
+		}
+		return
+
+	case ir.OSEND:
+		n := n.(*ir.SendStmt)
+		if v := a.markSafe(n.Chan); v != nil {
+			a.op(n, v)
+		}
+
+	case ir.ORECV:
+		n := n.(*ir.UnaryExpr)
+		if v := a.markSafe(n.X); v != nil {
+			a.op(n, v)
+		}
+
+	case ir.ORANGE:
+		n := n.(*ir.RangeStmt)
+		if v := a.markSafe(n.X); v != nil {
+			a.op(n, v)
+		}
+
+	case ir.OLEN, ir.OCAP:
+		a.markSafe(n.(*ir.UnaryExpr).X)
+
+	case ir.OEQ, ir.ONE:
+		n := n.(*ir.BinaryExpr)
+		a.markSafe(n.X)
+		a.markSafe(n.Y)
+
+	case ir.ODCL:
+		a.markSafe(n.(*ir.Decl).X)
+
+	case ir.OAS:
+		n := n.(*ir.AssignStmt)
+		a.assign(n.X, n.Y)
+
+	case ir.OAS2:
+		// Inlining assigns the arguments of a call to the parameters
+		// of the inlined function all at once.
+		n := n.(*ir.AssignListStmt)
+		if len(n.Lhs) == len(n.Rhs) {
+			for i := range n.Lhs {
+				a.assign(n.Lhs[i], n.Rhs[i])
+			}
+		}
+
+	case ir.OCALLFUNC:
+		n := n.(*ir.CallExpr)
+		var callee *ir.Name
+		switch x := n.X; x.Op() {
+		case ir.ONAME:
+			if x := x.(*ir.Name); x.Class == ir.PFUNC {
+				callee = x
+			}
+		case ir.OMETHEXPR:
+			callee = ir.MethodExprName(x)
+		}
+		if callee != nil && callee.Func != nil {
+			for i, x := range n.Args {
+				if v := chanVar(x); v != nil {
+					a.args = append(a.args, arg{v, callee.Func, i})
+				}
+			}
+		}
+	}
+	ir.DoChildren(n, func(n ir.Node) bool {
+		a.visit(n)
+		return false
+	})
+}
+
+// op records channel operation n on the channel held by v.
+func (a *analysis) op(n ir.Node, v *ir.Name) {
+	if !a.inSelect {
+		a.ops = append(a.ops, op{n, v})
+	}
+}
+
+// assign records the assignment of y to x.
+func (a *analysis) assign(x, y ir.Node) {
+	if x.Op() != ir.ONAME {
+		return
+	}
+	v := chanVar(x)
+	if v == nil {
+		return
+	}
+	a.defs[v]++
+	if y == nil {
+		return
+	}
+	if y.Op() == ir.OMAKECHAN {
+		a.made[v] = true
+	} else if w := chanVar(y); w != nil {
+		a.src[v] = w
+	}
+}
+
+// solve computes which variables are good, given the current
+// summaries of the functions called.
+func (a *analysis) solve() {
+	a.good = make(map[*ir.Name]bool)
+	for v := range a.uses {
+		a.good[v] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		safe := make(map[*ir.Name]int)
+		for v, n := range a.safe {
+			safe[v] = n
+		}
+		for v, n := range a.defs {
+			// A variable's assignment is a safe use of it if it
+			// is its only one, and it isn't a parameter, whose
+			// argument is another assignment.
+			if n == 1 && v.Class == ir.PAUTO {
+				safe[v]++
+			}
+		}
+		for x, y := range a.src {
+			if a.good[x] {
+				safe[y]++
+			}
+		}
+		for _, arg := range a.args {
+			if arg.i < 32 && arg.callee.KeepsChansOpen&(1<<arg.i) != 0 {
+				safe[arg.v]++
+			}
+		}
+		for v := range a.good {
+			if safe[v] != a.uses[v] {
+				delete(a.good, v)
+				changed = true
+			}
+		}
+	}
+}
+
+// params returns the summary of which parameters a.fn keeps open.
+func (a *analysis) params() uint32 {
+	var keeps uint32
+	for i, p := range paramNames(a.fn) {
+		if i < 32 && (p == nil || !p.Type().IsChan() || a.uses[p] == 0 || a.good[p]) {
+			keeps |= 1 << i
+		}
+	}
+	return keeps
+}
+
+// paramNames returns the receiver and parameters of fn, in the order
+// of the arguments of a call to it.
+func paramNames(fn *ir.Func) []*ir.Name {
+	var names []*ir.Name
+	t := fn.Type()
+	if t.Recv() != nil {
+		names = append(names, fieldName(t.Recv().Nname))
+	}
+	for _, f := range t.Params().FieldSlice() {
+		names = append(names, fieldName(f.Nname))
+	}
+	return names
+}
+
+func fieldName(n types.Object) *ir.Name {
+	if n == nil || ir.IsBlank(n.(*ir.Name)) {
+		return nil
+	}
+	return n.(*ir.Name)
+}
+
+// notNil reports whether channel variable v is never nil: it is a
+// good variable only assigned a channel made by make, or another
+// such variable.
+func (a *analysis) notNil(v *ir.Name) bool {
+	for i := 0; i < len(a.defs); i++ {
+		if !a.good[v] || a.defs[v] != 1 || v.Class != ir.PAUTO {
+			return false
+		}
+		if a.made[v] {
+			return true
+		}
+		if v = a.src[v]; v == nil {
+			return false
+		}
+	}
+	return false
+}
+
+// record records the flags of the operations on good channels.
+func (a *analysis) record() {
+	for _, op := range a.ops {
+		if !a.notNil(op.v) {
+			continue
+		}
+		proven[op.n] = NotNil | NotClosed
+		if base.Debug.ChanFlags != 0 {
+			var what string
+			switch op.n.Op() {
+			case ir.OSEND:
+				what = "send on"
+			case ir.ORECV:
+				what = "receive from"
+			case ir.ORANGE:
+				what = "range over"
+			}
+			base.WarnfAt(op.n.Pos(), "%s %v skips nil and closed checks", what, op.v)
+		}
+	}
+}
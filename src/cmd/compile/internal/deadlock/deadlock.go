@@ -0,0 +1,267 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deadlock reports channel operations and mutex locks that
+// are certain to block forever. It is enabled by -d=deadlock.
+//
+// Only trivial cases are found, along straight-line code: a sequence
+// of statements of the same block, with no labels, and no gotos or
+// returns between them. These are
+//
+//   - a send on an unbuffered channel, or a receive from any channel,
+//     made by a statement before it, if the channel isn't used in
+//     between, so that no other goroutine can have it;
+//   - locking a sync.Mutex, or a sync.RWMutex for writing, that is
+//     already locked by a statement before it, or read locking a
+//     sync.RWMutex that is already locked for writing, if there are no
+//     calls in between, which could unlock it, and nothing is
+//     assigned that could change which mutex is locked.
+package deadlock
+
+import (
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+)
+
+// Func reports the channel operations and locks in fn that block
+// forever. Closures are checked as functions of their own.
+// It must run before inlining, and before package lockelide rewrites
+// Lock calls.
+func Func(fn *ir.Func) {
+	if base.Debug.Deadlock == 0 || fn.Wrapper() || fn.Dupok() {
+		return
+	}
+	stmts(fn.Body)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n := n.(type) {
+		case *ir.BlockStmt:
+			stmts(n.List)
+		case *ir.IfStmt:
+			stmts(n.Body)
+			stmts(n.Else)
+		case *ir.ForStmt:
+			stmts(n.Body)
+		case *ir.RangeStmt:
+			stmts(n.Body)
+		case *ir.CaseClause:
+			stmts(n.Body)
+		case *ir.CommClause:
+			stmts(n.Body)
+		}
+	})
+}
+
+// stmts checks the statements of a block.
+func stmts(list ir.Nodes) {
+	for i, n := range list {
+		if v := madeChan(n); v != nil {
+			checkChan(v, list[i+1:])
+		}
+		if l := lockCall(n); l != nil {
+			checkLock(l, list[i+1:])
+		}
+	}
+}
+
+// madeChan returns the variable n declares and initializes with a
+// new channel, if it does.
+func madeChan(n ir.Node) *ir.Name {
+	if n.Op() != ir.OAS {
+		return nil
+	}
+	as := n.(*ir.AssignStmt)
+	if as.X.Op() != ir.ONAME || as.Y == nil || as.Y.Op() != ir.OMAKECHAN {
+		return nil
+	}
+	v := as.X.(*ir.Name)
+	if v.Class != ir.PAUTO || v.Defn != as {
+		return nil
+	}
+	return v
+}
+
+// checkChan reports the first of list if it is a send or receive on
+// the channel just made in v, skipping statements that don't refer to
+// v. A send only blocks if the channel is unbuffered.
+func checkChan(v *ir.Name, list ir.Nodes) {
+	for _, n := range list {
+		if ends(n) {
+			return
+		}
+		refs := refs(n, v)
+		if refs == 0 {
+			continue
+		}
+		if refs > 1 {
+			// The channel may reach another goroutine before
+			// the operation blocks.
+			return
+		}
+		switch n.Op() {
+		case ir.OSEND:
+			n := n.(*ir.SendStmt)
+			if n.Chan == v && unbuffered(v) {
+				base.WarnfAt(n.Pos(), "send on unbuffered channel %v blocks forever: no other goroutine can receive from it", v)
+			}
+		case ir.ORECV, ir.OAS, ir.OAS2RECV:
+			if r := recv(n); r != nil && r.X == v {
+				base.WarnfAt(r.Pos(), "receive from channel %v blocks forever: no other goroutine can send on it", v)
+			}
+		}
+		return
+	}
+}
+
+// unbuffered reports whether the channel v was made with is unbuffered.
+func unbuffered(v *ir.Name) bool {
+	size := v.Defn.(*ir.AssignStmt).Y.(*ir.MakeExpr).Len
+	return ir.IsConst(size, constant.Int) && ir.Int64Val(size) == 0
+}
+
+// recv returns the receive that statement n consists of, if any.
+func recv(n ir.Node) *ir.UnaryExpr {
+	switch n.Op() {
+	case ir.OAS:
+		n = n.(*ir.AssignStmt).Y
+	case ir.OAS2RECV:
+		n = n.(*ir.AssignListStmt).Rhs[0]
+	}
+	if n == nil || n.Op() != ir.ORECV {
+		return nil
+	}
+	return n.(*ir.UnaryExpr)
+}
+
+// refs returns the number of references to v in n, counting its
+// capture by closures.
+func refs(n ir.Node, v *ir.Name) int {
+	count := 0
+	ir.Visit(n, func(n ir.Node) {
+		switch n.Op() {
+		case ir.ONAME:
+			if n == v {
+				count++
+			}
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				if cv.Canonical() == v {
+					count++
+				}
+			}
+		}
+	})
+	return count
+}
+
+// ends reports whether n ends the straight-line code it is part of,
+// or may be jumped to from elsewhere.
+func ends(n ir.Node) bool {
+	switch n.Op() {
+	case ir.ORETURN, ir.OTAILCALL, ir.OBREAK, ir.OCONTINUE, ir.OFALL, ir.OPANIC:
+		return true
+	}
+	return ir.Any(n, func(n ir.Node) bool {
+		return n.Op() == ir.OLABEL || n.Op() == ir.OGOTO
+	})
+}
+
+// A lock is a call locking a mutex.
+type lock struct {
+	call  *ir.CallExpr
+	mu    ir.Node // the mutex, or a pointer to it
+	write bool    // a Lock call, rather than RLock
+}
+
+// lockCall returns the lock that statement n is, if it is one.
+func lockCall(n ir.Node) *lock {
+	if n.Op() != ir.OCALLFUNC {
+		return nil
+	}
+	call := n.(*ir.CallExpr)
+	if call.X.Op() != ir.OMETHEXPR || len(call.Args) != 1 {
+		return nil
+	}
+	recv := call.X.(*ir.SelectorExpr).X.Type()
+	if !recv.IsPtr() {
+		return nil
+	}
+	var write bool
+	switch name := call.X.(*ir.SelectorExpr).Sel.Name; {
+	case isSync(recv.Elem(), "Mutex") && name == "Lock",
+		isSync(recv.Elem(), "RWMutex") && name == "Lock":
+		write = true
+	case isSync(recv.Elem(), "RWMutex") && name == "RLock":
+	default:
+		return nil
+	}
+	mu := call.Args[0]
+	if mu.Op() == ir.OADDR {
+		mu = mu.(*ir.AddrExpr).X
+	}
+	return &lock{call, mu, write}
+}
+
+// isSync reports whether t is the named type of package sync.
+func isSync(t *types.Type, name string) bool {
+	s := t.Sym()
+	return s != nil && s.Pkg.Path == "sync" && s.Name == name
+}
+
+// checkLock reports the first lock in list of the mutex l locks that
+// blocks, skipping statements that can't unlock it.
+func checkLock(l *lock, list ir.Nodes) {
+	for _, n := range list {
+		if ends(n) {
+			return
+		}
+		if l2 := lockCall(n); l2 != nil && ir.SameSafeExpr(l.mu, l2.mu) {
+			if l.write || l2.write {
+				base.WarnfAt(n.Pos(), "lock of %v blocks forever: it is already locked at %v", l.mu, base.FmtPos(l.call.Pos()))
+			}
+			return
+		}
+		if mayUnlock(n, l.mu) {
+			return
+		}
+	}
+}
+
+// mayUnlock reports whether n may unlock mutex mu, or change which
+// mutex the expression mu refers to. Only assignments of numbers,
+// booleans and strings can't, unless they are used as indexes in mu.
+func mayUnlock(n, mu ir.Node) bool {
+	indexes := ir.Any(mu, func(n ir.Node) bool {
+		return n.Op() == ir.OINDEX || n.Op() == ir.OINDEXMAP
+	})
+	assigns := func(x ir.Node) bool {
+		if ir.IsBlank(x) {
+			return false
+		}
+		t := x.Type()
+		return indexes || !(t.IsInteger() || t.IsFloat() || t.IsComplex() || t.IsBoolean() || t.IsString())
+	}
+	return ir.Any(n, func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.OCALLFUNC, ir.OCALLMETH, ir.OCALLINTER, ir.OGO, ir.ODEFER:
+			return true
+		case ir.OAS:
+			return assigns(n.(*ir.AssignStmt).X)
+		case ir.OASOP:
+			return assigns(n.(*ir.AssignOpStmt).X)
+		case ir.OAS2, ir.OAS2DOTTYPE, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2RECV, ir.OSELRECV2:
+			for _, x := range n.(*ir.AssignListStmt).Lhs {
+				if assigns(x) {
+					return true
+				}
+			}
+		case ir.ORANGE:
+			n := n.(*ir.RangeStmt)
+			return n.Key != nil && assigns(n.Key) || n.Value != nil && assigns(n.Value)
+		}
+		return false
+	})
+}
@@ -31,7 +31,9 @@ func Info(fnsym *obj.LSym, infosym *obj.LSym, curfn interface{}) ([]dwarf.Scope,
 		if fnsym.ABI() == obj.ABI0 {
 			expect = fn.LinksymABI(obj.ABI0)
 		}
-		if fnsym != expect {
+		// A //go:cpu variant is compiled from fn with fn.LSym
+		// temporarily set to the variant's symbol.
+		if fnsym != expect && fnsym != fn.LSym {
 			base.Fatalf("unexpected fnsym: %v != %v", fnsym, expect)
 		}
 	}
@@ -0,0 +1,280 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package escape
+
+import (
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+)
+
+// Append and copy aliasing diagnostics, enabled by -d=appendalias.
+//
+// append(a, x) stores x in a's backing array if it has spare capacity,
+// and only allocates a new array otherwise. Keeping the result of one
+// append to a in another variable, and then appending to a again,
+// therefore overwrites the element the first append added whenever a
+// had room for it:
+//
+//	b := append(a, x)
+//	c := append(a, y) // may overwrite b[len(a)]
+//
+// The same happens when a loop appends to a slice that it does not
+// change, and keeps the results beyond the iteration:
+//
+//	for _, x := range xs {
+//		all = append(all, append(prefix, x)) // may all share one array
+//	}
+//
+// copy moves elements as if through a temporary buffer, so copying
+// between overlapping parts of a slice such that the elements move to
+// higher indexes does not repeat the first ones, as a loop copying
+// one element at a time would:
+//
+//	copy(s[1:], s) // shifts s right by one
+//
+// Escape analysis has already found which variables are reassigned,
+// captured by closures or have their address taken, which settles
+// whether the appends and copies below refer to the same arrays.
+
+// checkAppendAlias reports the appends within fn that may overwrite
+// elements added by another append, and its surprising copies.
+func (b *batch) checkAppendAlias(fn *ir.Func) {
+	// local reports whether n is a local slice variable of fn that
+	// only fn's own statements change.
+	local := func(n ir.Node) (*ir.Name, bool) {
+		name, ok := n.(*ir.Name)
+		if !ok || name.Curfn != fn || name.IsClosureVar() || name.Class != ir.PAUTO && name.Class != ir.PPARAM {
+			return nil, false
+		}
+		loc := b.oldLoc(name)
+		return name, !loc.addrtaken && !loc.captured
+	}
+
+	// spare reports whether a may have spare capacity. It does not
+	// if it is only ever assigned by its declaration, which is nil
+	// or a slice literal.
+	spare := func(a *ir.Name) bool {
+		if b.oldLoc(a).reassigned || a.Class == ir.PPARAM {
+			return true
+		}
+		as, ok := a.Defn.(*ir.AssignStmt)
+		if !ok || as.Y == nil {
+			return !ok
+		}
+		return as.Y.Op() != ir.ONIL && as.Y.Op() != ir.OSLICELIT
+	}
+
+	// appendTo returns the local slice that n is an append to, if any.
+	appendTo := func(n ir.Node) *ir.Name {
+		if n.Op() != ir.OAPPEND {
+			return nil
+		}
+		a, ok := local(n.(*ir.CallExpr).Args[0])
+		if !ok || !a.Type().IsSlice() || !spare(a) {
+			return nil
+		}
+		return a
+	}
+
+	var list func(l ir.Nodes)
+	var visit func(n ir.Node) bool
+	var loops []ir.Node // enclosing loops, innermost last
+	visit = func(n ir.Node) bool {
+		switch n := n.(type) {
+		case *ir.ClosureExpr:
+			// Closures are checked on their own.
+			return false
+		case *ir.BlockStmt:
+			list(n.List)
+		case *ir.IfStmt:
+			list(n.Body)
+			list(n.Else)
+		case *ir.ForStmt:
+			loops = append(loops, n)
+			list(n.Body)
+			ir.DoChildren(n, visit)
+			loops = loops[:len(loops)-1]
+			return false
+		case *ir.RangeStmt:
+			loops = append(loops, n)
+			list(n.Body)
+			ir.DoChildren(n, visit)
+			loops = loops[:len(loops)-1]
+			return false
+		case *ir.CaseClause:
+			list(n.Body)
+		case *ir.CommClause:
+			list(n.Body)
+		case *ir.BinaryExpr:
+			if n.Op() == ir.OCOPY {
+				checkCopy(n)
+			}
+		case *ir.CallExpr:
+			if a := appendTo(n); a != nil && len(loops) > 0 {
+				checkLoopAppend(n, a, loops[len(loops)-1])
+			}
+		}
+		return ir.DoChildren(n, visit)
+	}
+
+	// list checks for appends to the same slice as an earlier
+	// statement of l, whose result it kept in another variable.
+	list = func(l ir.Nodes) {
+		for i, n := range l {
+			as, ok := n.(*ir.AssignStmt)
+			if !ok || as.Op() != ir.OAS || as.Y == nil {
+				continue
+			}
+			a := appendTo(as.Y)
+			if a == nil || as.X == a || ir.IsBlank(as.X) {
+				continue
+			}
+			for _, later := range l[i+1:] {
+				if again := findAppend(later, a); again != nil {
+					base.WarnfAt(again.Pos(), "append to %v may overwrite the elements of %v, appended to %v at %v", a, as.X, a, base.FmtPos(as.Y.Pos()))
+					break
+				}
+				if assigns(later, a) {
+					break
+				}
+			}
+		}
+	}
+
+	list(fn.Body)
+	for _, n := range fn.Body {
+		visit(n)
+	}
+}
+
+// findAppend returns an append to a within n, if any.
+func findAppend(n ir.Node, a *ir.Name) ir.Node {
+	var found ir.Node
+	ir.Any(n, func(n ir.Node) bool {
+		if n.Op() == ir.OAPPEND && n.(*ir.CallExpr).Args[0] == a {
+			found = n
+			return true
+		}
+		return n.Op() == ir.OCLOSURE
+	})
+	return found
+}
+
+// assigns reports whether n assigns to a, other than by appending.
+func assigns(n ir.Node, a *ir.Name) bool {
+	return ir.Any(n, func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.OAS:
+			return n.(*ir.AssignStmt).X == a
+		case ir.OAS2, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2DOTTYPE, ir.OAS2RECV, ir.OSELRECV2:
+			for _, l := range n.(*ir.AssignListStmt).Lhs {
+				if l == a {
+					return true
+				}
+			}
+		case ir.ORANGE:
+			n := n.(*ir.RangeStmt)
+			return n.Key == a || n.Value == a
+		}
+		return false
+	})
+}
+
+// checkLoopAppend reports call, an append to a within loop, if it may
+// use the same array of a in every iteration and its result is kept
+// beyond the iteration: appended to another slice, stored into an
+// element or sent on a channel.
+func checkLoopAppend(call *ir.CallExpr, a *ir.Name, loop ir.Node) {
+	if assigns(loop, a) || ir.Any(loop, func(n ir.Node) bool {
+		return n.Op() == ir.ODCL && n.(*ir.Decl).X == a
+	}) {
+		return
+	}
+	kept := ir.Any(loop, func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.OAPPEND:
+			for _, arg := range n.(*ir.CallExpr).Args[1:] {
+				if arg == call {
+					return true
+				}
+			}
+		case ir.OAS:
+			n := n.(*ir.AssignStmt)
+			if n.Y == call {
+				switch n.X.Op() {
+				case ir.OINDEX, ir.OINDEXMAP, ir.ODOT, ir.ODOTPTR, ir.ODEREF:
+					return true
+				}
+			}
+		case ir.OSEND:
+			return n.(*ir.SendStmt).Value == call
+		}
+		return false
+	})
+	if kept {
+		base.WarnfAt(call.Pos(), "append to %v in loop may reuse the same array in every iteration, but its results are kept beyond the iteration", a)
+	}
+}
+
+// checkCopy reports n, a copy, if its destination and source are
+// parts of the same slice or array variable, and it copies the slice
+// onto itself or moves its elements to higher indexes.
+func checkCopy(n *ir.BinaryExpr) {
+	// part returns the variable that x is a part of, and the
+	// constant index within it at which x starts, and ends if known.
+	part := func(x ir.Node) (v *ir.Name, lo, hi int64, ok bool) {
+		hi = -1
+		if s, isSlice := x.(*ir.SliceExpr); isSlice && (s.Op() == ir.OSLICE || s.Op() == ir.OSLICEARR) {
+			x = s.X
+			if s.Op() == ir.OSLICEARR {
+				if x.Op() != ir.OADDR {
+					return nil, 0, 0, false
+				}
+				x = x.(*ir.AddrExpr).X
+			}
+			if s.Low != nil {
+				if !ir.IsConst(s.Low, constant.Int) {
+					return nil, 0, 0, false
+				}
+				lo = ir.Int64Val(s.Low)
+			}
+			if s.High != nil && ir.IsConst(s.High, constant.Int) {
+				hi = ir.Int64Val(s.High)
+			}
+		} else if !x.Type().IsSlice() {
+			return nil, 0, 0, false
+		}
+		v, ok = x.(*ir.Name)
+		if ok && hi < 0 && v.Type().IsArray() {
+			hi = v.Type().NumElem()
+		}
+		return v, lo, hi, ok
+	}
+
+	dst, dlo, dhi, ok := part(n.X)
+	if !ok {
+		return
+	}
+	src, slo, shi, ok := part(n.Y)
+	if !ok || src != dst || dlo < slo {
+		return
+	}
+	if dlo == slo {
+		base.WarnfAt(n.Pos(), "copy of %v onto itself does nothing", dst)
+		return
+	}
+	if dhi >= 0 && shi >= 0 {
+		count := dhi - dlo
+		if shi-slo < count {
+			count = shi - slo
+		}
+		if slo+count <= dlo {
+			return // the parts do not overlap
+		}
+	}
+	base.WarnfAt(n.Pos(), "copy shifts the elements of %v right by %d; the elements it overwrites are copied first, as if through a temporary buffer", dst, dlo-slo)
+}
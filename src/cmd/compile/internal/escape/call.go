@@ -205,9 +205,12 @@ func (e *escape) callCommon(ks []hole, call ir.Node, init *ir.Nodes, wrapper *ir
 //	defer func() { f(x1, y1) }()
 func (e *escape) goDeferStmt(n *ir.GoDeferStmt) {
 	k := e.heapHole()
-	if n.Op() == ir.ODEFER && e.loopDepth == 1 {
+	if n.Op() == ir.ODEFER && (e.loopDepth == 1 || e.onceDefers[n]) {
 		// Top-level defer arguments don't escape to the heap,
 		// but they do need to last until they're invoked.
+		// The same holds for defers that execute at most once
+		// within a loop, because the loop never iterates again
+		// after the defer statement is reached.
 		k = e.later(e.discardHole())
 
 		// force stack allocation of defer record, unless
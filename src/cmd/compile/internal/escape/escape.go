@@ -87,6 +87,8 @@ type batch struct {
 	allLocs  []*location
 	closures []closure
 
+	bytesConvs []bytesConv // string(b) conversions to rewrite if possible
+
 	heapLoc  location
 	blankLoc location
 }
@@ -112,6 +114,19 @@ type escape struct {
 	// label with a corresponding backwards "goto" (i.e.,
 	// unstructured loop).
 	loopDepth int
+
+	// onceDefers records defer statements within loops that are
+	// known to execute at most once before curfn returns (see
+	// exitsWithoutLooping). They can be treated like top-level
+	// defers, and in particular can be open-coded.
+	onceDefers map[*ir.GoDeferStmt]bool
+
+	// uses and capturedVars record how many times each local
+	// variable of curfn is used, and which are captured by
+	// closures, for iterationLocals. They are computed when first
+	// needed.
+	uses         map[*ir.Name]int
+	capturedVars map[*ir.Name]bool
 }
 
 func Funcs(all []ir.Node) {
@@ -136,6 +151,7 @@ func Batch(fns []*ir.Func, recursive bool) {
 			s := fmt.Sprintf("\nbefore escape %v", fn)
 			ir.Dump(s, fn)
 		}
+		b.bytesConvs = append(b.bytesConvs, zeroCopyConversions(fn)...)
 		b.initFunc(fn)
 	}
 	for _, fn := range fns {
@@ -143,6 +159,11 @@ func Batch(fns []*ir.Func, recursive bool) {
 			b.walkFunc(fn)
 		}
 	}
+	if base.Debug.AppendAlias != 0 {
+		for _, fn := range fns {
+			b.checkAppendAlias(fn)
+		}
+	}
 
 	// We've walked the function bodies, so we've seen everywhere a
 	// variable might be reassigned or have it's address taken. Now we
@@ -153,13 +174,33 @@ func Batch(fns []*ir.Func, recursive bool) {
 	}
 	b.closures = nil
 
+	var large []*location
 	for _, loc := range b.allLocs {
-		if why := HeapAllocReason(loc.n); why != "" {
+		if why := FuncHeapAllocReason(loc.curfn, loc.n); why != "" {
+			if why == tooLarge {
+				large = append(large, loc)
+				continue
+			}
 			b.flow(b.heapHole().addr(loc.n, why), loc)
 		}
 	}
 
 	b.walkAll()
+
+	// Values too large for the stack are heap allocated too, but
+	// we only add them once we know which of them escape anyway,
+	// to report those that are moved to the heap just for their
+	// size. Escaping only grows, so walking again is enough.
+	if len(large) > 0 {
+		for _, loc := range large {
+			if !loc.escapes {
+				reportTooLarge(loc.curfn, loc.n)
+			}
+			b.flow(b.heapHole().addr(loc.n, tooLarge), loc)
+		}
+		b.walkAll()
+	}
+	b.finishBytesConvs()
 	b.finish(fns)
 }
 
@@ -278,7 +319,8 @@ func (b *batch) finish(fns []*ir.Func) {
 
 	for _, loc := range b.allLocs {
 		n := loc.n
-		if n == nil {
+		if n == nil || n.Op() == ir.OBYTES2STRTMP {
+			// Rewritten string(b) conversions no longer allocate.
 			continue
 		}
 		if n.Op() == ir.ONAME {
@@ -286,6 +328,10 @@ func (b *batch) finish(fns []*ir.Func) {
 			n.Opt = nil
 		}
 
+		if loc.aliasPos.IsKnown() {
+			reportLoopAlias(loc)
+		}
+
 		// Update n.Esc based on escape analysis results.
 
 		// Omit escape diagnostics for go/defer wrappers, at least for now.
@@ -297,6 +343,8 @@ func (b *batch) finish(fns []*ir.Func) {
 			if n.Op() == ir.ONAME {
 				if base.Flag.CompilingRuntime {
 					base.ErrorfAt(n.Pos(), "%v escapes to heap, not allowed in runtime", n)
+				} else if base.Debug.NoAllocPackage != 0 {
+					base.ErrorfAt(n.Pos(), "moved to heap: %v, not allowed with -d=noallocpackage%s", n, loc.heapPath)
 				}
 				if base.Flag.LowerM != 0 {
 					base.WarnfAt(n.Pos(), "moved to heap: %v", n)
@@ -305,6 +353,9 @@ func (b *batch) finish(fns []*ir.Func) {
 				if base.Flag.LowerM != 0 && !goDeferWrapper {
 					base.WarnfAt(n.Pos(), "%v escapes to heap", n)
 				}
+				if base.Debug.NoAllocPackage != 0 && !goDeferWrapper {
+					base.ErrorfAt(n.Pos(), "%v escapes to heap, not allowed with -d=noallocpackage%s", n, loc.heapPath)
+				}
 				if logopt.Enabled() {
 					var e_curfn *ir.Func // TODO(mdempsky): Fix.
 					logopt.LogOpt(n.Pos(), "escape", "escape", ir.FuncName(e_curfn))
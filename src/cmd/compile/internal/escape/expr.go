@@ -255,6 +255,11 @@ func (e *escape) exprSkipInit(k hole, n ir.Node) {
 		e.spill(k, n)
 		e.discard(n.X)
 
+	case ir.OBYTES2STRTMP, ir.OSTR2BYTESTMP:
+		// The result shares its operand's memory.
+		n := n.(*ir.ConvExpr)
+		e.expr(k, n.X)
+
 	case ir.OADDSTR:
 		n := n.(*ir.AddStringExpr)
 		e.spill(k, n)
@@ -9,7 +9,9 @@ import (
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/logopt"
 	"cmd/compile/internal/types"
+	"cmd/internal/src"
 	"fmt"
+	"strings"
 )
 
 // Below we implement the methods for walking the AST and recording
@@ -71,6 +73,10 @@ type location struct {
 	// allocated.
 	escapes bool
 
+	// heapPath explains how the represented variable's address
+	// escapes, for -d=noallocpackage errors.
+	heapPath string
+
 	// transient reports whether the represented expression's
 	// address does not outlive the statement; that is, whether
 	// its storage can be immediately reused.
@@ -82,6 +88,22 @@ type location struct {
 	captured   bool // has a closure captured this variable?
 	reassigned bool // has this variable been reassigned?
 	addrtaken  bool // has this variable's address been taken?
+
+	// iterDepth is the loopDepth of the loop body for variables
+	// declared by a range or for clause, which all iterations of
+	// the loop share, and 0 for other locations.
+	iterDepth int
+
+	// killDepth is the loopDepth of the innermost loop body whose
+	// iterations each assign the variable afresh before using it,
+	// so that none of its values survive an iteration, and 0 if
+	// there is no such loop. See iterationLocals.
+	killDepth int
+
+	// aliasPos is where a reference to a loop variable that
+	// outlives an iteration of its loop was made, or an unknown
+	// position if there is no such reference. See -d=loopalias.
+	aliasPos src.XPos
 }
 
 // An edge represents an assignment edge between two Go variables.
@@ -141,7 +163,7 @@ func (k hole) note(where ir.Node, why string) hole {
 	if where == nil || why == "" {
 		base.Fatalf("note: missing where/why")
 	}
-	if base.Flag.LowerM >= 2 || logopt.Enabled() {
+	if base.Flag.LowerM >= 2 || logopt.Enabled() || base.Debug.LoopAlias != 0 || base.Debug.NoAllocPackage != 0 {
 		k.notes = &note{
 			next:  k.notes,
 			where: where,
@@ -183,18 +205,28 @@ func (b *batch) flow(k hole, src *location) {
 		return
 	}
 	if dst.escapes && k.derefs < 0 { // dst = &src
-		if base.Flag.LowerM >= 2 || logopt.Enabled() {
+		if base.Flag.LowerM >= 2 || logopt.Enabled() || base.Debug.NoAllocPackage != 0 {
 			pos := base.FmtPos(src.n.Pos())
 			if base.Flag.LowerM >= 2 {
 				fmt.Printf("%s: %v escapes to heap:\n", pos, src.n)
 			}
-			explanation := b.explainFlow(pos, dst, src, k.derefs, k.notes, []*logopt.LoggedOpt{})
+			var desc *strings.Builder
+			if base.Debug.NoAllocPackage != 0 {
+				desc = new(strings.Builder)
+			}
+			explanation := b.explainFlow(pos, dst, src, k.derefs, k.notes, []*logopt.LoggedOpt{}, desc)
 			if logopt.Enabled() {
 				var e_curfn *ir.Func // TODO(mdempsky): Fix.
 				logopt.LogOpt(src.n.Pos(), "escapes", "escape", ir.FuncName(e_curfn), fmt.Sprintf("%v escapes to heap", src.n), explanation)
 			}
+			if desc != nil {
+				src.heapPath = desc.String()
+			}
 
 		}
+		if src.iterDepth > 0 && !src.aliasPos.IsKnown() {
+			src.aliasPos = aliasPos(k.notes, src.n)
+		}
 		src.escapes = true
 		return
 	}
@@ -0,0 +1,124 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package escape
+
+import (
+	"cmd/compile/internal/ir"
+)
+
+// Iteration-local variables.
+//
+// A variable declared outside a loop outlives each iteration of it,
+// so a value allocated in the loop body and stored in the variable
+// must normally be heap allocated: the stack slot of the allocation
+// is reused by the next iteration, while the variable still points
+// to it. But often the variable is only a scratch pointer, assigned
+// afresh at the start of each iteration and not used after the loop:
+//
+//	var p *T
+//	for _, v := range xs {
+//		t := T{v}
+//		p = &t
+//		sum += p.f()
+//	}
+//
+// Then no value of p survives the back edge of the loop, and for the
+// purposes of outlives, p is as if declared in the loop body.
+
+// iterationLocals records the variables of curfn whose values never
+// survive from one iteration of the loop with the given body, at the
+// current loop depth, to the next, or past the loop. These are the
+// variables that are assigned by a statement of the body itself (not
+// one nested in it) before any other statement of the body refers to
+// them, and that are used nowhere else, other than to be declared or
+// zeroed. Their address must not be taken, nor must they be captured
+// by a closure, since then they could be used indirectly.
+func (e *escape) iterationLocals(body ir.Nodes) {
+	if e.uses == nil {
+		e.uses = make(map[*ir.Name]int)
+		e.capturedVars = make(map[*ir.Name]bool)
+		countUses(e.curfn.Body, e.uses, e.capturedVars)
+	}
+
+	// A goto could skip the assignment to a variable, and reach
+	// a later use of it.
+	if ir.AnyList(body, func(n ir.Node) bool { return n.Op() == ir.OGOTO }) {
+		return
+	}
+
+	var killed []*ir.Name
+	seen := make(map[*ir.Name]bool)
+	for _, s := range body {
+		stmtUses := make(map[*ir.Name]int)
+		if s.Op() == ir.OAS {
+			as := s.(*ir.AssignStmt)
+			if x, ok := as.X.(*ir.Name); ok && as.Y != nil && !seen[x] {
+				countUses(as.Init(), stmtUses, nil)
+				countUses([]ir.Node{as.Y}, stmtUses, nil)
+				if stmtUses[x] == 0 {
+					killed = append(killed, x)
+				}
+			}
+		}
+		countUses([]ir.Node{s}, stmtUses, nil)
+		for x := range stmtUses {
+			seen[x] = true
+		}
+	}
+	if len(killed) == 0 {
+		return
+	}
+
+	loopUses := make(map[*ir.Name]int)
+	countUses(body, loopUses, nil)
+	for _, x := range killed {
+		if x.Class != ir.PAUTO || x.Curfn != e.curfn || x.Addrtaken() || e.capturedVars[x] || e.uses[x] != loopUses[x] {
+			continue
+		}
+		if loc := e.oldLoc(x); loc.killDepth < e.loopDepth {
+			loc.killDepth = e.loopDepth
+		}
+	}
+}
+
+// countUses adds to uses the number of times each local variable is
+// used in list, other than in declaring it or assigning it its zero
+// value, and adds the variables captured by closures to captured,
+// if it is not nil.
+func countUses(list ir.Nodes, uses map[*ir.Name]int, captured map[*ir.Name]bool) {
+	var visit func(n ir.Node) bool
+	visit = func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.ONAME:
+			if n := n.(*ir.Name); n.Class == ir.PAUTO {
+				uses[n]++
+			}
+			return false
+		case ir.ODCL:
+			return false
+		case ir.OAS:
+			n := n.(*ir.AssignStmt)
+			if _, ok := n.X.(*ir.Name); ok && n.Y == nil {
+				for _, init := range n.Init() {
+					visit(init)
+				}
+				return false
+			}
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				uses[cv.Canonical()]++
+				if captured != nil {
+					captured[cv.Canonical()] = true
+				}
+			}
+		}
+		return ir.DoChildren(n, visit)
+	}
+	for _, n := range list {
+		if n != nil {
+			visit(n)
+		}
+	}
+}
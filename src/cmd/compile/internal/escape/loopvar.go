@@ -0,0 +1,89 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package escape
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/internal/src"
+)
+
+// Loop variable diagnostics, enabled by -d=loopalias.
+//
+// A variable declared by a range or for clause is shared by every
+// iteration of its loop. Code that keeps the variable's address, or
+// a closure that refers to it, past the end of an iteration
+// therefore sees the values of later iterations, which is rarely
+// what was intended:
+//
+//	for _, v := range xs {
+//		ptrs = append(ptrs, &v) // every element points to the same v
+//	}
+//
+// Escape analysis already computes where addresses flow, so it can
+// report these references precisely: only those whose address flows
+// to a location declared outside the loop body, or to the heap.
+
+// loopVar records that n is declared by the clause of the loop
+// whose body is at the current loop depth.
+func (e *escape) loopVar(n ir.Node) {
+	if n == nil || ir.IsBlank(n) {
+		return
+	}
+	if n, ok := n.(*ir.Name); ok && !n.AutoTemp() {
+		e.oldLoc(n).iterDepth = e.loopDepth
+	}
+}
+
+// outlivesIteration reports whether values stored in root may
+// survive beyond an iteration of the loop that declares the loop
+// variable l.
+func (b *batch) outlivesIteration(root, l *location) bool {
+	if b.outlives(root, l) {
+		return true
+	}
+	return root.curfn == l.curfn && root.loopDepth < l.iterDepth
+}
+
+// aliasPos returns the position of the expression that takes the
+// address of the loop variable n, or captures it in a closure, given
+// the notes on the edge through which its address flows.
+func aliasPos(notes *note, n ir.Node) src.XPos {
+	for note := notes; note != nil; note = note.next {
+		if note.why == "address-of" || note.why == "captured by a closure" {
+			return note.where.Pos()
+		}
+	}
+	return n.Pos()
+}
+
+func reportLoopAlias(loc *location) {
+	if base.Debug.LoopAlias == 0 || loc.curfn.Wrapper() {
+		return
+	}
+	base.WarnfAt(loc.aliasPos, "loop variable %v is shared by all iterations, but a reference to it outlives the iteration", loc.n)
+}
+
+// checkRangeAppend reports appends to the slice that n ranges over.
+// The range expression is evaluated once, so the loop does not visit
+// the appended elements.
+func checkRangeAppend(n *ir.RangeStmt) {
+	s, ok := n.X.(*ir.Name)
+	if !ok || !s.Type().IsSlice() {
+		return
+	}
+	ir.VisitList(n.Body, func(n ir.Node) {
+		if n.Op() != ir.OAS {
+			return
+		}
+		as := n.(*ir.AssignStmt)
+		if as.X != s || as.Y == nil || as.Y.Op() != ir.OAPPEND {
+			return
+		}
+		if call := as.Y.(*ir.CallExpr); len(call.Args) > 0 && call.Args[0] == s {
+			base.WarnfAt(as.Pos(), "append to %v, which is being ranged over; the loop does not visit the appended elements", s)
+		}
+	})
+}
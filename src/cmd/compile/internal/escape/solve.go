@@ -86,6 +86,10 @@ func (b *batch) walkOne(root *location, walkgen uint32, enqueue func(*location))
 			}
 		}
 
+		if addressOf && l.iterDepth > 0 && !l.aliasPos.IsKnown() && b.outlivesIteration(root, l) {
+			l.aliasPos = aliasPos(l.dst.edges[l.dstEdgeIdx].notes, l.n)
+		}
+
 		if b.outlives(root, l) {
 			// l's value flows to root. If l is a function
 			// parameter and root is the heap or a
@@ -97,7 +101,7 @@ func (b *batch) walkOne(root *location, walkgen uint32, enqueue func(*location))
 					if base.Flag.LowerM >= 2 {
 						fmt.Printf("%s: parameter %v leaks to %s with derefs=%d:\n", base.FmtPos(l.n.Pos()), l.n, b.explainLoc(root), derefs)
 					}
-					explanation := b.explainPath(root, l)
+					explanation := b.explainPath(root, l, nil)
 					if logopt.Enabled() {
 						var e_curfn *ir.Func // TODO(mdempsky): Fix.
 						logopt.LogOpt(l.n.Pos(), "leak", "escape", ir.FuncName(e_curfn),
@@ -111,15 +115,22 @@ func (b *batch) walkOne(root *location, walkgen uint32, enqueue func(*location))
 			// outlives it, then l needs to be heap
 			// allocated.
 			if addressOf && !l.escapes {
-				if logopt.Enabled() || base.Flag.LowerM >= 2 {
+				if logopt.Enabled() || base.Flag.LowerM >= 2 || base.Debug.NoAllocPackage != 0 {
 					if base.Flag.LowerM >= 2 {
 						fmt.Printf("%s: %v escapes to heap:\n", base.FmtPos(l.n.Pos()), l.n)
 					}
-					explanation := b.explainPath(root, l)
+					var desc *strings.Builder
+					if base.Debug.NoAllocPackage != 0 {
+						desc = new(strings.Builder)
+					}
+					explanation := b.explainPath(root, l, desc)
 					if logopt.Enabled() {
 						var e_curfn *ir.Func // TODO(mdempsky): Fix.
 						logopt.LogOpt(l.n.Pos(), "escape", "escape", ir.FuncName(e_curfn), fmt.Sprintf("%v escapes to heap", l.n), explanation)
 					}
+					if desc != nil {
+						l.heapPath = desc.String()
+					}
 				}
 				l.escapes = true
 				enqueue(l)
@@ -144,7 +155,9 @@ func (b *batch) walkOne(root *location, walkgen uint32, enqueue func(*location))
 }
 
 // explainPath prints an explanation of how src flows to the walk root.
-func (b *batch) explainPath(root, src *location) []*logopt.LoggedOpt {
+// If desc is not nil, it also writes the explanation to desc, as
+// tab-indented lines to be appended to an error message.
+func (b *batch) explainPath(root, src *location, desc *strings.Builder) []*logopt.LoggedOpt {
 	visited := make(map[*location]bool)
 	pos := base.FmtPos(src.n.Pos())
 	var explanation []*logopt.LoggedOpt
@@ -163,7 +176,7 @@ func (b *batch) explainPath(root, src *location) []*logopt.LoggedOpt {
 			base.Fatalf("path inconsistency: %v != %v", edge.src, src)
 		}
 
-		explanation = b.explainFlow(pos, dst, src, edge.derefs, edge.notes, explanation)
+		explanation = b.explainFlow(pos, dst, src, edge.derefs, edge.notes, explanation, desc)
 
 		if dst == root {
 			break
@@ -174,16 +187,19 @@ func (b *batch) explainPath(root, src *location) []*logopt.LoggedOpt {
 	return explanation
 }
 
-func (b *batch) explainFlow(pos string, dst, srcloc *location, derefs int, notes *note, explanation []*logopt.LoggedOpt) []*logopt.LoggedOpt {
+func (b *batch) explainFlow(pos string, dst, srcloc *location, derefs int, notes *note, explanation []*logopt.LoggedOpt, desc *strings.Builder) []*logopt.LoggedOpt {
 	ops := "&"
 	if derefs >= 0 {
 		ops = strings.Repeat("*", derefs)
 	}
 	print := base.Flag.LowerM >= 2
 
-	flow := fmt.Sprintf("   flow: %s = %s%v:", b.explainLoc(dst), ops, b.explainLoc(srcloc))
+	flow := fmt.Sprintf("flow: %s = %s%v:", b.explainLoc(dst), ops, b.explainLoc(srcloc))
 	if print {
-		fmt.Printf("%s:%s\n", pos, flow)
+		fmt.Printf("%s:   %s\n", pos, flow)
+	}
+	if desc != nil {
+		fmt.Fprintf(desc, "\n\t%s", flow)
 	}
 	if logopt.Enabled() {
 		var epos src.XPos
@@ -193,13 +209,16 @@ func (b *batch) explainFlow(pos string, dst, srcloc *location, derefs int, notes
 			epos = srcloc.n.Pos()
 		}
 		var e_curfn *ir.Func // TODO(mdempsky): Fix.
-		explanation = append(explanation, logopt.NewLoggedOpt(epos, "escflow", "escape", ir.FuncName(e_curfn), flow))
+		explanation = append(explanation, logopt.NewLoggedOpt(epos, "escflow", "escape", ir.FuncName(e_curfn), "   "+flow))
 	}
 
 	for note := notes; note != nil; note = note.next {
 		if print {
 			fmt.Printf("%s:     from %v (%v) at %s\n", pos, note.where, note.why, base.FmtPos(note.where.Pos()))
 		}
+		if desc != nil {
+			fmt.Fprintf(desc, "\n\t  from %v (%v) at %s", note.where, note.why, base.FmtPos(note.where.Pos()))
+		}
 		if logopt.Enabled() {
 			var e_curfn *ir.Func // TODO(mdempsky): Fix.
 			explanation = append(explanation, logopt.NewLoggedOpt(note.where.Pos(), "escflow", "escape", ir.FuncName(e_curfn),
@@ -256,7 +275,10 @@ func (b *batch) outlives(l, other *location) bool {
 	//    for {
 	//        l = new(int)
 	//    }
-	if l.curfn == other.curfn && l.loopDepth < other.loopDepth {
+	//
+	// unless l's values never survive an iteration of that loop
+	// (see iterationLocals).
+	if l.curfn == other.curfn && l.loopDepth < other.loopDepth && l.killDepth < other.loopDepth {
 		return true
 	}
 
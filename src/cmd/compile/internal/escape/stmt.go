@@ -74,8 +74,23 @@ func (e *escape) stmt(n ir.Node) {
 	case ir.OFOR, ir.OFORUNTIL:
 		n := n.(*ir.ForStmt)
 		e.loopDepth++
+		for _, init := range n.Init() {
+			switch init.Op() {
+			case ir.OAS:
+				if init := init.(*ir.AssignStmt); init.Def {
+					e.loopVar(init.X)
+				}
+			case ir.OAS2:
+				if init := init.(*ir.AssignListStmt); init.Def {
+					for _, x := range init.Lhs {
+						e.loopVar(x)
+					}
+				}
+			}
+		}
 		e.discard(n.Cond)
 		e.stmt(n.Post)
+		e.iterationLocals(n.Body)
 		e.block(n.Body)
 		e.loopDepth--
 
@@ -88,6 +103,13 @@ func (e *escape) stmt(n ir.Node) {
 		e.expr(tmp.asHole(), n.X)
 
 		e.loopDepth++
+		if n.Def {
+			e.loopVar(n.Key)
+			e.loopVar(n.Value)
+		}
+		if base.Debug.LoopAlias != 0 {
+			checkRangeAppend(n)
+		}
 		ks := e.addrs([]ir.Node{n.Key, n.Value})
 		if n.X.Type().IsArray() {
 			e.flow(ks[1].note(n, "range"), tmp)
@@ -96,6 +118,7 @@ func (e *escape) stmt(n ir.Node) {
 		}
 		e.reassigned(ks, n)
 
+		e.iterationLocals(n.Body)
 		e.block(n.Body)
 		e.loopDepth--
 
@@ -186,11 +209,52 @@ func (e *escape) stmt(n ir.Node) {
 }
 
 func (e *escape) stmts(l ir.Nodes) {
-	for _, n := range l {
+	for i, n := range l {
+		if n != nil && n.Op() == ir.ODEFER && e.loopDepth > 1 && exitsWithoutLooping(l[i+1:]) {
+			if e.onceDefers == nil {
+				e.onceDefers = make(map[*ir.GoDeferStmt]bool)
+			}
+			e.onceDefers[n.(*ir.GoDeferStmt)] = true
+		}
 		e.stmt(n)
 	}
 }
 
+// exitsWithoutLooping reports whether the statement list l always
+// leaves the function (via return or panic) without branching, so
+// that a defer statement immediately preceding l executes at most
+// once per call even when it appears within a loop. For example:
+//
+//	for _, x := range xs {
+//		if x.ok {
+//			mu.Lock()
+//			defer mu.Unlock()
+//			return x.do()
+//		}
+//	}
+func exitsWithoutLooping(l ir.Nodes) bool {
+	for _, n := range l {
+		if n == nil {
+			continue
+		}
+		branches := ir.Any(n, func(n ir.Node) bool {
+			switch n.Op() {
+			case ir.OBREAK, ir.OCONTINUE, ir.OGOTO, ir.OLABEL:
+				return true
+			}
+			return false
+		})
+		if branches {
+			return false
+		}
+		switch n.Op() {
+		case ir.ORETURN, ir.OTAILCALL, ir.OPANIC:
+			return true
+		}
+	}
+	return false
+}
+
 // block is like stmts, but preserves loopDepth.
 func (e *escape) block(l ir.Nodes) {
 	old := e.loopDepth
@@ -5,6 +5,7 @@
 package escape
 
 import (
+	"cmd/compile/internal/base"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/typecheck"
 )
@@ -167,6 +168,53 @@ func mayAffectMemory(n ir.Node) bool {
 	}
 }
 
+// tooLarge is the reason HeapAllocReason gives for allocations that
+// are too large for the stack frame.
+const tooLarge = "too large for stack"
+
+// FuncHeapAllocReason is like HeapAllocReason for the allocation n in
+// fn, except that allocations too large for the stack frame stay on
+// the stack if fn is marked //go:stacklocals.
+func FuncHeapAllocReason(fn *ir.Func, n ir.Node) string {
+	why := HeapAllocReason(n)
+	if why == tooLarge && fn != nil && fn.Pragma&ir.StackLocals != 0 {
+		return ""
+	}
+	return why
+}
+
+// reportTooLarge reports that n is heap allocated because it is too
+// large for fn's stack frame, as an error if fn is marked
+// //go:noheaplocals and otherwise if -d=largelocals is set.
+func reportTooLarge(fn *ir.Func, n ir.Node) {
+	switch {
+	case fn.Pragma&ir.NoHeapLocals != 0:
+		base.ErrorfAt(n.Pos(), "%v is too large for stack (%d bytes) in //go:noheaplocals function %v", n, allocSize(n), fn)
+	case base.Debug.LargeLocals != 0:
+		base.WarnfAt(n.Pos(), "%v moved to heap: too large for stack (%d bytes)", n, allocSize(n))
+	}
+}
+
+// allocSize returns the number of bytes n allocates.
+func allocSize(n ir.Node) int64 {
+	switch n.Op() {
+	case ir.ONEW, ir.OPTRLIT:
+		return n.Type().Elem().Size()
+	case ir.OCLOSURE:
+		return typecheck.ClosureType(n.(*ir.ClosureExpr)).Size()
+	case ir.OMETHVALUE:
+		return typecheck.MethodValueType(n.(*ir.SelectorExpr)).Size()
+	case ir.OMAKESLICE:
+		n := n.(*ir.MakeExpr)
+		r := n.Cap
+		if r == nil {
+			r = n.Len
+		}
+		return ir.Int64Val(r) * n.Type().Elem().Size()
+	}
+	return n.Type().Size()
+}
+
 // HeapAllocReason returns the reason the given Node must be heap
 // allocated, or the empty string if it doesn't.
 func HeapAllocReason(n ir.Node) string {
@@ -183,18 +231,18 @@ func HeapAllocReason(n ir.Node) string {
 	}
 
 	if n.Type().Size() > ir.MaxStackVarSize {
-		return "too large for stack"
+		return tooLarge
 	}
 
 	if (n.Op() == ir.ONEW || n.Op() == ir.OPTRLIT) && n.Type().Elem().Size() > ir.MaxImplicitStackVarSize {
-		return "too large for stack"
+		return tooLarge
 	}
 
 	if n.Op() == ir.OCLOSURE && typecheck.ClosureType(n.(*ir.ClosureExpr)).Size() > ir.MaxImplicitStackVarSize {
-		return "too large for stack"
+		return tooLarge
 	}
 	if n.Op() == ir.OMETHVALUE && typecheck.MethodValueType(n.(*ir.SelectorExpr)).Size() > ir.MaxImplicitStackVarSize {
-		return "too large for stack"
+		return tooLarge
 	}
 
 	if n.Op() == ir.OMAKESLICE {
@@ -207,7 +255,7 @@ func HeapAllocReason(n ir.Node) string {
 			return "non-constant size"
 		}
 		if t := n.Type(); t.Elem().Size() != 0 && ir.Int64Val(r) > ir.MaxImplicitStackVarSize/t.Elem().Size() {
-			return "too large for stack"
+			return tooLarge
 		}
 	}
 
@@ -0,0 +1,298 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package escape
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+)
+
+// zeroCopyConversions rewrites []byte/string conversions within fn
+// that don't need to copy their operand into the corresponding
+// zero-copy operations, OBYTES2STRTMP and OSTR2BYTESTMP. It only
+// considers conversions involving local []byte variables b that are
+// never address-taken, captured by a closure or otherwise aliased,
+// and applies these rules:
+//
+// string(b) does not copy if b's backing array is owned by b (b is
+// only ever assigned from make, append(b, ...), b[i:j] or a
+// conversion) and the conversion is the last use of b, so b's bytes
+// can't be modified afterwards.
+//
+// b := []byte(s) does not copy if b is never assigned otherwise and
+// b's bytes are never modified, through indexing, copy or append.
+//
+// The []byte(s) rewrite must happen before escape analysis proper,
+// which treats the zero-copy conversions as aliasing their operands.
+// The string(b) conversions that qualify are returned instead, and
+// rewritten by finishBytesConvs once escape analysis is done.
+func zeroCopyConversions(fn *ir.Func) []bytesConv {
+	if base.Flag.N != 0 {
+		return nil
+	}
+
+	// Backward gotos could re-execute code after a conversion.
+	if ir.Any(fn, func(n ir.Node) bool { return n.Op() == ir.OLABEL || n.Op() == ir.OGOTO }) {
+		return nil
+	}
+
+	type bytesVar struct {
+		refs     int            // all references
+		known    int            // references in recognized contexts
+		owned    bool           // backing array is only reachable through b
+		written  bool           // b's bytes may be modified
+		assigns  []ir.Node      // right-hand sides of assignments to b
+		convs    []*ir.ConvExpr // string(b) conversions
+		captured bool           // captured by a closure, or pointed into
+	}
+	vars := make(map[*ir.Name]*bytesVar)
+	for _, n := range fn.Dcl {
+		if n.Class == ir.PAUTO && !n.Addrtaken() && isBytes(n.Type()) {
+			vars[n] = &bytesVar{owned: true}
+		}
+	}
+	if len(vars) == 0 {
+		return nil
+	}
+
+	lookup := func(n ir.Node) *bytesVar {
+		if n == nil || n.Op() != ir.ONAME {
+			return nil
+		}
+		return vars[n.(*ir.Name)]
+	}
+	markWritten := func(lhs ...ir.Node) {
+		for _, l := range lhs {
+			if l != nil && l.Op() == ir.OINDEX {
+				if v := lookup(l.(*ir.IndexExpr).X); v != nil {
+					v.written = true
+				}
+			}
+		}
+	}
+
+	ir.Visit(fn, func(n ir.Node) {
+		switch n.Op() {
+		case ir.ONAME:
+			if v := lookup(n); v != nil {
+				v.refs++
+			}
+
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				if v := vars[cv.Canonical()]; v != nil {
+					v.captured = true
+				}
+			}
+
+		case ir.ODCL:
+			if v := lookup(n.(*ir.Decl).X); v != nil {
+				v.known++
+			}
+
+		case ir.OAS:
+			n := n.(*ir.AssignStmt)
+			markWritten(n.X)
+			v := lookup(n.X)
+			if v == nil {
+				break
+			}
+			v.known++
+			v.assigns = append(v.assigns, n.Y)
+			switch y := n.Y; {
+			case y == nil || y.Op() == ir.ONIL || y.Op() == ir.OMAKESLICE || y.Op() == ir.OSTR2BYTES:
+			case y.Op() == ir.OAPPEND && y.(*ir.CallExpr).Args[0] == n.X:
+				v.known++
+			case y.Op() == ir.OSLICE && y.(*ir.SliceExpr).X == n.X:
+				v.known++
+			default:
+				v.owned = false
+			}
+		case ir.OASOP:
+			markWritten(n.(*ir.AssignOpStmt).X)
+		case ir.OAS2, ir.OAS2DOTTYPE, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2RECV, ir.OSELRECV2:
+			markWritten(n.(*ir.AssignListStmt).Lhs...)
+		case ir.ORANGE:
+			n := n.(*ir.RangeStmt)
+			markWritten(n.Key, n.Value)
+			if v := lookup(n.X); v != nil {
+				v.known++
+			}
+
+		case ir.OLEN, ir.OCAP:
+			if v := lookup(n.(*ir.UnaryExpr).X); v != nil {
+				v.known++
+			}
+		case ir.OINDEX:
+			if v := lookup(n.(*ir.IndexExpr).X); v != nil {
+				v.known++
+			}
+		case ir.OADDR:
+			// &b[i] can be used to modify b's bytes at any time.
+			if x := n.(*ir.AddrExpr).X; x.Op() == ir.OINDEX {
+				if v := lookup(x.(*ir.IndexExpr).X); v != nil {
+					v.captured = true
+				}
+			}
+		case ir.OCOPY:
+			n := n.(*ir.BinaryExpr)
+			if v := lookup(n.X); v != nil {
+				v.known++
+				v.written = true
+			}
+			if v := lookup(n.Y); v != nil {
+				v.known++
+			}
+		case ir.OAPPEND:
+			n := n.(*ir.CallExpr)
+			if v := lookup(n.Args[0]); v != nil {
+				v.written = true
+			}
+			if n.IsDDD {
+				if v := lookup(n.Args[len(n.Args)-1]); v != nil {
+					v.known++
+				}
+			}
+		case ir.OBYTES2STR:
+			n := n.(*ir.ConvExpr)
+			if v := lookup(n.X); v != nil {
+				v.known++
+				v.convs = append(v.convs, n)
+			}
+		}
+	})
+
+	// isLastUse reports whether conv is the only reference to b
+	// within the last top-level statement of fn that refers to b.
+	isLastUse := func(b *ir.Name, conv *ir.ConvExpr) bool {
+		for i := len(fn.Body) - 1; i >= 0; i-- {
+			refs, found := 0, false
+			ir.Visit(fn.Body[i], func(n ir.Node) {
+				switch n {
+				case b:
+					refs++
+				case conv:
+					found = true
+				}
+			})
+			if refs != 0 {
+				return refs == 1 && found
+			}
+		}
+		return false
+	}
+
+	var convs []bytesConv
+	for b, v := range vars {
+		if v.captured || v.refs != v.known {
+			if base.Flag.LowerM > 1 && len(v.convs) != 0 {
+				base.WarnfAt(v.convs[0].Pos(), "%v copies: %v may be aliased", v.convs[0], b)
+			}
+			continue
+		}
+
+		// []byte(s) conversions.
+		if !v.written && len(v.assigns) == 1 && v.assigns[0] != nil && v.assigns[0].Op() == ir.OSTR2BYTES {
+			conv := v.assigns[0].(*ir.ConvExpr)
+			if base.Flag.LowerM != 0 {
+				base.WarnfAt(conv.Pos(), "%v does not copy: %v is never modified", conv, b)
+			}
+			conv.SetOp(ir.OSTR2BYTESTMP)
+		}
+
+		// string(b) conversions.
+		if len(v.convs) != 1 {
+			continue
+		}
+		conv := v.convs[0]
+		switch {
+		case !v.owned:
+			if base.Flag.LowerM > 1 {
+				base.WarnfAt(conv.Pos(), "%v copies: %v may share its backing array", conv, b)
+			}
+		case !isLastUse(b, conv):
+			if base.Flag.LowerM > 1 {
+				base.WarnfAt(conv.Pos(), "%v copies: %v is used afterwards", conv, b)
+			}
+		default:
+			convs = append(convs, bytesConv{conv, b, v.assigns})
+		}
+	}
+	return convs
+}
+
+// A bytesConv is a string(b) conversion that doesn't need to copy
+// b's bytes, as far as b's uses are concerned.
+type bytesConv struct {
+	conv *ir.ConvExpr
+	b    *ir.Name
+	srcs []ir.Node // right-hand sides of assignments to b
+}
+
+// finishBytesConvs rewrites the string(b) conversions found by
+// zeroCopyConversions into OBYTES2STRTMP, now that escape analysis
+// has decided where each of them and b's backing arrays live.
+//
+// The conversions were analyzed as copies. A conversion whose result
+// doesn't escape can share b's backing array wherever that lives.
+// One whose result escapes can only share a backing array that is
+// heap allocated anyway; moving b's array to the heap instead of
+// copying could allocate more than the copy, as b's capacity may
+// exceed its length.
+func (b *batch) finishBytesConvs() {
+	if len(b.bytesConvs) == 0 {
+		return
+	}
+
+	escapes := make(map[ir.Node]bool)
+	for _, loc := range b.allLocs {
+		if loc.n != nil {
+			escapes[loc.n] = loc.escapes
+		}
+	}
+
+	for _, c := range b.bytesConvs {
+		if escapes[c.conv] {
+			if src := stackBacking(c.srcs, escapes); src != nil {
+				if base.Flag.LowerM > 1 {
+					base.WarnfAt(c.conv.Pos(), "%v copies: %v may not be heap allocated", c.conv, src)
+				}
+				continue
+			}
+		}
+		if base.Flag.LowerM != 0 {
+			base.WarnfAt(c.conv.Pos(), "%v does not copy: %v is not used afterwards", c.conv, c.b)
+		}
+		c.conv.SetOp(ir.OBYTES2STRTMP)
+	}
+	b.bytesConvs = nil
+}
+
+// stackBacking returns the first of srcs, the right-hand sides of
+// assignments to a []byte variable, that may provide a backing array
+// that isn't heap allocated, or nil if there is none.
+func stackBacking(srcs []ir.Node, escapes map[ir.Node]bool) ir.Node {
+	for _, src := range srcs {
+		switch {
+		case src == nil || src.Op() == ir.ONIL || src.Op() == ir.OAPPEND || src.Op() == ir.OSLICE:
+			// No backing array, or the same one as another assignment.
+		case src.Op() == ir.OMAKESLICE || src.Op() == ir.OSTR2BYTES:
+			if !escapes[src] {
+				return src
+			}
+		default:
+			// OSTR2BYTESTMP shares a string's bytes, which may be
+			// a temporary on the stack.
+			return src
+		}
+	}
+	return nil
+}
+
+// isBytes reports whether t is a []byte type.
+func isBytes(t *types.Type) bool {
+	return t.IsSlice() && t.Elem().Kind() == types.TUINT8
+}
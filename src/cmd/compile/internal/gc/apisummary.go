@@ -0,0 +1,186 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/bio"
+)
+
+// dumpapisummary writes the exported API of the package to the
+// -apisummary file, one feature per line, sorted, in a format like
+// that of the api/*.txt files checked by cmd/api:
+//
+//	pkg p, const C = 1
+//	pkg p, const C untyped int
+//	pkg p, func F(int) (string, error)
+//	pkg p, method (*T) M() bool
+//	pkg p, type T struct
+//	pkg p, type T struct, X int
+//	pkg p, type I interface { M, unexported methods }
+//	pkg p, type I interface, M() bool
+//	pkg p, var V []byte
+//
+// Comparing the summaries of two builds of a package then reports
+// its API changes, without typechecking it again.
+func dumpapisummary() {
+	var features []string
+	var tparams []*types.Type // of the declaration being summarized
+	add := func(format string, args ...interface{}) {
+		s := fmt.Sprintf(format, args...)
+		// Type parameters are printed qualified by the name of
+		// their function or type, as in F.T; drop the qualifier.
+		for _, tp := range tparams {
+			name := tp.Sym().Name
+			s = strings.ReplaceAll(s, name, name[strings.LastIndex(name, ".")+1:])
+		}
+		features = append(features, fmt.Sprintf("pkg %s, %s", base.Ctxt.Pkgpath, s))
+	}
+
+	for _, n := range typecheck.Target.Exports {
+		name := n.Sym().Name
+		if !types.IsExported(name) {
+			continue
+		}
+		switch n.Op() {
+		case ir.OLITERAL:
+			add("const %s = %v", name, n.Val())
+			add("const %s %v", name, n.Type())
+
+		case ir.ONAME:
+			if n.Class == ir.PFUNC {
+				tparams = funcTypeParams(n.Type())
+				add("func %s%s", name, apiSignature(n.Type()))
+			} else {
+				add("var %s %v", name, n.Type())
+			}
+			tparams = nil
+
+		case ir.OTYPE:
+			t := n.Type()
+			if n.Alias() {
+				add("type %s = %v", name, t)
+				continue
+			}
+			tparams = t.RParams()
+			typ := "type " + name + apiTypeParams(tparams)
+			switch {
+			case t.IsStruct():
+				add("%s struct", typ)
+				for _, f := range t.Fields().Slice() {
+					switch {
+					case f.Embedded != 0:
+						add("%s struct, embedded %v", typ, f.Type)
+					case types.IsExported(f.Sym.Name):
+						add("%s struct, %s %v", typ, f.Sym.Name, f.Type)
+					}
+				}
+
+			case t.IsInterface():
+				var names []string
+				unexported := false
+				for _, m := range t.AllMethods().Slice() {
+					if !types.IsExported(m.Sym.Name) {
+						unexported = true
+						continue
+					}
+					names = append(names, m.Sym.Name)
+					add("%s interface, %s%s", typ, m.Sym.Name, apiSignature(m.Type))
+				}
+				if unexported {
+					names = append(names, "unexported methods")
+				}
+				add("%s interface { %s }", typ, strings.Join(names, ", "))
+				tparams = nil
+				continue
+
+			default:
+				add("%s %L", typ, t)
+			}
+
+			for _, m := range t.Methods().Slice() {
+				if !types.IsExported(m.Sym.Name) {
+					continue
+				}
+				recv := m.Type.Recv().Type
+				if recv.IsPtr() {
+					tparams = recv.Elem().RParams()
+				} else {
+					tparams = recv.RParams()
+				}
+				if recv.IsPtr() {
+					add("method (*%s) %s%s", name, m.Sym.Name, apiSignature(m.Type))
+				} else {
+					add("method (%s) %s%s", name, m.Sym.Name, apiSignature(m.Type))
+				}
+			}
+			tparams = nil
+		}
+	}
+	sort.Strings(features)
+
+	b, err := bio.Create(base.Flag.APISummary)
+	if err != nil {
+		base.Fatalf("%v", err)
+	}
+	for _, f := range features {
+		fmt.Fprintln(b, f)
+	}
+	b.Close()
+}
+
+// apiSignature returns the signature of the function type t, without
+// the leading "func" or the receiver.
+func apiSignature(t *types.Type) string {
+	s := fmt.Sprintf("%S", t)
+	if t.NumTParams() > 0 {
+		// %S omits the type parameter names.
+		s = apiTypeParams(funcTypeParams(t)) + fmt.Sprintf("%S", t.Params())
+		switch t.NumResults() {
+		case 0:
+		case 1:
+			s += fmt.Sprintf(" %v", t.Results().Field(0).Type)
+		default:
+			s += fmt.Sprintf(" %S", t.Results())
+		}
+	}
+	return s
+}
+
+// apiTypeParams returns the type parameter list of tparams, with their
+// constraints, or "" if there are none.
+func apiTypeParams(tparams []*types.Type) string {
+	if len(tparams) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, tp := range tparams {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%v %v", tp, tp.Bound())
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// funcTypeParams returns the type parameters of the function type t.
+func funcTypeParams(t *types.Type) []*types.Type {
+	var tparams []*types.Type
+	if t.NumTParams() > 0 {
+		for _, f := range t.TParams().FieldSlice() {
+			tparams = append(tparams, f.Type)
+		}
+	}
+	return tparams
+}
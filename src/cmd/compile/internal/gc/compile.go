@@ -89,6 +89,7 @@ func prepareFunc(fn *ir.Func) {
 
 	typecheck.DeclContext = ir.PAUTO
 	ir.CurFunc = fn
+	ssagen.InsertCPUDispatch(fn)
 	walk.Walk(fn)
 	ir.CurFunc = nil // enforce no further uses of CurFunc
 	typecheck.DeclContext = ir.PEXTERN
@@ -0,0 +1,122 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"internal/buildcfg"
+	"io"
+	"os"
+	"sort"
+
+	"cmd/compile/internal/base"
+	"cmd/internal/objabi"
+)
+
+// inputsFlags lists how the flags that name files, or that cannot
+// change the output, contribute to the inputs digest: true means by
+// the contents of the named file, and false not at all. The other
+// flags contribute their values.
+var inputsFlags = map[string]bool{
+	"pgoprofile": true,
+	"symabis":    true,
+
+	"D":              false,
+	"I":              false,
+	"apisummary":     false,
+	"asmhdr":         false,
+	"bench":          false,
+	"blockprofile":   false,
+	"buildid":        false,
+	"c":              false,
+	"cpuprofile":     false,
+	"embedcfg":       false, // the embedded files are hashed below
+	"importcfg":      false, // the imported packages are hashed below
+	"importmap":      false,
+	"installsuffix":  false,
+	"json":           false,
+	"linknameallow":  false,
+	"linknamelog":    false,
+	"linkobj":        false,
+	"memprofile":     false,
+	"memprofilerate": false,
+	"mutexprofile":   false,
+	"o":              false,
+	"traceprofile":   false,
+	"trimpath":       false, // the source files are hashed with trimmed names
+}
+
+var inputs string
+
+// inputsDigest returns a SHA-256 hash of everything the object file
+// written by the compiler depends on: the toolchain version and
+// configuration, the flags, the source and embedded files, and the
+// fingerprints of the imported packages. It is recorded in the object
+// header, where the linker can read it back with -buildinputs.
+//
+// Flags that only name output or temporary files, and the absolute
+// paths of the source files unless they are also recorded in the
+// output, are not part of the digest, so that it is the same for all
+// builds that produce the same object file.
+func inputsDigest() string {
+	if inputs != "" {
+		return inputs
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s\n", buildcfg.Version)
+	fmt.Fprintf(h, "target %s/%s\n", buildcfg.GOOS, buildcfg.GOARCH)
+	fmt.Fprintf(h, "experiment %s\n", buildcfg.GOEXPERIMENT())
+	fmt.Fprintf(h, "package %s\n", base.Ctxt.Pkgpath)
+
+	flag.Visit(func(f *flag.Flag) {
+		hashFile, ok := inputsFlags[f.Name]
+		switch {
+		case !ok:
+			fmt.Fprintf(h, "flag %s=%s\n", f.Name, f.Value)
+		case hashFile:
+			fmt.Fprintf(h, "flag %s %x\n", f.Name, fileHash(f.Value.String()))
+		}
+	})
+	fmt.Fprintf(h, "debug %+v\n", base.Debug)
+
+	for _, file := range flag.Args() {
+		fmt.Fprintf(h, "file %s %x\n", objabi.AbsFile("", file, base.Flag.TrimPath), fileHash(file))
+	}
+
+	var embeds []string
+	for name := range base.Flag.Cfg.Embed.Files {
+		embeds = append(embeds, name)
+	}
+	sort.Strings(embeds)
+	for _, name := range embeds {
+		fmt.Fprintf(h, "embed %s %x\n", name, fileHash(base.Flag.Cfg.Embed.Files[name]))
+	}
+
+	imports := append(base.Ctxt.Imports[:0:0], base.Ctxt.Imports...)
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Pkg < imports[j].Pkg })
+	for _, imp := range imports {
+		fmt.Fprintf(h, "import %s %x\n", imp.Pkg, imp.Fingerprint)
+	}
+
+	inputs = fmt.Sprintf("%x", h.Sum(nil))
+	return inputs
+}
+
+// fileHash returns the SHA-256 hash of the contents of the named file.
+func fileHash(name string) []byte {
+	f, err := os.Open(name)
+	if err != nil {
+		base.Fatalf("hashing build inputs: %v", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		base.Fatalf("hashing build inputs: %v", err)
+	}
+	return h.Sum(nil)
+}
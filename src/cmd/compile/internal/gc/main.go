@@ -7,21 +7,32 @@ package gc
 import (
 	"bufio"
 	"bytes"
+	"cmd/compile/internal/appendprealloc"
 	"cmd/compile/internal/base"
+	"cmd/compile/internal/chanflags"
 	"cmd/compile/internal/deadcode"
+	"cmd/compile/internal/deadlock"
 	"cmd/compile/internal/devirtualize"
 	"cmd/compile/internal/dwarfgen"
 	"cmd/compile/internal/escape"
 	"cmd/compile/internal/inline"
 	"cmd/compile/internal/ir"
+	"cmd/compile/internal/lockelide"
 	"cmd/compile/internal/logopt"
+	"cmd/compile/internal/loopconcat"
+	"cmd/compile/internal/loopfuse"
 	"cmd/compile/internal/noder"
+	"cmd/compile/internal/pgo"
 	"cmd/compile/internal/pkginit"
 	"cmd/compile/internal/reflectdata"
+	"cmd/compile/internal/soa"
 	"cmd/compile/internal/ssa"
 	"cmd/compile/internal/ssagen"
+	"cmd/compile/internal/staticinit"
 	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
+	"cmd/compile/internal/unassigned"
+	"cmd/compile/internal/unsafeptr"
 	"cmd/internal/dwarf"
 	"cmd/internal/obj"
 	"cmd/internal/objabi"
@@ -36,18 +47,22 @@ import (
 )
 
 func hidePanic() {
+	err := recover()
+	if err == nil {
+		return
+	}
+	if err == "-h" {
+		panic(err)
+	}
 	if base.Debug.Panic == 0 && base.Errors() > 0 {
 		// If we've already complained about things
 		// in the program, don't bother complaining
 		// about a panic too; let the user clean up
 		// the code and try again.
-		if err := recover(); err != nil {
-			if err == "-h" {
-				panic(err)
-			}
-			base.ErrorExit()
-		}
+		base.ErrorExit()
 	}
+	base.WriteCrashReport(base.Pos, fmt.Sprintf("panic: %v", err))
+	panic(err)
 }
 
 // Main parses flags and Go source files specified in the command-line
@@ -64,6 +79,8 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	base.Ctxt.DiagFunc = base.Errorf
 	base.Ctxt.DiagFlush = base.FlushErrors
 	base.Ctxt.Bso = bufio.NewWriter(os.Stdout)
+	base.CrashFunc = crashFunc
+	base.Minimize = minimize
 
 	// UseBASEntries is preferred because it shaves about 2% off build time, but LLDB, dsymutil, and dwarfdump
 	// on Darwin don't support it properly, especially since macOS 10.14 (Mojave).  This is exposed as a flag
@@ -107,7 +124,7 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	// Record flags that affect the build result. (And don't
 	// record flags that don't, since that would cause spurious
 	// changes in the binary.)
-	dwarfgen.RecordFlags("B", "N", "l", "msan", "race", "asan", "shared", "dynlink", "dwarf", "dwarflocationlists", "dwarfbasentries", "smallframes", "spectre")
+	dwarfgen.RecordFlags("B", "N", "l", "msan", "race", "asan", "shared", "dynlink", "dwarf", "dwarflocationlists", "dwarfbasentries", "smallframes", "spectre", "O0", "O1", "O2", "Os")
 
 	if !base.EnableTrace && base.Flag.LowerT {
 		log.Fatalf("compiler not built with support for -t")
@@ -168,6 +185,14 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 		logopt.LogJsonOption(base.Flag.JSON)
 	}
 
+	if base.Flag.PGOProfile != "" {
+		p, err := pgo.Load(base.Flag.PGOProfile)
+		if err != nil {
+			log.Fatalf("-pgoprofile: %v", err)
+		}
+		pgo.Current = p
+	}
+
 	ir.EscFmt = escape.Fmt
 	ir.IsIntrinsicCall = ssagen.IsIntrinsicCall
 	inline.SSADumpInline = ssagen.DumpInline
@@ -177,6 +202,7 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	types.PtrSize = ssagen.Arch.LinkArch.PtrSize
 	types.RegSize = ssagen.Arch.LinkArch.RegSize
 	types.MaxWidth = ssagen.Arch.MAXWIDTH
+	types.CacheLineSize = int64(ssagen.Arch.LinkArch.CacheLineSize)
 
 	typecheck.Target = new(ir.Package)
 
@@ -187,8 +213,19 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	typecheck.InitUniverse()
 	typecheck.InitRuntime()
 
-	// Parse and typecheck input.
-	noder.LoadPackage(flag.Args())
+	// Parse and typecheck input, or read it in as captured by
+	// -d=capture.
+	if base.Debug.Replay != 0 {
+		if flag.NArg() != 1 {
+			base.Fatalf("-d=replay requires exactly one capture file")
+		}
+		noder.Replay(flag.Arg(0))
+	} else {
+		noder.LoadPackage(flag.Args())
+	}
+	if base.Debug.Capture != "" {
+		noder.Capture(base.Debug.Capture)
+	}
 
 	dwarfgen.RecordPackageName()
 
@@ -216,6 +253,20 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 		})
 	}
 
+	// Match the profile to the source, which may have been edited
+	// since it was taken. Must happen before inlining.
+	if pgo.Current != nil {
+		pgo.MatchSource(typecheck.Target.Decls)
+	}
+
+	// Report discarded results of //go:mustuse calls. Must happen
+	// before dead code elimination and inlining.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			typecheck.CheckMustUse(n.(*ir.Func))
+		}
+	}
+
 	// Eliminate some obviously dead code.
 	// Must happen after typechecking.
 	for _, n := range typecheck.Target.Decls {
@@ -224,6 +275,54 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 		}
 	}
 
+	// Report channel operations and locks that block forever, if
+	// requested. Must happen after dead code elimination, and before
+	// lockelide and inlining, which rewrite Lock calls.
+	if base.Debug.Deadlock != 0 {
+		for _, n := range typecheck.Target.Decls {
+			if n.Op() == ir.ODCLFUNC {
+				deadlock.Func(n.(*ir.Func))
+			}
+		}
+	}
+
+	// Store //go:soa slices of structs as a slice per field.
+	// Must happen before inlining and escape analysis.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			soa.Func(n.(*ir.Func))
+		}
+	}
+
+	// Elide the atomic operations of mutexes local to a function.
+	// Must happen before inlining, escape analysis and Addrtaken
+	// computation.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			lockelide.Func(n.(*ir.Func))
+		}
+	}
+
+	// Check unsafe.Pointer conversions, if requested.
+	// Must happen before inlining.
+	if base.Debug.UnsafePtr != 0 {
+		for _, n := range typecheck.Target.Decls {
+			if n.Op() == ir.ODCLFUNC {
+				unsafeptr.Func(n.(*ir.Func))
+			}
+		}
+	}
+
+	// Report reads of unassigned variables, if requested.
+	// Must happen before inlining.
+	if base.Debug.Unassigned != 0 {
+		for _, n := range typecheck.Target.Decls {
+			if n.Op() == ir.ODCLFUNC {
+				unassigned.Func(n.(*ir.Func))
+			}
+		}
+	}
+
 	// Compute Addrtaken for names.
 	// We need to wait until typechecking is done so that when we see &x[i]
 	// we know that x has its address taken if x is an array, but not if x is a slice.
@@ -235,6 +334,17 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	}
 	typecheck.IncrementalAddrtaken = true
 
+	// Report statements that are certain to panic, if requested.
+	// Must happen after dead code elimination and Addrtaken
+	// computation, and before inlining.
+	if base.Debug.StaticPanic != 0 {
+		for _, n := range typecheck.Target.Decls {
+			if n.Op() == ir.ODCLFUNC {
+				typecheck.CheckStaticPanics(n.(*ir.Func))
+			}
+		}
+	}
+
 	if base.Debug.TypecheckInl != 0 {
 		// Typecheck imported function bodies if Debug.l > 1,
 		// otherwise lazily when used or re-exported.
@@ -253,6 +363,10 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	}
 	noder.MakeWrappers(typecheck.Target) // must happen after inlining
 
+	// Fold constant-key lookups into read-only package-level maps.
+	// Must happen after inlining, so all accesses are visible.
+	staticinit.FoldReadOnlyMaps(typecheck.Target.Decls)
+
 	// Devirtualize.
 	for _, n := range typecheck.Target.Decls {
 		if n.Op() == ir.ODCLFUNC {
@@ -261,6 +375,32 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	}
 	ir.CurFunc = nil
 
+	// Rewrite string concatenation loops. Must happen before escape
+	// analysis, so the new buffers can be analyzed.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			loopconcat.Func(n.(*ir.Func))
+		}
+	}
+
+	// Fuse and interchange loops, if enabled. Must happen before
+	// escape analysis, which needs to see the new loops.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			loopfuse.Func(n.(*ir.Func))
+		}
+	}
+
+	// Preallocate slices appended to once per loop iteration.
+	// Must happen before escape analysis, which decides where the
+	// new slices are allocated, and after loop fusion, which would
+	// no longer see the loops as adjacent.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			appendprealloc.Func(n.(*ir.Func))
+		}
+	}
+
 	// Build init task, if needed.
 	if initTask := pkginit.Task(); initTask != nil {
 		typecheck.Export(initTask)
@@ -278,9 +418,33 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	// or else the stack copier will not update it.
 	// Large values are also moved off stack in escape analysis;
 	// because large values may contain pointers, it must happen early.
+	// It must happen after all inlining, so it analyzes the inlined
+	// bodies: an allocation that inlining leaves local to a function
+	// is stack allocated without a second pass. (Wrappers generated
+	// after this have calls inlined and are analyzed on their own; see
+	// reflectdata.methodWrapper.)
 	base.Timer.Start("fe", "escapes")
 	escape.Funcs(typecheck.Target.Decls)
 
+	// Check //go:pure functions. Must happen after inlining and
+	// escape analysis, and before walk.
+	for _, n := range typecheck.Target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			ssagen.CheckPure(n.(*ir.Func))
+		}
+	}
+
+	// Find channel operations that need no nil or closed checks.
+	// Must happen after inlining and escape analysis, and before
+	// removing dead parameters, which renumbers them, and walk.
+	chanflags.Funcs(typecheck.Target.Decls)
+
+	// Remove unused parameters and unread results, then specialize
+	// functions for their constant arguments. Must happen after
+	// inlining and escape analysis, and before walk.
+	ssagen.RemoveDeadParams(typecheck.Target.Decls)
+	ssagen.Specialize(typecheck.Target.Decls)
+
 	// TODO(mdempsky): This is a hack. We need a proper, global work
 	// queue for scheduling function compilation so components don't
 	// need to adjust their behavior depending on when they're called.
@@ -313,6 +477,7 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	base.Timer.AddEvent(fcount, "funcs")
 
 	compileFunctions()
+	ssagen.ComputeEffects()
 
 	if base.Flag.CompilingRuntime {
 		// Write barriers are now known. Check the call graph.
@@ -336,8 +501,12 @@ func Main(archInit func(*ssagen.ArchInfo)) {
 	if base.Flag.AsmHdr != "" {
 		dumpasmhdr()
 	}
+	if base.Flag.APISummary != "" {
+		dumpapisummary()
+	}
 
 	ssagen.CheckLargeStacks()
+	ssagen.CheckNoAlloc()
 	typecheck.CheckFuncStack()
 
 	if len(compilequeue) != 0 {
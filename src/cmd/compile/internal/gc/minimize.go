@@ -0,0 +1,237 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gc
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/syntax"
+	"cmd/internal/src"
+)
+
+// crashFunc returns the function the frontend is working on, for
+// base.WriteCrashReport.
+func crashFunc() (string, src.XPos, string) {
+	fn := ir.CurFunc
+	if fn == nil {
+		return "", src.NoXPos, ""
+	}
+	return ir.FuncName(fn), fn.Pos(), fmt.Sprintf("%+v", fn)
+}
+
+// minimizeTimeout bounds each compile run by minimize, in case removing
+// statements makes the compiler loop rather than crash.
+const minimizeTimeout = time.Minute
+
+// minimize implements -d=minimize. It removes statements from the
+// function declared at pos, in chunks that halve in size until single
+// statements, keeping each removal after which the package still makes
+// the compiler crash. Compiling is done by running the compiler again
+// with the same flags. minimize writes the source file with the
+// remaining statements to a temporary file and returns its name, or ""
+// if the crash could not be reproduced.
+//
+// Any internal compiler error or panic counts as a crash, so the
+// reproducer may crash the compiler in a different way than the
+// original source.
+func minimize(pos src.XPos) string {
+	p := base.Ctxt.PosTable.Pos(pos)
+	filename := p.Filename()
+	files := flag.Args()
+	index := -1
+	for i, file := range files {
+		if file == filename {
+			index = i
+		}
+	}
+	if index < 0 {
+		fmt.Printf("minimize: %s is not a source file of the package\n", filename)
+		return ""
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("minimize: %v\n", err)
+		return ""
+	}
+	stmts, err := minimizeStmts(filename, data, p.Line())
+	if err != nil {
+		fmt.Printf("minimize: %v\n", err)
+		return ""
+	}
+
+	dir, err := ioutil.TempDir("", "compile-minimize")
+	if err != nil {
+		fmt.Printf("minimize: %v\n", err)
+		return ""
+	}
+	defer os.RemoveAll(dir)
+
+	// The compiler's own flags come before the source files, with
+	// the output redirected and minimizing turned off.
+	args := append([]string(nil), os.Args[1:len(os.Args)-len(files)]...)
+	args = append(args, "-d=minimize=-1", "-o", filepath.Join(dir, "out.o"))
+	if base.Flag.LinkObj != "" {
+		args = append(args, "-linkobj", filepath.Join(dir, "link.o"))
+	}
+	args = append(args, files...)
+	args[len(args)-len(files)+index] = filepath.Join(dir, filepath.Base(filename))
+
+	crashes := func(removed []bool) bool {
+		if err := ioutil.WriteFile(args[len(args)-len(files)+index], removeStmts(data, stmts, removed), 0666); err != nil {
+			return false
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), minimizeTimeout)
+		defer cancel()
+		out, _ := exec.CommandContext(ctx, os.Args[0], args...).CombinedOutput()
+		return bytes.Contains(out, []byte("internal compiler error")) || bytes.HasPrefix(out, []byte("panic: "))
+	}
+
+	removed := make([]bool, len(stmts))
+	if !crashes(removed) {
+		fmt.Printf("minimize: compiling %s again did not crash\n", filename)
+		return ""
+	}
+	for chunk := len(stmts); chunk > 0; chunk /= 2 {
+		for i := 0; i < len(stmts); i += chunk {
+			try := append([]bool(nil), removed...)
+			changed := false
+			for j := i; j < i+chunk && j < len(stmts); j++ {
+				changed = changed || !try[j]
+				try[j] = true
+			}
+			if changed && crashes(try) {
+				removed = try
+			}
+		}
+	}
+
+	f, err := ioutil.TempFile("", "compile-crash-*-"+filepath.Base(filename))
+	if err == nil {
+		_, err = f.Write(removeStmts(data, stmts, removed))
+		if err1 := f.Close(); err == nil {
+			err = err1
+		}
+	}
+	if err != nil {
+		fmt.Printf("minimize: %v\n", err)
+		return ""
+	}
+	return f.Name()
+}
+
+// A stmtRange is the range of bytes of a statement in a source file,
+// including the space and comments up to the next statement.
+type stmtRange struct {
+	start, end int
+}
+
+// minimizeStmts returns the ranges of the statements, including those of
+// nested blocks, of the function declared in file whose body contains
+// line, in order of their start.
+func minimizeStmts(filename string, src []byte, line uint) ([]stmtRange, error) {
+	file, err := syntax.Parse(syntax.NewFileBase(filename), bytes.NewReader(src), nil, nil, syntax.AllowGenerics)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	offset := func(pos syntax.Pos) int {
+		return lines[pos.Line()-1] + int(pos.Col()) - 1
+	}
+
+	var stmts []stmtRange
+	var list func(list []syntax.Stmt, end syntax.Pos)
+	var stmt func(s syntax.Stmt)
+	list = func(l []syntax.Stmt, end syntax.Pos) {
+		var nonEmpty []syntax.Stmt
+		for _, s := range l {
+			if _, ok := s.(*syntax.EmptyStmt); !ok {
+				nonEmpty = append(nonEmpty, s)
+			}
+		}
+		for i, s := range nonEmpty {
+			r := stmtRange{start: offset(syntax.StartPos(s)), end: offset(end)}
+			if i+1 < len(nonEmpty) {
+				r.end = offset(syntax.StartPos(nonEmpty[i+1]))
+			}
+			stmts = append(stmts, r)
+			stmt(s)
+		}
+	}
+	stmt = func(s syntax.Stmt) {
+		switch s := s.(type) {
+		case *syntax.BlockStmt:
+			list(s.List, s.Rbrace)
+		case *syntax.LabeledStmt:
+			stmt(s.Stmt)
+		case *syntax.IfStmt:
+			stmt(s.Then)
+			if s.Else != nil {
+				stmt(s.Else)
+			}
+		case *syntax.ForStmt:
+			stmt(s.Body)
+		case *syntax.SwitchStmt:
+			for i, c := range s.Body {
+				end := s.Rbrace
+				if i+1 < len(s.Body) {
+					end = s.Body[i+1].Pos()
+				}
+				list(c.Body, end)
+			}
+		case *syntax.SelectStmt:
+			for i, c := range s.Body {
+				end := s.Rbrace
+				if i+1 < len(s.Body) {
+					end = s.Body[i+1].Pos()
+				}
+				list(c.Body, end)
+			}
+		}
+	}
+
+	for _, decl := range file.DeclList {
+		fn, ok := decl.(*syntax.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		if fn.Pos().Line() <= line && line <= fn.Body.Rbrace.Line() {
+			stmt(fn.Body)
+			return stmts, nil
+		}
+	}
+	return nil, fmt.Errorf("no function declared at line %d of %s", line, filename)
+}
+
+// removeStmts returns src without the statements that are removed.
+func removeStmts(src []byte, stmts []stmtRange, removed []bool) []byte {
+	var buf bytes.Buffer
+	last := 0
+	for i, s := range stmts {
+		if !removed[i] || s.start < last {
+			continue
+		}
+		buf.Write(src[last:s.start])
+		last = s.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
@@ -75,6 +75,7 @@ func printObjHeader(bout *bio.Writer) {
 	if base.Flag.BuildID != "" {
 		fmt.Fprintf(bout, "build id %q\n", base.Flag.BuildID)
 	}
+	fmt.Fprintf(bout, "inputs %s\n", inputsDigest())
 	if types.LocalPkg.Name == "main" {
 		fmt.Fprintf(bout, "main\n")
 	}
@@ -233,7 +234,8 @@ func dumpglobls(externs []ir.Node) {
 	}
 }
 
-// addGCLocals adds gcargs, gclocals, gcregs, and stack object symbols to Ctxt.Data.
+// addGCLocals adds gcargs, gclocals, gcregs, stack object and other funcdata
+// symbols to Ctxt.Data.
 //
 // This is done during the sequential phase after compilation, since
 // global symbols can't be declared during parallel compilation.
@@ -263,6 +265,10 @@ func addGCLocals() {
 			objw.Global(x, int32(len(x.P)), obj.RODATA|obj.DUPOK)
 			x.Set(obj.AttrStatic, true)
 		}
+		if x := fn.PanicInfo; x != nil {
+			objw.Global(x, int32(len(x.P)), obj.RODATA|obj.DUPOK)
+			x.Set(obj.AttrStatic, true)
+		}
 	}
 }
 
@@ -276,7 +282,14 @@ func ggloblnod(nam *ir.Name) {
 	if nam.Type() != nil && !nam.Type().HasPointers() {
 		flags |= obj.NOPTR
 	}
-	base.Ctxt.Globl(s, nam.Type().Size(), flags)
+	size := nam.Type().Size()
+	if nam.Pragma()&ir.CacheAlign != 0 || nam.Type().CacheAligned() {
+		// Give the variable whole cache lines, so that it shares
+		// none with other variables.
+		size = types.RndCacheLine(size)
+		s.NewVarInfo().Align = int32(types.CacheLineSize)
+	}
+	base.Ctxt.Globl(s, size, flags)
 	if nam.LibfuzzerExtraCounter() {
 		s.Type = objabi.SLIBFUZZER_EXTRA_COUNTER
 	}
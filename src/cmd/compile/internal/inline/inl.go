@@ -51,6 +51,9 @@ const (
 
 	inlineBigFunctionNodes   = 5000 // Functions with this many nodes are considered "big".
 	inlineBigFunctionMaxCost = 20   // Max cost of inlinee when inlining into a "big" function.
+
+	// Max cost of an inlinee with -Os or -O1: little more than the call it replaces.
+	inlineSizeMaxBudget = 20
 )
 
 // InlinePackage finds functions that can be inlined and clones them before walk expands them.
@@ -61,12 +64,19 @@ func InlinePackage() {
 			if !recursive || numfns > 1 {
 				// We allow inlining if there is no
 				// recursion, or the recursion cycle is
-				// across more than one function.
+				// across more than one function. Calls
+				// within such a cycle are then inlined like
+				// any other, and mkinlcall's inlMap keeps an
+				// inlined body from going around the cycle
+				// again (issue #29737).
 				CanInline(n)
 			} else {
 				if base.Flag.LowerM > 1 {
 					fmt.Printf("%v: cannot inline %v: recursive\n", ir.Line(n), n.Nname)
 				}
+				if base.Debug.WhyAsm != "" {
+					noInlineReasons[n] = "recursive"
+				}
 			}
 			InlineCalls(n)
 		}
@@ -82,7 +92,7 @@ func CanInline(fn *ir.Func) {
 	}
 
 	var reason string // reason, if any, that the function was not inlined
-	if base.Flag.LowerM > 1 || logopt.Enabled() {
+	if base.Flag.LowerM > 1 || logopt.Enabled() || base.Debug.WhyAsm != "" {
 		defer func() {
 			if reason != "" {
 				if base.Flag.LowerM > 1 {
@@ -91,6 +101,9 @@ func CanInline(fn *ir.Func) {
 				if logopt.Enabled() {
 					logopt.LogOpt(fn.Pos(), "cannotInlineFunction", "inline", ir.FuncName(fn), reason)
 				}
+				if base.Debug.WhyAsm != "" {
+					noInlineReasons[fn] = reason
+				}
 			}
 		}()
 	}
@@ -127,6 +140,13 @@ func CanInline(fn *ir.Func) {
 		return
 	}
 
+	// If marked "go:cpu", don't inline, since inlining would
+	// bypass the dispatch to the function's variants.
+	if fn.Pragma&ir.CPULevels != 0 {
+		reason = "marked go:cpu"
+		return
+	}
+
 	// The nowritebarrierrec checker currently works at function
 	// granularity, so inlining yeswritebarrierrec functions can
 	// confuse it (#22342). As a workaround, disallow inlining
@@ -152,6 +172,21 @@ func CanInline(fn *ir.Func) {
 	}
 	defer n.Func.SetInlinabilityChecked(true)
 
+	maxBudget := int32(inlineMaxBudget)
+	if importedInstantiation(fn) {
+		// Instantiations of generic functions from other packages
+		// are compiled from the bodies in their export data, and
+		// -d=inlimported can limit how much they add to this package.
+		maxBudget = int32(base.Debug.InlImported)
+		if maxBudget <= 0 {
+			reason = "instantiation of generic function from another package (-d=inlimported=0)"
+			return
+		}
+	}
+	if base.SmallCode() && maxBudget > inlineSizeMaxBudget {
+		maxBudget = inlineSizeMaxBudget
+	}
+
 	cc := int32(inlineExtraCallCost)
 	if base.Flag.LowerL == 4 {
 		cc = 1 // this appears to yield better performance than 0.
@@ -167,7 +202,8 @@ func CanInline(fn *ir.Func) {
 	// list. See issue 25249 for more context.
 
 	visitor := hairyVisitor{
-		budget:        inlineMaxBudget,
+		budget:        maxBudget,
+		maxBudget:     maxBudget,
 		extraCallCost: cc,
 	}
 	if visitor.tooHairy(fn) {
@@ -176,7 +212,7 @@ func CanInline(fn *ir.Func) {
 	}
 
 	n.Func.Inl = &ir.Inline{
-		Cost: inlineMaxBudget - visitor.budget,
+		Cost: maxBudget - visitor.budget,
 		Dcl:  pruneUnusedAutos(n.Defn.(*ir.Func).Dcl, &visitor),
 		Body: inlcopylist(fn.Body),
 
@@ -184,12 +220,12 @@ func CanInline(fn *ir.Func) {
 	}
 
 	if base.Flag.LowerM > 1 {
-		fmt.Printf("%v: can inline %v with cost %d as: %v { %v }\n", ir.Line(fn), n, inlineMaxBudget-visitor.budget, fn.Type(), ir.Nodes(n.Func.Inl.Body))
+		fmt.Printf("%v: can inline %v with cost %d as: %v { %v }\n", ir.Line(fn), n, maxBudget-visitor.budget, fn.Type(), ir.Nodes(n.Func.Inl.Body))
 	} else if base.Flag.LowerM != 0 {
 		fmt.Printf("%v: can inline %v\n", ir.Line(fn), n)
 	}
 	if logopt.Enabled() {
-		logopt.LogOpt(fn.Pos(), "canInlineFunction", "inline", ir.FuncName(fn), fmt.Sprintf("cost: %d", inlineMaxBudget-visitor.budget))
+		logopt.LogOpt(fn.Pos(), "canInlineFunction", "inline", ir.FuncName(fn), fmt.Sprintf("cost: %d", maxBudget-visitor.budget))
 	}
 }
 
@@ -229,6 +265,7 @@ func canDelayResults(fn *ir.Func) bool {
 // hairiness and whether or not it can be inlined.
 type hairyVisitor struct {
 	budget        int32
+	maxBudget     int32
 	reason        string
 	extraCallCost int32
 	usedLocals    ir.NameSet
@@ -241,7 +278,7 @@ func (v *hairyVisitor) tooHairy(fn *ir.Func) bool {
 		return true
 	}
 	if v.budget < 0 {
-		v.reason = fmt.Sprintf("function too complex: cost %d exceeds budget %d", inlineMaxBudget-v.budget, inlineMaxBudget)
+		v.reason = fmt.Sprintf("function too complex: cost %d exceeds budget %d", v.maxBudget-v.budget, v.maxBudget)
 		return true
 	}
 	return false
@@ -493,6 +530,9 @@ func InlineCalls(fn *ir.Func) {
 	savefn := ir.CurFunc
 	ir.CurFunc = fn
 	maxCost := int32(inlineMaxBudget)
+	if base.SmallCode() {
+		maxCost = inlineSizeMaxBudget
+	}
 	if isBigFunc(fn) {
 		maxCost = inlineBigFunctionMaxCost
 	}
@@ -561,6 +601,11 @@ func inlnode(n ir.Node, maxCost int32, inlMap map[*ir.Func]bool, edit func(ir.No
 				}
 			}
 		}
+		if ir.IsStaticReflectTypeOf(n) {
+			// Leave reflect.TypeOf on concrete types to walk,
+			// which replaces it with the type descriptor.
+			n.NoInline = true
+		}
 	}
 
 	lno := ir.SetPos(n)
@@ -587,6 +632,10 @@ func inlnode(n ir.Node, maxCost int32, inlMap map[*ir.Func]bool, edit func(ir.No
 		}
 		if fn := inlCallee(call.X); fn != nil && typecheck.HaveInlineBody(fn) {
 			n = mkinlcall(call, fn, maxCost, inlMap, edit)
+		} else if fn != nil {
+			whyAsm(call, "not inlining call to %v: %s", fn, noInlineReason(fn))
+		} else {
+			whyAsm(call, "not inlining call to %v: callee is not statically known", call.X)
 		}
 	}
 
@@ -595,6 +644,33 @@ func inlnode(n ir.Node, maxCost int32, inlMap map[*ir.Func]bool, edit func(ir.No
 	return n
 }
 
+// noInlineReasons records, for -d=whyasm, why functions
+// declared in this package cannot be inlined.
+var noInlineReasons = map[*ir.Func]string{}
+
+// noInlineReason returns why fn cannot be inlined.
+func noInlineReason(fn *ir.Func) string {
+	if reason := noInlineReasons[fn]; reason != "" {
+		return reason
+	}
+	return "no inlinable body"
+}
+
+// whyAsm reports a -d=whyasm message about the call n
+// if ir.CurFunc is the function being explained.
+func whyAsm(n *ir.CallExpr, format string, args ...interface{}) {
+	if ir.IsWhyAsmFunc(ir.CurFunc) {
+		base.WarnfAt(n.Pos(), format, args...)
+	}
+}
+
+// importedInstantiation reports whether fn is an instantiation of a
+// generic function or method declared in another package.
+func importedInstantiation(fn *ir.Func) bool {
+	sym := fn.Sym()
+	return sym.Pkg != types.LocalPkg && strings.Contains(sym.Name, "[")
+}
+
 // inlCallee takes a function-typed expression and returns the underlying function ONAME
 // that it refers to if statically known. Otherwise, it returns nil.
 func inlCallee(fn ir.Node) *ir.Func {
@@ -665,6 +741,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 			logopt.LogOpt(n.Pos(), "cannotInlineCall", "inline", ir.FuncName(ir.CurFunc),
 				fmt.Sprintf("%s cannot be inlined", ir.PkgFuncName(fn)))
 		}
+		whyAsm(n, "not inlining call to %v: %s", fn, noInlineReason(fn))
 		return n
 	}
 	if fn.Inl.Cost > maxCost {
@@ -674,6 +751,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 			logopt.LogOpt(n.Pos(), "cannotInlineCall", "inline", ir.FuncName(ir.CurFunc),
 				fmt.Sprintf("cost %d of %s exceeds max large caller cost %d", fn.Inl.Cost, ir.PkgFuncName(fn), maxCost))
 		}
+		whyAsm(n, "not inlining call to %v: cost %d exceeds max large caller cost %d", fn, fn.Inl.Cost, maxCost)
 		return n
 	}
 
@@ -682,6 +760,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 		if logopt.Enabled() {
 			logopt.LogOpt(n.Pos(), "cannotInlineCall", "inline", fmt.Sprintf("recursive call to %s", ir.FuncName(ir.CurFunc)))
 		}
+		whyAsm(n, "not inlining call to %v: recursive call", fn)
 		return n
 	}
 
@@ -701,6 +780,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 					logopt.LogOpt(n.Pos(), "cannotInlineCall", "inline", ir.FuncName(ir.CurFunc),
 						fmt.Sprintf("inlining non-shape function %v with shape args", ir.FuncName(fn)))
 				}
+				whyAsm(n, "not inlining call to %v: non-shape function with shape args", fn)
 				return n
 			}
 		}
@@ -713,6 +793,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 		// we disable inlining of runtime functions when instrumenting.
 		// The example that we observed is inlining of LockOSThread,
 		// which lead to false race reports on m contents.
+		whyAsm(n, "not inlining call to %v: runtime function in instrumented build", fn)
 		return n
 	}
 
@@ -720,6 +801,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 		if base.Flag.LowerM > 1 {
 			fmt.Printf("%v: cannot inline %v into %v: repeated recursive cycle\n", ir.Line(n), fn, ir.FuncName(ir.CurFunc))
 		}
+		whyAsm(n, "not inlining call to %v: repeated recursive cycle", fn)
 		return n
 	}
 	inlMap[fn] = true
@@ -744,6 +826,7 @@ func mkinlcall(n *ir.CallExpr, fn *ir.Func, maxCost int32, inlMap map[*ir.Func]b
 	if base.Flag.LowerM != 0 {
 		fmt.Printf("%v: inlining call to %v\n", ir.Line(n), fn)
 	}
+	whyAsm(n, "inlining call to %v", fn)
 	if base.Flag.LowerM > 2 {
 		fmt.Printf("%v: Before inlining: %+v\n", ir.Line(n), n)
 	}
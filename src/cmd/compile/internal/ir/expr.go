@@ -1056,6 +1056,21 @@ func IsReflectHeaderDataField(l Node) bool {
 	return tsym.Name == "SliceHeader" || tsym.Name == "StringHeader"
 }
 
+// IsStaticReflectTypeOf reports whether n is a call reflect.TypeOf(x)
+// where x has a concrete (non-interface) type, so that the result is
+// known at compile time.
+func IsStaticReflectTypeOf(n *CallExpr) bool {
+	if n.Op() != OCALLFUNC || n.X.Op() != ONAME || len(n.Args) != 1 {
+		return false
+	}
+	fn := n.X.(*Name)
+	if fn.Class != PFUNC || fn.Sym().Name != "TypeOf" || !types.IsReflectPkg(fn.Sym().Pkg) {
+		return false
+	}
+	arg := n.Args[0]
+	return arg.Op() == OCONVIFACE && !arg.(*ConvExpr).X.Type().IsInterface()
+}
+
 func ParamNames(ft *types.Type) []Node {
 	args := make([]Node, ft.NumParams())
 	for i, f := range ft.Params().FieldSlice() {
@@ -174,6 +174,7 @@ var OpPrec = []int{
 	OALIGNOF:       8,
 	OAPPEND:        8,
 	OBYTES2STR:     8,
+	OBYTES2STRTMP:  8,
 	OARRAYLIT:      8,
 	OSLICELIT:      8,
 	ORUNES2STR:     8,
@@ -211,6 +212,7 @@ var OpPrec = []int{
 	OSIZEOF:        8,
 	OSLICE2ARRPTR:  8,
 	OSTR2BYTES:     8,
+	OSTR2BYTESTMP:  8,
 	OSTR2RUNES:     8,
 	OSTRUCTLIT:     8,
 	OTARRAY:        8,
@@ -817,8 +819,10 @@ func exprFmt(n Node, s fmt.State, prec int) {
 		OCONVIDATA,
 		OCONVNOP,
 		OBYTES2STR,
+		OBYTES2STRTMP,
 		ORUNES2STR,
 		OSTR2BYTES,
+		OSTR2BYTESTMP,
 		OSTR2RUNES,
 		ORUNESTR,
 		OSLICE2ARRPTR:
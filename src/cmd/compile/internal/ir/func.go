@@ -10,6 +10,7 @@ import (
 	"cmd/internal/obj"
 	"cmd/internal/src"
 	"fmt"
+	"strings"
 )
 
 // A Func corresponds to a single function in a Go program
@@ -126,6 +127,18 @@ type Func struct {
 	// within a package.
 	ABIRefs obj.ABISet
 
+	// Effects summarizes what calls to the function may do. It is
+	// computed after the function is compiled, or read from export
+	// data for imported functions.
+	Effects FuncEffects
+
+	// KeepsChansOpen has bit i set if the function neither closes nor
+	// leaks the channel passed as its i'th parameter, counting the
+	// receiver first, so a caller that made the channel knows it stays
+	// open. It is computed by package chanflags, or read from export
+	// data for imported functions.
+	KeepsChansOpen uint32
+
 	NumDefers  int32 // number of defer calls in the function
 	NumReturns int32 // number of explicit returns in the function
 
@@ -250,6 +263,45 @@ func (f *Func) SetWBPos(pos src.XPos) {
 	}
 }
 
+// FuncEffects is a set of effects a call to a function may have that
+// are visible to its caller.
+type FuncEffects uint8
+
+const (
+	EffectsKnown    FuncEffects = 1 << iota // the summary has been computed
+	EffectReads                             // may read memory outside its own frame
+	EffectWrites                            // may write memory outside its own frame
+	EffectPanics                            // may panic
+	EffectBlocks                            // may block, or loop forever
+	EffectAllocates                         // may allocate heap memory
+
+	// EffectsAll is the summary of a function about which nothing
+	// is known.
+	EffectsAll = EffectReads | EffectWrites | EffectPanics | EffectBlocks | EffectAllocates
+)
+
+func (e FuncEffects) String() string {
+	if e&EffectsAll == 0 {
+		return "none"
+	}
+	var names []string
+	for _, x := range []struct {
+		e    FuncEffects
+		name string
+	}{
+		{EffectReads, "reads"},
+		{EffectWrites, "writes"},
+		{EffectPanics, "panics"},
+		{EffectBlocks, "blocks"},
+		{EffectAllocates, "allocates"},
+	} {
+		if e&x.e != 0 {
+			names = append(names, x.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
 // FuncName returns the name (without the package) of the function n.
 func FuncName(f *Func) string {
 	if f == nil || f.Nname == nil {
@@ -279,6 +331,13 @@ func PkgFuncName(f *Func) string {
 	return p + "." + s.Name
 }
 
+// IsWhyAsmFunc reports whether fn is the function named by -d=whyasm,
+// either plainly or qualified by its package path.
+func IsWhyAsmFunc(fn *Func) bool {
+	name := base.Debug.WhyAsm
+	return name != "" && fn != nil && fn.Nname != nil && (FuncName(fn) == name || PkgFuncName(fn) == name)
+}
+
 var CurFunc *Func
 
 // WithFunc invokes do with CurFunc and base.Pos set to curfn and
@@ -38,7 +38,7 @@ type Name struct {
 	miniExpr
 	BuiltinOp Op         // uint8
 	Class     Class      // uint8
-	pragma    PragmaFlag // int16
+	pragma    PragmaFlag // uint32
 	flags     bitset16
 	DictIndex uint16 // index of the dictionary entry describing the type of this variable declaration plus 1
 	sym       *types.Sym
@@ -456,7 +456,7 @@ func (s NameSet) Sorted(less func(*Name, *Name) bool) []*Name {
 	return res
 }
 
-type PragmaFlag uint16
+type PragmaFlag uint32
 
 const (
 	// Func pragmas.
@@ -469,6 +469,16 @@ const (
 	CgoUnsafeArgs               // treat a pointer to one arg as a pointer to them all
 	UintptrKeepAlive            // pointers converted to uintptr must be kept alive (compiler internal only)
 	UintptrEscapes              // pointers converted to uintptr escape
+	FastMinMax                  // float min/max may ignore NaN and signed zero semantics
+	StrictMinMax                // float min/max must keep NaN and signed zero semantics
+	FastMath                    // float arithmetic may be reassociated and ignore signed zero
+	CPUAMD64V2                  // func has a variant compiled for GOAMD64=v2
+	CPUAMD64V3                  // func has a variant compiled for GOAMD64=v3
+	CPUAMD64V4                  // func has a variant compiled for GOAMD64=v4
+	Pure                        // func must not store to memory it did not allocate (checked)
+	NoAlloc                     // func must not allocate heap memory (checked)
+	StackLocals                 // func allocates non-escaping values on the stack regardless of size
+	NoHeapLocals                // func must not move non-escaping values to the heap because of their size
 
 	// Runtime-only func pragmas.
 	// See ../../../../runtime/README.md for detailed descriptions.
@@ -480,6 +490,19 @@ const (
 	// Runtime and cgo type pragmas
 	NotInHeap // values of this type must not be heap allocated
 
+	// Type pragmas
+	NoCompare // values of this type must not be compared with == or !=
+	NoHash    // values of this type must not be used as map keys
+
+	// Type and package-level variable pragmas
+	CacheAlign // values of this type, or this variable, occupy whole cache lines
+
+	// Func and type pragmas
+	MustUse // results of calls to this func, or of this type, must be used
+
+	// Local variable pragmas
+	SoA // slice of structs is stored as a slice per field
+
 	// Go command pragmas
 	GoBuildPragma
 
@@ -487,6 +510,9 @@ const (
 
 )
 
+// CPULevels is the set of func pragmas set by //go:cpu.
+const CPULevels = CPUAMD64V2 | CPUAMD64V3 | CPUAMD64V4
+
 func AsNode(n types.Object) Node {
 	if n == nil {
 		return nil
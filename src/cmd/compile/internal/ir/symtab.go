@@ -18,6 +18,7 @@ var Syms struct {
 	Asanread          *obj.LSym
 	Asanwrite         *obj.LSym
 	CheckPtrAlignment *obj.LSym
+	Complex128div     *obj.LSym
 	Deferproc         *obj.LSym
 	DeferprocStack    *obj.LSym
 	Deferreturn       *obj.LSym
@@ -54,6 +55,7 @@ var Syms struct {
 	X86HasFMA       *obj.LSym
 	X86HasPOPCNT    *obj.LSym
 	X86HasSSE41     *obj.LSym
+	X86Level        *obj.LSym
 	// Wasm
 	WasmDiv *obj.LSym
 	// Wasm
@@ -6,6 +6,7 @@ package liveness
 
 import (
 	"fmt"
+	"strings"
 
 	"cmd/compile/internal/base"
 	"cmd/compile/internal/bitvec"
@@ -191,6 +192,9 @@ func ArgLiveness(fn *ir.Func, f *ssa.Func, pp *objw.Progs) (blockIdx, valueIdx m
 				// at last.
 				lastv = v
 			}
+			if v.Op.IsCall() {
+				lv.showlive(v, live)
+			}
 			if lastv != nil && (mayFault(v) || i == len(b.Values)-1) {
 				// Emit the liveness map if it may fault or at the end of
 				// the block. We may need a traceback if the instruction
@@ -260,6 +264,33 @@ func mayFault(v *ssa.Value) bool {
 	return true // conservatively assume all other ops could fault
 }
 
+// showlive reports, for -d=argliveness, which register argument
+// spill slots a traceback at call v would print as valid. Like the
+// traceback, it marks the others with a question mark.
+func (lv *argLiveness) showlive(v *ssa.Value, live bitvec.BitVec) {
+	if base.Debug.ArgLiveness == 0 || lv.fn.Wrapper() || lv.fn.Dupok() {
+		return
+	}
+	s := "args at indirect call:"
+	if sym, ok := v.Aux.(*ssa.AuxCall); ok && sym.Fn != nil {
+		fn := sym.Fn.Name
+		if pos := strings.Index(fn, "."); pos >= 0 {
+			fn = fn[pos+1:]
+		}
+		s = fmt.Sprintf("args at call to %s:", fn)
+	}
+	for i, a := range lv.args {
+		s += " " + a.n.Sym().Name
+		if a.off != 0 {
+			s += fmt.Sprintf("+%d", a.off)
+		}
+		if !live.Get(int32(i)) {
+			s += "?"
+		}
+	}
+	base.WarnfAt(v.Pos, s)
+}
+
 func (lv *argLiveness) print() {
 	fmt.Println("argument liveness:", lv.f.Name)
 	live := bitvec.New(int32(len(lv.args)))
@@ -1305,16 +1305,11 @@ func (lv *liveness) emit() (argsSym, liveSym *obj.LSym) {
 	// This would require shifting all bitmaps.
 	maxLocals := lv.stkptrsize
 
-	// Temporary symbols for encoding bitmaps.
-	var argsSymTmp, liveSymTmp obj.LSym
-
 	args := bitvec.New(int32(maxArgs / int64(types.PtrSize)))
-	aoff := objw.Uint32(&argsSymTmp, 0, uint32(len(lv.stackMaps))) // number of bitmaps
-	aoff = objw.Uint32(&argsSymTmp, aoff, uint32(args.N))          // number of bits in each bitmap
+	argsMaps := newStackMapWriter(int32(len(lv.stackMaps)), args.N, false)
 
 	locals := bitvec.New(int32(maxLocals / int64(types.PtrSize)))
-	loff := objw.Uint32(&liveSymTmp, 0, uint32(len(lv.stackMaps))) // number of bitmaps
-	loff = objw.Uint32(&liveSymTmp, loff, uint32(locals.N))        // number of bits in each bitmap
+	localsMaps := newStackMapWriter(int32(len(lv.stackMaps)), locals.N, true)
 
 	for _, live := range lv.stackMaps {
 		args.Clear()
@@ -1322,13 +1317,13 @@ func (lv *liveness) emit() (argsSym, liveSym *obj.LSym) {
 
 		lv.pointerMap(live, lv.vars, args, locals)
 
-		aoff = objw.BitVec(&argsSymTmp, aoff, args)
-		loff = objw.BitVec(&liveSymTmp, loff, locals)
+		argsMaps.add(args)
+		localsMaps.add(locals)
 	}
 
 	// These symbols will be added to Ctxt.Data by addGCLocals
 	// after parallel compilation is done.
-	return base.Ctxt.GCLocalsSym(argsSymTmp.P), base.Ctxt.GCLocalsSym(liveSymTmp.P)
+	return base.Ctxt.GCLocalsSym(argsMaps.data()), base.Ctxt.GCLocalsSym(localsMaps.data())
 }
 
 // Entry pointer for Compute analysis. Solves for the Compute of
@@ -0,0 +1,107 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package liveness
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/bitvec"
+	"cmd/internal/objabi"
+)
+
+// A stackMapWriter builds the data of a stack map symbol. It encodes
+// the bitmaps both one after another and, if there are few enough bits
+// for the runtime to decode them, compressed: in blocks, each of which
+// starts with a bitmap as is, followed by the others as the bits that
+// differ from the bitmap before them. See runtime/symtab.go:stackmap
+// for the encodings.
+type stackMapWriter struct {
+	n, nbit int32
+	count   int32 // number of bitmaps added
+
+	plain []byte
+
+	compress bool
+	offsets  []uint32 // of each block, relative to the first
+	blocks   []byte
+	prev     bitvec.BitVec
+}
+
+// newStackMapWriter returns a writer for a stack map of n bitmaps of
+// nbit bits each, which may be compressed if compress is set. The
+// runtime only decodes compressed locals bitmaps.
+func newStackMapWriter(n, nbit int32, compress bool) *stackMapWriter {
+	w := &stackMapWriter{n: n, nbit: nbit}
+	w.plain = w.header(make([]byte, 0, 8+int(n)*int((nbit+7)/8)), nbit)
+	if compress && n > 1 && nbit > 0 && nbit <= objabi.StackMapMaxCompressedBits {
+		w.compress = true
+		w.prev = bitvec.New(nbit)
+	}
+	return w
+}
+
+// header appends the stack map header to b.
+func (w *stackMapWriter) header(b []byte, nbit int32) []byte {
+	var hdr [8]byte
+	base.Ctxt.Arch.ByteOrder.PutUint32(hdr[0:], uint32(w.n))
+	base.Ctxt.Arch.ByteOrder.PutUint32(hdr[4:], uint32(nbit))
+	return append(b, hdr[:]...)
+}
+
+// add adds bv, the next bitmap of the stack map.
+func (w *stackMapWriter) add(bv bitvec.BitVec) {
+	// The runtime reads the bitmaps as byte arrays.
+	start := len(w.plain)
+	for j := int32(0); j < bv.N; j += 8 {
+		w.plain = append(w.plain, uint8(bv.B[j/32]>>(uint(j)%32)))
+	}
+
+	if w.compress && w.count%objabi.StackMapBlock == 0 {
+		w.offsets = append(w.offsets, uint32(len(w.blocks)))
+		w.blocks = append(w.blocks, w.plain[start:]...)
+		w.prev.Copy(bv)
+	} else if w.compress {
+		n := 0
+		for i, x := range bv.B {
+			n += bits.OnesCount32(x ^ w.prev.B[i])
+		}
+		w.uvarint(uint32(n))
+		last := int32(-1)
+		for i, x := range bv.B {
+			for d := x ^ w.prev.B[i]; d != 0; d &= d - 1 {
+				bit := int32(i)*32 + int32(bits.TrailingZeros32(d))
+				w.uvarint(uint32(bit - last - 1))
+				last = bit
+			}
+		}
+		w.prev.Copy(bv)
+	}
+	w.count++
+}
+
+func (w *stackMapWriter) uvarint(x uint32) {
+	var buf [binary.MaxVarintLen32]byte
+	n := binary.PutUvarint(buf[:], uint64(x))
+	w.blocks = append(w.blocks, buf[:n]...)
+}
+
+// data returns the shorter of the two encodings of the stack map.
+func (w *stackMapWriter) data() []byte {
+	if w.count != w.n {
+		base.Fatalf("stack map has %d bitmaps, want %d", w.count, w.n)
+	}
+	if !w.compress || 8+4*len(w.offsets)+len(w.blocks) >= len(w.plain) {
+		return w.plain
+	}
+	data := w.header(make([]byte, 0, 8+4*len(w.offsets)+len(w.blocks)), w.nbit|objabi.StackMapCompressed)
+	for _, off := range w.offsets {
+		var b [4]byte
+		base.Ctxt.Arch.ByteOrder.PutUint32(b[:], off)
+		data = append(data, b[:]...)
+	}
+	return append(data, w.blocks...)
+}
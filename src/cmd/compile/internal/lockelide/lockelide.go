@@ -0,0 +1,210 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lockelide removes the atomic operations from a sync.Mutex
+// that only the function declaring it can use, like
+//
+//	var c struct {
+//		mu sync.Mutex
+//		n  int
+//	}
+//	for _, x := range xs {
+//		c.mu.Lock()
+//		c.n += x
+//		c.mu.Unlock()
+//	}
+//
+// If the address of the variable holding the mutex is only taken to
+// call Lock and Unlock on it, no other goroutine can ever see the
+// mutex, so the calls can't contend. They are replaced by plain loads
+// and stores of the mutex's state:
+//
+//	if c.mu.state != 0 {
+//		block() // locked by this goroutine, which would deadlock
+//	}
+//	c.mu.state = 1
+//	...
+//	if c.mu.state != 1 {
+//		throw("sync: unlock of unlocked mutex")
+//	}
+//	c.mu.state = 0
+//
+// Then the address of the variable isn't taken at all, so it doesn't
+// have to be heap allocated either, as it would be to pass it to the
+// slow paths of Lock and Unlock.
+package lockelide
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+)
+
+// Func replaces the Lock and Unlock calls of mutexes local to fn.
+// It must run before inlining, which would expand the calls.
+func Func(fn *ir.Func) {
+	if base.Flag.N != 0 || base.Flag.Cfg.Instrumenting || base.Ctxt.Pkgpath == "sync" {
+		return
+	}
+
+	// Collect the calls to Lock and Unlock on local mutexes, by the
+	// variable holding the mutex. Variables captured by closures,
+	// or whose Lock and Unlock calls are deferred or run in a new
+	// goroutine, can't have theirs replaced.
+	calls := make(map[*ir.Name][]*ir.CallExpr)
+	bad := make(map[*ir.Name]bool)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n.Op() {
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				bad[cv.Canonical()] = true
+			}
+		case ir.ODEFER, ir.OGO:
+			if v, _ := lockCall(n.(*ir.GoDeferStmt).Call); v != nil {
+				bad[v] = true
+			}
+		case ir.OCALLFUNC:
+			call := n.(*ir.CallExpr)
+			if v, _ := lockCall(call); v != nil {
+				calls[v] = append(calls[v], call)
+			}
+		}
+	})
+	if len(calls) == 0 {
+		return
+	}
+
+	// The variable's address must not be taken other than by the
+	// calls, which take the address of the mutex as the receiver.
+	addrs := make(map[*ir.Name]int)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		if n.Op() == ir.OADDR {
+			if x := ir.OuterValue(n.(*ir.AddrExpr).X); x.Op() == ir.ONAME {
+				addrs[x.(*ir.Name)]++
+			}
+		}
+	})
+
+	repl := make(map[ir.Node]ir.Node)
+	for v, cs := range calls {
+		if bad[v] || v.Class != ir.PAUTO || v.Curfn != fn || addrs[v] != len(cs) {
+			continue
+		}
+		for _, call := range cs {
+			_, lock := lockCall(call)
+			if base.Flag.LowerM != 0 {
+				base.WarnfAt(call.Pos(), "elided atomic operations of local mutex %v", call.Args[0].(*ir.AddrExpr).X)
+			}
+			repl[call] = rewrite(call, lock)
+		}
+	}
+	if len(repl) == 0 {
+		return
+	}
+
+	ir.WithFunc(fn, func() {
+		var edit func(n ir.Node) ir.Node
+		edit = func(n ir.Node) ir.Node {
+			if r := repl[n]; r != nil {
+				return r
+			}
+			if n.Op() == ir.OCLOSURE {
+				return n
+			}
+			ir.EditChildren(n, edit)
+			return n
+		}
+		ir.EditChildren(fn, edit)
+	})
+}
+
+// lockCall returns the variable holding the mutex that n locks or
+// unlocks, and whether it locks it, if n is a Lock or Unlock call
+// on a sync.Mutex in a local variable or one of its fields.
+func lockCall(n ir.Node) (*ir.Name, bool) {
+	if n.Op() != ir.OCALLFUNC {
+		return nil, false
+	}
+	call := n.(*ir.CallExpr)
+	if call.X.Op() != ir.OMETHEXPR || len(call.Args) != 1 {
+		return nil, false
+	}
+	recv := call.X.(*ir.SelectorExpr).X.Type()
+	if !recv.IsPtr() || !isMutex(recv.Elem()) {
+		return nil, false
+	}
+	name := call.X.(*ir.SelectorExpr).Sel.Name
+	if name != "Lock" && name != "Unlock" {
+		return nil, false
+	}
+	if call.Args[0].Op() != ir.OADDR {
+		return nil, false
+	}
+	v := root(call.Args[0].(*ir.AddrExpr).X)
+	if v == nil {
+		return nil, false
+	}
+	return v, name == "Lock"
+}
+
+// isMutex reports whether t is sync.Mutex.
+func isMutex(t *types.Type) bool {
+	s := t.Sym()
+	return s != nil && s.Pkg.Path == "sync" && s.Name == "Mutex"
+}
+
+// root returns the local variable that n, a variable or a chain of
+// field selections from one, refers to, or nil if there isn't one.
+func root(n ir.Node) *ir.Name {
+	for n.Op() == ir.ODOT {
+		n = n.(*ir.SelectorExpr).X
+	}
+	if n.Op() != ir.ONAME {
+		return nil
+	}
+	v := n.(*ir.Name)
+	if v.Class != ir.PAUTO || v.IsClosureVar() {
+		return nil
+	}
+	return v
+}
+
+// rewrite returns the statements that replace call, which locks its
+// mutex if lock is set, and unlocks it otherwise. The uses of a mutex
+// that are rewritten are all by the same goroutine, so its state is
+// only ever 0 (unlocked) or 1 (locked).
+func rewrite(call *ir.CallExpr, lock bool) ir.Node {
+	pos := call.Pos()
+	mu := call.Args[0].(*ir.AddrExpr).X
+	var was, set int64
+	var fail ir.Node
+	if lock {
+		// Locking a locked mutex blocks forever, since only this
+		// goroutine could unlock it.
+		was, set = 0, 1
+		fail = typecheck.Call(pos, typecheck.LookupRuntime("block"), nil, false)
+	} else {
+		was, set = 1, 0
+		fail = typecheck.Call(pos, typecheck.LookupRuntime("throw"), []ir.Node{ir.NewString("sync: unlock of unlocked mutex")}, false)
+	}
+	cond := ir.NewBinaryExpr(pos, ir.ONE, state(pos, mu), ir.NewInt(was))
+	block := ir.NewBlockStmt(pos, nil)
+	block.List.Append(call.Init()...)
+	block.List.Append(typecheck.Stmt(ir.NewIfStmt(pos, cond, []ir.Node{fail}, nil)))
+	block.List.Append(typecheck.Stmt(ir.NewAssignStmt(pos, state(pos, mu), ir.NewInt(set))))
+	return block
+}
+
+// state returns the expression for the state field of mutex mu.
+func state(pos src.XPos, mu ir.Node) ir.Node {
+	for _, f := range mu.Type().Fields().Slice() {
+		if f.Sym.Name == "state" {
+			return typecheck.Expr(ir.NewSelectorExpr(pos, ir.OXDOT, ir.DeepCopy(pos, mu), f.Sym))
+		}
+	}
+	base.FatalfAt(pos, "sync.Mutex has no state field")
+	return nil
+}
@@ -225,6 +225,41 @@ func s15a8(x *[15]int64) [15]int64 {
 	})
 }
 
+func TestLogOptPrintf(t *testing.T) {
+	t.Parallel()
+
+	testenv.MustHaveGoBuild(t)
+
+	dir, err := ioutil.TempDir("", "TestLogOptPrintf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dir = fixSlash(dir)
+	src := filepath.Join(dir, "file.go")
+	if err := ioutil.WriteFile(src, []byte(`package x
+import "fmt"
+func f(s string) {
+	fmt.Printf("%d", s)
+}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outfile := filepath.Join(dir, "file.o")
+
+	cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p", "x", "-json=0,file://log/opt", "-d=printf", "-o", outfile, src)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("-json=0,file://log/opt -d=printf should have succeeded: %v\n%s", err, out)
+	}
+	logged, err := ioutil.ReadFile(filepath.Join(dir, "log", "opt", "x", "file.json"))
+	if err != nil {
+		t.Fatal("-json=0,file://log/opt -d=printf missing expected log file")
+	}
+	want(t, string(logged), `{"range":{"start":{"line":4,"character":2},"end":{"line":4,"character":2}},"severity":3,"code":"printf","source":"go compiler","message":"fmt.Printf format %d has arg s of wrong type string"}`)
+}
+
 func testLogOpt(t *testing.T, flag, src, outfile string) (string, error) {
 	run := []string{testenv.GoToolPath(t), "tool", "compile", flag, "-o", outfile, src}
 	t.Log(run)
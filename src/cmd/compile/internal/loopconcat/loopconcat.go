@@ -0,0 +1,229 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package loopconcat rewrites loops that repeatedly concatenate to a
+// local string variable, like
+//
+//	for _, x := range xs {
+//		s += x
+//	}
+//
+// to accumulate into a growable byte buffer instead, avoiding the
+// quadratic cost of copying s on every iteration:
+//
+//	var buf []byte
+//	for _, x := range xs {
+//		if buf == nil {
+//			buf = append(buf, s...)
+//		}
+//		buf = append(buf, x...)
+//	}
+//	if buf != nil {
+//		s = string(buf)
+//	}
+package loopconcat
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+)
+
+// Func rewrites string concatenation loops within fn.
+// It must run after inlining and before escape analysis.
+func Func(fn *ir.Func) {
+	if base.Flag.N != 0 {
+		return
+	}
+
+	// Find variables captured by closures. Their values can be
+	// observed outside of the loop, so they aren't rewritten.
+	captured := make(map[*ir.Name]bool)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		if n.Op() == ir.OCLOSURE {
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				captured[cv.Canonical()] = true
+			}
+		}
+	})
+
+	ir.WithFunc(fn, func() {
+		var edit func(n ir.Node) ir.Node
+		edit = func(n ir.Node) ir.Node {
+			var repl ir.Node
+			switch n.Op() {
+			case ir.OFOR, ir.ORANGE:
+				repl = rewriteLoop(n, captured)
+			case ir.OCLOSURE:
+				// Closures are rewritten on their own.
+				return n
+			}
+			ir.EditChildren(n, edit)
+			if repl != nil {
+				return repl
+			}
+			return n
+		}
+		ir.EditChildren(fn, edit)
+	})
+}
+
+// A concat is a statement of the form "s += x" or "s = s + x + ...".
+type concat struct {
+	n    ir.Node
+	xs   []ir.Node // concatenated operands
+	refs int       // number of references to s in n
+}
+
+// rewriteLoop rewrites the concatenations to local string variables
+// within loop. It returns the block replacing loop, or nil if there
+// were no concatenations to rewrite.
+func rewriteLoop(loop ir.Node, captured map[*ir.Name]bool) ir.Node {
+	switch loop := loop.(type) {
+	case *ir.ForStmt:
+		if loop.Label != nil {
+			return nil
+		}
+	case *ir.RangeStmt:
+		if loop.Label != nil {
+			return nil
+		}
+	}
+
+	// Control flow that leaves the loop other than at its end
+	// would skip the final conversion.
+	if ir.Any(loop, func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.OGOTO, ir.OLABEL:
+			return true
+		case ir.OBREAK, ir.OCONTINUE:
+			return n.(*ir.BranchStmt).Label != nil
+		}
+		return false
+	}) {
+		return nil
+	}
+
+	// Collect the concatenations within the loop, by variable.
+	concats := make(map[*ir.Name][]concat)
+	var vars []*ir.Name
+	ir.Visit(loop, func(n ir.Node) {
+		s, c, ok := isConcat(n)
+		if !ok || s.Class != ir.PAUTO || s.Addrtaken() || captured[s] {
+			return
+		}
+		if concats[s] == nil {
+			vars = append(vars, s)
+		}
+		concats[s] = append(concats[s], c)
+	})
+	if len(vars) == 0 {
+		return nil
+	}
+
+	// Count all references to each variable within the loop. The
+	// variable must not be used other than by the concatenations.
+	refs := make(map[*ir.Name]int)
+	ir.Visit(loop, func(n ir.Node) {
+		if n, ok := n.(*ir.Name); ok && concats[n] != nil {
+			refs[n]++
+		}
+	})
+
+	pos := loop.Pos()
+	var pre, post []ir.Node
+	bufs := make(map[ir.Node]*ir.Name) // concat statement -> buffer
+	for _, s := range vars {
+		want := 0
+		for _, c := range concats[s] {
+			want += c.refs
+		}
+		if refs[s] != want {
+			continue
+		}
+
+		if base.Flag.LowerM != 0 {
+			base.WarnfAt(pos, "string concatenation to %v in loop uses a growable buffer", s)
+		}
+
+		buf := typecheck.Temp(types.NewSlice(types.ByteType))
+		pre = append(pre, typecheck.Stmt(ir.NewAssignStmt(pos, buf, nil)))
+		for _, c := range concats[s] {
+			bufs[c.n] = buf
+		}
+		body := []ir.Node{ir.NewAssignStmt(pos, s, ir.NewConvExpr(pos, ir.OCONV, s.Type(), buf))}
+		post = append(post, typecheck.Stmt(ir.NewIfStmt(pos, ir.NewBinaryExpr(pos, ir.ONE, buf, typecheck.NodNil()), body, nil)))
+	}
+	if len(pre) == 0 {
+		return nil
+	}
+
+	var edit func(n ir.Node) ir.Node
+	edit = func(n ir.Node) ir.Node {
+		if buf := bufs[n]; buf != nil {
+			s, c, _ := isConcat(n)
+			return rewriteConcat(c, s, buf)
+		}
+		if n.Op() == ir.OCLOSURE {
+			return n
+		}
+		ir.EditChildren(n, edit)
+		return n
+	}
+	ir.EditChildren(loop, edit)
+
+	block := ir.NewBlockStmt(pos, pre)
+	block.List.Append(loop)
+	block.List.Append(post...)
+	return block
+}
+
+// isConcat reports whether n is a concatenation to a local string
+// variable, and returns that variable.
+func isConcat(n ir.Node) (*ir.Name, concat, bool) {
+	switch n.Op() {
+	case ir.OASOP:
+		n := n.(*ir.AssignOpStmt)
+		if n.AsOp != ir.OADD || n.X.Op() != ir.ONAME || !n.X.Type().IsString() {
+			break
+		}
+		return n.X.(*ir.Name), concat{n: n, xs: []ir.Node{n.Y}, refs: 1}, true
+
+	case ir.OAS:
+		n := n.(*ir.AssignStmt)
+		if n.X == nil || n.X.Op() != ir.ONAME || n.Y == nil || n.Y.Op() != ir.OADDSTR {
+			break
+		}
+		add := n.Y.(*ir.AddStringExpr)
+		if add.List[0] != n.X {
+			break
+		}
+		return n.X.(*ir.Name), concat{n: n, xs: add.List[1:], refs: 2}, true
+	}
+	return nil, concat{}, false
+}
+
+// rewriteConcat returns the statements replacing the concatenation c
+// to s, which append to buf instead, initializing buf with the value
+// of s first if necessary.
+func rewriteConcat(c concat, s, buf *ir.Name) ir.Node {
+	pos := c.n.Pos()
+	init := ir.NewAssignStmt(pos, buf, appendString(pos, buf, s))
+	block := ir.NewBlockStmt(pos, nil)
+	block.List.Append(c.n.Init()...)
+	block.List.Append(typecheck.Stmt(ir.NewIfStmt(pos, ir.NewBinaryExpr(pos, ir.OEQ, buf, typecheck.NodNil()), []ir.Node{init}, nil)))
+	for _, x := range c.xs {
+		block.List.Append(typecheck.Stmt(ir.NewAssignStmt(pos, buf, appendString(pos, buf, x))))
+	}
+	return block
+}
+
+// appendString returns the expression append(buf, x...).
+func appendString(pos src.XPos, buf *ir.Name, x ir.Node) ir.Node {
+	call := ir.NewCallExpr(pos, ir.OAPPEND, nil, []ir.Node{buf, x})
+	call.IsDDD = true
+	return call
+}
@@ -0,0 +1,812 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package loopfuse implements two experimental loop transformations,
+// enabled by -d=loopfusion.
+//
+// Loop fusion merges adjacent loops over the same index range into a
+// single loop, so that the elements a loop writes to a temporary
+// slice are consumed by the next loop while they are still in
+// registers or cache:
+//
+//	t := make([]float64, len(a))
+//	out := make([]float64, len(a))
+//	for i := range a {
+//		t[i] = a[i] * 2
+//	}
+//	for i, v := range t {
+//		out[i] = v + b[i]
+//	}
+//
+// becomes
+//
+//	t := make([]float64, len(a))
+//	out := make([]float64, len(a))
+//	if len(t) == len(a) && len(out) >= len(a) && len(b) >= len(a) {
+//		for i := range a {
+//			t[i] = a[i] * 2
+//			v = t[i]
+//			out[i] = v + b[i]
+//		}
+//	} else {
+//		// the original loops
+//	}
+//
+// The guard ensures that no index in the fused loop is out of range,
+// so the loops can't panic part way through and their iterations can
+// be interleaved. Loop bodies are limited to assignments and if
+// statements computing on the elements at the loop index, and the
+// loops must not write memory the others access through a different
+// variable. Slices made in the same block as the loops and only
+// indexed until then are known not to share memory with anything.
+//
+// Loop interchange swaps a perfectly nested pair of counted loops
+// over fixed-size arrays when the inner loop variable selects the
+// row and the outer one the column, like
+//
+//	for i := 0; i < 4; i++ {
+//		for j := 0; j < 4; j++ {
+//			m[j][i] = a[j][i] * s
+//		}
+//	}
+//
+// so that the inner loop walks memory sequentially. Every element
+// written must be written by only one iteration, and accessed by no
+// other.
+package loopfuse
+
+import (
+	"fmt"
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+)
+
+// Func fuses adjacent loops and interchanges loop nests within fn.
+// It must run after inlining and before escape analysis.
+func Func(fn *ir.Func) {
+	if base.Debug.LoopFusion == 0 || base.Flag.N != 0 || base.SmallCode() {
+		return
+	}
+
+	f := &fuser{
+		captured: make(map[*ir.Name]bool),
+		reported: make(map[ir.Node]bool),
+	}
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n.Op() {
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				f.captured[cv.Canonical()] = true
+			}
+		case ir.OGOTO:
+			f.hasGoto = true
+		}
+	})
+
+	ir.WithFunc(fn, func() {
+		f.block(&fn.Body)
+	})
+}
+
+type fuser struct {
+	captured map[*ir.Name]bool // variables captured by closures
+	hasGoto  bool              // whether the function contains a goto
+	reported map[ir.Node]bool  // loops already reported as unfusable
+}
+
+// block transforms the loops within list.
+func (f *fuser) block(list *ir.Nodes) {
+	for _, n := range *list {
+		f.stmt(n)
+	}
+	f.fuse(list)
+}
+
+// stmt transforms the loops nested within n.
+func (f *fuser) stmt(n ir.Node) {
+	switch n := n.(type) {
+	case *ir.BlockStmt:
+		f.block(&n.List)
+	case *ir.IfStmt:
+		f.block(&n.Body)
+		f.block(&n.Else)
+	case *ir.ForStmt:
+		f.block(&n.Body)
+		f.interchange(n)
+	case *ir.RangeStmt:
+		f.block(&n.Body)
+	case *ir.SwitchStmt:
+		for _, c := range n.Cases {
+			f.block(&c.Body)
+		}
+	case *ir.SelectStmt:
+		for _, c := range n.Cases {
+			f.block(&c.Body)
+		}
+	}
+}
+
+func (f *fuser) report(pos src.XPos, format string, args ...interface{}) {
+	if base.Debug.LoopFusion > 1 {
+		base.WarnfAt(pos, format, args...)
+	}
+}
+
+// fuse replaces runs of fusable loops in list with fused loops.
+func (f *fuser) fuse(list *ir.Nodes) {
+	stmts := *list
+	var out []ir.Node
+	for i := 0; i < len(stmts); {
+		n := stmts[i]
+		if !isLoop(n) || i+1 == len(stmts) || !isLoop(stmts[i+1]) {
+			out = append(out, n)
+			i++
+			continue
+		}
+
+		g := &group{f: f, list: stmts, start: i}
+		j := i
+		for ; j < len(stmts) && isLoop(stmts[j]); j++ {
+			l, why := f.analyze(stmts[j])
+			if l == nil {
+				if why != "" && !f.reported[stmts[j]] {
+					f.reported[stmts[j]] = true
+					f.report(stmts[j].Pos(), "cannot fuse loop: %s", why)
+				}
+				break
+			}
+			if why := g.add(l, j); why != "" {
+				f.report(l.n.Pos(), "cannot fuse loop with loop at %v: %s", base.FmtPos(stmts[j-1].Pos()), why)
+				break
+			}
+		}
+		if len(g.loops) < 2 {
+			out = append(out, n)
+			i++
+			continue
+		}
+
+		for _, l := range g.loops[1:] {
+			f.report(l.n.Pos(), "fused loop into loop at %v", base.FmtPos(n.Pos()))
+		}
+		out = append(out, g.rewrite())
+		i = j
+	}
+	*list = out
+}
+
+func isLoop(n ir.Node) bool {
+	return n.Op() == ir.OFOR || n.Op() == ir.ORANGE
+}
+
+// A loop is a loop that is a candidate for fusion.
+type loop struct {
+	n     ir.Node  // *ir.ForStmt or *ir.RangeStmt
+	x     *ir.Name // slice or array ranged over, or nil for a counted loop
+	bound ir.Node  // bound of a counted loop
+	index *ir.Name // index variable, or nil
+	value *ir.Name // value variable of a range loop, or nil
+	body  *body
+}
+
+// analyze returns the loop n as a candidate for fusion, or the
+// reason it can't be fused with other loops. The reason is empty if n
+// isn't of a form that is fused at all.
+func (f *fuser) analyze(n ir.Node) (*loop, string) {
+	l := &loop{n: n}
+	var body ir.Nodes
+	switch n := n.(type) {
+	case *ir.RangeStmt:
+		x, ok := n.X.(*ir.Name)
+		if !ok || !x.Type().IsSlice() && !x.Type().IsArray() {
+			return nil, ""
+		}
+		if n.Label != nil {
+			return nil, "loop has a label"
+		}
+		l.x = x
+		l.index = nonBlank(n.Key)
+		l.value = nonBlank(n.Value)
+		body = n.Body
+	case *ir.ForStmt:
+		i, bound, ok := counted(n)
+		if !ok {
+			return nil, ""
+		}
+		if n.Label != nil {
+			return nil, "loop has a label"
+		}
+		l.index, l.bound = i, bound
+		body = n.Body
+	}
+
+	l.body = newBody(l.index)
+	if why := l.body.stmts(body); why != "" {
+		return nil, why
+	}
+	if l.value != nil {
+		l.body.accesses = append(l.body.accesses, access{x: l.x, subs: []*ir.Name{l.index}})
+	}
+	for _, a := range l.body.accesses {
+		if len(a.subs) != 1 {
+			return nil, fmt.Sprintf("body indexes %v more than once", a.x)
+		}
+	}
+	return l, ""
+}
+
+func nonBlank(n ir.Node) *ir.Name {
+	if n == nil || ir.IsBlank(n) {
+		return nil
+	}
+	return n.(*ir.Name)
+}
+
+// counted reports whether loop is of the form
+//
+//	for i := 0; i < bound; i++ { ... }
+//
+// where i is an int and bound a constant, a variable or the length of
+// a variable, and returns i and bound.
+func counted(loop *ir.ForStmt) (*ir.Name, ir.Node, bool) {
+	var i *ir.Name
+	for _, init := range loop.Init() {
+		switch init.Op() {
+		case ir.ODCL:
+		case ir.OAS:
+			init := init.(*ir.AssignStmt)
+			x, ok := init.X.(*ir.Name)
+			if i != nil || !ok || !init.Def || x.Type().Kind() != types.TINT || !isInt(init.Y, 0) {
+				return nil, nil, false
+			}
+			i = x
+		default:
+			return nil, nil, false
+		}
+	}
+	if i == nil || loop.Cond == nil || loop.Cond.Op() != ir.OLT || len(loop.Late) != 0 {
+		return nil, nil, false
+	}
+	cond := loop.Cond.(*ir.BinaryExpr)
+	if cond.X != i || !isBound(cond.Y) {
+		return nil, nil, false
+	}
+
+	post := loop.Post
+	if post != nil && post.Op() == ir.OBLOCK && len(post.(*ir.BlockStmt).List) == 1 {
+		post = post.(*ir.BlockStmt).List[0]
+	}
+	if post == nil || post.Op() != ir.OASOP {
+		return nil, nil, false
+	}
+	inc := post.(*ir.AssignOpStmt)
+	if inc.AsOp != ir.OADD || inc.X != i || !isInt(inc.Y, 1) {
+		return nil, nil, false
+	}
+	return i, cond.Y, true
+}
+
+func isInt(n ir.Node, v int64) bool {
+	return ir.IsConst(n, constant.Int) && ir.Int64Val(n) == v
+}
+
+// isBound reports whether n is a constant, a variable, or the length
+// of a variable, none of which a candidate loop body can change.
+func isBound(n ir.Node) bool {
+	switch n.Op() {
+	case ir.OLITERAL:
+		return true
+	case ir.ONAME:
+		return n.(*ir.Name).Class != ir.PFUNC
+	case ir.OLEN:
+		return n.(*ir.UnaryExpr).X.Op() == ir.ONAME
+	}
+	return false
+}
+
+// countKey returns a value identifying the number of iterations of l:
+// the constant bound, the bound variable, or the variable whose
+// length it is.
+func (l *loop) countKey() interface{} {
+	n := l.bound
+	if l.x != nil {
+		if l.x.Type().IsArray() {
+			return l.x.Type().NumElem()
+		}
+		return lenOf{l.x}
+	}
+	switch n.Op() {
+	case ir.OLITERAL:
+		return ir.Int64Val(n)
+	case ir.OLEN:
+		return lenOf{n.(*ir.UnaryExpr).X.(*ir.Name)}
+	}
+	return n.(*ir.Name)
+}
+
+type lenOf struct{ x *ir.Name }
+
+// count returns an expression for the number of iterations of l.
+func (l *loop) count() ir.Node {
+	if l.x != nil {
+		return ir.NewUnaryExpr(l.n.Pos(), ir.OLEN, l.x)
+	}
+	return ir.DeepCopy(src.NoXPos, l.bound)
+}
+
+func (l *loop) stmtBody() ir.Nodes {
+	if n, ok := l.n.(*ir.RangeStmt); ok {
+		return n.Body
+	}
+	return l.n.(*ir.ForStmt).Body
+}
+
+// A group is a run of loops in a statement list that can be fused.
+type group struct {
+	f     *fuser
+	list  []ir.Node
+	start int
+	loops []*loop
+}
+
+// add adds l, the statement at list[end], to g, or returns why it
+// can't be fused with the loops in g.
+func (g *group) add(l *loop, end int) string {
+	for _, m := range g.loops {
+		for _, a := range m.body.accesses {
+			for _, b := range l.body.accesses {
+				if (a.write || b.write) && g.mayOverlap(a.x, b.x, end) {
+					return fmt.Sprintf("%v and %v may overlap", a.x, b.x)
+				}
+			}
+		}
+	}
+	g.loops = append(g.loops, l)
+	return ""
+}
+
+// mayOverlap reports whether the elements of x and y may share
+// memory, for loops ending at list[end].
+func (g *group) mayOverlap(x, y *ir.Name, end int) bool {
+	if x == y {
+		return false
+	}
+	return !distinct(x) && !distinct(y) && !g.fresh(x, end) && !g.fresh(y, end)
+}
+
+// distinct reports whether x's elements are stored in x itself, so
+// they can't be accessed through any other variable.
+func distinct(x *ir.Name) bool {
+	if x.Type().IsString() {
+		return true
+	}
+	return x.Type().IsArray() && (x.Class == ir.PAUTO || x.Class == ir.PPARAM) && !x.Addrtaken()
+}
+
+// fresh reports whether x is a slice made by a statement earlier in
+// the list, and not used up to list[end] other than to index it,
+// range over it or take its length, so no other variable can refer
+// to its elements.
+func (g *group) fresh(x *ir.Name, end int) bool {
+	f := g.f
+	if x.Class != ir.PAUTO || x.Addrtaken() || f.captured[x] || f.hasGoto || !x.Type().IsSlice() {
+		return false
+	}
+	def, ok := x.Defn.(*ir.AssignStmt)
+	if !ok || def.Y == nil || def.Y.Op() != ir.OMAKESLICE {
+		return false
+	}
+	for i := g.start - 1; i >= 0; i-- {
+		if g.list[i] != def {
+			continue
+		}
+		for _, n := range g.list[i+1 : end+1] {
+			if aliases(n, x) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// aliases reports whether n may copy the slice x.
+func aliases(n ir.Node, x *ir.Name) bool {
+	refs, safe := 0, 0
+	ir.Visit(n, func(n ir.Node) {
+		switch n.Op() {
+		case ir.ONAME:
+			if n == x {
+				refs++
+			}
+		case ir.OINDEX:
+			if n.(*ir.IndexExpr).X == x {
+				safe++
+			}
+		case ir.OLEN, ir.OCAP:
+			if n.(*ir.UnaryExpr).X == x {
+				safe++
+			}
+		case ir.ORANGE:
+			if n.(*ir.RangeStmt).X == x {
+				safe++
+			}
+		}
+	})
+	return refs > safe
+}
+
+// rewrite returns the statement replacing the loops in g.
+func (g *group) rewrite() ir.Node {
+	first := g.loops[0]
+	pos := first.n.Pos()
+
+	fused := ir.DeepCopy(src.NoXPos, first.n)
+	index := first.index
+	if index == nil {
+		// Only range loops can lack an index variable.
+		index = typecheck.Temp(types.Types[types.TINT])
+		fused.(*ir.RangeStmt).Key = index
+	}
+	var body *ir.Nodes
+	switch fused := fused.(type) {
+	case *ir.RangeStmt:
+		body = &fused.Body
+	case *ir.ForStmt:
+		body = &fused.Body
+	}
+	for _, l := range g.loops[1:] {
+		if l.value != nil {
+			body.Append(typecheck.Stmt(ir.NewAssignStmt(l.n.Pos(), l.value, ir.NewIndexExpr(l.n.Pos(), l.x, index))))
+		}
+		stmts := ir.DeepCopyList(src.NoXPos, l.stmtBody())
+		if l.index != nil {
+			replace(stmts, l.index, index)
+		}
+		body.Append(stmts...)
+	}
+
+	guard := g.guard()
+	if guard == nil {
+		return fused
+	}
+	orig := append([]ir.Node(nil), g.list[g.start:g.start+len(g.loops)]...)
+	return typecheck.Stmt(ir.NewIfStmt(pos, guard, []ir.Node{fused}, orig))
+}
+
+// guard returns the condition under which the loops in g can be
+// fused, or nil if they always can: they must all run the same number
+// of iterations, and every index must be in range.
+func (g *group) guard() ir.Node {
+	first := g.loops[0]
+	key := first.countKey()
+	pos := first.n.Pos()
+
+	var conds []ir.Node
+	inRange := make(map[*ir.Name]bool)
+	for _, l := range g.loops {
+		k := l.countKey()
+		if k != key {
+			conds = append(conds, ir.NewBinaryExpr(pos, ir.OEQ, l.count(), first.count()))
+		}
+		if k, ok := k.(lenOf); ok {
+			inRange[k.x] = true
+		}
+	}
+	for _, l := range g.loops {
+		for _, a := range l.body.accesses {
+			if inRange[a.x] {
+				continue
+			}
+			inRange[a.x] = true
+			if n, ok := key.(int64); ok && a.x.Type().IsArray() && a.x.Type().NumElem() >= n {
+				continue
+			}
+			conds = append(conds, ir.NewBinaryExpr(pos, ir.OGE, ir.NewUnaryExpr(pos, ir.OLEN, a.x), first.count()))
+		}
+	}
+	if len(conds) == 0 {
+		return nil
+	}
+	cond := conds[0]
+	for _, c := range conds[1:] {
+		cond = ir.NewLogicalExpr(pos, ir.OANDAND, cond, c)
+	}
+	return typecheck.Expr(cond)
+}
+
+// replace replaces the variable old with new in list.
+func replace(list []ir.Node, old, new *ir.Name) {
+	var edit func(n ir.Node) ir.Node
+	edit = func(n ir.Node) ir.Node {
+		if n == old {
+			return new
+		}
+		ir.EditChildren(n, edit)
+		return n
+	}
+	for i, n := range list {
+		list[i] = edit(n)
+	}
+}
+
+// interchange swaps the loops of the nest
+//
+//	for i := 0; i < n; i++ {
+//		for j := 0; j < m; j++ {
+//			...
+//		}
+//	}
+//
+// when the body accesses arrays in column order.
+func (f *fuser) interchange(outer *ir.ForStmt) {
+	if len(outer.Body) != 1 || outer.Body[0].Op() != ir.OFOR {
+		return
+	}
+	inner := outer.Body[0].(*ir.ForStmt)
+	i, ibound, ok := counted(outer)
+	if !ok || outer.Label != nil {
+		return
+	}
+	j, jbound, ok := counted(inner)
+	if !ok || inner.Label != nil {
+		return
+	}
+
+	// Only nests that access two-dimensional arrays in column order
+	// more often than in row order benefit.
+	rows, cols := 0, 0
+	ir.VisitList(inner.Body, func(n ir.Node) {
+		if n.Op() != ir.OINDEX || n.(*ir.IndexExpr).X.Op() != ir.OINDEX {
+			return
+		}
+		col, row := n.(*ir.IndexExpr).Index, n.(*ir.IndexExpr).X.(*ir.IndexExpr).Index
+		switch {
+		case row == i && col == j:
+			rows++
+		case row == j && col == i:
+			cols++
+		}
+	})
+	if cols <= rows {
+		return
+	}
+
+	if why := canInterchange(inner.Body, i, ibound, j, jbound); why != "" {
+		f.report(outer.Pos(), "cannot interchange loops: %s", why)
+		return
+	}
+	f.report(outer.Pos(), "interchanged loops")
+
+	oinit, iinit := outer.Init(), inner.Init()
+	outer.SetInit(iinit)
+	inner.SetInit(oinit)
+	outer.Cond, inner.Cond = inner.Cond, outer.Cond
+	outer.Post, inner.Post = inner.Post, outer.Post
+}
+
+// canInterchange returns why the loops over i and j around body can't
+// be interchanged, or "" if they can.
+func canInterchange(body ir.Nodes, i *ir.Name, ibound ir.Node, j *ir.Name, jbound ir.Node) string {
+	if ibound.Op() != ir.OLITERAL || jbound.Op() != ir.OLITERAL {
+		return "loop bounds are not constant"
+	}
+	b := newBody(i, j)
+	if why := b.stmts(body); why != "" {
+		return why
+	}
+
+	bounds := map[*ir.Name]int64{i: ir.Int64Val(ibound), j: ir.Int64Val(jbound)}
+	written := make(map[*ir.Name][]*ir.Name)
+	for _, a := range b.accesses {
+		t := a.x.Type()
+		for _, sub := range a.subs {
+			if !t.IsArray() || t.NumElem() < bounds[sub] {
+				return fmt.Sprintf("index of %v may be out of range", a.x)
+			}
+			t = t.Elem()
+		}
+		if a.write {
+			if len(a.subs) != 2 || a.subs[0] == a.subs[1] {
+				return fmt.Sprintf("%v is written by more than one iteration", a.x)
+			}
+			written[a.x] = a.subs
+		}
+	}
+	for _, a := range b.accesses {
+		if subs := written[a.x]; subs != nil && (len(a.subs) != 2 || a.subs[0] != subs[0] || a.subs[1] != subs[1]) {
+			return fmt.Sprintf("%v is accessed by more than one iteration", a.x)
+		}
+	}
+	return ""
+}
+
+// A body describes the statements of a loop body that is a candidate
+// for fusion or interchange.
+type body struct {
+	index    map[*ir.Name]bool // loop index variables
+	locals   map[*ir.Name]bool // variables declared in the body
+	accesses []access
+}
+
+// An access is an element access x[i] or x[i][j] with loop index
+// variables i and j.
+type access struct {
+	x     *ir.Name
+	subs  []*ir.Name
+	write bool
+}
+
+func newBody(index ...*ir.Name) *body {
+	b := &body{index: make(map[*ir.Name]bool), locals: make(map[*ir.Name]bool)}
+	for _, i := range index {
+		if i != nil {
+			b.index[i] = true
+		}
+	}
+	return b
+}
+
+// stmts records the accesses in list, or returns why list can't be
+// part of a candidate loop body.
+func (b *body) stmts(list ir.Nodes) string {
+	for _, n := range list {
+		if why := b.stmt(n); why != "" {
+			return why
+		}
+	}
+	return ""
+}
+
+func (b *body) stmt(n ir.Node) string {
+	if why := b.stmts(n.Init()); why != "" {
+		return why
+	}
+	switch n := n.(type) {
+	case *ir.Decl:
+		b.locals[n.X] = true
+		return ""
+	case *ir.AssignStmt:
+		if n.Def && n.X.Op() == ir.ONAME {
+			b.locals[n.X.(*ir.Name)] = true
+		}
+		if n.Y != nil {
+			if why := b.expr(n.Y); why != "" {
+				return why
+			}
+		}
+		return b.store(n.X)
+	case *ir.AssignOpStmt:
+		if why := b.binary(n.AsOp, n.X, n.Y); why != "" {
+			return why
+		}
+		return b.store(n.X)
+	case *ir.BlockStmt:
+		return b.stmts(n.List)
+	case *ir.IfStmt:
+		if why := b.expr(n.Cond); why != "" {
+			return why
+		}
+		if why := b.stmts(n.Body); why != "" {
+			return why
+		}
+		return b.stmts(n.Else)
+	}
+
+	switch n.Op() {
+	case ir.OFOR, ir.ORANGE:
+		return "body contains a loop"
+	case ir.OCALLFUNC, ir.OCALLINTER, ir.OCALLMETH, ir.OINLCALL:
+		return "body contains a function call"
+	case ir.OBREAK, ir.OCONTINUE, ir.ORETURN, ir.OGOTO, ir.OLABEL:
+		return "body contains a branch"
+	}
+	return fmt.Sprintf("body contains an unsupported %v statement", n.Op())
+}
+
+// store records an assignment to n.
+func (b *body) store(n ir.Node) string {
+	switch n := n.(type) {
+	case *ir.Name:
+		if b.locals[n] || ir.IsBlank(n) {
+			return ""
+		}
+	case *ir.IndexExpr:
+		return b.access(n, true)
+	}
+	return fmt.Sprintf("body assigns to %v", n)
+}
+
+// expr records the accesses in n, or returns why n can't be part of a
+// candidate loop body.
+func (b *body) expr(n ir.Node) string {
+	switch n.Op() {
+	case ir.OLITERAL, ir.ONIL:
+		return ""
+	case ir.ONAME:
+		if n.(*ir.Name).Class != ir.PFUNC {
+			return ""
+		}
+	case ir.OINDEX:
+		return b.access(n.(*ir.IndexExpr), false)
+	case ir.OLEN, ir.OCAP:
+		if n.(*ir.UnaryExpr).X.Op() == ir.ONAME {
+			return ""
+		}
+	case ir.OADD, ir.OSUB, ir.OMUL, ir.ODIV, ir.OMOD, ir.OOR, ir.OXOR, ir.OAND, ir.OANDNOT, ir.OLSH, ir.ORSH,
+		ir.OEQ, ir.ONE, ir.OLT, ir.OLE, ir.OGT, ir.OGE:
+		n := n.(*ir.BinaryExpr)
+		return b.binary(n.Op(), n.X, n.Y)
+	case ir.OANDAND, ir.OOROR:
+		n := n.(*ir.LogicalExpr)
+		if why := b.expr(n.X); why != "" {
+			return why
+		}
+		return b.expr(n.Y)
+	case ir.ONEG, ir.OPLUS, ir.OBITNOT, ir.ONOT:
+		return b.expr(n.(*ir.UnaryExpr).X)
+	case ir.OCONV:
+		n := n.(*ir.ConvExpr)
+		if isArith(n.Type()) && isArith(n.X.Type()) {
+			return b.expr(n.X)
+		}
+	case ir.OCONVNOP:
+		return b.expr(n.(*ir.ConvExpr).X)
+	case ir.OCALLFUNC, ir.OCALLINTER, ir.OCALLMETH, ir.OINLCALL:
+		return "body contains a function call"
+	}
+	return fmt.Sprintf("body contains unsupported expression %v", n)
+}
+
+// binary records the accesses in the operation x op y, or returns why
+// it can't be part of a candidate loop body. It must not panic.
+func (b *body) binary(op ir.Op, x, y ir.Node) string {
+	if !isArith(x.Type()) && !x.Type().IsBoolean() {
+		return fmt.Sprintf("body contains unsupported %v operation on %v", op, x.Type())
+	}
+	switch op {
+	case ir.ODIV, ir.OMOD:
+		if x.Type().IsInteger() && (y.Op() != ir.OLITERAL || ir.IsZero(y)) {
+			return "body contains an integer division that may panic"
+		}
+	case ir.OLSH, ir.ORSH:
+		if y.Op() != ir.OLITERAL && !y.Type().IsUnsigned() {
+			return "body contains a shift that may panic"
+		}
+	}
+	if why := b.expr(x); why != "" {
+		return why
+	}
+	return b.expr(y)
+}
+
+func isArith(t *types.Type) bool {
+	return t.IsInteger() || t.IsFloat() || t.IsComplex()
+}
+
+// access records the element access n.
+func (b *body) access(n *ir.IndexExpr, write bool) string {
+	var subs []*ir.Name
+	var x ir.Node = n
+	for x.Op() == ir.OINDEX {
+		ix := x.(*ir.IndexExpr)
+		i, ok := ix.Index.(*ir.Name)
+		if !ok || !b.index[i] {
+			return fmt.Sprintf("index %v is not the loop index", ix.Index)
+		}
+		subs = append([]*ir.Name{i}, subs...)
+		x = ix.X
+	}
+	name, ok := x.(*ir.Name)
+	if !ok || !name.Type().IsSlice() && !name.Type().IsArray() && !name.Type().IsString() {
+		return fmt.Sprintf("body contains unsupported expression %v", n)
+	}
+	b.accesses = append(b.accesses, access{x: name, subs: subs, write: write})
+	return ""
+}
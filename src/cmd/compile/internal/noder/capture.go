@@ -0,0 +1,539 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+)
+
+// A capture file, written by -d=capture and read by -d=replay, holds a
+// type-checked package. It starts with a text header, one line per
+// entry, that records the parts of typecheck.Target that are not
+// declarations, and the order of its declarations:
+//
+//	capture "path"    the package's import path
+//	import "path"     an entry of Target.Imports
+//	extern name       an entry of Target.Externs
+//	local name·N      a type declared in a function, with Vargen N
+//	func name         a function or method in Target.Decls
+//	decl name         one declared without a body
+//	closure name      a closure in Target.Decls
+//	init name         an entry of Target.Inits
+//	export name       an entry of Target.Exports
+//	asm name          an entry of Target.Asms
+//	cgo "arg"...      an entry of Target.CgoPragmas
+//
+// The header ends with a "$$" line, followed by indexed export data for
+// the package's declarations, which includes the bodies of all its
+// functions, as if they were all inlinable. Package-scope
+// initialization statements are exported as the body of a function
+// named captureInit.
+
+// captureInit is the name of the function whose body holds the
+// package-scope initialization statements in a capture file.
+const captureInit = "capture·init"
+
+// captureQuote quotes s for a capture file header, escaping spaces so
+// that the header's fields are separated by spaces alone.
+func captureQuote(s string) string {
+	return strings.Replace(strconv.Quote(s), " ", `\x20`, -1)
+}
+
+// Capture writes typecheck.Target to a capture file in dir, named for
+// the package's import path, so that -d=replay can compile it again
+// without its source or the cost of parsing and type-checking it.
+func Capture(dir string) {
+	base.Timer.Start("fe", "capture")
+
+	target := typecheck.Target
+	var hdr bytes.Buffer
+	fmt.Fprintf(&hdr, "capture %s\n", captureQuote(base.Ctxt.Pkgpath))
+	for _, pkg := range target.Imports {
+		fmt.Fprintf(&hdr, "import %s\n", captureQuote(pkg.Path))
+	}
+
+	var roots []*ir.Name
+	for _, n := range target.Externs {
+		if n.Op() == ir.ONAME || n.Op() == ir.OTYPE || n.Op() == ir.OLITERAL {
+			fmt.Fprintf(&hdr, "extern %s\n", n.Sym().Name)
+			roots = append(roots, n.(*ir.Name))
+		}
+	}
+
+	// Export every function with its body, by giving it an Inl for the
+	// exporter, and restore the functions before returning.
+	var restore []func()
+	defer func() {
+		for _, f := range restore {
+			f()
+		}
+	}()
+	exportBody := func(fn *ir.Func, dcl []*ir.Name, body []ir.Node) {
+		inl, exportInline := fn.Inl, fn.ExportInline()
+		restore = append(restore, func() {
+			fn.Inl = inl
+			fn.SetExportInline(exportInline)
+		})
+		fn.Inl = &ir.Inline{Dcl: dcl, Body: body}
+		fn.SetExportInline(true)
+	}
+
+	// Types declared in functions are told apart from others of the
+	// same name only by their Vargen. Export them under names made
+	// unique by it, listed in Vargen order for Replay to number them
+	// the same again.
+	var locals []*ir.Name
+	for _, n := range target.Decls {
+		if n.Op() != ir.ODCLFUNC {
+			continue
+		}
+		ir.VisitList(n.(*ir.Func).Body, func(n ir.Node) {
+			if n.Op() == ir.ODCLTYPE {
+				if x := n.(*ir.Decl).X; !x.Alias() {
+					locals = append(locals, x)
+				}
+			}
+		})
+	}
+	sort.Slice(locals, func(i, j int) bool {
+		return locals[i].Type().Vargen() < locals[j].Type().Vargen()
+	})
+	for _, n := range locals {
+		n := n
+		sym := n.Sym()
+		n.SetSym(sym.Pkg.Lookup(fmt.Sprintf("%s·%d", sym.Name, n.Type().Vargen())))
+		restore = append(restore, func() { n.SetSym(sym) })
+		fmt.Fprintf(&hdr, "local %s\n", n.Sym().Name)
+		roots = append(roots, n)
+	}
+
+	var inits []ir.Node
+	for _, n := range target.Decls {
+		switch n.Op() {
+		case ir.ODCLFUNC:
+			fn := n.(*ir.Func)
+			if ir.IsBlank(fn.Nname) {
+				continue // not compiled
+			}
+			if fn.OClosure != nil {
+				// Closures are exported with the functions
+				// that contain them, but are listed too, to be
+				// compiled in the same order.
+				fmt.Fprintf(&hdr, "closure %s\n", fn.Sym().Name)
+				continue
+			}
+			if fn.Type().HasTParam() || strings.Contains(fn.Sym().Name, "[") {
+				base.ErrorfAt(fn.Pos(), "-d=capture: cannot capture generic function %v", fn)
+				continue
+			}
+			exportBody(fn, fn.Dcl, fn.Body)
+			if len(fn.Body) > 0 {
+				fmt.Fprintf(&hdr, "func %s\n", fn.Sym().Name)
+			} else {
+				fmt.Fprintf(&hdr, "decl %s\n", fn.Sym().Name)
+			}
+			if !ir.IsMethod(fn.Nname) {
+				roots = append(roots, fn.Nname)
+			}
+
+		case ir.OAS, ir.OAS2, ir.OAS2DOTTYPE, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2RECV:
+			inits = append(inits, n)
+		}
+	}
+	for _, fn := range target.Inits {
+		fmt.Fprintf(&hdr, "init %s\n", fn.Sym().Name)
+	}
+	for _, n := range target.Exports {
+		fmt.Fprintf(&hdr, "export %s\n", n.Sym().Name)
+	}
+	for _, n := range target.Asms {
+		fmt.Fprintf(&hdr, "asm %s\n", n.Sym().Name)
+	}
+	for _, p := range target.CgoPragmas {
+		hdr.WriteString("cgo")
+		for _, arg := range p {
+			fmt.Fprintf(&hdr, " %s", captureQuote(arg))
+		}
+		hdr.WriteString("\n")
+	}
+	if len(target.Embeds) > 0 {
+		base.ErrorfAt(target.Embeds[0].Pos(), "-d=capture: cannot capture //go:embed variables")
+	}
+	base.ExitIfErrors()
+
+	if len(inits) > 0 {
+		sym := types.LocalPkg.Lookup(captureInit)
+		fn := ir.NewFunc(base.AutogeneratedPos)
+		fn.Nname = ir.NewNameAt(fn.Pos(), sym)
+		fn.Nname.Func = fn
+		fn.Nname.Class = ir.PFUNC
+		fn.Nname.SetType(types.NewSignature(types.NoPkg, nil, nil, nil, nil))
+		fn.Nname.SetTypecheck(1)
+		sym.Def = fn.Nname
+		restore = append(restore, func() { sym.Def = nil })
+		exportBody(fn, typecheck.InitTodoFunc.Dcl, inits)
+		roots = append(roots, fn.Nname)
+	}
+
+	var data bytes.Buffer
+	typecheck.WriteBodies(&data, roots)
+
+	name := filepath.Join(dir, strings.Replace(base.Ctxt.Pkgpath, "/", "%", -1)+".capture")
+	f, err := os.Create(name)
+	if err != nil {
+		base.Fatalf("-d=capture: %v", err)
+	}
+	hdr.WriteString("$$\n")
+	hdr.WriteTo(f)
+	data.WriteTo(f)
+	if err := f.Close(); err != nil {
+		base.Fatalf("-d=capture: %v", err)
+	}
+}
+
+// Replay reads the capture file written by -d=capture into
+// typecheck.Target, in place of LoadPackage.
+func Replay(filename string) {
+	base.Timer.Start("fe", "replay")
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		base.Fatalf("-d=replay: %v", err)
+	}
+	i := bytes.Index(data, []byte("\n$$\n"))
+	if i < 0 {
+		base.Fatalf("-d=replay: %s is not a capture file", filename)
+	}
+	hdr, exportData := data[:i+1], string(data[i+len("\n$$\n"):])
+	if exportData == "" || exportData[0] != 'i' {
+		base.Fatalf("-d=replay: %s is not a capture file", filename)
+	}
+
+	type entry struct {
+		kind string
+		args []string
+	}
+	var entries []entry
+	sc := bufio.NewScanner(bytes.NewReader(hdr))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 2 {
+			base.Fatalf("-d=replay: %s: bad header line %q", filename, sc.Text())
+		}
+		args := f[1:]
+		for i, arg := range args {
+			if strings.HasPrefix(arg, `"`) {
+				s, err := strconv.Unquote(arg)
+				if err != nil {
+					base.Fatalf("-d=replay: %s: bad header line %q", filename, sc.Text())
+				}
+				args[i] = s
+			}
+		}
+		entries = append(entries, entry{f[0], args})
+	}
+	if len(entries) == 0 || entries[0].kind != "capture" {
+		base.Fatalf("-d=replay: %s is not a capture file", filename)
+	}
+	if path := entries[0].args[0]; path != base.Ctxt.Pkgpath {
+		base.Fatalf("-d=replay: %s holds package %q, but compiling package %q (-p)", filename, path, base.Ctxt.Pkgpath)
+	}
+
+	target := typecheck.Target
+	for _, e := range entries {
+		if e.kind == "import" {
+			if _, _, err := readImportFile(e.args[0], target, nil, nil); err != nil {
+				base.Errorf("could not import %q: %v", e.args[0], err)
+				base.ErrorExit()
+			}
+		}
+	}
+	typecheck.ReadImports(types.LocalPkg, exportData[1:])
+	types.NumImport[types.LocalPkg.Name]--
+	typecheck.TypecheckAllowed = true
+
+	lookup := func(name string) *ir.Name {
+		n := typecheck.ImportDecl(types.LocalPkg.Lookup(name))
+		if n == nil {
+			base.Fatalf("-d=replay: %s: missing declaration of %s", filename, name)
+		}
+		return n
+	}
+
+	// Read in the declarations first, and then the bodies, which may
+	// refer to any of them.
+	methods := make(map[*types.Sym]*ir.Name)
+	var funcs []*ir.Func
+	bodyless := make(map[*ir.Func]bool)
+	for _, e := range entries {
+		switch e.kind {
+		case "extern":
+			n := lookup(e.args[0])
+			if n.Op() == ir.OTYPE && !n.Type().IsInterface() {
+				for _, m := range n.Type().Methods().Slice() {
+					methods[m.Nname.Sym()] = m.Nname.(*ir.Name)
+				}
+			}
+			target.Externs = append(target.Externs, n)
+		case "func", "decl":
+			n := methods[types.LocalPkg.Lookup(e.args[0])]
+			if n == nil {
+				n = lookup(e.args[0])
+			}
+			funcs = append(funcs, n.Func)
+			if e.kind == "decl" {
+				bodyless[n.Func] = true
+			}
+		}
+	}
+	typecheck.DeclareUniverse()
+
+	for i, n := range target.Externs {
+		if n.Op() == ir.ONAME {
+			target.Externs[i] = typecheck.Expr(n)
+		} else {
+			n.SetTypecheck(1)
+		}
+	}
+	for _, fn := range funcs {
+		fn.Nname.Defn = fn
+		fn.Body, fn.Dcl = replayBody(fn)
+		if bodyless[fn] {
+			fn.Body = nil
+		} else if len(fn.Body) == 0 {
+			// The exporter drops empty blocks; keep fn from
+			// looking like a function without a body.
+			fn.Body = []ir.Node{ir.NewBlockStmt(src.NoXPos, nil)}
+		}
+		fn.Body = replayDecls(fn.Body)
+		fn.SetTypecheck(1)
+		fn.Nname.SetTypecheck(1)
+		target.Decls = append(target.Decls, fn)
+		replayClosures(fn.Body, fn)
+	}
+	if n := typecheck.ImportDecl(types.LocalPkg.Lookup(captureInit)); n != nil {
+		body, dcl := replayBody(n.Func)
+		for _, n := range dcl {
+			n.Curfn = typecheck.InitTodoFunc
+		}
+		typecheck.InitTodoFunc.Dcl = append(typecheck.InitTodoFunc.Dcl, dcl...)
+		for _, stmt := range body {
+			switch stmt := stmt.(type) {
+			case *ir.AssignStmt:
+				setDefn(stmt.X, stmt)
+			case *ir.AssignListStmt:
+				for _, lhs := range stmt.Lhs {
+					setDefn(lhs, stmt)
+				}
+			}
+			target.Decls = append(target.Decls, stmt)
+		}
+		replayClosures(body, nil)
+	}
+
+	// Put the functions back in the order they were captured in, which
+	// is the order they are compiled in.
+	var decls, stmts []ir.Node
+	byName := make(map[string]ir.Node)
+	for _, n := range target.Decls {
+		if n.Op() == ir.ODCLFUNC {
+			byName[n.Sym().Name] = n
+		} else {
+			stmts = append(stmts, n)
+		}
+	}
+	for _, e := range entries {
+		switch e.kind {
+		case "func", "decl", "closure":
+			n := byName[e.args[0]]
+			if n == nil {
+				base.Fatalf("-d=replay: %s: missing function %s", filename, e.args[0])
+			}
+			decls = append(decls, n)
+		}
+	}
+	target.Decls = append(decls, stmts...)
+
+	for _, e := range entries {
+		switch e.kind {
+		case "local":
+			n := lookup(e.args[0])
+			n.Sym().Def = nil
+			sym := types.LocalPkg.Lookup(e.args[0][:strings.LastIndex(e.args[0], "·")])
+			n.SetSym(sym)
+			n.Type().SetSym(sym)
+			n.Type().SetVargen()
+		case "init":
+			target.Inits = append(target.Inits, lookup(e.args[0]).Func)
+		case "export":
+			typecheck.Export(lookup(e.args[0]))
+		case "asm":
+			n := lookup(e.args[0])
+			n.Sym().SetAsm(true)
+			target.Asms = append(target.Asms, n)
+		case "cgo":
+			target.CgoPragmas = append(target.CgoPragmas, e.args)
+		}
+	}
+}
+
+// replayBody reads in the body and declarations of fn, a function
+// in a capture file. Unlike ImportedBody, it leaves marking the
+// variables whose addresses are taken to Main, which does it after
+// dead code elimination.
+func replayBody(fn *ir.Func) ([]ir.Node, []*ir.Name) {
+	typecheck.ImportBody(fn)
+	body, dcl := fn.Inl.Body, fn.Inl.Dcl
+	fn.Inl = nil
+	return body, dcl
+}
+
+// replayClosures names the closures in list, which appears in outerfn,
+// or at package scope if outerfn is nil, and adds them, and the
+// closures nested within them, to typecheck.Target.Decls, as the
+// type checker would have.
+func replayClosures(list []ir.Node, outerfn *ir.Func) {
+	ir.VisitList(list, func(n ir.Node) {
+		clo, ok := n.(*ir.ClosureExpr)
+		if !ok {
+			return
+		}
+		fn := clo.Func
+		fn.SetIsHiddenClosure(outerfn != nil)
+		ir.NameClosure(clo, outerfn)
+		fn.SetTypecheck(1)
+		fn.Nname.SetTypecheck(1)
+		fn.Body = replayDecls(fn.Body)
+		ir.UseClosure(clo, typecheck.Target)
+		replayClosures(fn.Body, fn)
+	})
+}
+
+// setDefn records as as the definition of n, if n is a package-scope
+// variable, for the initialization order.
+func setDefn(n ir.Node, as ir.Node) {
+	if n, ok := n.(*ir.Name); ok && n.Class == ir.PEXTERN {
+		n.Defn = as
+	}
+}
+
+// replayDecls undoes what exporting and importing does to the
+// declarations of local variables in list, a function body: the
+// importer follows each ODCL with an assignment of the zero value of
+// its own, and leaves the Defn of the variables, and the Def of range
+// statements, unset.
+func replayDecls(list []ir.Node) []ir.Node {
+	ir.VisitList(list, func(n ir.Node) {
+		if init, ok := n.(ir.InitNode); ok && len(init.Init()) > 0 {
+			init.SetInit(replayDeclList(init.Init()))
+			if lhs, def := assignLHS(n); def != nil {
+				// "x := y" or "for x := range y".
+				for _, init := range init.Init() {
+					if init.Op() != ir.ODCL {
+						continue
+					}
+					if x := init.(*ir.Decl).X; hasNode(lhs, x) {
+						x.Defn = n
+						*def = true
+					}
+				}
+			}
+		}
+		switch n := n.(type) {
+		case *ir.BlockStmt:
+			n.List = replayDeclList(n.List)
+		case *ir.IfStmt:
+			n.Body = replayDeclList(n.Body)
+			n.Else = replayDeclList(n.Else)
+		case *ir.ForStmt:
+			n.Body = replayDeclList(n.Body)
+		case *ir.RangeStmt:
+			n.Body = replayDeclList(n.Body)
+		case *ir.CaseClause:
+			n.Body = replayDeclList(n.Body)
+		case *ir.CommClause:
+			n.Body = replayDeclList(n.Body)
+		}
+	})
+	return replayDeclList(list)
+}
+
+// replayDeclList removes from list the assignments that the importer
+// adds after each ODCL, and sets the Defn of variables declared by
+// "var x = y", whose ODCLs are followed by the assignment.
+func replayDeclList(list []ir.Node) []ir.Node {
+	out := list[:0]
+	for i := 0; i < len(list); i++ {
+		n := list[i]
+		out = append(out, n)
+		if n.Op() == ir.ODCL {
+			i++
+			if i == len(list) {
+				base.FatalfAt(n.Pos(), "-d=replay: missing assignment after %v", n)
+			}
+			if as, ok := list[i].(*ir.AssignStmt); !ok || as.X != n.(*ir.Decl).X || as.Y != nil {
+				base.FatalfAt(n.Pos(), "-d=replay: missing assignment after %v", n)
+			}
+		}
+	}
+	for i, n := range out {
+		if n.Op() != ir.ODCL {
+			continue
+		}
+		j := i
+		for j < len(out) && out[j].Op() == ir.ODCL {
+			j++
+		}
+		if j == len(out) {
+			continue
+		}
+		if as, ok := out[j].(*ir.AssignStmt); ok && as.Y == nil {
+			continue // "var x T"
+		}
+		if x := n.(*ir.Decl).X; x.Defn == nil {
+			if lhs, def := assignLHS(out[j]); def != nil && hasNode(lhs, x) {
+				x.Defn = out[j]
+			}
+		}
+	}
+	return out
+}
+
+// assignLHS returns the left-hand sides of n and a pointer to its Def
+// field, if n is an assignment or range statement.
+func assignLHS(n ir.Node) ([]ir.Node, *bool) {
+	switch n := n.(type) {
+	case *ir.AssignStmt:
+		return []ir.Node{n.X}, &n.Def
+	case *ir.AssignListStmt:
+		return n.Lhs, &n.Def
+	case *ir.RangeStmt:
+		return []ir.Node{n.Key, n.Value}, &n.Def
+	}
+	return nil, nil
+}
+
+func hasNode(list []ir.Node, n ir.Node) bool {
+	for _, x := range list {
+		if x == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/types2"
+)
+
+// checkCgo reports, for -d=cgocheck, obvious violations of the cgo
+// pointer passing rules in code translated by cgo: arguments of calls
+// to C that point to Go memory containing Go pointers, and stores of
+// Go pointers into memory allocated by C. The runtime only finds these
+// when GODEBUG=cgocheck is enabled and the call or store happens, and
+// the pointers it finds are not nil.
+//
+// Only types that always refer to Go memory count as Go pointers:
+// slices, maps, channels, functions, interfaces, and pointers to types
+// not declared by C. Pointers to C types and unsafe.Pointer values
+// might point to C memory, and so are not reported.
+func checkCgo(m *posMap, self *types2.Package, files []*syntax.File, info *types2.Info) {
+	if _, ok := self.Scope().Lookup("_cgoCheckPointer").(*types2.Func); !ok {
+		return // not translated by cgo
+	}
+	c := cgoChecker{m: m, self: self, info: info, defs: make(map[*types2.Var]syntax.Expr), cmem: make(map[*types2.Var]bool)}
+
+	// Find the variables only ever assigned pointers to C memory.
+	for _, file := range files {
+		syntax.Inspect(file, func(n syntax.Node) bool {
+			switch n := n.(type) {
+			case *syntax.AssignStmt:
+				if n.Rhs != nil {
+					c.assign(unpackListExpr(n.Lhs), unpackListExpr(n.Rhs))
+				}
+			case *syntax.VarDecl:
+				if n.Values == nil {
+					break // nil points to no memory at all
+				}
+				names := make([]syntax.Expr, len(n.NameList))
+				for i, name := range n.NameList {
+					names[i] = name
+				}
+				c.assign(names, unpackListExpr(n.Values))
+			}
+			return true
+		})
+	}
+
+	for _, file := range files {
+		syntax.Inspect(file, func(n syntax.Node) bool {
+			switch n := n.(type) {
+			case *syntax.CallExpr:
+				c.call(n)
+			case *syntax.AssignStmt:
+				if n.Rhs != nil && n.Op == 0 {
+					lhs, rhs := unpackListExpr(n.Lhs), unpackListExpr(n.Rhs)
+					if len(lhs) == len(rhs) {
+						for i := range lhs {
+							c.store(lhs[i], rhs[i])
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+type cgoChecker struct {
+	m    *posMap
+	self *types2.Package
+	info *types2.Info
+
+	// defs maps the variables declared by cgo's translation of a
+	// call to the expressions they are initialized with.
+	defs map[*types2.Var]syntax.Expr
+
+	// cmem records for each variable assigned to whether all the
+	// values assigned to it point to C memory.
+	cmem map[*types2.Var]bool
+}
+
+// assign records the assignment of rhs to lhs.
+func (c *cgoChecker) assign(lhs, rhs []syntax.Expr) {
+	for i, x := range lhs {
+		name, ok := x.(*syntax.Name)
+		if !ok {
+			continue
+		}
+		v, ok := c.info.Defs[name].(*types2.Var)
+		if !ok {
+			v, ok = c.info.Uses[name].(*types2.Var)
+		}
+		if !ok || v.Parent() == c.self.Scope() {
+			continue // package-level variables may be assigned anywhere
+		}
+		var y syntax.Expr
+		if len(lhs) == len(rhs) {
+			y = rhs[i]
+		}
+		if y != nil && strings.HasPrefix(v.Name(), "_cgo") {
+			c.defs[v] = y
+		}
+		cmem, seen := c.cmem[v]
+		c.cmem[v] = (cmem || !seen) && y != nil && c.isCMemory(y)
+	}
+}
+
+// isCMemory reports whether x is a pointer to memory allocated by C:
+// the result of C.malloc or another C function returning a pointer, or
+// of a variable only ever assigned such pointers, possibly converted
+// to another pointer type.
+func (c *cgoChecker) isCMemory(x syntax.Expr) bool {
+	x = c.unconvert(x)
+	switch x := x.(type) {
+	case *syntax.CallExpr:
+		name, ok := unparen(x.Fun).(*syntax.Name)
+		if !ok || name.Value != "_CMalloc" && !strings.HasPrefix(name.Value, "_Cfunc_") {
+			return false
+		}
+		if fn, ok := c.info.Uses[name].(*types2.Func); !ok || fn.Pkg() != c.self {
+			return false
+		}
+		return isPointerLike(c.info.Types[x].Type)
+	case *syntax.Name:
+		v, ok := c.info.Uses[x].(*types2.Var)
+		return ok && c.cmem[v]
+	}
+	return false
+}
+
+// unconvert returns x with parentheses and conversions between pointer
+// types removed.
+func (c *cgoChecker) unconvert(x syntax.Expr) syntax.Expr {
+	for {
+		x = unparen(x)
+		call, ok := x.(*syntax.CallExpr)
+		if !ok || len(call.ArgList) != 1 || !c.info.Types[call.Fun].IsType() {
+			return x
+		}
+		if !isPointerLike(c.info.Types[call].Type) || !isPointerLike(c.info.Types[call.ArgList[0]].Type) {
+			return x
+		}
+		x = call.ArgList[0]
+	}
+}
+
+// call checks the pointer cgo checks at run time when passing it to C.
+func (c *cgoChecker) call(call *syntax.CallExpr) {
+	name, ok := unparen(call.Fun).(*syntax.Name)
+	if !ok || name.Value != "_cgoCheckPointer" || len(call.ArgList) != 2 {
+		return
+	}
+	if fn, ok := c.info.Uses[name].(*types2.Func); !ok || fn.Pkg() != c.self {
+		return
+	}
+
+	// Find the argument as written, before cgo assigned it to a
+	// temporary variable.
+	arg := call.ArgList[0]
+	for i := 0; i < 10; i++ {
+		arg = c.unconvert(arg)
+		name, ok := arg.(*syntax.Name)
+		if !ok {
+			break
+		}
+		v, _ := c.info.Uses[name].(*types2.Var)
+		def, ok := c.defs[v]
+		if !ok {
+			break
+		}
+		arg = def
+	}
+
+	ptr, ok := c.under(arg).(*types2.Pointer)
+	if !ok || c.isCMemory(arg) {
+		return
+	}
+	if elem := ptr.Elem(); c.hasGoPointers(elem, 0) {
+		qual := types2.RelativeTo(c.self)
+		base.WarnfAt(c.m.makeXPos(arg.Pos()), "cgo argument has Go pointer to Go pointer: it points to %s, which contains Go pointers", types2.TypeString(elem, qual))
+	}
+}
+
+// under returns the underlying type of x, or nil if x has no type.
+func (c *cgoChecker) under(x syntax.Expr) types2.Type {
+	if t := c.info.Types[x].Type; t != nil {
+		return t.Underlying()
+	}
+	return nil
+}
+
+// store checks the assignment of x to lhs.
+func (c *cgoChecker) store(lhs, x syntax.Expr) {
+	if !c.inCMemory(lhs) {
+		return
+	}
+	tv := c.info.Types[x]
+	if tv.Type == nil || tv.IsNil() || !c.hasGoPointers(tv.Type, 0) || c.isCMemory(x) {
+		return
+	}
+	qual := types2.RelativeTo(c.self)
+	base.WarnfAt(c.m.makeXPos(lhs.Pos()), "Go pointer stored into C memory: %s has type %s, which contains Go pointers", syntax.String(x), types2.TypeString(tv.Type, qual))
+}
+
+// inCMemory reports whether the variable x denotes is in memory
+// allocated by C.
+func (c *cgoChecker) inCMemory(x syntax.Expr) bool {
+	switch x := unparen(x).(type) {
+	case *syntax.Operation:
+		return x.Op == syntax.Mul && x.Y == nil && c.isCMemory(x.X)
+	case *syntax.SelectorExpr:
+		if _, ok := c.under(x.X).(*types2.Pointer); ok {
+			return c.isCMemory(x.X)
+		}
+		return c.inCMemory(x.X)
+	case *syntax.IndexExpr:
+		switch c.under(x.X).(type) {
+		case *types2.Pointer:
+			return c.isCMemory(x.X)
+		case *types2.Array:
+			return c.inCMemory(x.X)
+		}
+	}
+	return false
+}
+
+// hasGoPointers reports whether values of type t always contain a
+// pointer to Go memory if they contain a pointer that is not nil.
+func (c *cgoChecker) hasGoPointers(t types2.Type, depth int) bool {
+	if depth > 10 || isCType(t) {
+		return false
+	}
+	if _, ok := t.(*types2.TypeParam); ok {
+		return false
+	}
+	switch t := t.Underlying().(type) {
+	case *types2.Pointer:
+		return !isCType(t.Elem())
+	case *types2.Slice, *types2.Map, *types2.Chan, *types2.Signature, *types2.Interface:
+		return true
+	case *types2.Array:
+		return t.Len() > 0 && c.hasGoPointers(t.Elem(), depth+1)
+	case *types2.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if c.hasGoPointers(t.Field(i).Type(), depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCType reports whether t is a type declared by cgo for a C type.
+func isCType(t types2.Type) bool {
+	named, ok := t.(*types2.Named)
+	return ok && strings.HasPrefix(named.Obj().Name(), "_Ctype_")
+}
+
+// isPointerLike reports whether t is a pointer type or unsafe.Pointer.
+func isPointerLike(t types2.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t := t.Underlying().(type) {
+	case *types2.Pointer:
+		return true
+	case *types2.Basic:
+		return t.Kind() == types2.UnsafePointer
+	}
+	return false
+}
@@ -65,6 +65,7 @@ func (g *irgen) constDecl(out *ir.Nodes, decl *syntax.ConstDecl) {
 
 	for _, name := range decl.NameList {
 		name, obj := g.def(name)
+		g.deprecation(decl.Pragma, name)
 
 		// For untyped numeric constants, make sure the value
 		// representation matches what the rest of the
@@ -104,9 +105,15 @@ func (g *irgen) funcDecl(out *ir.Nodes, decl *syntax.FuncDecl) {
 	fn.Nname.Defn = fn
 
 	fn.Pragma = g.pragmaFlags(decl.Pragma, funcPragmas)
+	g.deprecation(decl.Pragma, fn.Nname)
 	if fn.Pragma&ir.Systemstack != 0 && fn.Pragma&ir.Nosplit != 0 {
 		base.ErrorfAt(fn.Pos(), "go:nosplit and go:systemstack cannot be combined")
 	}
+	if fn.Pragma&ir.CPULevels != 0 {
+		if msg := goCPUError(decl); msg != "" {
+			base.ErrorfAt(fn.Pos(), "%s", msg)
+		}
+	}
 	if fn.Pragma&ir.Nointerface != 0 {
 		// Propagate //go:nointerface from Func.Pragma to Field.Nointerface.
 		// This is a bit roundabout, but this is the earliest point where we've
@@ -183,12 +190,34 @@ func (g *irgen) typeDecl(out *ir.Nodes, decl *syntax.TypeDecl) {
 		ntyp.SetVargen()
 	}
 
-	pragmas := g.pragmaFlags(decl.Pragma, typePragmas)
+	allowed := typePragmas
+	if ir.CurFunc == nil {
+		allowed |= globalTypePragmas
+	}
+	cacheAlign := cacheAlignDecl(decl)
+	pragmas := g.pragmaFlags(decl.Pragma, allowed)
 	name.SetPragma(pragmas) // TODO(mdempsky): Is this still needed?
+	g.deprecation(decl.Pragma, name)
 
 	if pragmas&ir.NotInHeap != 0 {
 		ntyp.SetNotInHeap(true)
 	}
+	if pragmas&ir.NoCompare != 0 {
+		ntyp.SetNoCompare(true)
+	}
+	if pragmas&ir.NoHash != 0 {
+		ntyp.SetNoHash(true)
+	}
+	if pragmas&ir.MustUse != 0 {
+		ntyp.SetMustUse(true)
+	}
+	if pragmas&ir.CacheAlign != 0 {
+		if !cacheAlign {
+			base.ErrorfAt(g.pos(decl), "//go:cachealign only applies to struct types that are not generic")
+		}
+		// types2 ignores pragmas; gcSizes pads the same types.
+		ntyp.SetCacheAlign(cacheAlign)
+	}
 
 	// We need to use g.typeExpr(decl.Type) here to ensure that for
 	// chained, defined-type declarations like:
@@ -252,6 +281,15 @@ func (g *irgen) varDecl(out *ir.Nodes, decl *syntax.VarDecl) {
 	if decl.Pragma != nil {
 		pragma := decl.Pragma.(*pragmas)
 		varEmbed(g.makeXPos, names[0], decl, pragma, g.haveEmbed)
+		varPragmas := globalVarPragmas
+		if ir.CurFunc != nil {
+			varPragmas = localVarPragmas
+		}
+		for _, name := range names {
+			name.SetPragma(pragma.Flag & varPragmas)
+			g.deprecation(pragma, name)
+		}
+		pragma.Flag &^= varPragmas
 		g.reportUnused(pragma)
 	}
 
@@ -326,6 +364,14 @@ func (g *irgen) pragmaFlags(pragma syntax.Pragma, allowed ir.PragmaFlag) ir.Prag
 	return present
 }
 
+// deprecation records the "Deprecated:" notice of the doc comment of a
+// package-level declaration, if any, for export.
+func (g *irgen) deprecation(pragma syntax.Pragma, name *ir.Name) {
+	if p, ok := pragma.(*pragmas); ok && p.Deprecated != "" && ir.CurFunc == nil {
+		typecheck.SetDeprecation(name, p.Deprecated)
+	}
+}
+
 // reportUnused reports errors about any unused pragmas.
 func (g *irgen) reportUnused(pragma *pragmas) {
 	for _, pos := range pragma.Pos {
@@ -0,0 +1,93 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types2"
+)
+
+// checkDeprecated reports, for -deprecated, the uses of package-level
+// declarations and methods of other packages whose doc comments have a
+// "Deprecated:" paragraph, quoting it, since it usually names the
+// replacement. With -deprecated=1 the uses are warnings, and with
+// -deprecated=2 they are errors.
+//
+// The notices are read from export data, so checkDeprecated must run
+// after all imported declarations used have been loaded by irgen.
+func (g *irgen) checkDeprecated(noders []*noder) {
+	for _, p := range noders {
+		syntax.Inspect(p.file, func(n syntax.Node) bool {
+			if name, ok := n.(*syntax.Name); ok {
+				g.checkDeprecatedUse(name)
+			}
+			return true
+		})
+	}
+}
+
+func (g *irgen) checkDeprecatedUse(name *syntax.Name) {
+	obj := g.info.Uses[name]
+	if obj == nil || obj.Pkg() == nil || obj.Pkg() == g.self || obj.Pkg() == types2.Unsafe {
+		return
+	}
+
+	what := obj.Pkg().Name() + "." + obj.Name()
+	var n *ir.Name
+	switch obj := obj.(type) {
+	case *types2.Func:
+		if recv := types2.AsSignature(obj.Type()).Recv(); recv != nil {
+			named, ok := deref2(recv.Type()).(*types2.Named)
+			if !ok {
+				return
+			}
+			what = obj.Pkg().Name() + "." + named.Obj().Name() + "." + obj.Name()
+			n = g.method(named, obj.Name())
+			break
+		}
+		n = g.obj(obj)
+	case *types2.Const, *types2.TypeName:
+		n = g.obj(obj)
+	case *types2.Var:
+		if obj.IsField() {
+			return
+		}
+		n = g.obj(obj)
+	default:
+		return
+	}
+	if n == nil {
+		return
+	}
+
+	notice, ok := typecheck.Deprecation(n)
+	if !ok {
+		return
+	}
+	msg := what + " is deprecated"
+	if notice != "" {
+		msg += ": " + notice
+	}
+	pos := g.pos(name)
+	if base.Flag.Deprecated > 1 {
+		base.ErrorfAt(pos, "%s", msg)
+	} else {
+		base.WarnfAt(pos, "%s", msg)
+	}
+}
+
+// method returns the declaration of the method of the imported type
+// named with the given name, or nil if it is an interface method.
+func (g *irgen) method(named *types2.Named, name string) *ir.Name {
+	for _, m := range g.obj(named.Obj()).Type().Methods().Slice() {
+		if m.Sym.Name == name && m.Nname != nil {
+			return m.Nname.(*ir.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+)
+
+var relocNames = [numRelocs]string{
+	relocString:  "String",
+	relocMeta:    "Meta",
+	relocPosBase: "PosBase",
+	relocPkg:     "Pkg",
+	relocName:    "Name",
+	relocType:    "Type",
+	relocObj:     "Obj",
+	relocObjExt:  "ObjExt",
+	relocObjDict: "ObjDict",
+	relocBody:    "Body",
+}
+
+// dumpPkg writes the elements of pr's export data to w, for
+// -d=unifieddump.
+//
+// Elements are printed in order, one section after another. Each
+// element starts with a line naming it, such as "Type[3]:". The sync
+// markers within it follow, one per line, with the values written
+// after them appended to the line: booleans, integers, operators,
+// which are printed by name, and references to other elements, which
+// are printed by name, or quoted for strings. Writer stack frames are omitted, so the output only changes
+// when the export data does.
+func dumpPkg(w io.Writer, pr *pkgDecoder) {
+	if !enableSync {
+		base.Fatalf("-d=unifieddump requires sync markers")
+	}
+	for k := reloc(0); k < numRelocs; k++ {
+		for idx := 0; idx < pr.numElems(k); idx++ {
+			if k == relocString {
+				fmt.Fprintf(w, "%s[%d]: %s\n", relocNames[k], idx, strconv.Quote(pr.stringIdx(idx)))
+				continue
+			}
+			fmt.Fprintf(w, "%s[%d]:\n", relocNames[k], idx)
+			r := pr.newDecoderRaw(k, idx)
+			dumpElem(w, &r)
+		}
+	}
+}
+
+// dumpElem writes the sync markers and values remaining in r to w.
+func dumpElem(w io.Writer, r *decoder) {
+	var line strings.Builder
+	flush := func() {
+		if line.Len() > 0 {
+			fmt.Fprintf(w, "\t%s\n", line.String())
+			line.Reset()
+		}
+	}
+
+	for r.data.Len() > 0 {
+		switch m := r.rawSync(); m {
+		case syncBool:
+			x, err := r.data.ReadByte()
+			r.checkErr(err)
+			fmt.Fprintf(&line, " %v", x != 0)
+		case syncInt64:
+			fmt.Fprintf(&line, " %d", r.rawVarint())
+		case syncUint64:
+			fmt.Fprintf(&line, " %d", r.rawUvarint())
+		case syncOp:
+			// Print operators by name, so the output doesn't change
+			// when ir.Op values are renumbered.
+			if m := r.rawSync(); m != syncUint64 {
+				base.Fatalf("export data desync: section %v, index %v: found %v after Op", relocNames[r.k], r.idx, m)
+			}
+			flush()
+			fmt.Fprintf(&line, "Op %s", ir.Op(r.rawUvarint()).String())
+		case syncUseReloc:
+			if m := r.rawSync(); m != syncUint64 {
+				base.Fatalf("export data desync: section %v, index %v: found %v after UseReloc", relocNames[r.k], r.idx, m)
+			}
+			e := r.relocs[r.rawUvarint()]
+			if e.kind == relocString {
+				fmt.Fprintf(&line, " %s", strconv.Quote(r.common.stringIdx(e.idx)))
+			} else {
+				fmt.Fprintf(&line, " %s[%d]", relocNames[e.kind], e.idx)
+			}
+		default:
+			flush()
+			line.WriteString(m.String())
+		}
+	}
+	flush()
+}
+
+// rawSync reads a sync marker and skips the writer stack frames
+// recorded with it.
+func (r *decoder) rawSync() syncMarker {
+	m := syncMarker(r.rawUvarint())
+	for n := r.rawUvarint(); n > 0; n-- {
+		r.rawUvarint()
+	}
+	return m
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder_test
+
+import (
+	"bytes"
+	"flag"
+	"internal/testenv"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var flagUpdate = flag.Bool("update", false, "update testdata/dump/*.golden")
+
+// TestUnifiedDump compiles each package in testdata/dump with
+// -d=unifieddump and compares the printed export data with the
+// corresponding .golden file. Run with -update to rewrite the golden
+// files after an intended change to the export data format.
+func TestUnifiedDump(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+
+	files, err := filepath.Glob(filepath.Join("testdata", "dump", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		file := file
+		name := strings.TrimSuffix(filepath.Base(file), ".go")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := filepath.Abs(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p="+name,
+				"-d=unified=1,unifieddump=1", "-trimpath="+filepath.Dir(src), "-o", filepath.Join(dir, name+".o"), src)
+			got, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%v: %v\n%s", cmd, err, got)
+			}
+
+			golden := strings.TrimSuffix(file, ".go") + ".golden"
+			if *flagUpdate {
+				if err := ioutil.WriteFile(golden, got, 0666); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("export data of %s does not match %s:\n%s", file, golden, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"go/constant"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/types2"
+)
+
+// checkExhaustive reports, for -d=exhaustive, expression switches
+// without a default case whose tag has a defined integer or string
+// type and that do not have a case for every value of the package-level
+// constants of that type. Constants from other packages only count if
+// they are exported, since the switch could not name them otherwise.
+//
+// Switches with a case that is not constant are not checked, since
+// its value is not known until run time.
+func checkExhaustive(m *posMap, self *types2.Package, files []*syntax.File, info *types2.Info) {
+	for _, file := range files {
+		syntax.Inspect(file, func(n syntax.Node) bool {
+			if stmt, ok := n.(*syntax.SwitchStmt); ok {
+				checkSwitch(m, self, stmt, info)
+			}
+			return true
+		})
+	}
+}
+
+func checkSwitch(m *posMap, self *types2.Package, stmt *syntax.SwitchStmt, info *types2.Info) {
+	if stmt.Tag == nil {
+		return
+	}
+	if _, ok := stmt.Tag.(*syntax.TypeSwitchGuard); ok {
+		return
+	}
+	typ, ok := info.Types[stmt.Tag].Type.(*types2.Named)
+	if !ok {
+		return
+	}
+	if basic, ok := typ.Underlying().(*types2.Basic); !ok || basic.Info()&(types2.IsInteger|types2.IsString) == 0 {
+		return
+	}
+
+	// The values the switch has cases for.
+	covered := make(map[string]bool)
+	for _, clause := range stmt.Body {
+		if clause.Cases == nil {
+			return // default
+		}
+		for _, e := range unpackListExpr(clause.Cases) {
+			val := info.Types[e].Value
+			if val == nil {
+				return
+			}
+			covered[val.ExactString()] = true
+		}
+	}
+
+	// The values of the constants of typ that the switch is missing,
+	// named by the alphabetically first constant with that value.
+	pkg := typ.Obj().Pkg()
+	if pkg == nil {
+		return // predeclared
+	}
+	local := pkg == self
+	var missing []string
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types2.Const)
+		if !ok || !types2.Identical(obj.Type(), typ) || !local && !obj.Exported() {
+			continue
+		}
+		key := obj.Val().ExactString()
+		if obj.Val().Kind() == constant.Unknown || covered[key] {
+			continue
+		}
+		covered[key] = true
+		if !local {
+			name = pkg.Name() + "." + name
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) > 0 {
+		qual := types2.RelativeTo(self)
+		base.WarnfAt(m.makeXPos(stmt.Pos()), "switch on %s is missing cases for %s", types2.TypeString(typ, qual), strings.Join(missing, ", "))
+	}
+}
@@ -599,3 +599,181 @@ func importName(sym *types.Sym) ir.Node {
 	}
 	return n
 }
+
+// checkImportCycle reports an error if a package imported by the
+// local package pkg depends on pkg in turn, according to the export
+// data it was compiled against. The go command does not allow this,
+// but it can happen when packages are compiled by hand or by other
+// build systems against stale export data.
+//
+// The error lists each edge of the shortest such cycle along with
+// the imported declarations that create it, and suggests breaking
+// the edge that the fewest declarations depend on.
+func checkImportCycle(m *posMap, pkg *types2.Package, files []*syntax.File, info *types2.Info) {
+	self := base.Ctxt.Pkgpath
+	if self == "" {
+		return
+	}
+
+	for _, file := range files {
+		for _, decl := range file.DeclList {
+			decl, ok := decl.(*syntax.ImportDecl)
+			if !ok {
+				continue
+			}
+			imported := pkgNameOf(info, decl).Imported()
+			if path := importPathTo(imported, self); path != nil {
+				reportImportCycle(m.makeXPos(decl.Pos()), pkg, path)
+				return
+			}
+		}
+	}
+}
+
+// importPathTo returns the shortest list of packages, starting with
+// from and ending with a package with the given path, in which each
+// package lists the next among its imports, or nil if there is none.
+func importPathTo(from *types2.Package, path string) []*types2.Package {
+	prev := map[*types2.Package]*types2.Package{from: nil}
+	queue := []*types2.Package{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p.Path() == path {
+			var list []*types2.Package
+			for ; p != nil; p = prev[p] {
+				list = append([]*types2.Package{p}, list...)
+			}
+			return list
+		}
+		for _, imp := range p.Imports() {
+			if _, seen := prev[imp]; !seen {
+				prev[imp] = p
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return nil
+}
+
+// reportImportCycle reports the import cycle formed by the local
+// package pkg importing path[0], at pos, and each package in path
+// depending on the next.
+func reportImportCycle(pos src.XPos, pkg *types2.Package, path []*types2.Package) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "import cycle not allowed\n\t%s imports %s", pkg.Path(), path[0].Path())
+
+	best, bestCount := -1, 0
+	for i := 0; i+1 < len(path); i++ {
+		from, to := path[i], path[i+1]
+		decls := declsReferringTo(from, to)
+		fmt.Fprintf(&b, "\n\t%s depends on %s", from.Path(), to.Path())
+		if len(decls) == 0 {
+			b.WriteString(" through function bodies or initializers only")
+			continue
+		}
+		fmt.Fprintf(&b, " through %s (%v)", types2.ObjectString(decls[0], types2.RelativeTo(from)), decls[0].Pos())
+		if n := len(decls) - 1; n == 1 {
+			b.WriteString(" and 1 other declaration")
+		} else if n > 1 {
+			fmt.Fprintf(&b, " and %d other declarations", n)
+		}
+		if best < 0 || len(decls) < bestCount {
+			best, bestCount = i, len(decls)
+		}
+	}
+	if best >= 0 {
+		fmt.Fprintf(&b, "\n\tto break the cycle, remove the dependency of %s on %s", path[best].Path(), path[best+1].Path())
+	}
+	base.ErrorfAt(pos, "%s", b.String())
+}
+
+// declsReferringTo returns the package-level declarations of from
+// whose types refer to a type declared in package to, directly or
+// through other types, sorted by name.
+func declsReferringTo(from, to *types2.Package) []types2.Object {
+	var decls []types2.Object
+	scope := from.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		typ := obj.Type()
+		if tname, ok := obj.(*types2.TypeName); ok {
+			if named, ok := typ.(*types2.Named); ok && tname.Pkg() == from {
+				typ = named.Underlying()
+				if refersTo(typ, to, make(map[types2.Type]bool)) {
+					decls = append(decls, obj)
+					continue
+				}
+				for i := 0; i < named.NumMethods(); i++ {
+					if refersTo(named.Method(i).Type(), to, make(map[types2.Type]bool)) {
+						decls = append(decls, obj)
+						break
+					}
+				}
+				continue
+			}
+		}
+		if refersTo(typ, to, make(map[types2.Type]bool)) {
+			decls = append(decls, obj)
+		}
+	}
+	return decls
+}
+
+// refersTo reports whether typ refers to a type declared in pkg.
+func refersTo(typ types2.Type, pkg *types2.Package, seen map[types2.Type]bool) bool {
+	if typ == nil || seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	switch t := typ.(type) {
+	case *types2.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == pkg.Path() {
+			return true
+		}
+		targs := t.TypeArgs()
+		for i := 0; i < targs.Len(); i++ {
+			if refersTo(targs.At(i), pkg, seen) {
+				return true
+			}
+		}
+		return refersTo(t.Underlying(), pkg, seen)
+	case *types2.Pointer:
+		return refersTo(t.Elem(), pkg, seen)
+	case *types2.Slice:
+		return refersTo(t.Elem(), pkg, seen)
+	case *types2.Array:
+		return refersTo(t.Elem(), pkg, seen)
+	case *types2.Chan:
+		return refersTo(t.Elem(), pkg, seen)
+	case *types2.Map:
+		return refersTo(t.Key(), pkg, seen) || refersTo(t.Elem(), pkg, seen)
+	case *types2.Signature:
+		return refersTo(t.Params(), pkg, seen) || refersTo(t.Results(), pkg, seen)
+	case *types2.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			if refersTo(t.At(i).Type(), pkg, seen) {
+				return true
+			}
+		}
+	case *types2.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if refersTo(t.Field(i).Type(), pkg, seen) {
+				return true
+			}
+		}
+	case *types2.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			if refersTo(t.Method(i).Type(), pkg, seen) {
+				return true
+			}
+		}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if refersTo(t.EmbeddedType(i), pkg, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	"cmd/compile/internal/base"
 	"cmd/compile/internal/dwarfgen"
 	"cmd/compile/internal/ir"
+	"cmd/compile/internal/pgo"
 	"cmd/compile/internal/syntax"
 	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
@@ -49,7 +50,7 @@ func checkFiles(noders []*noder) (posMap, *types2.Package, *types2.Info) {
 			base.ErrorfAt(m.makeXPos(terr.Pos), "%s", terr.Msg)
 		},
 		Importer: &importer,
-		Sizes:    &gcSizes{},
+		Sizes:    newGCSizes(files),
 	}
 	info := &types2.Info{
 		Types:      make(map[syntax.Expr]types2.TypeAndValue),
@@ -69,6 +70,23 @@ func checkFiles(noders []*noder) (posMap, *types2.Package, *types2.Info) {
 		base.FatalfAt(src.NoXPos, "conf.Check error: %v", err)
 	}
 
+	checkImportCycle(&m, pkg, files, info)
+	checkLinknames(&m, noders, pkg, importer.packages)
+	base.ExitIfErrors()
+
+	if base.Debug.CgoCheck != 0 {
+		checkCgo(&m, pkg, files, info)
+	}
+	if base.Debug.Exhaustive != 0 {
+		checkExhaustive(&m, pkg, files, info)
+	}
+	if base.Debug.Printf != 0 {
+		checkPrintf(&m, pkg, files, info)
+	}
+	if base.Debug.TypeSet != "" {
+		printTypeSet(&m, pkg, base.Debug.TypeSet)
+	}
+
 	return m, pkg, info
 }
 
@@ -91,6 +109,10 @@ func check2(noders []*noder) {
 	}
 	g.generate(noders)
 
+	if base.Flag.Deprecated != 0 {
+		g.checkDeprecated(noders)
+	}
+
 	if base.Flag.G < 3 {
 		os.Exit(0)
 	}
@@ -132,6 +154,10 @@ type instInfo struct {
 	dictParam *ir.Name // The node inside fun that refers to the dictionary param
 
 	dictInfo *dictInfo
+
+	// full is set if fun is instantiated with the type arguments
+	// themselves, rather than their shapes (see fullStencil).
+	full bool
 }
 
 type irgen struct {
@@ -315,6 +341,13 @@ Outer:
 
 	typecheck.DeclareUniverse()
 
+	// Match the profile to the source before the generic functions
+	// are instantiated, so that the instantiations can be chosen by
+	// the calls to them that it shows are hot (see fullStencil).
+	if pgo.Current != nil {
+		pgo.MatchSource(g.target.Decls)
+	}
+
 	// Create any needed instantiations of generic functions and transform
 	// existing and new functions to use those instantiations.
 	BuildInstantiations(true)
@@ -31,12 +31,29 @@ const (
 		ir.RegisterParams | // TODO(register args) remove after register abi is working
 		ir.CgoUnsafeArgs |
 		ir.UintptrEscapes |
+		ir.FastMinMax |
+		ir.StrictMinMax |
+		ir.FastMath |
+		ir.CPUAMD64V2 |
+		ir.CPUAMD64V3 |
+		ir.CPUAMD64V4 |
+		ir.Pure |
+		ir.NoAlloc |
+		ir.StackLocals |
+		ir.NoHeapLocals |
+		ir.MustUse |
 		ir.Systemstack |
 		ir.Nowritebarrier |
 		ir.Nowritebarrierrec |
 		ir.Yeswritebarrierrec
 
-	typePragmas = ir.NotInHeap
+	typePragmas = ir.NotInHeap | ir.NoCompare | ir.NoHash | ir.MustUse
+
+	localVarPragmas = ir.SoA
+
+	// Only allowed on package-level declarations.
+	globalTypePragmas = ir.CacheAlign
+	globalVarPragmas  = ir.CacheAlign
 )
 
 func pragmaFlag(verb string) ir.PragmaFlag {
@@ -80,10 +97,52 @@ func pragmaFlag(verb string) ir.PragmaFlag {
 		// in the argument list.
 		// Used in syscall/dll_windows.go.
 		return ir.UintptrEscapes
+	case "go:fastminmax":
+		// Float min and max written as comparisons may be
+		// compiled to native instructions even where their
+		// NaN and signed zero behavior differs from the source.
+		return ir.FastMinMax
+	case "go:fastmath":
+		return ir.FastMath
+	case "go:strictminmax":
+		// Overrides -d=fastminmax for this function.
+		return ir.StrictMinMax
+	case "go:pure":
+		// The function has no side effects visible to its
+		// caller other than its results. Verified by the compiler.
+		return ir.Pure
+	case "go:noalloc":
+		// The function does not allocate heap memory once
+		// compiled. Verified by the compiler.
+		return ir.NoAlloc
+	case "go:stacklocals":
+		// Non-escaping values too large for the stack frame
+		// by default stay on the stack anyway.
+		return ir.StackLocals
+	case "go:noheaplocals":
+		// It is an error for a non-escaping value to be
+		// moved to the heap because of its size.
+		return ir.NoHeapLocals
 	case "go:registerparams": // TODO(register args) remove after register abi is working
 		return ir.RegisterParams
 	case "go:notinheap":
 		return ir.NotInHeap
+	case "go:nocompare":
+		return ir.NoCompare
+	case "go:nohash":
+		return ir.NoHash
+	case "go:mustuse":
+		// Discarding the results of calls to the function, or
+		// results of the type, is an error.
+		return ir.MustUse
+	case "go:cachealign":
+		// The struct type or package-level variable declared
+		// next is padded to whole cache lines.
+		return ir.CacheAlign
+	case "go:soa":
+		// The local slice of structs declared next is
+		// stored as a slice per field, if possible.
+		return ir.SoA
 	}
 	return 0
 }
@@ -0,0 +1,242 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/types"
+	"cmd/compile/internal/types2"
+	"cmd/internal/objabi"
+)
+
+// checkLinknames reports //go:linkname directives whose target is
+// declared in an imported package, but is not a function or variable
+// like the local declaration, or has a different type. Without this
+// check, such mistakes are only found when the linker fails or the
+// program crashes.
+//
+// Targets in packages that were not imported cannot be checked, and
+// neither can unexported targets that are missing from the export
+// data.
+func checkLinknames(m *posMap, noders []*noder, self *types2.Package, packages map[string]*types2.Package) {
+	for _, p := range noders {
+		for _, l := range p.linknames {
+			local := self.Scope().Lookup(l.local)
+			if local == nil {
+				continue // reported by processPragmas
+			}
+			pkg, name := linknameTarget(l.remote, packages)
+			if pkg == nil || pkg == self {
+				continue
+			}
+			if msg := checkLinkname(local, pkg, name); msg != "" {
+				base.ErrorfAt(m.makeXPos(local.Pos()), "//go:linkname %s refers to %s.%s, %s", l.local, pkg.Path(), name, msg)
+			}
+		}
+	}
+}
+
+// linknameTarget splits the linker symbol name of a //go:linkname
+// target into the package in packages that declares it and the name
+// within that package. It returns a nil package if the package is
+// not in packages.
+func linknameTarget(sym string, packages map[string]*types2.Package) (*types2.Package, string) {
+	var pkg *types2.Package
+	var prefix string
+	for path, p := range packages {
+		if pre := objabi.PathToPrefix(path); strings.HasPrefix(sym, pre+".") && len(pre) > len(prefix) {
+			pkg, prefix = p, pre
+		}
+	}
+	if pkg == nil {
+		return nil, ""
+	}
+	return pkg, sym[len(prefix)+1:]
+}
+
+// checkLinkname returns why local cannot be linked to name in pkg,
+// or "" if it can or that is not known.
+func checkLinkname(local types2.Object, pkg *types2.Package, name string) string {
+	target := lookupLinknameTarget(pkg, name)
+	if target == nil {
+		if !types.IsExported(name) || !pkg.Complete() {
+			return ""
+		}
+		return "which is not declared"
+	}
+
+	switch local.(type) {
+	case *types2.Func:
+		if _, ok := target.(*types2.Func); !ok {
+			return "which is not a function"
+		}
+		if sig := target.Type().(*types2.Signature); sig.TypeParams().Len() > 0 {
+			return "which is generic"
+		}
+	case *types2.Var:
+		if _, ok := target.(*types2.Var); !ok {
+			return "which is not a variable"
+		}
+	default:
+		return ""
+	}
+
+	typ := target.Type()
+	if sig, ok := typ.(*types2.Signature); ok && sig.Recv() != nil {
+		// Methods are linked to functions that take the receiver
+		// as their first parameter.
+		params := []*types2.Var{sig.Recv()}
+		for i := 0; i < sig.Params().Len(); i++ {
+			params = append(params, sig.Params().At(i))
+		}
+		typ = types2.NewSignatureType(nil, nil, nil, types2.NewTuple(params...), sig.Results(), sig.Variadic())
+	}
+	if !types2.Identical(local.Type(), typ) {
+		qual := types2.RelativeTo(local.Pkg())
+		return "which has type " + types2.TypeString(typ, qual) + ", not " + types2.TypeString(local.Type(), qual)
+	}
+	return ""
+}
+
+// lookupLinknameTarget returns the object named by name in pkg, which
+// is either a package-level name or a method named T.m or (*T).m.
+func lookupLinknameTarget(pkg *types2.Package, name string) types2.Object {
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return pkg.Scope().Lookup(name)
+	}
+	recv, meth := name[:dot], name[dot+1:]
+	ptr := strings.HasPrefix(recv, "(*") && strings.HasSuffix(recv, ")")
+	if ptr {
+		recv = recv[len("(*") : len(recv)-len(")")]
+	}
+	tname, _ := pkg.Scope().Lookup(recv).(*types2.TypeName)
+	if tname == nil {
+		return nil
+	}
+	var typ types2.Type = tname.Type()
+	if ptr {
+		typ = types2.NewPointer(typ)
+	}
+	obj, _, _ := types2.LookupFieldOrMethod(typ, false, pkg, meth)
+	if fn, ok := obj.(*types2.Func); ok {
+		// The method's receiver must be exactly the receiver named,
+		// not *T for a method on T or a promoted method.
+		if recv := fn.Type().(*types2.Signature).Recv(); recv != nil && types2.Identical(recv.Type(), typ) {
+			return fn
+		}
+	}
+	return nil
+}
+
+// A linknameRecord describes a //go:linkname directive for
+// -linknamelog.
+type linknameRecord struct {
+	Package       string `json:"package"`
+	Pos           string `json:"pos"`
+	Local         string `json:"local"`
+	Target        string `json:"target"`
+	TargetPackage string `json:"target_package"`
+}
+
+// auditLinknames implements -linknamelog and -linknameallow. It
+// appends a JSON record for each //go:linkname directive to the
+// -linknamelog file, one per line, and reports directives whose
+// target is in a package other than the one being compiled that is
+// not in the -linknameallow list.
+func auditLinknames(noders []*noder) {
+	if base.Flag.LinknameLog == "" && base.Flag.Cfg.LinknameAllow == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	for _, p := range noders {
+		for _, l := range p.linknames {
+			pkg := linknamePackage(l.remote)
+			if base.Flag.LinknameLog != "" {
+				data, err := json.Marshal(linknameRecord{
+					Package:       base.Ctxt.Pkgpath,
+					Pos:           base.FmtPos(p.makeXPos(l.pos)),
+					Local:         l.local,
+					Target:        l.remote,
+					TargetPackage: pkg,
+				})
+				if err != nil {
+					base.Fatalf("marshaling linkname record: %v", err)
+				}
+				buf.Write(data)
+				buf.WriteByte('\n')
+			}
+			if base.Flag.Cfg.LinknameAllow != nil && pkg != base.Ctxt.Pkgpath && !linknameAllowed(pkg) {
+				p.errorAt(l.pos, "//go:linkname %s refers to %s, but package %s is not in the -linknameallow list", l.local, l.remote, strconv.Quote(pkg))
+			}
+		}
+	}
+
+	if base.Flag.LinknameLog != "" && buf.Len() > 0 {
+		// Write all records at once, so that those of concurrent
+		// compilations appending to the same file do not interleave.
+		f, err := os.OpenFile(base.Flag.LinknameLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+		if err == nil {
+			_, err = f.Write(buf.Bytes())
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			log.Fatalf("cannot write linkname log: %v", err)
+		}
+	}
+}
+
+// linknamePackage returns the import path of the package of the
+// linker symbol sym, which is the inverse of objabi.PathToPrefix
+// applied to the part of sym before the first dot after the last
+// slash.
+func linknamePackage(sym string) string {
+	prefix := sym
+	slash := strings.LastIndex(sym, "/")
+	if dot := strings.Index(sym[slash+1:], "."); dot >= 0 {
+		prefix = sym[:slash+1+dot]
+	}
+	if !strings.Contains(prefix, "%") {
+		return prefix
+	}
+	var b strings.Builder
+	for i := 0; i < len(prefix); i++ {
+		if c := prefix[i]; c == '%' && i+2 < len(prefix) {
+			if v, err := strconv.ParseUint(prefix[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(prefix[i])
+	}
+	return b.String()
+}
+
+// linknameAllowed reports whether the -linknameallow list contains
+// path, either itself or by a pattern "dir/..." that matches dir and
+// all paths below it.
+func linknameAllowed(path string) bool {
+	for _, allowed := range base.Flag.Cfg.LinknameAllow {
+		if dir := strings.TrimSuffix(allowed, "/..."); dir != allowed {
+			if path == dir || strings.HasPrefix(path, dir+"/") {
+				return true
+			}
+		} else if path == allowed {
+			return true
+		}
+	}
+	return false
+}
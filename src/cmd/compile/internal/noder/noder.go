@@ -80,6 +80,8 @@ func LoadPackage(filenames []string) {
 	}
 	base.Timer.AddEvent(int64(lines), "lines")
 
+	auditLinknames(noders)
+
 	if base.Debug.Unified != 0 {
 		unified(noders)
 		return
@@ -191,21 +193,28 @@ func (p *noder) errorAt(pos syntax.Pos, format string, args ...interface{}) {
 
 // trimFilename returns the "trimmed" filename of b, which is the
 // absolute filename after applying -trimpath processing. This
-// filename form is suitable for use in object files and export data.
+// filename form is suitable for use in object files and export data,
+// while b's own filename, as given on the command line or in a line
+// directive, is the one used in diagnostics.
 //
 // If b's filename has already been trimmed (i.e., because it was read
 // in from an imported package's export data), then the filename is
 // returned unchanged.
 func trimFilename(b *syntax.PosBase) string {
 	filename := b.Filename()
-	if !b.Trimmed() {
-		dir := ""
-		if b.IsFileBase() {
-			dir = base.Ctxt.Pathname
-		}
-		filename = objabi.AbsFile(dir, filename, base.Flag.TrimPath)
+	if b.Trimmed() {
+		return filename
+	}
+	if b.IsFileBase() {
+		return objabi.AbsFile(base.Ctxt.Pathname, filename, base.Flag.TrimPath)
+	}
+	if outer := b.Pos().Base(); outer.Filename() == filename {
+		// A line directive naming the file it is in, as the
+		// /*line :line:col*/ form does implicitly, refers to that
+		// file, so it must have the same trimmed filename too.
+		return trimFilename(outer)
 	}
-	return filename
+	return objabi.AbsFile("", filename, base.Flag.TrimPath)
 }
 
 // noder transforms package syntax's AST into a Node tree.
@@ -422,6 +431,14 @@ func (p *noder) varDecl(decl *syntax.VarDecl) []ir.Node {
 
 	if pragma, ok := decl.Pragma.(*pragmas); ok {
 		varEmbed(p.makeXPos, names[0], decl, pragma, p.importedEmbed)
+		varPragmas := globalVarPragmas
+		if ir.CurFunc != nil {
+			varPragmas = localVarPragmas
+		}
+		for _, name := range names {
+			name.SetPragma(pragma.Flag & varPragmas)
+		}
+		pragma.Flag &^= varPragmas
 		p.checkUnused(pragma)
 	}
 
@@ -548,8 +565,16 @@ func (p *noder) typeDecl(decl *syntax.TypeDecl) ir.Node {
 	n.SetAlias(decl.Alias)
 	if pragma, ok := decl.Pragma.(*pragmas); ok {
 		if !decl.Alias {
-			n.SetPragma(pragma.Flag & typePragmas)
-			pragma.Flag &^= typePragmas
+			allowed := typePragmas
+			if ir.CurFunc == nil {
+				allowed |= globalTypePragmas
+			}
+			n.SetPragma(pragma.Flag & allowed)
+			if n.Pragma()&ir.CacheAlign != 0 && !cacheAlignDecl(decl) {
+				base.ErrorfAt(p.pos(decl), "//go:cachealign only applies to struct types that are not generic")
+				n.SetPragma(n.Pragma() &^ ir.CacheAlign)
+			}
+			pragma.Flag &^= allowed
 		}
 		p.checkUnused(pragma)
 	}
@@ -607,6 +632,11 @@ func (p *noder) funcDecl(fun *syntax.FuncDecl) ir.Node {
 		if pragma.Flag&ir.Systemstack != 0 && pragma.Flag&ir.Nosplit != 0 {
 			base.ErrorfAt(f.Pos(), "go:nosplit and go:systemstack cannot be combined")
 		}
+		if pragma.Flag&ir.CPULevels != 0 {
+			if msg := goCPUError(fun); msg != "" {
+				base.ErrorfAt(f.Pos(), "%s", msg)
+			}
+		}
 		pragma.Flag &^= funcPragmas
 		p.checkUnused(pragma)
 	}
@@ -1583,9 +1613,10 @@ var allowedStdPragmas = map[string]bool{
 
 // *pragmas is the value stored in a syntax.pragmas during parsing.
 type pragmas struct {
-	Flag   ir.PragmaFlag // collected bits
-	Pos    []pragmaPos   // position of each individual flag
-	Embeds []pragmaEmbed
+	Flag       ir.PragmaFlag // collected bits
+	Pos        []pragmaPos   // position of each individual flag
+	Embeds     []pragmaEmbed
+	Deprecated string // "Deprecated:" notice of the doc comment
 }
 
 type pragmaPos struct {
@@ -1642,6 +1673,13 @@ func (p *noder) pragma(pos syntax.Pos, blankLine bool, text string, old syntax.P
 		panic("unreachable")
 	}
 
+	if strings.HasPrefix(text, "Deprecated:") {
+		// Not a directive, so never misplaced: it is dropped
+		// unless the next declaration is a package-level one.
+		pragma.Deprecated = text
+		return pragma
+	}
+
 	if !blankLine {
 		// directive must be on line by itself
 		p.error(syntax.Error{Pos: pos, Msg: "misplaced compiler directive"})
@@ -1684,6 +1722,15 @@ func (p *noder) pragma(pos syntax.Pos, blankLine bool, text string, old syntax.P
 		}
 		pragma.Embeds = append(pragma.Embeds, pragmaEmbed{pos, args})
 
+	case text == "go:cpu", strings.HasPrefix(text, "go:cpu "):
+		flag, err := parseGoCPU(strings.Fields(text)[1:])
+		if err != nil {
+			p.error(syntax.Error{Pos: pos, Msg: err.Error()})
+			break
+		}
+		pragma.Flag |= flag
+		pragma.Pos = append(pragma.Pos, pragmaPos{flag, pos})
+
 	case strings.HasPrefix(text, "go:cgo_import_dynamic "):
 		// This is permitted for general use because Solaris
 		// code relies on it in golang.org/x/sys/unix and others.
@@ -1757,6 +1804,41 @@ func mkname(sym *types.Sym) ir.Node {
 	return n
 }
 
+// parseGoCPU parses the feature levels listed in a //go:cpu directive.
+func parseGoCPU(args []string) (ir.PragmaFlag, error) {
+	if len(args) == 0 {
+		return 0, errors.New("usage: //go:cpu level...")
+	}
+	var flag ir.PragmaFlag
+	for _, arg := range args {
+		switch arg {
+		case "amd64.v2":
+			flag |= ir.CPUAMD64V2
+		case "amd64.v3":
+			flag |= ir.CPUAMD64V3
+		case "amd64.v4":
+			flag |= ir.CPUAMD64V4
+		default:
+			return 0, fmt.Errorf("invalid //go:cpu level %q (want amd64.v2, amd64.v3 or amd64.v4)", arg)
+		}
+	}
+	return flag, nil
+}
+
+// goCPUError returns the error, if any, for using //go:cpu on decl.
+// Variants are only generated for plain functions with bodies.
+func goCPUError(decl *syntax.FuncDecl) string {
+	switch {
+	case decl.Recv != nil:
+		return "go:cpu cannot be used on methods"
+	case len(decl.TParamList) != 0:
+		return "go:cpu cannot be used on generic functions"
+	case decl.Body == nil:
+		return "go:cpu requires a function body"
+	}
+	return ""
+}
+
 // parseGoEmbed parses the text following "//go:embed" to extract the glob patterns.
 // It accepts unquoted space-separated patterns as well as double-quoted and back-quoted Go strings.
 // go/build/read.go also processes these strings and contains similar logic.
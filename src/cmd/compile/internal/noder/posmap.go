@@ -49,6 +49,7 @@ func (m *posMap) makeSrcPosBase(b0 *syntax.PosBase) *src.PosBase {
 
 		if b0.IsFileBase() {
 			b1 = src.NewFileBase(fn, absfn)
+			b1.SetGenerated(b0.Generated())
 		} else {
 			// line directive base
 			p0 := b0.Pos()
@@ -0,0 +1,408 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"fmt"
+	"go/constant"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/logopt"
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/types2"
+)
+
+// checkPrintf reports, for -d=printf, calls to Printf-like functions
+// whose constant format string does not match their arguments. The
+// reports are warnings, and are also logged for -json as "printf".
+//
+// A function is Printf-like if its last two parameters are a string
+// named format and a ...interface{}, as they are for fmt.Printf,
+// log.Printf, and testing.TB's Errorf, unless its name ends in Scanf.
+// This finds calls through interfaces and of generic functions, whose
+// callee vet cannot know, and it checks arguments whose type is a type
+// parameter against every type in the parameter's constraint.
+func checkPrintf(m *posMap, self *types2.Package, files []*syntax.File, info *types2.Info) {
+	c := printfChecker{m: m, self: self, info: info}
+	for _, file := range files {
+		for _, decl := range file.DeclList {
+			c.fn = ""
+			if decl, ok := decl.(*syntax.FuncDecl); ok {
+				c.fn = decl.Name.Value
+			}
+			syntax.Inspect(decl, func(n syntax.Node) bool {
+				if call, ok := n.(*syntax.CallExpr); ok {
+					c.call(call)
+				}
+				return true
+			})
+		}
+	}
+}
+
+type printfChecker struct {
+	m    *posMap
+	self *types2.Package
+	info *types2.Info
+	fn   string // name of the function being checked, for -json
+}
+
+// printfArgType is the set of argument types a verb accepts.
+type printfArgType int
+
+const (
+	argBool printfArgType = 1 << iota
+	argInt
+	argRune
+	argString
+	argFloat
+	argComplex
+	argPointer
+	argError
+	anyType printfArgType = -1
+)
+
+var printfVerbs = map[rune]printfArgType{
+	'b': argInt | argFloat | argComplex | argPointer,
+	'c': argRune | argInt,
+	'd': argInt | argPointer,
+	'e': argFloat | argComplex,
+	'E': argFloat | argComplex,
+	'f': argFloat | argComplex,
+	'F': argFloat | argComplex,
+	'g': argFloat | argComplex,
+	'G': argFloat | argComplex,
+	'o': argInt | argPointer,
+	'O': argInt | argPointer,
+	'p': argPointer,
+	'q': argRune | argInt | argString,
+	's': argString,
+	't': argBool,
+	'T': anyType,
+	'U': argRune | argInt,
+	'v': anyType,
+	'w': argError,
+	'x': argRune | argInt | argString | argFloat | argComplex | argPointer,
+	'X': argRune | argInt | argString | argFloat | argComplex | argPointer,
+}
+
+func (c *printfChecker) warn(pos syntax.Pos, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	xpos := c.m.makeXPos(pos)
+	base.WarnfAt(xpos, "%s", msg)
+	if logopt.Enabled() {
+		logopt.LogOpt(xpos, "printf", "typecheck", c.fn, msg)
+	}
+}
+
+// call checks call if it calls a Printf-like function.
+func (c *printfChecker) call(call *syntax.CallExpr) {
+	tv := c.info.Types[call.Fun]
+	sig, ok := tv.Type.(*types2.Signature)
+	if !ok || tv.IsType() || !sig.Variadic() || call.HasDots {
+		return
+	}
+	params := sig.Params()
+	n := params.Len()
+	if n < 2 || len(call.ArgList) < n-1 {
+		return
+	}
+	if format := params.At(n - 2); format.Name() != "format" || !types2.Identical(format.Type(), types2.Typ[types2.String]) {
+		return
+	}
+	if elem := params.At(n - 1).Type().(*types2.Slice).Elem(); !types2.Identical(elem, types2.NewInterfaceType(nil, nil)) {
+		return
+	}
+	val := c.info.Types[call.ArgList[n-2]].Value
+	if val == nil || val.Kind() != constant.String {
+		return
+	}
+
+	// Scanf-like functions have the same signature.
+	name := syntax.String(call.Fun)
+	if strings.HasSuffix(strings.ToLower(name), "scanf") {
+		return
+	}
+
+	// Only fmt.Errorf among the fmt functions supports %w. Functions
+	// outside fmt may pass their arguments to it.
+	wrap := true
+	if fn, ok := c.funcObj(call.Fun).(*types2.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" {
+		wrap = fn.Name() == "Errorf"
+	}
+	c.format(call, name, constant.StringVal(val), call.ArgList[n-1:], wrap)
+}
+
+// funcObj returns the object fun refers to, if it is a possibly
+// qualified name or a method.
+func (c *printfChecker) funcObj(fun syntax.Expr) types2.Object {
+	switch fun := fun.(type) {
+	case *syntax.Name:
+		return c.info.Uses[fun]
+	case *syntax.SelectorExpr:
+		return c.info.Uses[fun.Sel]
+	case *syntax.IndexExpr:
+		return c.funcObj(fun.X)
+	}
+	return nil
+}
+
+// format checks the directives of format against args. wrap reports
+// whether the function accepts %w.
+func (c *printfChecker) format(call *syntax.CallExpr, name, format string, args []syntax.Expr, wrap bool) {
+	pos := syntax.StartPos(call)
+	argNum := 0
+	reordered := false
+
+	// index parses an explicit argument index at format[i:], if any,
+	// and returns the position after it.
+	index := func(i int) (int, bool) {
+		if i >= len(format) || format[i] != '[' {
+			return i, true
+		}
+		end := strings.IndexByte(format[i:], ']')
+		if end < 0 {
+			return i, false
+		}
+		n, err := strconv.Atoi(format[i+1 : i+end])
+		if err != nil || n < 1 {
+			return i, false
+		}
+		argNum = n - 1
+		reordered = true
+		return i + end + 1, true
+	}
+
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("#0+- ", format[i]) >= 0 {
+			i++
+		}
+
+		// Width and precision, either of which may be '*' to take
+		// an argument.
+		var stars []syntax.Expr
+		missing := -1
+		ok := true
+		for _, prec := range []bool{false, true} {
+			if prec {
+				if i >= len(format) || format[i] != '.' {
+					break
+				}
+				i++
+			}
+			if i, ok = index(i); !ok {
+				break
+			}
+			if i < len(format) && format[i] == '*' {
+				i++
+				if argNum >= len(args) {
+					missing = argNum
+				} else {
+					stars = append(stars, args[argNum])
+				}
+				argNum++
+				continue
+			}
+			for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+				i++
+			}
+		}
+		if ok {
+			i, ok = index(i)
+		}
+		if !ok || i >= len(format) {
+			c.warn(pos, "%s format %s is missing verb or has a bad argument index", name, format[start:])
+			return
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		directive := format[start:i]
+
+		if missing >= 0 {
+			c.warn(pos, "%s format %s reads arg #%d, but call has %d args", name, directive, missing+1, len(args))
+			return
+		}
+		for _, arg := range stars {
+			if !c.match(argInt, c.info.Types[arg].Type) {
+				c.warn(pos, "%s format %s uses non-int %s as argument of *", name, directive, syntax.String(arg))
+			}
+		}
+
+		if verb == '%' {
+			continue
+		}
+		if verb == 'w' && !wrap {
+			c.warn(pos, "%s does not support error-wrapping directive %%w", name)
+			return
+		}
+		if argNum >= len(args) {
+			c.warn(pos, "%s format %s reads arg #%d, but call has %d args", name, directive, argNum+1, len(args))
+			return
+		}
+		arg := args[argNum]
+		argNum++
+		typ := c.info.Types[arg].Type
+		if hasMethod(typ, "Format") {
+			continue // accepts any verb
+		}
+		want, known := printfVerbs[verb]
+		if !known {
+			c.warn(pos, "%s format %s has unknown verb %c", name, directive, verb)
+			return
+		}
+		if _, ok := typ.(*types2.Signature); ok && verb != 'p' && verb != 'T' {
+			c.warn(pos, "%s format %s arg %s is a func value, not called", name, directive, syntax.String(arg))
+			continue
+		}
+		if !c.match(want, typ) {
+			c.warn(pos, "%s format %s has arg %s of wrong type %s", name, directive, syntax.String(arg), types2.TypeString(typ, types2.RelativeTo(c.self)))
+		}
+	}
+
+	if !reordered && argNum < len(args) {
+		c.warn(pos, "%s call needs %d args but has %d args", name, argNum, len(args))
+	}
+}
+
+// match reports whether a value of type typ can be formatted by a
+// verb accepting the argument types in want.
+func (c *printfChecker) match(want printfArgType, typ types2.Type) bool {
+	return c.matchType(want, typ, true, make(map[types2.Type]bool))
+}
+
+func (c *printfChecker) matchType(want printfArgType, typ types2.Type, top bool, seen map[types2.Type]bool) bool {
+	if want == anyType || typ == nil {
+		return true
+	}
+	if seen[typ] {
+		return true
+	}
+	seen[typ] = true
+
+	// A type parameter matches if every type in its constraint
+	// does. Its methods are handled like those of any other type.
+	if tparam, ok := typ.(*types2.TypeParam); ok && !hasMethod(typ, "Format") {
+		if want&(argString|argError) != 0 && (hasMethod(typ, "String") || hasMethod(typ, "Error")) {
+			return true
+		}
+		terms := constraintTerms(tparam.Constraint())
+		for _, term := range terms {
+			if !c.matchType(want, term, top, seen) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Types implementing fmt.Formatter format themselves, and those
+	// implementing error or fmt.Stringer print as strings.
+	if hasMethod(typ, "Format") {
+		return true
+	}
+	if want&argError != 0 {
+		return hasMethod(typ, "Error") || types2.IsInterface(typ)
+	}
+	if want&argString != 0 && (hasMethod(typ, "String") || hasMethod(typ, "Error")) {
+		return true
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types2.Signature, *types2.Chan:
+		return want&argPointer != 0
+	case *types2.Map:
+		return want&argPointer != 0 || c.matchType(want, u.Key(), false, seen) && c.matchType(want, u.Elem(), false, seen)
+	case *types2.Slice:
+		if isByte(u.Elem()) && want&argString != 0 {
+			return true
+		}
+		return want&argPointer != 0 || c.matchType(want, u.Elem(), false, seen)
+	case *types2.Array:
+		if isByte(u.Elem()) && want&argString != 0 {
+			return true
+		}
+		return c.matchType(want, u.Elem(), false, seen)
+	case *types2.Pointer:
+		if want == argPointer {
+			return true
+		}
+		// Pointers to composite values print as &{...} at top level.
+		switch u.Elem().Underlying().(type) {
+		case *types2.Struct, *types2.Array, *types2.Slice, *types2.Map:
+			if top {
+				return want&argPointer != 0 || c.matchType(want, u.Elem(), false, seen)
+			}
+		}
+		return want&argPointer != 0
+	case *types2.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if !c.matchType(want, u.Field(i).Type(), false, seen) {
+				return false
+			}
+		}
+		return true
+	case *types2.Interface:
+		// The dynamic type is not known.
+		return true
+	case *types2.Basic:
+		switch {
+		case u.Kind() == types2.UnsafePointer, u.Kind() == types2.UntypedNil:
+			return want&argPointer != 0
+		case u.Info()&types2.IsBoolean != 0:
+			return want&argBool != 0
+		case u.Info()&types2.IsInteger != 0:
+			return want&(argInt|argRune) != 0
+		case u.Info()&types2.IsFloat != 0:
+			return want&argFloat != 0
+		case u.Info()&types2.IsComplex != 0:
+			return want&argComplex != 0
+		case u.Info()&types2.IsString != 0:
+			return want&argString != 0
+		case u.Kind() == types2.Invalid:
+			return true
+		}
+	}
+	return false
+}
+
+// hasMethod reports whether typ has a method with the given name.
+func hasMethod(typ types2.Type, name string) bool {
+	obj, _, _ := types2.LookupFieldOrMethod(typ, false, nil, name)
+	_, ok := obj.(*types2.Func)
+	return ok
+}
+
+// isByte reports whether typ is byte or a type defined as byte.
+func isByte(typ types2.Type) bool {
+	basic, ok := typ.Underlying().(*types2.Basic)
+	return ok && basic.Kind() == types2.Byte
+}
+
+// constraintTerms returns the types in the unions of constraint, or
+// nil if it does not restrict the type.
+func constraintTerms(constraint types2.Type) []types2.Type {
+	var terms []types2.Type
+	switch t := constraint.Underlying().(type) {
+	case *types2.Interface:
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			terms = append(terms, constraintTerms(t.EmbeddedType(i))...)
+		}
+	case *types2.Union:
+		for i := 0; i < t.Len(); i++ {
+			terms = append(terms, t.Term(i).Type())
+		}
+	default:
+		terms = append(terms, t)
+	}
+	return terms
+}
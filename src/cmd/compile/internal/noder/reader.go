@@ -890,6 +890,15 @@ func (r *reader) typeExt(name *ir.Name) {
 	if name.Pragma()&ir.NotInHeap != 0 {
 		typ.SetNotInHeap(true)
 	}
+	if name.Pragma()&ir.NoCompare != 0 {
+		typ.SetNoCompare(true)
+	}
+	if name.Pragma()&ir.NoHash != 0 {
+		typ.SetNoHash(true)
+	}
+	if name.Pragma()&ir.MustUse != 0 {
+		typ.SetMustUse(true)
+	}
 
 	typecheck.SetBaseTypeIndex(typ, r.int64(), r.int64())
 }
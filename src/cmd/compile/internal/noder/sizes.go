@@ -7,14 +7,90 @@ package noder
 import (
 	"fmt"
 
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
 	"cmd/compile/internal/types2"
+	"cmd/internal/src"
 )
 
 // Code below based on go/types.StdSizes.
 // Intentional differences are marked with "gc:".
 
-type gcSizes struct{}
+type gcSizes struct {
+	// types2 ignores pragmas, so gcSizes finds the //go:cachealign
+	// types itself: those of this package by the position of their
+	// declarations, and imported ones in their export data.
+	cacheAlignPos map[syntax.Pos]bool
+	cacheAlign    map[*types2.TypeName]bool
+}
+
+// newGCSizes returns the sizes of the types declared in files.
+func newGCSizes(files []*syntax.File) *gcSizes {
+	s := &gcSizes{
+		cacheAlignPos: make(map[syntax.Pos]bool),
+		cacheAlign:    make(map[*types2.TypeName]bool),
+	}
+	for _, file := range files {
+		for _, decl := range file.DeclList {
+			if decl, ok := decl.(*syntax.TypeDecl); ok && cacheAlignDecl(decl) {
+				s.cacheAlignPos[decl.Name.Pos()] = true
+			}
+		}
+	}
+	return s
+}
+
+// cacheAlignDecl reports whether decl declares a type whose fields and
+// variables are padded to whole cache lines. //go:cachealign only applies to struct types
+// that are not generic.
+func cacheAlignDecl(decl *syntax.TypeDecl) bool {
+	pragma, ok := decl.Pragma.(*pragmas)
+	if !ok || pragma.Flag&ir.CacheAlign == 0 || decl.Alias || len(decl.TParamList) > 0 {
+		return false
+	}
+	_, ok = decl.Type.(*syntax.StructType)
+	return ok
+}
+
+// cacheAligned reports whether fields and variables of type T occupy
+// whole cache lines. It corresponds to types.Type.CacheAligned.
+func (s *gcSizes) cacheAligned(T types2.Type) bool {
+	if t, ok := T.(*types2.Named); ok && s.isCacheAlign(t.Obj()) {
+		return true
+	}
+	switch t := T.Underlying().(type) {
+	case *types2.Array:
+		return s.cacheAligned(t.Elem())
+	case *types2.Struct:
+		for i, nf := 0, t.NumFields(); i < nf; i++ {
+			if s.cacheAligned(t.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCacheAlign reports whether obj was declared //go:cachealign.
+func (s *gcSizes) isCacheAlign(obj *types2.TypeName) bool {
+	switch {
+	case obj.Pkg() == nil:
+		return false
+	case obj.Pkg().Path() == base.Ctxt.Pkgpath:
+		return s.cacheAlignPos[obj.Pos()]
+	}
+	b, ok := s.cacheAlign[obj]
+	if !ok {
+		sym := types.NewPkg(obj.Pkg().Path(), "").Lookup(obj.Name())
+		n := typecheck.Resolve(ir.NewIdent(src.NoXPos, sym))
+		b = n.Op() == ir.OTYPE && n.Type() != nil && n.Type().CacheAlign()
+		s.cacheAlign[obj] = b
+	}
+	return b
+}
 
 func (s *gcSizes) Alignof(T types2.Type) int64 {
 	// For arrays and structs, alignment is defined in terms
@@ -72,8 +148,17 @@ func (s *gcSizes) Offsetsof(fields []*types2.Var) []int64 {
 		typ := f.Type()
 		a := s.Alignof(typ)
 		o = types.Rnd(o, a)
+		pad := s.cacheAligned(typ)
+		if pad {
+			// gc: Start the field on a cache line of its own.
+			o = types.RndCacheLine(o)
+		}
 		offsets[i] = o
 		o += s.Sizeof(typ)
+		if pad {
+			// gc: Fill the field's last cache line.
+			o = types.RndCacheLine(o)
+		}
 	}
 	return offsets
 }
@@ -123,7 +208,12 @@ func (s *gcSizes) Sizeof(T types2.Type) int64 {
 		}
 
 		// gc: Size includes alignment padding.
-		return types.Rnd(offsets[n-1]+last, s.Alignof(t))
+		size := types.Rnd(offsets[n-1]+last, s.Alignof(t))
+		if s.cacheAligned(t) {
+			// gc: Fill the last cache line.
+			size = types.RndCacheLine(size)
+		}
+		return size
 	case *types2.Interface:
 		return int64(types.PtrSize) * 2
 	case *types2.Chan, *types2.Map, *types2.Pointer, *types2.Signature:
@@ -12,6 +12,7 @@ import (
 	"cmd/compile/internal/inline"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/objw"
+	"cmd/compile/internal/pgo"
 	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
@@ -173,7 +174,8 @@ func (g *genInst) scanForGenCalls(decl ir.Node) {
 			inst := call.X.(*ir.InstExpr)
 			nameNode, isMeth := g.getInstNameNode(inst)
 			targs := typecheck.TypesOf(inst.Targs)
-			st := g.getInstantiation(nameNode, targs, isMeth).fun
+			full := !isMeth && fullStencil(call, nameNode, targs)
+			st := g.getInstantiation1(nameNode, targs, isMeth, full).fun
 			dictValue, usingSubdict := g.getDictOrSubdict(declInfo, n, nameNode, targs, isMeth)
 			if infoPrintMode {
 				dictkind := "Main dictionary"
@@ -235,8 +237,9 @@ func (g *genInst) scanForGenCalls(decl ir.Node) {
 			st := g.getInstantiation(gf, targs, true).fun
 			dictValue, usingSubdict := g.getDictOrSubdict(declInfo, n, gf, targs, true)
 			// We have to be using a subdictionary, since this is
-			// a generic method call.
-			assert(usingSubdict)
+			// a generic method call, unless decl is a full stencil,
+			// where the type arguments are known.
+			assert(usingSubdict || declInfo != nil && declInfo.full)
 
 			// Transform to a function call, by appending the
 			// dictionary and the receiver to the args.
@@ -646,6 +649,13 @@ func checkFetchBody(nameNode *ir.Name) {
 // with the type arguments shapes. If the instantiated function is not already
 // cached, then it calls genericSubst to create the new instantiation.
 func (g *genInst) getInstantiation(nameNode *ir.Name, shapes []*types.Type, isMeth bool) *instInfo {
+	return g.getInstantiation1(nameNode, shapes, isMeth, false)
+}
+
+// getInstantiation1 is like getInstantiation, but if full is set, it
+// instantiates nameNode with the type arguments themselves, rather than
+// their shapes. They must not be or contain shapes.
+func (g *genInst) getInstantiation1(nameNode *ir.Name, shapes []*types.Type, isMeth bool, full bool) *instInfo {
 	if nameNode.Func == nil {
 		// If nameNode.Func is nil, this must be a reference to a method of
 		// an imported instantiated type. We will have already called
@@ -665,6 +675,10 @@ func (g *genInst) getInstantiation(nameNode *ir.Name, shapes []*types.Type, isMe
 	// specified concrete type args.
 	s1 := make([]*types.Type, len(shapes))
 	for i, t := range shapes {
+		if full {
+			s1[i] = t
+			continue
+		}
 		if !t.IsShape() {
 			s1[i] = typecheck.Shapify(t, i)
 		} else {
@@ -681,6 +695,7 @@ func (g *genInst) getInstantiation(nameNode *ir.Name, shapes []*types.Type, isMe
 		// to the list of decls.
 		info = &instInfo{
 			dictInfo: &dictInfo{},
+			full:     full,
 		}
 		info.dictInfo.shapeToBound = make(map[*types.Type]*types.Type)
 
@@ -704,6 +719,39 @@ func (g *genInst) getInstantiation(nameNode *ir.Name, shapes []*types.Type, isMe
 	return info
 }
 
+// fullStencil reports whether the call to the generic function nameNode
+// with type arguments targs should call an instantiation specialized
+// for targs, rather than the one shared by all type arguments with the
+// same shapes. Sharing costs performance: the shared instantiation
+// looks up what depends on the types in a dictionary, and calls and
+// conversions through it can't be inlined or devirtualized. So calls
+// that the -pgoprofile profile shows are hot get their own, as long as
+// the type arguments are known at the call and neither -Os nor -O1
+// asks for small code.
+func fullStencil(call *ir.CallExpr, nameNode *ir.Name, targs []*types.Type) bool {
+	if pgo.Current == nil || base.SmallCode() || !pgo.HotCall(call.Pos(), pgo.GenericName(pgo.SymName(nameNode.Sym()))) {
+		return false
+	}
+	name := nameNode.Sym().Name + "["
+	for i, t := range targs {
+		if i > 0 {
+			name += ","
+		}
+		name += t.String()
+	}
+	name += "]"
+	if hasShapeTypes(targs) {
+		if base.Debug.PGOStencil > 1 {
+			base.WarnfAt(call.Pos(), "pgo: hot call to %s shares its instantiation: type arguments are not known here", name)
+		}
+		return false
+	}
+	if base.Debug.PGOStencil != 0 {
+		base.WarnfAt(call.Pos(), "pgo: fully stenciling hot call to %s", name)
+	}
+	return true
+}
+
 // Struct containing info needed for doing the substitution as we create the
 // instantiation of a generic function with specified type arguments.
 type subster struct {
@@ -742,6 +790,7 @@ func (g *genInst) genericSubst(newsym *types.Sym, nameNode *ir.Name, shapes []*t
 	// Pos of the instantiated function is same as the generic function
 	newf := ir.NewFunc(gf.Pos())
 	newf.Pragma = gf.Pragma // copy over pragmas from generic function to stenciled implementation.
+	newf.Endlineno = gf.Endlineno
 	newf.Nname = ir.NewNameAt(gf.Pos(), newsym)
 	newf.Nname.Func = newf
 	newf.Nname.Defn = newf
@@ -1166,6 +1215,7 @@ func (subst *subster) node(n ir.Node) ir.Node {
 				fun:       newfn,
 				dictParam: ldict,
 				dictInfo:  subst.info.dictInfo,
+				full:      subst.info.full,
 			}
 			subst.g.instInfoMap[newfn.Nname.Sym()] = cinfo
 
@@ -0,0 +1,9 @@
+package basic
+
+const C = 1 << 70
+
+type T struct{ x int }
+
+func (t *T) Get() int { return t.x + 1 }
+
+var V = T{x: 3}
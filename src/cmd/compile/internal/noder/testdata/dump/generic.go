@@ -0,0 +1,23 @@
+package generic
+
+type List[T any] struct {
+	next *List[T]
+	val  T
+}
+
+func (l *List[T]) Len() (n int) {
+	for ; l != nil; l = l.next {
+		n++
+	}
+	return n
+}
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, len(s))
+	for i, x := range s {
+		r[i] = f(x)
+	}
+	return r
+}
+
+var Lens = Map([]*List[int]{nil}, (*List[int]).Len)
@@ -0,0 +1,88 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noder
+
+import (
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/types2"
+)
+
+// printTypeSet prints, for -d=typeset, the type set and core type of
+// the constraint with the given name, as the type checker computes
+// them, to help explain why an operation isn't permitted on a type
+// parameter. The name is one of
+//
+//	Name      a constraint declared in the package, or predeclared
+//	pkg.Name  a constraint declared in an imported package
+//	F.T       the constraint of type parameter T of generic function
+//	          or type F, declared in the package
+//
+// Packages that don't declare or import the constraint print nothing,
+// so that the flag can be given to all the packages of a build.
+func printTypeSet(m *posMap, self *types2.Package, name string) {
+	obj, constraint := lookupConstraint(self, name)
+	if obj == nil {
+		return
+	}
+	iface, ok := constraint.Underlying().(*types2.Interface)
+	if !ok {
+		// A constraint that is not an interface, such as
+		// [T int], stands for interface{ T }.
+		iface = types2.NewInterfaceType(nil, []types2.Type{constraint})
+	}
+	qf := func(pkg *types2.Package) string {
+		if pkg == self {
+			return ""
+		}
+		return pkg.Name()
+	}
+	base.WarnfAt(m.makeXPos(obj.Pos()), "type set of %s: %s", name, types2.DescribeTypeSet(iface, qf))
+}
+
+// lookupConstraint returns the object named by name for -d=typeset,
+// and the constraint it declares or that constrains it, or nil.
+func lookupConstraint(self *types2.Package, name string) (types2.Object, types2.Type) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		obj := self.Scope().Lookup(name)
+		if obj == nil {
+			obj = types2.Universe.Lookup(name)
+		}
+		if obj, ok := obj.(*types2.TypeName); ok && obj.Type() != nil {
+			return obj, obj.Type()
+		}
+		return nil, nil
+	}
+	outer, inner := name[:i], name[i+1:]
+
+	// A type parameter of a generic function or type.
+	var tparams *types2.TypeParamList
+	switch obj := self.Scope().Lookup(outer).(type) {
+	case *types2.Func:
+		tparams = obj.Type().(*types2.Signature).TypeParams()
+	case *types2.TypeName:
+		if named, ok := obj.Type().(*types2.Named); ok {
+			tparams = named.TypeParams()
+		}
+	}
+	for j := 0; j < tparams.Len(); j++ {
+		if tp := tparams.At(j); tp.Obj().Name() == inner {
+			return tp.Obj(), tp.Constraint()
+		}
+	}
+
+	// A constraint declared in an imported package.
+	for _, pkg := range self.Imports() {
+		if pkg.Name() != outer && pkg.Path() != outer {
+			continue
+		}
+		if obj, ok := pkg.Scope().Lookup(inner).(*types2.TypeName); ok {
+			return obj, obj.Type()
+		}
+	}
+	return nil, nil
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"internal/goversion"
 	"io"
+	"os"
 	"runtime"
 	"sort"
 
@@ -88,6 +89,10 @@ func unified(noders []*noder) {
 	}
 
 	data := writePkgStub(noders)
+	if base.Debug.UnifiedDump != 0 {
+		pr := newPkgDecoder(base.Ctxt.Pkgpath, data)
+		dumpPkg(os.Stdout, &pr)
+	}
 
 	// We already passed base.Flag.Lang to types2 to handle validating
 	// the user's source code. Bump it up now to the current version and
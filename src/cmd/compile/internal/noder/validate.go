@@ -9,6 +9,7 @@ import (
 
 	"cmd/compile/internal/base"
 	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
 	"cmd/compile/internal/types2"
 )
@@ -72,6 +73,53 @@ func (g *irgen) validate(n syntax.Node) {
 				g.unhandled("builtin", n)
 			}
 		}
+	case *syntax.Operation:
+		// types2 currently ignores pragmas, so check the //go:nocompare
+		// type pragma here.
+		if (n.Op == syntax.Eql || n.Op == syntax.Neq) && n.Y != nil {
+			if why := typecheck.NoCompareReason(g.typ(g.info.Types[n.X].Type), g.typ(g.info.Types[n.Y].Type)); why != "" {
+				base.ErrorfAt(g.pos(n), "invalid operation: %v %v %v (%s)", syntax.String(n.X), n.Op, syntax.String(n.Y), why)
+			}
+		}
+	case *syntax.SwitchStmt:
+		if _, ok := n.Tag.(*syntax.TypeSwitchGuard); n.Tag != nil && !ok {
+			if why := typecheck.NoCompareReason(g.typ(g.info.Types[n.Tag].Type)); why != "" {
+				base.ErrorfAt(g.pos(n), "cannot switch on %v (%s)", syntax.String(n.Tag), why)
+			}
+		}
+	case *syntax.MapType:
+		if why := typecheck.NoHashReason(g.typ(g.info.Types[n.Key].Type)); why != "" {
+			base.ErrorfAt(g.pos(n), "invalid map key type %v (%s)", syntax.String(n.Key), why)
+		}
+	case *syntax.Name:
+		if inst, ok := g.info.Instances[n]; ok {
+			g.validateInstance(n, inst)
+		}
+	}
+}
+
+// validateInstance checks the type arguments of the instantiation of
+// a generic function or type at n against the //go:nocompare and
+// //go:nohash type pragmas, which types2 ignores. A type parameter
+// whose constraint is comparable lets the generic code compare values
+// and use them as map keys, so its type argument must allow both.
+func (g *irgen) validateInstance(n *syntax.Name, inst types2.Instance) {
+	var tparams *types2.TypeParamList
+	switch t := g.info.Uses[n].Type().(type) {
+	case *types2.Signature:
+		tparams = t.TypeParams()
+	case *types2.Named:
+		tparams = t.TypeParams()
+	}
+	for i := 0; i < tparams.Len() && i < inst.TypeArgs.Len(); i++ {
+		constraint, ok := tparams.At(i).Constraint().Underlying().(*types2.Interface)
+		if !ok || !constraint.IsComparable() {
+			continue
+		}
+		targ := inst.TypeArgs.At(i)
+		if why := typecheck.NoHashReason(g.typ(targ)); why != "" {
+			base.ErrorfAt(g.pos(n), "%v does not implement comparable (%s)", targ, why)
+		}
 	}
 }
 
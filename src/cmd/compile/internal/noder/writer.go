@@ -713,6 +713,11 @@ func (w *writer) funcExt(obj *types2.Func) {
 	if pragma&ir.Systemstack != 0 && pragma&ir.Nosplit != 0 {
 		w.p.errorf(decl, "go:nosplit and go:systemstack cannot be combined")
 	}
+	if pragma&ir.CPULevels != 0 {
+		if msg := goCPUError(decl); msg != "" {
+			w.p.errorf(decl, "%s", msg)
+		}
+	}
 
 	if decl.Body != nil {
 		if pragma&ir.Noescape != 0 {
@@ -0,0 +1,285 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pgo
+
+import (
+	"sort"
+	"strings"
+)
+
+// Source edited since a profile was taken no longer has its samples
+// on the lines the profile says. Match maps the profile back onto the
+// source being compiled, one function at a time:
+//
+//  1. The lines sampled in a function (and its closures) are shifted
+//     by the offset that puts the most sample weight on calls that are
+//     still to the same function, and then on lines that now have
+//     code. Edits elsewhere in the file move a function as a whole,
+//     so this usually finds all of them. If that leaves a choice, the
+//     function is assumed to have moved with the one before it.
+//  2. A sampled line that then has no code is moved to the nearest
+//     line with code within fuzzLines, to allow for small edits
+//     inside the function. If there is none, its samples are dropped.
+//
+// Each frame of a stack, including the frames of inlined calls, is
+// matched with its own function, so call sites move with their
+// callers and inlined code with its callee. Samples in functions of
+// the package that no longer exist are dropped; samples in other
+// packages are assumed to still match.
+
+// fuzzLines is how far Match moves a sampled line to find code.
+const fuzzLines = 2
+
+// A Func describes a function of the package being compiled.
+type Func struct {
+	Name  string             // linker symbol name
+	File  string             // file name, as recorded in binaries
+	Lines map[int64]bool     // lines with code, including those of closures
+	Calls map[int64][]string // functions called on each line, by linker symbol name without type arguments
+}
+
+// A FuncMatch reports how the profile matched a Func.
+type FuncMatch struct {
+	Shift   int64 // lines the function moved since the profile was taken
+	Weight  int64 // weight of the samples in the function
+	Matched int64 // weight of those that were matched to code
+}
+
+// A MatchResult reports how the profile matched a package.
+type MatchResult struct {
+	// Weight is the total weight of the samples that were executing
+	// code of the package, and Matched is the weight of those whose
+	// lines were matched to the source.
+	Weight, Matched int64
+
+	// Funcs reports how each of the Funcs matched, in order.
+	// Functions without samples have zero Weight.
+	Funcs []FuncMatch
+
+	// Missing lists the functions of the package that have samples,
+	// but aren't among the Funcs.
+	Missing []string
+}
+
+// Match maps the profile's samples onto fns, the functions of the
+// package with symbol name prefix pkg, as they are in the source
+// being compiled, and from then on reports line and call weights for
+// that source.
+func (p *Profile) Match(pkg string, fns []Func) *MatchResult {
+	byName := make(map[string]int)
+	for i, fn := range fns {
+		name := GenericName(fn.Name)
+		if _, dup := byName[name]; !dup {
+			byName[name] = i
+		}
+	}
+	owner := func(name string) int {
+		// Instantiations are matched with their generic function.
+		name = GenericName(name)
+		for {
+			if i, ok := byName[name]; ok {
+				return i
+			}
+			// Closures, and the copies of a function the compiler
+			// makes to specialize it or to compile it for other CPU
+			// levels, are matched with the function they come from.
+			dot := strings.LastIndex(name, ".")
+			if dot < 0 || !isDerivedSuffix(name[dot+1:]) {
+				return -1
+			}
+			name = name[:dot]
+		}
+	}
+
+	// Collect the weight of each function's sampled lines and calls.
+	weights := make([]*funcWeights, len(fns))
+	missing := make(map[string]bool)
+	for _, s := range p.samples {
+		seen := make(map[frame]bool)
+		for k, f := range s.stack {
+			if seen[f] || !inPackage(f.fn, pkg) {
+				continue
+			}
+			seen[f] = true
+			i := owner(f.fn)
+			if i < 0 {
+				missing[f.fn] = true
+				continue
+			}
+			w := weights[i]
+			if w == nil {
+				w = &funcWeights{lines: make(map[int64]int64), calls: make(map[callSite]int64)}
+				weights[i] = w
+			}
+			w.lines[f.line] += s.weight
+			if k > 0 {
+				w.calls[callSite{f.line, GenericName(s.stack[k-1].fn)}] += s.weight
+			}
+		}
+	}
+
+	res := &MatchResult{Funcs: make([]FuncMatch, len(fns))}
+	moves := make([]map[int64]int64, len(fns))
+	prev := make(map[string]int64) // shift of the last function matched in each file
+	for i, w := range weights {
+		if w == nil {
+			continue
+		}
+		shift := bestShift(w, &fns[i], prev[fns[i].File])
+		prev[fns[i].File] = shift
+		m := &res.Funcs[i]
+		m.Shift = shift
+		moves[i] = make(map[int64]int64)
+		for line, weight := range w.lines {
+			m.Weight += weight
+			if to, ok := nearestCode(line+shift, fns[i].Lines); ok {
+				moves[i][line] = to
+				m.Matched += weight
+			}
+		}
+	}
+	for name := range missing {
+		res.Missing = append(res.Missing, name)
+	}
+	sort.Strings(res.Missing)
+
+	move := func(f frame) (frame, bool) {
+		if !inPackage(f.fn, pkg) {
+			return f, true
+		}
+		i := owner(f.fn)
+		if i < 0 {
+			return f, false
+		}
+		to, ok := moves[i][f.line]
+		return frame{f.fn, fns[i].File, to}, ok
+	}
+	for _, s := range p.samples {
+		if len(s.stack) == 0 || !inPackage(s.stack[0].fn, pkg) {
+			continue
+		}
+		res.Weight += s.weight
+		if _, ok := move(s.stack[0]); ok {
+			res.Matched += s.weight
+		}
+	}
+	p.index(move)
+	p.matched = true
+	return res
+}
+
+// funcWeights is the weight of the samples in a function, by line
+// and by call.
+type funcWeights struct {
+	lines map[int64]int64
+	calls map[callSite]int64
+}
+
+type callSite struct {
+	line   int64
+	callee string
+}
+
+// bestShift returns the offset to add to the sampled lines of fn, with
+// the given weights, to put the most weight on calls to the same
+// functions, and then on lines with code. Ties go to the shift nearest
+// to prefer, and then the smallest.
+func bestShift(w *funcWeights, fn *Func, prefer int64) int64 {
+	type score struct{ calls, lines int64 }
+	scores := make(map[int64]score)
+	for c, weight := range w.calls {
+		for line, callees := range fn.Calls {
+			for _, callee := range callees {
+				if callee == c.callee {
+					s := scores[line-c.line]
+					s.calls += weight
+					scores[line-c.line] = s
+				}
+			}
+		}
+	}
+	for line, weight := range w.lines {
+		for c := range fn.Lines {
+			s := scores[c-line]
+			s.lines += weight
+			scores[c-line] = s
+		}
+	}
+	best := prefer
+	var bestScore score
+	for shift, s := range scores {
+		switch {
+		case s.calls != bestScore.calls:
+			if s.calls < bestScore.calls {
+				continue
+			}
+		case s.lines != bestScore.lines:
+			if s.lines < bestScore.lines {
+				continue
+			}
+		case abs(shift-prefer) > abs(best-prefer) || abs(shift-prefer) == abs(best-prefer) && shift > best:
+			continue
+		}
+		best, bestScore = shift, s
+	}
+	return best
+}
+
+// nearestCode returns the line with code nearest to line, looking at
+// most fuzzLines away. Ties go to the earlier line.
+func nearestCode(line int64, code map[int64]bool) (int64, bool) {
+	for d := int64(0); d <= fuzzLines; d++ {
+		if code[line-d] {
+			return line - d, true
+		}
+		if code[line+d] {
+			return line + d, true
+		}
+	}
+	return 0, false
+}
+
+// inPackage reports whether the function with symbol name fn is in
+// the package with symbol name prefix pkg.
+func inPackage(fn, pkg string) bool {
+	if !strings.HasPrefix(fn, pkg+".") {
+		return false
+	}
+	// Package a doesn't contain a.b/c.F.
+	rest := fn[len(pkg)+1:]
+	if i := strings.Index(rest, "["); i >= 0 {
+		rest = rest[:i]
+	}
+	return !strings.Contains(rest, "/")
+}
+
+// isDerivedSuffix reports whether s, the last element of a function's
+// symbol name, names a function derived from another: a closure, like
+// func1 in F.func1 and 2 in F.func1.2, a specialized copy, like spec1,
+// or a copy for a CPU level, like amd64v3.
+func isDerivedSuffix(s string) bool {
+	for _, prefix := range []string{"func", "spec", "amd64v"} {
+		if strings.HasPrefix(s, prefix) {
+			s = s[len(prefix):]
+			break
+		}
+	}
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
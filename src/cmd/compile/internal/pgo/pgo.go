@@ -0,0 +1,240 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pgo reads the CPU profile used for profile-guided
+// optimization (-pgoprofile), and attributes its samples to source
+// lines and functions.
+package pgo
+
+import (
+	"fmt"
+	"internal/profile"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Current is the profile read from the -pgoprofile flag, or nil.
+var Current *Profile
+
+// hotFraction is the fraction of a profile's samples covered by the
+// functions it reports as hot.
+const hotFraction = 0.9
+
+// hotCallFraction is the fraction of a profile's samples that must
+// include a call for HotCall to report it.
+const hotCallFraction = 0.01
+
+// A Profile holds the sample weights of a CPU profile.
+type Profile struct {
+	// samples are the profile's samples, kept so that their lines
+	// can be matched to edited source (see Match).
+	samples []sample
+
+	// lines is the total weight of the samples whose stacks include
+	// each source line, counting each line once per sample.
+	lines map[lineKey]int64
+
+	// calls is the total weight of the samples whose stacks include
+	// each call, counting each call once per sample.
+	calls map[callKey]int64
+
+	// total is the total weight of the samples.
+	total int64
+
+	// matched is set once the profile has been matched to the source
+	// being compiled (see Match).
+	matched bool
+
+	// hot is the set of functions, by linker symbol name, that
+	// together account for hotFraction of the samples executing
+	// their own code.
+	hot map[string]bool
+}
+
+type lineKey struct {
+	file string
+	line int64
+}
+
+// A callKey is a call on a line to the function with the given linker
+// symbol name, without its type arguments (see GenericName).
+type callKey struct {
+	lineKey
+	callee string
+}
+
+// A sample is a stack of frames, innermost first, and its weight.
+// Frames of inlined calls are separate, as in the source.
+type sample struct {
+	weight int64
+	stack  []frame
+}
+
+type frame struct {
+	fn   string // linker symbol name of the function
+	file string
+	line int64
+}
+
+// Load reads the CPU profile in the named file.
+func Load(name string) (*Profile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	prof, err := profile.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	p, err := build(prof)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return p, nil
+}
+
+func build(prof *profile.Profile) (*Profile, error) {
+	if len(prof.SampleType) == 0 {
+		return nil, fmt.Errorf("profile has no sample values")
+	}
+
+	// Use the sample count, or failing that the first value.
+	index := 0
+	for i, st := range prof.SampleType {
+		if st.Type == "samples" {
+			index = i
+			break
+		}
+	}
+
+	p := &Profile{hot: make(map[string]bool)}
+	self := make(map[string]int64)
+	for _, s := range prof.Sample {
+		w := s.Value[index]
+		if w <= 0 || len(s.Location) == 0 {
+			continue
+		}
+		p.total += w
+
+		var stack []frame
+		for _, loc := range s.Location {
+			for _, l := range loc.Line {
+				if l.Function != nil {
+					stack = append(stack, frame{l.Function.Name, l.Function.Filename, l.Line})
+				}
+			}
+		}
+		p.samples = append(p.samples, sample{w, stack})
+
+		// The leaf location's outermost line is the function whose
+		// code was executing; the others were inlined into it.
+		if leaf := s.Location[0].Line; len(leaf) > 0 && leaf[len(leaf)-1].Function != nil {
+			self[leaf[len(leaf)-1].Function.Name] += w
+		}
+	}
+	p.index(nil)
+
+	names := make([]string, 0, len(self))
+	for name := range self {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if self[names[i]] != self[names[j]] {
+			return self[names[i]] > self[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	var sum int64
+	for _, name := range names {
+		if float64(sum) >= hotFraction*float64(p.total) {
+			break
+		}
+		p.hot[name] = true
+		sum += self[name]
+	}
+	return p, nil
+}
+
+// index computes p.lines and p.calls from the samples. If move is not
+// nil, it returns where each frame's line is in the source being
+// compiled, or false if it isn't.
+func (p *Profile) index(move func(frame) (frame, bool)) {
+	p.lines = make(map[lineKey]int64)
+	p.calls = make(map[callKey]int64)
+	for _, s := range p.samples {
+		seenLine := make(map[lineKey]bool)
+		seenCall := make(map[callKey]bool)
+		for i, f := range s.stack {
+			if move != nil {
+				var ok bool
+				if f, ok = move(f); !ok {
+					continue
+				}
+			}
+			k := lineKey{f.file, f.line}
+			if !seenLine[k] {
+				seenLine[k] = true
+				p.lines[k] += s.weight
+			}
+			if i == 0 {
+				continue
+			}
+			c := callKey{k, GenericName(s.stack[i-1].fn)}
+			if !seenCall[c] {
+				seenCall[c] = true
+				p.calls[c] += s.weight
+			}
+		}
+	}
+}
+
+// LineWeight returns the total weight of the samples whose stacks
+// include the given line of the named file, as recorded in the
+// binary that was profiled.
+func (p *Profile) LineWeight(file string, line int64) int64 {
+	return p.lines[lineKey{file, line}]
+}
+
+// Hot reports whether the function with the given linker symbol name
+// is one of the functions that account for most of the time spent in
+// the profile.
+func (p *Profile) Hot(name string) bool {
+	return p.hot[name]
+}
+
+// CallWeight returns the total weight of the samples whose stacks
+// include a call on the given line of the named file to the function
+// with the given linker symbol name, without its type arguments.
+func (p *Profile) CallWeight(file string, line int64, callee string) int64 {
+	return p.calls[callKey{lineKey{file, line}, callee}]
+}
+
+// GenericName returns the linker symbol name fn without the type
+// arguments of the generic function or type it instantiates, so that
+// all the instantiations of a generic function have the same name.
+// For example, the name of
+//
+//	a.Map[go.shape.int_0,go.shape.string_1]
+//
+// is a.Map, and that of a.(*List[int]).Push.func1 is a.(*List).Push.func1.
+func GenericName(fn string) string {
+	if !strings.Contains(fn, "[") {
+		return fn
+	}
+	var b strings.Builder
+	depth := 0
+	for _, c := range fn {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
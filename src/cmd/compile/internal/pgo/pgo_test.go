@@ -0,0 +1,200 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pgo
+
+import (
+	"internal/profile"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	main := &profile.Function{ID: 1, Name: "main.main", Filename: "/x/main.go"}
+	loop := &profile.Function{ID: 2, Name: "main.loop", Filename: "/x/main.go"}
+	add := &profile.Function{ID: 3, Name: "main.add", Filename: "/x/add.go"}
+	cold := &profile.Function{ID: 4, Name: "main.cold", Filename: "/x/cold.go"}
+
+	// main calls loop, which inlines add at line 20 and calls
+	// cold at line 21. Line 20 of main.go appears twice in the
+	// second location's stack, to check it's only counted once.
+	inMain := &profile.Location{ID: 1, Line: []profile.Line{{Function: main, Line: 5}}}
+	inLoop := &profile.Location{ID: 2, Line: []profile.Line{
+		{Function: add, Line: 3},
+		{Function: loop, Line: 20},
+	}}
+	inCold := &profile.Location{ID: 3, Line: []profile.Line{{Function: cold, Line: 7}}}
+	callCold := &profile.Location{ID: 4, Line: []profile.Line{{Function: loop, Line: 21}}}
+	recurse := &profile.Location{ID: 5, Line: []profile.Line{{Function: loop, Line: 20}}}
+
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{inLoop, inMain}, Value: []int64{90, 900}},
+			{Location: []*profile.Location{inLoop, recurse, inMain}, Value: []int64{5, 50}},
+			{Location: []*profile.Location{inCold, callCold, inMain}, Value: []int64{5, 50}},
+		},
+	}
+	p, err := build(prof)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		file string
+		line int64
+		want int64
+	}{
+		{"/x/main.go", 5, 100},
+		{"/x/main.go", 20, 95},
+		{"/x/main.go", 21, 5},
+		{"/x/add.go", 3, 95},
+		{"/x/cold.go", 7, 5},
+		{"/x/cold.go", 8, 0},
+	} {
+		if got := p.LineWeight(tt.file, tt.line); got != tt.want {
+			t.Errorf("LineWeight(%q, %d) = %d, want %d", tt.file, tt.line, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		file   string
+		line   int64
+		callee string
+		want   int64
+	}{
+		{"/x/main.go", 20, "main.add", 95},
+		{"/x/main.go", 20, "main.loop", 5},
+		{"/x/main.go", 21, "main.cold", 5},
+		{"/x/main.go", 21, "main.add", 0},
+	} {
+		if got := p.CallWeight(tt.file, tt.line, tt.callee); got != tt.want {
+			t.Errorf("CallWeight(%q, %d, %q) = %d, want %d", tt.file, tt.line, tt.callee, got, tt.want)
+		}
+	}
+
+	for name, want := range map[string]bool{
+		"main.loop": true,
+		"main.add":  false, // inlined
+		"main.main": false,
+		"main.cold": false,
+	} {
+		if got := p.Hot(name); got != want {
+			t.Errorf("Hot(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBuildNoSamples(t *testing.T) {
+	if _, err := build(&profile.Profile{}); err == nil {
+		t.Errorf("build succeeded for a profile without sample types")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	f := &profile.Function{ID: 1, Name: "main.f", Filename: "/x/main.go"}
+	g := &profile.Function{ID: 2, Name: "main.g", Filename: "/x/main.go"}
+	gone := &profile.Function{ID: 3, Name: "main.gone", Filename: "/x/main.go"}
+	h := &profile.Function{ID: 4, Name: "other.h", Filename: "/x/other.go"}
+	loc := func(fn *profile.Function, line int64) *profile.Location {
+		return &profile.Location{Line: []profile.Line{{Function: fn, Line: line}}}
+	}
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}},
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{loc(g, 20), loc(f, 10)}, Value: []int64{50}},
+			{Location: []*profile.Location{loc(f, 11)}, Value: []int64{30}},
+			{Location: []*profile.Location{loc(gone, 30)}, Value: []int64{10}},
+			{Location: []*profile.Location{loc(h, 5), loc(f, 12)}, Value: []int64{10}},
+		},
+	}
+	p, err := build(prof)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Since the profile was taken, three lines were added above f,
+	// and gone was deleted. f still has code on its old lines, but
+	// its calls say where it went.
+	fns := []Func{
+		{
+			Name:  "main.f",
+			File:  "/x/main.go",
+			Lines: map[int64]bool{10: true, 11: true, 12: true, 13: true, 14: true, 15: true},
+			Calls: map[int64][]string{13: {"main.g"}, 15: {"other.h"}},
+		},
+		{
+			Name:  "main.g",
+			File:  "/x/main.go",
+			Lines: map[int64]bool{19: true, 20: true},
+		},
+	}
+	res := p.Match("main", fns)
+
+	if res.Weight != 90 || res.Matched != 80 {
+		t.Errorf("matched %d of %d, want 80 of 90", res.Matched, res.Weight)
+	}
+	for i, want := range []FuncMatch{{Shift: 3, Weight: 90, Matched: 90}, {Shift: 0, Weight: 50, Matched: 50}} {
+		if res.Funcs[i] != want {
+			t.Errorf("%s matched %+v, want %+v", fns[i].Name, res.Funcs[i], want)
+		}
+	}
+	if len(res.Missing) != 1 || res.Missing[0] != "main.gone" {
+		t.Errorf("missing %v, want [main.gone]", res.Missing)
+	}
+
+	for _, tt := range []struct {
+		file string
+		line int64
+		want int64
+	}{
+		{"/x/main.go", 10, 0},
+		{"/x/main.go", 13, 50},
+		{"/x/main.go", 14, 30},
+		{"/x/main.go", 15, 10},
+		{"/x/main.go", 20, 50},
+		{"/x/main.go", 30, 0},
+		{"/x/other.go", 5, 10},
+	} {
+		if got := p.LineWeight(tt.file, tt.line); got != tt.want {
+			t.Errorf("LineWeight(%q, %d) = %d, want %d", tt.file, tt.line, got, tt.want)
+		}
+	}
+	if got := p.CallWeight("/x/main.go", 13, "main.g"); got != 50 {
+		t.Errorf("CallWeight of moved call = %d, want 50", got)
+	}
+}
+
+func TestInPackage(t *testing.T) {
+	for _, tt := range []struct {
+		fn, pkg string
+		want    bool
+	}{
+		{"main.f", "main", true},
+		{"main.(*T).m.func1", "main", true},
+		{"a.b/c.F", "a", false},
+		{"a.F[a/b.T]", "a", true},
+		{"mainx.f", "main", false},
+	} {
+		if got := inPackage(tt.fn, tt.pkg); got != tt.want {
+			t.Errorf("inPackage(%q, %q) = %v, want %v", tt.fn, tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestGenericName(t *testing.T) {
+	for fn, want := range map[string]string{
+		"main.f": "main.f",
+		"main.Map[go.shape.int_0,go.shape.string_1]": "main.Map",
+		"main.Map[go.shape.[]int_0].func1":           "main.Map.func1",
+		"main.(*List[int]).Push":                     "main.(*List).Push",
+		"a.b/c.F[...]":                               "a.b/c.F",
+	} {
+		if got := GenericName(fn); got != want {
+			t.Errorf("GenericName(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pgo
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+	"cmd/internal/objabi"
+	"cmd/internal/src"
+	"internal/buildcfg"
+	"strings"
+)
+
+// FileName returns the name of pos's file as the profile has it: the
+// name recorded in the binary that was profiled, where the linker has
+// expanded $GOROOT.
+func FileName(pos src.Pos) string {
+	file := pos.AbsFilename()
+	if strings.HasPrefix(file, "$GOROOT/") {
+		file = buildcfg.GOROOT + file[len("$GOROOT"):]
+	}
+	return file
+}
+
+// SymName returns the linker symbol name of s, as the profile has it.
+func SymName(s *types.Sym) string {
+	if s.Pkg == types.LocalPkg {
+		return objabi.PathToPrefix(base.Ctxt.Pkgpath) + "." + s.Name
+	}
+	return s.Pkg.Prefix + "." + s.Name
+}
+
+// HotCall reports whether enough of the samples of Current, the
+// -pgoprofile profile, include the call at pos to the function with
+// linker symbol name callee, without its type arguments (see
+// GenericName), for it to be worth optimizing at some cost in code
+// size.
+func HotCall(pos src.XPos, callee string) bool {
+	if Current == nil || Current.total == 0 {
+		return false
+	}
+	p := base.Ctxt.PosTable.Pos(pos)
+	w := Current.CallWeight(FileName(p), int64(p.RelLine()), callee)
+	return float64(w) >= hotCallFraction*float64(Current.total)
+}
+
+// MatchSource matches Current, the -pgoprofile profile, to the
+// functions declared in the package being compiled, as they are in
+// its source. See Match. It does nothing if it has already done so.
+//
+// It must run before inlining, while the functions' bodies only have
+// their own positions, and before generic functions are instantiated,
+// while they are still among decls.
+func MatchSource(decls []ir.Node) {
+	if Current.matched {
+		return
+	}
+	pkg := objabi.PathToPrefix(base.Ctxt.Pkgpath)
+	var fns []Func
+	var decl []*ir.Func
+	for _, n := range decls {
+		if n.Op() != ir.ODCLFUNC {
+			continue
+		}
+		fn := n.(*ir.Func)
+		if fn.Nname == nil || len(fn.Body) == 0 {
+			continue
+		}
+		pos := base.Ctxt.PosTable.Pos(fn.Pos())
+		f := Func{
+			Name:  pkg + "." + ir.FuncName(fn),
+			File:  FileName(pos),
+			Lines: make(map[int64]bool),
+			Calls: make(map[int64][]string),
+		}
+		line := func(xpos src.XPos) (int64, bool) {
+			p := base.Ctxt.PosTable.Pos(xpos)
+			return int64(p.RelLine()), p.IsKnown() && p.Base() == pos.Base()
+		}
+		addLine := func(xpos src.XPos) {
+			if l, ok := line(xpos); ok {
+				f.Lines[l] = true
+			}
+		}
+		addLine(fn.Pos())
+		addLine(fn.Endlineno)
+		// Deferred calls happen at the function's exits, not where
+		// they are in the source.
+		deferred := make(map[ir.Node]bool)
+		var visit func(ir.Node)
+		visit = func(n ir.Node) {
+			addLine(n.Pos())
+			switch n.Op() {
+			case ir.ODEFER, ir.OGO:
+				deferred[n.(*ir.GoDeferStmt).Call] = true
+			case ir.OCALLFUNC, ir.OCALLMETH:
+				if deferred[n] {
+					break
+				}
+				if callee := callee(n.(*ir.CallExpr)); callee != "" {
+					if l, ok := line(n.Pos()); ok {
+						f.Calls[l] = append(f.Calls[l], callee)
+					}
+				}
+			case ir.OCLOSURE:
+				clo := n.(*ir.ClosureExpr).Func
+				addLine(clo.Endlineno)
+				ir.VisitList(clo.Body, visit)
+			}
+		}
+		ir.VisitList(fn.Body, visit)
+		fns = append(fns, f)
+		decl = append(decl, fn)
+	}
+
+	res := Current.Match(pkg, fns)
+	if base.Debug.PGOMatch == 0 {
+		return
+	}
+	// Report on the package at its first function.
+	pos := src.NoXPos
+	if len(decl) > 0 {
+		pos = decl[0].Pos()
+	}
+	if res.Weight == 0 {
+		base.WarnfAt(pos, "pgo: profile has no samples for this package")
+		return
+	}
+	base.WarnfAt(pos, "pgo: %d%% of the profile samples for this package matched this build", 100*res.Matched/res.Weight)
+	if base.Debug.PGOMatch > 1 {
+		for i, m := range res.Funcs {
+			if m.Weight == 0 {
+				continue
+			}
+			base.WarnfAt(decl[i].Pos(), "pgo: %s shifted %+d lines, %d%% of its samples matched", fns[i].Name, m.Shift, 100*m.Matched/m.Weight)
+		}
+		for _, name := range res.Missing {
+			base.WarnfAt(pos, "pgo: profile function %s is not in this build", name)
+		}
+	}
+}
+
+// callee returns the linker symbol name of the function or method
+// that call calls statically, without its type arguments, or "" if
+// there isn't one.
+func callee(call *ir.CallExpr) string {
+	var name *ir.Name
+	switch call.Op() {
+	case ir.OCALL, ir.OCALLFUNC:
+		fn := call.X
+		if fn.Op() == ir.OFUNCINST {
+			fn = fn.(*ir.InstExpr).X
+		}
+		switch fn.Op() {
+		case ir.ONAME:
+			if fn.(*ir.Name).Class == ir.PFUNC {
+				name = fn.(*ir.Name)
+			}
+		case ir.OMETHEXPR:
+			name = ir.MethodExprName(fn)
+		}
+	case ir.OCALLMETH:
+		name = ir.MethodExprName(call.X)
+	}
+	if name == nil || name.Sym() == nil || name.Sym().Pkg == nil {
+		return ""
+	}
+	return GenericName(SymName(name.Sym()))
+}
@@ -1053,7 +1053,7 @@ func ssaGenValue(s *ssagen.State, v *ssa.Value) {
 
 			// Don't generate padding for
 			// loops with few iterations.
-			if ctr > 3 {
+			if ctr > 3 && !base.Flag.OptSize {
 				p = s.Prog(obj.APCALIGN)
 				p.From.Type = obj.TYPE_CONST
 				p.From.Offset = 16
@@ -1265,7 +1265,7 @@ func ssaGenValue(s *ssagen.State, v *ssa.Value) {
 
 			// Don't add padding for alignment
 			// with few loop iterations.
-			if ctr > 3 {
+			if ctr > 3 && !base.Flag.OptSize {
 				p = s.Prog(obj.APCALIGN)
 				p.From.Type = obj.TYPE_CONST
 				p.From.Offset = 16
@@ -1425,7 +1425,7 @@ func ssaGenValue(s *ssagen.State, v *ssa.Value) {
 			// Don't adding padding for
 			// alignment with small iteration
 			// counts.
-			if ctr > 3 {
+			if ctr > 3 && !base.Flag.OptSize {
 				p = s.Prog(obj.APCALIGN)
 				p.From.Type = obj.TYPE_CONST
 				p.From.Offset = 16
@@ -1647,9 +1647,11 @@ func ssaGenValue(s *ssagen.State, v *ssa.Value) {
 			p.To.Type = obj.TYPE_REG
 			p.To.Reg = ppc64.REG_CTR
 
-			p = s.Prog(obj.APCALIGN)
-			p.From.Type = obj.TYPE_CONST
-			p.From.Offset = 16
+			if !base.Flag.OptSize {
+				p = s.Prog(obj.APCALIGN)
+				p.From.Type = obj.TYPE_CONST
+				p.From.Offset = 16
+			}
 
 			// Generate 16 byte loads and stores.
 			p = s.Prog(ppc64.ALXV)
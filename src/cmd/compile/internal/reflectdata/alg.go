@@ -646,6 +646,61 @@ func geneq(t *types.Type) *obj.LSym {
 	return closure
 }
 
+// DescribeEq describes, for -d=eqsize, the comparisons that the
+// equality function geneq generates for t make: how many there are,
+// and whether they are made in a loop over the elements of an array.
+// It must be kept in sync with geneq.
+func DescribeEq(t *types.Type) string {
+	switch t.Kind() {
+	case types.TARRAY:
+		// geneq unrolls the checks of arrays with at most unroll
+		// elements, and otherwise loops over them.
+		nelem := t.NumElem()
+		checks, unroll := nelem, int64(1)
+		switch t.Elem().Kind() {
+		case types.TSTRING:
+			// One pass comparing lengths, one comparing contents.
+			checks *= 2
+			unroll = 3
+		case types.TFLOAT32, types.TFLOAT64:
+			unroll = 2
+		}
+		if nelem > unroll {
+			return fmt.Sprintf("generated eq func, %d comparisons in a loop", checks)
+		}
+		return fmt.Sprintf("generated eq func, %d comparisons", checks)
+
+	case types.TSTRUCT:
+		checks := 0
+		for i, fields := 0, t.FieldSlice(); i < len(fields); {
+			f := fields[i]
+			switch {
+			case f.Sym.IsBlank():
+				i++
+			case !isRegularMemory(f.Type):
+				checks++
+				if f.Type.IsString() {
+					checks++ // length and contents
+				}
+				i++
+			default:
+				// Runs of more than two memory fields are
+				// compared with one call to memequal.
+				_, next := memrun(t, i)
+				if n := next - i; n <= 2 {
+					checks += n
+				} else {
+					checks++
+				}
+				i = next
+			}
+		}
+		return fmt.Sprintf("generated eq func, %d comparisons", checks)
+	}
+	base.Fatalf("DescribeEq %v", t)
+	return ""
+}
+
 func anyCall(fn *ir.Func) bool {
 	return ir.Any(fn, func(n ir.Node) bool {
 		// TODO(rsc): No methods?
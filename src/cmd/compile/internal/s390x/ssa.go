@@ -256,6 +256,16 @@ func ssaGenValue(s *ssagen.State, v *ssa.Value) {
 		p.From.Reg = r0
 		p.To.Reg = s390x.REG_R2
 		p.To.Type = obj.TYPE_REG
+	case ssa.OpS390XDLGR:
+		// DLGR Rx R2:R3 -> R3 (quotient), R2 (remainder)
+		if v.Args[0].Reg() != s390x.REG_R2 || v.Args[1].Reg() != s390x.REG_R3 {
+			v.Fatalf("We require the dividend to be stored in R2:R3 for DLGR %s", v.LongString())
+		}
+		p := s.Prog(s390x.ADLGR)
+		p.From.Type = obj.TYPE_REG
+		p.From.Reg = v.Args[2].Reg()
+		p.To.Reg = s390x.REG_R2
+		p.To.Type = obj.TYPE_REG
 	case ssa.OpS390XFMADD, ssa.OpS390XFMADDS,
 		ssa.OpS390XFMSUB, ssa.OpS390XFMSUBS:
 		r1 := v.Args[1].Reg()
@@ -0,0 +1,346 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package soa converts local slices of structs marked //go:soa into
+// one slice per field of the struct:
+//
+//	//go:soa
+//	var ps []Particle
+//
+// so that a loop reading a few fields of every element touches only
+// the memory holding those fields. This is only possible if the
+// variable is used one field at a time, in these forms:
+//
+//	ps[i].f // read, assigned or with its address taken
+//	len(ps)
+//	for i := range ps
+//	ps = nil
+//	ps = make([]Particle, n, c)
+//	ps = append(ps, p1, p2)
+//	ps = ps[i:j] // j omitted, 0, len(ps) or len(ps)-c
+//
+// and is not captured by a closure. The compiler reports the variables
+// it cannot convert, and with -m, those it converts.
+package soa
+
+import (
+	"fmt"
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+)
+
+// Func converts the //go:soa variables declared in fn.
+// It must run before inlining and escape analysis.
+func Func(fn *ir.Func) {
+	var vars []*ir.Name
+	for _, n := range fn.Dcl {
+		if n.Pragma()&ir.SoA != 0 {
+			vars = append(vars, n)
+		}
+	}
+	if len(vars) == 0 {
+		return
+	}
+
+	ir.CurFunc = fn
+	for _, v := range vars {
+		if pos, why := check(fn, v); why != "" {
+			base.WarnfAt(pos, "%v not converted to struct of slices: %s", v, why)
+			continue
+		}
+		c := newConverter(fn, v)
+		for i, n := range fn.Body {
+			fn.Body[i] = c.edit(n)
+		}
+		if base.Flag.LowerM != 0 {
+			base.WarnfAt(v.Pos(), "converted %v to struct of slices", v)
+		}
+	}
+	ir.CurFunc = nil
+}
+
+// check reports why v cannot be converted, and where, or "" if it can.
+func check(fn *ir.Func, v *ir.Name) (pos src.XPos, why string) {
+	t := v.Type()
+	if !t.IsSlice() || !t.Elem().IsStruct() {
+		return v.Pos(), "it is not a slice of structs"
+	}
+	if len(fieldsOf(t.Elem())) == 0 {
+		return v.Pos(), "its element type has no fields"
+	}
+
+	fail := func(n ir.Node, reason string) bool {
+		pos, why = n.Pos(), reason
+		return true
+	}
+	var do func(ir.Node) bool
+	assign := func(n *ir.AssignStmt) bool {
+		switch y := n.Y; {
+		case y == nil || y.Op() == ir.ONIL:
+			return false
+		case y.Op() == ir.OMAKESLICE:
+			return ir.DoChildren(y, do)
+		case y.Op() == ir.OAPPEND:
+			y := y.(*ir.CallExpr)
+			if y.Args[0] != v {
+				return fail(n, "it is assigned a value that is not built from its fields")
+			}
+			if y.IsDDD {
+				return fail(n, "a slice is appended to it")
+			}
+			return doList(y.Args[1:], do)
+		case y.Op() == ir.OSLICE:
+			y := y.(*ir.SliceExpr)
+			if y.X != v {
+				return fail(n, "it is assigned a value that is not built from its fields")
+			}
+			if !withinLen(y.High, v) {
+				// Each field's slice has its own capacity, so
+				// the elements past the length of v may not be
+				// there in all of them.
+				return fail(n, "it may be resliced past its length")
+			}
+			return y.Low != nil && do(y.Low) || y.High != nil && do(y.High)
+		}
+		return fail(n, "it is assigned a value that is not built from its fields")
+	}
+	do = func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.ODCL:
+			if n.(*ir.Decl).X == v {
+				return false
+			}
+		case ir.OAS:
+			if n := n.(*ir.AssignStmt); n.X == v {
+				return doList(n.Init(), do) || assign(n)
+			}
+		case ir.ODOT:
+			if x, ok := elem(n.(*ir.SelectorExpr).X, v); ok {
+				return do(x.Index)
+			}
+		case ir.OINDEX:
+			if n.(*ir.IndexExpr).X == v {
+				return fail(n, "one of its elements is used as a whole")
+			}
+		case ir.OLEN:
+			if n.(*ir.UnaryExpr).X == v {
+				return false
+			}
+		case ir.OCAP:
+			if n.(*ir.UnaryExpr).X == v {
+				return fail(n, "its capacity is used")
+			}
+		case ir.ORANGE:
+			if n := n.(*ir.RangeStmt); n.X == v {
+				if n.Value != nil {
+					return fail(n, "its elements are ranged over")
+				}
+				return doList(n.Init(), do) || doList(n.Body, do)
+			}
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				if cv.Canonical() == v {
+					return fail(n, "it is captured by a closure")
+				}
+			}
+		}
+		return ir.DoChildren(n, func(x ir.Node) bool {
+			if x == v {
+				// v itself has the position of its declaration.
+				return fail(n, "it is used as a whole")
+			}
+			return do(x)
+		})
+	}
+	doList(fn.Body, do)
+	return pos, why
+}
+
+// withinLen reports whether high, the upper bound of a slice
+// expression on v, is known not to exceed len(v).
+func withinLen(high ir.Node, v *ir.Name) bool {
+	isLen := func(n ir.Node) bool {
+		return n.Op() == ir.OLEN && n.(*ir.UnaryExpr).X == v
+	}
+	switch {
+	case high == nil || isLen(high):
+		return true
+	case ir.IsConst(high, constant.Int):
+		return ir.Int64Val(high) == 0
+	case high.Op() == ir.OSUB:
+		high := high.(*ir.BinaryExpr)
+		return isLen(high.X) && ir.IsConst(high.Y, constant.Int) && ir.Int64Val(high.Y) >= 0
+	}
+	return false
+}
+
+// doList calls do on each node in list, stopping at the first for
+// which it returns true, like ir.DoChildren.
+func doList(list ir.Nodes, do func(ir.Node) bool) bool {
+	for _, n := range list {
+		if n != nil && do(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// elem reports whether n is an element of the slice v.
+func elem(n ir.Node, v *ir.Name) (*ir.IndexExpr, bool) {
+	if n.Op() != ir.OINDEX {
+		return nil, false
+	}
+	x := n.(*ir.IndexExpr)
+	return x, x.X == v
+}
+
+// fieldsOf returns the fields of the struct type t that can be used.
+// Blank fields cannot, so they need no slices.
+func fieldsOf(t *types.Type) []*types.Field {
+	var fields []*types.Field
+	for _, f := range t.Fields().Slice() {
+		if !f.Sym.IsBlank() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// A converter rewrites the uses of a slice of structs v into uses of
+// slices of its fields.
+type converter struct {
+	v      *ir.Name
+	fields []*types.Field
+	slices []*ir.Name // slices[i] holds fields[i] of each element
+}
+
+func newConverter(fn *ir.Func, v *ir.Name) *converter {
+	c := &converter{v: v, fields: fieldsOf(v.Type().Elem())}
+	for _, f := range c.fields {
+		sym := typecheck.Lookup(fmt.Sprintf("%v.%v", v.Sym().Name, f.Sym.Name))
+		s := ir.NewNameAt(v.Pos(), sym)
+		s.Class = ir.PAUTO
+		s.Curfn = fn
+		s.SetType(types.NewSlice(f.Type))
+		s.SetTypecheck(1)
+		s.SetUsed(true)
+		fn.Dcl = append(fn.Dcl, s)
+		c.slices = append(c.slices, s)
+	}
+	return c
+}
+
+// slice returns the slice holding field f of each element.
+func (c *converter) slice(f *types.Field) *ir.Name {
+	for i, g := range c.fields {
+		if g.Sym == f.Sym {
+			return c.slices[i]
+		}
+	}
+	base.Fatalf("soa: %v has no field %v", c.v.Type().Elem(), f.Sym)
+	return nil
+}
+
+func (c *converter) edit(n ir.Node) ir.Node {
+	switch n.Op() {
+	case ir.ODCL:
+		if n.(*ir.Decl).X == c.v {
+			var out ir.Nodes
+			for _, s := range c.slices {
+				out.Append(ir.NewDecl(n.Pos(), ir.ODCL, s))
+			}
+			return ir.NewBlockStmt(n.Pos(), out)
+		}
+	case ir.OAS:
+		if n := n.(*ir.AssignStmt); n.X == c.v {
+			return c.assign(n)
+		}
+	case ir.ODOT:
+		n := n.(*ir.SelectorExpr)
+		if x, ok := elem(n.X, c.v); ok {
+			index := c.edit(x.Index)
+			return typecheck.Expr(ir.NewIndexExpr(n.Pos(), c.slice(n.Selection), index))
+		}
+	case ir.OLEN:
+		if n := n.(*ir.UnaryExpr); n.X == c.v {
+			n.X = c.slices[0]
+			return n
+		}
+	case ir.ORANGE:
+		if n := n.(*ir.RangeStmt); n.X == c.v {
+			n.X = c.slices[0]
+		}
+	}
+	ir.EditChildren(n, c.edit)
+	return n
+}
+
+// assign rewrites the assignment n to c.v into assignments to each
+// of the slices that replace it.
+func (c *converter) assign(n *ir.AssignStmt) ir.Node {
+	pos := n.Pos()
+	var out ir.Nodes
+	out.Append(n.Init()...)
+	// eval evaluates x once, before the assignments.
+	eval := func(x ir.Node) ir.Node {
+		if x == nil {
+			return nil
+		}
+		x = c.edit(x)
+		if x.Op() == ir.ONAME || x.Op() == ir.OLITERAL {
+			return x
+		}
+		tmp := typecheck.Temp(x.Type())
+		out.Append(ir.NewDecl(pos, ir.ODCL, tmp))
+		out.Append(typecheck.Stmt(ir.NewAssignStmt(pos, tmp, x)))
+		return tmp
+	}
+	set := func(s *ir.Name, y ir.Node) {
+		out.Append(typecheck.Stmt(ir.NewAssignStmt(pos, s, y)))
+	}
+
+	switch y := n.Y; {
+	case y == nil || y.Op() == ir.ONIL:
+		for _, s := range c.slices {
+			set(s, nil)
+		}
+	case y.Op() == ir.OMAKESLICE:
+		y := y.(*ir.MakeExpr)
+		length, capacity := eval(y.Len), eval(y.Cap)
+		for _, s := range c.slices {
+			args := []ir.Node{ir.TypeNode(s.Type()), length}
+			if capacity != nil {
+				args = append(args, capacity)
+			}
+			set(s, typecheck.Expr(ir.NewCallExpr(pos, ir.OMAKE, nil, args)))
+		}
+	case y.Op() == ir.OAPPEND:
+		y := y.(*ir.CallExpr)
+		var elems []ir.Node
+		for _, x := range y.Args[1:] {
+			elems = append(elems, eval(x))
+		}
+		for i, s := range c.slices {
+			args := []ir.Node{s}
+			for _, x := range elems {
+				args = append(args, ir.NewSelectorExpr(pos, ir.OXDOT, x, c.fields[i].Sym))
+			}
+			set(s, typecheck.Expr(ir.NewCallExpr(pos, ir.OAPPEND, nil, args)))
+		}
+	case y.Op() == ir.OSLICE:
+		y := y.(*ir.SliceExpr)
+		low, high := eval(y.Low), eval(y.High)
+		for _, s := range c.slices {
+			set(s, typecheck.Expr(ir.NewSliceExpr(pos, ir.OSLICE, s, low, high, nil)))
+		}
+	default:
+		base.FatalfAt(pos, "soa: unexpected assignment %v", n)
+	}
+	return ir.NewBlockStmt(pos, out)
+}
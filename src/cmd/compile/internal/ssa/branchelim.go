@@ -35,7 +35,7 @@ func branchelim(f *Func) {
 	for _, b := range f.Blocks {
 		for _, v := range b.Values {
 			switch v.Op {
-			case OpLoad, OpAtomicLoad8, OpAtomicLoad32, OpAtomicLoad64, OpAtomicLoadPtr, OpAtomicLoadAcq32, OpAtomicLoadAcq64:
+			case OpLoad, OpAtomicLoad8, OpAtomicLoad32, OpAtomicLoad64, OpAtomicLoadPtr, OpAtomicLoadAcq32, OpAtomicLoadAcq64, OpAtomicLoadRelaxed32, OpAtomicLoadRelaxed64:
 				loadAddr.add(v.Args[0].ID)
 			case OpMove:
 				loadAddr.add(v.Args[1].ID)
@@ -67,7 +67,7 @@ func branchelim(f *Func) {
 	for change {
 		change = false
 		for _, b := range f.Blocks {
-			change = elimIf(f, loadAddr, b) || elimIfElse(f, loadAddr, b) || change
+			change = elimIf(f, loadAddr, b) || elimIfElse(f, loadAddr, b) || elimIfRet(f, b) || change
 		}
 	}
 }
@@ -100,6 +100,74 @@ func canCondSelect(v *Value, arch string, loadAddr *sparseSet) bool {
 	}
 }
 
+// minMaxSelect returns the Min or Max op and arguments that compute
+// cond ? a : b for floating-point a and b, or OpInvalid if the select
+// is not a min or max that can be computed without a branch.
+//
+// A comparison with < selects exactly what MINSD and MAXSD on amd64 do,
+// including for NaNs and signed zeros, so it is always rewritten. A
+// comparison with <= only matches when the function allows fast
+// min/max; see Func.FastMinMax.
+func minMaxSelect(f *Func, a, b, cond *Value) (Op, *Value, *Value) {
+	switch f.Config.arch {
+	case "amd64", "arm64":
+	default:
+		return OpInvalid, nil, nil
+	}
+	var min, max Op
+	switch cond.Op {
+	case OpLess64F, OpLeq64F:
+		min, max = OpMin64F, OpMax64F
+	case OpLess32F, OpLeq32F:
+		min, max = OpMin32F, OpMax32F
+	default:
+		return OpInvalid, nil, nil
+	}
+	if (cond.Op == OpLeq64F || cond.Op == OpLeq32F) && !f.FastMinMax {
+		return OpInvalid, nil, nil
+	}
+	p, q := cond.Args[0], cond.Args[1]
+	switch {
+	case a == p && b == q:
+		// p < q ? p : q
+		return min, p, q
+	case a == q && b == p:
+		// p < q ? q : p, which is q > p ? q : p
+		return max, q, p
+	}
+	return OpInvalid, nil, nil
+}
+
+// isMinMaxPhi reports whether the floating-point Phi v, controlled
+// by cond, can be rewritten by rewriteMinMax.
+func isMinMaxPhi(v *Value, swap bool, cond *Value) bool {
+	if !v.Type.IsFloat() || len(v.Args) != 2 {
+		return false
+	}
+	a, b := v.Args[0], v.Args[1]
+	if swap {
+		a, b = b, a
+	}
+	op, _, _ := minMaxSelect(v.Block.Func, a, b, cond)
+	return op != OpInvalid
+}
+
+// rewriteMinMax rewrites the floating-point CondSelect v
+// as a Min or Max op.
+func rewriteMinMax(v *Value) {
+	if !v.Type.IsFloat() {
+		return
+	}
+	f := v.Block.Func
+	op, x, y := minMaxSelect(f, v.Args[0], v.Args[1], v.Args[2])
+	v.reset(op)
+	v.AuxInt = boolToAuxInt(f.FastMinMax)
+	v.AddArg2(x, y)
+	if f.pass.debug > 0 {
+		f.Warnl(v.Pos, "rewrote select as %s", op)
+	}
+}
+
 // elimIf converts the one-way branch starting at dom in f to a conditional move if possible.
 // loadAddr is a set of values which are used to compute the address of a load.
 // Those values are exempt from CMOV generation.
@@ -129,11 +197,12 @@ func elimIf(f *Func, loadAddr *sparseSet, dom *Block) bool {
 
 	// Check that there are Phis, and that all of them
 	// can be safely rewritten to CondSelect.
+	swap := (post.Preds[0].Block() == dom) != (dom.Succs[0].Block() == post)
 	hasphis := false
 	for _, v := range post.Values {
 		if v.Op == OpPhi {
 			hasphis = true
-			if !canCondSelect(v, f.Config.arch, loadAddr) {
+			if !canCondSelect(v, f.Config.arch, loadAddr) && !isMinMaxPhi(v, swap, dom.Controls[0]) {
 				return false
 			}
 		}
@@ -153,7 +222,6 @@ func elimIf(f *Func, loadAddr *sparseSet, dom *Block) bool {
 	}
 
 	// Replace Phi instructions in b with CondSelect instructions
-	swap := (post.Preds[0].Block() == dom) != (dom.Succs[0].Block() == post)
 	for _, v := range post.Values {
 		if v.Op != OpPhi {
 			continue
@@ -163,6 +231,7 @@ func elimIf(f *Func, loadAddr *sparseSet, dom *Block) bool {
 			v.Args[0], v.Args[1] = v.Args[1], v.Args[0]
 		}
 		v.AddArg(dom.Controls[0])
+		rewriteMinMax(v)
 	}
 
 	// Put all of the instructions into 'dom'
@@ -319,11 +388,12 @@ func elimIfElse(f *Func, loadAddr *sparseSet, b *Block) bool {
 	if len(post.Preds) != 2 || post == b {
 		return false
 	}
+	swap := post.Preds[0].Block() != b.Succs[0].Block()
 	hasphis := false
 	for _, v := range post.Values {
 		if v.Op == OpPhi {
 			hasphis = true
-			if !canCondSelect(v, f.Config.arch, loadAddr) {
+			if !canCondSelect(v, f.Config.arch, loadAddr) && !isMinMaxPhi(v, swap, b.Controls[0]) {
 				return false
 			}
 		}
@@ -338,7 +408,6 @@ func elimIfElse(f *Func, loadAddr *sparseSet, b *Block) bool {
 	}
 
 	// now we're committed: rewrite each Phi as a CondSelect
-	swap := post.Preds[0].Block() != b.Succs[0].Block()
 	for _, v := range post.Values {
 		if v.Op != OpPhi {
 			continue
@@ -348,6 +417,7 @@ func elimIfElse(f *Func, loadAddr *sparseSet, b *Block) bool {
 			v.Args[0], v.Args[1] = v.Args[1], v.Args[0]
 		}
 		v.AddArg(b.Controls[0])
+		rewriteMinMax(v)
 	}
 
 	// Move the contents of all of these
@@ -382,6 +452,75 @@ func elimIfElse(f *Func, loadAddr *sparseSet, b *Block) bool {
 	return true
 }
 
+// elimIfRet converts a branch to two returns, whose results differ
+// only in returning the minimum or maximum of a pair of floats, into
+// a single return of Min or Max ops.
+func elimIfRet(f *Func, b *Block) bool {
+	if b.Kind != BlockIf || b.Likely != BranchUnknown {
+		return false
+	}
+	yes, no := b.Succs[0].Block(), b.Succs[1].Block()
+	if yes == no || !isLeafRet(yes) || !isLeafRet(no) {
+		return false
+	}
+	cond := b.Controls[0]
+	ry, rn := yes.Controls[0], no.Controls[0]
+	if len(ry.Args) != len(rn.Args) {
+		return false
+	}
+	differ := false
+	for i, a := range ry.Args {
+		if a == rn.Args[i] {
+			continue
+		}
+		if !a.Type.IsFloat() {
+			return false
+		}
+		if op, _, _ := minMaxSelect(f, a, rn.Args[i], cond); op == OpInvalid {
+			return false
+		}
+		differ = true
+	}
+	if !differ {
+		return false
+	}
+
+	// Compute the differing results in b and return from there.
+	for i, a := range ry.Args {
+		if a == rn.Args[i] {
+			continue
+		}
+		op, x, y := minMaxSelect(f, a, rn.Args[i], cond)
+		m := b.NewValue2(ry.Pos, op, a.Type, x, y)
+		m.AuxInt = boolToAuxInt(f.FastMinMax)
+		ry.SetArg(i, m)
+		if f.pass.debug > 0 {
+			f.Warnl(m.Pos, "rewrote select as %s", op)
+		}
+	}
+	rn.resetArgs()
+	ry.Block = b
+	b.Values = append(b.Values, ry)
+	yes.Values = nil
+	b.Kind = BlockRet
+	b.ResetControls()
+	b.AddControl(ry)
+	b.Succs = b.Succs[:0]
+
+	clobberBlock(yes)
+	clobberBlock(no)
+
+	f.invalidateCFG()
+	return true
+}
+
+// isLeafRet reports whether b is a BlockRet with one predecessor
+// and no values other than its result.
+func isLeafRet(b *Block) bool {
+	return b.Kind == BlockRet && len(b.Preds) == 1 && len(b.Values) == 1 &&
+		b.Values[0] == b.Controls[0] && b.Controls[0].Op == OpMakeResult
+}
+
 // shouldElimIfElse reports whether estimated cost of eliminating branch
 // is lower than threshold.
 func shouldElimIfElse(no, yes, post *Block, arch string) bool {
@@ -40,6 +40,11 @@ func Compile(f *Func) {
 		seed := int64(crc32.ChecksumIEEE(([]byte)(f.Name))) ^ int64(checkRandSeed)
 		rnd = rand.New(rand.NewSource(seed))
 	}
+	order := passIndexes[:]
+	if stressEnabled {
+		order = stressOrder(rnd)
+		f.stress = rnd
+	}
 
 	// hook to print function & phase if panic happens
 	phaseName := "init"
@@ -68,7 +73,8 @@ func Compile(f *Func) {
 		checkFunc(f)
 	}
 	const logMemStats = false
-	for _, p := range passes {
+	for _, i := range order {
+		p := passes[i]
 		if !f.Config.optimize && !p.required || p.disabled {
 			continue
 		}
@@ -231,6 +237,9 @@ var (
 	checkRandSeed = 0
 )
 
+// Perturb the order of passes and rewrites, using checkRandSeed
+var stressEnabled = false
+
 // Debug output
 var IntrinsicsDebug int
 var IntrinsicsDisable bool
@@ -264,7 +273,7 @@ func PhaseOption(phase, flag string, val int, valString string) string {
 	switch phase {
 	case "", "help":
 		lastcr := 0
-		phasenames := "    check, all, build, intrinsics, genssa"
+		phasenames := "    check, stress, all, build, intrinsics, genssa"
 		for _, p := range passes {
 			pn := strings.Replace(p.name, " ", "_", -1)
 			if len(pn)+len(phasenames)-lastcr > 70 {
@@ -308,6 +317,12 @@ enables checking after each phase
 enables checking after each phase, using 1234 to seed the PRNG
 used for value order randomization
 
+    -d=ssa/stress=1234
+enables checking after each phase, and runs the optional phases between
+two required ones, and rewrite rules, in an order randomized using 1234
+to seed the PRNG, or a random seed if none is given, which is reported
+with any internal compiler error in a phase.
+
     -d=ssa/all/time
 enables time reporting for all phases
 
@@ -344,6 +359,22 @@ commas. For example:
 		}
 	}
 
+	if phase == "stress" {
+		switch flag {
+		case "debug", "seed": // -d=ssa/stress[=seed]
+			stressEnabled = true
+			checkEnabled = true
+			debugPoset = true
+			checkRandSeed = val
+			if valString == "" {
+				checkRandSeed = int(time.Now().UnixNano() % 1e9)
+			}
+			return ""
+		default:
+			return fmt.Sprintf("Did not find a flag matching %s in -d=ssa/%s debug option (expected ssa/stress[=seed])", flag, phase)
+		}
+	}
+
 	alltime := false
 	allmem := false
 	alldump := false
@@ -463,11 +494,14 @@ var passes = [...]pass{
 	{name: "early copyelim", fn: copyelim},
 	{name: "early deadcode", fn: deadcode}, // remove generated dead code to avoid doing pointless work during opt
 	{name: "short circuit", fn: shortcircuit},
+	{name: "check keepalive", fn: checkKeepAlive, required: true},
 	{name: "decompose user", fn: decomposeUser, required: true},
 	{name: "pre-opt deadcode", fn: deadcode},
-	{name: "opt", fn: opt, required: true},               // NB: some generic rules know the name of the opt pass. TODO: split required rules and optimizing rules
-	{name: "zero arg cse", fn: zcse, required: true},     // required to merge OpSB values
-	{name: "opt deadcode", fn: deadcode, required: true}, // remove any blocks orphaned during opt
+	{name: "opt", fn: opt, required: true},                  // NB: some generic rules know the name of the opt pass. TODO: split required rules and optimizing rules
+	{name: "error checks", fn: errorChecks, required: true}, // report nil error checks removed by opt
+	{name: "zero arg cse", fn: zcse, required: true},        // required to merge OpSB values
+	{name: "opt deadcode", fn: deadcode, required: true},    // remove any blocks orphaned during opt
+	{name: "fastmath", fn: fastmath},
 	{name: "generic cse", fn: cse},
 	{name: "phiopt", fn: phiopt},
 	{name: "gcse deadcode", fn: deadcode, required: true}, // clean out after cse and phiopt
@@ -485,6 +519,7 @@ var passes = [...]pass{
 	{name: "late fuse", fn: fuseLate},
 	{name: "dse", fn: dse},
 	{name: "writebarrier", fn: writebarrier, required: true}, // expand write barrier ops
+	{name: "effects", fn: effects, required: true},           // summarize effects visible to callers
 	{name: "insert resched checks", fn: insertLoopReschedChecks,
 		disabled: !buildcfg.Experiment.PreemptibleLoops}, // insert resched checks in loops.
 	{name: "lower", fn: lower, required: true},
@@ -502,6 +537,7 @@ var passes = [...]pass{
 	{name: "critical", fn: critical, required: true}, // remove critical edges
 	{name: "phi tighten", fn: phiTighten},            // place rematerializable phi args near uses to reduce value lifetimes
 	{name: "likelyadjust", fn: likelyadjust},
+	{name: "pgo likely", fn: pgoLikely},
 	{name: "layout", fn: layout, required: true},     // schedule blocks
 	{name: "schedule", fn: schedule, required: true}, // schedule values
 	{name: "late nilcheck", fn: nilcheckelim2},
@@ -523,6 +559,9 @@ type constraint struct {
 var passOrder = [...]constraint{
 	// "insert resched checks" uses mem, better to clean out stores first.
 	{"dse", "insert resched checks"},
+	// effects needs generic ops and the final write barrier calls.
+	{"writebarrier", "effects"},
+	{"effects", "lower"},
 	// insert resched checks adds new blocks containing generic instructions
 	{"insert resched checks", "lower"},
 	{"insert resched checks", "tighten"},
@@ -542,6 +581,10 @@ var passOrder = [...]constraint{
 	{"nilcheckelim", "late fuse"},
 	// nilcheckelim relies on opt to rewrite user nil checks
 	{"opt", "nilcheckelim"},
+	// phielim turns phis into copies, which copyelim bypasses and
+	// leaves for deadcode to remove before register allocation.
+	{"late phielim", "late copyelim"},
+	{"late copyelim", "late deadcode"},
 	// tighten will be most effective when as many values have been removed as possible
 	{"generic deadcode", "tighten"},
 	{"generic cse", "tighten"},
@@ -555,6 +598,10 @@ var passOrder = [...]constraint{
 	{"tighten tuple selectors", "schedule"},
 	// remove critical edges before phi tighten, so that phi args get better placement
 	{"critical", "phi tighten"},
+	// profile weights override the static branch predictions, and are
+	// used by layout
+	{"likelyadjust", "pgo likely"},
+	{"pgo likely", "layout"},
 	// don't layout blocks until critical edges have been removed
 	{"critical", "layout"},
 	// regalloc requires the removal of all critical edges
@@ -578,7 +625,61 @@ var passOrder = [...]constraint{
 	{"regalloc", "trim"},
 }
 
+// passIndexes lists the indexes of passes in order.
+var passIndexes [len(passes)]int
+
+// stressOrder returns the indexes of passes in a random order for
+// -d=ssa/stress, in which each run of optional passes between two
+// required ones is shuffled as far as passOrder allows.
+func stressOrder(rnd *rand.Rand) []int {
+	order := make([]int, 0, len(passes))
+	for i := 0; i < len(passes); {
+		if passes[i].required {
+			order = append(order, i)
+			i++
+			continue
+		}
+		var left []int
+		for ; i < len(passes) && !passes[i].required; i++ {
+			left = append(left, i)
+		}
+		for len(left) > 0 {
+			// Pick one of the passes that need not come after
+			// another one left.
+			var ready []int
+			for x, k := range left {
+				if !mustFollow(k, left) {
+					ready = append(ready, x)
+				}
+			}
+			x := ready[rnd.Intn(len(ready))]
+			order = append(order, left[x])
+			left = append(left[:x], left[x+1:]...)
+		}
+	}
+	return order
+}
+
+// mustFollow reports whether passOrder requires passes[k] to come
+// after one of the passes whose indexes are in others.
+func mustFollow(k int, others []int) bool {
+	for _, c := range passOrder {
+		if c.b != passes[k].name {
+			continue
+		}
+		for _, o := range others {
+			if passes[o].name == c.a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func init() {
+	for i := range passIndexes {
+		passIndexes[i] = i
+	}
 	for _, c := range passOrder {
 		a, b := c.a, c.b
 		i := -1
@@ -0,0 +1,34 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStressOrder(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		order := stressOrder(rand.New(rand.NewSource(seed)))
+		if len(order) != len(passes) {
+			t.Fatalf("seed %d: got %d passes, want %d", seed, len(order), len(passes))
+		}
+		pos := make(map[string]int)
+		for i, k := range order {
+			if passes[k].required && k != i {
+				t.Errorf("seed %d: required pass %s moved from %d to %d", seed, passes[k].name, k, i)
+			}
+			pos[passes[k].name] = i
+		}
+		if len(pos) != len(passes) {
+			t.Errorf("seed %d: passes repeated in %v", seed, order)
+		}
+		for _, c := range passOrder {
+			if pos[c.a] >= pos[c.b] {
+				t.Errorf("seed %d: passes %s and %s out of order", seed, c.a, c.b)
+			}
+		}
+	}
+}
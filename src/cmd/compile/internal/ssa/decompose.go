@@ -317,11 +317,10 @@ func decomposeUserStructInto(f *Func, name *LocalSlot, slots []*LocalSlot) []*Lo
 		}
 	}
 
-	makeOp := StructMakeOp(n)
 	var keep []*Value
 	// create named values for each struct field
 	for _, v := range f.NamedValues[*name] {
-		if v.Op != makeOp {
+		if v.Op != OpStructMake {
 			keep = append(keep, v)
 			continue
 		}
@@ -363,20 +362,20 @@ func decomposeUserPhi(v *Value) {
 func decomposeStructPhi(v *Value) {
 	t := v.Type
 	n := t.NumFields()
-	var fields [MaxStruct]*Value
-	for i := 0; i < n; i++ {
+	fields := make([]*Value, n)
+	for i := range fields {
 		fields[i] = v.Block.NewValue0(v.Pos, OpPhi, t.FieldType(i))
 	}
 	for _, a := range v.Args {
-		for i := 0; i < n; i++ {
-			fields[i].AddArg(a.Block.NewValue1I(v.Pos, OpStructSelect, t.FieldType(i), int64(i), a))
+		for i, f := range fields {
+			f.AddArg(a.Block.NewValue1I(v.Pos, OpStructSelect, t.FieldType(i), int64(i), a))
 		}
 	}
-	v.reset(StructMakeOp(n))
-	v.AddArgs(fields[:n]...)
+	v.reset(OpStructMake)
+	v.AddArgs(fields...)
 
 	// Recursively decompose phis for each field.
-	for _, f := range fields[:n] {
+	for _, f := range fields {
 		decomposeUserPhi(f)
 	}
 }
@@ -403,28 +402,11 @@ func decomposeArrayPhi(v *Value) {
 	decomposeUserPhi(elem)
 }
 
-// MaxStruct is the maximum number of fields a struct
-// can have and still be SSAable.
+// MaxStruct is the maximum number of fields of nonzero size a struct
+// can have and still be SSAable. Fields of zero size take no registers,
+// so they don't count.
 const MaxStruct = 4
 
-// StructMakeOp returns the opcode to construct a struct with the
-// given number of fields.
-func StructMakeOp(nf int) Op {
-	switch nf {
-	case 0:
-		return OpStructMake0
-	case 1:
-		return OpStructMake1
-	case 2:
-		return OpStructMake2
-	case 3:
-		return OpStructMake3
-	case 4:
-		return OpStructMake4
-	}
-	panic("too many fields in an SSAable struct")
-}
-
 type namedVal struct {
 	locIndex, valIndex int // f.NamedValues[f.Names[locIndex]][valIndex] = key
 }
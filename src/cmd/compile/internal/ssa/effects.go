@@ -0,0 +1,224 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import (
+	"strings"
+
+	"cmd/compile/internal/ir"
+	"cmd/internal/obj"
+)
+
+// effects computes f.Effects, the effects of f that its callers can
+// observe, from its optimized code. Calls to functions outside the
+// runtime are left to the frontend, which knows their summaries.
+//
+// Memory accessed through the address of a local variable, or of an
+// object f just allocated, is f's own; any other load or store is
+// counted as reading or writing memory outside f's frame. Any loop
+// counts as blocking, since it may not terminate.
+func effects(f *Func) {
+	var e ir.FuncEffects
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			switch {
+			case v.Op == OpLoad:
+				if !ownMemory(v.Args[0]) {
+					e |= ir.EffectReads
+				}
+			case v.Op == OpStore || v.Op == OpZero:
+				if !ownMemory(v.Args[0]) {
+					e |= ir.EffectWrites
+				}
+			case v.Op == OpMove:
+				if !ownMemory(v.Args[0]) {
+					e |= ir.EffectWrites
+				}
+				if !ownMemory(v.Args[1]) {
+					e |= ir.EffectReads
+				}
+			case v.Op == OpWB:
+				e |= ir.EffectWrites
+			case v.Op == OpNilCheck || v.Op == OpPanicBounds || v.Op == OpPanicExtend:
+				e |= ir.EffectPanics
+			case v.Op == OpStaticCall || v.Op == OpTailCall:
+				if rt, ok := RuntimeEffects(v.Aux.(*AuxCall).Fn); ok {
+					e |= rt
+				}
+			case strings.HasPrefix(v.Op.String(), "AtomicLoad"):
+				if !ownMemory(v.Args[0]) {
+					e |= ir.EffectReads
+				}
+			case strings.HasPrefix(v.Op.String(), "Atomic"):
+				if !ownMemory(v.Args[0]) {
+					e |= ir.EffectReads | ir.EffectWrites
+				}
+			}
+		}
+	}
+
+	// In a postorder, only the edges that close a loop lead to a
+	// block that is not numbered below their source.
+	po := f.postorder()
+	num := make([]int, f.NumBlocks())
+	for i, b := range po {
+		num[b.ID] = i
+	}
+	for _, b := range po {
+		for _, s := range b.Succs {
+			if num[s.b.ID] >= num[b.ID] {
+				e |= ir.EffectBlocks
+			}
+		}
+	}
+
+	f.Effects = e
+}
+
+// ownMemory reports whether ptr points into the frame of the function
+// being compiled, or into an object it allocated.
+func ownMemory(ptr *Value) bool {
+	for {
+		switch ptr.Op {
+		case OpOffPtr, OpAddPtr, OpPtrIndex, OpCopy:
+			ptr = ptr.Args[0]
+			continue
+		case OpLocalAddr, OpSP:
+			return true
+		case OpSelectN:
+			call := ptr.Args[0]
+			if call.Op != OpStaticCall {
+				return false
+			}
+			rt, ok := RuntimeEffects(call.Aux.(*AuxCall).Fn)
+			return ok && rt&^ir.EffectPanics == ir.EffectAllocates
+		}
+		return false
+	}
+}
+
+// RuntimeEffects returns the effects of calls to fn, if it is a
+// runtime function.
+func RuntimeEffects(fn *obj.LSym) (ir.FuncEffects, bool) {
+	if fn == nil || !strings.HasPrefix(fn.Name, "runtime.") {
+		return 0, false
+	}
+	name := fn.Name[len("runtime."):]
+	if e, ok := runtimeEffects[name]; ok {
+		return e, true
+	}
+	if strings.HasPrefix(name, "panic") || strings.HasPrefix(name, "goPanic") {
+		return ir.EffectPanics, true
+	}
+	return ir.EffectsAll, true
+}
+
+const (
+	rtAlloc = ir.EffectAllocates
+	rtRead  = ir.EffectReads
+	rtWrite = ir.EffectReads | ir.EffectWrites
+	rtPanic = ir.EffectPanics
+	rtBlock = ir.EffectBlocks
+)
+
+// runtimeEffects gives the effects of the runtime functions that
+// compiled code calls. Other runtime functions are assumed to have
+// every effect, except for those that only panic.
+var runtimeEffects = map[string]ir.FuncEffects{
+	"newobject":     rtAlloc,
+	"mallocgc":      rtAlloc,
+	"makeslice":     rtAlloc | rtPanic,
+	"makeslice64":   rtAlloc | rtPanic,
+	"makeslicecopy": rtAlloc | rtRead | rtPanic,
+	"growslice":     rtAlloc | rtRead | rtPanic,
+	"makemap":       rtAlloc | rtPanic,
+	"makemap64":     rtAlloc | rtPanic,
+	"makemap_small": rtAlloc,
+	"makechan":      rtAlloc | rtPanic,
+	"makechan64":    rtAlloc | rtPanic,
+
+	"mapaccess1":          rtRead | rtPanic,
+	"mapaccess1_fast32":   rtRead,
+	"mapaccess1_fast64":   rtRead,
+	"mapaccess1_faststr":  rtRead,
+	"mapaccess1_fat":      rtRead | rtPanic,
+	"mapaccess2":          rtRead | rtPanic,
+	"mapaccess2_fast32":   rtRead,
+	"mapaccess2_fast64":   rtRead,
+	"mapaccess2_faststr":  rtRead,
+	"mapaccess2_fat":      rtRead | rtPanic,
+	"mapassign":           rtAlloc | rtWrite | rtPanic,
+	"mapassign_fast32":    rtAlloc | rtWrite | rtPanic,
+	"mapassign_fast32ptr": rtAlloc | rtWrite | rtPanic,
+	"mapassign_fast64":    rtAlloc | rtWrite | rtPanic,
+	"mapassign_fast64ptr": rtAlloc | rtWrite | rtPanic,
+	"mapassign_faststr":   rtAlloc | rtWrite | rtPanic,
+	"mapiterinit":         rtRead,
+	"mapiternext":         rtRead,
+
+	"concatstring2":     rtAlloc | rtRead,
+	"concatstring3":     rtAlloc | rtRead,
+	"concatstring4":     rtAlloc | rtRead,
+	"concatstring5":     rtAlloc | rtRead,
+	"concatstrings":     rtAlloc | rtRead,
+	"slicebytetostring": rtAlloc | rtRead,
+	"slicerunetostring": rtAlloc | rtRead,
+	"stringtoslicebyte": rtAlloc | rtRead,
+	"stringtoslicerune": rtAlloc | rtRead,
+	"intstring":         rtAlloc,
+	"cmpstring":         rtRead,
+	"countrunes":        rtRead,
+	"decoderune":        rtRead,
+
+	"convT":       rtAlloc | rtRead,
+	"convTnoptr":  rtAlloc | rtRead,
+	"convT16":     rtAlloc,
+	"convT32":     rtAlloc,
+	"convT64":     rtAlloc,
+	"convTstring": rtAlloc,
+	"convTslice":  rtAlloc,
+	"assertE2I":   rtRead | rtPanic,
+	"assertE2I2":  rtRead,
+
+	"memequal":      rtRead,
+	"memequal0":     rtRead,
+	"memequal8":     rtRead,
+	"memequal16":    rtRead,
+	"memequal32":    rtRead,
+	"memequal64":    rtRead,
+	"memequal128":   rtRead,
+	"f32equal":      rtRead,
+	"f64equal":      rtRead,
+	"c64equal":      rtRead,
+	"c128equal":     rtRead,
+	"strequal":      rtRead,
+	"interequal":    rtRead | rtPanic,
+	"nilinterequal": rtRead | rtPanic,
+	"efaceeq":       rtRead | rtPanic,
+	"ifaceeq":       rtRead | rtPanic,
+
+	"memmove":              rtWrite,
+	"typedmemmove":         rtWrite,
+	"typedslicecopy":       rtWrite,
+	"memclrNoHeapPointers": rtWrite,
+	"memclrHasPointers":    rtWrite,
+	"typedmemclr":          rtWrite,
+
+	"chansend1":    rtWrite | rtBlock | rtPanic,
+	"chanrecv1":    rtWrite | rtBlock | rtPanic,
+	"chanrecv2":    rtWrite | rtBlock | rtPanic,
+	"selectgo":     rtWrite | rtBlock | rtPanic,
+	"selectnbsend": rtWrite | rtPanic,
+	"selectnbrecv": rtWrite | rtPanic,
+	"closechan":    rtWrite | rtPanic,
+	"block":        rtBlock,
+	"fastrand":     rtWrite,
+
+	"gopanic":        rtRead | rtPanic,
+	"gorecover":      rtWrite,
+	"newproc":        rtAlloc | rtWrite,
+	"deferproc":      rtAlloc | rtWrite,
+	"deferprocStack": rtWrite,
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import "cmd/internal/src"
+
+// An ErrorCheck is a branch on the comparison of an error with nil.
+type ErrorCheck struct {
+	Block *Block   // the BlockIf
+	Yes   *Block   // successor taken when Cond is true
+	Pos   src.XPos // position of Cond
+	Cond  string   // the comparison, for reporting
+}
+
+// errorChecks reports the branches in f.ErrorChecks that opt has
+// turned into unconditional jumps. That happens when inlining
+// exposes the error a callee returns, typically a nil error on its
+// success path.
+func errorChecks(f *Func) {
+	if len(f.ErrorChecks) == 0 {
+		return
+	}
+	reachable := make(map[*Block]bool, len(f.Blocks))
+	for _, b := range f.Blocks {
+		reachable[b] = true
+	}
+	for _, c := range f.ErrorChecks {
+		b := c.Block
+		if !reachable[b] || b.Kind != BlockFirst {
+			continue
+		}
+		f.Warnl(c.Pos, "%s is always %v; branch eliminated", c.Cond, b.Succs[0].b == c.Yes)
+	}
+	f.ErrorChecks = nil
+}
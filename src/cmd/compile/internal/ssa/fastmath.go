@@ -0,0 +1,161 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import "math"
+
+// fastmath applies floating-point rewrites that do not preserve
+// IEEE 754 results to functions marked //go:fastmath. It reassociates
+// sums and products, replaces division by a constant with
+// multiplication by its reciprocal, and ignores the sign of zero.
+// Code inlined into the function from elsewhere is left alone.
+//
+// Each rewrite is reported with -d=ssa/fastmath/debug=1.
+func fastmath(f *Func) {
+	if !f.FastMath {
+		return
+	}
+	pt := f.Config.ctxt.PosTable
+	for changed := true; changed; {
+		changed = false
+		for _, b := range f.Blocks {
+			for _, v := range b.Values {
+				if v.Uses == 0 || pt.Pos(v.Pos).Base().InliningIndex() >= 0 {
+					continue
+				}
+				what := fastmathValue(v)
+				if what == "" {
+					continue
+				}
+				changed = true
+				if f.pass.debug > 0 {
+					f.Warnl(v.Pos, "fastmath: %s", what)
+				}
+			}
+		}
+	}
+}
+
+// floatOps are the generic ops for one floating-point width.
+type floatOps struct {
+	add, sub, mul, div, neg, cnst Op
+}
+
+var (
+	float32Ops = floatOps{OpAdd32F, OpSub32F, OpMul32F, OpDiv32F, OpNeg32F, OpConst32F}
+	float64Ops = floatOps{OpAdd64F, OpSub64F, OpMul64F, OpDiv64F, OpNeg64F, OpConst64F}
+)
+
+// fastmathValue applies one rewrite to v, if any applies,
+// and returns a description of it.
+func fastmathValue(v *Value) string {
+	var ops floatOps
+	switch v.Op {
+	case OpAdd32F, OpSub32F, OpMul32F, OpDiv32F, OpNeg32F:
+		ops = float32Ops
+	case OpAdd64F, OpSub64F, OpMul64F, OpDiv64F, OpNeg64F:
+		ops = float64Ops
+	default:
+		return ""
+	}
+	f := v.Block.Func
+	konst := func(c float64) *Value {
+		if ops.cnst == OpConst32F {
+			return f.ConstFloat32(v.Type, float64(float32(c)))
+		}
+		return f.ConstFloat64(v.Type, c)
+	}
+	isConst := func(x *Value) (float64, bool) {
+		if x.Op != ops.cnst {
+			return 0, false
+		}
+		return x.AuxFloat(), true
+	}
+	finite := func(c float64) bool {
+		if ops.cnst == OpConst32F {
+			c = float64(float32(c))
+		}
+		return !math.IsInf(c, 0) && !math.IsNaN(c)
+	}
+
+	switch v.Op {
+	case ops.add:
+		for i := 0; i < 2; i++ {
+			x, y := v.Args[i], v.Args[i^1]
+			// x + 0 => x
+			if c, ok := isConst(y); ok && c == 0 {
+				v.copyOf(x)
+				return "dropped addition of zero"
+			}
+			// (x + c1) + c2 => x + (c1 + c2)
+			if c2, ok := isConst(y); ok && x.Op == ops.add {
+				for j := 0; j < 2; j++ {
+					if c1, ok := isConst(x.Args[j^1]); ok && finite(c1+c2) {
+						v.SetArg(i, x.Args[j])
+						v.SetArg(i^1, konst(c1+c2))
+						return "reassociated constant addition"
+					}
+				}
+			}
+		}
+		// a*b + a*c => a * (b + c)
+		x, y := v.Args[0], v.Args[1]
+		if x.Op == ops.mul && y.Op == ops.mul && x.Uses == 1 && y.Uses == 1 {
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					if x.Args[i] != y.Args[j] {
+						continue
+					}
+					a, b, c := x.Args[i], x.Args[i^1], y.Args[j^1]
+					sum := v.Block.NewValue2(v.Pos, ops.add, v.Type, b, c)
+					v.reset(ops.mul)
+					v.AddArg2(a, sum)
+					return "factored common multiplicand out of sum"
+				}
+			}
+		}
+	case ops.sub:
+		// 0 - x => -x
+		if c, ok := isConst(v.Args[0]); ok && c == 0 {
+			x := v.Args[1]
+			v.reset(ops.neg)
+			v.AddArg(x)
+			return "replaced subtraction from zero with negation"
+		}
+	case ops.neg:
+		// -(a - b) => b - a
+		if x := v.Args[0]; x.Op == ops.sub && x.Uses == 1 {
+			a, b := x.Args[0], x.Args[1]
+			v.reset(ops.sub)
+			v.AddArg2(b, a)
+			return "replaced negated difference with reversed difference"
+		}
+	case ops.mul:
+		// (x * c1) * c2 => x * (c1 * c2)
+		for i := 0; i < 2; i++ {
+			x, y := v.Args[i], v.Args[i^1]
+			c2, ok := isConst(y)
+			if !ok || x.Op != ops.mul {
+				continue
+			}
+			for j := 0; j < 2; j++ {
+				if c1, ok := isConst(x.Args[j^1]); ok && finite(c1*c2) {
+					v.SetArg(i, x.Args[j])
+					v.SetArg(i^1, konst(c1*c2))
+					return "reassociated constant multiplication"
+				}
+			}
+		}
+	case ops.div:
+		// x / c => x * (1/c)
+		if c, ok := isConst(v.Args[1]); ok && c != 0 && finite(c) && finite(1/c) {
+			x := v.Args[0]
+			v.reset(ops.mul)
+			v.AddArg2(x, konst(1/c))
+			return "replaced division by constant with multiplication by reciprocal"
+		}
+	}
+	return ""
+}
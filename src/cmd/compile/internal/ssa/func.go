@@ -7,12 +7,15 @@ package ssa
 import (
 	"cmd/compile/internal/abi"
 	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
 	"cmd/compile/internal/types"
 	"cmd/internal/src"
 	"crypto/sha1"
 	"fmt"
+	"internal/buildcfg"
 	"io"
 	"math"
+	"math/rand"
 	"os"
 	"strings"
 )
@@ -50,10 +53,25 @@ type Func struct {
 	ABISelf        *abi.ABIConfig // ABI for function being compiled
 	ABIDefault     *abi.ABIConfig // ABI for rtcall and other no-parsed-signature/pragma functions.
 
-	scheduled   bool  // Values in Blocks are in final order
-	laidout     bool  // Blocks are ordered
-	NoSplit     bool  // true if function is marked as nosplit.  Used by schedule check pass.
-	dumpFileSeq uint8 // the sequence numbers of dump file. (%s_%02d__%s.dump", funcname, dumpFileSeq, phaseName)
+	scheduled      bool  // Values in Blocks are in final order
+	laidout        bool  // Blocks are ordered
+	NoSplit        bool  // true if function is marked as nosplit.  Used by schedule check pass.
+	FastMinMax     bool  // true if float min/max may ignore NaN and signed zero ordering. Used by branchelim.
+	FastMath       bool  // true if function is marked as fastmath. Used by fastmath pass.
+	KeepAliveCheck bool  // true if -d=keepalive is set. Used by check keepalive pass.
+	GOAMD64        int   // amd64 microarchitecture level to compile for. Above buildcfg.GOAMD64 only in go:cpu variants.
+	dumpFileSeq    uint8 // the sequence numbers of dump file. (%s_%02d__%s.dump", funcname, dumpFileSeq, phaseName)
+
+	// nil error checks to report on, for -d=errchecks
+	ErrorChecks []ErrorCheck
+
+	// LineWeight, if not nil, returns the weight of the profile samples
+	// attributed to the source line of a position. Used by pgo likely.
+	LineWeight func(src.XPos) int64
+
+	// stress, if not nil, shuffles the order in which rewrite rules are
+	// applied to values, for -d=ssa/stress.
+	stress *rand.Rand
 
 	// when register allocation is done, maps value ids to locations
 	RegAlloc []Location
@@ -73,6 +91,11 @@ type Func struct {
 	// AuxCall describing parameters and results for this function.
 	OwnAux *AuxCall
 
+	// Effects summarizes the effects of the function its callers
+	// can observe, except those of the calls it makes to functions
+	// outside the runtime. Set by the effects pass.
+	Effects ir.FuncEffects
+
 	// WBLoads is a list of Blocks that branch on the write
 	// barrier flag. Safe-points are disabled from the OpLoad that
 	// reads the write-barrier flag until the control flow rejoins
@@ -101,7 +124,7 @@ type LocalSlotSplitKey struct {
 // NewFunc returns a new, empty function object.
 // Caller must set f.Config and f.Cache before using f.
 func NewFunc(fe Frontend) *Func {
-	return &Func{fe: fe, NamedValues: make(map[LocalSlot][]*Value), CanonicalLocalSlots: make(map[LocalSlot]*LocalSlot), CanonicalLocalSplits: make(map[LocalSlotSplitKey]*LocalSlot)}
+	return &Func{fe: fe, GOAMD64: buildcfg.GOAMD64, NamedValues: make(map[LocalSlot][]*Value), CanonicalLocalSlots: make(map[LocalSlot]*LocalSlot), CanonicalLocalSplits: make(map[LocalSlotSplitKey]*LocalSlot)}
 }
 
 // NumBlocks returns an integer larger than the id of any Block in the Func.
@@ -762,6 +785,9 @@ func (f *Func) Log() bool                                           { return f.f
 
 func (f *Func) Fatalf(msg string, args ...interface{}) {
 	stats := "crashed"
+	if f.pass != nil {
+		base.SetCrashPass("ssa/" + f.pass.name)
+	}
 	if f.Log() {
 		f.Logf("  pass %s end %s\n", f.pass.name, stats)
 		printFunc(f)
@@ -770,6 +796,9 @@ func (f *Func) Fatalf(msg string, args ...interface{}) {
 		f.HTMLWriter.WritePhase(f.pass.name, fmt.Sprintf("%s <span class=\"stats\">%s</span>", f.pass.name, stats))
 		f.HTMLWriter.flushPhases()
 	}
+	if stressEnabled {
+		msg += fmt.Sprintf("\n(reproduce with -d=ssa/stress=%d)", checkRandSeed)
+	}
 	f.fe.Fatalf(f.Entry.Pos, msg, args...)
 }
 
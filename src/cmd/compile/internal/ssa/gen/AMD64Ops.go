@@ -183,6 +183,10 @@ func init() {
 		{name: "MULSD", argLength: 2, reg: fp21, asm: "MULSD", commutative: true, resultInArg0: true}, // fp64 mul
 		{name: "DIVSS", argLength: 2, reg: fp21, asm: "DIVSS", resultInArg0: true},                    // fp32 div
 		{name: "DIVSD", argLength: 2, reg: fp21, asm: "DIVSD", resultInArg0: true},                    // fp64 div
+		{name: "MINSS", argLength: 2, reg: fp21, asm: "MINSS", resultInArg0: true},                    // fp32 arg0 < arg1 ? arg0 : arg1
+		{name: "MINSD", argLength: 2, reg: fp21, asm: "MINSD", resultInArg0: true},                    // fp64 arg0 < arg1 ? arg0 : arg1
+		{name: "MAXSS", argLength: 2, reg: fp21, asm: "MAXSS", resultInArg0: true},                    // fp32 arg0 > arg1 ? arg0 : arg1
+		{name: "MAXSD", argLength: 2, reg: fp21, asm: "MAXSD", resultInArg0: true},                    // fp64 arg0 > arg1 ? arg0 : arg1
 
 		{name: "MOVSSload", argLength: 2, reg: fpload, asm: "MOVSS", aux: "SymOff", faultOnNilArg0: true, symEffect: "Read"}, // fp32 load
 		{name: "MOVSDload", argLength: 2, reg: fpload, asm: "MOVSD", aux: "SymOff", faultOnNilArg0: true, symEffect: "Read"}, // fp64 load
@@ -169,6 +169,7 @@ func init() {
 		fp21           = regInfo{inputs: []regMask{fp, fp}, outputs: []regMask{fp}}
 		fp31           = regInfo{inputs: []regMask{fp, fp, fp}, outputs: []regMask{fp}}
 		fp2flags       = regInfo{inputs: []regMask{fp, fp}}
+		fp2flags1      = regInfo{inputs: []regMask{fp, fp}, outputs: []regMask{fp}}
 		fp1flags       = regInfo{inputs: []regMask{fp}}
 		fpload         = regInfo{inputs: []regMask{gpspsbg}, outputs: []regMask{fp}}
 		fp2load        = regInfo{inputs: []regMask{gpspsbg, gpg}, outputs: []regMask{fp}}
@@ -216,6 +217,10 @@ func init() {
 		{name: "FNMULD", argLength: 2, reg: fp21, asm: "FNMULD", commutative: true}, // -(arg0 * arg1)
 		{name: "FDIVS", argLength: 2, reg: fp21, asm: "FDIVS"},                      // arg0 / arg1
 		{name: "FDIVD", argLength: 2, reg: fp21, asm: "FDIVD"},                      // arg0 / arg1
+		{name: "FMINS", argLength: 2, reg: fp21, asm: "FMINS"},                      // min(arg0, arg1), NaN if either is NaN, -0 < +0
+		{name: "FMIND", argLength: 2, reg: fp21, asm: "FMIND"},                      // min(arg0, arg1), NaN if either is NaN, -0 < +0
+		{name: "FMAXS", argLength: 2, reg: fp21, asm: "FMAXS"},                      // max(arg0, arg1), NaN if either is NaN, -0 < +0
+		{name: "FMAXD", argLength: 2, reg: fp21, asm: "FMAXD"},                      // max(arg0, arg1), NaN if either is NaN, -0 < +0
 
 		{name: "AND", argLength: 2, reg: gp21, asm: "AND", commutative: true}, // arg0 & arg1
 		{name: "ANDconst", argLength: 1, reg: gp11, asm: "AND", aux: "Int64"}, // arg0 & auxInt
@@ -490,6 +495,10 @@ func init() {
 		{name: "CSNEG", argLength: 3, reg: gp2flags1, asm: "CSNEG", aux: "CCop"}, // auxint(flags) ? arg0 : -arg1
 		{name: "CSETM", argLength: 1, reg: readflags, asm: "CSETM", aux: "CCop"}, // auxint(flags) ? -1 : 0
 
+		// floating point conditional select; auxint as above
+		{name: "FCSELS", argLength: 3, reg: fp2flags1, asm: "FCSELS", aux: "CCop"}, // auxint(flags) ? arg0 : arg1, float32
+		{name: "FCSELD", argLength: 3, reg: fp2flags1, asm: "FCSELD", aux: "CCop"}, // auxint(flags) ? arg0 : arg1, float64
+
 		// function calls
 		{name: "CALLstatic", argLength: -1, reg: regInfo{clobbers: callerSave}, aux: "CallOff", clobberFlags: true, call: true},                                               // call static function aux.(*obj.LSym).  last arg=mem, auxint=argsize, returns mem
 		{name: "CALLtail", argLength: -1, reg: regInfo{clobbers: callerSave}, aux: "CallOff", clobberFlags: true, call: true, tailCall: true},                                 // tail call static function aux.(*obj.LSym).  last arg=mem, auxint=argsize, returns mem
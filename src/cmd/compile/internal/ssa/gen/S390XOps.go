@@ -624,6 +624,18 @@ func init() {
 			asm:       "MLGR",
 		},
 
+		// unsigned division (128/64 → 64)
+		//
+		// Divide the 128-bit dividend in the even-odd register pair R2:R3 by the
+		// 64-bit divisor, leaving the quotient in R3 and the remainder in R2.
+		// The caller must ensure the quotient fits in 64 bits.
+		{
+			name:      "DLGR",
+			argLength: 3,
+			reg:       regInfo{inputs: []regMask{r2, r3, gp &^ (r2 | r3)}, outputs: []regMask{r3, r2}},
+			asm:       "DLGR",
+		},
+
 		// pseudo operations to sum the output of the POPCNT instruction
 		{name: "SumBytes2", argLength: 1, typ: "UInt8"}, // sum the rightmost 2 bytes in arg0 ignoring overflow
 		{name: "SumBytes4", argLength: 1, typ: "UInt8"}, // sum the rightmost 4 bytes in arg0 ignoring overflow
@@ -59,6 +59,9 @@ var genericOps = []opData{
 	{name: "Mul32uover", argLength: 2, typ: "(UInt32,Bool)", commutative: true}, // Let x = arg0*arg1 (full 32x32-> 64 unsigned multiply), returns (uint32(x), (uint32(x) != x))
 	{name: "Mul64uover", argLength: 2, typ: "(UInt64,Bool)", commutative: true}, // Let x = arg0*arg1 (full 64x64->128 unsigned multiply), returns (uint64(x), (uint64(x) != x))
 
+	{name: "Add64over", argLength: 2, typ: "(Int64,Bool)", commutative: true}, // arg0 + arg1, returns (sum, whether the signed addition overflowed)
+	{name: "Sub64over", argLength: 2, typ: "(Int64,Bool)"},                    // arg0 - arg1, returns (difference, whether the signed subtraction overflowed)
+
 	// Weird special instructions for use in the strength reduction of divides.
 	// These ops compute unsigned (arg0 + arg1) / 2, correct to all
 	// 32/64 bits, even when the intermediate result of the add has 33/65 bits.
@@ -296,6 +299,17 @@ var genericOps = []opData{
 	// See section 7.2 in ieee754.
 	{name: "FMA", argLength: 3}, // compute (a*b)+c without intermediate rounding
 
+	// Floating point min and max, produced by branchelim from
+	// compare-and-select code. Min computes arg0 < arg1 ? arg0 : arg1
+	// and Max computes arg0 > arg1 ? arg0 : arg1. If auxint is true,
+	// the function was compiled with fast min/max and the result is
+	// unspecified (but is one of the arguments) when the arguments
+	// are unordered or are zeros of different sign.
+	{name: "Min32F", argLength: 2, aux: "Bool"},
+	{name: "Min64F", argLength: 2, aux: "Bool"},
+	{name: "Max32F", argLength: 2, aux: "Bool"},
+	{name: "Max64F", argLength: 2, aux: "Bool"},
+
 	// Data movement. Max argument length for Phi is indefinite.
 	{name: "Phi", argLength: -1, zeroWidth: true}, // select an argument based on which predecessor block we came from
 	{name: "Copy", argLength: 1},                  // output = arg0
@@ -502,11 +516,7 @@ var genericOps = []opData{
 	{name: "IData", argLength: 1},                // arg0=interface, returns data field
 
 	// Structs
-	{name: "StructMake0"},                              // Returns struct with 0 fields.
-	{name: "StructMake1", argLength: 1},                // arg0=field0.  Returns struct.
-	{name: "StructMake2", argLength: 2},                // arg0,arg1=field0,field1.  Returns struct.
-	{name: "StructMake3", argLength: 3},                // arg0..2=field0..2.  Returns struct.
-	{name: "StructMake4", argLength: 4},                // arg0..3=field0..3.  Returns struct.
+	{name: "StructMake", argLength: -1},                // args=field0..fieldN-1, one for each field.  Returns struct.
 	{name: "StructSelect", argLength: 1, aux: "Int64"}, // arg0=struct, auxint=field index.  Returns the auxint'th field.
 
 	// Arrays
@@ -602,6 +612,15 @@ var genericOps = []opData{
 	{name: "AtomicOr8", argLength: 3, typ: "Mem", hasSideEffects: true},                        // *arg0 |= arg1.  arg2=memory.  Returns memory.
 	{name: "AtomicOr32", argLength: 3, typ: "Mem", hasSideEffects: true},                       // *arg0 |= arg1.  arg2=memory.  Returns memory.
 
+	// Relaxed atomic loads and stores, only used by the runtime and sync.
+	// They are atomic with respect to the word they access, but don't
+	// order other memory accesses. Relaxed stores have no side effects
+	// beyond their memory, so one overwritten by the next can be dropped.
+	{name: "AtomicLoadRelaxed32", argLength: 2, typ: "(UInt32,Mem)"}, // Load from arg0.  arg1=memory.  Returns loaded value and new memory.
+	{name: "AtomicLoadRelaxed64", argLength: 2, typ: "(UInt64,Mem)"}, // Load from arg0.  arg1=memory.  Returns loaded value and new memory.
+	{name: "AtomicStoreRelaxed32", argLength: 3, typ: "Mem"},         // Store arg1 to *arg0.  arg2=memory.  Returns memory.
+	{name: "AtomicStoreRelaxed64", argLength: 3, typ: "Mem"},         // Store arg1 to *arg0.  arg2=memory.  Returns memory.
+
 	// Atomic operation variants
 	// These variants have the same semantics as above atomic operations.
 	// But they are used for generating more efficient code on certain modern machines, with run-time CPU feature detection.
@@ -0,0 +1,461 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"cmd/compile/internal/types"
+)
+
+// This file contains an interpreter for functions made of generic
+// values, so that passes and rewrite rules can be tested by running a
+// function before and after them and comparing what it computes.
+//
+// Values are held as int64s, sign-extended from their size like the
+// AuxInt of constants, with booleans as 0 or 1. Pointers are plain
+// addresses into a memory that maps addresses to the values stored
+// there; loads and stores of any size access one memory cell.
+
+// An interpMem is the state of memory, indexed by address.
+type interpMem map[int64]int64
+
+// maxInterpBlocks bounds the number of blocks run by interpret, so that
+// miscompiled loops do not run forever.
+const maxInterpBlocks = 1 << 20
+
+// interpret runs f with its OpArg values, in order of their IDs, set to
+// args, and returns the memory of its exit block.
+func interpret(f *Func, args ...int64) (interpMem, error) {
+	var fargs []*Value
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Op == OpArg {
+				fargs = append(fargs, v)
+			}
+		}
+	}
+	sort.Slice(fargs, func(i, j int) bool { return fargs[i].ID < fargs[j].ID })
+	if len(fargs) != len(args) {
+		return nil, fmt.Errorf("%s has %d arguments, got %d", f.Name, len(fargs), len(args))
+	}
+
+	in := &interp{
+		vals: make(map[*Value]int64),
+		mems: make(map[*Value]interpMem),
+	}
+	for i, v := range fargs {
+		in.vals[v] = in.ext(v.Type, args[i])
+	}
+
+	b, pred := f.Entry, -1
+	for n := 0; ; n++ {
+		if n == maxInterpBlocks {
+			return nil, fmt.Errorf("%s ran more than %d blocks", f.Name, n)
+		}
+		if err := in.block(b, pred); err != nil {
+			return nil, err
+		}
+		var succ int
+		switch b.Kind {
+		case BlockPlain, BlockFirst:
+		case BlockIf:
+			if in.vals[b.Controls[0]] == 0 {
+				succ = 1
+			}
+		case BlockExit, BlockRet:
+			return in.mems[b.Controls[0]], nil
+		default:
+			return nil, fmt.Errorf("%v: cannot interpret %s block", b, b.Kind)
+		}
+		e := b.Succs[succ]
+		b, pred = e.b, e.i
+	}
+}
+
+type interp struct {
+	vals map[*Value]int64     // latest values of non-memory values
+	mems map[*Value]interpMem // latest values of memory values
+	done map[*Value]bool      // values of the current block run so far
+}
+
+// block runs the values of b, entered from its predecessor with index
+// pred.
+func (in *interp) block(b *Block, pred int) error {
+	// The phis take their values at once, from the values on
+	// entry to the block.
+	vals := make(map[*Value]int64)
+	mems := make(map[*Value]interpMem)
+	for _, v := range b.Values {
+		if v.Op != OpPhi {
+			continue
+		}
+		if pred < 0 {
+			return fmt.Errorf("%v: phi in entry block", v)
+		}
+		if v.Type.IsMemory() {
+			mems[v] = in.mems[v.Args[pred]]
+		} else {
+			vals[v] = in.vals[v.Args[pred]]
+		}
+	}
+	for v, x := range vals {
+		in.vals[v] = x
+	}
+	for v, m := range mems {
+		in.mems[v] = m
+	}
+
+	in.done = make(map[*Value]bool)
+	for _, v := range b.Values {
+		if v.Op == OpPhi {
+			in.done[v] = true
+		}
+	}
+	for _, v := range b.Values {
+		if err := in.value(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value runs v, and the values of its block that it uses first.
+func (in *interp) value(v *Value) error {
+	if in.done[v] {
+		return nil
+	}
+	in.done[v] = true
+	for _, a := range v.Args {
+		if a.Block == v.Block {
+			if err := in.value(a); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v.Type.IsMemory() {
+		m, err := in.memory(v)
+		in.mems[v] = m
+		return err
+	}
+	x, err := in.compute(v)
+	in.vals[v] = x
+	return err
+}
+
+// memory returns the memory computed by v.
+func (in *interp) memory(v *Value) (interpMem, error) {
+	switch v.Op {
+	case OpInitMem:
+		return interpMem{}, nil
+	case OpCopy, OpVarDef, OpVarKill:
+		return in.mems[v.MemoryArg()], nil
+	case OpStore:
+		m := make(interpMem)
+		for addr, x := range in.mems[v.Args[2]] {
+			m[addr] = x
+		}
+		m[in.vals[v.Args[0]]] = in.vals[v.Args[1]]
+		return m, nil
+	}
+	return nil, fmt.Errorf("%v: cannot interpret %s", v, v.Op)
+}
+
+// compute returns the value computed by v.
+func (in *interp) compute(v *Value) (int64, error) {
+	arg := func(i int) int64 { return in.vals[v.Args[i]] }
+
+	switch v.Op {
+	case OpArg:
+		return in.vals[v], nil
+	case OpConst8, OpConst16, OpConst32, OpConst64, OpConstBool:
+		return v.AuxInt, nil
+	case OpConstNil:
+		return 0, nil
+	case OpCopy:
+		return arg(0), nil
+	case OpOffPtr:
+		return arg(0) + v.AuxInt, nil
+	case OpAddPtr:
+		return arg(0) + arg(1), nil
+	case OpLoad:
+		return in.ext(v.Type, in.mems[v.Args[1]][arg(0)]), nil
+	case OpNot:
+		return 1 - arg(0), nil
+	case OpAndB:
+		return arg(0) & arg(1), nil
+	case OpOrB:
+		return arg(0) | arg(1), nil
+	case OpEqB, OpEqPtr:
+		return b2i(arg(0) == arg(1)), nil
+	case OpNeqB, OpNeqPtr:
+		return b2i(arg(0) != arg(1)), nil
+	case OpCondSelect:
+		if arg(2) != 0 {
+			return arg(0), nil
+		}
+		return arg(1), nil
+	}
+
+	// The remaining ops come in families for each size, and their
+	// names are made of the name of the family, sizes and a u or U
+	// for unsigned ones, like Less32U, Div16u, Rsh64Ux8 or SignExt8to64.
+	name := v.Op.String()
+	family := name
+	if i := strings.IndexAny(name, "0123456789"); i >= 0 {
+		family = name[:i]
+	}
+	unsigned := strings.ContainsAny(name[len(family):], "uU")
+	if len(v.Args) == 0 {
+		return 0, fmt.Errorf("%v: cannot interpret %s", v, v.Op)
+	}
+	size := v.Args[0].Type.Size() * 8
+	x := arg(0)
+	var y, ux, uy uint64
+	if len(v.Args) > 1 {
+		y = uint64(arg(1))
+		uy = zext(y, v.Args[1].Type.Size()*8)
+	}
+	ux = zext(uint64(x), size)
+
+	var r int64
+	switch family {
+	case "Add":
+		r = x + int64(y)
+	case "Sub":
+		r = x - int64(y)
+	case "Mul":
+		r = x * int64(y)
+	case "And":
+		r = x & int64(y)
+	case "Or":
+		r = x | int64(y)
+	case "Xor":
+		r = x ^ int64(y)
+	case "Neg":
+		r = -x
+	case "Com":
+		r = ^x
+	case "Div", "Mod":
+		if y == 0 {
+			return 0, fmt.Errorf("%v: division by zero", v)
+		}
+		switch {
+		case unsigned && family == "Div":
+			r = int64(ux / zext(y, size))
+		case unsigned:
+			r = int64(ux % zext(y, size))
+		case int64(y) == -1 && family == "Div":
+			r = -x // without the overflow trap of x / -1
+		case int64(y) == -1:
+			r = 0
+		case family == "Div":
+			r = x / int64(y)
+		default:
+			r = x % int64(y)
+		}
+	case "Lsh":
+		if uy >= uint64(size) {
+			r = 0
+		} else {
+			r = x << uy
+		}
+	case "Rsh":
+		switch {
+		case unsigned && uy >= uint64(size):
+			r = 0
+		case unsigned:
+			r = int64(ux >> uy)
+		case uy >= uint64(size):
+			r = x >> 63
+		default:
+			r = x >> uy
+		}
+	case "Eq":
+		r = b2i(x == int64(y))
+	case "Neq":
+		r = b2i(x != int64(y))
+	case "Less":
+		if unsigned {
+			r = b2i(ux < zext(y, size))
+		} else {
+			r = b2i(x < int64(y))
+		}
+	case "Leq":
+		if unsigned {
+			r = b2i(ux <= zext(y, size))
+		} else {
+			r = b2i(x <= int64(y))
+		}
+	case "SignExt", "Trunc":
+		r = x
+	case "ZeroExt":
+		r = int64(ux)
+	default:
+		return 0, fmt.Errorf("%v: cannot interpret %s", v, v.Op)
+	}
+	return in.ext(v.Type, r), nil
+}
+
+// ext sign-extends x from the size of t, or converts it to 0 or 1 for
+// booleans.
+func (in *interp) ext(t *types.Type, x int64) int64 {
+	if t.IsBoolean() {
+		return b2i(x != 0)
+	}
+	bits := uint(t.Size() * 8)
+	if bits == 0 || bits >= 64 {
+		return x
+	}
+	return x << (64 - bits) >> (64 - bits)
+}
+
+// zext zero-extends x from size bits.
+func zext(x uint64, size int64) uint64 {
+	if size <= 0 || size >= 64 {
+		return x
+	}
+	return x & (1<<uint(size) - 1)
+}
+
+// checkPass runs pass on f, and fails the test if f exits with different
+// memory, or fails differently, before and after it for any of args.
+func checkPass(t *testing.T, f *Func, pass func(*Func), args ...[]int64) {
+	t.Helper()
+	var before []string
+	for _, a := range args {
+		before = append(before, interpResult(f, a))
+	}
+	pass(f)
+	CheckFunc(f)
+	for i, a := range args {
+		if after := interpResult(f, a); after != before[i] {
+			t.Errorf("%s%v: got %s after the pass, %s before\n%s", f.Name, a, after, before[i], f)
+		}
+	}
+}
+
+// interpResult returns the memory f exits with, or its error, as a string.
+func interpResult(f *Func, args []int64) string {
+	m, err := interpret(f, args...)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	var addrs []int64
+	for addr := range m {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	var s []string
+	for _, addr := range addrs {
+		s = append(s, strconv.FormatInt(addr, 10)+":"+strconv.FormatInt(m[addr], 10))
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
+func TestInterp(t *testing.T) {
+	c := testConfig(t)
+	i64 := c.config.Types.Int64
+	i8 := c.config.Types.Int8
+	u8 := c.config.Types.UInt8
+	ptr := c.config.Types.BytePtr
+	boolt := c.config.Types.Bool
+
+	// sum stores at p the sum of the i from 0 to n-1 that are not
+	// multiples of 3, and at p+8 that sum truncated to int8.
+	sum := func() fun {
+		return c.Fun("entry",
+			Bloc("entry",
+				Valu("mem", OpInitMem, types.TypeMem, 0, nil),
+				Valu("p", OpArg, ptr, 0, nil),
+				Valu("n", OpArg, i64, 0, nil),
+				Valu("zero", OpConst64, i64, 0, nil),
+				Valu("one", OpConst64, i64, 1, nil),
+				Valu("three", OpConst64, i64, 3, nil),
+				Goto("loop")),
+			Bloc("loop",
+				Valu("i", OpPhi, i64, 0, nil, "zero", "inc"),
+				Valu("s", OpPhi, i64, 0, nil, "zero", "s2"),
+				Valu("cond", OpLess64, boolt, 0, nil, "i", "n"),
+				If("cond", "body", "exit")),
+			Bloc("body",
+				Valu("rem", OpMod64, i64, 0, nil, "i", "three"),
+				Valu("mul3", OpEq64, boolt, 0, nil, "rem", "zero"),
+				Valu("add", OpAdd64, i64, 0, nil, "s", "i"),
+				Valu("s2", OpCondSelect, i64, 0, nil, "s", "add", "mul3"),
+				Valu("inc", OpAdd64, i64, 0, nil, "i", "one"),
+				Goto("loop")),
+			Bloc("exit",
+				Valu("st", OpStore, types.TypeMem, 0, i64, "p", "s", "mem"),
+				Valu("p8", OpOffPtr, ptr, 8, nil, "p"),
+				Valu("t", OpTrunc64to8, i8, 0, nil, "s"),
+				Valu("st2", OpStore, types.TypeMem, 0, i8, "p8", "t", "st"),
+				Exit("st2")))
+	}
+
+	for _, test := range []struct {
+		n    int64
+		want string
+	}{
+		{0, "[100:0 108:0]"},
+		{5, "[100:7 108:7]"},
+		{20, "[100:127 108:127]"},
+		{30, "[100:300 108:44]"},
+	} {
+		if got := interpResult(sum().f, []int64{100, test.n}); got != test.want {
+			t.Errorf("sum(%d) = %s, want %s", test.n, got, test.want)
+		}
+	}
+
+	// shift stores at p the unsigned byte x shifted right by y, and
+	// at p+1 the signed one.
+	shift := c.Fun("entry",
+		Bloc("entry",
+			Valu("mem", OpInitMem, types.TypeMem, 0, nil),
+			Valu("p", OpArg, ptr, 0, nil),
+			Valu("x", OpArg, u8, 0, nil),
+			Valu("y", OpArg, i64, 0, nil),
+			Valu("ux", OpRsh8Ux64, u8, 0, nil, "x", "y"),
+			Valu("sx", OpRsh8x64, i8, 0, nil, "x", "y"),
+			Valu("st", OpStore, types.TypeMem, 0, u8, "p", "ux", "mem"),
+			Valu("p1", OpOffPtr, ptr, 1, nil, "p"),
+			Valu("st2", OpStore, types.TypeMem, 0, i8, "p1", "sx", "st"),
+			Exit("st2")))
+	for _, test := range []struct {
+		x, y int64
+		want string
+	}{
+		{-128, 1, "[0:64 1:-64]"},
+		{-128, 9, "[0:0 1:-1]"},
+		{127, 3, "[0:15 1:15]"},
+	} {
+		if got := interpResult(shift.f, []int64{0, test.x, test.y}); got != test.want {
+			t.Errorf("shift(%d, %d) = %s, want %s", test.x, test.y, got, test.want)
+		}
+	}
+
+	// The results must not change in the passes that simplify sum.
+	args := [][]int64{{100, 0}, {100, 1}, {100, 7}, {100, 100}}
+	for _, pass := range []struct {
+		name string
+		fn   func(*Func)
+	}{
+		{"opt", opt},
+		{"cse", cse},
+		{"phielim", phielim},
+		{"fuseEarly", fuseEarly},
+		{"deadcode", deadcode},
+		{"fuseLate", fuseLate},
+	} {
+		t.Run(pass.name, func(t *testing.T) {
+			checkPass(t, sum().f, pass.fn, args...)
+		})
+	}
+}
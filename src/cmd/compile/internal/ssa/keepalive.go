@@ -0,0 +1,218 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import (
+	"cmd/compile/internal/ir"
+	"strings"
+)
+
+// checkKeepAlive reports calls that are passed a uintptr derived from
+// a pointer which is dead once the call starts. Nothing then keeps the
+// pointed-to object alive while the callee runs, so the garbage
+// collector may free it, or run its finalizer, while the callee still
+// uses the address:
+//
+//	u := uintptr(unsafe.Pointer(p))
+//	syscall.Syscall(SYS_READ, fd, u, n) // p is dead here
+//
+// The fix is usually a call to runtime.KeepAlive(p) after the call.
+//
+// Only pointers held in SSA values are checked: function arguments and
+// pointers returned by calls. A pointer loaded from memory, or the
+// address of a variable, stays reachable through that memory for as
+// long as the variable itself is live. The uintptr must be passed to
+// the call directly, possibly with offsets added.
+//
+// It is enabled by -d=keepalive.
+func checkKeepAlive(f *Func) {
+	if !f.KeepAliveCheck {
+		return
+	}
+	var users map[*Value][]*Value
+	for _, b := range f.Blocks {
+		for _, c := range b.Values {
+			switch c.Op {
+			case OpStaticLECall, OpClosureLECall, OpInterLECall, OpTailLECall:
+			default:
+				continue
+			}
+			callee := "function"
+			if aux, ok := c.Aux.(*AuxCall); ok && aux.Fn != nil {
+				if strings.HasPrefix(aux.Fn.Name, "runtime.") {
+					// Runtime helpers called by compiled code,
+					// such as map accesses with pointer keys,
+					// use the pointer's bits, not its target.
+					continue
+				}
+				callee = strings.TrimPrefix(aux.Fn.Name, `"".`)
+			}
+			for _, a := range c.Args[:len(c.Args)-1] {
+				p := uintptrSource(a)
+				if p == nil {
+					continue
+				}
+				if users == nil {
+					users = valueUsers(f)
+				}
+				if keepAliveLiveAfter(p, c, users) {
+					continue
+				}
+				name := keepAliveName(f, p)
+				f.Warnl(c.Pos, "%s may be garbage collected during call to %s that is passed a uintptr derived from it; call runtime.KeepAlive(%s) after the call", name, callee, name)
+			}
+		}
+	}
+}
+
+// uintptrSource returns the pointer that the uintptr a was converted
+// from, if that pointer is an argument or a call result.
+func uintptrSource(a *Value) *Value {
+	for {
+		switch a.Op {
+		case OpCopy:
+			a = a.Args[0]
+			continue
+		case OpAdd64, OpAdd32:
+			if a.Args[0].Op == OpConst64 || a.Args[0].Op == OpConst32 {
+				a = a.Args[1]
+			} else {
+				a = a.Args[0]
+			}
+			continue
+		case OpSub64, OpSub32:
+			a = a.Args[0]
+			continue
+		}
+		break
+	}
+	if a.Op != OpConvert || !a.Args[0].Type.IsPtrShaped() {
+		return nil
+	}
+	p := a.Args[0]
+	for p.Op == OpCopy || p.Op == OpOffPtr || p.Op == OpAddPtr || p.Op == OpPtrIndex {
+		p = p.Args[0]
+	}
+	switch p.Op {
+	case OpArg:
+		return p
+	case OpSelectN:
+		if p.Args[0].Op.IsCall() && p.Type.IsPtrShaped() {
+			return p
+		}
+	}
+	return nil
+}
+
+// valueUsers returns a map from each value in f to the values using it.
+func valueUsers(f *Func) map[*Value][]*Value {
+	users := make(map[*Value][]*Value)
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			for _, a := range v.Args {
+				users[a] = append(users[a], v)
+			}
+		}
+	}
+	return users
+}
+
+// keepAliveLiveAfter reports whether p, or a value computed from it,
+// is used after call c returns.
+func keepAliveLiveAfter(p, c *Value, users map[*Value][]*Value) bool {
+	// Blocks that may run after c's block.
+	after := make(map[*Block]bool)
+	work := []*Block{c.Block}
+	for len(work) > 0 {
+		b := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, e := range b.Succs {
+			if s := e.b; !after[s] {
+				after[s] = true
+				work = append(work, s)
+			}
+		}
+	}
+	if after[c.Block] {
+		// c is in a loop; p's use before c in the next iteration
+		// keeps it alive.
+		return true
+	}
+
+	controls := make(map[*Value][]*Block)
+	for b := range after {
+		for _, v := range b.ControlValues() {
+			controls[v] = append(controls[v], b)
+		}
+	}
+	for _, v := range c.Block.ControlValues() {
+		controls[v] = append(controls[v], c.Block)
+	}
+
+	seen := map[*Value]bool{p: true}
+	vals := []*Value{p}
+	for len(vals) > 0 {
+		v := vals[len(vals)-1]
+		vals = vals[:len(vals)-1]
+		if len(controls[v]) > 0 {
+			return true
+		}
+		for _, u := range users[v] {
+			if u == c || seen[u] {
+				continue
+			}
+			seen[u] = true
+			if after[u.Block] {
+				return true
+			}
+			if u.Op != OpPhi && u.Block == c.Block && memoryAfter(u.MemoryArg(), c) {
+				return true
+			}
+			switch {
+			case u.Op == OpStore && u.Args[1] == v:
+				// p may stay reachable from wherever it was stored.
+				return true
+			case u.Op == OpConvert, u.Type.IsMemory():
+				// Neither a uintptr nor a memory state refers to p.
+				continue
+			}
+			vals = append(vals, u)
+		}
+	}
+	return false
+}
+
+// memoryAfter reports whether memory state m in c's block comes
+// from the memory c returns.
+func memoryAfter(m, c *Value) bool {
+	for m != nil && m.Block == c.Block && m.Op != OpPhi {
+		if m == c {
+			return true
+		}
+		if m.Op == OpSelectN || m.Op == OpSelect1 {
+			m = m.Args[0]
+			continue
+		}
+		m = m.MemoryArg()
+	}
+	return false
+}
+
+// keepAliveName returns the name of the variable holding p.
+func keepAliveName(f *Func, p *Value) string {
+	if p.Op == OpArg {
+		if n, ok := p.Aux.(*ir.Name); ok {
+			return n.Sym().Name
+		}
+	}
+	for _, slot := range f.Names {
+		for _, v := range f.NamedValues[*slot] {
+			if v == p {
+				return slot.N.Sym().Name
+			}
+		}
+	}
+	return "pointer"
+}
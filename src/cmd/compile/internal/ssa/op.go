@@ -46,6 +46,13 @@ type opInfo struct {
 	scale             uint8     // amd64/386 indexed load scale
 }
 
+// FaultsOnNil reports whether o faults if one of its pointer arguments
+// is nil, either as an explicit nil check or as an implicit one.
+func (o Op) FaultsOnNil() bool {
+	i := &opcodeTable[o]
+	return i.nilCheck || i.faultOnNilArg0 || i.faultOnNilArg1
+}
+
 type inputInfo struct {
 	idx  int     // index in Args array
 	regs regMask // allowed input registers
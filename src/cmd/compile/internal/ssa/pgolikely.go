@@ -0,0 +1,128 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+// pgoLikely sets the likely direction of branches from the weights of
+// a CPU profile (-pgoprofile), so that layout places the frequently
+// executed successor of each branch right after it and moves the
+// rarely executed code out of the way.
+//
+// The profile only records which source lines were executing, so the
+// frequency of an edge is estimated from the weight of the lines in
+// the block it leads to. That is only meaningful if the block can't
+// be reached any other way; edges into merge points are left alone.
+func pgoLikely(f *Func) {
+	if f.LineWeight == nil {
+		return
+	}
+
+	var before []*Block
+	if f.pass.debug > 0 {
+		before = layoutOrder(f)
+		f.laidout = false
+	}
+
+	predicted, changed := 0, 0
+	for _, b := range f.Blocks {
+		if len(b.Succs) != 2 || b.Kind == BlockFirst || b.Kind == BlockDefer {
+			continue
+		}
+		w0, ok0 := edgeWeight(f, b.Succs[0].b)
+		w1, ok1 := edgeWeight(f, b.Succs[1].b)
+		if !ok0 || !ok1 {
+			continue
+		}
+		likely := BranchUnknown
+		switch {
+		case w0 > 2*w1:
+			likely = BranchLikely
+		case w1 > 2*w0:
+			likely = BranchUnlikely
+		default:
+			continue
+		}
+		predicted++
+		if b.Likely != likely {
+			changed++
+			b.Likely = likely
+			if f.pass.debug > 1 {
+				s := b.Succs[0].b
+				if likely == BranchUnlikely {
+					s = b.Succs[1].b
+				}
+				f.Warnl(b.Pos, "pgo likely: %s now likely to go to %s (weights %d, %d)", b, s, w0, w1)
+			}
+		}
+	}
+
+	if f.pass.debug > 0 && predicted > 0 {
+		after := layoutOrder(f)
+		f.laidout = false
+		f.Warnl(f.Entry.Pos, "pgo likely: %d branches predicted, %d changed; weighted jump distance %d -> %d",
+			predicted, changed, jumpDistance(f, before), jumpDistance(f, after))
+	}
+}
+
+// maxPGOEmptyBlocks bounds the chain of empty blocks edgeWeight looks
+// through to find the code a branch leads to.
+const maxPGOEmptyBlocks = 4
+
+// edgeWeight estimates how often control flows into b, which is
+// entered from a branch. It reports false if that can't be told apart
+// from the weight of other paths into the same code.
+func edgeWeight(f *Func, b *Block) (int64, bool) {
+	for i := 0; ; i++ {
+		if len(b.Preds) != 1 {
+			return 0, false
+		}
+		if len(b.Values) > 0 || b.Kind != BlockPlain || i == maxPGOEmptyBlocks {
+			return blockWeight(f, b), true
+		}
+		b = b.Succs[0].b
+	}
+}
+
+// blockWeight returns the largest profile weight of the source lines
+// of b's values and control.
+func blockWeight(f *Func, b *Block) int64 {
+	w := f.LineWeight(b.Pos)
+	for _, v := range b.Values {
+		if v.Op == OpPhi {
+			continue
+		}
+		if x := f.LineWeight(v.Pos); x > w {
+			w = x
+		}
+	}
+	return w
+}
+
+// jumpDistance returns the number of blocks skipped over by the edges
+// of f when its blocks are in the given order, weighted by the profile
+// weight of each edge. Lower is better: it approximates how much code
+// the hot paths of f are spread across.
+func jumpDistance(f *Func, order []*Block) int64 {
+	idx := make([]int32, f.NumBlocks())
+	for i, b := range order {
+		idx[b.ID] = int32(i)
+	}
+	var d int64
+	for _, b := range order {
+		bw := blockWeight(f, b)
+		for _, e := range b.Succs {
+			s := e.b
+			w := blockWeight(f, s)
+			if bw < w {
+				w = bw
+			}
+			skip := int64(idx[s.ID] - idx[b.ID] - 1)
+			if skip < 0 {
+				skip = -skip
+			}
+			d += skip * w
+		}
+	}
+	return d
+}
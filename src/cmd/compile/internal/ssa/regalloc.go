@@ -2467,6 +2467,10 @@ func (v *Value) rematerializeable() bool {
 	if !opcodeTable[v.Op].rematerializeable {
 		return false
 	}
+	if v.isPCRelAddr() {
+		// Recomputing it would call the PC thunk again.
+		return false
+	}
 	for _, a := range v.Args {
 		// SP and SB (generated by OpSP and OpSB) are always available.
 		if a.Op != OpSP && a.Op != OpSB {
@@ -40,7 +40,21 @@ func applyRewrite(f *Func, rb blockRewriter, rv valueRewriter, deadcode deadValu
 	var states map[string]bool
 	for {
 		change := false
-		for _, b := range f.Blocks {
+		blocks := f.Blocks
+		if f.stress != nil && !f.scheduled {
+			// Apply the rules to values in a random order, to find
+			// rules that depend on the order.
+			blocks = make([]*Block, len(f.Blocks))
+			for i, j := range f.stress.Perm(len(blocks)) {
+				blocks[i] = f.Blocks[j]
+			}
+			for _, b := range blocks {
+				f.stress.Shuffle(len(b.Values), func(i, j int) {
+					b.Values[i], b.Values[j] = b.Values[j], b.Values[i]
+				})
+			}
+		}
+		for _, b := range blocks {
 			var b0 *Block
 			if debug > 1 {
 				b0 = new(Block)
@@ -552,6 +566,19 @@ func shiftIsBounded(v *Value) bool {
 	return v.AuxInt != 0
 }
 
+// canRotate reports whether the architecture lowers variable rotates
+// of integers with the given number of bits.
+func canRotate(c *Config, bits int64) bool {
+	if bits > c.PtrSize*8 {
+		return false
+	}
+	switch c.arch {
+	case "amd64", "arm", "arm64", "ppc64", "ppc64le", "s390x", "wasm":
+		return true
+	}
+	return false
+}
+
 // canonLessThan returns whether x is "ordered" less than y, for purposes of normalizing
 // generated code as much as possible.
 func canonLessThan(x, y *Value) bool {
@@ -824,6 +851,46 @@ func devirtLECall(v *Value, sym *obj.LSym) *Value {
 	return v
 }
 
+// rewriteStructLoad rewrites v, a load of an SSA-able struct, into a
+// StructMake of loads of each of its fields.
+func rewriteStructLoad(v *Value) *Value {
+	b := v.Block
+	ptr := v.Args[0]
+	mem := v.Args[1]
+
+	t := v.Type
+	args := make([]*Value, t.NumFields())
+	for i := range args {
+		ft := t.FieldType(i)
+		addr := b.NewValue1I(v.Pos, OpOffPtr, ft.PtrTo(), t.FieldOff(i), ptr)
+		args[i] = b.NewValue2(v.Pos, OpLoad, ft, addr, mem)
+	}
+
+	v.reset(OpStructMake)
+	v.AddArgs(args...)
+	return v
+}
+
+// rewriteStructStore returns the memory state after storing each of the
+// fields of a StructMake value, in order, as v does for the whole struct.
+func rewriteStructStore(v *Value) *Value {
+	b := v.Block
+	dst := v.Args[0]
+	x := v.Args[1]
+	if x.Op != OpStructMake {
+		v.Fatalf("invalid struct store: %v", x)
+	}
+	mem := v.Args[2]
+
+	t := x.Type
+	for i, arg := range x.Args {
+		ft := t.FieldType(i)
+		addr := b.NewValue1I(v.Pos, OpOffPtr, ft.PtrTo(), t.FieldOff(i), dst)
+		mem = b.NewValue3A(v.Pos, OpStore, types.TypeMem, typeToAux(ft), addr, arg, mem)
+	}
+	return mem
+}
+
 // isSamePtr reports whether p1 and p2 point to the same address.
 func isSamePtr(p1, p2 *Value) bool {
 	if p1 == p2 {
@@ -3,7 +3,6 @@
 
 package ssa
 
-import "internal/buildcfg"
 import "math"
 import "cmd/internal/obj"
 import "cmd/compile/internal/types"
@@ -584,6 +583,10 @@ func rewriteValueAMD64(v *Value) bool {
 		return rewriteValueAMD64_OpAtomicLoad8(v)
 	case OpAtomicLoadPtr:
 		return rewriteValueAMD64_OpAtomicLoadPtr(v)
+	case OpAtomicLoadRelaxed32:
+		return rewriteValueAMD64_OpAtomicLoadRelaxed32(v)
+	case OpAtomicLoadRelaxed64:
+		return rewriteValueAMD64_OpAtomicLoadRelaxed64(v)
 	case OpAtomicOr32:
 		return rewriteValueAMD64_OpAtomicOr32(v)
 	case OpAtomicOr8:
@@ -596,6 +599,10 @@ func rewriteValueAMD64(v *Value) bool {
 		return rewriteValueAMD64_OpAtomicStore8(v)
 	case OpAtomicStorePtrNoWB:
 		return rewriteValueAMD64_OpAtomicStorePtrNoWB(v)
+	case OpAtomicStoreRelaxed32:
+		return rewriteValueAMD64_OpAtomicStoreRelaxed32(v)
+	case OpAtomicStoreRelaxed64:
+		return rewriteValueAMD64_OpAtomicStoreRelaxed64(v)
 	case OpAvg64u:
 		v.Op = OpAMD64AVGQU
 		return true
@@ -856,6 +863,14 @@ func rewriteValueAMD64(v *Value) bool {
 		return rewriteValueAMD64_OpLsh8x64(v)
 	case OpLsh8x8:
 		return rewriteValueAMD64_OpLsh8x8(v)
+	case OpMax32F:
+		return rewriteValueAMD64_OpMax32F(v)
+	case OpMax64F:
+		return rewriteValueAMD64_OpMax64F(v)
+	case OpMin32F:
+		return rewriteValueAMD64_OpMin32F(v)
+	case OpMin64F:
+		return rewriteValueAMD64_OpMin64F(v)
 	case OpMod16:
 		return rewriteValueAMD64_OpMod16(v)
 	case OpMod16u:
@@ -2770,7 +2785,7 @@ func rewriteValueAMD64_OpAMD64ANDL(v *Value) bool {
 		break
 	}
 	// match: (ANDL x (NOTL y))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (ANDNL x y)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
@@ -2779,7 +2794,7 @@ func rewriteValueAMD64_OpAMD64ANDL(v *Value) bool {
 				continue
 			}
 			y := v_1.Args[0]
-			if !(buildcfg.GOAMD64 >= 3) {
+			if !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64ANDNL)
@@ -2789,12 +2804,12 @@ func rewriteValueAMD64_OpAMD64ANDL(v *Value) bool {
 		break
 	}
 	// match: (ANDL x (NEGL x))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (BLSIL x)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
 			x := v_0
-			if v_1.Op != OpAMD64NEGL || x != v_1.Args[0] || !(buildcfg.GOAMD64 >= 3) {
+			if v_1.Op != OpAMD64NEGL || x != v_1.Args[0] || !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64BLSIL)
@@ -2804,12 +2819,12 @@ func rewriteValueAMD64_OpAMD64ANDL(v *Value) bool {
 		break
 	}
 	// match: (ANDL x (ADDLconst [-1] x))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (BLSRL x)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
 			x := v_0
-			if v_1.Op != OpAMD64ADDLconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(buildcfg.GOAMD64 >= 3) {
+			if v_1.Op != OpAMD64ADDLconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64BLSRL)
@@ -3240,7 +3255,7 @@ func rewriteValueAMD64_OpAMD64ANDQ(v *Value) bool {
 		break
 	}
 	// match: (ANDQ x (NOTQ y))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (ANDNQ x y)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
@@ -3249,7 +3264,7 @@ func rewriteValueAMD64_OpAMD64ANDQ(v *Value) bool {
 				continue
 			}
 			y := v_1.Args[0]
-			if !(buildcfg.GOAMD64 >= 3) {
+			if !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64ANDNQ)
@@ -3259,12 +3274,12 @@ func rewriteValueAMD64_OpAMD64ANDQ(v *Value) bool {
 		break
 	}
 	// match: (ANDQ x (NEGQ x))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (BLSIQ x)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
 			x := v_0
-			if v_1.Op != OpAMD64NEGQ || x != v_1.Args[0] || !(buildcfg.GOAMD64 >= 3) {
+			if v_1.Op != OpAMD64NEGQ || x != v_1.Args[0] || !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64BLSIQ)
@@ -3274,12 +3289,12 @@ func rewriteValueAMD64_OpAMD64ANDQ(v *Value) bool {
 		break
 	}
 	// match: (ANDQ x (ADDQconst [-1] x))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (BLSRQ x)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
 			x := v_0
-			if v_1.Op != OpAMD64ADDQconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(buildcfg.GOAMD64 >= 3) {
+			if v_1.Op != OpAMD64ADDQconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64BLSRQ)
@@ -3628,7 +3643,7 @@ func rewriteValueAMD64_OpAMD64BSWAPL(v *Value) bool {
 		return true
 	}
 	// match: (BSWAPL x:(MOVLload [i] {s} p mem))
-	// cond: x.Uses == 1 && buildcfg.GOAMD64 >= 3
+	// cond: x.Uses == 1 && v.Block.Func.GOAMD64 >= 3
 	// result: (MOVBELload [i] {s} p mem)
 	for {
 		x := v_0
@@ -3639,7 +3654,7 @@ func rewriteValueAMD64_OpAMD64BSWAPL(v *Value) bool {
 		s := auxToSym(x.Aux)
 		mem := x.Args[1]
 		p := x.Args[0]
-		if !(x.Uses == 1 && buildcfg.GOAMD64 >= 3) {
+		if !(x.Uses == 1 && v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64MOVBELload)
@@ -3679,7 +3694,7 @@ func rewriteValueAMD64_OpAMD64BSWAPQ(v *Value) bool {
 		return true
 	}
 	// match: (BSWAPQ x:(MOVQload [i] {s} p mem))
-	// cond: x.Uses == 1 && buildcfg.GOAMD64 >= 3
+	// cond: x.Uses == 1 && v.Block.Func.GOAMD64 >= 3
 	// result: (MOVBEQload [i] {s} p mem)
 	for {
 		x := v_0
@@ -3690,7 +3705,7 @@ func rewriteValueAMD64_OpAMD64BSWAPQ(v *Value) bool {
 		s := auxToSym(x.Aux)
 		mem := x.Args[1]
 		p := x.Args[0]
-		if !(x.Uses == 1 && buildcfg.GOAMD64 >= 3) {
+		if !(x.Uses == 1 && v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64MOVBEQload)
@@ -12350,7 +12365,7 @@ func rewriteValueAMD64_OpAMD64MOVLstore(v *Value) bool {
 		return true
 	}
 	// match: (MOVLstore [i] {s} p x:(BSWAPL w) mem)
-	// cond: x.Uses == 1 && buildcfg.GOAMD64 >= 3
+	// cond: x.Uses == 1 && v.Block.Func.GOAMD64 >= 3
 	// result: (MOVBELstore [i] {s} p w mem)
 	for {
 		i := auxIntToInt32(v.AuxInt)
@@ -12362,7 +12377,7 @@ func rewriteValueAMD64_OpAMD64MOVLstore(v *Value) bool {
 		}
 		w := x.Args[0]
 		mem := v_2
-		if !(x.Uses == 1 && buildcfg.GOAMD64 >= 3) {
+		if !(x.Uses == 1 && v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64MOVBELstore)
@@ -13311,7 +13326,7 @@ func rewriteValueAMD64_OpAMD64MOVQstore(v *Value) bool {
 		return true
 	}
 	// match: (MOVQstore [i] {s} p x:(BSWAPQ w) mem)
-	// cond: x.Uses == 1 && buildcfg.GOAMD64 >= 3
+	// cond: x.Uses == 1 && v.Block.Func.GOAMD64 >= 3
 	// result: (MOVBEQstore [i] {s} p w mem)
 	for {
 		i := auxIntToInt32(v.AuxInt)
@@ -13323,7 +13338,7 @@ func rewriteValueAMD64_OpAMD64MOVQstore(v *Value) bool {
 		}
 		w := x.Args[0]
 		mem := v_2
-		if !(x.Uses == 1 && buildcfg.GOAMD64 >= 3) {
+		if !(x.Uses == 1 && v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64MOVBEQstore)
@@ -26892,12 +26907,12 @@ func rewriteValueAMD64_OpAMD64XORL(v *Value) bool {
 		break
 	}
 	// match: (XORL x (ADDLconst [-1] x))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (BLSMSKL x)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
 			x := v_0
-			if v_1.Op != OpAMD64ADDLconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(buildcfg.GOAMD64 >= 3) {
+			if v_1.Op != OpAMD64ADDLconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64BLSMSKL)
@@ -27383,12 +27398,12 @@ func rewriteValueAMD64_OpAMD64XORQ(v *Value) bool {
 		break
 	}
 	// match: (XORQ x (ADDQconst [-1] x))
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (BLSMSKQ x)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
 			x := v_0
-			if v_1.Op != OpAMD64ADDQconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(buildcfg.GOAMD64 >= 3) {
+			if v_1.Op != OpAMD64ADDQconst || auxIntToInt32(v_1.AuxInt) != -1 || x != v_1.Args[0] || !(v.Block.Func.GOAMD64 >= 3) {
 				continue
 			}
 			v.reset(OpAMD64BLSMSKQ)
@@ -27840,6 +27855,32 @@ func rewriteValueAMD64_OpAtomicLoadPtr(v *Value) bool {
 		return true
 	}
 }
+func rewriteValueAMD64_OpAtomicLoadRelaxed32(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicLoadRelaxed32 ptr mem)
+	// result: (MOVLatomicload ptr mem)
+	for {
+		ptr := v_0
+		mem := v_1
+		v.reset(OpAMD64MOVLatomicload)
+		v.AddArg2(ptr, mem)
+		return true
+	}
+}
+func rewriteValueAMD64_OpAtomicLoadRelaxed64(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicLoadRelaxed64 ptr mem)
+	// result: (MOVQatomicload ptr mem)
+	for {
+		ptr := v_0
+		mem := v_1
+		v.reset(OpAMD64MOVQatomicload)
+		v.AddArg2(ptr, mem)
+		return true
+	}
+}
 func rewriteValueAMD64_OpAtomicOr32(v *Value) bool {
 	v_2 := v.Args[2]
 	v_1 := v.Args[1]
@@ -27946,6 +27987,36 @@ func rewriteValueAMD64_OpAtomicStorePtrNoWB(v *Value) bool {
 		return true
 	}
 }
+func rewriteValueAMD64_OpAtomicStoreRelaxed32(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicStoreRelaxed32 ptr val mem)
+	// result: (MOVLstore ptr val mem)
+	for {
+		ptr := v_0
+		val := v_1
+		mem := v_2
+		v.reset(OpAMD64MOVLstore)
+		v.AddArg3(ptr, val, mem)
+		return true
+	}
+}
+func rewriteValueAMD64_OpAtomicStoreRelaxed64(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicStoreRelaxed64 ptr val mem)
+	// result: (MOVQstore ptr val mem)
+	for {
+		ptr := v_0
+		val := v_1
+		mem := v_2
+		v.reset(OpAMD64MOVQstore)
+		v.AddArg3(ptr, val, mem)
+		return true
+	}
+}
 func rewriteValueAMD64_OpBitLen16(v *Value) bool {
 	v_0 := v.Args[0]
 	b := v.Block
@@ -28969,11 +29040,11 @@ func rewriteValueAMD64_OpCtz16(v *Value) bool {
 func rewriteValueAMD64_OpCtz16NonZero(v *Value) bool {
 	v_0 := v.Args[0]
 	// match: (Ctz16NonZero x)
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (TZCNTL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 >= 3) {
+		if !(v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64TZCNTL)
@@ -28981,11 +29052,11 @@ func rewriteValueAMD64_OpCtz16NonZero(v *Value) bool {
 		return true
 	}
 	// match: (Ctz16NonZero x)
-	// cond: buildcfg.GOAMD64 < 3
+	// cond: v.Block.Func.GOAMD64 < 3
 	// result: (BSFL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 < 3) {
+		if !(v.Block.Func.GOAMD64 < 3) {
 			break
 		}
 		v.reset(OpAMD64BSFL)
@@ -28999,11 +29070,11 @@ func rewriteValueAMD64_OpCtz32(v *Value) bool {
 	b := v.Block
 	typ := &b.Func.Config.Types
 	// match: (Ctz32 x)
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (TZCNTL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 >= 3) {
+		if !(v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64TZCNTL)
@@ -29011,11 +29082,11 @@ func rewriteValueAMD64_OpCtz32(v *Value) bool {
 		return true
 	}
 	// match: (Ctz32 x)
-	// cond: buildcfg.GOAMD64 < 3
+	// cond: v.Block.Func.GOAMD64 < 3
 	// result: (Select0 (BSFQ (BTSQconst <typ.UInt64> [32] x)))
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 < 3) {
+		if !(v.Block.Func.GOAMD64 < 3) {
 			break
 		}
 		v.reset(OpSelect0)
@@ -29032,11 +29103,11 @@ func rewriteValueAMD64_OpCtz32(v *Value) bool {
 func rewriteValueAMD64_OpCtz32NonZero(v *Value) bool {
 	v_0 := v.Args[0]
 	// match: (Ctz32NonZero x)
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (TZCNTL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 >= 3) {
+		if !(v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64TZCNTL)
@@ -29044,11 +29115,11 @@ func rewriteValueAMD64_OpCtz32NonZero(v *Value) bool {
 		return true
 	}
 	// match: (Ctz32NonZero x)
-	// cond: buildcfg.GOAMD64 < 3
+	// cond: v.Block.Func.GOAMD64 < 3
 	// result: (BSFL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 < 3) {
+		if !(v.Block.Func.GOAMD64 < 3) {
 			break
 		}
 		v.reset(OpAMD64BSFL)
@@ -29062,11 +29133,11 @@ func rewriteValueAMD64_OpCtz64(v *Value) bool {
 	b := v.Block
 	typ := &b.Func.Config.Types
 	// match: (Ctz64 x)
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (TZCNTQ x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 >= 3) {
+		if !(v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64TZCNTQ)
@@ -29074,12 +29145,12 @@ func rewriteValueAMD64_OpCtz64(v *Value) bool {
 		return true
 	}
 	// match: (Ctz64 <t> x)
-	// cond: buildcfg.GOAMD64 < 3
+	// cond: v.Block.Func.GOAMD64 < 3
 	// result: (CMOVQEQ (Select0 <t> (BSFQ x)) (MOVQconst <t> [64]) (Select1 <types.TypeFlags> (BSFQ x)))
 	for {
 		t := v.Type
 		x := v_0
-		if !(buildcfg.GOAMD64 < 3) {
+		if !(v.Block.Func.GOAMD64 < 3) {
 			break
 		}
 		v.reset(OpAMD64CMOVQEQ)
@@ -29101,11 +29172,11 @@ func rewriteValueAMD64_OpCtz64NonZero(v *Value) bool {
 	b := v.Block
 	typ := &b.Func.Config.Types
 	// match: (Ctz64NonZero x)
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (TZCNTQ x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 >= 3) {
+		if !(v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64TZCNTQ)
@@ -29113,11 +29184,11 @@ func rewriteValueAMD64_OpCtz64NonZero(v *Value) bool {
 		return true
 	}
 	// match: (Ctz64NonZero x)
-	// cond: buildcfg.GOAMD64 < 3
+	// cond: v.Block.Func.GOAMD64 < 3
 	// result: (Select0 (BSFQ x))
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 < 3) {
+		if !(v.Block.Func.GOAMD64 < 3) {
 			break
 		}
 		v.reset(OpSelect0)
@@ -29147,11 +29218,11 @@ func rewriteValueAMD64_OpCtz8(v *Value) bool {
 func rewriteValueAMD64_OpCtz8NonZero(v *Value) bool {
 	v_0 := v.Args[0]
 	// match: (Ctz8NonZero x)
-	// cond: buildcfg.GOAMD64 >= 3
+	// cond: v.Block.Func.GOAMD64 >= 3
 	// result: (TZCNTL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 >= 3) {
+		if !(v.Block.Func.GOAMD64 >= 3) {
 			break
 		}
 		v.reset(OpAMD64TZCNTL)
@@ -29159,11 +29230,11 @@ func rewriteValueAMD64_OpCtz8NonZero(v *Value) bool {
 		return true
 	}
 	// match: (Ctz8NonZero x)
-	// cond: buildcfg.GOAMD64 < 3
+	// cond: v.Block.Func.GOAMD64 < 3
 	// result: (BSFL x)
 	for {
 		x := v_0
-		if !(buildcfg.GOAMD64 < 3) {
+		if !(v.Block.Func.GOAMD64 < 3) {
 			break
 		}
 		v.reset(OpAMD64BSFL)
@@ -30618,6 +30689,58 @@ func rewriteValueAMD64_OpLsh8x8(v *Value) bool {
 	}
 	return false
 }
+func rewriteValueAMD64_OpMax32F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (Max32F x y)
+	// result: (MAXSS x y)
+	for {
+		x := v_0
+		y := v_1
+		v.reset(OpAMD64MAXSS)
+		v.AddArg2(x, y)
+		return true
+	}
+}
+func rewriteValueAMD64_OpMax64F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (Max64F x y)
+	// result: (MAXSD x y)
+	for {
+		x := v_0
+		y := v_1
+		v.reset(OpAMD64MAXSD)
+		v.AddArg2(x, y)
+		return true
+	}
+}
+func rewriteValueAMD64_OpMin32F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (Min32F x y)
+	// result: (MINSS x y)
+	for {
+		x := v_0
+		y := v_1
+		v.reset(OpAMD64MINSS)
+		v.AddArg2(x, y)
+		return true
+	}
+}
+func rewriteValueAMD64_OpMin64F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (Min64F x y)
+	// result: (MINSD x y)
+	for {
+		x := v_0
+		y := v_1
+		v.reset(OpAMD64MINSD)
+		v.AddArg2(x, y)
+		return true
+	}
+}
 func rewriteValueAMD64_OpMod16(v *Value) bool {
 	v_1 := v.Args[1]
 	v_0 := v.Args[0]
@@ -32913,6 +33036,36 @@ func rewriteValueAMD64_OpSelect0(v *Value) bool {
 		v.AddArg(v0)
 		return true
 	}
+	// match: (Select0 (Add64over x y))
+	// result: (Select0 <typ.UInt64> (ADDQcarry x y))
+	for {
+		if v_0.Op != OpAdd64over {
+			break
+		}
+		y := v_0.Args[1]
+		x := v_0.Args[0]
+		v.reset(OpSelect0)
+		v.Type = typ.UInt64
+		v0 := b.NewValue0(v.Pos, OpAMD64ADDQcarry, types.NewTuple(typ.UInt64, types.TypeFlags))
+		v0.AddArg2(x, y)
+		v.AddArg(v0)
+		return true
+	}
+	// match: (Select0 (Sub64over x y))
+	// result: (Select0 <typ.UInt64> (SUBQborrow x y))
+	for {
+		if v_0.Op != OpSub64over {
+			break
+		}
+		y := v_0.Args[1]
+		x := v_0.Args[0]
+		v.reset(OpSelect0)
+		v.Type = typ.UInt64
+		v0 := b.NewValue0(v.Pos, OpAMD64SUBQborrow, types.NewTuple(typ.UInt64, types.TypeFlags))
+		v0.AddArg2(x, y)
+		v.AddArg(v0)
+		return true
+	}
 	// match: (Select0 (Add64carry x y c))
 	// result: (Select0 <typ.UInt64> (ADCQ x y (Select1 <types.TypeFlags> (NEGLflags c))))
 	for {
@@ -33021,6 +33174,38 @@ func rewriteValueAMD64_OpSelect1(v *Value) bool {
 		v.AddArg(v0)
 		return true
 	}
+	// match: (Select1 (Add64over x y))
+	// result: (SETO (Select1 <types.TypeFlags> (ADDQcarry x y)))
+	for {
+		if v_0.Op != OpAdd64over {
+			break
+		}
+		y := v_0.Args[1]
+		x := v_0.Args[0]
+		v.reset(OpAMD64SETO)
+		v0 := b.NewValue0(v.Pos, OpSelect1, types.TypeFlags)
+		v1 := b.NewValue0(v.Pos, OpAMD64ADDQcarry, types.NewTuple(typ.UInt64, types.TypeFlags))
+		v1.AddArg2(x, y)
+		v0.AddArg(v1)
+		v.AddArg(v0)
+		return true
+	}
+	// match: (Select1 (Sub64over x y))
+	// result: (SETO (Select1 <types.TypeFlags> (SUBQborrow x y)))
+	for {
+		if v_0.Op != OpSub64over {
+			break
+		}
+		y := v_0.Args[1]
+		x := v_0.Args[0]
+		v.reset(OpAMD64SETO)
+		v0 := b.NewValue0(v.Pos, OpSelect1, types.TypeFlags)
+		v1 := b.NewValue0(v.Pos, OpAMD64SUBQborrow, types.NewTuple(typ.UInt64, types.TypeFlags))
+		v1.AddArg2(x, y)
+		v0.AddArg(v1)
+		v.AddArg(v0)
+		return true
+	}
 	// match: (Select1 (Add64carry x y c))
 	// result: (NEGQ <typ.UInt64> (SBBQcarrymask <typ.UInt64> (Select1 <types.TypeFlags> (ADCQ x y (Select1 <types.TypeFlags> (NEGLflags c))))))
 	for {
@@ -105,6 +105,10 @@ func rewriteValueARM64(v *Value) bool {
 		return rewriteValueARM64_OpARM64FCMPD(v)
 	case OpARM64FCMPS:
 		return rewriteValueARM64_OpARM64FCMPS(v)
+	case OpARM64FCSELD:
+		return rewriteValueARM64_OpARM64FCSELD(v)
+	case OpARM64FCSELS:
+		return rewriteValueARM64_OpARM64FCSELS(v)
 	case OpARM64FMOVDfpgp:
 		return rewriteValueARM64_OpARM64FMOVDfpgp(v)
 	case OpARM64FMOVDgpfp:
@@ -814,6 +818,14 @@ func rewriteValueARM64(v *Value) bool {
 		return rewriteValueARM64_OpLsh8x64(v)
 	case OpLsh8x8:
 		return rewriteValueARM64_OpLsh8x8(v)
+	case OpMax32F:
+		return rewriteValueARM64_OpMax32F(v)
+	case OpMax64F:
+		return rewriteValueARM64_OpMax64F(v)
+	case OpMin32F:
+		return rewriteValueARM64_OpMin32F(v)
+	case OpMin64F:
+		return rewriteValueARM64_OpMin64F(v)
 	case OpMod16:
 		return rewriteValueARM64_OpMod16(v)
 	case OpMod16u:
@@ -4391,6 +4403,48 @@ func rewriteValueARM64_OpARM64FCMPS(v *Value) bool {
 	}
 	return false
 }
+func rewriteValueARM64_OpARM64FCSELD(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (FCSELD [cc] x y (InvertFlags cmp))
+	// result: (FCSELD [arm64Invert(cc)] x y cmp)
+	for {
+		cc := auxIntToOp(v.AuxInt)
+		x := v_0
+		y := v_1
+		if v_2.Op != OpARM64InvertFlags {
+			break
+		}
+		cmp := v_2.Args[0]
+		v.reset(OpARM64FCSELD)
+		v.AuxInt = opToAuxInt(arm64Invert(cc))
+		v.AddArg3(x, y, cmp)
+		return true
+	}
+	return false
+}
+func rewriteValueARM64_OpARM64FCSELS(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (FCSELS [cc] x y (InvertFlags cmp))
+	// result: (FCSELS [arm64Invert(cc)] x y cmp)
+	for {
+		cc := auxIntToOp(v.AuxInt)
+		x := v_0
+		y := v_1
+		if v_2.Op != OpARM64InvertFlags {
+			break
+		}
+		cmp := v_2.Args[0]
+		v.reset(OpARM64FCSELS)
+		v.AuxInt = opToAuxInt(arm64Invert(cc))
+		v.AddArg3(x, y, cmp)
+		return true
+	}
+	return false
+}
 func rewriteValueARM64_OpARM64FMOVDfpgp(v *Value) bool {
 	v_0 := v.Args[0]
 	b := v.Block
@@ -24913,6 +24967,138 @@ func rewriteValueARM64_OpLsh8x8(v *Value) bool {
 		return true
 	}
 }
+func rewriteValueARM64_OpMax32F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	b := v.Block
+	// match: (Max32F [true] x y)
+	// result: (FMAXS x y)
+	for {
+		if auxIntToBool(v.AuxInt) != true {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FMAXS)
+		v.AddArg2(x, y)
+		return true
+	}
+	// match: (Max32F [false] x y)
+	// result: (FCSELS [OpARM64GreaterThanF] x y (FCMPS x y))
+	for {
+		if auxIntToBool(v.AuxInt) != false {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FCSELS)
+		v.AuxInt = opToAuxInt(OpARM64GreaterThanF)
+		v0 := b.NewValue0(v.Pos, OpARM64FCMPS, types.TypeFlags)
+		v0.AddArg2(x, y)
+		v.AddArg3(x, y, v0)
+		return true
+	}
+	return false
+}
+func rewriteValueARM64_OpMax64F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	b := v.Block
+	// match: (Max64F [true] x y)
+	// result: (FMAXD x y)
+	for {
+		if auxIntToBool(v.AuxInt) != true {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FMAXD)
+		v.AddArg2(x, y)
+		return true
+	}
+	// match: (Max64F [false] x y)
+	// result: (FCSELD [OpARM64GreaterThanF] x y (FCMPD x y))
+	for {
+		if auxIntToBool(v.AuxInt) != false {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FCSELD)
+		v.AuxInt = opToAuxInt(OpARM64GreaterThanF)
+		v0 := b.NewValue0(v.Pos, OpARM64FCMPD, types.TypeFlags)
+		v0.AddArg2(x, y)
+		v.AddArg3(x, y, v0)
+		return true
+	}
+	return false
+}
+func rewriteValueARM64_OpMin32F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	b := v.Block
+	// match: (Min32F [true] x y)
+	// result: (FMINS x y)
+	for {
+		if auxIntToBool(v.AuxInt) != true {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FMINS)
+		v.AddArg2(x, y)
+		return true
+	}
+	// match: (Min32F [false] x y)
+	// result: (FCSELS [OpARM64LessThanF] x y (FCMPS x y))
+	for {
+		if auxIntToBool(v.AuxInt) != false {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FCSELS)
+		v.AuxInt = opToAuxInt(OpARM64LessThanF)
+		v0 := b.NewValue0(v.Pos, OpARM64FCMPS, types.TypeFlags)
+		v0.AddArg2(x, y)
+		v.AddArg3(x, y, v0)
+		return true
+	}
+	return false
+}
+func rewriteValueARM64_OpMin64F(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	b := v.Block
+	// match: (Min64F [true] x y)
+	// result: (FMIND x y)
+	for {
+		if auxIntToBool(v.AuxInt) != true {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FMIND)
+		v.AddArg2(x, y)
+		return true
+	}
+	// match: (Min64F [false] x y)
+	// result: (FCSELD [OpARM64LessThanF] x y (FCMPD x y))
+	for {
+		if auxIntToBool(v.AuxInt) != false {
+			break
+		}
+		x := v_0
+		y := v_1
+		v.reset(OpARM64FCSELD)
+		v.AuxInt = opToAuxInt(OpARM64LessThanF)
+		v0 := b.NewValue0(v.Pos, OpARM64FCMPD, types.TypeFlags)
+		v0.AddArg2(x, y)
+		v.AddArg3(x, y, v0)
+		return true
+	}
+	return false
+}
 func rewriteValueARM64_OpMod16(v *Value) bool {
 	v_1 := v.Args[1]
 	v_0 := v.Args[0]
@@ -72,6 +72,10 @@ func rewriteValueS390X(v *Value) bool {
 		return rewriteValueS390X_OpAtomicLoadAcq32(v)
 	case OpAtomicLoadPtr:
 		return rewriteValueS390X_OpAtomicLoadPtr(v)
+	case OpAtomicLoadRelaxed32:
+		return rewriteValueS390X_OpAtomicLoadRelaxed32(v)
+	case OpAtomicLoadRelaxed64:
+		return rewriteValueS390X_OpAtomicLoadRelaxed64(v)
 	case OpAtomicOr32:
 		v.Op = OpS390XLAO
 		return true
@@ -87,6 +91,10 @@ func rewriteValueS390X(v *Value) bool {
 		return rewriteValueS390X_OpAtomicStorePtrNoWB(v)
 	case OpAtomicStoreRel32:
 		return rewriteValueS390X_OpAtomicStoreRel32(v)
+	case OpAtomicStoreRelaxed32:
+		return rewriteValueS390X_OpAtomicStoreRelaxed32(v)
+	case OpAtomicStoreRelaxed64:
+		return rewriteValueS390X_OpAtomicStoreRelaxed64(v)
 	case OpAvg64u:
 		return rewriteValueS390X_OpAvg64u(v)
 	case OpBitLen64:
@@ -199,6 +207,9 @@ func rewriteValueS390X(v *Value) bool {
 	case OpCvtBoolToUint8:
 		v.Op = OpCopy
 		return true
+	case OpDiv128u:
+		v.Op = OpS390XDLGR
+		return true
 	case OpDiv16:
 		return rewriteValueS390X_OpDiv16(v)
 	case OpDiv16u:
@@ -1121,6 +1132,32 @@ func rewriteValueS390X_OpAtomicLoadPtr(v *Value) bool {
 		return true
 	}
 }
+func rewriteValueS390X_OpAtomicLoadRelaxed32(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicLoadRelaxed32 ptr mem)
+	// result: (MOVWZatomicload ptr mem)
+	for {
+		ptr := v_0
+		mem := v_1
+		v.reset(OpS390XMOVWZatomicload)
+		v.AddArg2(ptr, mem)
+		return true
+	}
+}
+func rewriteValueS390X_OpAtomicLoadRelaxed64(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicLoadRelaxed64 ptr mem)
+	// result: (MOVDatomicload ptr mem)
+	for {
+		ptr := v_0
+		mem := v_1
+		v.reset(OpS390XMOVDatomicload)
+		v.AddArg2(ptr, mem)
+		return true
+	}
+}
 func rewriteValueS390X_OpAtomicOr8(v *Value) bool {
 	v_2 := v.Args[2]
 	v_1 := v.Args[1]
@@ -1234,6 +1271,36 @@ func rewriteValueS390X_OpAtomicStoreRel32(v *Value) bool {
 		return true
 	}
 }
+func rewriteValueS390X_OpAtomicStoreRelaxed32(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicStoreRelaxed32 ptr val mem)
+	// result: (MOVWatomicstore ptr val mem)
+	for {
+		ptr := v_0
+		val := v_1
+		mem := v_2
+		v.reset(OpS390XMOVWatomicstore)
+		v.AddArg3(ptr, val, mem)
+		return true
+	}
+}
+func rewriteValueS390X_OpAtomicStoreRelaxed64(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicStoreRelaxed64 ptr val mem)
+	// result: (MOVDatomicstore ptr val mem)
+	for {
+		ptr := v_0
+		val := v_1
+		mem := v_2
+		v.reset(OpS390XMOVDatomicstore)
+		v.AddArg3(ptr, val, mem)
+		return true
+	}
+}
 func rewriteValueS390X_OpAvg64u(v *Value) bool {
 	v_1 := v.Args[1]
 	v_0 := v.Args[0]
@@ -34,6 +34,10 @@ func rewriteValuegeneric(v *Value) bool {
 		return rewriteValuegeneric_OpAndB(v)
 	case OpArraySelect:
 		return rewriteValuegeneric_OpArraySelect(v)
+	case OpAtomicStoreRelaxed32:
+		return rewriteValuegeneric_OpAtomicStoreRelaxed32(v)
+	case OpAtomicStoreRelaxed64:
+		return rewriteValuegeneric_OpAtomicStoreRelaxed64(v)
 	case OpCom16:
 		return rewriteValuegeneric_OpCom16(v)
 	case OpCom32:
@@ -3653,6 +3657,56 @@ func rewriteValuegeneric_OpArraySelect(v *Value) bool {
 	}
 	return false
 }
+func rewriteValuegeneric_OpAtomicStoreRelaxed32(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicStoreRelaxed32 ptr1 x store:(AtomicStoreRelaxed32 ptr2 _ mem))
+	// cond: isSamePtr(ptr1, ptr2) && store.Uses == 1 && clobber(store)
+	// result: (AtomicStoreRelaxed32 ptr1 x mem)
+	for {
+		ptr1 := v_0
+		x := v_1
+		store := v_2
+		if store.Op != OpAtomicStoreRelaxed32 {
+			break
+		}
+		mem := store.Args[2]
+		ptr2 := store.Args[0]
+		if !(isSamePtr(ptr1, ptr2) && store.Uses == 1 && clobber(store)) {
+			break
+		}
+		v.reset(OpAtomicStoreRelaxed32)
+		v.AddArg3(ptr1, x, mem)
+		return true
+	}
+	return false
+}
+func rewriteValuegeneric_OpAtomicStoreRelaxed64(v *Value) bool {
+	v_2 := v.Args[2]
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	// match: (AtomicStoreRelaxed64 ptr1 x store:(AtomicStoreRelaxed64 ptr2 _ mem))
+	// cond: isSamePtr(ptr1, ptr2) && store.Uses == 1 && clobber(store)
+	// result: (AtomicStoreRelaxed64 ptr1 x mem)
+	for {
+		ptr1 := v_0
+		x := v_1
+		store := v_2
+		if store.Op != OpAtomicStoreRelaxed64 {
+			break
+		}
+		mem := store.Args[2]
+		ptr2 := store.Args[0]
+		if !(isSamePtr(ptr1, ptr2) && store.Uses == 1 && clobber(store)) {
+			break
+		}
+		v.reset(OpAtomicStoreRelaxed64)
+		v.AddArg3(ptr1, x, mem)
+		return true
+	}
+	return false
+}
 func rewriteValuegeneric_OpCom16(v *Value) bool {
 	v_0 := v.Args[0]
 	// match: (Com16 (Com16 x))
@@ -8692,6 +8746,32 @@ func rewriteValuegeneric_OpEqPtr(v *Value) bool {
 		}
 		break
 	}
+	// match: (EqPtr (ITab (IMake x _)) (ITab (IMake y _)))
+	// result: (EqPtr x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpITab {
+				continue
+			}
+			v_0_0 := v_0.Args[0]
+			if v_0_0.Op != OpIMake {
+				continue
+			}
+			x := v_0_0.Args[0]
+			if v_1.Op != OpITab {
+				continue
+			}
+			v_1_0 := v_1.Args[0]
+			if v_1_0.Op != OpIMake {
+				continue
+			}
+			y := v_1_0.Args[0]
+			v.reset(OpEqPtr)
+			v.AddArg2(x, y)
+			return true
+		}
+		break
+	}
 	return false
 }
 func rewriteValuegeneric_OpEqSlice(v *Value) bool {
@@ -8716,11 +8796,11 @@ func rewriteValuegeneric_OpEqSlice(v *Value) bool {
 func rewriteValuegeneric_OpIMake(v *Value) bool {
 	v_1 := v.Args[1]
 	v_0 := v.Args[0]
-	// match: (IMake _typ (StructMake1 val))
+	// match: (IMake _typ (StructMake val))
 	// result: (IMake _typ val)
 	for {
 		_typ := v_0
-		if v_1.Op != OpStructMake1 {
+		if v_1.Op != OpStructMake || len(v_1.Args) != 1 {
 			break
 		}
 		val := v_1.Args[0]
@@ -11032,120 +11112,14 @@ func rewriteValuegeneric_OpLoad(v *Value) bool {
 		return true
 	}
 	// match: (Load <t> _ _)
-	// cond: t.IsStruct() && t.NumFields() == 0 && fe.CanSSA(t)
-	// result: (StructMake0)
-	for {
-		t := v.Type
-		if !(t.IsStruct() && t.NumFields() == 0 && fe.CanSSA(t)) {
-			break
-		}
-		v.reset(OpStructMake0)
-		return true
-	}
-	// match: (Load <t> ptr mem)
-	// cond: t.IsStruct() && t.NumFields() == 1 && fe.CanSSA(t)
-	// result: (StructMake1 (Load <t.FieldType(0)> (OffPtr <t.FieldType(0).PtrTo()> [0] ptr) mem))
-	for {
-		t := v.Type
-		ptr := v_0
-		mem := v_1
-		if !(t.IsStruct() && t.NumFields() == 1 && fe.CanSSA(t)) {
-			break
-		}
-		v.reset(OpStructMake1)
-		v0 := b.NewValue0(v.Pos, OpLoad, t.FieldType(0))
-		v1 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v1.AuxInt = int64ToAuxInt(0)
-		v1.AddArg(ptr)
-		v0.AddArg2(v1, mem)
-		v.AddArg(v0)
-		return true
-	}
-	// match: (Load <t> ptr mem)
-	// cond: t.IsStruct() && t.NumFields() == 2 && fe.CanSSA(t)
-	// result: (StructMake2 (Load <t.FieldType(0)> (OffPtr <t.FieldType(0).PtrTo()> [0] ptr) mem) (Load <t.FieldType(1)> (OffPtr <t.FieldType(1).PtrTo()> [t.FieldOff(1)] ptr) mem))
-	for {
-		t := v.Type
-		ptr := v_0
-		mem := v_1
-		if !(t.IsStruct() && t.NumFields() == 2 && fe.CanSSA(t)) {
-			break
-		}
-		v.reset(OpStructMake2)
-		v0 := b.NewValue0(v.Pos, OpLoad, t.FieldType(0))
-		v1 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v1.AuxInt = int64ToAuxInt(0)
-		v1.AddArg(ptr)
-		v0.AddArg2(v1, mem)
-		v2 := b.NewValue0(v.Pos, OpLoad, t.FieldType(1))
-		v3 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(1).PtrTo())
-		v3.AuxInt = int64ToAuxInt(t.FieldOff(1))
-		v3.AddArg(ptr)
-		v2.AddArg2(v3, mem)
-		v.AddArg2(v0, v2)
-		return true
-	}
-	// match: (Load <t> ptr mem)
-	// cond: t.IsStruct() && t.NumFields() == 3 && fe.CanSSA(t)
-	// result: (StructMake3 (Load <t.FieldType(0)> (OffPtr <t.FieldType(0).PtrTo()> [0] ptr) mem) (Load <t.FieldType(1)> (OffPtr <t.FieldType(1).PtrTo()> [t.FieldOff(1)] ptr) mem) (Load <t.FieldType(2)> (OffPtr <t.FieldType(2).PtrTo()> [t.FieldOff(2)] ptr) mem))
-	for {
-		t := v.Type
-		ptr := v_0
-		mem := v_1
-		if !(t.IsStruct() && t.NumFields() == 3 && fe.CanSSA(t)) {
-			break
-		}
-		v.reset(OpStructMake3)
-		v0 := b.NewValue0(v.Pos, OpLoad, t.FieldType(0))
-		v1 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v1.AuxInt = int64ToAuxInt(0)
-		v1.AddArg(ptr)
-		v0.AddArg2(v1, mem)
-		v2 := b.NewValue0(v.Pos, OpLoad, t.FieldType(1))
-		v3 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(1).PtrTo())
-		v3.AuxInt = int64ToAuxInt(t.FieldOff(1))
-		v3.AddArg(ptr)
-		v2.AddArg2(v3, mem)
-		v4 := b.NewValue0(v.Pos, OpLoad, t.FieldType(2))
-		v5 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(2).PtrTo())
-		v5.AuxInt = int64ToAuxInt(t.FieldOff(2))
-		v5.AddArg(ptr)
-		v4.AddArg2(v5, mem)
-		v.AddArg3(v0, v2, v4)
-		return true
-	}
-	// match: (Load <t> ptr mem)
-	// cond: t.IsStruct() && t.NumFields() == 4 && fe.CanSSA(t)
-	// result: (StructMake4 (Load <t.FieldType(0)> (OffPtr <t.FieldType(0).PtrTo()> [0] ptr) mem) (Load <t.FieldType(1)> (OffPtr <t.FieldType(1).PtrTo()> [t.FieldOff(1)] ptr) mem) (Load <t.FieldType(2)> (OffPtr <t.FieldType(2).PtrTo()> [t.FieldOff(2)] ptr) mem) (Load <t.FieldType(3)> (OffPtr <t.FieldType(3).PtrTo()> [t.FieldOff(3)] ptr) mem))
+	// cond: t.IsStruct() && fe.CanSSA(t)
+	// result: rewriteStructLoad(v)
 	for {
 		t := v.Type
-		ptr := v_0
-		mem := v_1
-		if !(t.IsStruct() && t.NumFields() == 4 && fe.CanSSA(t)) {
+		if !(t.IsStruct() && fe.CanSSA(t)) {
 			break
 		}
-		v.reset(OpStructMake4)
-		v0 := b.NewValue0(v.Pos, OpLoad, t.FieldType(0))
-		v1 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v1.AuxInt = int64ToAuxInt(0)
-		v1.AddArg(ptr)
-		v0.AddArg2(v1, mem)
-		v2 := b.NewValue0(v.Pos, OpLoad, t.FieldType(1))
-		v3 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(1).PtrTo())
-		v3.AuxInt = int64ToAuxInt(t.FieldOff(1))
-		v3.AddArg(ptr)
-		v2.AddArg2(v3, mem)
-		v4 := b.NewValue0(v.Pos, OpLoad, t.FieldType(2))
-		v5 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(2).PtrTo())
-		v5.AuxInt = int64ToAuxInt(t.FieldOff(2))
-		v5.AddArg(ptr)
-		v4.AddArg2(v5, mem)
-		v6 := b.NewValue0(v.Pos, OpLoad, t.FieldType(3))
-		v7 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(3).PtrTo())
-		v7.AuxInt = int64ToAuxInt(t.FieldOff(3))
-		v7.AddArg(ptr)
-		v6.AddArg2(v7, mem)
-		v.AddArg4(v0, v2, v4, v6)
+		v.copyOf(rewriteStructLoad(v))
 		return true
 	}
 	// match: (Load <t> _ _)
@@ -16402,6 +16376,32 @@ func rewriteValuegeneric_OpNeqPtr(v *Value) bool {
 		}
 		break
 	}
+	// match: (NeqPtr (ITab (IMake x _)) (ITab (IMake y _)))
+	// result: (NeqPtr x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpITab {
+				continue
+			}
+			v_0_0 := v_0.Args[0]
+			if v_0_0.Op != OpIMake {
+				continue
+			}
+			x := v_0_0.Args[0]
+			if v_1.Op != OpITab {
+				continue
+			}
+			v_1_0 := v_1.Args[0]
+			if v_1_0.Op != OpIMake {
+				continue
+			}
+			y := v_1_0.Args[0]
+			v.reset(OpNeqPtr)
+			v.AddArg2(x, y)
+			return true
+		}
+		break
+	}
 	return false
 }
 func rewriteValuegeneric_OpNeqSlice(v *Value) bool {
@@ -17088,6 +17088,7 @@ func rewriteValuegeneric_OpOr32(v *Value) bool {
 	v_1 := v.Args[1]
 	v_0 := v.Args[0]
 	b := v.Block
+	config := b.Func.Config
 	// match: (Or32 (Const32 [c]) (Const32 [d]))
 	// result: (Const32 [c|d])
 	for {
@@ -17106,151 +17107,539 @@ func rewriteValuegeneric_OpOr32(v *Value) bool {
 		}
 		break
 	}
-	// match: (Or32 x x)
-	// result: x
-	for {
-		x := v_0
-		if x != v_1 {
-			break
-		}
-		v.copyOf(x)
-		return true
-	}
-	// match: (Or32 (Const32 [0]) x)
-	// result: x
-	for {
-		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			if v_0.Op != OpConst32 || auxIntToInt32(v_0.AuxInt) != 0 {
-				continue
-			}
-			x := v_1
-			v.copyOf(x)
-			return true
-		}
-		break
-	}
-	// match: (Or32 (Const32 [-1]) _)
-	// result: (Const32 [-1])
+	// match: (Or32 (Lsh32x64 x (And64 y (Const64 [31]))) (Rsh32Ux64 x (And64 (Neg64 y) (Const64 [31]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft32 x y)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			if v_0.Op != OpConst32 || auxIntToInt32(v_0.AuxInt) != -1 {
+			if v_0.Op != OpLsh32x64 {
 				continue
 			}
-			v.reset(OpConst32)
-			v.AuxInt = int32ToAuxInt(-1)
-			return true
-		}
-		break
-	}
-	// match: (Or32 x (Or32 x y))
-	// result: (Or32 x y)
-	for {
-		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			x := v_0
-			if v_1.Op != OpOr32 {
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
 				continue
 			}
-			_ = v_1.Args[1]
-			v_1_0 := v_1.Args[0]
-			v_1_1 := v_1.Args[1]
-			for _i1 := 0; _i1 <= 1; _i1, v_1_0, v_1_1 = _i1+1, v_1_1, v_1_0 {
-				if x != v_1_0 {
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 31 || v_1.Op != OpRsh32Ux64 {
 					continue
 				}
-				y := v_1_1
-				v.reset(OpOr32)
-				v.AddArg2(x, y)
-				return true
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg64 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v.AddArg2(x, y)
+					return true
+				}
 			}
 		}
 		break
 	}
-	// match: (Or32 (And32 x (Const32 [c2])) (Const32 <t> [c1]))
-	// cond: ^(c1 | c2) == 0
-	// result: (Or32 (Const32 <t> [c1]) x)
+	// match: (Or32 (Lsh32x64 x (And64 y (Const64 [31]))) (Rsh32Ux64 x (And64 (Sub64 (Const64 [32]) y) (Const64 [31]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft32 x y)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			if v_0.Op != OpAnd32 {
+			if v_0.Op != OpLsh32x64 {
 				continue
 			}
 			_ = v_0.Args[1]
-			v_0_0 := v_0.Args[0]
+			x := v_0.Args[0]
 			v_0_1 := v_0.Args[1]
-			for _i1 := 0; _i1 <= 1; _i1, v_0_0, v_0_1 = _i1+1, v_0_1, v_0_0 {
-				x := v_0_0
-				if v_0_1.Op != OpConst32 {
+			if v_0_1.Op != OpAnd64 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 31 || v_1.Op != OpRsh32Ux64 {
 					continue
 				}
-				c2 := auxIntToInt32(v_0_1.AuxInt)
-				if v_1.Op != OpConst32 {
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
 					continue
 				}
-				t := v_1.Type
-				c1 := auxIntToInt32(v_1.AuxInt)
-				if !(^(c1 | c2) == 0) {
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
 					continue
 				}
-				v.reset(OpOr32)
-				v0 := b.NewValue0(v.Pos, OpConst32, t)
-				v0.AuxInt = int32ToAuxInt(c1)
-				v.AddArg2(v0, x)
-				return true
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub64 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst64 || auxIntToInt64(v_1_1_0_0.AuxInt) != 32 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v.AddArg2(x, y)
+					return true
+				}
 			}
 		}
 		break
 	}
-	// match: (Or32 (Or32 i:(Const32 <t>) z) x)
-	// cond: (z.Op != OpConst32 && x.Op != OpConst32)
-	// result: (Or32 i (Or32 <t> z x))
+	// match: (Or32 (Lsh32x32 x (And32 y (Const32 [31]))) (Rsh32Ux32 x (And32 (Neg32 y) (Const32 [31]))))
+	// cond: canRotate(config, 32)
+	// result: (RotateLeft32 x y)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			if v_0.Op != OpOr32 {
+			if v_0.Op != OpLsh32x32 {
 				continue
 			}
 			_ = v_0.Args[1]
-			v_0_0 := v_0.Args[0]
+			x := v_0.Args[0]
 			v_0_1 := v_0.Args[1]
-			for _i1 := 0; _i1 <= 1; _i1, v_0_0, v_0_1 = _i1+1, v_0_1, v_0_0 {
-				i := v_0_0
-				if i.Op != OpConst32 {
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 31 || v_1.Op != OpRsh32Ux32 {
 					continue
 				}
-				t := i.Type
-				z := v_0_1
-				x := v_1
-				if !(z.Op != OpConst32 && x.Op != OpConst32) {
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
 					continue
 				}
-				v.reset(OpOr32)
-				v0 := b.NewValue0(v.Pos, OpOr32, t)
-				v0.AddArg2(z, x)
-				v.AddArg2(i, v0)
-				return true
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg32 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 32)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v.AddArg2(x, y)
+					return true
+				}
 			}
 		}
 		break
 	}
-	// match: (Or32 (Const32 <t> [c]) (Or32 (Const32 <t> [d]) x))
-	// result: (Or32 (Const32 <t> [c|d]) x)
+	// match: (Or32 (Lsh32x32 x (And32 y (Const32 [31]))) (Rsh32Ux32 x (And32 (Sub32 (Const32 [32]) y) (Const32 [31]))))
+	// cond: canRotate(config, 32)
+	// result: (RotateLeft32 x y)
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			if v_0.Op != OpConst32 {
+			if v_0.Op != OpLsh32x32 {
 				continue
 			}
-			t := v_0.Type
-			c := auxIntToInt32(v_0.AuxInt)
-			if v_1.Op != OpOr32 {
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
 				continue
 			}
-			_ = v_1.Args[1]
-			v_1_0 := v_1.Args[0]
-			v_1_1 := v_1.Args[1]
-			for _i1 := 0; _i1 <= 1; _i1, v_1_0, v_1_1 = _i1+1, v_1_1, v_1_0 {
-				if v_1_0.Op != OpConst32 || v_1_0.Type != t {
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 31 || v_1.Op != OpRsh32Ux32 {
 					continue
 				}
-				d := auxIntToInt32(v_1_0.AuxInt)
-				x := v_1_1
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub32 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst32 || auxIntToInt32(v_1_1_0_0.AuxInt) != 32 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 32)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v.AddArg2(x, y)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or32 (Rsh32Ux64 x (And64 y (Const64 [31]))) (Lsh32x64 x (And64 (Neg64 y) (Const64 [31]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft32 x (Neg64 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh32Ux64 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 31 || v_1.Op != OpLsh32x64 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg64 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v0 := b.NewValue0(v.Pos, OpNeg64, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or32 (Rsh32Ux64 x (And64 y (Const64 [31]))) (Lsh32x64 x (And64 (Sub64 (Const64 [32]) y) (Const64 [31]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft32 x (Neg64 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh32Ux64 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 31 || v_1.Op != OpLsh32x64 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub64 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst64 || auxIntToInt64(v_1_1_0_0.AuxInt) != 32 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v0 := b.NewValue0(v.Pos, OpNeg64, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or32 (Rsh32Ux32 x (And32 y (Const32 [31]))) (Lsh32x32 x (And32 (Neg32 y) (Const32 [31]))))
+	// cond: canRotate(config, 32)
+	// result: (RotateLeft32 x (Neg32 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh32Ux32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 31 || v_1.Op != OpLsh32x32 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg32 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 32)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v0 := b.NewValue0(v.Pos, OpNeg32, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or32 (Rsh32Ux32 x (And32 y (Const32 [31]))) (Lsh32x32 x (And32 (Sub32 (Const32 [32]) y) (Const32 [31]))))
+	// cond: canRotate(config, 32)
+	// result: (RotateLeft32 x (Neg32 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh32Ux32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 31 || v_1.Op != OpLsh32x32 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub32 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst32 || auxIntToInt32(v_1_1_0_0.AuxInt) != 32 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 31 || !(canRotate(config, 32)) {
+						continue
+					}
+					v.reset(OpRotateLeft32)
+					v0 := b.NewValue0(v.Pos, OpNeg32, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or32 x x)
+	// result: x
+	for {
+		x := v_0
+		if x != v_1 {
+			break
+		}
+		v.copyOf(x)
+		return true
+	}
+	// match: (Or32 (Const32 [0]) x)
+	// result: x
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpConst32 || auxIntToInt32(v_0.AuxInt) != 0 {
+				continue
+			}
+			x := v_1
+			v.copyOf(x)
+			return true
+		}
+		break
+	}
+	// match: (Or32 (Const32 [-1]) _)
+	// result: (Const32 [-1])
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpConst32 || auxIntToInt32(v_0.AuxInt) != -1 {
+				continue
+			}
+			v.reset(OpConst32)
+			v.AuxInt = int32ToAuxInt(-1)
+			return true
+		}
+		break
+	}
+	// match: (Or32 x (Or32 x y))
+	// result: (Or32 x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			x := v_0
+			if v_1.Op != OpOr32 {
+				continue
+			}
+			_ = v_1.Args[1]
+			v_1_0 := v_1.Args[0]
+			v_1_1 := v_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_1_0, v_1_1 = _i1+1, v_1_1, v_1_0 {
+				if x != v_1_0 {
+					continue
+				}
+				y := v_1_1
+				v.reset(OpOr32)
+				v.AddArg2(x, y)
+				return true
+			}
+		}
+		break
+	}
+	// match: (Or32 (And32 x (Const32 [c2])) (Const32 <t> [c1]))
+	// cond: ^(c1 | c2) == 0
+	// result: (Or32 (Const32 <t> [c1]) x)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			v_0_0 := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_0, v_0_1 = _i1+1, v_0_1, v_0_0 {
+				x := v_0_0
+				if v_0_1.Op != OpConst32 {
+					continue
+				}
+				c2 := auxIntToInt32(v_0_1.AuxInt)
+				if v_1.Op != OpConst32 {
+					continue
+				}
+				t := v_1.Type
+				c1 := auxIntToInt32(v_1.AuxInt)
+				if !(^(c1 | c2) == 0) {
+					continue
+				}
+				v.reset(OpOr32)
+				v0 := b.NewValue0(v.Pos, OpConst32, t)
+				v0.AuxInt = int32ToAuxInt(c1)
+				v.AddArg2(v0, x)
+				return true
+			}
+		}
+		break
+	}
+	// match: (Or32 (Or32 i:(Const32 <t>) z) x)
+	// cond: (z.Op != OpConst32 && x.Op != OpConst32)
+	// result: (Or32 i (Or32 <t> z x))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpOr32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			v_0_0 := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_0, v_0_1 = _i1+1, v_0_1, v_0_0 {
+				i := v_0_0
+				if i.Op != OpConst32 {
+					continue
+				}
+				t := i.Type
+				z := v_0_1
+				x := v_1
+				if !(z.Op != OpConst32 && x.Op != OpConst32) {
+					continue
+				}
+				v.reset(OpOr32)
+				v0 := b.NewValue0(v.Pos, OpOr32, t)
+				v0.AddArg2(z, x)
+				v.AddArg2(i, v0)
+				return true
+			}
+		}
+		break
+	}
+	// match: (Or32 (Const32 <t> [c]) (Or32 (Const32 <t> [d]) x))
+	// result: (Or32 (Const32 <t> [c|d]) x)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpConst32 {
+				continue
+			}
+			t := v_0.Type
+			c := auxIntToInt32(v_0.AuxInt)
+			if v_1.Op != OpOr32 {
+				continue
+			}
+			_ = v_1.Args[1]
+			v_1_0 := v_1.Args[0]
+			v_1_1 := v_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_1_0, v_1_1 = _i1+1, v_1_1, v_1_0 {
+				if v_1_0.Op != OpConst32 || v_1_0.Type != t {
+					continue
+				}
+				d := auxIntToInt32(v_1_0.AuxInt)
+				x := v_1_1
 				v.reset(OpOr32)
 				v0 := b.NewValue0(v.Pos, OpConst32, t)
 				v0.AuxInt = int32ToAuxInt(c | d)
@@ -17260,27 +17649,416 @@ func rewriteValuegeneric_OpOr32(v *Value) bool {
 		}
 		break
 	}
-	return false
-}
-func rewriteValuegeneric_OpOr64(v *Value) bool {
-	v_1 := v.Args[1]
-	v_0 := v.Args[0]
-	b := v.Block
-	// match: (Or64 (Const64 [c]) (Const64 [d]))
-	// result: (Const64 [c|d])
+	return false
+}
+func rewriteValuegeneric_OpOr64(v *Value) bool {
+	v_1 := v.Args[1]
+	v_0 := v.Args[0]
+	b := v.Block
+	config := b.Func.Config
+	// match: (Or64 (Const64 [c]) (Const64 [d]))
+	// result: (Const64 [c|d])
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpConst64 {
+				continue
+			}
+			c := auxIntToInt64(v_0.AuxInt)
+			if v_1.Op != OpConst64 {
+				continue
+			}
+			d := auxIntToInt64(v_1.AuxInt)
+			v.reset(OpConst64)
+			v.AuxInt = int64ToAuxInt(c | d)
+			return true
+		}
+		break
+	}
+	// match: (Or64 (Lsh64x64 x (And64 y (Const64 [63]))) (Rsh64Ux64 x (And64 (Neg64 y) (Const64 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpLsh64x64 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 63 || v_1.Op != OpRsh64Ux64 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg64 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v.AddArg2(x, y)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Lsh64x64 x (And64 y (Const64 [63]))) (Rsh64Ux64 x (And64 (Sub64 (Const64 [64]) y) (Const64 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpLsh64x64 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 63 || v_1.Op != OpRsh64Ux64 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub64 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst64 || auxIntToInt64(v_1_1_0_0.AuxInt) != 64 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v.AddArg2(x, y)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Lsh64x32 x (And32 y (Const32 [63]))) (Rsh64Ux32 x (And32 (Neg32 y) (Const32 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpLsh64x32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 63 || v_1.Op != OpRsh64Ux32 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg32 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v.AddArg2(x, y)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Lsh64x32 x (And32 y (Const32 [63]))) (Rsh64Ux32 x (And32 (Sub32 (Const32 [64]) y) (Const32 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x y)
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpLsh64x32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 63 || v_1.Op != OpRsh64Ux32 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub32 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst32 || auxIntToInt32(v_1_1_0_0.AuxInt) != 64 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v.AddArg2(x, y)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Rsh64Ux64 x (And64 y (Const64 [63]))) (Lsh64x64 x (And64 (Neg64 y) (Const64 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x (Neg64 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh64Ux64 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 63 || v_1.Op != OpLsh64x64 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg64 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v0 := b.NewValue0(v.Pos, OpNeg64, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Rsh64Ux64 x (And64 y (Const64 [63]))) (Lsh64x64 x (And64 (Sub64 (Const64 [64]) y) (Const64 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x (Neg64 <y.Type> y))
 	for {
 		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
-			if v_0.Op != OpConst64 {
+			if v_0.Op != OpRsh64Ux64 {
 				continue
 			}
-			c := auxIntToInt64(v_0.AuxInt)
-			if v_1.Op != OpConst64 {
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd64 {
 				continue
 			}
-			d := auxIntToInt64(v_1.AuxInt)
-			v.reset(OpConst64)
-			v.AuxInt = int64ToAuxInt(c | d)
-			return true
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst64 || auxIntToInt64(v_0_1_1.AuxInt) != 63 || v_1.Op != OpLsh64x64 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd64 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub64 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst64 || auxIntToInt64(v_1_1_0_0.AuxInt) != 64 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst64 || auxIntToInt64(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v0 := b.NewValue0(v.Pos, OpNeg64, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Rsh64Ux32 x (And32 y (Const32 [63]))) (Lsh64x32 x (And32 (Neg32 y) (Const32 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x (Neg32 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh64Ux32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 63 || v_1.Op != OpLsh64x32 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpNeg32 || y != v_1_1_0.Args[0] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v0 := b.NewValue0(v.Pos, OpNeg32, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
+		}
+		break
+	}
+	// match: (Or64 (Rsh64Ux32 x (And32 y (Const32 [63]))) (Lsh64x32 x (And32 (Sub32 (Const32 [64]) y) (Const32 [63]))))
+	// cond: canRotate(config, 64)
+	// result: (RotateLeft64 x (Neg32 <y.Type> y))
+	for {
+		for _i0 := 0; _i0 <= 1; _i0, v_0, v_1 = _i0+1, v_1, v_0 {
+			if v_0.Op != OpRsh64Ux32 {
+				continue
+			}
+			_ = v_0.Args[1]
+			x := v_0.Args[0]
+			v_0_1 := v_0.Args[1]
+			if v_0_1.Op != OpAnd32 {
+				continue
+			}
+			_ = v_0_1.Args[1]
+			v_0_1_0 := v_0_1.Args[0]
+			v_0_1_1 := v_0_1.Args[1]
+			for _i1 := 0; _i1 <= 1; _i1, v_0_1_0, v_0_1_1 = _i1+1, v_0_1_1, v_0_1_0 {
+				y := v_0_1_0
+				if v_0_1_1.Op != OpConst32 || auxIntToInt32(v_0_1_1.AuxInt) != 63 || v_1.Op != OpLsh64x32 {
+					continue
+				}
+				_ = v_1.Args[1]
+				if x != v_1.Args[0] {
+					continue
+				}
+				v_1_1 := v_1.Args[1]
+				if v_1_1.Op != OpAnd32 {
+					continue
+				}
+				_ = v_1_1.Args[1]
+				v_1_1_0 := v_1_1.Args[0]
+				v_1_1_1 := v_1_1.Args[1]
+				for _i2 := 0; _i2 <= 1; _i2, v_1_1_0, v_1_1_1 = _i2+1, v_1_1_1, v_1_1_0 {
+					if v_1_1_0.Op != OpSub32 {
+						continue
+					}
+					_ = v_1_1_0.Args[1]
+					v_1_1_0_0 := v_1_1_0.Args[0]
+					if v_1_1_0_0.Op != OpConst32 || auxIntToInt32(v_1_1_0_0.AuxInt) != 64 || y != v_1_1_0.Args[1] || v_1_1_1.Op != OpConst32 || auxIntToInt32(v_1_1_1.AuxInt) != 63 || !(canRotate(config, 64)) {
+						continue
+					}
+					v.reset(OpRotateLeft64)
+					v0 := b.NewValue0(v.Pos, OpNeg32, y.Type)
+					v0.AddArg(y)
+					v.AddArg2(x, v0)
+					return true
+				}
+			}
 		}
 		break
 	}
@@ -20996,6 +21774,48 @@ func rewriteValuegeneric_OpSelect0(v *Value) bool {
 		v.AddArg2(lo, y)
 		return true
 	}
+	// match: (Select0 (AtomicLoadRelaxed32 ptr1 (AtomicStoreRelaxed32 ptr2 x _)))
+	// cond: isSamePtr(ptr1, ptr2)
+	// result: x
+	for {
+		if v_0.Op != OpAtomicLoadRelaxed32 {
+			break
+		}
+		_ = v_0.Args[1]
+		ptr1 := v_0.Args[0]
+		v_0_1 := v_0.Args[1]
+		if v_0_1.Op != OpAtomicStoreRelaxed32 {
+			break
+		}
+		x := v_0_1.Args[1]
+		ptr2 := v_0_1.Args[0]
+		if !(isSamePtr(ptr1, ptr2)) {
+			break
+		}
+		v.copyOf(x)
+		return true
+	}
+	// match: (Select0 (AtomicLoadRelaxed64 ptr1 (AtomicStoreRelaxed64 ptr2 x _)))
+	// cond: isSamePtr(ptr1, ptr2)
+	// result: x
+	for {
+		if v_0.Op != OpAtomicLoadRelaxed64 {
+			break
+		}
+		_ = v_0.Args[1]
+		ptr1 := v_0.Args[0]
+		v_0_1 := v_0.Args[1]
+		if v_0_1.Op != OpAtomicStoreRelaxed64 {
+			break
+		}
+		x := v_0_1.Args[1]
+		ptr2 := v_0_1.Args[0]
+		if !(isSamePtr(ptr1, ptr2)) {
+			break
+		}
+		v.copyOf(x)
+		return true
+	}
 	return false
 }
 func rewriteValuegeneric_OpSelect1(v *Value) bool {
@@ -22139,128 +22959,13 @@ func rewriteValuegeneric_OpStore(v *Value) bool {
 		v.copyOf(mem)
 		return true
 	}
-	// match: (Store _ (StructMake0) mem)
-	// result: mem
-	for {
-		if v_1.Op != OpStructMake0 {
-			break
-		}
-		mem := v_2
-		v.copyOf(mem)
-		return true
-	}
-	// match: (Store dst (StructMake1 <t> f0) mem)
-	// result: (Store {t.FieldType(0)} (OffPtr <t.FieldType(0).PtrTo()> [0] dst) f0 mem)
-	for {
-		dst := v_0
-		if v_1.Op != OpStructMake1 {
-			break
-		}
-		t := v_1.Type
-		f0 := v_1.Args[0]
-		mem := v_2
-		v.reset(OpStore)
-		v.Aux = typeToAux(t.FieldType(0))
-		v0 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v0.AuxInt = int64ToAuxInt(0)
-		v0.AddArg(dst)
-		v.AddArg3(v0, f0, mem)
-		return true
-	}
-	// match: (Store dst (StructMake2 <t> f0 f1) mem)
-	// result: (Store {t.FieldType(1)} (OffPtr <t.FieldType(1).PtrTo()> [t.FieldOff(1)] dst) f1 (Store {t.FieldType(0)} (OffPtr <t.FieldType(0).PtrTo()> [0] dst) f0 mem))
-	for {
-		dst := v_0
-		if v_1.Op != OpStructMake2 {
-			break
-		}
-		t := v_1.Type
-		f1 := v_1.Args[1]
-		f0 := v_1.Args[0]
-		mem := v_2
-		v.reset(OpStore)
-		v.Aux = typeToAux(t.FieldType(1))
-		v0 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(1).PtrTo())
-		v0.AuxInt = int64ToAuxInt(t.FieldOff(1))
-		v0.AddArg(dst)
-		v1 := b.NewValue0(v.Pos, OpStore, types.TypeMem)
-		v1.Aux = typeToAux(t.FieldType(0))
-		v2 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v2.AuxInt = int64ToAuxInt(0)
-		v2.AddArg(dst)
-		v1.AddArg3(v2, f0, mem)
-		v.AddArg3(v0, f1, v1)
-		return true
-	}
-	// match: (Store dst (StructMake3 <t> f0 f1 f2) mem)
-	// result: (Store {t.FieldType(2)} (OffPtr <t.FieldType(2).PtrTo()> [t.FieldOff(2)] dst) f2 (Store {t.FieldType(1)} (OffPtr <t.FieldType(1).PtrTo()> [t.FieldOff(1)] dst) f1 (Store {t.FieldType(0)} (OffPtr <t.FieldType(0).PtrTo()> [0] dst) f0 mem)))
-	for {
-		dst := v_0
-		if v_1.Op != OpStructMake3 {
-			break
-		}
-		t := v_1.Type
-		f2 := v_1.Args[2]
-		f0 := v_1.Args[0]
-		f1 := v_1.Args[1]
-		mem := v_2
-		v.reset(OpStore)
-		v.Aux = typeToAux(t.FieldType(2))
-		v0 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(2).PtrTo())
-		v0.AuxInt = int64ToAuxInt(t.FieldOff(2))
-		v0.AddArg(dst)
-		v1 := b.NewValue0(v.Pos, OpStore, types.TypeMem)
-		v1.Aux = typeToAux(t.FieldType(1))
-		v2 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(1).PtrTo())
-		v2.AuxInt = int64ToAuxInt(t.FieldOff(1))
-		v2.AddArg(dst)
-		v3 := b.NewValue0(v.Pos, OpStore, types.TypeMem)
-		v3.Aux = typeToAux(t.FieldType(0))
-		v4 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v4.AuxInt = int64ToAuxInt(0)
-		v4.AddArg(dst)
-		v3.AddArg3(v4, f0, mem)
-		v1.AddArg3(v2, f1, v3)
-		v.AddArg3(v0, f2, v1)
-		return true
-	}
-	// match: (Store dst (StructMake4 <t> f0 f1 f2 f3) mem)
-	// result: (Store {t.FieldType(3)} (OffPtr <t.FieldType(3).PtrTo()> [t.FieldOff(3)] dst) f3 (Store {t.FieldType(2)} (OffPtr <t.FieldType(2).PtrTo()> [t.FieldOff(2)] dst) f2 (Store {t.FieldType(1)} (OffPtr <t.FieldType(1).PtrTo()> [t.FieldOff(1)] dst) f1 (Store {t.FieldType(0)} (OffPtr <t.FieldType(0).PtrTo()> [0] dst) f0 mem))))
+	// match: (Store _ (StructMake ___) _)
+	// result: rewriteStructStore(v)
 	for {
-		dst := v_0
-		if v_1.Op != OpStructMake4 {
+		if v_1.Op != OpStructMake {
 			break
 		}
-		t := v_1.Type
-		f3 := v_1.Args[3]
-		f0 := v_1.Args[0]
-		f1 := v_1.Args[1]
-		f2 := v_1.Args[2]
-		mem := v_2
-		v.reset(OpStore)
-		v.Aux = typeToAux(t.FieldType(3))
-		v0 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(3).PtrTo())
-		v0.AuxInt = int64ToAuxInt(t.FieldOff(3))
-		v0.AddArg(dst)
-		v1 := b.NewValue0(v.Pos, OpStore, types.TypeMem)
-		v1.Aux = typeToAux(t.FieldType(2))
-		v2 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(2).PtrTo())
-		v2.AuxInt = int64ToAuxInt(t.FieldOff(2))
-		v2.AddArg(dst)
-		v3 := b.NewValue0(v.Pos, OpStore, types.TypeMem)
-		v3.Aux = typeToAux(t.FieldType(1))
-		v4 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(1).PtrTo())
-		v4.AuxInt = int64ToAuxInt(t.FieldOff(1))
-		v4.AddArg(dst)
-		v5 := b.NewValue0(v.Pos, OpStore, types.TypeMem)
-		v5.Aux = typeToAux(t.FieldType(0))
-		v6 := b.NewValue0(v.Pos, OpOffPtr, t.FieldType(0).PtrTo())
-		v6.AuxInt = int64ToAuxInt(0)
-		v6.AddArg(dst)
-		v5.AddArg3(v6, f0, mem)
-		v3.AddArg3(v4, f1, v5)
-		v1.AddArg3(v2, f2, v3)
-		v.AddArg3(v0, f3, v1)
+		v.copyOf(rewriteStructStore(v))
 		return true
 	}
 	// match: (Store {t} dst (Load src mem) mem)
@@ -22775,104 +23480,15 @@ func rewriteValuegeneric_OpStructSelect(v *Value) bool {
 	v_0 := v.Args[0]
 	b := v.Block
 	fe := b.Func.fe
-	// match: (StructSelect (StructMake1 x))
-	// result: x
-	for {
-		if v_0.Op != OpStructMake1 {
-			break
-		}
-		x := v_0.Args[0]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [0] (StructMake2 x _))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 0 || v_0.Op != OpStructMake2 {
-			break
-		}
-		x := v_0.Args[0]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [1] (StructMake2 _ x))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 1 || v_0.Op != OpStructMake2 {
-			break
-		}
-		x := v_0.Args[1]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [0] (StructMake3 x _ _))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 0 || v_0.Op != OpStructMake3 {
-			break
-		}
-		x := v_0.Args[0]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [1] (StructMake3 _ x _))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 1 || v_0.Op != OpStructMake3 {
-			break
-		}
-		x := v_0.Args[1]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [2] (StructMake3 _ _ x))
-	// result: x
+	// match: (StructSelect [i] x:(StructMake ___))
+	// result: x.Args[i]
 	for {
-		if auxIntToInt64(v.AuxInt) != 2 || v_0.Op != OpStructMake3 {
-			break
-		}
-		x := v_0.Args[2]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [0] (StructMake4 x _ _ _))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 0 || v_0.Op != OpStructMake4 {
-			break
-		}
-		x := v_0.Args[0]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [1] (StructMake4 _ x _ _))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 1 || v_0.Op != OpStructMake4 {
-			break
-		}
-		x := v_0.Args[1]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [2] (StructMake4 _ _ x _))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 2 || v_0.Op != OpStructMake4 {
-			break
-		}
-		x := v_0.Args[2]
-		v.copyOf(x)
-		return true
-	}
-	// match: (StructSelect [3] (StructMake4 _ _ _ x))
-	// result: x
-	for {
-		if auxIntToInt64(v.AuxInt) != 3 || v_0.Op != OpStructMake4 {
+		i := auxIntToInt64(v.AuxInt)
+		x := v_0
+		if x.Op != OpStructMake {
 			break
 		}
-		x := v_0.Args[3]
-		v.copyOf(x)
+		v.copyOf(x.Args[i])
 		return true
 	}
 	// match: (StructSelect [i] x:(Load <t> ptr mem))
@@ -110,6 +110,11 @@ func tighten(f *Func) {
 					continue
 				}
 				targetloop := loops.b2l[t.ID]
+				origloop := origloop
+				if v.isPCRelAddr() {
+					// Compute it once, before any loop.
+					origloop = nil
+				}
 				for targetloop != nil && (origloop == nil || targetloop.depth > origloop.depth) {
 					t = idom[targetloop.header.ID]
 					target[v.ID] = t
@@ -141,6 +146,15 @@ func tighten(f *Func) {
 	}
 }
 
+// isPCRelAddr reports whether v computes the address of a global
+// in position-independent 386 code. Each such address is computed
+// from the PC, which 386 code can only load by calling a thunk, so it
+// is worth keeping the address in a register, and computing it
+// outside of loops.
+func (v *Value) isPCRelAddr() bool {
+	return v.Op == Op386LEAL && v.Args[0].Op == OpSB && v.Block.Func.Config.ctxt.Flag_shared
+}
+
 // phiTighten moves constants closer to phi users.
 // This pass avoids having lots of constants live for lots of the program.
 // See issue 16407.
@@ -38,44 +38,82 @@ func needwb(v *Value, zeroes map[ID]ZeroRegion) bool {
 	if IsStackAddr(v.Args[0]) {
 		return false // write on stack doesn't need write barrier
 	}
+	if v.Op == OpZero && isKnownZero(v.Args[0], t.Size(), zeroes[v.MemoryArg().ID]) {
+		// Zeroing memory that is already zero writes no pointers and overwrites none.
+		return false
+	}
 	if v.Op == OpMove && IsReadOnlyGlobalAddr(v.Args[1]) {
 		if mem, ok := IsNewObject(v.Args[0]); ok && mem == v.MemoryArg() {
 			// Copying data from readonly memory into a fresh object doesn't need a write barrier.
 			return false
 		}
 	}
-	if v.Op == OpStore && IsGlobalAddr(v.Args[1]) {
-		// Storing pointers to non-heap locations into zeroed memory doesn't need a write barrier.
-		ptr := v.Args[0]
-		var off int64
-		size := v.Aux.(*types.Type).Size()
-		for ptr.Op == OpOffPtr {
-			off += ptr.AuxInt
-			ptr = ptr.Args[0]
-		}
-		ptrSize := v.Block.Func.Config.PtrSize
-		if off%ptrSize != 0 || size%ptrSize != 0 {
-			v.Fatalf("unaligned pointer write")
-		}
-		if off < 0 || off+size > 64*ptrSize {
-			// write goes off end of tracked offsets
-			return true
-		}
+	if v.Op == OpStore {
 		z := zeroes[v.MemoryArg().ID]
-		if ptr != z.base {
-			return true
-		}
-		for i := off; i < off+size; i += ptrSize {
-			if z.mask>>uint(i/ptrSize)&1 == 0 {
-				return true // not known to be zero
+		if IsGlobalAddr(v.Args[1]) || z.base != nil && basePtr(v.Args[1]) == z.base {
+			// Storing pointers to non-heap locations, or to the
+			// object itself, into zeroed memory doesn't need a
+			// write barrier: no pointer is overwritten, and the
+			// stored pointer can't make anything reachable that
+			// wasn't already.
+			ptr := v.Args[0]
+			size := t.Size()
+			ptrSize := v.Block.Func.Config.PtrSize
+			if off := offsetFrom(ptr); off%ptrSize != 0 || size%ptrSize != 0 {
+				v.Fatalf("unaligned pointer write")
+			}
+			if isKnownZero(ptr, size, z) {
+				// All written locations are known to be zero - write barrier not needed.
+				return false
 			}
 		}
-		// All written locations are known to be zero - write barrier not needed.
+	}
+	return true
+}
+
+// isKnownZero reports whether the size bytes at ptr are known to be
+// zero according to z.
+func isKnownZero(ptr *Value, size int64, z ZeroRegion) bool {
+	ptrSize := ptr.Block.Func.Config.PtrSize
+	off := offsetFrom(ptr)
+	for ptr.Op == OpOffPtr {
+		ptr = ptr.Args[0]
+	}
+	if ptr != z.base || off%ptrSize != 0 || size%ptrSize != 0 {
+		return false
+	}
+	if off < 0 || off+size > 64*ptrSize {
+		// write goes off end of tracked offsets
 		return false
 	}
+	for i := off; i < off+size; i += ptrSize {
+		if z.mask>>uint(i/ptrSize)&1 == 0 {
+			return false // not known to be zero
+		}
+	}
 	return true
 }
 
+// offsetFrom returns the constant offset of ptr from the base
+// of its chain of OffPtr ops.
+func offsetFrom(ptr *Value) int64 {
+	var off int64
+	for ptr.Op == OpOffPtr {
+		off += ptr.AuxInt
+		ptr = ptr.Args[0]
+	}
+	return off
+}
+
+// basePtr returns the pointer that v is derived from by pointer
+// arithmetic, or v itself.
+func basePtr(v *Value) *Value {
+	for v.Op == OpOffPtr || v.Op == OpAddPtr || v.Op == OpPtrIndex || v.Op == OpCopy {
+		v = v.Args[0]
+	}
+	return v
+}
+
 // writebarrier pass inserts write barriers for store ops (Store, Move, Zero)
 // when necessary (the condition above). It rewrites store ops to branches
 // and runtime calls, like
@@ -87,7 +125,8 @@ func needwb(v *Value, zeroes map[ID]ZeroRegion) bool {
 // }
 //
 // A sequence of WB stores for many pointer fields of a single type will
-// be emitted together, with a single branch.
+// be emitted together, with a single branch, even if stores that don't
+// need a write barrier are interleaved with them.
 func writebarrier(f *Func) {
 	if !f.fe.UseWriteBarrier() {
 		return
@@ -165,6 +204,12 @@ func writebarrier(f *Func) {
 				}
 			case OpVarDef, OpVarLive, OpVarKill:
 				continue
+			case OpStore:
+				// Plain stores between WB stores are included in
+				// the sequence and emitted in both branches, so that
+				// stores to pointer and non-pointer fields of an
+				// object share a single write barrier test.
+				continue
 			default:
 				if last == nil {
 					continue
@@ -301,6 +346,8 @@ func writebarrier(f *Func) {
 				}
 				// Note that we set up a writebarrier function call.
 				f.fe.SetWBPos(pos)
+			case OpStore:
+				memThen = bThen.NewValue3A(pos, OpStore, types.TypeMem, w.Aux, ptr, w.Args[1], memThen)
 			case OpVarDef, OpVarLive, OpVarKill:
 				memThen = bThen.NewValue1A(pos, w.Op, types.TypeMem, w.Aux, memThen)
 			}
@@ -315,6 +362,8 @@ func writebarrier(f *Func) {
 			case OpZeroWB:
 				memElse = bElse.NewValue2I(pos, OpZero, types.TypeMem, w.AuxInt, ptr, memElse)
 				memElse.Aux = w.Aux
+			case OpStore:
+				memElse = bElse.NewValue3A(pos, OpStore, types.TypeMem, w.Aux, ptr, w.Args[1], memElse)
 			case OpVarDef, OpVarLive, OpVarKill:
 				memElse = bElse.NewValue1A(pos, w.Op, types.TypeMem, w.Aux, memElse)
 			}
@@ -407,7 +456,16 @@ func (f *Func) computeZeroMap() map[ID]ZeroRegion {
 			// Note: iterating forwards helps convergence, as values are
 			// typically (but not always!) in store order.
 			for _, v := range b.Values {
-				if v.Op != OpStore {
+				switch v.Op {
+				case OpStore, OpMove, OpZero:
+				case OpVarDef, OpVarKill, OpVarLive:
+					// Markers for stack variables don't touch the heap.
+					if z, ok := zeroes[v.MemoryArg().ID]; ok && zeroes[v.ID] != z {
+						zeroes[v.ID] = z
+						changed = true
+					}
+					continue
+				default:
 					continue
 				}
 				z, ok := zeroes[v.MemoryArg().ID]
@@ -422,36 +480,49 @@ func (f *Func) computeZeroMap() map[ID]ZeroRegion {
 					ptr = ptr.Args[0]
 				}
 				if ptr != z.base {
-					// Different base object - we don't know anything.
-					// We could even be writing to the base object we know
-					// about, but through an aliased but offset pointer.
-					// So we have to throw all the zero information we have away.
-					continue
-				}
-				// Round to cover any partially written pointer slots.
-				// Pointer writes should never be unaligned like this, but non-pointer
-				// writes to pointer-containing types will do this.
-				if d := off % ptrSize; d != 0 {
-					off -= d
-					size += d
-				}
-				if d := size % ptrSize; d != 0 {
-					size += ptrSize - d
-				}
-				// Clip to the 64 words that we track.
-				min := off
-				max := off + size
-				if min < 0 {
-					min = 0
-				}
-				if max > 64*ptrSize {
-					max = 64 * ptrSize
-				}
-				// Clear bits for parts that we are writing (and hence
-				// will no longer necessarily be zero).
-				for i := min; i < max; i += ptrSize {
-					bit := i / ptrSize
-					z.mask &^= 1 << uint(bit)
+					// Different base object - we don't know anything,
+					// unless the write is known not to alias the base
+					// object and can't make it reachable from the heap.
+					// Otherwise we could even be writing to the base
+					// object we know about, but through an aliased but
+					// offset pointer. So we have to throw all the zero
+					// information we have away.
+					if !isDisjointWrite(v, ptr, z.base) {
+						continue
+					}
+				} else if v.Op == OpZero {
+					// Mark the words that are completely zeroed.
+					for i := off; i+ptrSize <= off+size && i < 64*ptrSize; i += ptrSize {
+						if i >= 0 && i%ptrSize == 0 {
+							z.mask |= 1 << uint(i/ptrSize)
+						}
+					}
+				} else {
+					// Round to cover any partially written pointer slots.
+					// Pointer writes should never be unaligned like this, but non-pointer
+					// writes to pointer-containing types will do this.
+					if d := off % ptrSize; d != 0 {
+						off -= d
+						size += d
+					}
+					if d := size % ptrSize; d != 0 {
+						size += ptrSize - d
+					}
+					// Clip to the 64 words that we track.
+					min := off
+					max := off + size
+					if min < 0 {
+						min = 0
+					}
+					if max > 64*ptrSize {
+						max = 64 * ptrSize
+					}
+					// Clear bits for parts that we are writing (and hence
+					// will no longer necessarily be zero).
+					for i := min; i < max; i += ptrSize {
+						bit := i / ptrSize
+						z.mask &^= 1 << uint(bit)
+					}
 				}
 				if z.mask == 0 {
 					// No more known zeros - don't bother keeping.
@@ -477,6 +548,30 @@ func (f *Func) computeZeroMap() map[ID]ZeroRegion {
 	return zeroes
 }
 
+// isDisjointWrite reports whether the store op v, whose destination
+// is offset from ptr, certainly doesn't write to the object at base,
+// and doesn't store a pointer that could make base reachable by other
+// goroutines.
+func isDisjointWrite(v, ptr, base *Value) bool {
+	if IsStackAddr(ptr) {
+		// Our stack is neither part of base nor visible to other goroutines.
+		return true
+	}
+	if _, ok := IsNewObject(ptr); !ok && !IsGlobalAddr(ptr) {
+		return false
+	}
+	// ptr is a global or a different fresh object, so it can't overlap
+	// base. It may already be reachable by others, though, so only
+	// allow values that can't be (or contain) base.
+	switch v.Op {
+	case OpZero:
+		return true
+	case OpMove:
+		return !v.Aux.(*types.Type).HasPointers()
+	}
+	return !v.Args[1].Type.HasPointers() || IsGlobalAddr(v.Args[1])
+}
+
 // wbcall emits write barrier runtime call in b, returns memory.
 func wbcall(pos src.XPos, b *Block, fn, typ *obj.LSym, ptr, val, mem, sp, sb *Value) *Value {
 	config := b.Func.Config
@@ -0,0 +1,330 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/ssa"
+	"cmd/internal/src"
+)
+
+// Function contracts.
+//
+// A function marked //go:pure must not have side effects visible to
+// its caller other than its results: it may not assign to
+// package-level variables, store to memory it did not allocate
+// itself, operate on channels, print, start goroutines, or call
+// functions that are not themselves //go:pure.
+//
+// A function marked //go:noalloc must not allocate heap memory once
+// optimized: escape analysis must keep its allocations on the stack,
+// and it may only call functions that are themselves //go:noalloc.
+//
+// Both pragmas are recorded in export data with the function's other
+// pragmas, so the contracts hold across package boundaries.
+
+// CheckPure reports the statements in fn, a //go:pure function, that
+// violate its contract. It must run after inlining, so that calls
+// inlined into fn are checked as part of its body, and after escape
+// analysis.
+func CheckPure(fn *ir.Func) {
+	if fn.Pragma&ir.Pure == 0 || len(fn.Body) == 0 {
+		return
+	}
+	c := pureChecker{fn: fn, fresh: freshLocals(fn)}
+	ir.VisitList(fn.Body, c.node)
+}
+
+type pureChecker struct {
+	fn *ir.Func
+
+	// fresh is the set of local pointer, slice, map and channel
+	// variables that only ever refer to memory fn allocated.
+	fresh map[*ir.Name]bool
+}
+
+func (c *pureChecker) errorf(pos src.XPos, format string, args ...interface{}) {
+	base.ErrorfAt(pos, "//go:pure function %v "+format, append([]interface{}{c.fn.Nname}, args...)...)
+}
+
+func (c *pureChecker) node(n ir.Node) {
+	switch n := n.(type) {
+	case *ir.AssignStmt:
+		if n.Def || n.Y == nil {
+			// Initializing a new variable.
+			return
+		}
+		c.store(n.Pos(), n.X)
+	case *ir.AssignOpStmt:
+		c.store(n.Pos(), n.X)
+	case *ir.AssignListStmt:
+		for _, x := range n.Lhs {
+			c.store(n.Pos(), x)
+		}
+	case *ir.RangeStmt:
+		c.store(n.Pos(), n.Key)
+		c.store(n.Pos(), n.Value)
+
+	case *ir.CallExpr:
+		switch n.Op() {
+		case ir.OAPPEND:
+			if len(n.Args) > 0 && !isFresh(n.Args[0], c.fresh) {
+				c.errorf(n.Pos(), "appends to %v, which it did not allocate", n.Args[0])
+			}
+		case ir.ODELETE:
+			if len(n.Args) > 0 && !isFresh(n.Args[0], c.fresh) {
+				c.errorf(n.Pos(), "deletes from %v, which it did not allocate", n.Args[0])
+			}
+		case ir.OPRINT, ir.OPRINTN:
+			c.errorf(n.Pos(), "writes to standard error")
+		case ir.OCALLFUNC, ir.OCALLMETH:
+			if clo, ok := n.X.(*ir.ClosureExpr); ok {
+				// A function literal called directly, including
+				// the wrappers of go and defer statements.
+				ir.VisitList(clo.Func.Body, c.node)
+				return
+			}
+			callee := staticCallee(n)
+			switch {
+			case callee == nil:
+				c.errorf(n.Pos(), "makes an indirect call")
+			case callee.Func == nil || callee.Func.Pragma&ir.Pure == 0:
+				c.errorf(n.Pos(), "calls %v, which is not //go:pure", callee)
+			}
+		case ir.OCALLINTER:
+			c.errorf(n.Pos(), "makes an indirect call")
+		}
+	case *ir.BinaryExpr:
+		if n.Op() == ir.OCOPY && !isFresh(n.X, c.fresh) {
+			c.errorf(n.Pos(), "copies to %v, which it did not allocate", n.X)
+		}
+	case *ir.UnaryExpr:
+		switch n.Op() {
+		case ir.OCLOSE:
+			c.errorf(n.Pos(), "closes a channel")
+		case ir.ORECV:
+			c.errorf(n.Pos(), "receives from a channel")
+		}
+	case *ir.SendStmt:
+		c.errorf(n.Pos(), "sends on a channel")
+	case *ir.GoDeferStmt:
+		if n.Op() == ir.OGO {
+			c.errorf(n.Pos(), "starts a goroutine")
+		}
+	}
+}
+
+// store checks an assignment to x at pos.
+func (c *pureChecker) store(pos src.XPos, x ir.Node) {
+	if x == nil || ir.IsBlank(x) {
+		return
+	}
+	root := x
+	for {
+		switch r := root.(type) {
+		case *ir.ParenExpr:
+			root = r.X
+			continue
+		case *ir.ConvExpr:
+			if r.Op() == ir.OCONVNOP {
+				root = r.X
+				continue
+			}
+		case *ir.SelectorExpr:
+			if r.Op() == ir.ODOT {
+				root = r.X
+				continue
+			}
+		case *ir.IndexExpr:
+			if r.Op() == ir.OINDEX && r.X.Type().IsArray() {
+				root = r.X
+				continue
+			}
+		}
+		break
+	}
+
+	switch r := root.(type) {
+	case *ir.Name:
+		switch {
+		case r.Class == ir.PEXTERN:
+			c.errorf(pos, "assigns to package-level variable %v", r)
+		case r.Esc() == ir.EscHeap:
+			c.errorf(pos, "assigns to %v, which escapes to the heap", r)
+		}
+		return
+	case *ir.StarExpr:
+		root = r.X
+	case *ir.SelectorExpr: // ODOTPTR
+		root = r.X
+	case *ir.IndexExpr: // OINDEX of a slice, OINDEXMAP
+		root = r.X
+	default:
+		return
+	}
+	if !isFresh(root, c.fresh) {
+		c.errorf(pos, "stores through %v, which it did not allocate", root)
+	}
+}
+
+// staticCallee returns the function n calls, or nil if n is an
+// indirect call.
+func staticCallee(n *ir.CallExpr) *ir.Name {
+	switch n.X.Op() {
+	case ir.ONAME:
+		if name := n.X.(*ir.Name); name.Class == ir.PFUNC {
+			return name
+		}
+	case ir.ODOTMETH, ir.OMETHEXPR:
+		return ir.MethodExprName(n.X)
+	}
+	return nil
+}
+
+// freshLocals returns the local variables of fn that only ever refer
+// to memory fn allocated, or to fn's own stack variables.
+func freshLocals(fn *ir.Func) map[*ir.Name]bool {
+	fresh := make(map[*ir.Name]bool)
+	for _, n := range fn.Dcl {
+		if n.Class == ir.PAUTO && !n.Addrtaken() && n.Type().HasPointers() {
+			fresh[n] = true
+		}
+	}
+
+	// Start by assuming every candidate is fresh, and remove those
+	// assigned a value that may not be until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		forget := func(x ir.Node) {
+			if name, ok := x.(*ir.Name); ok && fresh[name] {
+				delete(fresh, name)
+				changed = true
+			}
+		}
+		ir.VisitList(fn.Body, func(n ir.Node) {
+			switch n := n.(type) {
+			case *ir.AssignStmt:
+				if n.Y != nil && !isFresh(n.Y, fresh) {
+					forget(n.X)
+				}
+			case *ir.AssignOpStmt:
+				forget(n.X)
+			case *ir.AssignListStmt:
+				for i, x := range n.Lhs {
+					if n.Op() != ir.OAS2 || !isFresh(n.Rhs[i], fresh) {
+						forget(x)
+					}
+				}
+			case *ir.RangeStmt:
+				if n.Key != nil {
+					forget(n.Key)
+				}
+				if n.Value != nil {
+					forget(n.Value)
+				}
+			}
+		})
+	}
+	return fresh
+}
+
+// isFresh reports whether the memory x refers to was allocated by
+// the function being checked.
+func isFresh(x ir.Node, fresh map[*ir.Name]bool) bool {
+	switch x.Op() {
+	case ir.ONIL, ir.ONEW, ir.OPTRLIT, ir.OSLICELIT, ir.OMAPLIT,
+		ir.OMAKESLICE, ir.OMAKESLICECOPY, ir.OMAKEMAP, ir.OMAKECHAN,
+		ir.OSTR2BYTES, ir.OSTR2RUNES:
+		return true
+	case ir.ONAME:
+		return fresh[x.(*ir.Name)]
+	case ir.OPAREN:
+		return isFresh(x.(*ir.ParenExpr).X, fresh)
+	case ir.OCONVNOP:
+		return isFresh(x.(*ir.ConvExpr).X, fresh)
+	case ir.OAPPEND:
+		return isFresh(x.(*ir.CallExpr).Args[0], fresh)
+	case ir.OSLICE, ir.OSLICE3, ir.OSLICEARR, ir.OSLICE3ARR:
+		return isFresh(x.(*ir.SliceExpr).X, fresh)
+	case ir.OINLCALL:
+		x := x.(*ir.InlinedCallExpr)
+		return len(x.ReturnVars) == 1 && isFresh(x.ReturnVars[0], fresh)
+	case ir.OADDR:
+		// The address of a local variable that stays on the stack.
+		root := ir.OuterValue(x.(*ir.AddrExpr).X)
+		name, ok := root.(*ir.Name)
+		return ok && name.Class == ir.PAUTO && name.Esc() != ir.EscHeap
+	}
+	return false
+}
+
+var (
+	noallocErrorsMu sync.Mutex // protects noallocErrors
+	noallocErrors   []noallocError
+)
+
+// A noallocError is a violation of a //go:noalloc contract found
+// while compiling a function.
+type noallocError struct {
+	pos src.XPos
+	msg string
+}
+
+func noallocErrorf(fn *ir.Func, pos src.XPos, format string, args ...interface{}) {
+	if !pos.IsKnown() {
+		pos = fn.Pos()
+	}
+	msg := fmt.Sprintf("//go:noalloc function %v "+format, append([]interface{}{fn.Nname}, args...)...)
+	noallocErrorsMu.Lock()
+	noallocErrors = append(noallocErrors, noallocError{pos, msg})
+	noallocErrorsMu.Unlock()
+}
+
+// checkNoAllocCall checks a call made by s.curfn, a //go:noalloc
+// function. callee is the called function, or nil for an indirect
+// call.
+func (s *state) checkNoAllocCall(n *ir.CallExpr, callee *ir.Name) {
+	switch {
+	case callee == nil:
+		noallocErrorf(s.curfn, n.Pos(), "makes an indirect call")
+	case callee.Sym().Pkg == ir.Pkgs.Runtime:
+		// Runtime calls that allocate are found by checkNoAlloc.
+	case callee.Func == nil || callee.Func.Pragma&ir.NoAlloc == 0:
+		noallocErrorf(s.curfn, n.Pos(), "calls %v, which is not //go:noalloc", callee)
+	}
+}
+
+// checkNoAlloc reports the heap allocations that remain in f, the
+// optimized code of a //go:noalloc function.
+func checkNoAlloc(fn *ir.Func, f *ssa.Func) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			call, ok := v.Aux.(*ssa.AuxCall)
+			if !ok {
+				continue
+			}
+			if e, ok := ssa.RuntimeEffects(call.Fn); ok && e&ir.EffectAllocates != 0 {
+				noallocErrorf(fn, v.Pos, "allocates: calls %s", call.Fn.Name)
+			}
+		}
+	}
+}
+
+// CheckNoAlloc reports the //go:noalloc violations found while
+// compiling functions.
+func CheckNoAlloc() {
+	sort.Slice(noallocErrors, func(i, j int) bool {
+		return noallocErrors[i].pos.Before(noallocErrors[j].pos)
+	})
+	for _, err := range noallocErrors {
+		base.ErrorfAt(err.pos, "%s", err.msg)
+	}
+	noallocErrors = nil
+}
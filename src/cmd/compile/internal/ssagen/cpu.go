@@ -0,0 +1,127 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"fmt"
+	"go/constant"
+	"internal/buildcfg"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+)
+
+// A cpuVariant is a copy of a //go:cpu function compiled
+// for a higher GOAMD64 level than the rest of the package.
+type cpuVariant struct {
+	level int
+	fn    *ir.Func // bodyless declaration that owns the variant's symbol
+}
+
+// cpuVariants maps each //go:cpu function to its variants, highest
+// level first. It is filled in by InsertCPUDispatch before the
+// backend starts and is read-only afterwards.
+var cpuVariants = map[*ir.Func][]cpuVariant{}
+
+// cpuLevels returns the GOAMD64 levels fn asks to be compiled for
+// with //go:cpu, highest first, leaving out levels the whole package
+// is already compiled for. With -Os or -O1, fn is only compiled for
+// the package's level.
+func cpuLevels(fn *ir.Func) []int {
+	if buildcfg.GOARCH != "amd64" || base.SmallCode() {
+		return nil
+	}
+	var levels []int
+	for _, l := range []struct {
+		flag  ir.PragmaFlag
+		level int
+	}{
+		{ir.CPUAMD64V4, 4},
+		{ir.CPUAMD64V3, 3},
+		{ir.CPUAMD64V2, 2},
+	} {
+		if fn.Pragma&l.flag != 0 && l.level > buildcfg.GOAMD64 {
+			levels = append(levels, l.level)
+		}
+	}
+	return levels
+}
+
+// InsertCPUDispatch declares the variants of a //go:cpu function fn
+// and prepends to fn's body a call to the best variant the CPU
+// supports, checking levels from highest to lowest:
+//
+//	if cpuDispatch(3) {
+//		return fn.amd64v3(params...)
+//	}
+//
+// The variants are compiled from fn's own body by Compile, in which
+// cpuDispatch is always false. InsertCPUDispatch must be called with
+// ir.CurFunc set to fn, before fn is walked.
+func InsertCPUDispatch(fn *ir.Func) {
+	levels := cpuLevels(fn)
+	if len(levels) == 0 {
+		return
+	}
+
+	pos := fn.Pos()
+	ft := fn.Type()
+	var init ir.Nodes
+	var args []ir.Node
+	for _, f := range ft.Params().FieldSlice() {
+		if arg, ok := f.Nname.(*ir.Name); ok && !ir.IsBlank(arg) {
+			args = append(args, arg)
+			continue
+		}
+		// The body can't refer to an unnamed or blank parameter,
+		// so pass a zero value in its place.
+		tmp := typecheck.Temp(f.Type)
+		init.Append(typecheck.Stmt(ir.NewAssignStmt(pos, tmp, nil)))
+		args = append(args, tmp)
+	}
+
+	var dispatch []ir.Node
+	for _, level := range levels {
+		v := declareCPUVariant(fn, level)
+		cpuVariants[fn] = append(cpuVariants[fn], cpuVariant{level, v})
+
+		call := ir.NewCallExpr(pos, ir.OCALL, v.Nname, append([]ir.Node(nil), args...))
+		call.IsDDD = ft.IsVariadic()
+		body := []ir.Node{call, ir.NewReturnStmt(pos, nil)}
+		if ft.NumResults() > 0 {
+			body = []ir.Node{ir.NewReturnStmt(pos, []ir.Node{call})}
+		}
+		cond := ir.NewCallExpr(pos, ir.OCALL, typecheck.LookupRuntime("cpuDispatch"), []ir.Node{ir.NewBasicLit(pos, constant.MakeInt64(int64(level)))})
+		nif := ir.NewIfStmt(pos, cond, body, nil)
+		nif.Likely = true
+		dispatch = append(dispatch, typecheck.Stmt(nif))
+	}
+	fn.Body.Prepend(append(init, dispatch...)...)
+}
+
+// declareCPUVariant returns a bodyless function with the same
+// signature and attributes as fn, whose symbol receives fn compiled
+// for the given GOAMD64 level.
+func declareCPUVariant(fn *ir.Func, level int) *ir.Func {
+	sym := typecheck.Lookup(fmt.Sprintf("%s.amd64v%d", fn.Sym().Name, level))
+	if sym.Def != nil {
+		base.FatalfAt(fn.Pos(), "go:cpu variant %v already declared", sym)
+	}
+	v := ir.NewFunc(fn.Pos())
+	v.Nname = ir.NewNameAt(fn.Pos(), sym)
+	v.Nname.Class = ir.PFUNC
+	v.Nname.SetType(fn.Type())
+	v.Nname.Func = v
+	sym.Def = v.Nname
+	v.ABI = fn.ABI
+	v.Pragma = fn.Pragma &^ ir.CPULevels
+	v.SetDupok(fn.Dupok())
+	v.LSym = v.Nname.LinksymABI(v.ABI)
+	setupTextLSym(v, 0)
+	types.CalcSize(v.Type())
+	return v
+}
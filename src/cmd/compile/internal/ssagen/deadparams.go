@@ -0,0 +1,459 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"fmt"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/obj"
+)
+
+// Removal of dead parameters and results.
+//
+// The signature of an unexported function that is only ever called
+// directly, from within the package, can be changed along with its
+// calls. Parameters the function never uses are dropped, and so are
+// results none of its callers read, so that calls pass and return
+// only what is needed. A dropped named result becomes a local
+// variable of the function.
+
+// RemoveDeadParams removes the unused parameters and unread results of
+// the functions among decls that are only called directly. It must be
+// called after inlining and escape analysis, and before walk.
+func RemoveDeadParams(decls []ir.Node) {
+	if base.Flag.N != 0 || base.Flag.CompilingRuntime {
+		// The compiler calls runtime functions by signature.
+		return
+	}
+
+	calls, used := directCalls(decls)
+	exported := inlineRefs(decls)
+
+	// Find the calls whose results are all discarded, and the
+	// multi-value assignments of the others.
+	discarded := make(map[ir.Node]bool)
+	assigns := make(map[ir.Node]*ir.AssignListStmt)
+	stmts := func(list ir.Nodes) {
+		for _, n := range list {
+			discarded[n] = true
+		}
+	}
+	visitFuncs(decls, func(n ir.Node) {
+		stmts(n.Init())
+		switch n.Op() {
+		case ir.ODCLFUNC:
+			stmts(n.(*ir.Func).Body)
+		case ir.OBLOCK:
+			stmts(n.(*ir.BlockStmt).List)
+		case ir.OIF:
+			n := n.(*ir.IfStmt)
+			stmts(n.Body)
+			stmts(n.Else)
+		case ir.OFOR, ir.OFORUNTIL:
+			n := n.(*ir.ForStmt)
+			stmts(n.Body)
+			if n.Post != nil {
+				discarded[n.Post] = true
+			}
+		case ir.ORANGE:
+			stmts(n.(*ir.RangeStmt).Body)
+		case ir.OCASE:
+			switch n := n.(type) {
+			case *ir.CaseClause:
+				stmts(n.Body)
+			case *ir.CommClause:
+				stmts(n.Body)
+			}
+		case ir.OINLCALL:
+			stmts(n.(*ir.InlinedCallExpr).Body)
+		case ir.OAS:
+			if n := n.(*ir.AssignStmt); ir.IsBlank(n.X) && n.Y != nil {
+				discarded[n.Y] = true
+			}
+		case ir.OAS2FUNC:
+			n := n.(*ir.AssignListStmt)
+			assigns[n.Rhs[0]] = n
+		}
+	})
+
+	removed := make(map[*ir.Func]*deadParams)
+	for _, n := range decls {
+		fn, ok := n.(*ir.Func)
+		if !ok || used[fn] || exported[fn] || len(calls[fn]) == 0 || !canRemoveDeadParams(fn) {
+			continue
+		}
+		if d := findDeadParams(fn, calls[fn], discarded, assigns); d != nil {
+			removed[fn] = d
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	for _, n := range decls {
+		if fn, ok := n.(*ir.Func); ok && removed[fn] != nil {
+			removed[fn].rewriteFunc(fn)
+		}
+	}
+	for _, n := range decls {
+		if fn, ok := n.(*ir.Func); ok && fn.OClosure == nil {
+			rewriteDeadParamUses(fn, removed)
+		}
+	}
+}
+
+// canRemoveDeadParams reports whether fn's signature may be changed.
+func canRemoveDeadParams(fn *ir.Func) bool {
+	switch {
+	case fn.OClosure != nil, fn.Nname == nil, len(fn.Body) == 0:
+		return false
+	case types.IsExported(fn.Sym().Name), fn.Sym().Linkname != "":
+		// Calls from other packages are not visible.
+		return false
+	case fn.ABI != obj.ABIInternal, fn.ABIRefs&^obj.ABISetOf(fn.ABI) != 0:
+		// Called from assembly, through an ABI wrapper.
+		return false
+	case fn.Type().Recv() != nil, fn.Type().IsVariadic(), fn.Type().HasTParam():
+		return false
+	case fn.Dupok(), fn.Wrapper(), fn.ABIWrapper():
+		return false
+	case fn.Pragma&ir.CgoUnsafeArgs != 0, len(cpuLevels(fn)) > 0:
+		return false
+	case fn.Pragma&(ir.Noinline|ir.Nosplit) != 0:
+		// Calls are meant to reach fn as written.
+		return false
+	}
+	return true
+}
+
+// inlineRefs returns the set of functions referred to by the inline
+// bodies of the functions among decls. Other packages may call them
+// through those bodies.
+func inlineRefs(decls []ir.Node) map[*ir.Func]bool {
+	refs := make(map[*ir.Func]bool)
+	var visit func(n ir.Node)
+	visit = func(n ir.Node) {
+		switch n.Op() {
+		case ir.ONAME:
+			if n := n.(*ir.Name); n.Class == ir.PFUNC && n.Func != nil {
+				refs[n.Func] = true
+			}
+		case ir.OCLOSURE:
+			ir.VisitList(n.(*ir.ClosureExpr).Func.Body, visit)
+		}
+	}
+	for _, n := range decls {
+		if fn, ok := n.(*ir.Func); ok && fn.Inl != nil {
+			ir.VisitList(fn.Inl.Body, visit)
+		}
+	}
+	return refs
+}
+
+// visitFuncs calls do for each node of the bodies of the functions
+// among decls, including closures.
+func visitFuncs(decls []ir.Node, do func(n ir.Node)) {
+	var visit func(n ir.Node)
+	visit = func(n ir.Node) {
+		do(n)
+		if n.Op() == ir.OCLOSURE {
+			ir.Visit(n.(*ir.ClosureExpr).Func, visit)
+		}
+	}
+	for _, n := range decls {
+		if fn, ok := n.(*ir.Func); ok && fn.OClosure == nil {
+			ir.Visit(fn, visit)
+		}
+	}
+}
+
+// deadParams records the parameters and results to remove from a
+// function's signature.
+type deadParams struct {
+	params  []bool // whether each parameter is removed
+	results []bool // whether each result is removed
+}
+
+// findDeadParams returns the parameters and results to remove from
+// fn's signature, given its calls, or nil if there are none.
+func findDeadParams(fn *ir.Func, calls []*ir.CallExpr, discarded map[ir.Node]bool, assigns map[ir.Node]*ir.AssignListStmt) *deadParams {
+	ft := fn.Type()
+	refs, captured := referencedNames(fn)
+	d := &deadParams{
+		params:  make([]bool, ft.NumParams()),
+		results: make([]bool, ft.NumResults()),
+	}
+	found := false
+
+	for i, f := range ft.Params().FieldSlice() {
+		if p, ok := f.Nname.(*ir.Name); ok && refs[p] {
+			continue
+		}
+		// The argument need not be evaluated if that has no
+		// effect.
+		dead := true
+		for _, call := range calls {
+			if len(call.Args) != ft.NumParams() || !noEffectExpr(call.Args[i]) {
+				dead = false
+				break
+			}
+		}
+		d.params[i] = dead
+		found = found || dead
+	}
+
+	if !fn.HasDefer() {
+		// With no deferred calls, nothing can read a result
+		// after the function returns but its caller.
+		for i, f := range ft.Results().FieldSlice() {
+			// A result captured by a closure may be read
+			// through it, even after fn returns.
+			r, ok := f.Nname.(*ir.Name)
+			if !ok || r.Addrtaken() || !r.OnStack() || captured[r] {
+				continue
+			}
+			dead := true
+			for _, call := range calls {
+				if discarded[call] {
+					continue
+				}
+				if as := assigns[call]; as != nil && ir.IsBlank(as.Lhs[i]) {
+					continue
+				}
+				dead = false
+				break
+			}
+			d.results[i] = dead
+			found = found || dead
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	if base.Flag.LowerM > 1 {
+		for i, f := range ft.Params().FieldSlice() {
+			if d.params[i] {
+				base.WarnfAt(fn.Pos(), "removing unused parameter %s of %v", paramName(f, i), fn.Nname)
+			}
+		}
+		for i, f := range ft.Results().FieldSlice() {
+			if d.results[i] {
+				base.WarnfAt(fn.Pos(), "removing unread result %s of %v", paramName(f, i), fn.Nname)
+			}
+		}
+	}
+	return d
+}
+
+// referencedNames returns the set of names fn's body refers to,
+// directly or by capturing them in closures, and the set of those it
+// captures.
+func referencedNames(fn *ir.Func) (refs, captured map[*ir.Name]bool) {
+	refs = make(map[*ir.Name]bool)
+	captured = make(map[*ir.Name]bool)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n.Op() {
+		case ir.ONAME:
+			refs[n.(*ir.Name)] = true
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				refs[cv.Outer] = true
+				captured[cv.Outer] = true
+			}
+		}
+	})
+	return refs, captured
+}
+
+// paramName returns the name of parameter or result f at index i, for
+// messages.
+func paramName(f *types.Field, i int) string {
+	if f.Sym == nil || f.Sym.IsBlank() || strings.HasPrefix(f.Sym.Name, "~") {
+		return fmt.Sprintf("#%d", i+1)
+	}
+	return f.Sym.Name
+}
+
+// rewriteFunc removes d's parameters and results from fn's signature,
+// and its results from its return statements.
+func (d *deadParams) rewriteFunc(fn *ir.Func) {
+	ft := fn.Type()
+	keep := func(fields []*types.Field, dead []bool) []*types.Field {
+		var kept []*types.Field
+		for i, f := range fields {
+			if !dead[i] {
+				kept = append(kept, f.Copy())
+			}
+		}
+		return kept
+	}
+	nt := types.NewSignature(ft.Pkg(), nil, nil,
+		keep(ft.Params().FieldSlice(), d.params),
+		keep(ft.Results().FieldSlice(), d.results))
+	types.CalcSize(nt)
+
+	refs, _ := referencedNames(fn)
+	drop := make(map[*ir.Name]bool)
+	var init []ir.Node
+	for i, f := range ft.Params().FieldSlice() {
+		if p, ok := f.Nname.(*ir.Name); ok && d.params[i] {
+			drop[p] = true
+		}
+	}
+	for i, f := range ft.Results().FieldSlice() {
+		if r, ok := f.Nname.(*ir.Name); ok && d.results[i] {
+			if !refs[r] {
+				drop[r] = true
+				continue
+			}
+			// The body uses the result as a variable.
+			r.Class = ir.PAUTO
+			init = append(init, ir.NewAssignStmt(r.Pos(), r, nil))
+		}
+	}
+	dcl := fn.Dcl[:0]
+	for _, n := range fn.Dcl {
+		if !drop[n] {
+			dcl = append(dcl, n)
+		}
+	}
+	fn.Dcl = dcl
+	fn.Nname.SetType(nt)
+
+	ir.WithFunc(fn, func() {
+		typecheck.Stmts(init)
+		var edit func(n ir.Node) ir.Node
+		edit = func(n ir.Node) ir.Node {
+			ir.EditChildren(n, edit)
+			if n.Op() != ir.ORETURN {
+				return n
+			}
+			ret := n.(*ir.ReturnStmt)
+			if len(ret.Results) == 0 {
+				return ret
+			}
+			var kept []ir.Node
+			pure := true
+			for i, r := range ret.Results {
+				if !d.results[i] {
+					kept = append(kept, r)
+				} else if !noEffectExpr(r) {
+					pure = false
+				}
+			}
+			if pure {
+				ret.Results = kept
+				return ret
+			}
+			// Evaluate all the results, in order, before
+			// returning the ones kept.
+			tmps := make([]ir.Node, len(ret.Results))
+			kept = kept[:0]
+			for i, r := range ret.Results {
+				tmps[i] = typecheck.TempAt(r.Pos(), fn, r.Type())
+				if !d.results[i] {
+					kept = append(kept, tmps[i])
+				}
+			}
+			as := typecheck.Stmt(ir.NewAssignListStmt(ret.Pos(), ir.OAS2, tmps, ret.Results))
+			ret.Results = kept
+			return typecheck.Stmt(ir.NewBlockStmt(ret.Pos(), []ir.Node{as, ret}))
+		}
+		ir.EditChildren(fn, edit)
+	})
+	fn.Body.Prepend(init...)
+}
+
+// rewriteDeadParamUses rewrites the calls in fn, and in its closures,
+// to the functions in removed, to match their new signatures.
+func rewriteDeadParamUses(fn *ir.Func, removed map[*ir.Func]*deadParams) {
+	callee := func(n ir.Node) (*ir.CallExpr, *deadParams) {
+		if n.Op() != ir.OCALLFUNC {
+			return nil, nil
+		}
+		call := n.(*ir.CallExpr)
+		if name, ok := call.X.(*ir.Name); ok && name.Class == ir.PFUNC && name.Func != nil {
+			return call, removed[name.Func]
+		}
+		return nil, nil
+	}
+
+	var edit func(n ir.Node) ir.Node
+	edit = func(n ir.Node) ir.Node {
+		if n.Op() == ir.OCLOSURE {
+			ir.EditChildren(n.(*ir.ClosureExpr).Func, edit)
+		}
+		ir.EditChildren(n, edit)
+		switch n.Op() {
+		case ir.OCALLFUNC:
+			call, d := callee(n)
+			if d == nil {
+				break
+			}
+			var args []ir.Node
+			for i, arg := range call.Args {
+				if !d.params[i] {
+					args = append(args, arg)
+				}
+			}
+			call.Args = args
+			switch rt := call.X.Type().Results(); rt.NumFields() {
+			case 0:
+				call.SetType(nil)
+			case 1:
+				call.SetType(rt.Field(0).Type)
+			default:
+				call.SetType(rt)
+			}
+
+		case ir.OAS:
+			// _ = f(), with f's result removed.
+			n := n.(*ir.AssignStmt)
+			if n.Y == nil {
+				break
+			}
+			if call, d := callee(n.Y); d != nil && call.Type() == nil {
+				call.PtrInit().Prepend(n.Init()...)
+				return call
+			}
+
+		case ir.OAS2FUNC:
+			n := n.(*ir.AssignListStmt)
+			call, d := callee(n.Rhs[0])
+			if d == nil {
+				break
+			}
+			var lhs []ir.Node
+			for i, l := range n.Lhs {
+				if !d.results[i] {
+					lhs = append(lhs, l)
+				}
+			}
+			switch len(lhs) {
+			case 0:
+				call.PtrInit().Prepend(n.Init()...)
+				return call
+			case 1:
+				as := ir.NewAssignStmt(n.Pos(), lhs[0], call)
+				as.Def = n.Def
+				as.SetInit(n.Init())
+				as.SetTypecheck(1)
+				if l, ok := lhs[0].(*ir.Name); ok && l.Defn == n {
+					l.Defn = as
+				}
+				return as
+			}
+			n.Lhs = lhs
+		}
+		return n
+	}
+	ir.EditChildren(fn, edit)
+}
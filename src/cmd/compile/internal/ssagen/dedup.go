@@ -0,0 +1,220 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"fmt"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/objw"
+	"cmd/compile/internal/ssa"
+	"cmd/compile/internal/types"
+	"cmd/internal/obj"
+)
+
+// Deduplication of identical functions.
+//
+// With -d=dedupfuncs, a function whose optimized code is identical to
+// that of a function compiled before it is not emitted again. Its
+// symbol instead gets a single instruction that jumps to the other
+// function's body, which behaves exactly as its own would have.
+// Generated code and trivial methods on types of the same shape are
+// the usual sources of such duplicates. With -d=dedupfuncs=2, the
+// compiler also reports each duplicate it finds.
+//
+// Debug flags disable the concurrent backend, so functions are
+// compiled one at a time in a deterministic order, and the first of
+// a set of identical functions is always the same one.
+
+// dedupFuncs maps the code of each function compiled so far, as
+// described by dedupKey, to the first function with that code.
+var dedupFuncs = map[string]*ir.Func{}
+
+// dedup returns the function compiled earlier whose code is identical
+// to f, the optimized code of fn, or nil if there is none.
+func dedup(fn *ir.Func, f *ssa.Func) *ir.Func {
+	if base.Debug.DedupFuncs == 0 || !canDedup(fn) {
+		return nil
+	}
+	key := dedupKey(fn, f)
+	canon := dedupFuncs[key]
+	if canon == nil {
+		dedupFuncs[key] = fn
+		return nil
+	}
+	if base.Debug.DedupFuncs > 1 {
+		base.WarnfAt(fn.Pos(), "%v has the same code as %v", fn.Nname, canon.Nname)
+	}
+	return canon
+}
+
+// canDedup reports whether fn may share the body of another function.
+func canDedup(fn *ir.Func) bool {
+	switch {
+	case base.Flag.CompilingRuntime:
+		// The runtime identifies some of its functions by name.
+		return false
+	case fn.Dupok(), fn.Wrapper(), fn.ABIWrapper():
+		return false
+	case len(cpuVariants[fn]) > 0, len(specializations[fn]) > 0:
+		// fn.LSym changes while its variants are compiled.
+		return false
+	}
+	return true
+}
+
+// emitAlias emits fn, whose code is identical to canon's, as a jump
+// to canon.
+func emitAlias(fn, canon *ir.Func, f *ssa.Func, worker int) {
+	pp := objw.NewProgs(fn, worker)
+	defer pp.Free()
+	pp.Text.To.Type = obj.TYPE_TEXTSIZE
+	pp.Text.To.Val = int32(types.Rnd(f.OwnAux.ArgWidth(), int64(types.RegSize)))
+	pp.Text.To.Offset = 0
+
+	p := pp.Prog(obj.ARET)
+	p.To.Type = obj.TYPE_MEM
+	p.To.Name = obj.NAME_EXTERN
+	p.To.Sym = canon.LSym
+
+	// The alias has no frame, so it cannot grow the stack, and none
+	// of fn's variables live in it.
+	fn.LSym.Set(obj.AttrNoSplit, true)
+	fn.Dcl = nil
+	pp.Flush()
+}
+
+// dedupKey returns a description of f, the optimized code of fn, that
+// is the same for two functions exactly when they can share a body.
+// It leaves out source positions and the names of local variables,
+// and describes types only by what the generated code depends on:
+// their size, alignment and pointer layout.
+func dedupKey(fn *ir.Func, f *ssa.Func) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "abi=%v pragma=%#x ctxt=%v frame=%d args=%d\n",
+		fn.ABI, fn.Pragma, fn.Needctxt(), f.Frontend().(*ssafn).stksize, f.OwnAux.ArgWidth())
+	for _, fields := range []*types.Type{fn.Type().Recvs(), fn.Type().Params(), fn.Type().Results()} {
+		b.WriteString(typeShape(fields))
+		b.WriteByte('\n')
+	}
+
+	// Number blocks, values and locals in order of appearance.
+	blocks := make(map[ssa.ID]int)
+	for i, blk := range f.Blocks {
+		blocks[blk.ID] = i
+	}
+	values := make(map[ssa.ID]int)
+	locals := make(map[*ir.Name]int)
+	name := func(n *ir.Name) string {
+		if n.Class == ir.PEXTERN || n.Class == ir.PFUNC {
+			return n.Linksym().Name
+		}
+		i, ok := locals[n]
+		if !ok {
+			i = len(locals)
+			locals[n] = i
+		}
+		return fmt.Sprintf("local%d(%v %d %s)", i, n.Class, n.FrameOffset(), typeShape(n.Type()))
+	}
+	aux := func(x interface{}) string {
+		switch x := x.(type) {
+		case nil:
+			return ""
+		case *ir.Name:
+			return name(x)
+		case *ssa.AuxNameOffset:
+			return fmt.Sprintf("%s+%d", name(x.Name), x.Offset)
+		case *ssa.AuxCall:
+			return fmt.Sprintf("call(%v %d)", x.Fn, x.ArgWidth())
+		case *obj.LSym:
+			return x.Name
+		case *types.Type:
+			return typeShape(x)
+		}
+		return fmt.Sprintf("%T(%v)", x, x)
+	}
+	var loc func(l ssa.Location) string
+	loc = func(l ssa.Location) string {
+		switch l := l.(type) {
+		case nil:
+			return ""
+		case ssa.LocalSlot:
+			return fmt.Sprintf("%s+%d", name(l.N), l.Off)
+		case ssa.LocPair:
+			return fmt.Sprintf("<%s,%s>", loc(l[0]), loc(l[1]))
+		}
+		return l.String()
+	}
+
+	for _, blk := range f.Blocks {
+		for _, v := range blk.Values {
+			values[v.ID] = len(values)
+		}
+	}
+	for _, blk := range f.Blocks {
+		fmt.Fprintf(&b, "b%d: %v %d %s", blocks[blk.ID], blk.Kind, blk.AuxInt, aux(blk.Aux))
+		for _, c := range blk.ControlValues() {
+			fmt.Fprintf(&b, " v%d", values[c.ID])
+		}
+		for _, e := range blk.Succs {
+			fmt.Fprintf(&b, " ->b%d", blocks[e.Block().ID])
+		}
+		fmt.Fprintf(&b, " %v\n", blk.Likely)
+		for _, v := range blk.Values {
+			fmt.Fprintf(&b, "v%d = %v %s %d %s", values[v.ID], v.Op, typeShape(v.Type), v.AuxInt, aux(v.Aux))
+			for _, a := range v.Args {
+				fmt.Fprintf(&b, " v%d", values[a.ID])
+			}
+			if int(v.ID) < len(f.RegAlloc) {
+				fmt.Fprintf(&b, " : %s", loc(f.RegAlloc[v.ID]))
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// typeShape describes the size, alignment and pointer layout of t.
+func typeShape(t *types.Type) string {
+	switch {
+	case t == nil:
+		return "nil"
+	case t.IsMemory(), t.IsFlags(), t.IsVoid():
+		return t.String()
+	case t.IsTuple():
+		return "(" + typeShape(t.FieldType(0)) + "," + typeShape(t.FieldType(1)) + ")"
+	case t.IsResults():
+		var s []string
+		for i := 0; i < t.NumFields(); i++ {
+			s = append(s, typeShape(t.FieldType(i)))
+		}
+		return "(" + strings.Join(s, ",") + ")"
+	case t.IsPtrShaped():
+		return "ptr"
+	}
+	switch t.Kind() {
+	case types.TSTRING:
+		return "string"
+	case types.TSLICE:
+		return "slice"
+	case types.TINTER:
+		return "iface"
+	case types.TARRAY:
+		return fmt.Sprintf("[%d]%s", t.NumElem(), typeShape(t.Elem()))
+	case types.TSTRUCT:
+		var b strings.Builder
+		b.WriteByte('{')
+		for _, f := range t.Fields().Slice() {
+			fmt.Fprintf(&b, "%d:%s;", f.Offset, typeShape(f.Type))
+		}
+		fmt.Fprintf(&b, "}%d/%d", t.Size(), t.Alignment())
+		return b.String()
+	case types.TFLOAT32, types.TFLOAT64, types.TCOMPLEX64, types.TCOMPLEX128:
+		return fmt.Sprintf("float%d", t.Size())
+	}
+	return fmt.Sprintf("int%d", t.Size())
+}
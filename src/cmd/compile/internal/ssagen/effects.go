@@ -0,0 +1,251 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"sort"
+	"sync"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+)
+
+// Function effect summaries.
+//
+// The effects pass of the SSA backend summarizes what each function
+// may do that its callers can observe, from its optimized code (see
+// ssa.effects). Calls to functions outside the runtime are recorded
+// while building SSA instead, and once every function has been
+// compiled, ComputeEffects adds the summaries of the callees to those
+// of their callers and stores the result in Func.Effects. The summary
+// is then written to export data, so that a package calling the
+// function knows it too.
+
+// effectsInfo is what compiling a function tells about its effects.
+type effectsInfo struct {
+	fn       *ir.Func
+	own      ir.FuncEffects // effects of the function's own code
+	callees  []*ir.Name     // functions it calls directly
+	indirect bool           // whether it makes other calls
+}
+
+var (
+	effectsMu    sync.Mutex // protects effectsInfos
+	effectsInfos []*effectsInfo
+)
+
+// noteCall records a call made by the function being compiled, to
+// callee, or an indirect call if callee is nil.
+func (s *state) noteCall(callee *ir.Name) {
+	switch {
+	case callee == nil:
+		s.indirectCalls = true
+	case callee.Sym().Pkg == ir.Pkgs.Runtime:
+		// Summarized by the effects pass.
+	case specializationOf[callee.Func] != nil:
+		// A specialized copy has the effects of the function
+		// it copies.
+		s.callees = append(s.callees, specializationOf[callee.Func].Nname)
+	default:
+		s.callees = append(s.callees, callee)
+	}
+}
+
+// recordEffects records what compiling fn into f tells about its
+// effects.
+func (s *state) recordEffects(fn *ir.Func) {
+	info := &effectsInfo{
+		fn:       fn,
+		own:      s.f.Effects,
+		callees:  s.callees,
+		indirect: s.indirectCalls || fn.HasDefer(),
+	}
+	effectsMu.Lock()
+	effectsInfos = append(effectsInfos, info)
+	effectsMu.Unlock()
+}
+
+// ComputeEffects computes the effect summaries of the functions
+// compiled so far. It must be called after compiling them, and
+// before writing export data.
+func ComputeEffects() {
+	infos := effectsInfos
+	effectsInfos = nil
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].fn.Pos().Before(infos[j].fn.Pos())
+	})
+
+	effects := make(map[*ir.Func]ir.FuncEffects)
+	for _, info := range infos {
+		effects[info.fn] = info.own
+	}
+	// A recursive call may not return, like a loop.
+	for _, info := range recursiveEffects(infos) {
+		effects[info.fn] |= ir.EffectBlocks
+	}
+	calleeEffects := func(callee *ir.Name) ir.FuncEffects {
+		fn := callee.Func
+		if fn == nil {
+			return ir.EffectsAll
+		}
+		if e, ok := effects[fn]; ok {
+			return e
+		}
+		if fn.Effects&ir.EffectsKnown != 0 {
+			return fn.Effects
+		}
+		return ir.EffectsAll
+	}
+
+	// Effects only accumulate, so iterating until nothing changes
+	// handles recursion.
+	for changed := true; changed; {
+		changed = false
+		for _, info := range infos {
+			e := effects[info.fn]
+			if info.indirect {
+				e |= ir.EffectsAll
+			}
+			for _, callee := range info.callees {
+				e |= calleeEffects(callee)
+			}
+			e &^= ir.EffectsKnown
+			if e != effects[info.fn] {
+				effects[info.fn] = e
+				changed = true
+			}
+		}
+	}
+
+	for _, info := range infos {
+		info.fn.Effects = effects[info.fn] | ir.EffectsKnown
+		// Don't report wrappers or the generated package initializer.
+		if base.Debug.Effects != 0 && !info.fn.Wrapper() && info.fn.Sym().Name != "init" {
+			base.WarnfAt(info.fn.Pos(), "%v effects: %v", info.fn.Nname, info.fn.Effects)
+		}
+	}
+}
+
+// recursiveEffects returns the functions in infos that may call
+// themselves, directly or through other functions in infos.
+func recursiveEffects(infos []*effectsInfo) []*effectsInfo {
+	byFunc := make(map[*ir.Func]*effectsInfo, len(infos))
+	for _, info := range infos {
+		byFunc[info.fn] = info
+	}
+
+	// Tarjan's strongly connected components algorithm, as in
+	// ir.VisitFuncsBottomUp. A function is recursive if its
+	// component has more than one function, or if it calls itself.
+	var (
+		recursive []*effectsInfo
+		stack     []*effectsInfo
+		nextID    int
+		ids       = make(map[*effectsInfo]int, len(infos))
+		done      = make(map[*effectsInfo]bool, len(infos))
+	)
+	var visit func(info *effectsInfo) int
+	visit = func(info *effectsInfo) int {
+		nextID++
+		id := nextID
+		ids[info] = id
+		min := id
+		stack = append(stack, info)
+		self := false
+		for _, callee := range info.callees {
+			c := byFunc[callee.Func]
+			switch {
+			case c == nil || done[c]:
+			case c == info:
+				self = true
+			case ids[c] != 0:
+				if ids[c] < min {
+					min = ids[c]
+				}
+			default:
+				if m := visit(c); m < min {
+					min = m
+				}
+			}
+		}
+		if min == id {
+			i := len(stack) - 1
+			for stack[i] != info {
+				i--
+			}
+			scc := stack[i:]
+			stack = stack[:i]
+			for _, c := range scc {
+				done[c] = true
+			}
+			if len(scc) > 1 || self {
+				recursive = append(recursive, scc...)
+			}
+		}
+		return min
+	}
+	for _, info := range infos {
+		if ids[info] == 0 {
+			visit(info)
+		}
+	}
+	return recursive
+}
+
+// callHasNoEffect reports whether the call statement n can be
+// omitted because it can have no effect its caller could observe.
+// That is known only for functions from other packages, whose
+// summaries are in export data.
+func callHasNoEffect(n *ir.CallExpr) bool {
+	if base.Flag.N != 0 || n.X.Op() != ir.ONAME {
+		return false
+	}
+	if n.X.Type().NumResults() > 1 {
+		// The results of the call may be read by the
+		// statements that follow it.
+		return false
+	}
+	callee := n.X.(*ir.Name)
+	if callee.Class != ir.PFUNC || callee.Func == nil || callee.Sym().Pkg == types.LocalPkg {
+		return false
+	}
+	e := callee.Func.Effects
+	if e&ir.EffectsKnown == 0 || e&(ir.EffectWrites|ir.EffectPanics|ir.EffectBlocks) != 0 {
+		return false
+	}
+	for _, arg := range n.Args {
+		if !noEffectExpr(arg) {
+			return false
+		}
+	}
+	if base.Debug.Effects != 0 {
+		base.WarnfAt(n.Pos(), "removed call to %v, which has no effects", callee)
+	}
+	return true
+}
+
+// noEffectExpr reports whether evaluating n can have no effect,
+// including panicking.
+func noEffectExpr(n ir.Node) bool {
+	switch n.Op() {
+	case ir.ONAME, ir.OLITERAL, ir.ONIL, ir.OLINKSYMOFFSET:
+		return true
+	case ir.OADDR:
+		return n.(*ir.AddrExpr).X.Op() == ir.ONAME
+	case ir.OCONV, ir.OCONVNOP:
+		n := n.(*ir.ConvExpr)
+		return n.X.Type().IsScalar() && n.Type().IsScalar() && noEffectExpr(n.X)
+	case ir.ODOT:
+		return noEffectExpr(n.(*ir.SelectorExpr).X)
+	case ir.ONEG, ir.OBITNOT, ir.ONOT:
+		return noEffectExpr(n.(*ir.UnaryExpr).X)
+	case ir.OADD, ir.OSUB, ir.OMUL, ir.OAND, ir.OOR, ir.OXOR, ir.OANDNOT,
+		ir.OEQ, ir.ONE, ir.OLT, ir.OLE, ir.OGT, ir.OGE:
+		n := n.(*ir.BinaryExpr)
+		return !n.X.Type().IsInterface() && noEffectExpr(n.X) && noEffectExpr(n.Y)
+	}
+	return false
+}
@@ -9,12 +9,14 @@ import (
 	"internal/race"
 	"math/rand"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"cmd/compile/internal/base"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/objw"
+	"cmd/compile/internal/pgo"
 	"cmd/compile/internal/ssa"
 	"cmd/compile/internal/types"
 	"cmd/internal/obj"
@@ -180,7 +182,44 @@ const maxStackSize = 1 << 30
 // and flushes that plist to machine code.
 // worker indicates which of the backend workers is doing the processing.
 func Compile(fn *ir.Func, worker int) {
-	f := buildssa(fn, worker)
+	variants, specs := cpuVariants[fn], specializations[fn]
+	if len(variants) == 0 && len(specs) == 0 {
+		compile(fn, worker, buildcfg.GOAMD64, nil)
+		return
+	}
+
+	// Compiling fn drops its unused locals from fn.Dcl, so each
+	// compilation after the first starts from a copy.
+	dcl := append([]*ir.Name(nil), fn.Dcl...)
+	// The relocations and attributes walk gave fn.LSym tell the
+	// linker which types and methods fn uses dynamically. The
+	// specialized copies need them too, since fn itself may be
+	// unreachable.
+	marks := append([]obj.Reloc(nil), fn.LSym.R...)
+	compile(fn, worker, buildcfg.GOAMD64, nil)
+
+	// Compile fn's //go:cpu variants and its specialized copies
+	// from the same body, each into its own symbol.
+	lsym := fn.LSym
+	for _, v := range variants {
+		fn.LSym = v.fn.LSym
+		fn.Dcl = append([]*ir.Name(nil), dcl...)
+		compile(fn, worker, v.level, nil)
+	}
+	for _, sp := range specs {
+		fn.LSym = sp.fn.LSym
+		fn.LSym.R = append(fn.LSym.R, marks...)
+		fn.LSym.Set(obj.AttrReflectMethod, fn.ReflectMethod())
+		fn.Dcl = append([]*ir.Name(nil), dcl...)
+		compile(fn, worker, buildcfg.GOAMD64, sp)
+	}
+	fn.LSym = lsym
+}
+
+// compile compiles fn into fn.LSym, targeting the given GOAMD64 level.
+// If spec is not nil, fn.LSym is the symbol of that specialized copy.
+func compile(fn *ir.Func, worker int, goamd64 int, spec *specialization) {
+	f := buildssa(fn, worker, goamd64, spec)
 	// Note: check arg size to fix issue 25507.
 	if f.Frontend().(*ssafn).stksize >= maxStackSize || f.OwnAux.ArgWidth() >= maxStackSize {
 		largeStackFramesMu.Lock()
@@ -188,9 +227,16 @@ func Compile(fn *ir.Func, worker int) {
 		largeStackFramesMu.Unlock()
 		return
 	}
+	if canon := dedup(fn, f); canon != nil {
+		emitAlias(fn, canon, f, worker)
+		return
+	}
 	pp := objw.NewProgs(fn, worker)
 	defer pp.Free()
 	genssa(f, pp)
+	if ir.IsWhyAsmFunc(fn) {
+		reportCalls(pp)
+	}
 	// Check frame size again.
 	// The check above included only the space needed for local variables.
 	// After genssa, the space needed includes local variables and the callee arg region.
@@ -205,11 +251,51 @@ func Compile(fn *ir.Func, worker int) {
 		return
 	}
 
+	if pgo.Current != nil {
+		markHot(fn.LSym)
+	}
 	pp.Flush() // assemble, fill in boilerplate, etc.
 	// fieldtrack must be called after pp.Flush. See issue 20014.
 	fieldtrack(pp.Text.From.Sym, fn.FieldTrack)
 }
 
+// reportCalls reports, for -d=whyasm, each call instruction
+// in the generated code, distinguishing calls into the runtime.
+func reportCalls(pp *objw.Progs) {
+	type call struct {
+		pos    src.XPos
+		target string
+	}
+	seen := make(map[call]bool)
+	for p := pp.Text; p != nil; p = p.Link {
+		var c call
+		switch {
+		case p.As != obj.ACALL && p.As != obj.ADUFFZERO && p.As != obj.ADUFFCOPY:
+			continue
+		case p.To.Sym != nil:
+			c = call{p.Pos, p.To.Sym.Name}
+		default:
+			c = call{p.Pos, ""}
+		}
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		switch {
+		case c.target == "":
+			base.WarnfAt(c.pos, "indirect call")
+		case strings.HasPrefix(c.target, "runtime."):
+			base.WarnfAt(c.pos, "runtime call to %s", c.target)
+		default:
+			target := c.target
+			if strings.HasPrefix(target, `"".`) && base.Ctxt.Pkgpath != "" {
+				target = base.Ctxt.Pkgpath + target[len(`""`):]
+			}
+			base.WarnfAt(c.pos, "call to %s", strings.TrimPrefix(target, `"".`))
+		}
+	}
+}
+
 func init() {
 	if race.Enabled {
 		rand.Seed(time.Now().UnixNano())
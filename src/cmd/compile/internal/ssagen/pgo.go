@@ -0,0 +1,37 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/pgo"
+	"cmd/internal/obj"
+	"cmd/internal/objabi"
+	"cmd/internal/src"
+	"strings"
+)
+
+// lineWeight returns the weight of the -pgoprofile samples taken on
+// the source line of pos. For inlined code, that is the line in the
+// inlined function.
+func lineWeight(pos src.XPos) int64 {
+	p := base.Ctxt.InnermostPos(pos)
+	if !p.IsKnown() {
+		return 0
+	}
+	return pgo.Current.LineWeight(pgo.FileName(p), int64(p.RelLine()))
+}
+
+// markHot marks fn's symbol as hot if -pgoprofile shows that it
+// accounts for much of the program's time.
+func markHot(fn *obj.LSym) {
+	name := fn.Name
+	if strings.HasPrefix(name, `"".`) {
+		name = objabi.PathToPrefix(base.Ctxt.Pkgpath) + name[len(`""`):]
+	}
+	if pgo.Current.Hot(name) {
+		fn.Set(obj.AttrHot, true)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/ssa"
+	"cmd/compile/internal/types"
+	"cmd/internal/sys"
+)
+
+// Automatic prefetching, enabled by -d=prefetch.
+//
+// A loop that follows a chain of pointers, like
+//
+//	for p := l; p != nil; p = p.next {
+//		...
+//	}
+//
+// can't load the next element until it has loaded the current one,
+// so each iteration waits for a cache miss. Issuing a prefetch of
+// p.next at the start of the body overlaps that miss with the work
+// the body does on p. The prefetch is only a hint: it can't fault,
+// and it doesn't matter if the body changes p.next afterwards.
+
+// prefetchNext returns the expression p.f if loop follows the chain
+// of pointers p.f, p.f.f, ..., testing p against nil each time
+// around, and updating it either in the loop's post statement or at
+// the end of its body. Otherwise it returns nil.
+func prefetchNext(loop *ir.ForStmt) *ir.SelectorExpr {
+	if loop.Op() != ir.OFOR || loop.Cond == nil || loop.Cond.Op() != ir.ONE {
+		return nil
+	}
+	cond := loop.Cond.(*ir.BinaryExpr)
+	p, _ := cond.X.(*ir.Name)
+	if p == nil || cond.Y.Op() != ir.ONIL || !p.Type().IsPtr() {
+		return nil
+	}
+
+	update := unblock(loop.Post)
+	if update == nil && len(loop.Body) > 0 {
+		update = unblock(loop.Body[len(loop.Body)-1])
+	}
+	if update == nil || update.Op() != ir.OAS {
+		return nil
+	}
+	as := update.(*ir.AssignStmt)
+	if as.X != p || as.Y == nil || as.Y.Op() != ir.ODOTPTR {
+		return nil
+	}
+	next := as.Y.(*ir.SelectorExpr)
+	if next.X != p {
+		return nil
+	}
+	return next
+}
+
+// unblock returns the statement in n, which may be wrapped in blocks,
+// or nil if n is empty.
+func unblock(n ir.Node) ir.Node {
+	for n != nil && n.Op() == ir.OBLOCK {
+		list := n.(*ir.BlockStmt).List
+		switch len(list) {
+		case 0:
+			return nil
+		case 1:
+			n = list[0]
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// prefetchLoop emits a prefetch of the next element of loop, if it
+// follows a chain of pointers. It must be called at the start of the
+// loop body, where the pointer is known not to be nil.
+func (s *state) prefetchLoop(loop *ir.ForStmt) {
+	if base.Debug.Prefetch == 0 || base.Flag.N != 0 || !Arch.LinkArch.InFamily(sys.AMD64, sys.ARM64, sys.PPC64) {
+		return
+	}
+	next := prefetchNext(loop)
+	if next == nil {
+		return
+	}
+	if base.Debug.Prefetch > 1 {
+		base.WarnfAt(loop.Pos(), "prefetching %v", next)
+	}
+	addr := s.expr(next)
+	s.vars[memVar] = s.newValue2(ssa.OpPrefetchCache, types.TypeMem, addr, s.mem())
+}
@@ -0,0 +1,333 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+	"cmd/internal/obj"
+)
+
+// Specialization of functions for constant arguments.
+//
+// If every call in the package to an unexported function passes a
+// constant for some of its parameters, the function gets a copy for
+// each combination of constants passed, compiled with the parameters
+// bound to those constants so the code using them folds, and the calls
+// are redirected to the copies. Like the variants of //go:cpu
+// functions, the copies are compiled from the function's own body.
+// The function itself is still compiled as usual, for calls made
+// through exported inline bodies and from assembly. The copies are
+// named fn.specN, but the runtime reports them by fn's name, so
+// tracebacks and runtime.FuncForPC show the function as written.
+
+const (
+	// specializeMaxCopies is the maximum number of specialized
+	// copies of a function.
+	specializeMaxCopies = 4
+
+	// specializeBudget is the maximum total size, in IR nodes, of
+	// the specialized copies of a function.
+	specializeBudget = 1000
+)
+
+// A specialization is a copy of a function with some of its
+// parameters bound to constants.
+type specialization struct {
+	fn   *ir.Func             // bodyless declaration that owns the copy's symbol
+	args map[*ir.Name]ir.Node // constant value of each bound parameter
+}
+
+// arg returns the constant parameter n is bound to in sp, or nil if
+// it is not bound or sp is nil.
+func (sp *specialization) arg(n *ir.Name) ir.Node {
+	if sp == nil {
+		return nil
+	}
+	return sp.args[n]
+}
+
+var (
+	// specializations maps each specialized function to its copies.
+	// It is filled in by Specialize before the backend starts and is
+	// read-only afterwards.
+	specializations = map[*ir.Func][]*specialization{}
+
+	// specializationOf maps each copy to the function it copies.
+	specializationOf = map[*ir.Func]*ir.Func{}
+)
+
+// Specialize specializes the functions among decls that are always
+// called with constant arguments for some of their parameters, and
+// redirects their calls to the copies. It must be called after
+// inlining and escape analysis, and before walk.
+func Specialize(decls []ir.Node) {
+	if base.Flag.N != 0 || base.SmallCode() || base.Flag.CompilingRuntime {
+		// The runtime identifies some of its functions by name.
+		return
+	}
+
+	calls, used := directCalls(decls)
+	for _, n := range decls {
+		fn, ok := n.(*ir.Func)
+		if !ok || used[fn] || len(calls[fn]) == 0 || !canSpecialize(fn) {
+			continue
+		}
+		specialize(fn, calls[fn])
+	}
+}
+
+// directCalls returns the direct calls of each function, made from
+// the functions among decls, and the set of functions used in other
+// ways, like being taken as a value.
+func directCalls(decls []ir.Node) (map[*ir.Func][]*ir.CallExpr, map[*ir.Func]bool) {
+	calls := make(map[*ir.Func][]*ir.CallExpr)
+	used := make(map[*ir.Func]bool)
+	var visit func(n ir.Node)
+	visitList := func(list ir.Nodes) {
+		for _, n := range list {
+			visit(n)
+		}
+	}
+	visit = func(n ir.Node) {
+		if n == nil {
+			return
+		}
+		switch n.Op() {
+		case ir.OCALLFUNC:
+			n := n.(*ir.CallExpr)
+			if callee, ok := n.X.(*ir.Name); ok && callee.Class == ir.PFUNC && callee.Func != nil {
+				calls[callee.Func] = append(calls[callee.Func], n)
+				visitList(n.Init())
+				visitList(n.Args)
+				return
+			}
+		case ir.ONAME:
+			n := n.(*ir.Name)
+			if n.Class == ir.PFUNC && n.Func != nil {
+				used[n.Func] = true
+			}
+		case ir.OCLOSURE:
+			visitList(n.(*ir.ClosureExpr).Func.Body)
+		}
+		ir.DoChildren(n, func(c ir.Node) bool {
+			visit(c)
+			return false
+		})
+	}
+	for _, n := range decls {
+		if fn, ok := n.(*ir.Func); ok {
+			if fn.OClosure == nil {
+				visitList(fn.Body)
+			}
+			continue
+		}
+		visit(n)
+	}
+
+	return calls, used
+}
+
+// canSpecialize reports whether fn may be specialized.
+func canSpecialize(fn *ir.Func) bool {
+	switch {
+	case fn.OClosure != nil, fn.Nname == nil, len(fn.Body) == 0:
+		return false
+	case types.IsExported(fn.Sym().Name), fn.Sym().Linkname != "":
+		// Calls from other packages are not visible.
+		return false
+	case fn.Type().Recv() != nil, fn.Type().IsVariadic(), fn.Type().HasTParam():
+		return false
+	case fn.Dupok(), fn.Wrapper(), fn.ABIWrapper():
+		return false
+	case fn.Pragma&ir.CgoUnsafeArgs != 0, len(cpuLevels(fn)) > 0:
+		return false
+	case fn.Pragma&(ir.Noinline|ir.Nosplit) != 0:
+		// Calls are meant to reach fn as written, with their
+		// arguments, e.g. to show them in tracebacks, or to
+		// account for its stack use.
+		return false
+	}
+	return true
+}
+
+// specialize specializes fn for the constant arguments of calls,
+// within the budget.
+func specialize(fn *ir.Func, calls []*ir.CallExpr) {
+	// Find the parameters always passed constants.
+	var params []*ir.Name
+	var index []int
+	for i, f := range fn.Type().Params().FieldSlice() {
+		p, ok := f.Nname.(*ir.Name)
+		if !ok || ir.IsBlank(p) || p.Addrtaken() || !p.OnStack() || !TypeOK(p.Type()) {
+			continue
+		}
+		constant := true
+		for _, call := range calls {
+			if len(call.Args) != fn.Type().NumParams() || !isSpecializationArg(call.Args[i]) {
+				constant = false
+				break
+			}
+		}
+		if constant {
+			params = append(params, p)
+			index = append(index, i)
+		}
+	}
+	params, index = foldableParams(fn, params, index)
+	if len(params) == 0 {
+		return
+	}
+
+	// Group the calls by the constants they pass, most frequent
+	// first.
+	type group struct {
+		calls []*ir.CallExpr
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, call := range calls {
+		var b strings.Builder
+		for _, i := range index {
+			if arg := call.Args[i]; arg.Op() == ir.OLITERAL {
+				b.WriteString(arg.Val().ExactString())
+			} else {
+				b.WriteString("nil")
+			}
+			b.WriteByte(0)
+		}
+		key := b.String()
+		g := byKey[key]
+		if g == nil {
+			g = &group{}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.calls = append(g.calls, call)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].calls) > len(groups[j].calls)
+	})
+
+	size := 0
+	ir.VisitList(fn.Body, func(ir.Node) { size++ })
+	cost := 0
+	for _, g := range groups {
+		if len(specializations[fn]) == specializeMaxCopies || cost+size > specializeBudget {
+			break
+		}
+		cost += size
+
+		sp := &specialization{
+			fn:   declareSpecialization(fn, len(specializations[fn])+1),
+			args: make(map[*ir.Name]ir.Node),
+		}
+		var desc []string
+		for i, p := range params {
+			arg := g.calls[0].Args[index[i]]
+			sp.args[p] = arg
+			desc = append(desc, fmt.Sprintf("%v = %v", p.Sym(), arg))
+		}
+		specializations[fn] = append(specializations[fn], sp)
+		specializationOf[sp.fn] = fn
+		for _, call := range g.calls {
+			call.X = sp.fn.Nname
+		}
+		if base.Flag.LowerM != 0 {
+			base.WarnfAt(fn.Pos(), "specializing %v for %s", fn.Nname, strings.Join(desc, ", "))
+		}
+	}
+}
+
+// foldableParams returns the parameters among params, with their
+// indices, that fn uses in ways that fold when they are constants:
+// as conditions, switch tags or shift counts, or as operands along
+// with constants or other such parameters. Binding the others, like
+// a message passed on to panic, would only duplicate code.
+func foldableParams(fn *ir.Func, params []*ir.Name, index []int) ([]*ir.Name, []int) {
+	bound := make(map[ir.Node]bool, len(params))
+	for _, p := range params {
+		bound[p] = true
+	}
+	isConst := func(n ir.Node) bool {
+		return bound[n] || n.Op() == ir.OLITERAL || n.Op() == ir.ONIL
+	}
+	foldable := make(map[ir.Node]bool)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n.Op() {
+		case ir.OIF:
+			foldable[n.(*ir.IfStmt).Cond] = true
+		case ir.OFOR:
+			foldable[n.(*ir.ForStmt).Cond] = true
+		case ir.OSWITCH:
+			foldable[n.(*ir.SwitchStmt).Tag] = true
+		case ir.ONOT:
+			foldable[n.(*ir.UnaryExpr).X] = true
+		case ir.OLSH, ir.ORSH:
+			foldable[n.(*ir.BinaryExpr).Y] = true
+		case ir.OASOP:
+			if n := n.(*ir.AssignOpStmt); n.AsOp == ir.OLSH || n.AsOp == ir.ORSH {
+				foldable[n.Y] = true
+			}
+		case ir.OANDAND, ir.OOROR:
+			n := n.(*ir.LogicalExpr)
+			foldable[n.X] = true
+			foldable[n.Y] = true
+		case ir.OADD, ir.OSUB, ir.OMUL, ir.ODIV, ir.OMOD, ir.OAND, ir.OOR, ir.OXOR, ir.OANDNOT,
+			ir.OEQ, ir.ONE, ir.OLT, ir.OLE, ir.OGT, ir.OGE:
+			n := n.(*ir.BinaryExpr)
+			if isConst(n.X) && isConst(n.Y) {
+				foldable[n.X] = true
+				foldable[n.Y] = true
+			}
+		}
+	})
+
+	var fparams []*ir.Name
+	var findex []int
+	for i, p := range params {
+		if foldable[p] {
+			fparams = append(fparams, p)
+			findex = append(findex, index[i])
+		}
+	}
+	return fparams, findex
+}
+
+// isSpecializationArg reports whether n is a constant argument a
+// function can be specialized for.
+func isSpecializationArg(n ir.Node) bool {
+	return n.Op() == ir.OLITERAL || n.Op() == ir.ONIL
+}
+
+// declareSpecialization returns a bodyless function with the same
+// signature and attributes as fn, whose symbol receives fn's n'th
+// specialized copy.
+func declareSpecialization(fn *ir.Func, n int) *ir.Func {
+	sym := typecheck.Lookup(fmt.Sprintf("%s.spec%d", fn.Sym().Name, n))
+	if sym.Def != nil {
+		base.FatalfAt(fn.Pos(), "specialization %v already declared", sym)
+	}
+	v := ir.NewFunc(fn.Pos())
+	v.Nname = ir.NewNameAt(fn.Pos(), sym)
+	v.Nname.Class = ir.PFUNC
+	v.Nname.SetType(fn.Type())
+	v.Nname.SetTypecheck(1)
+	v.Nname.Func = v
+	sym.Def = v.Nname
+	v.ABI = fn.ABI
+	v.Pragma = fn.Pragma
+	v.LSym = v.Nname.LinksymABI(v.ABI)
+	setupTextLSym(v, 0)
+	v.LSym.Set(obj.AttrSpecialized, true)
+	types.CalcSize(v.Type())
+	return v
+}
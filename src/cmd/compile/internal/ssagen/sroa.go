@@ -0,0 +1,369 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssagen
+
+import (
+	"fmt"
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/ssa"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+)
+
+// Scalar replacement of aggregates.
+//
+// A local struct or array too large to be SSA-able normally lives in
+// memory. If its address is never taken, and every use of it selects
+// fields or indexes elements with constants, it is instead split into
+// its largest SSA-able parts, the leaves, each of which becomes a
+// variable of its own. Inlined calls pass their arguments and results
+// through such locals, so this lets value-oriented code using large
+// structs optimize as if it had been written with the scalars.
+//
+// Uses of a part of a split variable that is a leaf, or within one, are
+// rewritten to use the leaf's variable. Assignments to a larger part
+// assign each of its leaves, and copies between split variables copy
+// leaf by leaf. Any other use of a larger part reads a copy of it
+// stored in a temporary.
+
+// sroaMaxLeaves is the maximum number of leaves of a split variable.
+const sroaMaxLeaves = 16
+
+// An sroaNode describes a part of a split variable. A leaf has a
+// variable of its own; any other part is a struct or array, split
+// further into its fields or elements.
+type sroaNode struct {
+	t    *types.Type
+	off  int64       // offset in the split variable
+	leaf *ir.Name    // variable holding the part, if it is a leaf
+	kids []*sroaNode // fields or elements, if it is not
+}
+
+// eachLeaf calls do for each leaf of p, in order.
+func (p *sroaNode) eachLeaf(do func(l *sroaNode)) {
+	if p.leaf != nil {
+		do(p)
+		return
+	}
+	for _, k := range p.kids {
+		k.eachLeaf(do)
+	}
+}
+
+// padded reports whether p has bytes that are not in any of its
+// leaves, like the padding around fields of go:cachealign types.
+func (p *sroaNode) padded() bool {
+	var n int64
+	p.eachLeaf(func(l *sroaNode) {
+		n += l.t.Size()
+	})
+	return n != p.t.Size()
+}
+
+// sroaLeaves returns the number of leaves a variable of type t would
+// be split into, or a number greater than sroaMaxLeaves if there are
+// too many.
+func sroaLeaves(t *types.Type) int64 {
+	if TypeOK(t) {
+		return 1
+	}
+	switch {
+	case t.IsStruct():
+		var n int64
+		for _, f := range t.Fields().Slice() {
+			n += sroaLeaves(f.Type)
+			if n > sroaMaxLeaves {
+				break
+			}
+		}
+		return n
+	case t.IsArray():
+		// Arrays of small elements, like byte buffers, tend to be
+		// copied and compared whole, which is done best in memory.
+		if t.NumElem() > sroaMaxLeaves || t.Elem().Size() < int64(types.PtrSize) {
+			break
+		}
+		return t.NumElem() * sroaLeaves(t.Elem())
+	}
+	return sroaMaxLeaves + 1
+}
+
+// sroaBase returns the variable x refers to part of, if x is an
+// ONAME, or a selection of fields and array elements from one.
+func sroaBase(x ir.Node) *ir.Name {
+	for {
+		switch x.Op() {
+		case ir.ONAME:
+			return x.(*ir.Name)
+		case ir.ODOT:
+			x = x.(*ir.SelectorExpr).X
+		case ir.OINDEX:
+			n := x.(*ir.IndexExpr)
+			if !n.X.Type().IsArray() {
+				return nil
+			}
+			x = n.X
+		default:
+			return nil
+		}
+	}
+}
+
+// findSROA chooses the local variables of fn to split, and creates
+// variables for their leaves.
+func (s *state) findSROA(fn *ir.Func) {
+	if base.Flag.N != 0 {
+		return
+	}
+	cands := make(map[*ir.Name]bool)
+	for _, n := range fn.Dcl {
+		if n.Class == ir.PAUTO && !n.Addrtaken() && n.OnStack() && !TypeOK(n.Type()) && sroaLeaves(n.Type()) <= sroaMaxLeaves {
+			cands[n] = true
+		}
+	}
+	if len(cands) == 0 {
+		return
+	}
+
+	visit := func(n ir.Node) {
+		switch n.Op() {
+		case ir.OADDR:
+			// Walk takes addresses without marking the variable
+			// Addrtaken, e.g. to pass it to the runtime.
+			n := n.(*ir.AddrExpr)
+			if b := sroaBase(n.X); b != nil {
+				delete(cands, b)
+			}
+		case ir.OINDEX:
+			// Only parts that are within a leaf can be indexed
+			// with a variable.
+			n := n.(*ir.IndexExpr)
+			if n.X.Type().IsArray() && !TypeOK(n.X.Type()) && !ir.IsConst(n.Index, constant.Int) {
+				if b := sroaBase(n.X); b != nil {
+					delete(cands, b)
+				}
+			}
+		}
+	}
+	ir.VisitList(fn.Enter, visit)
+	ir.VisitList(fn.Body, visit)
+	ir.VisitList(fn.Exit, visit)
+	if len(cands) == 0 {
+		return
+	}
+
+	s.sroa = make(map[*ir.Name]*sroaNode, len(cands))
+	s.sroaSlots = make(map[*ir.Name]*ssa.LocalSlot)
+	for _, n := range fn.Dcl {
+		if !cands[n] {
+			continue
+		}
+		var slot *ssa.LocalSlot
+		if !ir.IsAutoTmp(n) {
+			// Name the leaves after the parts of n they hold,
+			// for debug information.
+			slot = &ssa.LocalSlot{N: n, Type: n.Type(), Off: 0}
+		}
+		s.sroa[n] = s.sroaSplit(n, n.Type(), 0, slot)
+	}
+}
+
+// sroaSplit creates the leaves of the part of variable n of type t at
+// offset off. If slot is not nil, it is the slot naming the part.
+func (s *state) sroaSplit(n *ir.Name, t *types.Type, off int64, slot *ssa.LocalSlot) *sroaNode {
+	p := &sroaNode{t: t, off: off}
+	if TypeOK(t) {
+		if slot != nil {
+			p.leaf = slot.N
+			s.sroaSlots[p.leaf] = slot
+		} else {
+			p.leaf = typecheck.TempAt(n.Pos(), s.curfn, t)
+		}
+		// Give the leaf a value from the start, like the
+		// stack slot of the variable would have.
+		s.vars[p.leaf] = s.zeroVal(t)
+		return p
+	}
+	switch {
+	case t.IsStruct():
+		for i, f := range t.Fields().Slice() {
+			var fslot *ssa.LocalSlot
+			if slot != nil {
+				fslot = s.f.SplitStruct(slot, i)
+			}
+			p.kids = append(p.kids, s.sroaSplit(n, f.Type, off+f.Offset, fslot))
+		}
+	case t.IsArray():
+		et := t.Elem()
+		for i := int64(0); i < t.NumElem(); i++ {
+			var eslot *ssa.LocalSlot
+			if slot != nil {
+				eslot = s.f.SplitSlot(slot, fmt.Sprintf("[%d]", i), i*et.Size(), et)
+			}
+			p.kids = append(p.kids, s.sroaSplit(n, et, off+i*et.Size(), eslot))
+		}
+	default:
+		s.Fatalf("cannot split %v of type %v", n, t)
+	}
+	return p
+}
+
+// sroaResolve reports whether n refers to part of a split variable. If
+// it does, it returns the part. If that part is within a leaf, it
+// returns the leaf instead, along with n rewritten to use the leaf's
+// variable.
+func (s *state) sroaResolve(n ir.Node) (*sroaNode, ir.Node) {
+	switch n.Op() {
+	case ir.ONAME:
+		return s.sroa[n.(*ir.Name)], nil
+	case ir.ODOT:
+		n := n.(*ir.SelectorExpr)
+		p, r := s.sroaResolve(n.X)
+		if p == nil {
+			return nil, nil
+		}
+		if r == nil && p.leaf == nil {
+			for i, f := range p.t.Fields().Slice() {
+				if f.Sym == n.Sel {
+					return p.kids[i], nil
+				}
+			}
+			s.Fatalf("no field %v in %v", n.Sel, p.t)
+		}
+		if r == nil {
+			r = p.leaf
+		}
+		c := ir.Copy(n).(*ir.SelectorExpr)
+		c.SetInit(nil)
+		c.X = r
+		return p, c
+	case ir.OINDEX:
+		n := n.(*ir.IndexExpr)
+		if !n.X.Type().IsArray() {
+			return nil, nil
+		}
+		p, r := s.sroaResolve(n.X)
+		if p == nil {
+			return nil, nil
+		}
+		if r == nil && p.leaf == nil {
+			return p.kids[ir.Int64Val(n.Index)], nil
+		}
+		if r == nil {
+			r = p.leaf
+		}
+		c := ir.Copy(n).(*ir.IndexExpr)
+		c.SetInit(nil)
+		c.X = r
+		return p, c
+	}
+	return nil, nil
+}
+
+// sroaPart returns the part of a split variable n refers to, if it is
+// larger than a leaf.
+func (s *state) sroaPart(n ir.Node) *sroaNode {
+	if len(s.sroa) == 0 {
+		return nil
+	}
+	p, r := s.sroaResolve(n)
+	if p == nil || r != nil || p.leaf != nil {
+		return nil
+	}
+	return p
+}
+
+// sroaExpr returns the value of n if it refers to part of a split
+// variable, and nil otherwise.
+func (s *state) sroaExpr(n ir.Node) *ssa.Value {
+	p, r := s.sroaResolve(n)
+	switch {
+	case p == nil:
+		return nil
+	case r != nil:
+		return s.expr(r)
+	case p.leaf != nil:
+		return s.variable(p.leaf, p.leaf.Type())
+	}
+	return s.load(n.Type(), s.sroaSpill(p))
+}
+
+// sroaSpill stores the value of split part p in a temporary, and
+// returns the temporary's address.
+func (s *state) sroaSpill(p *sroaNode) *ssa.Value {
+	_, addr := s.temp(s.peekPos(), p.t)
+	if p.padded() {
+		s.zero(p.t, addr)
+	}
+	p.eachLeaf(func(l *sroaNode) {
+		if l.t.Size() == 0 {
+			return
+		}
+		ptr := s.newValue1I(ssa.OpOffPtr, types.NewPtr(l.t), l.off-p.off, addr)
+		s.store(l.t, ptr, s.variable(l.leaf, l.t))
+	})
+	return addr
+}
+
+// sroaAssign does p = *right, or p = 0 if right is nil, for split
+// part p.
+func (s *state) sroaAssign(p *sroaNode, right *ssa.Value) {
+	p.eachLeaf(func(l *sroaNode) {
+		var v *ssa.Value
+		if right == nil {
+			v = s.zeroVal(l.t)
+		} else {
+			ptr := s.newValue1I(ssa.OpOffPtr, types.NewPtr(l.t), l.off-p.off, right)
+			v = s.load(l.t, ptr)
+		}
+		s.assign(l.leaf, v, false, 0)
+	})
+}
+
+// sroaCopy does left = right if right is a split part larger than a
+// leaf, reporting whether it did.
+func (s *state) sroaCopy(left, right ir.Node) bool {
+	src := s.sroaPart(right)
+	if src == nil {
+		return false
+	}
+	var vals []*ssa.Value
+	src.eachLeaf(func(l *sroaNode) {
+		vals = append(vals, s.variable(l.leaf, l.t))
+	})
+
+	i := 0
+	if dst := s.sroaPart(left); dst != nil {
+		dst.eachLeaf(func(l *sroaNode) {
+			s.assign(l.leaf, vals[i], false, 0)
+			i++
+		})
+		return true
+	}
+
+	// Store the leaves to memory directly, rather than
+	// through a temporary.
+	if base, ok := clobberBase(left).(*ir.Name); ok && base.OnStack() {
+		s.vars[memVar] = s.newValue1Apos(ssa.OpVarDef, types.TypeMem, base, s.mem(), !ir.IsAutoTmp(base))
+	}
+	addr := s.addr(left)
+	if left.Op() == ir.ODOTPTR {
+		s.noteNilDeref(s.peekPos(), left.(*ir.SelectorExpr))
+	}
+	if src.padded() {
+		s.zero(src.t, addr)
+	}
+	src.eachLeaf(func(l *sroaNode) {
+		if l.t.Size() != 0 {
+			ptr := s.newValue1I(ssa.OpOffPtr, types.NewPtr(l.t), l.off-src.off, addr)
+			s.storeType(l.t, ptr, vals[i], 0, !ir.IsAutoTmp(left))
+		}
+		i++
+	})
+	return true
+}
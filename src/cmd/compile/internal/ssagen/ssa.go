@@ -21,6 +21,7 @@ import (
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/liveness"
 	"cmd/compile/internal/objw"
+	"cmd/compile/internal/pgo"
 	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/ssa"
 	"cmd/compile/internal/staticdata"
@@ -97,6 +98,7 @@ func InitConfig() {
 	ir.Syms.AssertI2I = typecheck.LookupRuntimeFunc("assertI2I")
 	ir.Syms.AssertI2I2 = typecheck.LookupRuntimeFunc("assertI2I2")
 	ir.Syms.CheckPtrAlignment = typecheck.LookupRuntimeFunc("checkptrAlignment")
+	ir.Syms.Complex128div = typecheck.LookupRuntimeFunc("complex128div")
 	ir.Syms.Deferproc = typecheck.LookupRuntimeFunc("deferproc")
 	ir.Syms.DeferprocStack = typecheck.LookupRuntimeFunc("deferprocStack")
 	ir.Syms.Deferreturn = typecheck.LookupRuntimeFunc("deferreturn")
@@ -124,6 +126,7 @@ func InitConfig() {
 	ir.Syms.Racewriterange = typecheck.LookupRuntimeFunc("racewriterange")
 	ir.Syms.X86HasPOPCNT = typecheck.LookupRuntimeVar("x86HasPOPCNT")       // bool
 	ir.Syms.X86HasSSE41 = typecheck.LookupRuntimeVar("x86HasSSE41")         // bool
+	ir.Syms.X86Level = typecheck.LookupRuntimeVar("x86Level")               // uint8
 	ir.Syms.X86HasFMA = typecheck.LookupRuntimeVar("x86HasFMA")             // bool
 	ir.Syms.ARMHasVFPv4 = typecheck.LookupRuntimeVar("armHasVFPv4")         // bool
 	ir.Syms.ARM64HasATOMICS = typecheck.LookupRuntimeVar("arm64HasATOMICS") // bool
@@ -342,8 +345,15 @@ func okOffset(offset int64) int64 {
 
 // buildssa builds an SSA function for fn.
 // worker indicates which of the backend workers is doing the processing.
-func buildssa(fn *ir.Func, worker int) *ssa.Func {
+// If spec is not nil, it builds the specialized copy spec instead.
+func buildssa(fn *ir.Func, worker int, goamd64 int, spec *specialization) *ssa.Func {
 	name := ir.FuncName(fn)
+	if goamd64 != buildcfg.GOAMD64 {
+		name = fmt.Sprintf("%s.amd64v%d", name, goamd64)
+	}
+	if spec != nil {
+		name = ir.FuncName(spec.fn)
+	}
 	printssa := false
 	if ssaDump != "" { // match either a simple name e.g. "(*Reader).Reset", package.name e.g. "compress/gzip.(*Reader).Reset", or subpackage name "gzip.(*Reader).Reset"
 		pkgDotName := base.Ctxt.Pkgpath + "." + name
@@ -390,6 +400,13 @@ func buildssa(fn *ir.Func, worker int) *ssa.Func {
 	if fn.Pragma&ir.Nosplit != 0 {
 		s.f.NoSplit = true
 	}
+	s.f.FastMath = fn.Pragma&ir.FastMath != 0
+	s.f.KeepAliveCheck = base.Debug.KeepAlive != 0 && !base.Flag.CompilingRuntime && !fn.Wrapper()
+	s.f.GOAMD64 = goamd64
+	if pgo.Current != nil {
+		s.f.LineWeight = lineWeight
+	}
+	s.f.FastMinMax = fn.Pragma&(ir.FastMinMax|ir.FastMath) != 0 || base.Debug.FastMinMax != 0 && fn.Pragma&ir.StrictMinMax == 0
 	s.f.ABI0 = ssaConfig.ABI0.Copy() // Make a copy to avoid racy map operations in type-register-width cache.
 	s.f.ABI1 = ssaConfig.ABI1.Copy()
 	s.f.ABIDefault = abiForFunc(nil, s.f.ABI0, s.f.ABI1)
@@ -519,7 +536,12 @@ func buildssa(fn *ir.Func, worker int) *ssa.Func {
 	// Populate SSAable arguments.
 	for _, n := range fn.Dcl {
 		if n.Class == ir.PPARAM {
-			if s.canSSA(n) {
+			if arg := spec.arg(n); arg != nil && s.canSSA(n) {
+				// Bound to a constant in this copy.
+				v := s.expr(arg)
+				s.vars[n] = v
+				s.addNamedValue(n, v)
+			} else if s.canSSA(n) {
 				v := s.newValue0A(ssa.OpArg, n.Type(), n)
 				s.vars[n] = v
 				s.addNamedValue(n, v) // This helps with debugging information, not needed for compilation itself.
@@ -575,6 +597,8 @@ func buildssa(fn *ir.Func, worker int) *ssa.Func {
 		}
 	}
 
+	s.findSROA(fn)
+
 	// Convert the AST-based IR to the SSA-based IR
 	s.stmtList(fn.Enter)
 	s.zeroResults()
@@ -601,6 +625,13 @@ func buildssa(fn *ir.Func, worker int) *ssa.Func {
 	// Main call to ssa package to compile function
 	ssa.Compile(s.f)
 
+	if spec == nil {
+		s.recordEffects(fn)
+	}
+	if fn.Pragma&ir.NoAlloc != 0 {
+		checkNoAlloc(fn, s.f)
+	}
+
 	if s.hasOpenDefers {
 		s.emitOpenDeferInfo()
 	}
@@ -887,6 +918,11 @@ type state struct {
 	// addresses of PPARAM and PPARAMOUT variables on the stack.
 	decladdrs map[*ir.Name]*ssa.Value
 
+	// local variables split into their parts (see sroa.go), and
+	// the slots naming the parts' variables.
+	sroa      map[*ir.Name]*sroaNode
+	sroaSlots map[*ir.Name]*ssa.LocalSlot
+
 	// starting values. Memory, stack pointer, and globals pointer
 	startmem *ssa.Value
 	sp       *ssa.Value
@@ -923,6 +959,10 @@ type state struct {
 	lastDeferCount      int        // Number of defers encountered at that point
 
 	prevCall *ssa.Value // the previous call; use this to tie results to the call op.
+
+	// Calls made by the function, for its effect summary.
+	callees       []*ir.Name // static calls outside the runtime
+	indirectCalls bool       // whether it makes any other calls
 }
 
 type funcLine struct {
@@ -1431,6 +1471,9 @@ func (s *state) stmt(n ir.Node) {
 			s.intrinsicCall(n)
 			return
 		}
+		if callHasNoEffect(n) {
+			return
+		}
 		fallthrough
 
 	case ir.OCALLINTER:
@@ -1620,6 +1663,9 @@ func (s *state) stmt(n ir.Node) {
 
 		var r *ssa.Value
 		deref := !TypeOK(t)
+		if deref && rhs != nil && s.sroaCopy(n.X, rhs) {
+			return
+		}
 		if deref {
 			if rhs == nil {
 				r = nil // Signal assign to use OpZero.
@@ -1803,6 +1849,7 @@ func (s *state) stmt(n ir.Node) {
 
 		// generate body
 		s.startBlock(bBody)
+		s.prefetchLoop(n)
 		s.stmtList(n.Body)
 
 		// tear down continue/break
@@ -1891,7 +1938,7 @@ func (s *state) stmt(n ir.Node) {
 
 	case ir.OVARDEF:
 		n := n.(*ir.UnaryExpr)
-		if !s.canSSA(n.X) {
+		if !s.canSSA(n.X) && s.sroa[n.X.(*ir.Name)] == nil {
 			s.vars[memVar] = s.newValue1Apos(ssa.OpVarDef, types.TypeMem, n.X.(*ir.Name), s.mem(), false)
 		}
 	case ir.OVARKILL:
@@ -1900,7 +1947,7 @@ func (s *state) stmt(n ir.Node) {
 		// varkill in the store chain is enough to keep it correctly ordered
 		// with respect to call ops.
 		n := n.(*ir.UnaryExpr)
-		if !s.canSSA(n.X) {
+		if !s.canSSA(n.X) && s.sroa[n.X.(*ir.Name)] == nil {
 			s.vars[memVar] = s.newValue1Apos(ssa.OpVarKill, types.TypeMem, n.X.(*ir.Name), s.mem(), false)
 		}
 
@@ -2562,6 +2609,11 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 	}
 
 	s.stmtList(n.Init())
+	if len(s.sroa) != 0 {
+		if v := s.sroaExpr(n); v != nil {
+			return v
+		}
+	}
 	switch n.Op() {
 	case ir.OBYTES2STRTMP:
 		n := n.(*ir.ConvExpr)
@@ -2587,6 +2639,9 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 	case ir.ONAME:
 		n := n.(*ir.Name)
 		if n.Class == ir.PFUNC {
+			if base.Debug.Intrinsics != 0 && findIntrinsic(n.Sym()) != nil {
+				base.WarnfAt(s.peekPos(), "function value %s.%s is called out of line, not intrinsified", intrinsicPkg(n.Sym()), n.Sym().Name)
+			}
 			// "value" of a function is the address of the function's closure
 			sym := staticdata.FuncLinksym(n)
 			return s.entryNewValue1A(ssa.OpAddr, types.NewPtr(n.Type()), sym, s.sb)
@@ -2834,44 +2889,7 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 		a := s.expr(n.X)
 		b := s.expr(n.Y)
 		if n.Type().IsComplex() {
-			// TODO this is not executed because the front-end substitutes a runtime call.
-			// That probably ought to change; with modest optimization the widen/narrow
-			// conversions could all be elided in larger expression trees.
-			mulop := ssa.OpMul64F
-			addop := ssa.OpAdd64F
-			subop := ssa.OpSub64F
-			divop := ssa.OpDiv64F
-			pt := types.FloatForComplex(n.Type()) // Could be Float32 or Float64
-			wt := types.Types[types.TFLOAT64]     // Compute in Float64 to minimize cancellation error
-
-			areal := s.newValue1(ssa.OpComplexReal, pt, a)
-			breal := s.newValue1(ssa.OpComplexReal, pt, b)
-			aimag := s.newValue1(ssa.OpComplexImag, pt, a)
-			bimag := s.newValue1(ssa.OpComplexImag, pt, b)
-
-			if pt != wt { // Widen for calculation
-				areal = s.newValueOrSfCall1(ssa.OpCvt32Fto64F, wt, areal)
-				breal = s.newValueOrSfCall1(ssa.OpCvt32Fto64F, wt, breal)
-				aimag = s.newValueOrSfCall1(ssa.OpCvt32Fto64F, wt, aimag)
-				bimag = s.newValueOrSfCall1(ssa.OpCvt32Fto64F, wt, bimag)
-			}
-
-			denom := s.newValueOrSfCall2(addop, wt, s.newValueOrSfCall2(mulop, wt, breal, breal), s.newValueOrSfCall2(mulop, wt, bimag, bimag))
-			xreal := s.newValueOrSfCall2(addop, wt, s.newValueOrSfCall2(mulop, wt, areal, breal), s.newValueOrSfCall2(mulop, wt, aimag, bimag))
-			ximag := s.newValueOrSfCall2(subop, wt, s.newValueOrSfCall2(mulop, wt, aimag, breal), s.newValueOrSfCall2(mulop, wt, areal, bimag))
-
-			// TODO not sure if this is best done in wide precision or narrow
-			// Double-rounding might be an issue.
-			// Note that the pre-SSA implementation does the entire calculation
-			// in wide format, so wide is compatible.
-			xreal = s.newValueOrSfCall2(divop, wt, xreal, denom)
-			ximag = s.newValueOrSfCall2(divop, wt, ximag, denom)
-
-			if pt != wt { // Narrow to store back
-				xreal = s.newValueOrSfCall1(ssa.OpCvt64Fto32F, pt, xreal)
-				ximag = s.newValueOrSfCall1(ssa.OpCvt64Fto32F, pt, ximag)
-			}
-			return s.newValue2(ssa.OpComplexMake, n.Type(), xreal, ximag)
+			return s.complexDivide(n, a, b)
 		}
 		if n.Type().IsFloat() {
 			return s.newValueOrSfCall2(s.ssaOp(n.Op(), n.Type()), a.Type, a, b)
@@ -3038,12 +3056,14 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 
 	case ir.ODOTPTR:
 		n := n.(*ir.SelectorExpr)
+		s.noteNilDeref(n.Pos(), n)
 		p := s.exprPtr(n.X, n.Bounded(), n.Pos())
 		p = s.newValue1I(ssa.OpOffPtr, types.NewPtr(n.Type()), n.Offset(), p)
 		return s.load(n.Type(), p)
 
 	case ir.OINDEX:
 		n := n.(*ir.IndexExpr)
+		s.noteBounds(n)
 		switch {
 		case n.X.Type().IsString():
 			if n.Bounded() && ir.IsConst(n.X, constant.String) && ir.IsConst(n.Index, constant.Int) {
@@ -3144,6 +3164,7 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 
 	case ir.OSLICE, ir.OSLICEARR, ir.OSLICE3, ir.OSLICE3ARR:
 		n := n.(*ir.SliceExpr)
+		s.noteBounds(n)
 		check := s.checkPtrEnabled && n.Op() == ir.OSLICE3ARR && n.X.Op() == ir.OCONVNOP && n.X.(*ir.ConvExpr).X.Type().IsUnsafePtr()
 		v := s.exprCheckPtr(n.X, !check)
 		var i, j, k *ssa.Value
@@ -3165,6 +3186,7 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 
 	case ir.OSLICESTR:
 		n := n.(*ir.SliceExpr)
+		s.noteBounds(n)
 		v := s.expr(n.X)
 		var i, j *ssa.Value
 		if n.Low != nil {
@@ -3182,6 +3204,7 @@ func (s *state) exprCheckPtr(n ir.Node, checkPtrOK bool) *ssa.Value {
 		// }
 		// slice.ptr
 		n := n.(*ir.ConvExpr)
+		s.noteBounds(n)
 		v := s.expr(n.X)
 		arrlen := s.constInt(types.Types[types.TINT], n.Type().Elem().NumElem())
 		cap := s.newValue1(ssa.OpSliceLen, types.Types[types.TINT], v)
@@ -3481,6 +3504,22 @@ func (s *state) condBranch(cond ir.Node, yes, no *ssa.Block, likely int8) {
 	b.Likely = ssa.BranchPrediction(likely) // gc and ssa both use -1/0/+1 for likeliness
 	b.AddEdgeTo(yes)
 	b.AddEdgeTo(no)
+	if base.Debug.ErrChecks != 0 && isNilErrorCheck(cond) {
+		s.f.ErrorChecks = append(s.f.ErrorChecks, ssa.ErrorCheck{Block: b, Yes: yes, Pos: cond.Pos(), Cond: fmt.Sprint(cond)})
+	}
+}
+
+// isNilErrorCheck reports whether cond compares an error with nil.
+func isNilErrorCheck(n ir.Node) bool {
+	if n.Op() != ir.OEQ && n.Op() != ir.ONE {
+		return false
+	}
+	cond := n.(*ir.BinaryExpr)
+	x, y := cond.X, cond.Y
+	if ir.IsNil(x) {
+		x, y = y, x
+	}
+	return ir.IsNil(y) && x.Type() == types.ErrorType
 }
 
 type skipMask uint8
@@ -3500,6 +3539,22 @@ func (s *state) assign(left ir.Node, right *ssa.Value, deref bool, skip skipMask
 	if left.Op() == ir.ONAME && ir.IsBlank(left) {
 		return
 	}
+	if len(s.sroa) != 0 {
+		if p, r := s.sroaResolve(left); p != nil {
+			switch {
+			case r != nil:
+				left = r
+			case p.leaf != nil:
+				left = p.leaf
+			default:
+				if !deref {
+					s.Fatalf("assigning SSA value to split %v", left)
+				}
+				s.sroaAssign(p, right)
+				return
+			}
+		}
+	}
 	t := left.Type()
 	types.CalcSize(t)
 	if s.canSSA(left) {
@@ -3526,7 +3581,7 @@ func (s *state) assign(left ir.Node, right *ssa.Value, deref bool, skip skipMask
 			old := s.expr(left.X)
 
 			// Make new structure.
-			new := s.newValue0(ssa.StructMakeOp(t.NumFields()), t)
+			new := s.newValue0(ssa.OpStructMake, t)
 
 			// Add fields as args.
 			for i := 0; i < nf; i++ {
@@ -3584,6 +3639,11 @@ func (s *state) assign(left ir.Node, right *ssa.Value, deref bool, skip skipMask
 
 	// Left is not ssa-able. Compute its address.
 	addr := s.addr(left)
+	if left.Op() == ir.ODOTPTR {
+		// The store itself may do the nil check, at the
+		// assignment's position.
+		s.noteNilDeref(s.peekPos(), left.(*ir.SelectorExpr))
+	}
 	if ir.IsReflectHeaderDataField(left) {
 		// Package unsafe's documentation says storing pointers into
 		// reflect.SliceHeader and reflect.StringHeader's Data fields
@@ -3654,7 +3714,7 @@ func (s *state) zeroVal(t *types.Type) *ssa.Value {
 		return s.constSlice(t)
 	case t.IsStruct():
 		n := t.NumFields()
-		v := s.entryNewValue0(ssa.StructMakeOp(t.NumFields()), t)
+		v := s.entryNewValue0(ssa.OpStructMake, t)
 		for i := 0; i < n; i++ {
 			v.AddArg(s.zeroVal(t.FieldType(i)))
 		}
@@ -3791,6 +3851,7 @@ func InitTables() {
 	var p4 []*sys.Arch
 	var p8 []*sys.Arch
 	var lwatomics []*sys.Arch
+	var weakatomics []*sys.Arch
 	for _, a := range &sys.Archs {
 		all = append(all, a)
 		if a.PtrSize == 4 {
@@ -3801,6 +3862,9 @@ func InitTables() {
 		if a.Family != sys.PPC64 {
 			lwatomics = append(lwatomics, a)
 		}
+		if a.Family != sys.AMD64 && a.Family != sys.S390X {
+			weakatomics = append(weakatomics, a)
+		}
 	}
 
 	// add adds the intrinsic b for pkg.fn for the given list of architectures.
@@ -3857,6 +3921,36 @@ func InitTables() {
 			return s.newValue2(ssa.OpMul64uover, types.NewTuple(types.Types[types.TUINT], types.Types[types.TUINT]), args[0], args[1])
 		},
 		sys.AMD64, sys.I386, sys.MIPS64, sys.RISCV64)
+	addF("runtime/internal/math", "AddInt64",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			return s.newValue2(ssa.OpAdd64over, types.NewTuple(types.Types[types.TINT64], types.Types[types.TBOOL]), args[0], args[1])
+		},
+		sys.AMD64)
+	addF("runtime/internal/math", "SubInt64",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			return s.newValue2(ssa.OpSub64over, types.NewTuple(types.Types[types.TINT64], types.Types[types.TBOOL]), args[0], args[1])
+		},
+		sys.AMD64)
+	addF("runtime/internal/math", "AddSat64",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			// sum | -carry
+			t := types.Types[types.TUINT64]
+			v := s.newValue3(ssa.OpAdd64carry, types.NewTuple(t, t), args[0], args[1], s.constInt64(t, 0))
+			sum := s.newValue1(ssa.OpSelect0, t, v)
+			carry := s.newValue1(ssa.OpSelect1, t, v)
+			return s.newValue2(ssa.OpOr64, t, sum, s.newValue1(ssa.OpNeg64, t, carry))
+		},
+		sys.AMD64, sys.ARM64, sys.PPC64, sys.S390X)
+	addF("runtime/internal/math", "SubSat64",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			// diff &^ -borrow
+			t := types.Types[types.TUINT64]
+			v := s.newValue3(ssa.OpSub64borrow, types.NewTuple(t, t), args[0], args[1], s.constInt64(t, 0))
+			diff := s.newValue1(ssa.OpSelect0, t, v)
+			borrow := s.newValue1(ssa.OpSelect1, t, v)
+			return s.newValue2(ssa.OpAnd64, t, diff, s.newValue1(ssa.OpCom64, t, s.newValue1(ssa.OpNeg64, t, borrow)))
+		},
+		sys.AMD64, sys.ARM64, sys.S390X)
 	add("runtime", "KeepAlive",
 		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
 			data := s.newValue1(ssa.OpIData, s.f.Config.Types.BytePtr, args[0])
@@ -3882,6 +3976,34 @@ func InitTables() {
 		},
 		all...)
 
+	// Goroutine-local storage is a pointer at a fixed offset in the
+	// current g. See runtime/gls.go.
+	add("runtime", "getgls",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			return s.load(types.Types[types.TUNSAFEPTR], s.glsAddr())
+		},
+		all...)
+	add("runtime", "setgls",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			s.store(types.Types[types.TUNSAFEPTR], s.glsAddr(), args[0])
+			return nil
+		},
+		all...)
+	alias("sync", "runtime_getgls", "runtime", "getgls", all...) // linknamed
+	alias("sync", "runtime_setgls", "runtime", "setgls", all...) // linknamed
+
+	addF("runtime", "cpuDispatch",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			if s.f.GOAMD64 > buildcfg.GOAMD64 {
+				// Compiling a //go:cpu variant, which must not dispatch again.
+				return s.constBool(false)
+			}
+			addr := s.entryNewValue1A(ssa.OpAddr, types.NewPtr(types.Types[types.TUINT8]), ir.Syms.X86Level, s.sb)
+			level := s.load(types.Types[types.TUINT8], addr)
+			return s.newValue2(ssa.OpLeq8U, types.Types[types.TBOOL], args[0], level)
+		},
+		sys.AMD64)
+
 	addF("runtime", "publicationBarrier",
 		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
 			s.vars[memVar] = s.newValue1(ssa.OpPubBarrier, types.TypeMem, s.mem())
@@ -4007,6 +4129,36 @@ func InitTables() {
 		},
 		sys.PPC64)
 
+	// Relaxed loads and stores need no fences on architectures with
+	// stronger memory models. Elsewhere they are ordinary atomic loads
+	// and stores; see the aliases below.
+	addF("runtime/internal/atomic", "LoadRelaxed",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			v := s.newValue2(ssa.OpAtomicLoadRelaxed32, types.NewTuple(types.Types[types.TUINT32], types.TypeMem), args[0], s.mem())
+			s.vars[memVar] = s.newValue1(ssa.OpSelect1, types.TypeMem, v)
+			return s.newValue1(ssa.OpSelect0, types.Types[types.TUINT32], v)
+		},
+		sys.AMD64, sys.S390X)
+	addF("runtime/internal/atomic", "LoadRelaxed64",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			v := s.newValue2(ssa.OpAtomicLoadRelaxed64, types.NewTuple(types.Types[types.TUINT64], types.TypeMem), args[0], s.mem())
+			s.vars[memVar] = s.newValue1(ssa.OpSelect1, types.TypeMem, v)
+			return s.newValue1(ssa.OpSelect0, types.Types[types.TUINT64], v)
+		},
+		sys.AMD64, sys.S390X)
+	addF("runtime/internal/atomic", "StoreRelaxed",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			s.vars[memVar] = s.newValue3(ssa.OpAtomicStoreRelaxed32, types.TypeMem, args[0], args[1], s.mem())
+			return nil
+		},
+		sys.AMD64, sys.S390X)
+	addF("runtime/internal/atomic", "StoreRelaxed64",
+		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			s.vars[memVar] = s.newValue3(ssa.OpAtomicStoreRelaxed64, types.TypeMem, args[0], args[1], s.mem())
+			return nil
+		},
+		sys.AMD64, sys.S390X)
+
 	addF("runtime/internal/atomic", "Xchg",
 		func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
 			v := s.newValue3(ssa.OpAtomicExchange32, types.NewTuple(types.Types[types.TUINT32], types.TypeMem), args[0], args[1], s.mem())
@@ -4184,6 +4336,10 @@ func InitTables() {
 	alias("sync", "runtime_LoadAcquintptr", "runtime/internal/atomic", "LoadAcq", p4...) // linknamed
 	alias("runtime/internal/atomic", "LoadAcquintptr", "runtime/internal/atomic", "LoadAcq64", p8...)
 	alias("sync", "runtime_LoadAcquintptr", "runtime/internal/atomic", "LoadAcq64", p8...) // linknamed
+	alias("runtime/internal/atomic", "LoadRelaxed", "runtime/internal/atomic", "Load", weakatomics...)
+	alias("runtime/internal/atomic", "LoadRelaxed64", "runtime/internal/atomic", "Load64", weakatomics...)
+	alias("runtime/internal/atomic", "LoadRelaxeduintptr", "runtime/internal/atomic", "LoadRelaxed", p4...)
+	alias("runtime/internal/atomic", "LoadRelaxeduintptr", "runtime/internal/atomic", "LoadRelaxed64", p8...)
 
 	// Aliases for atomic store operations
 	alias("runtime/internal/atomic", "Storeint32", "runtime/internal/atomic", "Store", all...)
@@ -4196,6 +4352,10 @@ func InitTables() {
 	alias("sync", "runtime_StoreReluintptr", "runtime/internal/atomic", "StoreRel", p4...) // linknamed
 	alias("runtime/internal/atomic", "StoreReluintptr", "runtime/internal/atomic", "StoreRel64", p8...)
 	alias("sync", "runtime_StoreReluintptr", "runtime/internal/atomic", "StoreRel64", p8...) // linknamed
+	alias("runtime/internal/atomic", "StoreRelaxed", "runtime/internal/atomic", "Store", weakatomics...)
+	alias("runtime/internal/atomic", "StoreRelaxed64", "runtime/internal/atomic", "Store64", weakatomics...)
+	alias("runtime/internal/atomic", "StoreRelaxeduintptr", "runtime/internal/atomic", "StoreRelaxed", p4...)
+	alias("runtime/internal/atomic", "StoreRelaxeduintptr", "runtime/internal/atomic", "StoreRelaxed64", p8...)
 
 	// Aliases for atomic swap operations
 	alias("runtime/internal/atomic", "Xchgint32", "runtime/internal/atomic", "Xchg", all...)
@@ -4271,7 +4431,7 @@ func InitTables() {
 				return s.variable(n, types.Types[types.TFLOAT64])
 			}
 
-			if buildcfg.GOAMD64 >= 3 {
+			if s.f.GOAMD64 >= 3 {
 				return s.newValue3(ssa.OpFMA, types.Types[types.TFLOAT64], args[0], args[1], args[2])
 			}
 
@@ -4337,7 +4497,7 @@ func InitTables() {
 
 	makeRoundAMD64 := func(op ssa.Op) func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
 		return func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
-			if buildcfg.GOAMD64 >= 2 {
+			if s.f.GOAMD64 >= 2 {
 				return s.newValue1(op, types.Types[types.TFLOAT64], args[0])
 			}
 
@@ -4547,7 +4707,7 @@ func InitTables() {
 
 	makeOnesCountAMD64 := func(op ssa.Op) func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
 		return func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
-			if buildcfg.GOAMD64 >= 2 {
+			if s.f.GOAMD64 >= 2 {
 				return s.newValue1(op, types.Types[types.TINT], args[0])
 			}
 
@@ -4637,8 +4797,8 @@ func InitTables() {
 			s.check(cmpOverflow, ir.Syms.Panicoverflow)
 			return s.newValue3(ssa.OpDiv128u, types.NewTuple(types.Types[types.TUINT64], types.Types[types.TUINT64]), args[0], args[1], args[2])
 		},
-		sys.AMD64)
-	alias("math/bits", "Div", "math/bits", "Div64", sys.ArchAMD64)
+		sys.AMD64, sys.S390X)
+	alias("math/bits", "Div", "math/bits", "Div64", sys.ArchAMD64, sys.ArchS390X)
 
 	alias("runtime/internal/sys", "Ctz8", "math/bits", "TrailingZeros8", all...)
 	alias("runtime/internal/sys", "TrailingZeros8", "math/bits", "TrailingZeros8", all...)
@@ -4693,6 +4853,84 @@ func InitTables() {
 			return s.newValue2(ssa.OpMul64uhilo, types.NewTuple(types.Types[types.TUINT64], types.Types[types.TUINT64]), args[0], args[1])
 		},
 		sys.ArchAMD64, sys.ArchARM64, sys.ArchPPC64LE, sys.ArchPPC64, sys.ArchS390X)
+
+	/******** encoding/binary ********/
+	// Access whole words, so that dead store elimination sees full-width
+	// stores (such as into a local [8]byte) before lowering would have
+	// combined the individual byte accesses.
+	binaryPtr := func(s *state, b *ssa.Value, t *types.Type) *ssa.Value {
+		// Same check as the _ = b[n-1] in the Go implementation.
+		len := s.newValue1(ssa.OpSliceLen, types.Types[types.TINT], b)
+		s.boundsCheck(s.constInt(types.Types[types.TINT], t.Size()-1), len, ssa.BoundsIndex, false)
+		return s.newValue1(ssa.OpSlicePtr, types.NewPtr(t), b)
+	}
+	makeBinaryLoad := func(et types.Kind, swap ssa.Op) intrinsicBuilder {
+		return func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			// args[0] is the receiver.
+			t := types.Types[et]
+			v := s.load(t, binaryPtr(s, args[1], t))
+			if swap != ssa.OpInvalid {
+				v = s.newValue1(swap, t, v)
+			}
+			return v
+		}
+	}
+	makeBinaryStore := func(et types.Kind, swap ssa.Op) intrinsicBuilder {
+		return func(s *state, n *ir.CallExpr, args []*ssa.Value) *ssa.Value {
+			t := types.Types[et]
+			p := binaryPtr(s, args[1], t)
+			v := args[2]
+			if swap != ssa.OpInvalid {
+				v = s.newValue1(swap, t, v)
+			}
+			s.store(t, p, v)
+			return nil
+		}
+	}
+	littleEndian := []*sys.Arch{sys.Arch386, sys.ArchAMD64, sys.ArchARM64, sys.ArchPPC64LE}
+	bigEndian := []*sys.Arch{sys.ArchPPC64, sys.ArchS390X}
+	byteSwap := []*sys.Arch{sys.ArchAMD64, sys.ArchARM64}
+	for _, w := range []struct {
+		name string
+		et   types.Kind
+		swap ssa.Op
+	}{
+		{"Uint16", types.TUINT16, ssa.OpInvalid},
+		{"Uint32", types.TUINT32, ssa.OpBswap32},
+		{"Uint64", types.TUINT64, ssa.OpBswap64},
+	} {
+		add("encoding/binary", "littleEndian."+w.name, makeBinaryLoad(w.et, ssa.OpInvalid), littleEndian...)
+		add("encoding/binary", "littleEndian.Put"+w.name, makeBinaryStore(w.et, ssa.OpInvalid), littleEndian...)
+		add("encoding/binary", "bigEndian."+w.name, makeBinaryLoad(w.et, ssa.OpInvalid), bigEndian...)
+		add("encoding/binary", "bigEndian.Put"+w.name, makeBinaryStore(w.et, ssa.OpInvalid), bigEndian...)
+		if w.swap != ssa.OpInvalid {
+			add("encoding/binary", "bigEndian."+w.name, makeBinaryLoad(w.et, w.swap), byteSwap...)
+			add("encoding/binary", "bigEndian.Put"+w.name, makeBinaryStore(w.et, w.swap), byteSwap...)
+		}
+	}
+}
+
+// intrinsicPkg returns the path of the package containing sym, as used
+// to key the intrinsics table.
+func intrinsicPkg(sym *types.Sym) string {
+	if sym.Pkg == types.LocalPkg {
+		return base.Ctxt.Pkgpath
+	}
+	if sym.Pkg == ir.Pkgs.Runtime {
+		return "runtime"
+	}
+	return sym.Pkg.Path
+}
+
+// glsOffset is the offset, in pointers, of the goroutine-local storage
+// in the runtime's g. Keep in sync with runtime.glsOffset.
+const glsOffset = 7
+
+// glsAddr returns the address of the current goroutine's
+// goroutine-local storage.
+func (s *state) glsAddr() *ssa.Value {
+	g := s.newValue1(ssa.OpGetG, s.f.Config.Types.BytePtr, s.mem())
+	return s.newValue1I(ssa.OpOffPtr, types.NewPtr(types.Types[types.TUNSAFEPTR]), glsOffset*int64(types.PtrSize), g)
 }
 
 // findIntrinsic returns a function which builds the SSA equivalent of the
@@ -4701,13 +4939,7 @@ func findIntrinsic(sym *types.Sym) intrinsicBuilder {
 	if sym == nil || sym.Pkg == nil {
 		return nil
 	}
-	pkg := sym.Pkg.Path
-	if sym.Pkg == types.LocalPkg {
-		pkg = base.Ctxt.Pkgpath
-	}
-	if sym.Pkg == ir.Pkgs.Runtime {
-		pkg = "runtime"
-	}
+	pkg := intrinsicPkg(sym)
 	if base.Flag.Race && pkg == "sync/atomic" {
 		// The race detector needs to be able to intercept these calls.
 		// We can't intrinsify them.
@@ -4721,7 +4953,7 @@ func findIntrinsic(sym *types.Sym) intrinsicBuilder {
 
 	fn := sym.Name
 	if ssa.IntrinsicsDisable {
-		if pkg == "runtime" && (fn == "getcallerpc" || fn == "getcallersp" || fn == "getclosureptr") {
+		if pkg == "runtime" && (fn == "getcallerpc" || fn == "getcallersp" || fn == "getclosureptr" || fn == "cpuDispatch") {
 			// These runtime functions don't have definitions, must be intrinsics.
 		} else {
 			return nil
@@ -4734,11 +4966,85 @@ func IsIntrinsicCall(n *ir.CallExpr) bool {
 	if n == nil {
 		return false
 	}
-	name, ok := n.X.(*ir.Name)
-	if !ok {
+	var sym *types.Sym
+	switch x := n.X.(type) {
+	case *ir.Name:
+		sym = x.Sym()
+		if findIntrinsic(sym) == nil && x.Class == ir.PFUNC && base.Debug.Intrinsics != 0 {
+			reportIntrinsicMiss(n.Pos(), "call to", sym)
+		}
+	case *ir.SelectorExpr:
+		// Method calls are only rewritten into calls of the method's
+		// function during walk.
+		if x.Op() != ir.OMETHEXPR {
+			return false
+		}
+		sym = ir.MethodSym(x.X.Type(), x.Sel)
+		if findIntrinsic(sym) == nil && base.Debug.Intrinsics != 0 {
+			reportIntrinsicMiss(n.Pos(), "call to", sym)
+		}
+	default:
 		return false
 	}
-	return findIntrinsic(name.Sym()) != nil
+	return findIntrinsic(sym) != nil
+}
+
+// intrinsicMisses records the positions already reported by
+// reportIntrinsicMiss, as calls are examined several times.
+var intrinsicMisses = make(map[src.XPos]bool)
+
+// reportIntrinsicMiss reports, for -d=intrinsics, that the function sym
+// used as described by what at pos is not intrinsified, if it would be
+// in another configuration.
+func reportIntrinsicMiss(pos src.XPos, what string, sym *types.Sym) {
+	if base.Ctxt.PosTable.Pos(pos).Base().InliningIndex() >= 0 {
+		// Report only at the source of the inlined call.
+		return
+	}
+	if intrinsicMisses[pos] {
+		return
+	}
+	why := intrinsicMissReason(sym)
+	if why == "" {
+		return
+	}
+	intrinsicMisses[pos] = true
+	base.WarnfAt(pos, "%s %s.%s not intrinsified: %s", what, intrinsicPkg(sym), sym.Name, why)
+}
+
+// intrinsicMissReason returns why a call of sym isn't intrinsified when
+// it is an intrinsic under some other configuration, or "" otherwise.
+func intrinsicMissReason(sym *types.Sym) string {
+	if sym == nil || sym.Pkg == nil {
+		return ""
+	}
+	pkg := intrinsicPkg(sym)
+	var here bool
+	var others []string
+	for k := range intrinsics {
+		if k.pkg != pkg || k.fn != sym.Name {
+			continue
+		}
+		if k.arch == Arch.LinkArch.Arch {
+			here = true
+		} else {
+			others = append(others, k.arch.Name)
+		}
+	}
+	switch {
+	case here && base.Flag.Race && pkg == "sync/atomic":
+		return "sync/atomic intrinsics are disabled by -race"
+	case here && Arch.SoftFloat && pkg == "math":
+		return "math intrinsics are disabled with soft float"
+	case here && ssa.IntrinsicsDisable:
+		return "intrinsics are disabled by -d=ssa/intrinsics/off"
+	case here:
+		return ""
+	case len(others) > 0:
+		sort.Strings(others)
+		return fmt.Sprintf("intrinsic only on %s", strings.Join(others, ", "))
+	}
+	return ""
 }
 
 // intrinsicCall converts a call to a recognized intrinsic function into the intrinsic SSA operation.
@@ -4754,6 +5060,10 @@ func (s *state) intrinsicCall(n *ir.CallExpr) *ssa.Value {
 		}
 		base.WarnfAt(n.Pos(), "intrinsic substitution for %v with %s", n.X.Sym().Name, x.LongString())
 	}
+	if ir.IsWhyAsmFunc(s.curfn) {
+		sym := n.X.Sym()
+		base.WarnfAt(n.Pos(), "intrinsified call to %s.%s", intrinsicPkg(sym), sym.Name)
+	}
 	return v
 }
 
@@ -5009,6 +5319,13 @@ func (s *state) call(n *ir.CallExpr, k callKind, returnResultAddr bool) *ssa.Val
 		}
 	}
 
+	if k == callNormal || k == callTail {
+		s.noteCall(callee)
+		if s.curfn.Pragma&ir.NoAlloc != 0 {
+			s.checkNoAllocCall(n, callee)
+		}
+	}
+
 	if !buildcfg.Experiment.RegabiArgs {
 		if regAbiForFuncType(n.X.Type().FuncType()) {
 			// Magic last type in input args to call
@@ -5100,7 +5417,24 @@ func (s *state) call(n *ir.CallExpr, k callKind, returnResultAddr bool) *ssa.Val
 			s.startBlock(curb)
 		}
 
+		// Copy parts of split variables passed as arguments to
+		// memory before evaluating any argument, so that every
+		// OpDereference uses the same mem as the call.
+		var spills []*ssa.Value
+		for i, n := range args {
+			if p := s.sroaPart(n); p != nil {
+				if spills == nil {
+					spills = make([]*ssa.Value, len(args))
+				}
+				spills[i] = s.sroaSpill(p)
+			}
+		}
+
 		for i, n := range args {
+			if spills != nil && spills[i] != nil {
+				callArgs = append(callArgs, s.newValue2(ssa.OpDereference, t.Params().Field(i).Type, spills[i], s.mem()))
+				continue
+			}
 			callArgs = append(callArgs, s.putArg(n, t.Params().Field(i).Type))
 		}
 
@@ -5216,6 +5550,9 @@ func (s *state) addr(n ir.Node) *ssa.Value {
 		defer s.popLine()
 	}
 
+	if p := s.sroaPart(n); p != nil {
+		return s.sroaSpill(p)
+	}
 	if s.canSSA(n) {
 		s.Fatalf("addr of canSSA expression: %+v", n)
 	}
@@ -5267,6 +5604,7 @@ func (s *state) addr(n ir.Node) *ssa.Value {
 		return s.resultAddrOfCall(s.prevCall, n.Index, n.Type())
 	case ir.OINDEX:
 		n := n.(*ir.IndexExpr)
+		s.noteBounds(n)
 		if n.X.Type().IsSlice() {
 			a := s.expr(n.X)
 			i := s.expr(n.Index)
@@ -5290,6 +5628,7 @@ func (s *state) addr(n ir.Node) *ssa.Value {
 		return s.newValue1I(ssa.OpOffPtr, t, n.Offset(), p)
 	case ir.ODOTPTR:
 		n := n.(*ir.SelectorExpr)
+		s.noteNilDeref(n.Pos(), n)
 		p := s.exprPtr(n.X, n.Bounded(), n.Pos())
 		return s.newValue1I(ssa.OpOffPtr, t, n.Offset(), p)
 	case ir.OCONVNOP:
@@ -5328,6 +5667,13 @@ func (s *state) canSSA(n ir.Node) bool {
 	if base.Flag.N != 0 {
 		return false
 	}
+	if len(s.sroa) != 0 {
+		if p, r := s.sroaResolve(n); p != nil {
+			// Leaves of split variables and their parts are
+			// SSA-able; larger parts are not.
+			return r != nil || p.leaf != nil
+		}
+	}
 	for {
 		nn := n
 		if nn.Op() == ir.ODOT {
@@ -5393,20 +5739,197 @@ func TypeOK(t *types.Type) bool {
 		}
 		return false
 	case types.TSTRUCT:
-		if t.NumFields() > ssa.MaxStruct {
-			return false
-		}
+		nf := 0
 		for _, t1 := range t.Fields().Slice() {
 			if !TypeOK(t1.Type) {
 				return false
 			}
+			if t1.Type.Size() != 0 {
+				nf++
+			}
 		}
-		return true
+		return nf <= ssa.MaxStruct
 	default:
 		return true
 	}
 }
 
+// noteNilDeref records a description of selector n, whose pointer
+// operand is nil-checked, so that a nil dereference panic at any
+// instruction at pos faulting on its behalf can name the field involved.
+func (s *state) noteNilDeref(pos src.XPos, n *ir.SelectorExpr) {
+	e := s.f.Frontend().(*ssafn)
+	if e.nilDerefs == nil {
+		e.nilDerefs = make(map[src.XPos]string)
+	}
+	what := fmt.Sprintf("field %v", n)
+	if !isSourceExpr(n.X) {
+		// Don't expose compiler temporaries.
+		what = "field " + n.Sel.Name
+	}
+	e.nilDerefs[pos.WithNotStmt()] = what
+}
+
+// isSourceExpr reports whether n consists only of user variables and
+// operations that read sensibly in a runtime error message, as opposed
+// to compiler temporaries and runtime calls introduced by order and walk.
+func isSourceExpr(n ir.Node) bool {
+	return !ir.Any(n, func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.ONAME:
+			n := n.(*ir.Name)
+			if n.Sym() == nil || ir.IsAutoTmp(n) || ir.IsBlank(n) {
+				return true
+			}
+			name := n.Sym().Name
+			return strings.HasPrefix(name, "~") || strings.HasPrefix(name, ".")
+		case ir.OLITERAL, ir.ONIL, ir.ODOT, ir.ODOTPTR, ir.ODEREF, ir.OINDEX,
+			ir.OSLICE, ir.OSLICEARR, ir.OSLICE3, ir.OSLICE3ARR, ir.OSLICESTR, ir.OSLICE2ARRPTR,
+			ir.OLEN, ir.OCAP, ir.OCONV, ir.OCONVNOP,
+			ir.OADD, ir.OSUB, ir.OMUL, ir.ODIV, ir.OMOD,
+			ir.OAND, ir.OOR, ir.OXOR, ir.OANDNOT, ir.OLSH, ir.ORSH,
+			ir.ONEG, ir.OPLUS, ir.OBITNOT:
+			return false
+		}
+		return true
+	})
+}
+
+// noteBounds records a description of n, an index, slice or
+// slice-to-array-pointer conversion expression, so that a failure of
+// any bounds check generated on its behalf can name the expression.
+func (s *state) noteBounds(n ir.Node) {
+	var what string
+	switch {
+	case isSourceExpr(n):
+		what = fmt.Sprintf("in %v", n)
+	case n.Op() == ir.OSLICE2ARRPTR:
+		return
+	default:
+		// Don't expose compiler temporaries, but name the
+		// operand if we can.
+		var x ir.Node
+		verb := "indexing"
+		switch n := n.(type) {
+		case *ir.IndexExpr:
+			x = n.X
+		case *ir.SliceExpr:
+			x, verb = n.X, "slicing"
+		}
+		if x.Op() == ir.OADDR {
+			x = x.(*ir.AddrExpr).X
+		}
+		if !isSourceExpr(x) {
+			return
+		}
+		what = fmt.Sprintf("%s %v", verb, x)
+	}
+	b := &boundsExpr{what: what, length: -1}
+	if n, ok := n.(*ir.SliceExpr); ok {
+		b.noteSlice(n)
+	}
+	e := s.f.Frontend().(*ssafn)
+	if e.boundsExprs == nil {
+		e.boundsExprs = make(map[src.XPos]*boundsExpr)
+	}
+	e.boundsExprs[n.Pos().WithNotStmt()] = b
+}
+
+// A boundsExpr describes a bounds-checked expression.
+type boundsExpr struct {
+	what string // description of the expression, such as "in s[i:j]"
+
+	// For slice expressions, the length of the sliced operand if
+	// known at compile time, or else -1.
+	length int64
+}
+
+// noteSlice records the length of the operand of slice expression n
+// in b, if it is a constant.
+func (b *boundsExpr) noteSlice(n *ir.SliceExpr) {
+	switch x := n.X; {
+	case x.Type().IsPtr() && x.Type().Elem().IsArray():
+		b.length = x.Type().Elem().NumElem()
+	case ir.IsConst(x, constant.String):
+		b.length = int64(len(ir.StringVal(x)))
+	}
+}
+
+// describe returns b's description of a failure of a bounds check
+// of the given kind. The runtime's message already gives the operands
+// compared by the check, and, for checks against the length or
+// capacity, that too. For the other slice checks, describe adds the
+// length of the sliced operand if it is a constant.
+func (b *boundsExpr) describe(kind ssa.BoundsKind) string {
+	switch kind {
+	case ssa.BoundsSliceB, ssa.BoundsSliceBU, ssa.BoundsSlice3C, ssa.BoundsSlice3CU, ssa.BoundsSlice3B, ssa.BoundsSlice3BU:
+		if b.length >= 0 {
+			return fmt.Sprintf("%s (length %d)", b.what, b.length)
+		}
+	}
+	return b.what
+}
+
+// notePanicBounds associates panic value v, an OpPanicBounds or
+// OpPanicExtend, with the description recorded by noteBounds for the
+// expression being evaluated, if any.
+func (s *state) notePanicBounds(v *ssa.Value) {
+	e := s.f.Frontend().(*ssafn)
+	b, ok := e.boundsExprs[s.peekPos().WithNotStmt()]
+	if !ok {
+		return
+	}
+	if e.panicBounds == nil {
+		e.panicBounds = make(map[ssa.ID]string)
+	}
+	e.panicBounds[v.ID] = b.describe(ssa.BoundsKind(v.AuxInt))
+}
+
+// panicInfoIndex returns the PCDATA_PanicInfoIndex value for v: the offset
+// of its description in the function's FUNCDATA_PanicInfo table, or
+// -1 if v doesn't panic on behalf of a recorded expression.
+func (e *ssafn) panicInfoIndex(v *ssa.Value) int {
+	if what, ok := e.panicBounds[v.ID]; ok {
+		return e.panicInfoOffset(what)
+	}
+	if !v.Op.FaultsOnNil() {
+		return -1
+	}
+	what, ok := e.nilDerefs[v.Pos.WithNotStmt()]
+	if !ok {
+		return -1
+	}
+	if v.Op.SymEffect()&ssa.SymWrite != 0 {
+		what = "writing " + what
+	} else {
+		what = "reading " + what
+	}
+	return e.panicInfoOffset(what)
+}
+
+// panicInfoOffset returns the offset of description what in the
+// function's FUNCDATA_PanicInfo table, adding it if necessary.
+func (e *ssafn) panicInfoOffset(what string) int {
+	if len(what) > 255 {
+		what = what[:255]
+	}
+	if off, ok := e.panicInfoOffs[what]; ok {
+		return off
+	}
+
+	// Each entry is a length byte followed by the description.
+	if e.panicInfoSym == nil {
+		e.panicInfoSym = base.Ctxt.Lookup(e.curfn.LSym.Name + ".panicinfo")
+		e.panicInfoSym.Set(obj.AttrContentAddressable, true)
+		e.panicInfoOffs = make(map[string]int)
+	}
+	off := int(e.panicInfoSym.Size)
+	e.panicInfoOffs[what] = off
+	objw.Uint8(e.panicInfoSym, off, uint8(len(what)))
+	e.panicInfoSym.WriteString(base.Ctxt, int64(off+1), len(what), what)
+	return off
+}
+
 // exprPtr evaluates n to a pointer and nil-checks it.
 func (s *state) exprPtr(n ir.Node, bounded bool, lineno src.XPos) *ssa.Value {
 	p := s.expr(n)
@@ -5507,6 +6030,7 @@ func (s *state) boundsCheck(idx, len *ssa.Value, kind ssa.BoundsKind, bounded bo
 		s.rtcall(BoundsCheckFunc[kind], false, nil, idx, len)
 	} else {
 		mem := s.newValue3I(ssa.OpPanicBounds, types.TypeMem, int64(kind), idx, len, s.mem())
+		s.notePanicBounds(mem)
 		s.endBlock().SetControl(mem)
 	}
 	s.startBlock(bNext)
@@ -5563,6 +6087,113 @@ func (s *state) intDivide(n ir.Node, a, b *ssa.Value) *ssa.Value {
 	return s.newValue2(s.ssaOp(n.Op(), n.Type()), a.Type, a, b)
 }
 
+// InlineComplexDiv reports whether complex division is compiled
+// inline by complexDivide rather than by calling runtime.complex128div.
+func InlineComplexDiv() bool {
+	switch Arch.LinkArch.Family {
+	case sys.AMD64, sys.ARM64:
+		return !Arch.SoftFloat
+	}
+	return false
+}
+
+// complexDivide returns a / b for complex a and b. It uses the same
+// algorithm as runtime.complex128div, and calls complex128div only
+// when both parts of the quotient are NaN, to correct the result for
+// infinite and zero operands.
+func (s *state) complexDivide(n *ir.BinaryExpr, a, b *ssa.Value) *ssa.Value {
+	ft := types.FloatForComplex(n.Type())
+	wt := types.Types[types.TFLOAT64]
+	ct := types.Types[types.TCOMPLEX128]
+
+	// Compute in complex128, as complex128div does.
+	parts := func(x *ssa.Value) (re, im *ssa.Value) {
+		re = s.newValue1(ssa.OpComplexReal, ft, x)
+		im = s.newValue1(ssa.OpComplexImag, ft, x)
+		if ft != wt {
+			re = s.newValue1(ssa.OpCvt32Fto64F, wt, re)
+			im = s.newValue1(ssa.OpCvt32Fto64F, wt, im)
+		}
+		return re, im
+	}
+	ar, ai := parts(a)
+	br, bi := parts(b)
+	if ft != wt {
+		a = s.newValue2(ssa.OpComplexMake, ct, ar, ai)
+		b = s.newValue2(ssa.OpComplexMake, ct, br, bi)
+	}
+
+	abs := func(x *ssa.Value) *ssa.Value {
+		if Arch.LinkArch.Family == sys.ARM64 {
+			return s.newValue1(ssa.OpAbs, wt, x)
+		}
+		// max(x, -x) differs from |x| only in the sign of zero,
+		// which does not matter to the comparison below.
+		return s.newValue2I(ssa.OpMax64F, wt, 0, x, s.newValue1(ssa.OpNeg64F, wt, x))
+	}
+	add := func(x, y *ssa.Value) *ssa.Value { return s.newValue2(ssa.OpAdd64F, wt, x, y) }
+	sub := func(x, y *ssa.Value) *ssa.Value { return s.newValue2(ssa.OpSub64F, wt, x, y) }
+	mul := func(x, y *ssa.Value) *ssa.Value { return s.newValue2(ssa.OpMul64F, wt, x, y) }
+	div := func(x, y *ssa.Value) *ssa.Value { return s.newValue2(ssa.OpDiv64F, wt, x, y) }
+
+	// Smith's algorithm, scaling by whichever part of b is larger.
+	bReal := s.f.NewBlock(ssa.BlockPlain)
+	bImag := s.f.NewBlock(ssa.BlockPlain)
+	bQuo := s.f.NewBlock(ssa.BlockPlain)
+	bFix := s.f.NewBlock(ssa.BlockPlain)
+	bAfter := s.f.NewBlock(ssa.BlockPlain)
+
+	cmp := s.newValue2(ssa.OpLeq64F, types.Types[types.TBOOL], abs(bi), abs(br)) // |br| >= |bi|
+	blk := s.endBlock()
+	blk.Kind = ssa.BlockIf
+	blk.SetControl(cmp)
+	blk.AddEdgeTo(bReal)
+	blk.AddEdgeTo(bImag)
+
+	s.startBlock(bReal)
+	ratio := div(bi, br)
+	denom := add(br, mul(ratio, bi))
+	e := div(add(ar, mul(ai, ratio)), denom)
+	f := div(sub(ai, mul(ar, ratio)), denom)
+	s.vars[n] = s.newValue2(ssa.OpComplexMake, ct, e, f)
+	s.endBlock().AddEdgeTo(bQuo)
+
+	s.startBlock(bImag)
+	ratio = div(br, bi)
+	denom = add(bi, mul(ratio, br))
+	e = div(add(mul(ar, ratio), ai), denom)
+	f = div(sub(mul(ai, ratio), ar), denom)
+	s.vars[n] = s.newValue2(ssa.OpComplexMake, ct, e, f)
+	s.endBlock().AddEdgeTo(bQuo)
+
+	s.startBlock(bQuo)
+	q := s.variable(n, ct)
+	e = s.newValue1(ssa.OpComplexReal, wt, q)
+	f = s.newValue1(ssa.OpComplexImag, wt, q)
+	tbool := types.Types[types.TBOOL]
+	nan := s.newValue2(ssa.OpAndB, tbool, s.newValue2(ssa.OpNeq64F, tbool, e, e), s.newValue2(ssa.OpNeq64F, tbool, f, f))
+	blk = s.endBlock()
+	blk.Kind = ssa.BlockIf
+	blk.SetControl(nan)
+	blk.Likely = ssa.BranchUnlikely
+	blk.AddEdgeTo(bFix)
+	blk.AddEdgeTo(bAfter)
+
+	s.startBlock(bFix)
+	s.vars[n] = s.rtcall(ir.Syms.Complex128div, true, []*types.Type{ct}, a, b)[0]
+	s.endBlock().AddEdgeTo(bAfter)
+
+	s.startBlock(bAfter)
+	q = s.variable(n, ct)
+	delete(s.vars, n)
+	if ft != wt {
+		re := s.newValue1(ssa.OpCvt64Fto32F, ft, s.newValue1(ssa.OpComplexReal, wt, q))
+		im := s.newValue1(ssa.OpCvt64Fto32F, ft, s.newValue1(ssa.OpComplexImag, wt, q))
+		q = s.newValue2(ssa.OpComplexMake, n.Type(), re, im)
+	}
+	return q
+}
+
 // rtcall issues a call to the given runtime function fn with the listed args.
 // Returns a slice of results of the given result types.
 // The call is added to the end of the current block.
@@ -6472,6 +7103,10 @@ func (s *state) addNamedValue(n *ir.Name, v *ssa.Value) {
 		return
 	}
 	loc := ssa.LocalSlot{N: n, Type: n.Type(), Off: 0}
+	if slot := s.sroaSlots[n]; slot != nil {
+		// n holds a leaf of a split variable.
+		loc = *slot
+	}
 	values, ok := s.f.NamedValues[loc]
 	if !ok {
 		s.f.Names = append(s.f.Names, &loc)
@@ -6812,7 +7447,8 @@ func genssa(f *ssa.Func, pp *objw.Progs) {
 	// Progs that are in the set above and have that source position.
 	var inlMarksByPos map[src.XPos][]*obj.Prog
 
-	var argLiveIdx int = -1 // argument liveness info index
+	var argLiveIdx int = -1   // argument liveness info index
+	var panicInfoIdx int = -1 // panic site description index
 
 	// Emit basic blocks
 	for i, b := range f.Blocks {
@@ -6837,6 +7473,15 @@ func genssa(f *ssa.Func, pp *objw.Progs) {
 		// Emit values in block
 		Arch.SSAMarkMoves(&s, b)
 		for _, v := range b.Values {
+			if e.nilDerefs != nil || e.panicBounds != nil {
+				if idx := e.panicInfoIndex(v); idx != panicInfoIdx {
+					panicInfoIdx = idx
+					p := s.pp.Prog(obj.APCDATA)
+					p.From.SetConst(objabi.PCDATA_PanicInfoIndex)
+					p.To.SetConst(int64(idx))
+				}
+			}
+
 			x := s.pp.Next
 			s.DebugFriendlySetPosFrom(v)
 
@@ -6919,6 +7564,12 @@ func genssa(f *ssa.Func, pp *objw.Progs) {
 			}
 			b.Pos = b.Pos.WithBogusLine() // Debuggers are not good about infinite loops, force a change in line number
 		}
+		if panicInfoIdx != -1 {
+			panicInfoIdx = -1
+			p := s.pp.Prog(obj.APCDATA)
+			p.From.SetConst(objabi.PCDATA_PanicInfoIndex)
+			p.To.SetConst(-1)
+		}
 		// Emit control flow instructions for block
 		var next *ssa.Block
 		if i < len(f.Blocks)-1 && base.Flag.N == 0 {
@@ -6937,6 +7588,16 @@ func genssa(f *ssa.Func, pp *objw.Progs) {
 			}
 		}
 	}
+	if e.panicInfoSym != nil {
+		// Emit a funcdata pointing at the descriptions referenced
+		// by PCDATA_PanicInfoIndex.
+		e.curfn.LSym.Func().PanicInfo = e.panicInfoSym
+		p := pp.Prog(obj.AFUNCDATA)
+		p.From.SetConst(objabi.FUNCDATA_PanicInfo)
+		p.To.Type = obj.TYPE_MEM
+		p.To.Name = obj.NAME_EXTERN
+		p.To.Sym = e.panicInfoSym
+	}
 	if f.Blocks[len(f.Blocks)-1].Kind == ssa.BlockExit {
 		// We need the return address of a panic call to
 		// still be inside the function in question. So if
@@ -7401,6 +8062,7 @@ func (s *state) extendIndex(idx, len *ssa.Value, kind ssa.BoundsKind, bounded bo
 
 		s.startBlock(bPanic)
 		mem := s.newValue4I(ssa.OpPanicExtend, types.TypeMem, int64(kind), hi, lo, len, s.mem())
+		s.notePanicBounds(mem)
 		s.endBlock().SetControl(mem)
 		s.startBlock(bNext)
 
@@ -7607,6 +8269,12 @@ type ssafn struct {
 	stksize    int64                // stack size for current frame
 	stkptrsize int64                // prefix of stack containing pointers
 	log        bool                 // print ssa debug to the stdout
+
+	nilDerefs     map[src.XPos]string      // selector expressions with implicit nil checks, by position
+	boundsExprs   map[src.XPos]*boundsExpr // bounds-checked expressions, by position
+	panicBounds   map[ssa.ID]string        // descriptions of PanicBounds and PanicExtend values
+	panicInfoSym  *obj.LSym                // FUNCDATA_PanicInfo table
+	panicInfoOffs map[string]int           // offsets of descriptions within panicInfoSym
 }
 
 // StringData returns a symbol which
@@ -7671,6 +8339,7 @@ func (e *ssafn) Log() bool {
 // Fatal reports a compiler error and exits.
 func (e *ssafn) Fatalf(pos src.XPos, msg string, args ...interface{}) {
 	base.Pos = pos
+	base.SetCrashFunc(ir.FuncName(e.curfn), e.curfn.Pos(), fmt.Sprintf("%+v", e.curfn))
 	nargs := append([]interface{}{ir.FuncName(e.curfn)}, args...)
 	base.Fatalf("'%s': "+msg, nargs...)
 }
@@ -0,0 +1,274 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package staticinit
+
+import (
+	"go/constant"
+	"go/token"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/typecheck"
+	"cmd/compile/internal/types"
+)
+
+// A readOnlyMap is a package-level map variable that is initialized
+// from a map literal and never modified afterwards.
+type readOnlyMap struct {
+	name    *ir.Name
+	entries []*ir.KeyExpr // entries of the initializing map literal
+	bad     bool          // used other than for reading
+	folded  int           // number of lookups folded
+}
+
+// FoldReadOnlyMaps finds unexported package-level map variables that
+// are initialized with a map literal of constant keys and values and
+// are only ever read afterwards (indexed, ranged over or passed to
+// len). Lookups of constant keys in such maps, and calls to len, are
+// replaced by the constant result.
+//
+// FoldReadOnlyMaps must run after inlining, so that all accesses to
+// the maps in this package are visible.
+func FoldReadOnlyMaps(decls []ir.Node) {
+	if base.Flag.N != 0 {
+		return
+	}
+
+	maps := make(map[*ir.Name]*readOnlyMap)
+	for _, n := range decls {
+		if n.Op() != ir.OAS {
+			continue
+		}
+		if m := readOnlyMapCandidate(n.(*ir.AssignStmt)); m != nil {
+			maps[m.name] = m
+		}
+	}
+	if len(maps) == 0 {
+		return
+	}
+
+	lookup := func(n ir.Node) *readOnlyMap {
+		if n.Op() != ir.ONAME {
+			return nil
+		}
+		return maps[n.(*ir.Name)]
+	}
+	markAssigned := func(lhs ...ir.Node) {
+		for _, l := range lhs {
+			if l != nil && l.Op() == ir.OINDEXMAP {
+				if m := lookup(l.(*ir.IndexExpr).X); m != nil {
+					m.bad = true
+				}
+			}
+		}
+	}
+
+	// Find all uses of the candidate maps. Any use other than a read
+	// disqualifies the map.
+	var visit func(n ir.Node) bool
+	visit = func(n ir.Node) bool {
+		switch n.Op() {
+		case ir.ONAME:
+			if m := lookup(n); m != nil {
+				m.bad = true
+			}
+			return false
+
+		case ir.OAS:
+			n := n.(*ir.AssignStmt)
+			if m := lookup(n.X); m != nil && n == m.name.Defn {
+				return visit(n.Y)
+			}
+			markAssigned(n.X)
+		case ir.OASOP:
+			markAssigned(n.(*ir.AssignOpStmt).X)
+		case ir.OAS2, ir.OAS2DOTTYPE, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2RECV, ir.OSELRECV2:
+			markAssigned(n.(*ir.AssignListStmt).Lhs...)
+		case ir.ORANGE:
+			n := n.(*ir.RangeStmt)
+			markAssigned(n.Key, n.Value)
+			if lookup(n.X) != nil {
+				for _, x := range []ir.Node{n.Key, n.Value} {
+					if x != nil {
+						visit(x)
+					}
+				}
+				for _, x := range n.Init() {
+					visit(x)
+				}
+				for _, x := range n.Body {
+					visit(x)
+				}
+				return false
+			}
+
+		case ir.OINDEXMAP:
+			n := n.(*ir.IndexExpr)
+			if lookup(n.X) != nil {
+				return visit(n.Index)
+			}
+		case ir.OLEN:
+			if lookup(n.(*ir.UnaryExpr).X) != nil {
+				return false
+			}
+		}
+		return ir.DoChildren(n, visit)
+	}
+	for _, n := range decls {
+		visit(n)
+	}
+
+	// Fold constant-key lookups in the remaining maps.
+	found := false
+	for _, m := range maps {
+		if !m.bad {
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	for _, n := range decls {
+		if n.Op() != ir.ODCLFUNC {
+			continue
+		}
+		fn := n.(*ir.Func)
+		ir.WithFunc(fn, func() {
+			var edit func(n ir.Node) ir.Node
+			edit = func(n ir.Node) ir.Node {
+				// Handle v, ok := m[k] before its operands are edited,
+				// so the lookup is still recognizable.
+				if n.Op() == ir.OAS2MAPR {
+					n := n.(*ir.AssignListStmt)
+					r := n.Rhs[0].(*ir.IndexExpr)
+					if m := lookup(r.X); m != nil && !m.bad && ir.IsConstNode(r.Index) {
+						v, ok := m.lookup(r, r.Index)
+						as := ir.NewAssignListStmt(n.Pos(), ir.OAS2, n.Lhs, []ir.Node{v, ir.NewBool(ok)})
+						as.Def = n.Def
+						as.SetInit(n.Init())
+						ir.EditChildren(as, edit)
+						return typecheck.Stmt(as)
+					}
+				}
+
+				ir.EditChildren(n, edit)
+				switch n.Op() {
+				case ir.OINDEXMAP:
+					n := n.(*ir.IndexExpr)
+					if m := lookup(n.X); m != nil && !m.bad && ir.IsConstNode(n.Index) {
+						v, _ := m.lookup(n, n.Index)
+						return v
+					}
+				case ir.OLEN:
+					n := n.(*ir.UnaryExpr)
+					if m := lookup(n.X); m != nil && !m.bad {
+						return ir.NewConstExpr(constant.MakeInt64(int64(len(m.entries))), n)
+					}
+				}
+				return n
+			}
+			ir.EditChildren(fn, edit)
+		})
+	}
+
+	if base.Flag.LowerM != 0 {
+		for _, m := range maps {
+			if m.folded > 0 {
+				base.WarnfAt(m.name.Pos(), "read-only map %v: constant-key lookups folded", m.name)
+			}
+		}
+	}
+}
+
+// readOnlyMapCandidate reports whether the package-level assignment
+// as initializes a map variable that FoldReadOnlyMaps could handle.
+func readOnlyMapCandidate(as *ir.AssignStmt) *readOnlyMap {
+	if as.X == nil || as.X.Op() != ir.ONAME || as.Y == nil || as.Y.Op() != ir.OMAPLIT {
+		return nil
+	}
+	name := as.X.(*ir.Name)
+	if name.Class != ir.PEXTERN || name.Defn != as || name.Sym().Pkg != types.LocalPkg ||
+		types.IsExported(name.Sym().Name) || name.Sym().Linkname != "" {
+		return nil
+	}
+	elem := name.Type().Elem()
+	if !foldableType(name.Type().Key()) || !foldableType(elem) && elem.Kind() != types.TFUNC {
+		return nil
+	}
+
+	lit := as.Y.(*ir.CompLitExpr)
+	m := &readOnlyMap{name: name}
+	for _, r := range lit.List {
+		r := r.(*ir.KeyExpr)
+		if !ir.IsConstNode(r.Key) {
+			return nil
+		}
+		switch v := r.Value; v.Op() {
+		case ir.OLITERAL, ir.ONIL:
+		case ir.ONAME:
+			if v.(*ir.Name).Class != ir.PFUNC {
+				return nil
+			}
+		default:
+			return nil
+		}
+		m.entries = append(m.entries, r)
+	}
+	return m
+}
+
+// foldableType reports whether values of type t are represented by
+// constants.
+func foldableType(t *types.Type) bool {
+	return t.IsBoolean() || t.IsString() || t.IsInteger() || t.IsFloat() || t.IsComplex()
+}
+
+// lookup returns the result of looking up the constant key in m,
+// and whether it was found. n is the lookup expression being
+// replaced.
+func (m *readOnlyMap) lookup(n *ir.IndexExpr, key ir.Node) (ir.Node, bool) {
+	if base.Flag.LowerM > 1 {
+		base.WarnfAt(n.Pos(), "folded constant-key lookup %v", n)
+	}
+	m.folded++
+	for _, r := range m.entries {
+		if constant.Compare(r.Key.Val(), token.EQL, key.Val()) {
+			switch v := r.Value; v.Op() {
+			case ir.OLITERAL:
+				return ir.NewConstExpr(v.Val(), n), true
+			case ir.ONIL:
+				return zeroFoldedValue(n), true
+			default:
+				return v, true
+			}
+		}
+	}
+	return zeroFoldedValue(n), false
+}
+
+// zeroFoldedValue returns the zero value for the lookup expression n.
+func zeroFoldedValue(n *ir.IndexExpr) ir.Node {
+	t := n.Type()
+	var v constant.Value
+	switch {
+	case t.IsBoolean():
+		v = constant.MakeBool(false)
+	case t.IsString():
+		v = constant.MakeString("")
+	case t.IsInteger():
+		v = constant.MakeInt64(0)
+	case t.IsFloat():
+		v = constant.MakeFloat64(0)
+	case t.IsComplex():
+		v = constant.ToComplex(constant.MakeInt64(0))
+	default:
+		z := ir.NewNilExpr(n.Pos())
+		z.SetType(t)
+		z.SetTypecheck(1)
+		return z
+	}
+	return ir.NewConstExpr(v, n)
+}
@@ -25,6 +25,7 @@ type parser struct {
 	first  error    // first error encountered
 	errcnt int      // number of errors encountered
 	pragma Pragma   // pragmas
+	notice string   // deprecation notice being scanned, or ""
 
 	fnest  int    // function nesting level (for error handling)
 	xnest  int    // expression nesting level (for complit ambiguity resolution)
@@ -49,7 +50,8 @@ func (p *parser) init(file *PosBase, r io.Reader, errh ErrorHandler, pragh Pragm
 				return
 			}
 
-			// otherwise it must be a comment containing a line or go: directive.
+			// otherwise it must be a comment containing a line or go: directive,
+			// a deprecation notice, or a generated code header.
 			// //line directives must be at the start of the line (column colbase).
 			// /*line*/ directives can be anywhere in the line.
 			text := commentText(msg)
@@ -69,10 +71,36 @@ func (p *parser) init(file *PosBase, r io.Reader, errh ErrorHandler, pragh Pragm
 				return
 			}
 
+			// Code generated header, recorded with the file base
+			const genPrefix, genSuffix = " Code generated ", " DO NOT EDIT."
+			if col == colbase && msg[1] == '/' && strings.HasPrefix(text, genPrefix) {
+				if strings.HasSuffix(text, genSuffix) && len(text) >= len(genPrefix)+len(genSuffix) {
+					p.file.generated = strings.TrimSuffix(text[len(genPrefix):len(text)-len(genSuffix)], ".")
+				}
+				p.notice = ""
+				return
+			}
+
 			// go: directive (but be conservative and test)
 			if pragh != nil && strings.HasPrefix(text, "go:") {
 				p.pragma = pragh(p.posAt(line, col+2), p.scanner.blank, text, p.pragma) // +2 to skip over // or /*
 			}
+
+			// Deprecated: notice, only in doc comments, and the lines
+			// continuing it up to the end of its paragraph
+			if pragh == nil || msg[1] != '/' {
+				return
+			}
+			switch {
+			case p.scanner.blank && strings.HasPrefix(text, " Deprecated:"):
+				p.notice = text[1:]
+			case p.notice != "" && p.scanner.blank && strings.TrimSpace(text) != "" && !strings.HasPrefix(text, "go:"):
+				p.notice += " " + strings.TrimSpace(text)
+			default:
+				p.notice = ""
+				return
+			}
+			p.pragma = pragh(p.posAt(line, col+3), true, p.notice, p.pragma) // +3 to skip over "// "
 		},
 		directives,
 	)
@@ -81,6 +109,7 @@ func (p *parser) init(file *PosBase, r io.Reader, errh ErrorHandler, pragh Pragm
 	p.first = nil
 	p.errcnt = 0
 	p.pragma = nil
+	p.notice = ""
 
 	p.fnest = 0
 	p.xnest = 0
@@ -1435,6 +1464,9 @@ func (p *parser) structType() *StructType {
 	p.want(_Struct)
 	p.want(_Lbrace)
 	p.list(_Semi, _Rbrace, func() bool {
+		// Pragmas in field comments are not for the enclosing
+		// declaration, or the one after it in a group.
+		p.clearPragma()
 		p.fieldDecl(typ)
 		return false
 	})
@@ -1456,6 +1488,7 @@ func (p *parser) interfaceType() *InterfaceType {
 	p.want(_Interface)
 	p.want(_Lbrace)
 	p.list(_Semi, _Rbrace, func() bool {
+		p.clearPragma() // see structType
 		switch p.tok {
 		case _Name:
 			f := p.methodDecl()
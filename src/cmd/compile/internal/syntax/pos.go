@@ -133,7 +133,8 @@ type PosBase struct {
 	pos       Pos
 	filename  string
 	line, col uint32
-	trimmed   bool // whether -trimpath has been applied
+	trimmed   bool   // whether -trimpath has been applied
+	generated string // for file bases, see Generated
 }
 
 // NewFileBase returns a new PosBase for the given filename.
@@ -145,7 +146,7 @@ func NewFileBase(filename string) *PosBase {
 
 // NewTrimmedFileBase is like NewFileBase, but allows specifying Trimmed.
 func NewTrimmedFileBase(filename string, trimmed bool) *PosBase {
-	base := &PosBase{MakePos(nil, linebase, colbase), filename, linebase, colbase, trimmed}
+	base := &PosBase{MakePos(nil, linebase, colbase), filename, linebase, colbase, trimmed, ""}
 	base.pos.base = base
 	return base
 }
@@ -156,7 +157,7 @@ func NewTrimmedFileBase(filename string, trimmed bool) *PosBase {
 // that position is the beginning of the next line (i.e., the newline character
 // belongs to the line comment).
 func NewLineBase(pos Pos, filename string, trimmed bool, line, col uint) *PosBase {
-	return &PosBase{pos, filename, sat32(line), sat32(col), trimmed}
+	return &PosBase{pos, filename, sat32(line), sat32(col), trimmed, ""}
 }
 
 func (base *PosBase) IsFileBase() bool {
@@ -201,6 +202,17 @@ func (base *PosBase) Trimmed() bool {
 	return base.trimmed
 }
 
+// Generated returns the description of how the file of base was
+// generated, from its "// Code generated ... DO NOT EDIT." header,
+// such as "by goyacc". It is "" if the file has no such header.
+// Line directive bases report the file they are in.
+func (base *PosBase) Generated() string {
+	if base == nil || base.pos.base == nil {
+		return ""
+	}
+	return base.pos.base.generated
+}
+
 func sat32(x uint) uint32 {
 	if x > PosMax {
 		return PosMax
@@ -31,6 +31,7 @@ type scanner struct {
 	source
 	mode   uint
 	nlsemi bool // if set '\n' and EOF translate to ';'
+	notice uint // line of the last deprecation notice line reported, or 0
 
 	// current token, valid after calling next()
 	line, col uint
@@ -47,6 +48,7 @@ func (s *scanner) init(src io.Reader, errh func(line, col uint, msg string), mod
 	s.source.init(src, errh)
 	s.mode = mode
 	s.nlsemi = false
+	s.notice = 0
 }
 
 // errorf reports an error at the most recently read character position.
@@ -83,8 +85,11 @@ func (s *scanner) setLit(kind LitKind, ok bool) {
 // which can be used to distinguish these handler calls from errors.
 //
 // If the scanner mode includes the directives (but not the comments)
-// flag, only comments containing a //line, /*line, or //go: directive
-// are reported, in the same way as regular comments.
+// flag, only comments containing a //line, /*line, or //go: directive,
+// // Deprecated: notices, and // Code generated headers are reported,
+// in the same way as regular comments. The line comments on the lines
+// immediately following a notice are reported as well, since they may
+// continue it.
 func (s *scanner) next() {
 	nlsemi := s.nlsemi
 	s.nlsemi = false
@@ -746,17 +751,33 @@ func (s *scanner) lineComment() {
 		return
 	}
 
+	// continuation of a deprecation notice?
+	if s.mode&directives != 0 && s.notice != 0 && s.line == s.notice+1 {
+		s.notice = s.line
+		s.skipLine()
+		s.comment(string(s.segment()))
+		return
+	}
+
 	// are we saving directives? or is this definitely not a directive?
-	if s.mode&directives == 0 || (s.ch != 'g' && s.ch != 'l') {
+	if s.mode&directives == 0 || (s.ch != 'g' && s.ch != 'l' && s.ch != ' ') {
 		s.stop()
 		s.skipLine()
 		return
 	}
 
-	// recognize go: or line directives
+	// recognize go: or line directives, deprecation notices, or
+	// generated code headers
 	prefix := "go:"
-	if s.ch == 'l' {
+	switch s.ch {
+	case 'l':
 		prefix = "line "
+	case ' ':
+		s.nextch()
+		prefix = "Deprecated:"
+		if s.ch == 'C' {
+			prefix = "Code generated "
+		}
 	}
 	for _, m := range prefix {
 		if s.ch != m {
@@ -766,6 +787,9 @@ func (s *scanner) lineComment() {
 		}
 		s.nextch()
 	}
+	if prefix == "Deprecated:" {
+		s.notice = s.line
+	}
 
 	// directive text
 	s.skipLine()
@@ -704,6 +704,16 @@ func TestDirectives(t *testing.T) {
 		"//go :foo",
 		"//go:foo",
 		"//go:foo%bar",
+
+		"Deprecated:",
+		"//Deprecated: foo",
+		"// deprecated: foo",
+		"// Deprecated:",
+		"// Deprecated: foo",
+
+		"// Code generated",
+		"// code generated by foo. DO NOT EDIT.",
+		"// Code generated by foo. DO NOT EDIT.",
 	} {
 		got := ""
 		var s scanner
@@ -718,7 +728,7 @@ func TestDirectives(t *testing.T) {
 		}, directives)
 
 		s.next()
-		if strings.HasPrefix(src, "//line ") || strings.HasPrefix(src, "//go:") {
+		if strings.HasPrefix(src, "//line ") || strings.HasPrefix(src, "//go:") || strings.HasPrefix(src, "// Deprecated:") || strings.HasPrefix(src, "// Code generated ") {
 			// handler should have been called
 			if got != src {
 				t.Errorf("got %s; want %s", got, src)
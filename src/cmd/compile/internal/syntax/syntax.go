@@ -39,10 +39,12 @@ type ErrorHandler func(err error)
 // except that nil is used to mean “no pragma seen.”
 type Pragma interface{}
 
-// A PragmaHandler is used to process //go: directives while scanning.
+// A PragmaHandler is used to process //go: directives, and the
+// "Deprecated:" notices of doc comments, while scanning.
 // It is passed the current pragma value, which starts out being nil,
 // and it returns an updated pragma value.
-// The text is the directive, with the "//" prefix stripped.
+// The text is the directive, with the "//" prefix stripped, or the
+// notice, with the "// " prefix stripped.
 // The current pragma is saved at each package, import, const, func, type, or var
 // declaration, into the File, ImportDecl, ConstDecl, FuncDecl, TypeDecl, or VarDecl node.
 //
@@ -337,6 +337,25 @@ func TestABIUtilsInterfaces(t *testing.T) {
 	abitest(t, ft, exp)
 }
 
+func TestABIUtilsErrorResult(t *testing.T) {
+	// type s1 { f1 int64; f2 *int64 }
+	// func(p1 *int64) (r1 s1, r2 error)
+	i64 := types.Types[types.TINT64]
+	pi64 := types.NewPtr(i64)
+	s1 := mkstruct([]*types.Type{i64, pi64})
+	ft := mkFuncType(nil, []*types.Type{pi64}, []*types.Type{s1, types.ErrorType})
+
+	// The error is returned in registers alongside the value.
+	exp := makeExpectedDump(`
+        IN 0: R{ I0 } spilloffset: 0 typ: *int64
+        OUT 0: R{ I0 I1 } spilloffset: -1 typ: struct { int64; *int64 }
+        OUT 1: R{ I2 I3 } spilloffset: -1 typ: error
+        offsetToSpillArea: 0 spillAreaSize: 8
+`)
+
+	abitest(t, ft, exp)
+}
+
 func TestABINumParamRegs(t *testing.T) {
 	i8 := types.Types[types.TINT8]
 	i16 := types.Types[types.TINT16]
@@ -397,3 +416,61 @@ func TestABIUtilsComputePadding(t *testing.T) {
 		t.Errorf("padding mismatch: wanted %q got %q\n", got, want)
 	}
 }
+
+// ARM64 registers available:
+// - integer: R0 - R15
+// - floating point: F0 - F15
+var configARM64 = abi.NewABIConfig(16, 16, 0)
+
+func TestABIUtilsRegisterCount(t *testing.T) {
+	// func(p1, ..., p12 int64, p13 float64) (r1 int64, r2 [2]int64)
+	i64 := types.Types[types.TINT64]
+	f64 := types.Types[types.TFLOAT64]
+	a2 := types.NewArray(i64, 2)
+	ft := mkFuncType(nil,
+		[]*types.Type{i64, i64, i64, i64, i64, i64, i64, i64, i64, i64, i64, i64, f64},
+		[]*types.Type{i64, a2})
+
+	// With 9 integer registers, the last three integers go on the
+	// stack, and arrays of more than one element never use registers.
+	exp := makeExpectedDump(`
+        IN 0: R{ I0 } spilloffset: 0 typ: int64
+        IN 1: R{ I1 } spilloffset: 8 typ: int64
+        IN 2: R{ I2 } spilloffset: 16 typ: int64
+        IN 3: R{ I3 } spilloffset: 24 typ: int64
+        IN 4: R{ I4 } spilloffset: 32 typ: int64
+        IN 5: R{ I5 } spilloffset: 40 typ: int64
+        IN 6: R{ I6 } spilloffset: 48 typ: int64
+        IN 7: R{ I7 } spilloffset: 56 typ: int64
+        IN 8: R{ I8 } spilloffset: 64 typ: int64
+        IN 9: R{ } offset: 0 typ: int64
+        IN 10: R{ } offset: 8 typ: int64
+        IN 11: R{ } offset: 16 typ: int64
+        IN 12: R{ F0 } spilloffset: 72 typ: float64
+        OUT 0: R{ I0 } spilloffset: -1 typ: int64
+        OUT 1: R{ } offset: 24 typ: [2]int64
+        offsetToSpillArea: 40 spillAreaSize: 80
+`)
+	abitestConfig(t, configAMD64, ft, exp)
+
+	// With 16 integer registers, all the integers fit.
+	exp = makeExpectedDump(`
+        IN 0: R{ I0 } spilloffset: 0 typ: int64
+        IN 1: R{ I1 } spilloffset: 8 typ: int64
+        IN 2: R{ I2 } spilloffset: 16 typ: int64
+        IN 3: R{ I3 } spilloffset: 24 typ: int64
+        IN 4: R{ I4 } spilloffset: 32 typ: int64
+        IN 5: R{ I5 } spilloffset: 40 typ: int64
+        IN 6: R{ I6 } spilloffset: 48 typ: int64
+        IN 7: R{ I7 } spilloffset: 56 typ: int64
+        IN 8: R{ I8 } spilloffset: 64 typ: int64
+        IN 9: R{ I9 } spilloffset: 72 typ: int64
+        IN 10: R{ I10 } spilloffset: 80 typ: int64
+        IN 11: R{ I11 } spilloffset: 88 typ: int64
+        IN 12: R{ F0 } spilloffset: 96 typ: float64
+        OUT 0: R{ I0 } spilloffset: -1 typ: int64
+        OUT 1: R{ } offset: 0 typ: [2]int64
+        offsetToSpillArea: 16 spillAreaSize: 104
+`)
+	abitestConfig(t, configARM64, ft, exp)
+}
@@ -115,11 +115,17 @@ func nrtest(t *testing.T, ft *types.Type, expected int) {
 }
 
 func abitest(t *testing.T, ft *types.Type, exp expectedDump) {
+	abitestConfig(t, configAMD64, ft, exp)
+}
+
+// abitestConfig is like abitest, but analyzes ft with the registers
+// of config instead of those of amd64.
+func abitestConfig(t *testing.T, config *abi.ABIConfig, ft *types.Type, exp expectedDump) {
 
 	types.CalcSize(ft)
 
 	// Analyze with full set of registers.
-	regRes := configAMD64.ABIAnalyze(ft, false)
+	regRes := config.ABIAnalyze(ft, false)
 	regResString := strings.TrimSpace(regRes.String())
 
 	// Check results.
@@ -0,0 +1,107 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const apiSummarySrc = `package p
+
+import "io"
+
+const C = 1
+const S string = "s"
+
+var V []byte
+
+func F(x int, s ...string) (string, error) { return "", nil }
+func G[T any, U comparable](t T) (u U) { return }
+func f() {}
+
+type T struct {
+	X, y int
+	io.Reader
+}
+
+func (T) M() bool { return true }
+func (*T) N(io.Reader) {}
+func (T) m() {}
+
+type I interface {
+	io.Reader
+	M() bool
+	n()
+}
+
+type A = T
+type E int
+
+type L[K comparable, V any] struct {
+	K K
+	v V
+}
+
+func (l *L[K, V]) Get(k K) V { return l.v }
+`
+
+const apiSummaryWant = `pkg example.com/p, const C = 1
+pkg example.com/p, const C untyped int
+pkg example.com/p, const S = "s"
+pkg example.com/p, const S string
+pkg example.com/p, func F(int, ...string) (string, error)
+pkg example.com/p, func G[T any, U comparable](T) U
+pkg example.com/p, method (*L) Get(K) V
+pkg example.com/p, method (*T) N(io.Reader)
+pkg example.com/p, method (T) M() bool
+pkg example.com/p, type A = T
+pkg example.com/p, type E int
+pkg example.com/p, type I interface { M, Read, unexported methods }
+pkg example.com/p, type I interface, M() bool
+pkg example.com/p, type I interface, Read([]byte) (int, error)
+pkg example.com/p, type L[K comparable, V any] struct
+pkg example.com/p, type L[K comparable, V any] struct, K K
+pkg example.com/p, type T struct
+pkg example.com/p, type T struct, X int
+pkg example.com/p, type T struct, embedded io.Reader
+pkg example.com/p, var V []byte
+`
+
+// Make sure -apisummary lists the exported API of the package.
+func TestAPISummary(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestAPISummary")
+	if err != nil {
+		t.Fatalf("could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(src, []byte(apiSummarySrc), 0644); err != nil {
+		t.Fatalf("could not write source file: %v", err)
+	}
+
+	summary := filepath.Join(dir, "api.txt")
+	cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p", "example.com/p", "-o", filepath.Join(dir, "p.o"), "-apisummary", summary, src)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("could not compile: %v\n%s", err, out)
+	}
+	data, err := ioutil.ReadFile(summary)
+	if err != nil {
+		t.Fatalf("could not read summary: %v", err)
+	}
+	if got := string(data); got != apiSummaryWant {
+		t.Errorf("got summary:\n%s\nwant:\n%s", got, strings.TrimSuffix(apiSummaryWant, "\n"))
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"bytes"
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+const captureSrc = `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type noCopy struct{}
+
+func (*noCopy) Lock() {}
+
+type shape interface{ area() float64 }
+
+type rect struct{ w, h float64 }
+
+func (r rect) area() float64 { return r.w * r.h }
+
+var (
+	shapes = []shape{rect{2, 3}, rect{4, 5}}
+	total  float64
+	names  = map[string]int{"a": 1, "b": 2}
+	count  = func() int { return len(names) }()
+	unset  []byte
+)
+
+func init() {
+	for _, s := range shapes {
+		total += s.area()
+	}
+}
+
+func first() string {
+	type span struct{ lo, hi int }
+	s := span{1, 2}
+	return fmt.Sprint(s)
+}
+
+func second() string {
+	type span struct{ name string }
+	s := span{"x"}
+	return fmt.Sprint(s)
+}
+
+func adder() func(int) int {
+	var sum int
+	return func(x int) int {
+		f := func() { sum += x }
+		f()
+		return sum
+	}
+}
+
+func safeDiv(a, b int) (q int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered: %v", r)
+		}
+	}()
+	q = a / b
+	return
+}
+
+func kind(x interface{}) string {
+	switch x := x.(type) {
+	case int:
+		return fmt.Sprint("int ", x)
+	case string:
+		return "string " + strings.ToUpper(x)
+	}
+	return "other"
+}
+
+func main() {
+	var mu noCopy
+	mu.Lock()
+	add := adder()
+	add(1)
+	fmt.Println(total, count, len(unset), first(), second(), add(2))
+	_, err := safeDiv(1, 0)
+	fmt.Println(err, kind(3), kind("go"), kind(1.5))
+}
+`
+
+const captureOut = `26 2 0 {1 2} {x} 3
+recovered: runtime error: integer divide by zero int 3 string GO other
+`
+
+// TestCaptureReplay checks that a package compiled with -d=replay from
+// the capture file written by -d=capture compiles to the same code as
+// the package compiled from source, and runs the same.
+func TestCaptureReplay(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestCaptureReplay")
+	if err != nil {
+		t.Fatalf("could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(src, []byte(captureSrc), 0644); err != nil {
+		t.Fatalf("could not write source file: %v", err)
+	}
+	run := func(args ...string) []byte {
+		cmd := exec.Command(testenv.GoToolPath(t), args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%v failed: %v\n%s", args, err, out)
+		}
+		return out
+	}
+
+	run("tool", "compile", "-p", "main", "-o", "src.o", src)
+	run("tool", "compile", "-p", "main", "-d=capture="+dir, "-o", "capture.o", src)
+	run("tool", "compile", "-p", "main", "-d=replay", "-o", "replay.o", filepath.Join(dir, "main.capture"))
+
+	// Addresses depend on the layout of the object file, which
+	// includes the flags the package was compiled with.
+	addr := regexp.MustCompile(`0x[0-9a-f]+`)
+	want := addr.ReplaceAll(run("tool", "objdump", "src.o"), nil)
+	if got := addr.ReplaceAll(run("tool", "objdump", "replay.o"), nil); !bytes.Equal(got, want) {
+		t.Errorf("replayed code differs from code compiled from source:\n%s\nwant:\n%s", got, want)
+	}
+
+	run("tool", "link", "-o", "replay.exe", "replay.o")
+	cmd := exec.Command(filepath.Join(dir, "replay.exe"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("replayed program failed: %v\n%s", err, out)
+	}
+	if string(out) != captureOut {
+		t.Errorf("replayed program printed:\n%s\nwant:\n%s", out, captureOut)
+	}
+}
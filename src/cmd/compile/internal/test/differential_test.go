@@ -0,0 +1,58 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"internal/testenv"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzDifferential builds the programs made by genProgram with and
+// without optimizations, runs both, and fails if their output differs,
+// to catch miscompilations by the optimizing passes.
+func FuzzDifferential(f *testing.F) {
+	testenv.MustHaveGoBuild(f)
+
+	seeds := 8
+	if testing.Short() {
+		seeds = 2
+	}
+	for i := 0; i < seeds; i++ {
+		data := make([]byte, 256)
+		rand.New(rand.NewSource(int64(i))).Read(data)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		src := genProgram(data)
+		dir := t.TempDir()
+		prog := filepath.Join(dir, "prog.go")
+		if err := os.WriteFile(prog, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		run := func(gcflags string) string {
+			exe := filepath.Join(dir, "prog.exe")
+			cmd := exec.Command(testenv.GoToolPath(t), "build", "-gcflags="+gcflags, "-o", exe, prog)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("go build -gcflags=%q: %v\n%s\n%s", gcflags, err, out, src)
+			}
+			out, err := exec.Command(exe).CombinedOutput()
+			if err != nil {
+				t.Fatalf("running program built with -gcflags=%q: %v\n%s\n%s", gcflags, err, out, src)
+			}
+			return string(out)
+		}
+
+		want := run("-N -l")
+		if got := run(""); got != want {
+			t.Fatalf("optimized program printed:\n%s\nunoptimized program printed:\n%s\nprogram:\n%s", got, want, src)
+		}
+	})
+}
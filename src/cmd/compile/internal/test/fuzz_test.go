@@ -0,0 +1,71 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"bytes"
+	"internal/testenv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cmd/compile/internal/syntax"
+	"cmd/compile/internal/types2"
+)
+
+// FuzzFrontend compiles the programs made by genProgram. It parses and
+// type-checks them in-process, then runs the whole compiler on them,
+// with the SSA checker on, and fails if the compiler crashes or
+// rejects them.
+//
+// The compiler itself runs in a separate process because it keeps its
+// state in globals and exits when it finds an error.
+func FuzzFrontend(f *testing.F) {
+	testenv.MustHaveGoBuild(f)
+
+	for _, seed := range []string{
+		"",
+		"\x03\x02\x00\x05\x05\x01\x07\x03",
+		"\x01\x03\x01\x0a\x01\x06\x02\x05\x01\x04\x07\x01\x02",
+		"\x02\x01\x02\x04\x08\x02\x03\x09\x01\x01\x05\x06\x07\x00\x01\x02",
+		strings.Repeat("\x0a\x05\x07\x03", 16),
+		strings.Repeat("\x01\x02\x03\x04\x05\x06\x07\x08\x09", 12),
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		src := genProgram(data)
+
+		file, err := syntax.Parse(syntax.NewFileBase("prog.go"), strings.NewReader(src), nil, nil, syntax.AllowGenerics)
+		if err != nil {
+			t.Fatalf("parsing generated program: %v\n%s", err, src)
+		}
+		conf := types2.Config{}
+		if _, err := conf.Check("main", []*syntax.File{file}, nil); err != nil {
+			t.Fatalf("type-checking generated program: %v\n%s", err, src)
+		}
+
+		dir := t.TempDir()
+		compile(t, dir, src, "-d=ssa/check/on")
+	})
+}
+
+// compile writes src to prog.go in dir and compiles it into prog.o,
+// with flags, failing the test if the compiler does not succeed.
+func compile(t *testing.T, dir, src string, flags ...string) {
+	prog := filepath.Join(dir, "prog.go")
+	if err := os.WriteFile(prog, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"tool", "compile", "-p=main", "-o", filepath.Join(dir, "prog.o")}
+	args = append(args, flags...)
+	cmd := exec.Command(testenv.GoToolPath(t), append(args, prog)...)
+	if out, err := cmd.CombinedOutput(); err != nil || len(bytes.TrimSpace(out)) > 0 {
+		t.Fatalf("go %s: %v\n%s\n%s", strings.Join(cmd.Args[1:], " "), err, out, src)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const generatedSrc = `// Code generated by gen from tmpl.txt. DO NOT EDIT.
+
+package main
+
+import "runtime"
+
+func main() {
+//line tmpl.txt:10
+	_, file, line, _ := runtime.Caller(0)
+	println(file, line)
+	x
+}
+`
+
+// Make sure positions in generated files are reported with how the
+// file was generated by -L, and at their line in the file, rather
+// than the template, by -d=genlines.
+func TestGeneratedPositions(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestGeneratedPositions")
+	if err != nil {
+		t.Fatalf("could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "gen.go")
+	write := func(source string) {
+		if err := ioutil.WriteFile(src, []byte(source), 0644); err != nil {
+			t.Fatalf("could not write source file: %v", err)
+		}
+	}
+
+	write(generatedSrc)
+	cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p=main", "-L", "-o", filepath.Join(dir, "gen.o"), src)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("compiling succeeded unexpectedly")
+	}
+	if want := "tmpl.txt:12[" + src + ":11:2, generated by gen from tmpl.txt]: undefined: x"; !strings.Contains(string(out), want) {
+		t.Errorf("output does not contain %q:\n%s", want, out)
+	}
+
+	write(strings.Replace(generatedSrc, "\tx\n", "", 1))
+	for _, tc := range []struct {
+		gcflags string
+		want    string
+	}{
+		{"", "tmpl.txt 10"},
+		{"-d=genlines", src + " 9"},
+	} {
+		cmd := exec.Command(testenv.GoToolPath(t), "run", "-gcflags="+tc.gcflags, src)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("could not run with -gcflags=%s: %v\n%s", tc.gcflags, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != tc.want {
+			t.Errorf("with -gcflags=%s, got %q, want %q", tc.gcflags, got, tc.want)
+		}
+	}
+}
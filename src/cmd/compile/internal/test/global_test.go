@@ -11,6 +11,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -114,3 +116,67 @@ func main() {
 		}
 	}
 }
+
+// Make sure position-independent 386 code computes the address of a
+// global, which calls a thunk to load the PC, outside of the loops
+// using it.
+func TestPICGlobalAddrHoisted(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestPICGlobalAddrHoisted")
+	if err != nil {
+		t.Fatalf("could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "x.go")
+	err = ioutil.WriteFile(src, []byte(`
+package x
+
+var tab [256]uint32
+
+func sum(b []byte) uint32 {
+	var s uint32
+	for _, c := range b {
+		s += tab[c]
+	}
+	return s
+}
+`), 0644)
+	if err != nil {
+		t.Fatalf("could not write source file: %v", err)
+	}
+
+	cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p=x", "-shared", "-S", "-o", filepath.Join(dir, "x.o"), src)
+	cmd.Env = append(os.Environ(), "GOARCH=386")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("could not compile: %v\n%s", err, out)
+	}
+
+	// The loop starts at the target of its conditional backward
+	// branch. The JMP back to the function entry after morestack
+	// is not a loop.
+	branch := regexp.MustCompile(`^\s+0x[0-9a-f]+ (\d+) \(.*\)\s+J(?:[A-LN-Z][A-Z]*|MI)\s+(\d+)$`)
+	thunk := regexp.MustCompile(`^\s+0x[0-9a-f]+ (\d+) \(.*\)\s+CALL\s+__x86\.get_pc_thunk`)
+	thunkPC, loopPC := -1, -1
+	for _, line := range strings.Split(string(out), "\n") {
+		if m := thunk.FindStringSubmatch(line); m != nil {
+			thunkPC, _ = strconv.Atoi(m[1])
+		}
+		if m := branch.FindStringSubmatch(line); m != nil {
+			pc, _ := strconv.Atoi(m[1])
+			target, _ := strconv.Atoi(m[2])
+			if target < pc {
+				loopPC = target
+			}
+		}
+	}
+	if thunkPC < 0 || loopPC < 0 {
+		t.Fatalf("could not find the thunk call and the loop in:\n%s", out)
+	}
+	if thunkPC >= loopPC {
+		t.Errorf("thunk call at %d is not before the loop at %d:\n%s", thunkPC, loopPC, out)
+	}
+}
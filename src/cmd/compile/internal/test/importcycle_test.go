@@ -0,0 +1,65 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Make sure the compiler reports an import cycle formed through
+// export data that is stale: r was compiled against an older p that
+// did not import it yet.
+func TestImportCycleExportData(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestImportCycleExportData")
+	if err != nil {
+		t.Fatalf("could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	compile := func(pkg, src string) (string, error) {
+		file := filepath.Join(dir, pkg+".go")
+		if err := ioutil.WriteFile(file, []byte(src), 0644); err != nil {
+			t.Fatalf("could not write source file: %v", err)
+		}
+		cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p", pkg, "-I", dir, "-o", filepath.Join(dir, pkg+".a"), file)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	for _, pkg := range []struct{ name, src string }{
+		{"p", "package p\ntype T int\n"},
+		{"q", "package q\nimport \"p\"\ntype S struct{ t p.T }\nfunc F(x p.T) {}\n"},
+		{"r", "package r\nimport \"q\"\nvar W q.S\nfunc G() {}\n"},
+	} {
+		if out, err := compile(pkg.name, pkg.src); err != nil {
+			t.Fatalf("could not compile %s: %v\n%s", pkg.name, err, out)
+		}
+	}
+
+	out, err := compile("p", "package p\nimport \"r\"\ntype T int\nvar _ = r.G\n")
+	if err == nil {
+		t.Fatalf("compiling p importing r succeeded unexpectedly")
+	}
+	for _, want := range []string{
+		"p.go:2:8: import cycle not allowed\n",
+		"\tp imports r\n",
+		"\tr depends on p through var W q.S (",
+		"\tto break the cycle, remove the dependency of r on p\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
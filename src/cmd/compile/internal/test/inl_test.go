@@ -96,6 +96,10 @@ func TestIntendedInlining(t *testing.T) {
 		"runtime/internal/sys": {},
 		"runtime/internal/math": {
 			"MulUintptr",
+			"AddInt64",
+			"SubInt64",
+			"AddSat64",
+			"SubSat64",
 		},
 		"bytes": {
 			"(*Buffer).Bytes",
@@ -0,0 +1,115 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"encoding/json"
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const linknameSrc = `package p
+
+import _ "unsafe"
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//go:linkname f example.com/a%2eb%2ec.F
+func f()
+
+//go:linkname G
+func G()
+`
+
+// Make sure -linknamelog records every //go:linkname directive, and
+// -linknameallow rejects those into packages not on the list.
+func TestLinknameAudit(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "TestLinknameAudit")
+	if err != nil {
+		t.Fatalf("could not create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(src, []byte(linknameSrc), 0644); err != nil {
+		t.Fatalf("could not write source file: %v", err)
+	}
+
+	compile := func(args ...string) (string, error) {
+		args = append([]string{"tool", "compile", "-p", "example.com/p", "-o", filepath.Join(dir, "p.o")}, args...)
+		cmd := exec.Command(testenv.GoToolPath(t), append(args, src)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	// The log is appended to, so compiling twice records each
+	// directive twice.
+	logFile := filepath.Join(dir, "linkname.json")
+	for i := 0; i < 2; i++ {
+		if out, err := compile("-linknamelog", logFile); err != nil {
+			t.Fatalf("could not compile: %v\n%s", err, out)
+		}
+	}
+	data, err := ioutil.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("could not read log: %v", err)
+	}
+	type record struct {
+		Package, Pos, Local, Target string
+		TargetPackage               string `json:"target_package"`
+	}
+	var got []record
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("could not decode log: %v\n%s", err, data)
+		}
+		r.Pos = filepath.Base(r.Pos)
+		got = append(got, r)
+	}
+	want := []record{
+		{"example.com/p", "p.go:5:3", "nanotime", "runtime.nanotime", "runtime"},
+		{"example.com/p", "p.go:8:3", "f", "example.com/a%2eb%2ec.F", "example.com/a.b.c"},
+		{"example.com/p", "p.go:11:3", "G", "example.com/p.G", "example.com/p"},
+	}
+	want = append(want, want...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got log records %+v, want %+v", got, want)
+	}
+
+	allowFile := filepath.Join(dir, "allow.txt")
+	if err := ioutil.WriteFile(allowFile, []byte("# linkname targets\nexample.com/...\n"), 0644); err != nil {
+		t.Fatalf("could not write allowlist: %v", err)
+	}
+	out, err := compile("-linknameallow", allowFile)
+	if err == nil {
+		t.Fatalf("compiling with -linknameallow succeeded unexpectedly")
+	}
+	if want := `p.go:5:3: //go:linkname nanotime refers to runtime.nanotime, but package "runtime" is not in the -linknameallow list`; !strings.Contains(out, want) {
+		t.Errorf("output does not contain %q:\n%s", want, out)
+	}
+	if n := strings.Count(out, "-linknameallow"); n != 1 {
+		t.Errorf("got %d errors, want 1:\n%s", n, out)
+	}
+
+	if err := ioutil.WriteFile(allowFile, []byte("runtime\nexample.com/a.b.c\n"), 0644); err != nil {
+		t.Fatalf("could not write allowlist: %v", err)
+	}
+	if out, err := compile("-linknameallow", allowFile); err != nil {
+		t.Errorf("could not compile with all packages allowed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// phaseBenchPackages are the packages compiled by BenchmarkPhases, as a
+// corpus of real-world code.
+var phaseBenchPackages = []string{"strconv", "go/parser", "encoding/json", "text/template/parse"}
+
+// phaseBenchPasses are the SSA passes timed by BenchmarkPhases.
+var phaseBenchPasses = []string{"opt", "generic_cse", "prove", "lower", "lowered_cse", "schedule", "regalloc"}
+
+// BenchmarkPhases compiles each of phaseBenchPackages, and reports the
+// time spent in each phase of the compiler, as timed by -bench, and in
+// each of phaseBenchPasses, as timed by -d=ssa/<pass>/time, as metrics
+// named for the phase or pass, so that changes to one of them can be
+// measured apart from the others.
+func BenchmarkPhases(b *testing.B) {
+	testenv.MustHaveGoBuild(b)
+
+	var debug []string
+	for _, pass := range phaseBenchPasses {
+		debug = append(debug, "ssa/"+pass+"/time")
+	}
+
+	for _, pkg := range phaseBenchPackages {
+		b.Run(strings.Replace(pkg, "/", "_", -1), func(b *testing.B) {
+			out, err := exec.Command(testenv.GoToolPath(b), "list", "-f", "{{.Dir}}\n{{range .GoFiles}}{{.}}\n{{end}}", pkg).Output()
+			if err != nil {
+				b.Fatalf("go list %s: %v", pkg, err)
+			}
+			lines := strings.Fields(string(out))
+			dir, files := lines[0], lines[1:]
+
+			tmpdir, err := ioutil.TempDir("", "BenchmarkPhases")
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer os.RemoveAll(tmpdir)
+			bench := filepath.Join(tmpdir, "bench.txt")
+			args := []string{"tool", "compile", "-p=" + pkg, "-std", "-o", filepath.Join(tmpdir, "pkg.o"), "-bench=" + bench, "-d=" + strings.Join(debug, ",")}
+
+			times := make(map[string]int64)
+			var phases []string
+			add := func(phase string, ns int64) {
+				if _, ok := times[phase]; !ok {
+					phases = append(phases, phase)
+				}
+				times[phase] += ns
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				os.Remove(bench)
+				cmd := exec.Command(testenv.GoToolPath(b), append(args, files...)...)
+				cmd.Dir = dir
+				out, err := cmd.CombinedOutput()
+				if err != nil {
+					b.Fatalf("compiling %s: %v\n%s", pkg, err, out)
+				}
+
+				// Lines like
+				//	x.go:10:6: 	generic_cse	TIME(ns)	17444	F
+				sc := bufio.NewScanner(bytes.NewReader(out))
+				for sc.Scan() {
+					f := strings.Split(sc.Text(), "\t")
+					if len(f) >= 4 && f[2] == "TIME(ns)" {
+						ns, _ := strconv.ParseInt(f[3], 10, 64)
+						add("ssa:"+f[1], ns)
+					}
+				}
+
+				// Lines like
+				//	BenchmarkCompile:strconv:fe:parse    1    65743168 ns/op ...
+				data, err := ioutil.ReadFile(bench)
+				if err != nil {
+					b.Fatal(err)
+				}
+				prefix := "BenchmarkCompile:" + pkg + ":"
+				for _, line := range strings.Split(string(data), "\n") {
+					f := strings.Fields(line)
+					if len(f) < 4 || f[3] != "ns/op" || !strings.HasPrefix(f[0], prefix) {
+						continue
+					}
+					phase := strings.TrimPrefix(f[0], prefix)
+					if strings.HasSuffix(phase, "subtotal") || phase == "total" || phase == "unaccounted" {
+						continue
+					}
+					ns, _ := strconv.ParseInt(f[2], 10, 64)
+					add(phase, ns)
+				}
+			}
+			b.StopTimer()
+
+			for _, phase := range phases {
+				b.ReportMetric(float64(times[phase])/float64(b.N), phase+"-ns/op")
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// genProgram returns a main package made of random, type-correct Go
+// code. The random choices are read from data, one byte each, so that
+// fuzzing can mutate the program by mutating data; once data runs out,
+// the simplest choice is made everywhere, so every data generates a
+// program of bounded size.
+//
+// The programs print their results with println. They do not import
+// anything, and do not panic, loop forever or depend on the order of
+// map iteration, so their output is the same however they are compiled.
+func genProgram(data []byte) string {
+	g := &progGen{data: data, consts: make(map[string]bool)}
+	g.buf.WriteString(progPrelude)
+	for i, n := 0, 1+g.choose(4); i < n; i++ {
+		g.function()
+	}
+	g.printf("func main() {\n")
+	g.indent++
+	g.push()
+	for _, f := range g.funcs {
+		switch x := g.call(f); f.result {
+		case "[]int", "map[string]int":
+			g.printf("println(len(%s))\n", x)
+		case "S":
+			g.printf("println(%s == S{})\n", x)
+		default:
+			g.printf("println(%s)\n", x)
+		}
+	}
+	g.pop()
+	g.indent--
+	g.printf("}\n")
+	return g.buf.String()
+}
+
+// progPrelude declares the types and helpers used by the generated
+// programs. The helpers guard the operations that could panic.
+const progPrelude = `package main
+
+type S struct {
+	a int
+	b string
+}
+
+func id[T any](x T) T { return x }
+
+func div(x, y int) int {
+	if y == 0 || y == -1 {
+		return x
+	}
+	return x / y
+}
+
+func get(s []int, i int) int {
+	if len(s) == 0 {
+		return i
+	}
+	return s[uint(i)%uint(len(s))]
+}
+
+func set(s []int, i, x int) {
+	if len(s) > 0 {
+		s[uint(i)%uint(len(s))] = x
+	}
+}
+
+func sub(s string, i int) string {
+	if len(s) == 0 {
+		return s
+	}
+	return s[uint(i)%uint(len(s)):]
+}
+
+`
+
+// The types of the generated values.
+var progTypes = []string{"int", "string", "bool", "[]int", "map[string]int", "S"}
+
+type progVar struct {
+	name, typ string
+	readOnly  bool // loop variable
+}
+
+type progFunc struct {
+	name   string
+	params []string
+	result string
+}
+
+type progGen struct {
+	data   []byte
+	buf    bytes.Buffer
+	indent int
+	depth  int         // nesting of expressions and statements
+	scopes [][]progVar // variables in scope, innermost last
+	nvars  int
+	funcs  []progFunc      // functions that can be called
+	consts map[string]bool // constant int and string expressions
+}
+
+// choose returns a choice in [0, n).
+func (g *progGen) choose(n int) int {
+	if len(g.data) == 0 {
+		return 0
+	}
+	b := g.data[0]
+	g.data = g.data[1:]
+	return int(b) % n
+}
+
+func (g *progGen) printf(format string, args ...interface{}) {
+	g.buf.WriteString(strings.Repeat("\t", g.indent))
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *progGen) push() { g.scopes = append(g.scopes, nil) }
+func (g *progGen) pop()  { g.scopes = g.scopes[:len(g.scopes)-1] }
+
+// declare declares a new variable of type typ and returns its name.
+// Read-only variables are not assigned to.
+func (g *progGen) declare(typ string, readOnly bool) string {
+	name := fmt.Sprintf("v%d", g.nvars)
+	g.nvars++
+	g.scopes[len(g.scopes)-1] = append(g.scopes[len(g.scopes)-1], progVar{name, typ, readOnly})
+	return name
+}
+
+// variable returns a variable of type typ in scope that can be assigned
+// to if assign is set, or "".
+func (g *progGen) variable(typ string, assign bool) string {
+	var vars []string
+	for _, scope := range g.scopes {
+		for _, v := range scope {
+			if v.typ == typ && !(assign && v.readOnly) {
+				vars = append(vars, v.name)
+			}
+		}
+	}
+	if len(vars) == 0 {
+		return ""
+	}
+	return vars[g.choose(len(vars))]
+}
+
+func (g *progGen) function() {
+	f := progFunc{
+		name:   fmt.Sprintf("f%d", len(g.funcs)),
+		result: progTypes[g.choose(len(progTypes))],
+	}
+	g.push()
+	var params []string
+	for i, n := 0, g.choose(3); i < n; i++ {
+		typ := progTypes[g.choose(len(progTypes))]
+		f.params = append(f.params, typ)
+		params = append(params, g.declare(typ, false)+" "+typ)
+	}
+	g.printf("func %s(%s) %s {\n", f.name, strings.Join(params, ", "), f.result)
+	g.indent++
+	g.stmts()
+	g.printf("return %s\n", g.expr(f.result))
+	g.indent--
+	g.printf("}\n\n")
+	g.pop()
+	g.funcs = append(g.funcs, f)
+}
+
+func (g *progGen) call(f progFunc) string {
+	var args []string
+	for _, typ := range f.params {
+		args = append(args, g.expr(typ))
+	}
+	return f.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// block generates a block of statements with its own scope.
+func (g *progGen) block() {
+	g.indent++
+	g.push()
+	g.stmts()
+	g.pop()
+	g.indent--
+}
+
+func (g *progGen) stmts() {
+	for i, n := 0, g.choose(5); i < n; i++ {
+		g.stmt()
+	}
+}
+
+func (g *progGen) stmt() {
+	g.depth++
+	defer func() { g.depth-- }()
+	n := 11
+	if g.depth > 3 {
+		n = 5 // no nested blocks
+	}
+	switch g.choose(n) {
+	case 0:
+		typ := progTypes[g.choose(len(progTypes))]
+		x := g.expr(typ)
+		v := g.declare(typ, false)
+		g.printf("%s := %s\n", v, x)
+		g.printf("_ = %s\n", v)
+	case 1:
+		typ := progTypes[g.choose(len(progTypes))]
+		if v := g.variable(typ, true); v != "" {
+			g.printf("%s = %s\n", v, g.expr(typ))
+		}
+	case 2:
+		if v := g.variable("int", true); v != "" {
+			op := []string{"+=", "-=", "*=", "|=", "^="}[g.choose(5)]
+			g.printf("%s %s %s\n", v, op, g.expr("int"))
+		}
+	case 3:
+		switch g.choose(4) {
+		case 0:
+			g.printf("println(%s)\n", g.expr("int"))
+		case 1:
+			g.printf("println(%s)\n", g.expr("string"))
+		case 2:
+			g.printf("println(%s)\n", g.expr("bool"))
+		case 3:
+			g.printf("println(len(%s))\n", g.expr("[]int"))
+		}
+	case 4:
+		switch g.choose(4) {
+		case 0:
+			g.printf("set(%s, %s, %s)\n", g.expr("[]int"), g.expr("int"), g.expr("int"))
+		case 1:
+			if m := g.variable("map[string]int", false); m != "" {
+				g.printf("%s[%s] = %s\n", m, g.expr("string"), g.expr("int"))
+			}
+		case 2:
+			if m := g.variable("map[string]int", false); m != "" {
+				g.printf("delete(%s, %s)\n", m, g.expr("string"))
+			}
+		case 3:
+			if s := g.variable("S", true); s != "" {
+				g.printf("%s.a = %s\n", s, g.expr("int"))
+			}
+		}
+	case 5:
+		g.printf("if %s {\n", g.expr("bool"))
+		g.block()
+		if g.choose(2) == 1 {
+			g.printf("} else {\n")
+			g.block()
+		}
+		g.printf("}\n")
+	case 6:
+		g.push()
+		i := g.declare("int", true)
+		g.printf("for %s := 0; %s < %d; %s++ {\n", i, i, g.choose(4), i)
+		g.block()
+		g.printf("}\n")
+		g.pop()
+	case 7:
+		x := g.expr("[]int")
+		g.push()
+		i, v := g.declare("int", true), g.declare("int", true)
+		g.printf("for %s, %s := range %s {\n", i, v, x)
+		g.printf("\t_, _ = %s, %s\n", i, v)
+		g.block()
+		g.printf("}\n")
+		g.pop()
+	case 8:
+		g.printf("switch %s {\n", g.expr("int"))
+		for c, n := 0, g.choose(3); c < n; c++ {
+			g.printf("case %d:\n", c)
+			g.block()
+		}
+		g.printf("default:\n")
+		g.block()
+		g.printf("}\n")
+	case 9:
+		g.printf("{\n")
+		g.block()
+		g.printf("}\n")
+	case 10:
+		// A closure over the variables in scope, called right away
+		// or deferred.
+		call := "func() {\n"
+		if g.choose(2) == 1 {
+			call = "defer func() {\n"
+		}
+		g.printf("%s", call)
+		g.block()
+		g.printf("}()\n")
+	}
+}
+
+// expr returns an expression of type typ.
+func (g *progGen) expr(typ string) string {
+	g.depth++
+	defer func() { g.depth-- }()
+	if g.depth > 4 {
+		return g.leaf(typ)
+	}
+	if g.choose(8) == 7 {
+		return "id(" + g.expr(typ) + ")"
+	}
+	if g.choose(6) == 5 {
+		var fs []progFunc
+		for _, f := range g.funcs {
+			if f.result == typ {
+				fs = append(fs, f)
+			}
+		}
+		if len(fs) > 0 {
+			return g.call(fs[g.choose(len(fs))])
+		}
+	}
+	switch typ {
+	case "int":
+		switch g.choose(7) {
+		case 1:
+			op := []string{"+", "-", "*", "&", "|", "^", "&^"}[g.choose(7)]
+			x, y := g.expr("int"), g.expr("int")
+			if op == "*" && g.consts[x] && g.consts[y] {
+				// Keep constant products from overflowing.
+				x = "id(" + x + ")"
+			}
+			return g.constant("("+x+" "+op+" "+y+")", g.consts[x] && g.consts[y])
+		case 2:
+			return "div(" + g.expr("int") + ", " + g.expr("int") + ")"
+		case 3:
+			x, y := g.expr("int"), g.expr("int")
+			if g.consts[x] {
+				// Keep constant shifts from overflowing, and
+				// untyped operands of other shifts from taking
+				// the type of their context.
+				x = "id(" + x + ")"
+			}
+			if g.consts[y] {
+				return "(" + x + " << (" + y + " & 7))"
+			}
+			return "(" + x + " << (uint(" + y + ") & 7))"
+		case 4:
+			x := g.expr([]string{"string", "[]int", "map[string]int"}[g.choose(3)])
+			return g.constant("len("+x+")", g.consts[x])
+		case 5:
+			return "get(" + g.expr("[]int") + ", " + g.expr("int") + ")"
+		case 6:
+			return g.expr("S") + ".a"
+		}
+	case "string":
+		switch g.choose(5) {
+		case 1:
+			x, y := g.expr("string"), g.expr("string")
+			return g.constant("("+x+" + "+y+")", g.consts[x] && g.consts[y])
+		case 2:
+			return "sub(" + g.expr("string") + ", " + g.expr("int") + ")"
+		case 3:
+			x := g.expr("int")
+			return g.constant("string(rune("+x+" & 0x7f))", g.consts[x])
+		case 4:
+			return g.expr("S") + ".b"
+		}
+	case "bool":
+		switch g.choose(6) {
+		case 1:
+			op := []string{"==", "!=", "<", "<=", ">", ">="}[g.choose(6)]
+			return "(" + g.expr("int") + " " + op + " " + g.expr("int") + ")"
+		case 2:
+			return "(" + g.expr("string") + " == " + g.expr("string") + ")"
+		case 3:
+			return "!" + g.expr("bool")
+		case 4:
+			op := []string{"&&", "||"}[g.choose(2)]
+			return "(" + g.expr("bool") + " " + op + " " + g.expr("bool") + ")"
+		case 5:
+			return "(" + g.expr("S") + " == " + g.expr("S") + ")"
+		}
+	case "[]int":
+		switch g.choose(3) {
+		case 1:
+			return "append(" + g.expr("[]int") + ", " + g.expr("int") + ")"
+		case 2:
+			return "[]int{" + g.expr("int") + ", " + g.expr("int") + "}"
+		}
+	case "map[string]int":
+		if g.choose(2) == 1 {
+			return "map[string]int{" + g.expr("string") + ": " + g.expr("int") + "}"
+		}
+	case "S":
+		if g.choose(2) == 1 {
+			return "(S{a: " + g.expr("int") + ", b: " + g.expr("string") + "})"
+		}
+	}
+	return g.leaf(typ)
+}
+
+// leaf returns a variable or a constant of type typ.
+func (g *progGen) leaf(typ string) string {
+	if g.choose(2) == 1 {
+		if v := g.variable(typ, false); v != "" {
+			return v
+		}
+	}
+	switch typ {
+	case "int":
+		return g.constant(fmt.Sprint(g.choose(256)-128), true)
+	case "string":
+		return g.constant(fmt.Sprintf("%q", []string{"", "a", "go", "hello"}[g.choose(4)]), true)
+	case "bool":
+		return []string{"false", "true"}[g.choose(2)]
+	case "[]int":
+		return "[]int(nil)"
+	case "map[string]int":
+		return "map[string]int{}"
+	case "S":
+		return "(S{})"
+	}
+	panic("unknown type " + typ)
+}
+
+// constant returns x, recording whether it is a constant expression.
+// The generated programs must not contain constant int expressions
+// that overflow, or constant shift counts that are negative.
+func (g *progGen) constant(x string, isConst bool) string {
+	if isConst {
+		g.consts[x] = true
+	}
+	return x
+}
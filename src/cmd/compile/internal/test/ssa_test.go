@@ -165,6 +165,10 @@ func TestCode(t *testing.T) {
 	if runtime.GOARCH == "arm" || runtime.GOARCH == "mips" || runtime.GOARCH == "mips64" || runtime.GOARCH == "386" {
 		flags = append(flags, ",softfloat")
 	}
+	if !testing.Short() {
+		// Look for code that depends on the order of the SSA passes.
+		flags = append(flags, ",ssa/stress=1")
+	}
 	for _, flag := range flags {
 		args := []string{"test", "-c", "-gcflags=-d=ssa/check/on" + flag, "-o", filepath.Join(tmpdir, "code.test")}
 		args = append(args, srcs...)
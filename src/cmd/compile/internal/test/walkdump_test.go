@@ -0,0 +1,63 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const walkDumpSrc = `package p
+
+func sum(m map[string]int) (n int) {
+	for _, v := range m {
+		n += v
+	}
+	return
+}
+`
+
+// TestWalkDump checks that -d=walkdump lists the statements of a
+// function before and after walk under the source lines they are from.
+func TestWalkDump(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(src, []byte(walkDumpSrc), 0644); err != nil {
+		t.Fatalf("could not write source file: %v", err)
+	}
+	cmd := exec.Command(testenv.GoToolPath(t), "tool", "compile", "-p", "p", "-d=walkdump=p.sum", "-o", filepath.Join(dir, "p.o"), src)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("compile failed: %v\n%s", err, out)
+	}
+
+	want := []string{
+		"walk sum",
+		"p.go:4",
+		"\t-\tfor _, v = range m {",
+		"\t+\truntime.mapiterinit(",
+		"p.go:5",
+		"\t-\t\tn += v",
+		"\t+\t\tn = n + v",
+		"p.go:7",
+		"\t-\treturn",
+		"\t+\treturn",
+	}
+	dump := string(out)
+	for _, w := range want {
+		i := strings.Index(dump, w)
+		if i < 0 {
+			t.Fatalf("-d=walkdump output is missing %q after the lines before it:\n%s", w, out)
+		}
+		dump = dump[i+len(w):]
+	}
+}
@@ -65,6 +65,8 @@ func printsp()
 func printlock()
 func printunlock()
 
+func throw(string)
+
 func concatstring2(*[32]byte, string, string) string
 func concatstring3(*[32]byte, string, string, string) string
 func concatstring4(*[32]byte, string, string, string, string) string
@@ -147,13 +149,14 @@ func mapdelete_fast64(mapType *byte, hmap map[any]any, key uint64)
 func mapdelete_faststr(mapType *byte, hmap map[any]any, key string)
 func mapiternext(hiter *any)
 func mapclear(mapType *byte, hmap map[any]any)
+func mapinitbulk(mapType *byte, hmap map[any]any, keys, elems unsafe.Pointer, n int)
 
 // *byte is really *runtime.Type
 func makechan64(chanType *byte, size int64) (hchan chan any)
 func makechan(chanType *byte, size int) (hchan chan any)
-func chanrecv1(hchan <-chan any, elem *any)
+func chanrecv1(hchan <-chan any, elem *any, flags uint8)
 func chanrecv2(hchan <-chan any, elem *any) bool
-func chansend1(hchan chan<- any, elem *any)
+func chansend1(hchan chan<- any, elem *any, flags uint8)
 func closechan(hchan any)
 
 var writeBarrier struct {
@@ -237,6 +240,9 @@ func complex128div(num complex128, den complex128) (quo complex128)
 func getcallerpc() uintptr
 func getcallersp() uintptr
 
+// go:cpu function variants; always intrinsified
+func cpuDispatch(level uint8) bool
+
 // race detection
 func racefuncenter(uintptr)
 func racefuncexit()
@@ -270,5 +276,6 @@ func libfuzzerTraceConstCmp8(uint64, uint64)
 var x86HasPOPCNT bool
 var x86HasSSE41 bool
 var x86HasFMA bool
+var x86Level uint8
 var armHasVFPv4 bool
 var arm64HasATOMICS bool
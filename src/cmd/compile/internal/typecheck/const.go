@@ -564,6 +564,191 @@ func EvalConst(n ir.Node) ir.Node {
 	return n
 }
 
+// maxFoldedString is the length of the longest string FoldString
+// creates from expressions that are not constant.
+const maxFoldedString = 64 << 10
+
+// FoldString returns a literal with the value of n if n computes a
+// string, byte, integer or boolean from constant strings in a way
+// the language does not consider constant: indexing, slicing,
+// comparing or concatenating the results, converting to []byte or
+// []rune and back, or calling strings.Repeat with constant arguments.
+// Otherwise, or if the result would be longer than maxFoldedString,
+// FoldString returns n.
+func FoldString(n ir.Node) ir.Node {
+	if n.Op() == ir.OLITERAL || n.Type() == nil {
+		return n
+	}
+	if v := foldString(n); v != nil {
+		return OrigConst(n, v)
+	}
+	return n
+}
+
+// foldString returns the value of n for FoldString, or nil.
+func foldString(n ir.Node) constant.Value {
+	if len(n.Init()) != 0 {
+		return nil
+	}
+	switch n.Op() {
+	case ir.OLITERAL:
+		return n.Val()
+
+	case ir.OCONV, ir.OCONVNOP:
+		n := n.(*ir.ConvExpr)
+		if n.Type().IsString() && n.X.Type().IsString() {
+			return foldString(n.X)
+		}
+
+	case ir.ORUNESTR:
+		n := n.(*ir.ConvExpr)
+		if v := foldString(n.X); v != nil && v.Kind() == constant.Int {
+			return tostr(v)
+		}
+
+	case ir.OBYTES2STR, ir.ORUNES2STR:
+		n := n.(*ir.ConvExpr)
+		switch x := n.X; x.Op() {
+		case ir.OSTR2BYTES:
+			return foldString(x.(*ir.ConvExpr).X)
+		case ir.OSTR2RUNES:
+			if s, ok := foldStringVal(x.(*ir.ConvExpr).X); ok {
+				return constant.MakeString(string([]rune(s)))
+			}
+		case ir.OSLICELIT:
+			// Conversions of constant strings to []rune are
+			// rewritten to slice literals.
+			return foldSliceLit(n.Op(), x.(*ir.CompLitExpr))
+		}
+
+	case ir.OLEN:
+		n := n.(*ir.UnaryExpr)
+		if s, ok := foldStringVal(n.X); ok {
+			return constant.MakeInt64(int64(len(s)))
+		}
+
+	case ir.OINDEX:
+		n := n.(*ir.IndexExpr)
+		if !n.X.Type().IsString() {
+			break
+		}
+		s, ok1 := foldStringVal(n.X)
+		i, ok2 := foldIntVal(n.Index)
+		if ok1 && ok2 && 0 <= i && i < int64(len(s)) {
+			return constant.MakeInt64(int64(s[i]))
+		}
+
+	case ir.OSLICESTR:
+		n := n.(*ir.SliceExpr)
+		s, ok := foldStringVal(n.X)
+		if !ok {
+			break
+		}
+		low, high := int64(0), int64(len(s))
+		if n.Low != nil {
+			if low, ok = foldIntVal(n.Low); !ok {
+				break
+			}
+		}
+		if n.High != nil {
+			if high, ok = foldIntVal(n.High); !ok {
+				break
+			}
+		}
+		if 0 <= low && low <= high && high <= int64(len(s)) {
+			return constant.MakeString(s[low:high])
+		}
+
+	case ir.OADDSTR:
+		n := n.(*ir.AddStringExpr)
+		var b strings.Builder
+		for _, x := range n.List {
+			s, ok := foldStringVal(x)
+			if !ok || b.Len()+len(s) > maxFoldedString {
+				return nil
+			}
+			b.WriteString(s)
+		}
+		return constant.MakeString(b.String())
+
+	case ir.OEQ, ir.ONE, ir.OLT, ir.OLE, ir.OGT, ir.OGE:
+		n := n.(*ir.BinaryExpr)
+		if !n.X.Type().IsString() {
+			break
+		}
+		x, ok1 := foldStringVal(n.X)
+		y, ok2 := foldStringVal(n.Y)
+		if ok1 && ok2 {
+			return constant.MakeBool(constant.Compare(constant.MakeString(x), tokenForOp[n.Op()], constant.MakeString(y)))
+		}
+
+	case ir.OCALLFUNC:
+		n := n.(*ir.CallExpr)
+		fn, ok := n.X.(*ir.Name)
+		if !ok || fn.Class != ir.PFUNC || fn.Sym().Pkg.Path != "strings" || fn.Sym().Name != "Repeat" || len(n.Args) != 2 {
+			break
+		}
+		s, ok1 := foldStringVal(n.Args[0])
+		count, ok2 := foldIntVal(n.Args[1])
+		if ok1 && ok2 && count >= 0 && (count == 0 || int64(len(s)) <= maxFoldedString/count) {
+			return constant.MakeString(strings.Repeat(s, int(count)))
+		}
+	}
+	return nil
+}
+
+// foldSliceLit returns the string that converting lit, a []byte or
+// []rune literal with constant elements, to string with op gives.
+func foldSliceLit(op ir.Op, lit *ir.CompLitExpr) constant.Value {
+	if lit.Len > maxFoldedString {
+		return nil
+	}
+	elems := make([]int64, lit.Len)
+	i := int64(0)
+	for _, e := range lit.List {
+		if e.Op() == ir.OKEY {
+			kv := e.(*ir.KeyExpr)
+			k, ok := foldIntVal(kv.Key)
+			if !ok {
+				return nil
+			}
+			i, e = k, kv.Value
+		}
+		v, ok := foldIntVal(e)
+		if !ok || i < 0 || i >= lit.Len {
+			return nil
+		}
+		elems[i] = v
+		i++
+	}
+
+	var b strings.Builder
+	for _, v := range elems {
+		if op == ir.OBYTES2STR {
+			b.WriteByte(byte(v))
+		} else {
+			b.WriteRune(rune(v))
+		}
+	}
+	return constant.MakeString(b.String())
+}
+
+// foldStringVal returns the string FoldString finds n evaluates to.
+func foldStringVal(n ir.Node) (string, bool) {
+	if v := foldString(n); v != nil && v.Kind() == constant.String {
+		return constant.StringVal(v), true
+	}
+	return "", false
+}
+
+// foldIntVal returns the int64 FoldString finds n evaluates to.
+func foldIntVal(n ir.Node) (int64, bool) {
+	if v := foldString(n); v != nil && v.Kind() == constant.Int {
+		return constant.Int64Val(v)
+	}
+	return 0, false
+}
+
 func makeFloat64(f float64) constant.Value {
 	if math.IsInf(f, 0) {
 		base.Fatalf("infinity is not a valid constant")
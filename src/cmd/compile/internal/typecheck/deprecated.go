@@ -0,0 +1,30 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typecheck
+
+import (
+	"strings"
+
+	"cmd/compile/internal/ir"
+)
+
+// deprecated maps the package-level declarations and methods whose doc
+// comments have a "Deprecated:" paragraph to that paragraph, joined
+// into one line. The notices of imported declarations are read from
+// export data, and those of the local package are written to it.
+var deprecated = make(map[*ir.Name]string)
+
+// Deprecation returns the deprecation notice of n, without its
+// "Deprecated:" prefix, and whether n is deprecated at all.
+func Deprecation(n *ir.Name) (string, bool) {
+	notice, ok := deprecated[n]
+	return strings.TrimSpace(strings.TrimPrefix(notice, "Deprecated:")), ok
+}
+
+// SetDeprecation records the deprecation notice of n, as written in its
+// doc comment, starting with "Deprecated:".
+func SetDeprecation(n *ir.Name, notice string) {
+	deprecated[n] = notice
+}
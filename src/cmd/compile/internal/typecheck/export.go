@@ -16,7 +16,9 @@ import (
 // importalias declares symbol s as an imported type alias with type t.
 // ipkg is the package being imported
 func importalias(pos src.XPos, s *types.Sym, t *types.Type) *ir.Name {
-	return importobj(pos, s, ir.OTYPE, ir.PEXTERN, t)
+	n := importobj(pos, s, ir.OTYPE, ir.PEXTERN, t)
+	n.SetAlias(true)
+	return n
 }
 
 // importconst declares symbol s as an imported constant with type t and value val.
@@ -187,6 +187,13 @@ func tcArith(n ir.Node, op ir.Op, l, r ir.Node) (ir.Node, ir.Node, *types.Type)
 		}
 	}
 
+	if op == ir.OEQ || op == ir.ONE {
+		if why := NoCompareReason(l.Type(), r.Type()); why != "" {
+			base.Errorf("invalid operation: %v (%s)", n, why)
+			return l, r, nil
+		}
+	}
+
 	if (op == ir.ODIV || op == ir.OMOD) && ir.IsConst(r, constant.Int) {
 		if constant.Sign(r.Val()) == 0 {
 			base.Errorf("division by zero")
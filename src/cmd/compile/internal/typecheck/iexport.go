@@ -305,6 +305,27 @@ func WriteExports(out io.Writer, extensions bool) {
 		crawlExports(Target.Exports)
 	}
 
+	h := md5.New()
+	writeExports(io.MultiWriter(out, h), Target.Exports, extensions, false)
+
+	// Add fingerprint (used by linker object file).
+	// Attach this to the end, so tools (e.g. gcimporter) don't care.
+	copy(base.Ctxt.Fingerprint[:], h.Sum(nil)[:])
+	out.Write(base.Ctxt.Fingerprint[:])
+}
+
+// WriteBodies writes the declarations of roots, and of everything
+// they refer to, in the indexed export format to out, including the
+// bodies of the functions whose Inl is set and marked for export,
+// whether or not they could be inlined. Unlike WriteExports, it does
+// not crawl for bodies to export nor compute a fingerprint, and it
+// exports assignments of zero values, which the importer leaves out
+// but for those it adds after each ODCL.
+func WriteBodies(out io.Writer, roots []*ir.Name) {
+	writeExports(out, roots, true, true)
+}
+
+func writeExports(out io.Writer, roots []*ir.Name, extensions, bodies bool) {
 	p := iexporter{
 		allPkgs:     map[*types.Pkg]bool{},
 		stringIndex: map[string]uint64{},
@@ -312,6 +333,7 @@ func WriteExports(out io.Writer, extensions bool) {
 		inlineIndex: map[*types.Sym]uint64{},
 		typIndex:    map[*types.Type]uint64{},
 		extensions:  extensions,
+		bodies:      bodies,
 	}
 
 	for i, pt := range predeclared() {
@@ -322,7 +344,7 @@ func WriteExports(out io.Writer, extensions bool) {
 	}
 
 	// Initialize work queue with exported declarations.
-	for _, n := range Target.Exports {
+	for _, n := range roots {
 		p.pushDecl(n)
 	}
 
@@ -352,16 +374,9 @@ func WriteExports(out io.Writer, extensions bool) {
 	hdr.uint64(dataLen)
 
 	// Flush output.
-	h := md5.New()
-	wr := io.MultiWriter(out, h)
-	io.Copy(wr, &hdr)
-	io.Copy(wr, &p.strings)
-	io.Copy(wr, &p.data0)
-
-	// Add fingerprint (used by linker object file).
-	// Attach this to the end, so tools (e.g. gcimporter) don't care.
-	copy(base.Ctxt.Fingerprint[:], h.Sum(nil)[:])
-	out.Write(base.Ctxt.Fingerprint[:])
+	io.Copy(out, &hdr)
+	io.Copy(out, &p.strings)
+	io.Copy(out, &p.data0)
 }
 
 // writeIndex writes out a symbol index. mainIndex indicates whether
@@ -435,6 +450,10 @@ type iexporter struct {
 	typIndex    map[*types.Type]uint64
 
 	extensions bool
+
+	// bodies is set by WriteBodies, whose function bodies must be
+	// exported exactly, including assignments of zero values.
+	bodies bool
 }
 
 // stringOff returns the offset of s within the string section.
@@ -1385,11 +1404,13 @@ func (w *exportWriter) constExt(n *ir.Name) {
 		w.mprat(constant.Real(v))
 		w.mprat(constant.Imag(v))
 	}
+	w.deprecation(n)
 }
 
 func (w *exportWriter) varExt(n *ir.Name) {
 	w.linkname(n.Sym())
 	w.symIdx(n.Sym())
+	w.deprecation(n)
 }
 
 func (w *exportWriter) funcExt(n *ir.Name) {
@@ -1402,6 +1423,9 @@ func (w *exportWriter) funcExt(n *ir.Name) {
 	w.uint64(uint64(n.Func.ABI))
 
 	w.uint64(uint64(n.Func.Pragma))
+	w.uint64(uint64(n.Func.Effects))
+	w.uint64(uint64(n.Func.KeepsChansOpen))
+	w.deprecation(n)
 
 	// Escape analysis.
 	for _, fs := range &types.RecvsParams {
@@ -1439,6 +1463,11 @@ func (w *exportWriter) methExt(m *types.Field) {
 	w.funcExt(m.Nname.(*ir.Name))
 }
 
+// deprecation writes the deprecation notice of n, or "" if it has none.
+func (w *exportWriter) deprecation(n *ir.Name) {
+	w.string(deprecated[n])
+}
+
 func (w *exportWriter) linkname(s *types.Sym) {
 	w.string(s.Linkname)
 }
@@ -1458,8 +1487,14 @@ func (w *exportWriter) symIdx(s *types.Sym) {
 }
 
 func (w *exportWriter) typeExt(t *types.Type) {
-	// Export whether this type is marked notinheap.
+	// Export whether this type is marked notinheap, nocompare, nohash,
+	// cachealign or mustuse.
 	w.bool(t.NotInHeap())
+	w.bool(t.NoCompare())
+	w.bool(t.NoHash())
+	w.bool(t.CacheAlign())
+	w.bool(t.MustUse())
+	w.deprecation(t.Obj().(*ir.Name))
 	// For type T, export the index of type descriptor symbols of T and *T.
 	if i, ok := typeSymIdx[t]; ok {
 		w.int64(i[0])
@@ -1554,12 +1589,16 @@ func (w *exportWriter) stmt(n ir.Node) {
 		// preceded by the DCL which will be re-parsed and typecheck to reproduce
 		// the "v = <N>" again.
 		n := n.(*ir.AssignStmt)
-		if n.Y != nil {
+		if n.Y != nil || w.p.bodies {
 			w.op(ir.OAS)
 			w.pos(n.Pos())
 			w.stmtList(n.Init())
 			w.expr(n.X)
-			w.expr(n.Y)
+			if n.Y != nil {
+				w.expr(n.Y)
+			} else {
+				w.op(ir.OEND) // read as nil
+			}
 			w.bool(n.Def)
 		}
 
@@ -1821,6 +1860,7 @@ func (w *exportWriter) expr(n ir.Node) {
 		w.op(ir.OCLOSURE)
 		w.pos(n.Pos())
 		w.signature(n.Type())
+		w.pos(n.Func.Endlineno)
 
 		// Write out id for the Outer of each conditional variable. The
 		// conditional variable itself for this closure will be re-created
@@ -2150,6 +2190,14 @@ func (w *exportWriter) expr(n ir.Node) {
 		// if exporting, DCLCONST should just be removed as its usage
 		// has already been replaced with literals
 
+	case ir.ODCLTYPE:
+		// Only bodies written by WriteBodies can declare types, which
+		// are exported under names made unique by -d=capture.
+		n := n.(*ir.Decl)
+		w.op(ir.ODCLTYPE)
+		w.pos(n.Pos())
+		w.qualifiedIdent(n.X)
+
 	case ir.OFUNCINST:
 		n := n.(*ir.InstExpr)
 		w.op(ir.OFUNCINST)
@@ -2245,8 +2293,13 @@ func (w *exportWriter) localIdent(s *types.Sym) {
 	// The name of autotmp variables isn't important; they just need to
 	// be unique. To stabilize the export data, simply write out "$" as
 	// a marker and let the importer generate its own unique name.
+	// WriteBodies keeps the names, which order variables in stack frames.
 	if strings.HasPrefix(name, ".autotmp_") {
-		w.string("$autotmp")
+		if w.p.bodies {
+			w.string(name)
+		} else {
+			w.string("$autotmp")
+		}
 		return
 	}
 
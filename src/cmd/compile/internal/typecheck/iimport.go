@@ -61,6 +61,15 @@ func expandDecl(n ir.Node) ir.Node {
 	return r.doDecl(n.Sym())
 }
 
+// ImportDecl reads in the declaration of sym from the export data
+// given to ReadImports, unless it has been read in already, and
+// returns it, or nil if there is no such declaration. Unlike Resolve,
+// it also reads in declarations in the local package, for -d=replay.
+func ImportDecl(sym *types.Sym) *ir.Name {
+	n, _ := expandDecl(ir.NewIdent(src.NoXPos, sym)).(*ir.Name)
+	return n
+}
+
 // ImportBody reads in the dcls and body of an imported function (which should not
 // yet have been read in).
 func ImportBody(fn *ir.Func) {
@@ -397,11 +406,12 @@ func (r *importReader) doDecl(sym *types.Sym) *ir.Name {
 		t.Methods().Set(ms)
 
 		// Finish up all instantiations and CheckSize calls now
-		// that a top-level type is fully constructed.
+		// that a top-level type is fully constructed. The size
+		// depends on go:cachealign, which is in the extension.
+		r.typeExt(t)
 		resumeDoInst()
 		types.ResumeCheckSize()
 
-		r.typeExt(t)
 		for _, m := range ms {
 			r.methExt(m)
 		}
@@ -992,11 +1002,13 @@ func (r *importReader) constExt(n *ir.Name) {
 		im := r.mprat(constant.Imag(v))
 		n.SetVal(makeComplex(re, im))
 	}
+	r.deprecation(n)
 }
 
 func (r *importReader) varExt(n *ir.Name) {
 	r.linkname(n.Sym())
 	r.symIdx(n.Sym())
+	r.deprecation(n)
 }
 
 func (r *importReader) funcExt(n *ir.Name) {
@@ -1008,6 +1020,9 @@ func (r *importReader) funcExt(n *ir.Name) {
 	// Make sure //go:noinline pragma is imported (so stenciled functions have
 	// same noinline status as the corresponding generic function.)
 	n.Func.Pragma = ir.PragmaFlag(r.uint64())
+	n.Func.Effects = ir.FuncEffects(r.uint64())
+	n.Func.KeepsChansOpen = uint32(r.uint64())
+	r.deprecation(n)
 
 	// Escape analysis.
 	for _, fs := range &types.RecvsParams {
@@ -1033,6 +1048,12 @@ func (r *importReader) methExt(m *types.Field) {
 	r.funcExt(m.Nname.(*ir.Name))
 }
 
+func (r *importReader) deprecation(n *ir.Name) {
+	if notice := r.string(); notice != "" {
+		SetDeprecation(n, notice)
+	}
+}
+
 func (r *importReader) linkname(s *types.Sym) {
 	s.Linkname = r.string()
 }
@@ -1051,6 +1072,11 @@ func (r *importReader) symIdx(s *types.Sym) {
 
 func (r *importReader) typeExt(t *types.Type) {
 	t.SetNotInHeap(r.bool())
+	t.SetNoCompare(r.bool())
+	t.SetNoHash(r.bool())
+	t.SetCacheAlign(r.bool())
+	t.SetMustUse(r.bool())
+	r.deprecation(t.Obj().(*ir.Name))
 	SetBaseTypeIndex(t, r.int64(), r.int64())
 }
 
@@ -1155,6 +1181,9 @@ func (r *importReader) readNames(fn *ir.Func) []*ir.Name {
 		n.Class = ir.PAUTO // overwritten below for parameters/results
 		n.Curfn = fn
 		n.SetType(r.typ())
+		if strings.HasPrefix(n.Sym().Name, ".autotmp_") {
+			n.SetAutoTemp(true)
+		}
 		dcls[i] = n
 	}
 	r.allDcls = append(r.allDcls, dcls...)
@@ -1372,11 +1401,13 @@ func (r *importReader) node() ir.Node {
 		//println("Importing CLOSURE")
 		pos := r.pos()
 		typ := r.signature(nil, nil)
+		endlineno := r.pos()
 
 		// All the remaining code below is similar to (*noder).funcLit(), but
 		// with Dcls and ClosureVars lists already set up
 		fn := ir.NewClosureFunc(pos, true)
 		fn.Nname.SetType(typ)
+		fn.Endlineno = endlineno
 
 		cvars := make([]*ir.Name, r.int64())
 		for i := range cvars {
@@ -1686,6 +1717,10 @@ func (r *importReader) node() ir.Node {
 		stmts.Append(ir.NewAssignStmt(n.Pos(), n, nil))
 		return ir.NewBlockStmt(n.Pos(), stmts)
 
+	case ir.ODCLTYPE:
+		pos := r.pos()
+		return ir.NewDecl(pos, ir.ODCLTYPE, expandDecl(r.qualifiedIdent()).(*ir.Name))
+
 	// case OASWB:
 	// 	unreachable - never exported
 
@@ -0,0 +1,108 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typecheck
+
+import (
+	"fmt"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+)
+
+// CheckMustUse reports calls in fn whose results are discarded,
+// although the function called or the type of one of its results is
+// marked //go:mustuse. Results are discarded by calls used as
+// statements, including in go and defer statements; assigning them
+// to the blank identifier uses them.
+//
+// CheckMustUse must run before dead code elimination and inlining,
+// so that all calls fn was written with are checked.
+func CheckMustUse(fn *ir.Func) {
+	stmts := func(list ir.Nodes) {
+		for _, n := range list {
+			checkMustUse(n)
+		}
+	}
+	stmts(fn.Body)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		stmts(n.Init())
+		switch n := n.(type) {
+		case *ir.BlockStmt:
+			stmts(n.List)
+		case *ir.IfStmt:
+			stmts(n.Body)
+			stmts(n.Else)
+		case *ir.ForStmt:
+			stmts(n.Body)
+			if n.Post != nil {
+				checkMustUse(n.Post)
+			}
+		case *ir.RangeStmt:
+			stmts(n.Body)
+		case *ir.CaseClause:
+			stmts(n.Body)
+		case *ir.CommClause:
+			stmts(n.Body)
+		case *ir.GoDeferStmt:
+			checkMustUse(n.Call)
+		}
+	})
+}
+
+// checkMustUse reports n if it is a call whose results must be used.
+func checkMustUse(n ir.Node) {
+	switch n.Op() {
+	case ir.OCALLFUNC, ir.OCALLMETH, ir.OCALLINTER:
+	default:
+		return
+	}
+	call := n.(*ir.CallExpr)
+	if why := MustUseReason(call); why != "" {
+		var fn ir.Node = call.X
+		if callee := calleeName(call); callee != nil {
+			fn = callee
+		}
+		base.ErrorfAt(call.Pos(), "result of call to %v is not used (%s)", fn, why)
+	}
+}
+
+// MustUseReason returns why the results of call must not be discarded
+// because of a //go:mustuse directive, or "" if they may.
+func MustUseReason(call *ir.CallExpr) string {
+	callee := calleeName(call)
+	if callee != nil && callee.Func != nil && callee.Func.Pragma&ir.MustUse != 0 {
+		return fmt.Sprintf("%v is marked go:mustuse", callee)
+	}
+
+	ft := call.X.Type()
+	if ft == nil || ft.Kind() != types.TFUNC {
+		return ""
+	}
+	for _, f := range ft.Results().FieldSlice() {
+		t := f.Type
+		if t.IsPtr() && t.Elem().MustUse() {
+			t = t.Elem()
+		}
+		if t.MustUse() {
+			return fmt.Sprintf("%v is marked go:mustuse", t)
+		}
+	}
+	return ""
+}
+
+// calleeName returns the function or method call statically calls, if
+// any.
+func calleeName(call *ir.CallExpr) *ir.Name {
+	switch x := call.X; x.Op() {
+	case ir.ONAME:
+		if x := x.(*ir.Name); x.Class == ir.PFUNC {
+			return x
+		}
+	case ir.OMETHEXPR, ir.ODOTMETH:
+		return ir.MethodExprName(x)
+	}
+	return nil
+}
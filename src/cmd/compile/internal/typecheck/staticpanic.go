@@ -0,0 +1,164 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typecheck
+
+import (
+	"go/constant"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+)
+
+// CheckStaticPanics reports, for -d=staticpanic, statements in fn
+// that are certain to panic when they execute: writes to a local map variable that is
+// still nil, and array indexes by a local variable whose constant
+// value is out of range.
+//
+// The analysis only follows values through the straight-line
+// statements of a single block, up to the next label. A variable is
+// tracked from an assignment of nil or a constant until the next
+// statement that might change it; variables that have their address
+// taken or are captured by a closure are never tracked.
+//
+// CheckStaticPanics must run after dead code elimination and before
+// inlining, so that only reachable code fn was written with is
+// reported.
+func CheckStaticPanics(fn *ir.Func) {
+	c := staticPanicChecker{captured: make(map[*ir.Name]bool)}
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		if n.Op() == ir.OCLOSURE {
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				c.captured[cv.Canonical()] = true
+			}
+		}
+	})
+
+	c.stmts(fn.Body)
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n := n.(type) {
+		case *ir.BlockStmt:
+			c.stmts(n.List)
+		case *ir.IfStmt:
+			c.stmts(n.Body)
+			c.stmts(n.Else)
+		case *ir.ForStmt:
+			c.stmts(n.Body)
+		case *ir.RangeStmt:
+			c.stmts(n.Body)
+		case *ir.CaseClause:
+			c.stmts(n.Body)
+		case *ir.CommClause:
+			c.stmts(n.Body)
+		}
+	})
+}
+
+type staticPanicChecker struct {
+	captured map[*ir.Name]bool
+
+	// known maps each tracked variable to its value: a constant
+	// for integers, and nil for maps.
+	known map[*ir.Name]constant.Value
+}
+
+// stmts checks the statement list of a single block.
+func (c *staticPanicChecker) stmts(list ir.Nodes) {
+	c.known = make(map[*ir.Name]constant.Value)
+	for _, n := range list {
+		if n.Op() == ir.OLABEL {
+			// A goto may arrive here without running the
+			// assignments above.
+			c.known = make(map[*ir.Name]constant.Value)
+		}
+		ir.Visit(n, c.forgetAssigned)
+		if len(c.known) != 0 {
+			ir.Visit(n, c.report)
+		}
+		c.track(n)
+	}
+}
+
+// trackable reports whether n is a local variable whose value can
+// only change by an assignment naming it.
+func (c *staticPanicChecker) trackable(n ir.Node) (*ir.Name, bool) {
+	name, ok := n.(*ir.Name)
+	if !ok || name.Class != ir.PAUTO || name.Addrtaken() || c.captured[name] {
+		return nil, false
+	}
+	t := name.Type()
+	return name, t.IsMap() || t.IsInteger()
+}
+
+// track starts tracking the variable assigned by n, if n assigns
+// it a value the checker understands.
+func (c *staticPanicChecker) track(n ir.Node) {
+	if n.Op() != ir.OAS {
+		return
+	}
+	as := n.(*ir.AssignStmt)
+	name, ok := c.trackable(as.X)
+	if !ok {
+		return
+	}
+	y := as.Y
+	for y != nil && y.Op() == ir.OCONVNOP {
+		y = y.(*ir.ConvExpr).X
+	}
+	switch {
+	case name.Type().IsMap() && (y == nil || y.Op() == ir.ONIL):
+		c.known[name] = nil
+	case name.Type().IsInteger() && y == nil:
+		c.known[name] = constant.MakeInt64(0)
+	case name.Type().IsInteger() && y.Op() == ir.OLITERAL:
+		c.known[name] = y.Val()
+	}
+}
+
+// forgetAssigned stops tracking the variables n assigns to.
+func (c *staticPanicChecker) forgetAssigned(n ir.Node) {
+	forget := func(x ir.Node) {
+		if name, ok := x.(*ir.Name); ok {
+			delete(c.known, name)
+		}
+	}
+	switch n := n.(type) {
+	case *ir.AssignStmt:
+		forget(n.X)
+	case *ir.AssignOpStmt:
+		forget(n.X)
+	case *ir.AssignListStmt:
+		for _, x := range n.Lhs {
+			forget(x)
+		}
+	case *ir.RangeStmt:
+		if n.Key != nil {
+			forget(n.Key)
+		}
+		if n.Value != nil {
+			forget(n.Value)
+		}
+	}
+}
+
+// report reports n if it is certain to panic.
+func (c *staticPanicChecker) report(n ir.Node) {
+	switch n := n.(type) {
+	case *ir.IndexExpr:
+		name, ok := n.X.(*ir.Name)
+		if n.Op() == ir.OINDEXMAP && n.Assigned && ok {
+			if v, ok := c.known[name]; ok && v == nil {
+				base.WarnfAt(n.Pos(), "assignment to entry in nil map %v always panics", name)
+			}
+		}
+		index, ok := n.Index.(*ir.Name)
+		if n.Op() == ir.OINDEX && n.X.Type().IsArray() && ok {
+			if v, ok := c.known[index]; ok && v != nil {
+				if i, exact := constant.Int64Val(v); !exact || i < 0 || i >= n.X.Type().NumElem() {
+					base.WarnfAt(n.Pos(), "index %v (= %v) out of bounds [0:%d] always panics", index, v, n.X.Type().NumElem())
+				}
+			}
+		}
+	}
+}
@@ -515,6 +515,9 @@ func tcSwitchExpr(n *ir.SwitchStmt) {
 				base.ErrorfAt(n.Pos(), "cannot switch on %L", n.Tag)
 			}
 			t = nil
+		case NoCompareReason(t) != "":
+			base.ErrorfAt(n.Pos(), "cannot switch on %L (%s)", n.Tag, NoCompareReason(t))
+			t = nil
 		}
 	}
 
@@ -1460,3 +1460,38 @@ func Shapify(t *types.Type, index int) *types.Type {
 }
 
 var shapeMap map[int]map[*types.Type]*types.Type
+
+// NoCompareReason returns why values of the given types may not be
+// compared with == and != because of a //go:nocompare directive, or ""
+// if they may.
+func NoCompareReason(ts ...*types.Type) string {
+	for _, t := range ts {
+		if t == nil {
+			continue
+		}
+		if m := types.NoCompareType(t); m != nil {
+			return markedReason(t, m, "go:nocompare")
+		}
+	}
+	return ""
+}
+
+// NoHashReason returns why type t may not be used as a map key because
+// of a //go:nohash or //go:nocompare directive, or "" if it may.
+func NoHashReason(t *types.Type) string {
+	m := types.NoHashType(t)
+	if m == nil {
+		return ""
+	}
+	if m.NoHash() {
+		return markedReason(t, m, "go:nohash")
+	}
+	return markedReason(t, m, "go:nocompare")
+}
+
+func markedReason(t, m *types.Type, directive string) string {
+	if t == m {
+		return fmt.Sprintf("%v is marked %s", t, directive)
+	}
+	return fmt.Sprintf("%v contains %v, which is marked %s", t, m, directive)
+}
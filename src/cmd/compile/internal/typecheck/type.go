@@ -127,6 +127,9 @@ func tcMapType(n *ir.MapType) ir.Node {
 	if r.Type().NotInHeap() {
 		base.Errorf("incomplete (or unallocatable) map value not allowed")
 	}
+	if why := NoHashReason(l.Type()); why != "" {
+		base.Errorf("invalid map key type %v (%s)", l.Type(), why)
+	}
 	n.SetOTYPE(types.NewMap(l.Type(), r.Type()))
 	mapqueue = append(mapqueue, n) // check map keys when all types are settled
 	return n
@@ -1750,6 +1750,18 @@ func typecheckdeftype(n *ir.Name) {
 	if n.Pragma()&ir.NotInHeap != 0 {
 		t.SetNotInHeap(true)
 	}
+	if n.Pragma()&ir.NoCompare != 0 {
+		t.SetNoCompare(true)
+	}
+	if n.Pragma()&ir.NoHash != 0 {
+		t.SetNoHash(true)
+	}
+	if n.Pragma()&ir.MustUse != 0 {
+		t.SetMustUse(true)
+	}
+	if n.Pragma()&ir.CacheAlign != 0 {
+		t.SetCacheAlign(true)
+	}
 
 	n.SetType(t)
 	n.SetTypecheck(1)
@@ -159,6 +159,36 @@ func IncomparableField(t *Type) *Field {
 	return nil
 }
 
+// NoCompareType returns the type marked go:nocompare that makes values
+// of type t unusable with == and !=, if any. That is t itself, or a
+// type of one of its array elements or struct fields.
+func NoCompareType(t *Type) *Type {
+	return findMarked(t, (*Type).NoCompare)
+}
+
+// NoHashType returns the type marked go:nohash or go:nocompare that
+// makes type t unusable as a map key, if any.
+func NoHashType(t *Type) *Type {
+	return findMarked(t, func(t *Type) bool { return t.NoHash() || t.NoCompare() })
+}
+
+func findMarked(t *Type, marked func(*Type) bool) *Type {
+	if marked(t) {
+		return t
+	}
+	switch t.Kind() {
+	case TARRAY:
+		return findMarked(t.Elem(), marked)
+	case TSTRUCT:
+		for _, f := range t.FieldSlice() {
+			if m := findMarked(f.Type, marked); m != nil {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
 // IsPaddedField reports whether the i'th field of struct type t is followed
 // by padding.
 func IsPaddedField(t *Type, i int) bool {
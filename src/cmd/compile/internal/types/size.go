@@ -57,6 +57,10 @@ func typePos(t *Type) src.XPos {
 // MaxWidth is the maximum size of a value on the target architecture.
 var MaxWidth int64
 
+// CacheLineSize is the size of a cache line on the target architecture.
+// Fields and variables of go:cachealign types occupy whole cache lines.
+var CacheLineSize int64
+
 // CalcSizeDisabled indicates whether it is safe
 // to calculate Types' widths and alignments. See CalcSize.
 var CalcSizeDisabled bool
@@ -72,6 +76,11 @@ func Rnd(o int64, r int64) int64 {
 	return (o + r - 1) &^ (r - 1)
 }
 
+// RndCacheLine rounds o up to a multiple of CacheLineSize.
+func RndCacheLine(o int64) int64 {
+	return (o + CacheLineSize - 1) &^ (CacheLineSize - 1)
+}
+
 // expandiface computes the method set for interface type t by
 // expanding embedded interfaces.
 func expandiface(t *Type) {
@@ -176,6 +185,7 @@ func calcStructOffset(errtype *Type, t *Type, o int64, flag int) int64 {
 	// flag is 0 (receiver), 1 (actual struct), or RegSize (in/out parameters)
 	isStruct := flag == 1
 	starto := o
+	padded := false
 	maxalign := int32(flag)
 	if maxalign < 1 {
 		maxalign = 1
@@ -195,6 +205,14 @@ func calcStructOffset(errtype *Type, t *Type, o int64, flag int) int64 {
 		if f.Type.align > 0 {
 			o = Rnd(o, int64(f.Type.align))
 		}
+		// Give fields of go:cachealign types cache lines of their
+		// own. Structs generated by the compiler, like map buckets,
+		// must have the layout the runtime expects.
+		pad := isStruct && !t.Noalg() && f.Type.CacheAligned()
+		if pad {
+			o = RndCacheLine(o)
+			padded = true
+		}
 		if isStruct { // For receiver/args/results, do not set, it depends on ABI
 			f.Offset = o
 		}
@@ -207,6 +225,9 @@ func calcStructOffset(errtype *Type, t *Type, o int64, flag int) int64 {
 			lastzero = o
 		}
 		o += w
+		if pad {
+			o = RndCacheLine(o)
+		}
 		maxwidth := MaxWidth
 		// On 32-bit systems, reflect tables impose an additional constraint
 		// that each field start offset must fit in 31 bits.
@@ -231,6 +252,15 @@ func calcStructOffset(errtype *Type, t *Type, o int64, flag int) int64 {
 	if flag != 0 {
 		o = Rnd(o, int64(maxalign))
 	}
+	if padded {
+		// Fill the last cache line. The alignment stays that of
+		// the fields: the runtime does not support larger ones,
+		// so values are only placed at the start of a cache line
+		// where the compiler or the memory allocator chooses
+		// their address.
+		o = RndCacheLine(o)
+	}
+	t.flags.set(typeCacheAligned, padded)
 	t.align = uint8(maxalign)
 
 	// type width only includes back to first field's offset
@@ -497,6 +527,7 @@ func CalcSize(t *Type) {
 		}
 		w = t.NumElem() * t.Elem().width
 		t.align = t.Elem().align
+		t.flags.set(typeCacheAligned, t.Elem().CacheAligned())
 
 	case TSLICE:
 		if t.Elem() == nil {
@@ -570,6 +601,19 @@ func RecalcSize(t *Type) {
 	CalcSize(t)
 }
 
+// CacheAligned reports whether fields and package-level variables of
+// type t occupy whole cache lines: whether t is a go:cachealign type,
+// or a struct or array type containing one. The padding is outside
+// the values of a go:cachealign type, so their size is that of any
+// other type with the same underlying type.
+func (t *Type) CacheAligned() bool {
+	if t.CacheAlign() {
+		return true
+	}
+	CalcSize(t)
+	return t.flags&typeCacheAligned != 0
+}
+
 func (t *Type) widthCalculated() bool {
 	return t.align > 0
 }
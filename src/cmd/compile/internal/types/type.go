@@ -181,7 +181,7 @@ type Type struct {
 	kind  Kind  // kind of type
 	align uint8 // the required alignment of this type, in bytes (0 means Width and Align have not yet been computed)
 
-	flags bitset8
+	flags bitset16
 
 	// For defined (named) generic types, a pointer to the list of type params
 	// (in order) of this type that need to be instantiated. For instantiated
@@ -207,9 +207,14 @@ const (
 	typeNoalg                  // suppress hash and eq algorithm generation
 	typeDeferwidth             // width computation has been deferred and type is on deferredTypeStack
 	typeRecur
-	typeHasTParam // there is a typeparam somewhere in the type (generic function or type)
-	typeIsShape   // represents a set of closely related types, for generics
-	typeHasShape  // there is a shape somewhere in the type
+	typeHasTParam    // there is a typeparam somewhere in the type (generic function or type)
+	typeIsShape      // represents a set of closely related types, for generics
+	typeHasShape     // there is a shape somewhere in the type
+	typeNoCompare    // values of the type must not be compared (go:nocompare)
+	typeNoHash       // values of the type must not be map keys (go:nohash)
+	typeCacheAlign   // fields and variables of the type occupy whole cache lines (go:cachealign)
+	typeCacheAligned // the type contains fields of go:cachealign types; set by CalcSize
+	typeMustUse      // results of the type must not be discarded (go:mustuse)
 )
 
 func (t *Type) NotInHeap() bool  { return t.flags&typeNotInHeap != 0 }
@@ -220,12 +225,20 @@ func (t *Type) Recur() bool      { return t.flags&typeRecur != 0 }
 func (t *Type) HasTParam() bool  { return t.flags&typeHasTParam != 0 }
 func (t *Type) IsShape() bool    { return t.flags&typeIsShape != 0 }
 func (t *Type) HasShape() bool   { return t.flags&typeHasShape != 0 }
+func (t *Type) NoCompare() bool  { return t.flags&typeNoCompare != 0 }
+func (t *Type) NoHash() bool     { return t.flags&typeNoHash != 0 }
+func (t *Type) CacheAlign() bool { return t.flags&typeCacheAlign != 0 }
+func (t *Type) MustUse() bool    { return t.flags&typeMustUse != 0 }
 
 func (t *Type) SetNotInHeap(b bool)  { t.flags.set(typeNotInHeap, b) }
 func (t *Type) SetBroke(b bool)      { t.flags.set(typeBroke, b) }
 func (t *Type) SetNoalg(b bool)      { t.flags.set(typeNoalg, b) }
 func (t *Type) SetDeferwidth(b bool) { t.flags.set(typeDeferwidth, b) }
 func (t *Type) SetRecur(b bool)      { t.flags.set(typeRecur, b) }
+func (t *Type) SetNoCompare(b bool)  { t.flags.set(typeNoCompare, b) }
+func (t *Type) SetNoHash(b bool)     { t.flags.set(typeNoHash, b) }
+func (t *Type) SetCacheAlign(b bool) { t.flags.set(typeCacheAlign, b) }
+func (t *Type) SetMustUse(b bool)    { t.flags.set(typeMustUse, b) }
 
 // Generic types should never have alg functions.
 func (t *Type) SetHasTParam(b bool) { t.flags.set(typeHasTParam, b); t.flags.set(typeNoalg, b) }
@@ -1749,6 +1762,10 @@ func (t *Type) SetVargen() {
 	t.vargen = typeGen
 }
 
+// Vargen returns the generation number assigned to t by SetVargen,
+// or 0 if it has none.
+func (t *Type) Vargen() int32 { return t.vargen }
+
 // SetUnderlying sets the underlying type. SetUnderlying automatically updates any
 // types that were waiting for this type to be completed.
 func (t *Type) SetUnderlying(underlying *Type) {
@@ -1770,6 +1787,21 @@ func (t *Type) SetUnderlying(underlying *Type) {
 	if underlying.NotInHeap() {
 		t.SetNotInHeap(true)
 	}
+	if underlying.NoCompare() {
+		t.SetNoCompare(true)
+	}
+	if underlying.NoHash() {
+		t.SetNoHash(true)
+	}
+	if underlying.MustUse() {
+		t.SetMustUse(true)
+	}
+	if underlying.flags&typeCacheAligned != 0 {
+		// Types defined from a go:cachealign type are not
+		// go:cachealign themselves, but their fields keep
+		// their padding.
+		t.flags.set(typeCacheAligned, true)
+	}
 	if underlying.Broke() {
 		t.SetBroke(true)
 	}
@@ -469,7 +469,11 @@ func (check *Checker) selector(x *operand, e *syntax.SelectorExpr) {
 				if exp == nil {
 					if !pkg.fake {
 						if check.conf.CompilerErrorMessages {
-							check.errorf(e.Sel, "undefined: %s.%s", pkg.name, sel)
+							if alt := suggestExported(pkg, sel); alt != "" {
+								check.errorf(e.Sel, "undefined: %s.%s (did you mean %s.%s?)", pkg.name, sel, pkg.name, alt)
+							} else {
+								check.errorf(e.Sel, "undefined: %s.%s", pkg.name, sel)
+							}
 						} else {
 							check.errorf(e.Sel, "%s not declared by package %s", sel, pkg.name)
 						}
@@ -556,6 +560,8 @@ func (check *Checker) selector(x *operand, e *syntax.SelectorExpr) {
 				}
 				if obj, _, _ = LookupFieldOrMethod(x.typ, x.mode == variable, check.pkg, changeCase); obj != nil {
 					why += ", but does have " + changeCase
+				} else if check.conf.CompilerErrorMessages {
+					why += check.selectorHint(x, sel, changeCase)
 				}
 			}
 
@@ -11,6 +11,7 @@ import (
 	"cmd/compile/internal/syntax"
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Instantiate instantiates the type orig with the given type arguments targs.
@@ -192,12 +193,23 @@ func (check *Checker) implements(V, T Type, qf Qualifier) error {
 		return errorf("cannot implement %s (empty type set)", T)
 	}
 
+	// Compiler error messages explain which element of T is not
+	// satisfied, and why.
+	explain := check != nil && check.conf.CompilerErrorMessages
+
 	// If T is comparable, V must be comparable.
 	// TODO(gri) the error messages could be better, here
-	if Ti.IsComparable() && !Comparable(V) {
+	//
+	// If T is only comparable because of its type terms, V isn't one
+	// of them, which compiler error messages report below instead.
+	if Ti.IsComparable() && !Comparable(V) && !(explain && Vi == nil && !Ti.typeSet().comparable) {
 		if Vi != nil && Vi.Empty() {
 			return errorf("empty interface %s does not implement %s", V, T)
 		}
+		if explain {
+			return errorf("%s does not implement comparable (%s)%s", V, check.incomparableCause(V),
+				check.constraintElement(Ti, func(e *Interface) bool { return !e.typeSet().comparable }, "comparable required by"))
+		}
 		return errorf("%s does not implement comparable", V)
 	}
 
@@ -209,6 +221,16 @@ func (check *Checker) implements(V, T Type, qf Qualifier) error {
 			//           (print warning for now)
 			// Old warning:
 			// check.softErrorf(pos, "%s does not implement %s (warning: name not updated) = %s (missing method %s)", V, T, Ti, m)
+			if explain {
+				return errorf("%s does not implement %s %s%s%s%s", V, T,
+					check.missingMethodReason(V, T, m, wrong),
+					check.otherMissingMethods(V, Ti, m),
+					check.constraintElement(Ti, func(e *Interface) bool {
+						_, f := e.typeSet().LookupMethod(m.pkg, m.name)
+						return f == nil
+					}, m.name+" method required by"),
+					check.pointerImplements(V, T))
+			}
 			if wrong != nil {
 				// TODO(gri) This can still report uninstantiated types which makes the error message
 				//           more difficult to read then necessary.
@@ -257,6 +279,9 @@ func (check *Checker) implements(V, T Type, qf Qualifier) error {
 	}) {
 		if alt != nil {
 			return errorf("%s does not implement %s (possibly missing ~ for %s in constraint %s)", V, T, alt, T)
+		} else if explain {
+			return errorf("%s does not implement %s (%s missing in %s)%s", V, T, V, check.termsString(Ti.typeSet().terms),
+				check.constraintElement(Ti, func(e *Interface) bool { return e.typeSet().terms.includes(V) }, check.sprintf("%s excluded by", V)))
 		} else {
 			return errorf("%s does not implement %s", V, T)
 		}
@@ -264,3 +289,99 @@ func (check *Checker) implements(V, T Type, qf Qualifier) error {
 
 	return nil
 }
+
+// constraintElement returns, for compiler error messages, a line naming
+// the element embedded in constraint T whose type set V isn't in, if any,
+// where ok reports whether V is in the type set of an element, and
+// reason describes why it isn't, such as "String method required by".
+func (check *Checker) constraintElement(T *Interface, ok func(*Interface) bool, reason string) string {
+	for _, e := range T.embeddeds {
+		if _, isUnion := e.(*Union); isUnion || e == universeComparable.Type() {
+			// A union written in T itself, which the error
+			// message already shows, or comparable, which
+			// speaks for itself.
+			continue
+		}
+		ei, _ := under(e).(*Interface)
+		if ei == nil {
+			continue
+		}
+		if !ok(ei) {
+			return check.sprintf("\n\t\t%s constraint element %s", reason, e)
+		}
+	}
+	return ""
+}
+
+// pointerImplements returns, for compiler error messages, a line
+// suggesting to use *V instead of V if that implements T.
+func (check *Checker) pointerImplements(V, T Type) string {
+	switch under(V).(type) {
+	case *Pointer, *Interface:
+		return ""
+	}
+	P := NewPointer(V)
+	if check.implements(P, T, check.qualifier) != nil {
+		return ""
+	}
+	return check.sprintf("\n\t\t%s implements %s", P, T)
+}
+
+// termsString returns the terms of a type set as they would be
+// written in a constraint.
+func (check *Checker) termsString(terms termlist) string {
+	var b strings.Builder
+	for i, t := range terms {
+		if i > 0 {
+			b.WriteString(" | ")
+		}
+		if t.tilde {
+			b.WriteByte('~')
+		}
+		b.WriteString(check.sprintf("%s", t.typ))
+	}
+	return b.String()
+}
+
+// incomparableCause returns, for compiler error messages, the reason
+// that T is not comparable.
+func (check *Checker) incomparableCause(T Type) string {
+	switch t := under(T).(type) {
+	case *Slice:
+		return "slice can only be compared to nil"
+	case *Map:
+		return "map can only be compared to nil"
+	case *Signature:
+		return "func can only be compared to nil"
+	case *Struct, *Array:
+		// Report the innermost field or element type that
+		// is not comparable.
+		part := T
+		for {
+			var next Type
+			switch t := under(part).(type) {
+			case *Struct:
+				for _, f := range t.fields {
+					if !Comparable(f.typ) {
+						next = f.typ
+						break
+					}
+				}
+			case *Array:
+				next = t.elem
+			}
+			if next == nil {
+				break
+			}
+			part = next
+		}
+		if _, isStruct := t.(*Struct); isStruct {
+			return check.sprintf("struct containing %s cannot be compared", part)
+		}
+		return check.sprintf("array of %s cannot be compared", part)
+	}
+	if isTypeParam(T) {
+		return check.sprintf("type parameter %s is not comparable", T)
+	}
+	return check.sprintf("%s is not comparable", T)
+}
@@ -466,6 +466,34 @@ func (check *Checker) missingMethodReason(V, T Type, m, wrongType *Func) string
 	return r
 }
 
+// otherMissingMethods returns, for compiler error messages, a line
+// for each method of T other than first that is missing from V, so
+// that all of them can be reported at once.
+func (check *Checker) otherMissingMethods(V Type, T *Interface, first *Func) string {
+	var b strings.Builder
+	for _, m := range T.typeSet().methods {
+		if m == first {
+			continue
+		}
+		m, wrongType := check.missingMethod(V, NewInterfaceType([]*Func{m}, nil), true)
+		switch {
+		case m == nil:
+			continue
+		case wrongType == nil:
+			fmt.Fprintf(&b, "\n\t\talso missing %s method", m.Name())
+		case !Identical(m.typ, wrongType.typ):
+			fmt.Fprintf(&b, "\n\t\talso wrong type for %s method\n\t\t\thave %s%s\n\t\t\twant %s%s",
+				m.Name(), wrongType.Name(), strings.TrimPrefix(check.sprintf("%s", wrongType.typ), "func"),
+				m.Name(), strings.TrimPrefix(check.sprintf("%s", m.typ), "func"))
+		case m.Name() == wrongType.Name():
+			fmt.Fprintf(&b, "\n\t\talso %s method has pointer receiver", m.Name())
+		default:
+			fmt.Fprintf(&b, "\n\t\talso missing %s method (have %s)", m.Name(), wrongType.Name())
+		}
+	}
+	return b.String()
+}
+
 func isInterfacePtr(T Type) bool {
 	p, _ := under(T).(*Pointer)
 	return p != nil && IsInterface(p.base) && !isTypeParam(p.base)
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"go/constant"
 	"go/token"
+	"strings"
 )
 
 // An operandMode specifies the (addressing) mode of an operand.
@@ -295,6 +296,7 @@ func (x *operand) assignableTo(check *Checker, T Type, reason *string) (bool, er
 				if check.conf.CompilerErrorMessages {
 					*reason = check.sprintf("%s does not implement %s %s", x.typ, T,
 						check.missingMethodReason(x.typ, T, m, wrongType))
+					*reason += check.otherMissingMethods(x.typ, Ti, m)
 				} else {
 					if wrongType != nil {
 						if Identical(m.typ, wrongType.typ) {
@@ -330,6 +332,11 @@ func (x *operand) assignableTo(check *Checker, T Type, reason *string) (bool, er
 				return false, _IncompatibleAssign
 			}
 		}
+		if Vs, _ := Vu.(*Struct); Vs != nil && reason != nil {
+			if Ts, _ := Tu.(*Struct); Ts != nil {
+				*reason = check.structDiff(Vs, Ts)
+			}
+		}
 	}
 
 	// x is a bidirectional channel value, T is a channel
@@ -408,3 +415,49 @@ var kind2tok = [...]token.Token{
 	syntax.RuneLit:   token.CHAR,
 	syntax.StringLit: token.STRING,
 }
+
+// structDiff returns a description of the differences between the
+// fields of struct types V and T, for compiler error messages, or ""
+// if they are identical.
+func (check *Checker) structDiff(V, T *Struct) string {
+	var diffs []string
+	reordered := false
+	index := make(map[string]int)
+	for i, f := range V.fields {
+		index[f.Id()] = i
+	}
+	for i, f := range T.fields {
+		j, ok := index[f.Id()]
+		if !ok {
+			diffs = append(diffs, check.sprintf("missing field %s %s", f.name, f.typ))
+			continue
+		}
+		delete(index, f.Id())
+		g := V.fields[j]
+		switch {
+		case !Identical(g.typ, f.typ):
+			diffs = append(diffs, check.sprintf("field %s has type %s, want %s", f.name, g.typ, f.typ))
+		case g.embedded != f.embedded:
+			if f.embedded {
+				diffs = append(diffs, check.sprintf("field %s is not embedded, want embedded", f.name))
+			} else {
+				diffs = append(diffs, check.sprintf("field %s is embedded, want not embedded", f.name))
+			}
+		case V.Tag(j) != T.Tag(i):
+			diffs = append(diffs, check.sprintf("field %s has tag %q, want %q", f.name, V.Tag(j), T.Tag(i)))
+		}
+		reordered = reordered || i != j
+	}
+	for _, f := range V.fields {
+		if _, ok := index[f.Id()]; ok {
+			diffs = append(diffs, check.sprintf("extra field %s %s", f.name, f.typ))
+		}
+	}
+	if len(diffs) == 0 {
+		if reordered {
+			return "struct fields are in a different order"
+		}
+		return ""
+	}
+	return "struct fields differ:\n\t\t" + strings.Join(diffs, "\n\t\t")
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file implements the "did you mean" suggestions of compiler
+// error messages for undefined names and selectors.
+
+package types2
+
+import (
+	"sort"
+	"strings"
+
+	"cmd/compile/internal/syntax"
+)
+
+// closestName returns the name in names closest to name, or "" if
+// none is close enough to be a likely misspelling. A name that only
+// differs in case is always close enough.
+func closestName(name string, names []string) string {
+	sort.Strings(names)
+	best, bestDist := "", len(name)/3+1
+	for _, n := range names {
+		if n == name || n == "_" {
+			continue
+		}
+		if strings.EqualFold(n, name) {
+			return n
+		}
+		if d := editDistance(n, name); d < bestDist {
+			best, bestDist = n, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the number of single byte insertions,
+// deletions, substitutions, and transpositions of adjacent bytes
+// needed to turn a into b.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// suggestName returns the name declared in scope at pos, or in an
+// enclosing scope, that name is most likely a misspelling of.
+func suggestName(scope *Scope, pos syntax.Pos, name string) string {
+	var names []string
+	for s := scope; s != nil; s = s.parent {
+		for _, n := range s.Names() {
+			if obj := s.Lookup(n); !pos.IsKnown() || obj.scopePos().Cmp(pos) <= 0 {
+				names = append(names, n)
+			}
+		}
+	}
+	return closestName(name, names)
+}
+
+// suggestExported returns the exported name of pkg that sel is most
+// likely a misspelling of.
+func suggestExported(pkg *Package, sel string) string {
+	var names []string
+	for _, n := range pkg.scope.Names() {
+		if isExported(n) {
+			names = append(names, n)
+		}
+	}
+	return closestName(sel, names)
+}
+
+// suggestSelector returns the field or method of T, as seen from pkg,
+// that sel is most likely a misspelling of.
+func suggestSelector(T Type, addressable bool, pkg *Package, sel string) string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			if obj, _, _ := LookupFieldOrMethod(T, addressable, pkg, name); obj != nil {
+				names = append(names, name)
+			}
+		}
+	}
+
+	// Fields and methods, including promoted ones.
+	var collect func(typ Type, depth int)
+	collect = func(typ Type, depth int) {
+		if p, _ := typ.(*Pointer); p != nil {
+			typ = p.base
+		}
+		if depth > 4 {
+			return
+		}
+		if named, _ := typ.(*Named); named != nil {
+			for i := 0; i < named.NumMethods(); i++ {
+				add(named.Method(i).name)
+			}
+		}
+		switch u := under(typ).(type) {
+		case *Struct:
+			for _, f := range u.fields {
+				add(f.name)
+				if f.embedded {
+					collect(f.typ, depth+1)
+				}
+			}
+		case *Interface:
+			for _, m := range u.typeSet().methods {
+				add(m.name)
+			}
+		}
+	}
+	collect(T, 0)
+
+	return closestName(sel, names)
+}
+
+// selectorHint returns a hint for the compiler error message about
+// the undefined selector x.sel, which does not only differ in case
+// from an accessible field or method: either that the field or method
+// exists but is unexported, or the name sel is a likely misspelling of.
+func (check *Checker) selectorHint(x *operand, sel, changeCase string) string {
+	typ := x.typ
+	if p, _ := typ.(*Pointer); p != nil {
+		typ = p.base
+	}
+	if named, _ := typ.(*Named); named != nil && named.obj.pkg != nil && named.obj.pkg != check.pkg {
+		for _, name := range []string{sel, changeCase} {
+			if obj, _, _ := LookupFieldOrMethod(x.typ, x.mode == variable, named.obj.pkg, name); obj != nil && !obj.Exported() {
+				what := "field"
+				if _, ok := obj.(*Func); ok {
+					what = "method"
+				}
+				return check.sprintf(", but does have unexported %s %s", what, name)
+			}
+		}
+	}
+	if alt := suggestSelector(x.typ, x.mode == variable, check.pkg, sel); alt != "" {
+		return check.sprintf(", did you mean %s?", alt)
+	}
+	return ""
+}
@@ -9,6 +9,7 @@ import (
 	"cmd/compile/internal/syntax"
 	"fmt"
 	"sort"
+	"strings"
 )
 
 // ----------------------------------------------------------------------------
@@ -98,6 +99,82 @@ func (s *_TypeSet) String() string {
 	return buf.String()
 }
 
+// DescribeTypeSet returns a description of the type set of the
+// constraint interface t as the type checker computes it: its specific
+// types and methods, whether its types are comparable and ordered, and
+// its core type or why it has none. It is used by -d=typeset.
+// If a qualifier is provided, it is used to format types.
+func DescribeTypeSet(t *Interface, qf Qualifier) string {
+	s := t.typeSet()
+	sprintf := func(format string, args ...interface{}) string {
+		return sprintf(qf, false, format, args...)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("types: ")
+	switch {
+	case s.IsEmpty():
+		b.WriteString("none (the type set is empty)")
+	case !s.hasTerms():
+		b.WriteString("all")
+	default:
+		for i, t := range s.terms {
+			if i > 0 {
+				b.WriteString(" | ")
+			}
+			if t.tilde {
+				b.WriteByte('~')
+			}
+			b.WriteString(sprintf("%s", t.typ))
+		}
+	}
+
+	if len(s.methods) > 0 {
+		b.WriteString("\n\tmethods: ")
+		for i, m := range s.methods {
+			if i > 0 {
+				b.WriteString("; ")
+			}
+			b.WriteString(sprintf("%s%s", m.name, strings.TrimPrefix(sprintf("%s", m.typ), "func")))
+		}
+	}
+
+	yesNo := func(ok bool) string {
+		if ok {
+			return "yes"
+		}
+		return "no"
+	}
+	b.WriteString("\n\tcomparable: " + yesNo(s.IsComparable()))
+	b.WriteString("\n\tordered: " + yesNo(s.hasTerms() && s.underIs(func(u Type) bool { return isBasic(u, IsOrdered) })))
+
+	// The core type is the single underlying type of all types in the
+	// type set, as in structuralType.
+	var core, other Type
+	if s.underIs(func(u Type) bool {
+		if u == nil {
+			return false
+		}
+		if core != nil {
+			m := match(core, u)
+			if m == nil {
+				other = u
+				return false
+			}
+			u = m
+		}
+		core = u
+		return true
+	}) {
+		b.WriteString(sprintf("\n\tcore type: %s", core))
+	} else if other != nil {
+		b.WriteString(sprintf("\n\tcore type: none (underlying types %s and %s differ)", core, other))
+	} else {
+		b.WriteString("\n\tcore type: none (no specific types)")
+	}
+	return b.String()
+}
+
 // ----------------------------------------------------------------------------
 // Implementation
 
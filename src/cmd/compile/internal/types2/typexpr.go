@@ -39,7 +39,11 @@ func (check *Checker) ident(x *operand, e *syntax.Name, def *Named, wantType boo
 			}
 		} else {
 			if check.conf.CompilerErrorMessages {
-				check.errorf(e, "undefined: %s", e.Value)
+				if alt := suggestName(check.scope, check.pos, e.Value); alt != "" {
+					check.errorf(e, "undefined: %s (did you mean %s?)", e.Value, alt)
+				} else {
+					check.errorf(e, "undefined: %s", e.Value)
+				}
 			} else {
 				check.errorf(e, "undeclared name: %s", e.Value)
 			}
@@ -0,0 +1,540 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package unassigned reports reads of large struct and array
+// variables that may still hold the zero value they were declared
+// with, because no path to the read assigns them. Such reads are
+// legal Go, but in code ported from C they often mean an
+// initialization was forgotten. It is enabled by -d=unassigned=n,
+// which reports variables of at least n bytes.
+//
+// The analysis is a forward dataflow over the function's statements
+// that tracks, for each variable declared without an initial value,
+// whether it is unassigned on every path or only on some paths.
+// Assigning to any part of the variable counts as assigning it, and
+// taking its address stops tracking it, since it may then be
+// assigned indirectly. Variables updated by read-modify-write
+// operations such as x.n++ are not analyzed, as those are the usual
+// way of using a zero value on purpose, and neither are variables
+// captured by closures or functions that contain goto statements.
+package unassigned
+
+import (
+	"go/constant"
+	"go/token"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+	"cmd/internal/src"
+)
+
+// Func reports reads of unassigned variables in fn.
+// It must run before inlining.
+func Func(fn *ir.Func) {
+	if base.Debug.Unassigned <= 0 || fn.Wrapper() || fn.Dupok() {
+		return
+	}
+
+	a := analysis{
+		exempt:   make(map[*ir.Name]bool),
+		untrack:  make(map[*ir.Name]bool),
+		reported: make(map[*ir.Name]bool),
+	}
+	hasGoto := false
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n.Op() {
+		case ir.OGOTO:
+			hasGoto = true
+		case ir.OCLOSURE:
+			for _, cv := range n.(*ir.ClosureExpr).Func.ClosureVars {
+				a.exempt[cv.Canonical()] = true
+			}
+		case ir.OASOP:
+			if r := root(n.(*ir.AssignOpStmt).X); r != nil {
+				a.exempt[r] = true
+			}
+		}
+	})
+	if hasGoto {
+		return
+	}
+
+	a.state = newState()
+	a.stmts(fn.Body)
+}
+
+// A state records the tracked variables that are unassigned at a
+// point in the function: must holds those unassigned on every path
+// to the point, and may those unassigned on at least one path.
+// must is always a subset of may.
+type state struct {
+	dead bool // the point is unreachable
+	must map[*ir.Name]bool
+	may  map[*ir.Name]bool
+}
+
+func newState() *state {
+	return &state{must: make(map[*ir.Name]bool), may: make(map[*ir.Name]bool)}
+}
+
+func (s *state) copy() *state {
+	c := &state{dead: s.dead, must: make(map[*ir.Name]bool, len(s.must)), may: make(map[*ir.Name]bool, len(s.may))}
+	for n := range s.must {
+		c.must[n] = true
+	}
+	for n := range s.may {
+		c.may[n] = true
+	}
+	return c
+}
+
+// merge returns the state at a point reached from both s and t.
+func merge(s, t *state) *state {
+	switch {
+	case s == nil || s.dead:
+		return t
+	case t == nil || t.dead:
+		return s
+	}
+	m := newState()
+	for n := range s.must {
+		if t.must[n] {
+			m.must[n] = true
+		}
+	}
+	for n := range s.may {
+		m.may[n] = true
+	}
+	for n := range t.may {
+		m.may[n] = true
+	}
+	return m
+}
+
+// A target is a statement that break can leave.
+type target struct {
+	label  *types.Sym
+	loop   bool
+	breaks *state // merge of the states at each break
+	conts  *state // merge of the states at each continue
+}
+
+type analysis struct {
+	state   *state
+	targets []*target
+	pos     src.XPos // position of the innermost expression being analyzed
+
+	exempt   map[*ir.Name]bool // captured or updated in place
+	untrack  map[*ir.Name]bool // address taken
+	reported map[*ir.Name]bool
+}
+
+// trackable reports whether the declaration of n should be tracked.
+func (a *analysis) trackable(n *ir.Name) bool {
+	if n.Class != ir.PAUTO || a.exempt[n] || a.untrack[n] || n.Sym().IsBlank() {
+		return false
+	}
+	t := n.Type()
+	return (t.IsStruct() || t.IsArray()) && t.Size() >= int64(base.Debug.Unassigned)
+}
+
+func (a *analysis) assign(n *ir.Name) {
+	delete(a.state.must, n)
+	delete(a.state.may, n)
+}
+
+func (a *analysis) read(n *ir.Name) {
+	s := a.state
+	if s.dead || !s.may[n] || a.reported[n] {
+		return
+	}
+	a.reported[n] = true
+	if s.must[n] {
+		base.WarnfAt(a.pos, "%v (%d bytes) is read before it is assigned", n, n.Type().Size())
+	} else {
+		base.WarnfAt(a.pos, "%v (%d bytes) may be read before it is assigned", n, n.Type().Size())
+	}
+}
+
+// root returns the variable that n is a part of, if any.
+func root(n ir.Node) *ir.Name {
+	for {
+		switch n.Op() {
+		case ir.ONAME:
+			return n.(*ir.Name)
+		case ir.ODOT:
+			n = n.(*ir.SelectorExpr).X
+		case ir.OINDEX:
+			n := n.(*ir.IndexExpr)
+			if !n.X.Type().IsArray() {
+				return nil
+			}
+			return root(n.X)
+		case ir.OPAREN:
+			n = n.(*ir.ParenExpr).X
+		default:
+			return nil
+		}
+	}
+}
+
+func (a *analysis) stmts(list ir.Nodes) {
+	for _, n := range list {
+		a.stmt(n)
+	}
+}
+
+func (a *analysis) stmt(n ir.Node) {
+	if n == nil {
+		return
+	}
+	a.stmts(n.Init())
+	a.pos = n.Pos()
+
+	switch n.Op() {
+	case ir.ODCL:
+		n := n.(*ir.Decl)
+		if a.trackable(n.X) {
+			a.state.must[n.X] = true
+			a.state.may[n.X] = true
+		}
+
+	case ir.OAS:
+		n := n.(*ir.AssignStmt)
+		if n.Y == nil {
+			// The implicit zeroing that follows var x T.
+			return
+		}
+		a.expr(n.Y)
+		a.lvalue(n.X)
+
+	case ir.OASOP:
+		n := n.(*ir.AssignOpStmt)
+		a.expr(n.Y)
+		a.lvalue(n.X)
+
+	case ir.OAS2, ir.OAS2DOTTYPE, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2RECV, ir.OSELRECV2:
+		n := n.(*ir.AssignListStmt)
+		for _, y := range n.Rhs {
+			a.expr(y)
+		}
+		for _, x := range n.Lhs {
+			a.lvalue(x)
+		}
+
+	case ir.OBLOCK:
+		a.stmts(n.(*ir.BlockStmt).List)
+
+	case ir.OIF:
+		n := n.(*ir.IfStmt)
+		a.expr(n.Cond)
+		entry := a.state
+		a.state = entry.copy()
+		a.stmts(n.Body)
+		then := a.state
+		a.state = entry
+		a.stmts(n.Else)
+		a.state = merge(then, a.state)
+
+	case ir.OFOR:
+		n := n.(*ir.ForStmt)
+		a.loop(n, n.Label, func() *state {
+			a.expr(n.Cond)
+			if runsBody(n) {
+				return nil
+			}
+			exit := a.state.copy()
+			if n.Cond == nil {
+				exit.dead = true
+			}
+			return exit
+		}, func(t *target) {
+			a.stmts(n.Body)
+			a.state = merge(a.state, t.conts)
+			a.stmt(n.Post)
+		})
+
+	case ir.ORANGE:
+		n := n.(*ir.RangeStmt)
+		if n.Value != nil || n.X.Type().IsChan() || n.X.Type().IsMap() {
+			a.expr(n.X)
+		} else {
+			// Only the length of an array is needed.
+			a.exprSubparts(n.X)
+		}
+		a.loop(n, n.Label, func() *state {
+			var exit *state
+			t := n.X.Type()
+			if t.IsPtr() {
+				t = t.Elem()
+			}
+			if !t.IsArray() || t.NumElem() == 0 {
+				// The body may not run at all.
+				exit = a.state.copy()
+			}
+			a.lvalue(n.Key)
+			a.lvalue(n.Value)
+			return exit
+		}, func(*target) {
+			a.stmts(n.Body)
+		})
+
+	case ir.OSWITCH:
+		n := n.(*ir.SwitchStmt)
+		a.expr(n.Tag)
+		t := a.push(n.Label, false)
+		entry := a.state
+		hasDefault := false
+		var fall *state
+		for _, cas := range n.Cases {
+			a.state = entry
+			for _, x := range cas.List {
+				a.expr(x)
+			}
+			if len(cas.List) == 0 {
+				hasDefault = true
+			}
+			a.state = merge(entry.copy(), fall)
+			a.stmts(cas.Body)
+			fall = nil
+			if len(cas.Body) > 0 && cas.Body[len(cas.Body)-1].Op() == ir.OFALL {
+				fall = a.state
+			} else {
+				t.breaks = merge(t.breaks, a.state)
+			}
+		}
+		if !hasDefault {
+			t.breaks = merge(t.breaks, entry)
+		}
+		a.pop(t)
+
+	case ir.OSELECT:
+		n := n.(*ir.SelectStmt)
+		t := a.push(n.Label, false)
+		entry := a.state
+		for _, cas := range n.Cases {
+			a.state = entry.copy()
+			a.stmt(cas.Comm)
+			a.stmts(cas.Body)
+			t.breaks = merge(t.breaks, a.state)
+		}
+		if len(n.Cases) == 0 {
+			a.state = entry.copy()
+			a.state.dead = true
+			t.breaks = merge(t.breaks, a.state)
+		}
+		a.pop(t)
+
+	case ir.OBREAK:
+		n := n.(*ir.BranchStmt)
+		if t := a.target(n.Label, false); t != nil {
+			t.breaks = merge(t.breaks, a.state)
+		}
+		a.state = a.state.copy()
+		a.state.dead = true
+
+	case ir.OCONTINUE:
+		n := n.(*ir.BranchStmt)
+		if t := a.target(n.Label, true); t != nil {
+			t.conts = merge(t.conts, a.state)
+		}
+		a.state = a.state.copy()
+		a.state.dead = true
+
+	case ir.OFALL:
+		// Handled by the enclosing switch statement.
+
+	case ir.ORETURN:
+		n := n.(*ir.ReturnStmt)
+		for _, r := range n.Results {
+			a.expr(r)
+		}
+		a.state = a.state.copy()
+		a.state.dead = true
+
+	case ir.OPANIC:
+		a.expr(n.(*ir.UnaryExpr).X)
+		a.state = a.state.copy()
+		a.state.dead = true
+
+	case ir.OLABEL:
+	default:
+		a.expr(n)
+	}
+}
+
+// loop analyzes the for or range loop n. head evaluates the loop
+// condition and returns the state in which the loop exits without
+// a break, or nil if it only does so after running the body; body
+// analyzes the body.
+func (a *analysis) loop(n ir.Node, label *types.Sym, head func() *state, body func(t *target)) {
+	// On later iterations, the variables the loop assigns are no
+	// longer certain to be unassigned at its head.
+	a.state = a.state.copy()
+	forget := func(x ir.Node) {
+		if x == nil {
+			return
+		}
+		if r := root(x); r != nil {
+			delete(a.state.must, r)
+		}
+	}
+	ir.Visit(n, func(n ir.Node) {
+		switch n := n.(type) {
+		case *ir.AssignStmt:
+			if n.Y != nil {
+				forget(n.X)
+			}
+		case *ir.AssignOpStmt:
+			forget(n.X)
+		case *ir.AssignListStmt:
+			for _, x := range n.Lhs {
+				forget(x)
+			}
+		case *ir.RangeStmt:
+			forget(n.Key)
+			forget(n.Value)
+		case *ir.AddrExpr:
+			forget(n.X)
+		}
+	})
+
+	t := a.push(label, true)
+	exit := head()
+	body(t)
+	if exit == nil {
+		exit = merge(a.state, t.conts)
+	}
+	t.breaks = merge(t.breaks, exit)
+	a.pop(t)
+}
+
+// runsBody reports whether the for loop n certainly runs its body
+// at least once, because it has the form
+//
+//	for i := c0; i < c1; i++ {
+//
+// with constants c0 < c1, or uses <=, != or > in the same way.
+func runsBody(n *ir.ForStmt) bool {
+	cond, ok := n.Cond.(*ir.BinaryExpr)
+	if !ok || cond.Y.Op() != ir.OLITERAL {
+		return false
+	}
+	i, ok := cond.X.(*ir.Name)
+	if !ok || len(n.Init()) == 0 {
+		return false
+	}
+	as, ok := n.Init()[len(n.Init())-1].(*ir.AssignStmt)
+	if !ok || as.X != i || as.Y == nil || as.Y.Op() != ir.OLITERAL {
+		return false
+	}
+	switch cond.Op() {
+	case ir.OLT, ir.OLE, ir.ONE, ir.OGT, ir.OGE:
+		return constant.Compare(as.Y.Val(), compareTokens[cond.Op()], cond.Y.Val())
+	}
+	return false
+}
+
+var compareTokens = map[ir.Op]token.Token{
+	ir.OLT: token.LSS,
+	ir.OLE: token.LEQ,
+	ir.ONE: token.NEQ,
+	ir.OGT: token.GTR,
+	ir.OGE: token.GEQ,
+}
+
+func (a *analysis) push(label *types.Sym, loop bool) *target {
+	t := &target{label: label, loop: loop}
+	a.targets = append(a.targets, t)
+	return t
+}
+
+// pop ends the statement t, which continues in the state where it
+// breaks or exits.
+func (a *analysis) pop(t *target) {
+	a.targets = a.targets[:len(a.targets)-1]
+	if t.breaks == nil {
+		t.breaks = newState()
+		t.breaks.dead = true
+	}
+	a.state = t.breaks
+}
+
+// target returns the statement that a break with the given label
+// leaves.
+func (a *analysis) target(label *types.Sym, loop bool) *target {
+	for i := len(a.targets) - 1; i >= 0; i-- {
+		t := a.targets[i]
+		if label == nil && (!loop || t.loop) || label != nil && t.label == label {
+			return t
+		}
+	}
+	return nil
+}
+
+// lvalue analyzes an assignment to n.
+func (a *analysis) lvalue(n ir.Node) {
+	if n == nil || ir.IsBlank(n) {
+		return
+	}
+	if r := root(n); r != nil {
+		a.exprSubparts(n)
+		a.assign(r)
+		return
+	}
+	a.expr(n)
+}
+
+// exprSubparts analyzes the index expressions in n, a part of a
+// variable, without reading the variable itself.
+func (a *analysis) exprSubparts(n ir.Node) {
+	for {
+		switch n.Op() {
+		case ir.ODOT:
+			n = n.(*ir.SelectorExpr).X
+		case ir.OINDEX:
+			n := n.(*ir.IndexExpr)
+			a.expr(n.Index)
+			a.exprSubparts(n.X)
+			return
+		case ir.OPAREN:
+			n = n.(*ir.ParenExpr).X
+		case ir.ONAME:
+			return
+		default:
+			a.expr(n)
+			return
+		}
+	}
+}
+
+// expr analyzes the evaluation of n.
+func (a *analysis) expr(n ir.Node) {
+	if n == nil {
+		return
+	}
+	switch n.Op() {
+	case ir.ONAME:
+		a.read(n.(*ir.Name))
+		return
+	case ir.OTYPE, ir.OLITERAL, ir.ONIL:
+		return
+	case ir.OADDR:
+		n := n.(*ir.AddrExpr)
+		if r := root(n.X); r != nil {
+			a.exprSubparts(n.X)
+			a.assign(r)
+			a.untrack[r] = true
+			return
+		}
+	case ir.OCLOSURE:
+		return
+	}
+	a.pos = n.Pos()
+	ir.DoChildren(n, func(c ir.Node) bool {
+		a.expr(c)
+		return false
+	})
+}
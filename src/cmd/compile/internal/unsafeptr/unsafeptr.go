@@ -0,0 +1,158 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package unsafeptr reports conversions between unsafe.Pointer and
+// uintptr that do not follow the patterns the unsafe package
+// documents as valid. It is enabled by -d=unsafeptr.
+//
+// A uintptr converted to unsafe.Pointer must come straight from one
+// of the valid sources, in the same expression:
+//
+//	unsafe.Pointer(uintptr(unsafe.Pointer(p)) + off) // pointer arithmetic
+//	unsafe.Pointer(v.Pointer())                      // reflect.Value.Pointer or UnsafeAddr
+//	unsafe.Pointer(hdr.Data)                         // *reflect.SliceHeader or *reflect.StringHeader
+//
+// and an unsafe.Pointer converted to uintptr in a call argument is
+// kept alive during the call only if the callee is implemented in
+// assembly or marked //go:uintptrescapes.
+package unsafeptr
+
+import (
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+	"cmd/compile/internal/types"
+)
+
+// Func reports possible misuses of unsafe.Pointer in fn.
+// It must run before inlining, so that only the code fn was
+// written with is reported.
+func Func(fn *ir.Func) {
+	if base.Debug.UnsafePtr == 0 || fn.Wrapper() || fn.Dupok() {
+		return
+	}
+	ir.VisitList(fn.Body, func(n ir.Node) {
+		switch n.Op() {
+		case ir.OCONVNOP:
+			n := n.(*ir.ConvExpr)
+			if n.Type().IsUnsafePtr() && n.X.Type().IsUintptr() {
+				checkConv(n)
+			}
+		case ir.OCALLFUNC, ir.OCALLMETH, ir.OCALLINTER:
+			checkCall(n.(*ir.CallExpr))
+		}
+	})
+}
+
+// checkConv reports the conversion n of a uintptr to unsafe.Pointer
+// unless its operand is one of the valid sources.
+func checkConv(n *ir.ConvExpr) {
+	x := n.X
+	switch {
+	case isSafeUintptr(x):
+		return
+	case x.Op() == ir.ONAME && x.(*ir.Name).Class != ir.PFUNC:
+		base.WarnfAt(n.Pos(), "possible misuse of unsafe.Pointer: uintptr variable %v does not keep its referent alive", x)
+	default:
+		base.WarnfAt(n.Pos(), "possible misuse of unsafe.Pointer: conversion of uintptr %v to unsafe.Pointer", x)
+	}
+}
+
+// isSafeUintptr reports whether x may be converted to unsafe.Pointer.
+func isSafeUintptr(x ir.Node) bool {
+	switch x.Op() {
+	case ir.OLITERAL:
+		// A fixed address, which the garbage collector doesn't manage.
+		return true
+	case ir.OPAREN:
+		return isSafeUintptr(x.(*ir.ParenExpr).X)
+	case ir.ODOTPTR:
+		return ir.IsReflectHeaderDataField(x)
+	case ir.OCALLFUNC, ir.OCALLMETH:
+		return isReflectPointerCall(x.(*ir.CallExpr))
+	case ir.OCONVNOP:
+		// uintptr(unsafe.Pointer(p)), possibly with offsets applied.
+		return x.(*ir.ConvExpr).X.Type().IsUnsafePtr()
+	case ir.OADD:
+		// A constant offset is only safe if added to a safe base.
+		x := x.(*ir.BinaryExpr)
+		switch {
+		case x.Y.Op() == ir.OLITERAL:
+			return isSafeUintptr(x.X)
+		case x.X.Op() == ir.OLITERAL:
+			return isSafeUintptr(x.Y)
+		}
+		return isSafeUintptr(x.X) || isSafeUintptr(x.Y)
+	case ir.OSUB, ir.OANDNOT:
+		// Likewise, a constant base is only safe with a constant
+		// offset or mask.
+		x := x.(*ir.BinaryExpr)
+		if x.X.Op() == ir.OLITERAL {
+			return x.Y.Op() == ir.OLITERAL
+		}
+		return isSafeUintptr(x.X)
+	}
+	return false
+}
+
+// isReflectPointerCall reports whether n is a call to
+// reflect.Value.Pointer or reflect.Value.UnsafeAddr.
+func isReflectPointerCall(n *ir.CallExpr) bool {
+	switch n.X.Op() {
+	case ir.ODOTMETH, ir.OMETHEXPR:
+	default:
+		return false
+	}
+	sel := n.X.(*ir.SelectorExpr)
+	t := sel.X.Type()
+	if t.IsPtr() {
+		t = t.Elem()
+	}
+	if s := t.Sym(); s == nil || s.Pkg.Path != "reflect" || s.Name != "Value" {
+		return false
+	}
+	name := sel.Sel.Name
+	return name == "Pointer" || name == "UnsafeAddr"
+}
+
+// checkCall reports arguments of n that convert an unsafe.Pointer to
+// uintptr when the callee doesn't keep the pointer alive.
+func checkCall(n *ir.CallExpr) {
+	var callee *ir.Func
+	switch n.X.Op() {
+	case ir.ONAME:
+		if name := n.X.(*ir.Name); name.Class == ir.PFUNC {
+			callee = name.Func
+		}
+	case ir.ODOTMETH, ir.OMETHEXPR:
+		if name := ir.MethodExprName(n.X); name != nil {
+			callee = name.Func
+		}
+	}
+	if callee != nil && keepsUintptrsAlive(callee) {
+		return
+	}
+
+	for _, arg := range n.Args {
+		if arg.Op() != ir.OCONVNOP || !arg.Type().IsUintptr() || !arg.(*ir.ConvExpr).X.Type().IsUnsafePtr() {
+			continue
+		}
+		if callee == nil {
+			base.WarnfAt(arg.Pos(), "possible misuse of unsafe.Pointer: pointer converted to uintptr is not kept alive during indirect call")
+		} else {
+			base.WarnfAt(arg.Pos(), "possible misuse of unsafe.Pointer: pointer converted to uintptr is not kept alive during call to %v (not //go:uintptrescapes)", callee.Nname)
+		}
+	}
+}
+
+// keepsUintptrsAlive reports whether the pointers converted to
+// uintptr in arguments to fn are kept alive until fn returns.
+func keepsUintptrsAlive(fn *ir.Func) bool {
+	if fn.Pragma&(ir.UintptrKeepAlive|ir.UintptrEscapes) != 0 {
+		return true
+	}
+	// Functions declared in this package are marked
+	// UintptrKeepAlive by escape analysis, which hasn't run yet.
+	// Those implemented in assembly have no body.
+	return fn.Nname != nil && fn.Nname.Sym().Pkg == types.LocalPkg && len(fn.Body) == 0
+}
@@ -8,6 +8,7 @@ import (
 	"go/constant"
 
 	"cmd/compile/internal/base"
+	"cmd/compile/internal/chanflags"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/typecheck"
@@ -77,9 +78,7 @@ func walkAssign(init *ir.Nodes, n ir.Node) ir.Node {
 		recv := as.Y.(*ir.UnaryExpr)
 		recv.X = walkExpr(recv.X, init)
 
-		n1 := typecheck.NodAddr(as.X)
-		r := recv.X // the channel
-		return mkcall1(chanfn("chanrecv1", 2, r.Type()), nil, init, r, n1)
+		return chanrecv1(recv, typecheck.NodAddr(as.X), init)
 
 	case ir.OAPPEND:
 		// x = append(...)
@@ -214,8 +213,14 @@ func walkAssignRecv(init *ir.Nodes, n *ir.AssignListStmt) ir.Node {
 	} else {
 		n1 = typecheck.NodAddr(n.Lhs[0])
 	}
-	fn := chanfn("chanrecv2", 2, r.X.Type())
 	ok := n.Lhs[1]
+	if flags := chanflags.Flags(r); flags&chanflags.NotClosed != 0 {
+		// The channel is never closed, so the receive always
+		// succeeds.
+		init.Append(chanrecv1(r, n1, init))
+		return typecheck.Stmt(ir.NewAssignStmt(base.Pos, ok, ir.NewBool(true)))
+	}
+	fn := chanfn("chanrecv2", 2, r.X.Type())
 	call := mkcall1(fn, types.Types[types.TBOOL], init, r.X, n1)
 	return typecheck.Stmt(ir.NewAssignStmt(base.Pos, ok, call))
 }
@@ -256,8 +261,8 @@ func ascompatet(nl ir.Nodes, nr *types.Type) []ir.Node {
 		r := nr.Field(i)
 
 		// Order should have created autotemps of the appropriate type for
-		// us to store results into.
-		if tmp, ok := l.(*ir.Name); !ok || !tmp.AutoTemp() || !types.Identical(tmp.Type(), r.Type) {
+		// us to store results into, or found locals that can do.
+		if tmp, ok := l.(*ir.Name); !ok || !(tmp.AutoTemp() && types.Identical(tmp.Type(), r.Type) || isResultLocal(tmp, r.Type)) {
 			base.FatalfAt(l.Pos(), "assigning %v to %+v", r.Type, l)
 		}
 
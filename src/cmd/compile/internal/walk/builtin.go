@@ -268,6 +268,10 @@ func walkMakeMap(n *ir.MakeExpr, init *ir.Nodes) ir.Node {
 	hmapType := reflectdata.MapType(t)
 	hint := n.Len
 
+	if base.Debug.EqSize > 0 {
+		reportEqCost(n.Pos(), "map key", t.Key(), 0)
+	}
+
 	// var h *hmap
 	var h ir.Node
 	if n.Esc() == ir.EscNone {
@@ -373,7 +377,7 @@ func walkMakeSlice(n *ir.MakeExpr, init *ir.Nodes) ir.Node {
 		base.Errorf("%v can't be allocated in Go; it is incomplete (or unallocatable)", t.Elem())
 	}
 	if n.Esc() == ir.EscNone {
-		if why := escape.HeapAllocReason(n); why != "" {
+		if why := escape.FuncHeapAllocReason(ir.CurFunc, n); why != "" {
 			base.Fatalf("%v has EscNone, but %v", n, why)
 		}
 		// var arr [r]T
@@ -489,7 +493,7 @@ func walkNew(n *ir.UnaryExpr, init *ir.Nodes) ir.Node {
 		base.Errorf("%v can't be allocated in Go; it is incomplete (or unallocatable)", n.Type().Elem())
 	}
 	if n.Esc() == ir.EscNone {
-		if t.Size() > ir.MaxImplicitStackVarSize {
+		if t.Size() > ir.MaxImplicitStackVarSize && ir.CurFunc.Pragma&ir.StackLocals == 0 {
 			base.Fatalf("large ONEW with EscNone: %v", n)
 		}
 		return stackTempAddr(init, t)
@@ -5,6 +5,7 @@
 package walk
 
 import (
+	"fmt"
 	"go/constant"
 
 	"cmd/compile/internal/base"
@@ -13,6 +14,7 @@ import (
 	"cmd/compile/internal/ssagen"
 	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
+	"cmd/internal/src"
 )
 
 // The result of walkCompare MUST be assigned back to n, e.g.
@@ -137,8 +139,15 @@ func walkCompare(n *ir.BinaryExpr, init *ir.Nodes) ir.Node {
 	case types.TARRAY:
 		// We can compare several elements at once with 2/4/8 byte integer compares
 		inline = t.NumElem() <= 1 || (types.IsSimple[t.Elem().Kind()] && (t.NumElem() <= 4 || t.Elem().Size()*t.NumElem() <= maxcmpsize))
+		if base.Flag.OptSize {
+			// Only unroll comparisons that merge into one or two loads.
+			inline = t.NumElem() <= 1 || (types.IsSimple[t.Elem().Kind()] && t.Elem().Size()*t.NumElem() <= maxcmpsize)
+		}
 	case types.TSTRUCT:
 		inline = t.NumComponents(types.IgnoreBlankFields) <= 4
+		if base.Flag.OptSize {
+			inline = t.NumComponents(types.IgnoreBlankFields) <= 2
+		}
 	}
 
 	cmpl := n.X
@@ -152,6 +161,9 @@ func walkCompare(n *ir.BinaryExpr, init *ir.Nodes) ir.Node {
 
 	// Chose not to inline. Call equality function directly.
 	if !inline {
+		if base.Debug.EqSize > 0 {
+			reportEqCost(n.Pos(), "comparison of", t, 0)
+		}
 		// eq algs take pointers; cmpl and cmpr must be addressable
 		if !ir.IsAddressable(cmpl) || !ir.IsAddressable(cmpr) {
 			base.Fatalf("arguments of comparison must be lvalues - %v %v", cmpl, cmpr)
@@ -177,7 +189,9 @@ func walkCompare(n *ir.BinaryExpr, init *ir.Nodes) ir.Node {
 		andor = ir.OOROR
 	}
 	var expr ir.Node
+	ncompare := 0
 	compare := func(el, er ir.Node) {
+		ncompare++
 		a := ir.NewBinaryExpr(base.Pos, n.Op(), el, er)
 		if expr == nil {
 			expr = a
@@ -254,6 +268,9 @@ func walkCompare(n *ir.BinaryExpr, init *ir.Nodes) ir.Node {
 			}
 		}
 	}
+	if base.Debug.EqSize > 0 {
+		reportEqCost(n.Pos(), "comparison of", t, ncompare)
+	}
 	if expr == nil {
 		expr = ir.NewBool(n.Op() == ir.OEQ)
 		// We still need to use cmpl and cmpr, in case they contain
@@ -412,6 +429,27 @@ func finishCompare(n *ir.BinaryExpr, r ir.Node, init *ir.Nodes) ir.Node {
 	return r
 }
 
+// reportEqCost reports, for -d=eqsize=N, uses of t's equality
+// algorithm that compare at least N bytes. inline is the number of
+// comparisons walkCompare expanded the use into, or 0 if it calls
+// memequal or the equality function generated for t.
+func reportEqCost(pos src.XPos, what string, t *types.Type, inline int) {
+	size := t.Size()
+	if size < int64(base.Debug.EqSize) || pos == base.AutogeneratedPos {
+		return
+	}
+	var cost string
+	switch a, _ := types.AlgType(t); {
+	case inline > 0:
+		cost = fmt.Sprintf("inline, %d comparisons", inline)
+	case a == types.ASPECIAL:
+		cost = reflectdata.DescribeEq(t)
+	default:
+		cost = "memequal"
+	}
+	base.WarnfAt(pos, "%s %v compares %d bytes (%s)", what, t, size, cost)
+}
+
 func eqFor(t *types.Type) (n ir.Node, needsize bool) {
 	// Should only arrive here with large memory or
 	// a struct/array containing a non-memory field/element.
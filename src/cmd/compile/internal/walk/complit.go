@@ -5,8 +5,13 @@
 package walk
 
 import (
+	"go/constant"
+	"go/token"
+	"sort"
+
 	"cmd/compile/internal/base"
 	"cmd/compile/internal/ir"
+	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/ssagen"
 	"cmd/compile/internal/staticdata"
 	"cmd/compile/internal/staticinit"
@@ -434,7 +439,17 @@ func maplit(n *ir.CompLitExpr, m ir.Node, init *ir.Nodes) {
 	}
 
 	if len(entries) > 25 {
-		// For a large number of entries, put them in an array and loop.
+		// For a large number of entries, put them in arrays and
+		// add them all with one runtime call.
+
+		// Sort constant keys, so that the arrays are the same
+		// whatever order the entries are written in.
+		if sortableKeys(entries) {
+			sort.Slice(entries, func(i, j int) bool {
+				ki, kj := entries[i].(*ir.KeyExpr).Key, entries[j].(*ir.KeyExpr).Key
+				return constant.Compare(ki.Val(), token.LSS, kj.Val())
+			})
+		}
 
 		// build types [count]Tindex and [count]Tvalue
 		tk := types.NewArray(n.Type().Key(), int64(len(entries)))
@@ -460,31 +475,12 @@ func maplit(n *ir.CompLitExpr, m ir.Node, init *ir.Nodes) {
 		fixedlit(inInitFunction, initKindStatic, datak, vstatk, init)
 		fixedlit(inInitFunction, initKindStatic, datae, vstate, init)
 
-		// loop adding structure elements to map
-		// for i = 0; i < len(vstatk); i++ {
-		//	map[vstatk[i]] = vstate[i]
-		// }
-		i := typecheck.Temp(types.Types[types.TINT])
-		rhs := ir.NewIndexExpr(base.Pos, vstate, i)
-		rhs.SetBounded(true)
-
-		kidx := ir.NewIndexExpr(base.Pos, vstatk, i)
-		kidx.SetBounded(true)
-		lhs := ir.NewIndexExpr(base.Pos, m, kidx)
-
-		zero := ir.NewAssignStmt(base.Pos, i, ir.NewInt(0))
-		cond := ir.NewBinaryExpr(base.Pos, ir.OLT, i, ir.NewInt(tk.NumElem()))
-		incr := ir.NewAssignStmt(base.Pos, i, ir.NewBinaryExpr(base.Pos, ir.OADD, i, ir.NewInt(1)))
-
-		var body ir.Node = ir.NewAssignStmt(base.Pos, lhs, rhs)
-		body = typecheck.Stmt(body) // typechecker rewrites OINDEX to OINDEXMAP
-		body = orderStmtInPlace(body, map[string][]*ir.Name{})
-
-		loop := ir.NewForStmt(base.Pos, nil, cond, incr, nil)
-		loop.Body = []ir.Node{body}
-		loop.SetInit([]ir.Node{zero})
-
-		appendWalkStmt(init, loop)
+		// mapinitbulk(maptype, map, &vstatk, &vstate, len(vstatk))
+		fn := typecheck.LookupRuntime("mapinitbulk")
+		fn = typecheck.SubstArgTypes(fn, n.Type().Key(), n.Type().Elem())
+		keys := typecheck.ConvNop(typecheck.NodAddr(vstatk), types.Types[types.TUNSAFEPTR])
+		elems := typecheck.ConvNop(typecheck.NodAddr(vstate), types.Types[types.TUNSAFEPTR])
+		appendWalkStmt(init, mkcallstmt1(fn, reflectdata.TypePtr(n.Type()), m, keys, elems, ir.NewInt(tk.NumElem())))
 		return
 	}
 	// For a small number of entries, just add them directly.
@@ -516,6 +512,18 @@ func maplit(n *ir.CompLitExpr, m ir.Node, init *ir.Nodes) {
 	appendWalkStmt(init, ir.NewUnaryExpr(base.Pos, ir.OVARKILL, tmpelem))
 }
 
+// sortableKeys reports whether the keys of map literal entries are
+// all integer or string constants.
+func sortableKeys(entries []ir.Node) bool {
+	for _, r := range entries {
+		k := r.(*ir.KeyExpr).Key
+		if k.Op() != ir.OLITERAL || !k.Type().IsInteger() && !k.Type().IsString() {
+			return false
+		}
+	}
+	return true
+}
+
 func anylit(n ir.Node, var_ ir.Node, init *ir.Nodes) {
 	t := n.Type()
 	switch n.Op() {
@@ -6,10 +6,12 @@ package walk
 
 import (
 	"encoding/binary"
+	"fmt"
 	"go/constant"
 
 	"cmd/compile/internal/base"
 	"cmd/compile/internal/ir"
+	"cmd/compile/internal/logopt"
 	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/ssagen"
 	"cmd/compile/internal/typecheck"
@@ -40,7 +42,9 @@ func walkConv(n *ir.ConvExpr, init *ir.Nodes) ir.Node {
 // walkConvInterface walks an OCONVIFACE node.
 func walkConvInterface(n *ir.ConvExpr, init *ir.Nodes) ir.Node {
 
-	n.X = walkExpr(n.X, init)
+	if !isZeroData(n.X) {
+		n.X = walkExpr(n.X, init)
+	}
 
 	fromType := n.X.Type()
 	toType := n.Type()
@@ -125,6 +129,10 @@ func dataWord(pos src.XPos, n ir.Node, init *ir.Nodes, escapes bool) ir.Node {
 		// n is zero-sized. Use zerobase.
 		cheapExpr(n, init) // Evaluate n for side-effects. See issue 19246.
 		value = ir.NewLinksymExpr(base.Pos, ir.Syms.Zerobase, types.Types[types.TUINTPTR])
+	case isZeroData(n):
+		// n is the zero value of its type. Use the read-only zero
+		// symbol shared with map accesses.
+		return reflectdata.ZeroAddr(fromType.Size())
 	case fromType.IsBoolean() || (fromType.Size() == 1 && fromType.IsInteger()):
 		// n is a bool/byte. Use staticuint64s[n * 8] on little-endian
 		// and staticuint64s[n * 8 + 7] on big-endian.
@@ -154,6 +162,7 @@ func dataWord(pos src.XPos, n ir.Node, init *ir.Nodes, escapes bool) ir.Node {
 	}
 
 	// Time to do an allocation. We'll call into the runtime for that.
+	noteIfaceAlloc(pos, fromType)
 	fnname, argType, needsaddr := dataWordFuncName(fromType)
 	fn := typecheck.LookupRuntime(fnname)
 
@@ -201,9 +210,54 @@ func dataWord(pos src.XPos, n ir.Node, init *ir.Nodes, escapes bool) ir.Node {
 	return safeExpr(walkExpr(typecheck.Expr(call), init), init)
 }
 
+// isZeroData reports whether n is a non-interface value known to be
+// the zero value of its type, small enough that dataWord can point it
+// at the read-only zero symbol. Such values are left unwalked, so that
+// composite literals don't get a static of their own.
+func isZeroData(n ir.Node) bool {
+	return !n.Type().IsInterface() && ir.IsZero(n) && n.Type().Size() <= zeroValSize
+}
+
+// ifaceAllocs counts the interface conversions in the function being
+// walked that allocate, for -d=ifacealloc and -json.
+var ifaceAllocs int
+
+// noteIfaceAlloc records that the conversion at pos boxes a value of
+// type t into an interface by allocating a copy of it on the heap.
+// The runtime avoids the allocation for some values, such as small
+// integers and empty strings, but not in general.
+func noteIfaceAlloc(pos src.XPos, t *types.Type) {
+	if pos == base.AutogeneratedPos {
+		return
+	}
+	ifaceAllocs++
+	if base.Debug.IfaceAlloc != 0 {
+		base.WarnfAt(pos, "interface conversion of %v allocates %d bytes", t, t.Size())
+	}
+	if logopt.Enabled() {
+		logopt.LogOpt(pos, "ifaceAlloc", "walk", ir.FuncName(ir.CurFunc), fmt.Sprintf("%v, %d bytes", t, t.Size()))
+	}
+}
+
+// reportIfaceAllocs reports how many of fn's interface conversions
+// allocate, for -d=ifacealloc and -json.
+func reportIfaceAllocs(fn *ir.Func) {
+	if ifaceAllocs == 0 {
+		return
+	}
+	if base.Debug.IfaceAlloc != 0 {
+		base.WarnfAt(fn.Pos(), "%v has %d interface conversions that allocate", ir.FuncName(fn), ifaceAllocs)
+	}
+	if logopt.Enabled() {
+		logopt.LogOpt(fn.Pos(), "ifaceAllocs", "walk", ir.FuncName(fn), fmt.Sprint(ifaceAllocs))
+	}
+}
+
 // walkConvIData walks an OCONVIDATA node.
 func walkConvIData(n *ir.ConvExpr, init *ir.Nodes) ir.Node {
-	n.X = walkExpr(n.X, init)
+	if !isZeroData(n.X) {
+		n.X = walkExpr(n.X, init)
+	}
 	return dataWord(n.Pos(), n.X, init, n.Esc() != ir.EscNone)
 }
 
@@ -11,8 +11,10 @@ import (
 	"strings"
 
 	"cmd/compile/internal/base"
+	"cmd/compile/internal/chanflags"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/reflectdata"
+	"cmd/compile/internal/ssagen"
 	"cmd/compile/internal/staticdata"
 	"cmd/compile/internal/typecheck"
 	"cmd/compile/internal/types"
@@ -550,10 +552,45 @@ func walkCall(n *ir.CallExpr, init *ir.Nodes) ir.Node {
 		return e
 	}
 
+	if ir.IsStaticReflectTypeOf(n) {
+		return walkReflectTypeOf(n, init)
+	}
+
 	walkCall1(n, init)
 	return n
 }
 
+// walkReflectTypeOf rewrites reflect.TypeOf(x), where x has a concrete
+// type T, into a direct conversion of T's type descriptor to
+// reflect.Type.
+func walkReflectTypeOf(n *ir.CallExpr, init *ir.Nodes) ir.Node {
+	// reflect.rtype, the type of the type descriptors, is always in
+	// reflect's export data, since reflect.Type's common method
+	// returns a *rtype.
+	pkg := n.X.(*ir.Name).Sym().Pkg
+	rtype := typecheck.Resolve(ir.NewIdent(n.Pos(), pkg.Lookup("rtype")))
+	if rtype.Op() != ir.OTYPE {
+		base.FatalfAt(n.Pos(), "reflect.rtype not found, have %v", rtype)
+	}
+
+	// Evaluate x for its side effects.
+	x := n.Args[0].(*ir.ConvExpr).X
+	if x.Op() != ir.ONAME && x.Op() != ir.OLITERAL && x.Op() != ir.ONIL {
+		appendWalkStmt(init, ir.NewAssignStmt(n.Pos(), ir.BlankNode, x))
+	}
+
+	// The methods of x's type remain reachable through reflection,
+	// just as if x had been converted to an interface.
+	reflectdata.MarkTypeUsedInInterface(x.Type(), ir.CurFunc.LSym)
+
+	var typ ir.Node = reflectdata.TypePtr(x.Type())
+	typ = ir.NewConvExpr(n.Pos(), ir.OCONVNOP, types.NewPtr(rtype.Type()), typ)
+	typ.SetTypecheck(1)
+	conv := ir.NewConvExpr(n.Pos(), ir.OCONVIFACE, n.Type(), typ)
+	conv.SetTypecheck(1)
+	return walkExpr(conv, init)
+}
+
 func walkCall1(n *ir.CallExpr, init *ir.Nodes) {
 	if n.Walked() {
 		return // already walked
@@ -597,10 +634,11 @@ func walkDivMod(n *ir.BinaryExpr, init *ir.Nodes) ir.Node {
 	n.X = walkExpr(n.X, init)
 	n.Y = walkExpr(n.Y, init)
 
-	// rewrite complex div into function call.
+	// rewrite complex div into function call,
+	// unless the SSA backend compiles it inline.
 	et := n.X.Type().Kind()
 
-	if types.IsComplex[et] && n.Op() == ir.ODIV {
+	if types.IsComplex[et] && n.Op() == ir.ODIV && !ssagen.InlineComplexDiv() {
 		t := n.Type()
 		call := mkcall("complex128div", types.Types[types.TCOMPLEX128], init, typecheck.Conv(n.X, types.Types[types.TCOMPLEX128]), typecheck.Conv(n.Y, types.Types[types.TCOMPLEX128]))
 		return typecheck.Conv(call, t)
@@ -802,7 +840,7 @@ func walkSend(n *ir.SendStmt, init *ir.Nodes) ir.Node {
 	n1 = typecheck.AssignConv(n1, n.Chan.Type().Elem(), "chan send")
 	n1 = walkExpr(n1, init)
 	n1 = typecheck.NodAddr(n1)
-	return mkcall1(chanfn("chansend1", 2, n.Chan.Type()), nil, init, n.Chan, n1)
+	return mkcall1(chanfn("chansend1", 2, n.Chan.Type()), nil, init, n.Chan, n1, ir.NewInt(chanflags.Flags(n)))
 }
 
 // walkSlice walks an OSLICE, OSLICEARR, OSLICESTR, OSLICE3, or OSLICE3ARR node.
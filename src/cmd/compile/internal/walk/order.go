@@ -555,6 +555,14 @@ func (o *orderState) call(nn ir.Node) {
 		return
 	}
 
+	if ir.IsStaticReflectTypeOf(n) {
+		// For reflect.TypeOf(x), don't introduce a temporary
+		// for the interface conversion, which walk removes.
+		conv := n.Args[0].(*ir.ConvExpr)
+		conv.X = o.expr(conv.X, nil)
+		return
+	}
+
 	n.X = o.expr(n.X, nil)
 	o.exprList(n.Args)
 }
@@ -854,7 +862,7 @@ func (o *orderState) stmt(n ir.Node) {
 
 		case types.TCHAN, types.TSTRING:
 			// chan, string, slice, array ranges use value multiple times.
-			// make copy.
+			// make copy, unless the loop cannot change it.
 			r := n.X
 
 			if r.Type().IsString() && r.Type() != types.Types[types.TSTRING] {
@@ -863,6 +871,13 @@ func (o *orderState) stmt(n ir.Node) {
 				r = typecheck.Expr(r)
 			}
 
+			if name, ok := r.(*ir.Name); ok && !rangeAssigns(n, name) {
+				// Reading the variable itself sees the same values
+				// as a copy would, and saves a large array's copy
+				// from taking up as much stack again.
+				break
+			}
+
 			n.X = o.copyExpr(r)
 
 		case types.TMAP:
@@ -1093,7 +1108,7 @@ func (o *orderState) expr(n, lhs ir.Node) ir.Node {
 		return n
 	}
 	lno := ir.SetPos(n)
-	n = o.expr1(n, lhs)
+	n = o.expr1(typecheck.FoldString(n), lhs)
 	base.Pos = lno
 	return n
 }
@@ -1180,6 +1195,10 @@ func (o *orderState) expr1(n, lhs ir.Node) ir.Node {
 		if n.X.Type().IsInterface() {
 			return n
 		}
+		if isZeroData(n.X) {
+			// Walk points the data word at the shared zero symbol.
+			return n
+		}
 		if _, _, needsaddr := dataWordFuncName(n.X.Type()); needsaddr || isStaticCompositeLiteral(n.X) {
 			// Need a temp if we need to pass the address to the conversion function.
 			// We also process static composite literal node here, making a named static global
@@ -1439,6 +1458,45 @@ func (o *orderState) expr1(n, lhs ir.Node) ir.Node {
 	// No return - type-assertions above. Each case must return for itself.
 }
 
+// rangeAssigns reports whether name, the expression ranged over by n,
+// may change during the loop. It cannot if it is a local variable
+// whose address is not taken and that n does not assign to, or
+// to part of, either as its key or value or in its body.
+func rangeAssigns(n *ir.RangeStmt, name *ir.Name) bool {
+	if name.Class != ir.PAUTO && name.Class != ir.PPARAM || name.Addrtaken() {
+		return true
+	}
+
+	// assigns reports whether assigning to x changes name.
+	assigns := func(x ir.Node) bool {
+		return x != nil && ir.OuterValue(x) == name
+	}
+	if assigns(n.Key) || assigns(n.Value) {
+		return true
+	}
+	do := func(x ir.Node) bool {
+		switch x.Op() {
+		case ir.OAS:
+			return assigns(x.(*ir.AssignStmt).X)
+		case ir.OASOP:
+			return assigns(x.(*ir.AssignOpStmt).X)
+		case ir.OAS2, ir.OAS2FUNC, ir.OAS2MAPR, ir.OAS2DOTTYPE, ir.OAS2RECV, ir.OSELRECV2:
+			for _, l := range x.(*ir.AssignListStmt).Lhs {
+				if assigns(l) {
+					return true
+				}
+			}
+		case ir.ORANGE:
+			x := x.(*ir.RangeStmt)
+			if assigns(x.Key) || assigns(x.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return ir.AnyList(n.Body, do)
+}
+
 // as2func orders OAS2FUNC nodes. It creates temporaries to ensure left-to-right assignment.
 // The caller should order the right-hand side of the assignment before calling order.as2func.
 // It rewrites,
@@ -1447,8 +1505,16 @@ func (o *orderState) expr1(n, lhs ir.Node) ir.Node {
 //	tmp1, tmp2, tmp3 = ...
 //	a, b, a = tmp1, tmp2, tmp3
 // This is necessary to ensure left to right assignment order.
+// If each of a, b, ... is a distinct local variable of its result's
+// type, as in x, err := f(), no temporaries are needed: the results
+// can be stored straight into them, in any order.
 func (o *orderState) as2func(n *ir.AssignListStmt) {
 	results := n.Rhs[0].Type()
+	if resultLocals(n.Lhs, results) {
+		o.out = append(o.out, n)
+		return
+	}
+
 	as := ir.NewAssignListStmt(n.Pos(), ir.OAS2, nil, nil)
 	for i, nl := range n.Lhs {
 		if !ir.IsBlank(nl) {
@@ -1464,6 +1530,31 @@ func (o *orderState) as2func(n *ir.AssignListStmt) {
 	o.stmt(typecheck.Stmt(as))
 }
 
+// resultLocals reports whether each non-blank expression in lhs is a
+// distinct variable that the results of type results can be assigned
+// to directly: a local variable whose address is not taken, of the
+// same type as its result.
+func resultLocals(lhs ir.Nodes, results *types.Type) bool {
+	var seen ir.NameSet
+	for i, l := range lhs {
+		if ir.IsBlank(l) {
+			continue
+		}
+		name, ok := l.(*ir.Name)
+		if !ok || !isResultLocal(name, results.Field(i).Type) || seen.Has(name) {
+			return false
+		}
+		seen.Add(name)
+	}
+	return true
+}
+
+// isResultLocal reports whether name can be assigned a call result
+// of type t without an intervening temporary.
+func isResultLocal(name *ir.Name, t *types.Type) bool {
+	return name.Op() == ir.ONAME && name.Class == ir.PAUTO && !name.Addrtaken() && types.Identical(name.Type(), t)
+}
+
 // as2ok orders OAS2XXX with ok.
 // Just like as2func, this also adds temporaries to ensure left-to-right assignment.
 func (o *orderState) as2ok(n *ir.AssignListStmt) {
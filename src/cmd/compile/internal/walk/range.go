@@ -8,6 +8,7 @@ import (
 	"unicode/utf8"
 
 	"cmd/compile/internal/base"
+	"cmd/compile/internal/chanflags"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/ssagen"
@@ -199,18 +200,24 @@ func walkRange(nrange *ir.RangeStmt) ir.Node {
 		if t.Elem().HasPointers() {
 			init = append(init, ir.NewAssignStmt(base.Pos, hv1, nil))
 		}
-		hb := typecheck.Temp(types.Types[types.TBOOL])
-
-		nfor.Cond = ir.NewBinaryExpr(base.Pos, ir.ONE, hb, ir.NewBool(false))
-		lhs := []ir.Node{hv1, hb}
-		rhs := []ir.Node{ir.NewUnaryExpr(base.Pos, ir.ORECV, ha)}
-		a := ir.NewAssignListStmt(base.Pos, ir.OAS2RECV, lhs, rhs)
-		a.SetTypecheck(1)
-		nfor.Cond = ir.InitExpr([]ir.Node{a}, nfor.Cond)
-		if v1 == nil {
-			body = nil
+		if flags := chanflags.Flags(nrange); flags&chanflags.NotClosed != 0 {
+			// The channel is never closed, so the loop only ends
+			// by breaking out of it, and needn't check for that.
+			recv := ir.NewUnaryExpr(base.Pos, ir.ORECV, ha)
+			chanflags.SetFlags(recv, flags)
+			body = []ir.Node{ir.NewAssignStmt(base.Pos, hv1, recv)}
 		} else {
-			body = []ir.Node{ir.NewAssignStmt(base.Pos, v1, hv1)}
+			hb := typecheck.Temp(types.Types[types.TBOOL])
+
+			nfor.Cond = ir.NewBinaryExpr(base.Pos, ir.ONE, hb, ir.NewBool(false))
+			lhs := []ir.Node{hv1, hb}
+			rhs := []ir.Node{ir.NewUnaryExpr(base.Pos, ir.ORECV, ha)}
+			a := ir.NewAssignListStmt(base.Pos, ir.OAS2RECV, lhs, rhs)
+			a.SetTypecheck(1)
+			nfor.Cond = ir.InitExpr([]ir.Node{a}, nfor.Cond)
+		}
+		if v1 != nil {
+			body = append(body, ir.NewAssignStmt(base.Pos, v1, hv1))
 		}
 		// Zero hv1. This prevents hv1 from being the sole, inaccessible
 		// reference to an otherwise GC-able value during the next channel receive.
@@ -293,10 +300,12 @@ func walkRange(nrange *ir.RangeStmt) ir.Node {
 		nfor.PtrInit().Append(init...)
 	}
 
-	typecheck.Stmts(nfor.Cond.Init())
+	if nfor.Cond != nil {
+		typecheck.Stmts(nfor.Cond.Init())
 
-	nfor.Cond = typecheck.Expr(nfor.Cond)
-	nfor.Cond = typecheck.DefaultLit(nfor.Cond, nil)
+		nfor.Cond = typecheck.Expr(nfor.Cond)
+		nfor.Cond = typecheck.DefaultLit(nfor.Cond, nil)
+	}
 	nfor.Post = typecheck.Stmt(nfor.Post)
 	typecheck.Stmts(body)
 	nfor.Body.Append(body...)
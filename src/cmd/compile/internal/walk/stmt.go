@@ -113,8 +113,9 @@ func walkStmt(n ir.Node) ir.Node {
 			ir.CurFunc.SetOpenCodedDeferDisallowed(true)
 		}
 		if n.Esc() != ir.EscNever {
-			// If n.Esc is not EscNever, then this defer occurs in a loop,
-			// so open-coded defers cannot be used in this function.
+			// If n.Esc is not EscNever, then this defer occurs in a loop
+			// and may execute more than once, so open-coded defers cannot
+			// be used in this function.
 			ir.CurFunc.SetOpenCodedDeferDisallowed(true)
 		}
 		fallthrough
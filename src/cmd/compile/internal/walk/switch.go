@@ -190,7 +190,7 @@ func (s *exprSwitch) flush() {
 			func(i int, nif *ir.IfStmt) {
 				run := runs[i]
 				nif.Cond = ir.NewBinaryExpr(base.Pos, ir.OEQ, ir.NewUnaryExpr(base.Pos, ir.OLEN, s.exprname), ir.NewInt(runLen(run)))
-				s.search(run, &nif.Body)
+				s.searchBytes(run, &nif.Body)
 			},
 		)
 		return
@@ -235,6 +235,71 @@ func (s *exprSwitch) search(cc []exprClause, out *ir.Nodes) {
 	)
 }
 
+// searchBytes is like search for cc, a run of string cases of the
+// same length, which the switched-on string is known to have. With
+// enough cases, it first narrows them down by a binary search on the
+// byte at the index where they differ the most, so that whole strings
+// are only compared for equality, with the few cases that have the
+// string's byte there.
+func (s *exprSwitch) searchBytes(cc []exprClause, out *ir.Nodes) {
+	n := len(ir.StringVal(cc[0].lo))
+	if base.Debug.StringSwitch <= 0 || len(cc) < base.Debug.StringSwitch || n == 0 {
+		s.search(cc, out)
+		return
+	}
+
+	// Find the index with the most distinct bytes.
+	at, most := 0, 0
+	for i := 0; i < n; i++ {
+		var seen [256]bool
+		distinct := 0
+		for _, c := range cc {
+			if b := ir.StringVal(c.lo)[i]; !seen[b] {
+				seen[b] = true
+				distinct++
+			}
+		}
+		if distinct > most {
+			at, most = i, distinct
+		}
+	}
+	if most == 1 {
+		s.search(cc, out)
+		return
+	}
+
+	byteAt := func(c exprClause) byte { return ir.StringVal(c.lo)[at] }
+	sort.SliceStable(cc, func(i, j int) bool { return byteAt(cc[i]) < byteAt(cc[j]) })
+
+	// Collapse runs of cases with the same byte.
+	var runs [][]exprClause
+	start := 0
+	for i := 1; i < len(cc); i++ {
+		if byteAt(cc[start]) != byteAt(cc[i]) {
+			runs = append(runs, cc[start:i])
+			start = i
+		}
+	}
+	runs = append(runs, cc[start:])
+
+	// exprname[at], which is in range.
+	index := func() ir.Node {
+		x := ir.NewIndexExpr(base.Pos, s.exprname, ir.NewInt(int64(at)))
+		x.SetBounded(true)
+		return x
+	}
+	binarySearch(len(runs), out,
+		func(i int) ir.Node {
+			return ir.NewBinaryExpr(base.Pos, ir.OLE, index(), ir.NewInt(int64(byteAt(runs[i-1][0]))))
+		},
+		func(i int, nif *ir.IfStmt) {
+			run := runs[i]
+			nif.Cond = ir.NewBinaryExpr(base.Pos, ir.OEQ, index(), ir.NewInt(int64(byteAt(run[0]))))
+			s.search(run, &nif.Body)
+		},
+	)
+}
+
 func (c *exprClause) test(exprname ir.Node) ir.Node {
 	// Integer range.
 	if c.hi != c.lo {
@@ -295,6 +360,7 @@ func endsInFallthrough(stmts []ir.Node) (bool, src.XPos) {
 // type switch.
 func walkSwitchType(sw *ir.SwitchStmt) {
 	var s typeSwitch
+	s.pos = sw.Pos()
 	s.facename = sw.Tag.(*ir.TypeSwitchGuard).X
 	sw.Tag = nil
 
@@ -443,6 +509,8 @@ var rtypeHashField, itabHashField *types.Field
 
 // A typeSwitch walks a type switch.
 type typeSwitch struct {
+	pos src.XPos // position of the switch statement, for -m output
+
 	// Temporary variables (i.e., ONAMEs) used by type switch dispatch logic:
 	facename ir.Node // value being type-switched on
 	hashname ir.Node // type hash of the value being type-switched on
@@ -540,17 +608,31 @@ func (s *typeSwitch) flush() {
 	}
 	cc = merged
 
+	leaf := func(i int, nif *ir.IfStmt) {
+		// TODO(mdempsky): Omit hash equality check if
+		// there's only one type.
+		c := cc[i]
+		nif.Cond = ir.NewBinaryExpr(base.Pos, ir.OEQ, s.hashname, ir.NewInt(int64(c.hash)))
+		nif.Body.Append(c.body.Take()...)
+	}
+
+	report := base.Debug.TypeSwitch != "" && base.Flag.LowerM != 0
+	if base.Debug.TypeSwitch == "linear" {
+		if report {
+			base.WarnfAt(s.pos, "type switch on %d concrete types lowered to linear search", len(cc))
+		}
+		linearSearch(0, len(cc), &s.done, leaf)
+		return
+	}
+
+	if report {
+		base.WarnfAt(s.pos, "type switch on %d concrete types lowered to binary search", len(cc))
+	}
 	binarySearch(len(cc), &s.done,
 		func(i int) ir.Node {
 			return ir.NewBinaryExpr(base.Pos, ir.OLE, s.hashname, ir.NewInt(int64(cc[i-1].hash)))
 		},
-		func(i int, nif *ir.IfStmt) {
-			// TODO(mdempsky): Omit hash equality check if
-			// there's only one type.
-			c := cc[i]
-			nif.Cond = ir.NewBinaryExpr(base.Pos, ir.OEQ, s.hashname, ir.NewInt(int64(c.hash)))
-			nif.Body.Append(c.body.Take()...)
-		},
+		leaf,
 	)
 }
 
@@ -569,16 +651,10 @@ func binarySearch(n int, out *ir.Nodes, less func(i int) ir.Node, leaf func(i in
 	var do func(lo, hi int, out *ir.Nodes)
 	do = func(lo, hi int, out *ir.Nodes) {
 		n := hi - lo
-		if n < binarySearchMin {
-			for i := lo; i < hi; i++ {
-				nif := ir.NewIfStmt(base.Pos, nil, nil, nil)
-				leaf(i, nif)
-				base.Pos = base.Pos.WithNotStmt()
-				nif.Cond = typecheck.Expr(nif.Cond)
-				nif.Cond = typecheck.DefaultLit(nif.Cond, nil)
-				out.Append(nif)
-				out = &nif.Else
-			}
+		if n < binarySearchMin || base.Flag.OptSize {
+			// With -Os, a linear search saves the comparisons
+			// at the inner nodes of the tree.
+			linearSearch(lo, hi, out, leaf)
 			return
 		}
 
@@ -595,3 +671,19 @@ func binarySearch(n int, out *ir.Nodes, less func(i int) ir.Node, leaf func(i in
 
 	do(0, n, out)
 }
+
+// linearSearch constructs a chain of if-else statements testing cases
+// lo through hi-1 in order, and appends it to out.
+//
+// leaf is as for binarySearch.
+func linearSearch(lo, hi int, out *ir.Nodes, leaf func(i int, nif *ir.IfStmt)) {
+	for i := lo; i < hi; i++ {
+		nif := ir.NewIfStmt(base.Pos, nil, nil, nil)
+		leaf(i, nif)
+		base.Pos = base.Pos.WithNotStmt()
+		nif.Cond = typecheck.Expr(nif.Cond)
+		nif.Cond = typecheck.DefaultLit(nif.Cond, nil)
+		out.Append(nif)
+		out = &nif.Else
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"cmd/compile/internal/base"
+	"cmd/compile/internal/chanflags"
 	"cmd/compile/internal/ir"
 	"cmd/compile/internal/reflectdata"
 	"cmd/compile/internal/ssagen"
@@ -24,6 +25,10 @@ const zeroValSize = 1024 // must match value of runtime/map.go:maxZero
 func Walk(fn *ir.Func) {
 	ir.CurFunc = fn
 	errorsBefore := base.Errors()
+	var dump []dumpLine
+	if isWalkDumpFunc(fn) {
+		dump = dumpStmts(fn.Body, 0)
+	}
 	order(fn)
 	if base.Errors() > errorsBefore {
 		return
@@ -40,11 +45,16 @@ func Walk(fn *ir.Func) {
 	if base.Errors() > errorsBefore {
 		return
 	}
+	ifaceAllocs = 0
 	walkStmtList(ir.CurFunc.Body)
+	reportIfaceAllocs(fn)
 	if base.Flag.W != 0 {
 		s := fmt.Sprintf("after walk %v", ir.CurFunc.Sym())
 		ir.DumpList(s, ir.CurFunc.Body)
 	}
+	if dump != nil {
+		walkDump(fn, dump)
+	}
 
 	if base.Flag.Cfg.Instrumenting {
 		instrument(fn)
@@ -64,10 +74,16 @@ func walkRecv(n *ir.UnaryExpr) ir.Node {
 	init := ir.TakeInit(n)
 
 	n.X = walkExpr(n.X, &init)
-	call := walkExpr(mkcall1(chanfn("chanrecv1", 2, n.X.Type()), nil, &init, n.X, typecheck.NodNil()), &init)
+	call := walkExpr(chanrecv1(n, typecheck.NodNil(), &init), &init)
 	return ir.InitExpr(init, call)
 }
 
+// chanrecv1 returns a call receiving from the channel of n, an ORECV
+// node, into elem, without the checks the channel is known not to need.
+func chanrecv1(n *ir.UnaryExpr, elem ir.Node, init *ir.Nodes) ir.Node {
+	return mkcall1(chanfn("chanrecv1", 2, n.X.Type()), nil, init, n.X, elem, ir.NewInt(chanflags.Flags(n)))
+}
+
 func convas(n *ir.AssignStmt, init *ir.Nodes) *ir.AssignStmt {
 	if n.Op() != ir.OAS {
 		base.Fatalf("convas: not OAS %v", n.Op())
@@ -0,0 +1,224 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package walk
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"cmd/compile/internal/base"
+	"cmd/compile/internal/ir"
+)
+
+// isWalkDumpFunc reports whether fn is the function named by
+// -d=walkdump, either plainly or qualified by its package path.
+func isWalkDumpFunc(fn *ir.Func) bool {
+	name := base.Debug.WalkDump
+	return name != "" && fn.Nname != nil && (ir.FuncName(fn) == name || ir.PkgFuncName(fn) == name)
+}
+
+// A dumpLine is a line of a -d=walkdump listing: a statement, or part
+// of a compound statement, indented by its depth.
+type dumpLine struct {
+	pos  string // source line of the statement, or "" to go with the line before
+	text string
+}
+
+// dumpStmts returns the listing of the statement list l, each line
+// indented by depth tabs.
+func dumpStmts(l []ir.Node, depth int) []dumpLine {
+	var lines []dumpLine
+	// add adds a line for n, or, if n is nil, one that goes with the
+	// line before it, like the end of a block.
+	add := func(n ir.Node, depth int, format string, args ...interface{}) {
+		pos := ""
+		if n != nil {
+			if p := base.Ctxt.OutermostPos(n.Pos()); p.IsKnown() {
+				pos = fmt.Sprintf("%s:%d", p.Filename(), p.Line())
+			}
+		}
+		text := strings.Repeat("\t", depth) + strings.TrimRight(fmt.Sprintf(format, args...), " ")
+		lines = append(lines, dumpLine{pos, text})
+	}
+	var list func(l []ir.Node, depth int)
+	list = func(l []ir.Node, depth int) {
+		for _, n := range l {
+			if n == nil {
+				continue
+			}
+			list(n.Init(), depth)
+			switch n := n.(type) {
+			case *ir.BlockStmt:
+				add(n, depth, "{")
+				list(n.List, depth+1)
+				add(nil, depth, "}")
+			case *ir.IfStmt:
+				add(n, depth, "if %v {", n.Cond)
+				list(n.Body, depth+1)
+				if len(n.Else) > 0 {
+					add(nil, depth, "} else {")
+					list(n.Else, depth+1)
+				}
+				add(nil, depth, "}")
+			case *ir.ForStmt:
+				op := "for"
+				if n.Op() == ir.OFORUNTIL {
+					op = "foruntil"
+				}
+				switch {
+				case n.Post != nil:
+					cond := ""
+					if n.Cond != nil {
+						cond = fmt.Sprint(n.Cond)
+					}
+					add(n, depth, "%s ; %s; %v {", op, cond, n.Post)
+				case n.Cond != nil:
+					add(n, depth, "%s %v {", op, n.Cond)
+				default:
+					add(n, depth, "%s {", op)
+				}
+				list(n.Body, depth+1)
+				add(nil, depth, "}")
+			case *ir.RangeStmt:
+				switch {
+				case n.Value != nil:
+					add(n, depth, "for %v, %v = range %v {", n.Key, n.Value, n.X)
+				case n.Key != nil:
+					add(n, depth, "for %v = range %v {", n.Key, n.X)
+				default:
+					add(n, depth, "for range %v {", n.X)
+				}
+				list(n.Body, depth+1)
+				add(nil, depth, "}")
+			case *ir.SwitchStmt:
+				if len(n.Compiled) > 0 {
+					// Walked into the statements in Compiled.
+					list(n.Compiled, depth)
+					break
+				}
+				if guard, ok := n.Tag.(*ir.TypeSwitchGuard); ok {
+					if guard.Tag != nil {
+						add(n, depth, "switch %v := %v.(type) {", guard.Tag, guard.X)
+					} else {
+						add(n, depth, "switch %v.(type) {", guard.X)
+					}
+				} else if n.Tag != nil {
+					add(n, depth, "switch %v {", n.Tag)
+				} else {
+					add(n, depth, "switch {")
+				}
+				for _, c := range n.Cases {
+					if len(c.List) == 0 {
+						add(c, depth, "default:")
+					} else {
+						add(c, depth, "case %.v:", c.List)
+					}
+					list(c.Body, depth+1)
+				}
+				add(nil, depth, "}")
+			case *ir.SelectStmt:
+				if len(n.Compiled) > 0 {
+					list(n.Compiled, depth)
+					break
+				}
+				add(n, depth, "select {")
+				for _, c := range n.Cases {
+					if c.Comm == nil {
+						add(c, depth, "default:")
+					} else if recv, ok := c.Comm.(*ir.AssignListStmt); ok {
+						add(c, depth, "case %.v = %v:", recv.Lhs, recv.Rhs[0])
+					} else {
+						add(c, depth, "case %v:", c.Comm)
+					}
+					list(c.Body, depth+1)
+				}
+				add(nil, depth, "}")
+			case *ir.AssignStmt:
+				if n.Y == nil {
+					add(n, depth, "%v = <zero>", n.X)
+				} else {
+					add(n, depth, "%v", n)
+				}
+			case *ir.UnaryExpr:
+				if n.Op() == ir.OVARDEF || n.Op() == ir.OVARKILL || n.Op() == ir.OVARLIVE {
+					add(n, depth, "%v %v", n.Op(), n.X)
+				} else {
+					add(n, depth, "%v", n)
+				}
+			default:
+				add(n, depth, "%v", n)
+			}
+		}
+	}
+	list(l, depth)
+	return lines
+}
+
+// walkDump prints the listing before, of a function body before order
+// and walk, and its listing after them, side by side: each run of lines
+// after walk from the same source line follows the lines before walk
+// from that line, if they have not been printed yet.
+func walkDump(fn *ir.Func, before []dumpLine) {
+	after := dumpStmts(fn.Body, 0)
+
+	// runs splits lines into runs from the same source line.
+	type run struct {
+		pos   string
+		lines []string
+	}
+	runs := func(lines []dumpLine) []*run {
+		var runs []*run
+		for _, l := range lines {
+			if len(runs) == 0 || l.pos != "" && l.pos != runs[len(runs)-1].pos {
+				runs = append(runs, &run{pos: l.pos})
+			}
+			r := runs[len(runs)-1]
+			r.lines = append(r.lines, l.text)
+		}
+		return runs
+	}
+	b, a := runs(before), runs(after)
+
+	var buf bytes.Buffer
+	print := func(pos string, b, a *run) {
+		if pos == "" {
+			pos = "<unknown line>"
+		}
+		fmt.Fprintf(&buf, "%s\n", pos)
+		if b != nil {
+			for _, s := range b.lines {
+				fmt.Fprintf(&buf, "\t-\t%s\n", s)
+			}
+		}
+		if a != nil {
+			for _, s := range a.lines {
+				fmt.Fprintf(&buf, "\t+\t%s\n", s)
+			}
+		}
+	}
+	fmt.Fprintf(&buf, "walk %v\n", ir.FuncName(fn))
+	next := 0 // next run in b to print
+	for _, r := range a {
+		k := next
+		for k < len(b) && b[k].pos != r.pos {
+			k++
+		}
+		if k == len(b) {
+			print(r.pos, nil, r)
+			continue
+		}
+		for ; next < k; next++ {
+			print(b[next].pos, b[next], nil)
+		}
+		print(r.pos, b[k], r)
+		next++
+	}
+	for ; next < len(b); next++ {
+		print(b[next].pos, b[next], nil)
+	}
+	os.Stdout.Write(buf.Bytes())
+}
@@ -301,6 +301,8 @@ const (
 	SymFlagUsedInIface = 1 << iota
 	SymFlagItab
 	SymFlagDict
+	SymFlagHot
+	SymFlagSpecialized
 )
 
 // Returns the length of the name of the symbol.
@@ -331,6 +333,8 @@ func (s *Sym) IsGoType() bool      { return s.Flag()&SymFlagGoType != 0 }
 func (s *Sym) UsedInIface() bool   { return s.Flag2()&SymFlagUsedInIface != 0 }
 func (s *Sym) IsItab() bool        { return s.Flag2()&SymFlagItab != 0 }
 func (s *Sym) IsDict() bool        { return s.Flag2()&SymFlagDict != 0 }
+func (s *Sym) Hot() bool           { return s.Flag2()&SymFlagHot != 0 }
+func (s *Sym) Specialized() bool   { return s.Flag2()&SymFlagSpecialized != 0 }
 
 func (s *Sym) SetName(x string, w *Writer) {
 	binary.LittleEndian.PutUint32(s[:], uint32(len(x)))
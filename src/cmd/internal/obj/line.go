@@ -19,6 +19,11 @@ func linkgetlineFromPos(ctxt *Link, xpos src.XPos) (f string, l int32) {
 	if !pos.IsKnown() {
 		pos = src.Pos{}
 	}
+	if ctxt.Flag_genlines {
+		// The file and line the position is at, whatever
+		// line directive generated code says it comes from.
+		return pos.Base().Pos().SymFilename(), int32(pos.Line())
+	}
 	// TODO(gri) Should this use relative or absolute line number?
 	return pos.SymFilename(), int32(pos.RelLine())
 }
@@ -455,7 +455,7 @@ type LSym struct {
 	P      []byte
 	R      []Reloc
 
-	Extra *interface{} // *FuncInfo or *FileInfo, if present
+	Extra *interface{} // *FuncInfo, *VarInfo or *FileInfo, if present
 
 	Pkg    string
 	PkgIdx int32
@@ -487,6 +487,7 @@ type FuncInfo struct {
 	OpenCodedDeferInfo *LSym
 	ArgInfo            *LSym // argument info for traceback
 	ArgLiveInfo        *LSym // argument liveness info for traceback
+	PanicInfo          *LSym // descriptions of panic sites for runtime errors
 
 	FuncInfoSym *LSym
 }
@@ -538,6 +539,32 @@ func (s *LSym) File() *FileInfo {
 	return f
 }
 
+// A VarInfo contains extra fields for SDATA and SBSS symbols of
+// variables that need more than their natural alignment.
+type VarInfo struct {
+	Align int32 // alignment in bytes, a power of two
+}
+
+// NewVarInfo allocates and returns a VarInfo for LSym.
+func (s *LSym) NewVarInfo() *VarInfo {
+	if s.Extra != nil {
+		panic(fmt.Sprintf("invalid use of LSym - NewVarInfo with Extra of type %T", *s.Extra))
+	}
+	v := new(VarInfo)
+	s.Extra = new(interface{})
+	*s.Extra = v
+	return v
+}
+
+// Var returns the *VarInfo associated with s, or else nil.
+func (s *LSym) Var() *VarInfo {
+	if s.Extra == nil {
+		return nil
+	}
+	v, _ := (*s.Extra).(*VarInfo)
+	return v
+}
+
 type InlMark struct {
 	// When unwinding from an instruction in an inlined body, mark
 	// where we should unwind to.
@@ -704,6 +731,17 @@ const (
 	// IsPcdata indicates this is a pcdata symbol.
 	AttrPcdata
 
+	// Hot indicates that a CPU profile (-pgoprofile) shows this
+	// function accounts for much of the program's time. The linker
+	// places hot functions together.
+	AttrHot
+
+	// Specialized indicates that this function is a copy of the
+	// function whose name is its own up to the last ".", compiled
+	// for constant arguments. The runtime reports the copy by the
+	// name of the function it copies.
+	AttrSpecialized
+
 	// attrABIBase is the value at which the ABI is encoded in
 	// Attribute. This must be last; all bits after this are
 	// assumed to be an ABI value.
@@ -732,6 +770,8 @@ func (a *Attribute) UsedInIface() bool        { return a.load()&AttrUsedInIface
 func (a *Attribute) ContentAddressable() bool { return a.load()&AttrContentAddressable != 0 }
 func (a *Attribute) ABIWrapper() bool         { return a.load()&AttrABIWrapper != 0 }
 func (a *Attribute) IsPcdata() bool           { return a.load()&AttrPcdata != 0 }
+func (a *Attribute) Hot() bool                { return a.load()&AttrHot != 0 }
+func (a *Attribute) Specialized() bool        { return a.load()&AttrSpecialized != 0 }
 
 func (a *Attribute) Set(flag Attribute, value bool) {
 	for {
@@ -780,6 +820,8 @@ var textAttrStrings = [...]struct {
 	{bit: AttrIndexed, s: ""},
 	{bit: AttrContentAddressable, s: ""},
 	{bit: AttrABIWrapper, s: "ABIWRAPPER"},
+	{bit: AttrHot, s: ""},
+	{bit: AttrSpecialized, s: ""},
 }
 
 // String formats a for printing in as part of a TEXT prog.
@@ -882,6 +924,7 @@ type Link struct {
 	Flag_locationlists bool
 	Retpoline          bool   // emit use of retpoline stubs for indirect jmp/call
 	Flag_maymorestack  string // If not "", call this function before stack checks
+	Flag_genlines      bool   // ignore line directives in PC-line tables
 	Bso                *bufio.Writer
 	Pathname           string
 	Pkgpath            string           // the current package's import path, "" if unknown
@@ -324,6 +324,12 @@ func (w *writer) Sym(s *LSym) {
 	if strings.HasPrefix(s.Name, w.ctxt.Pkgpath) && strings.HasPrefix(s.Name[len(w.ctxt.Pkgpath):], ".") && strings.HasPrefix(s.Name[len(w.ctxt.Pkgpath)+1:], objabi.GlobalDictPrefix) {
 		flag2 |= goobj.SymFlagDict
 	}
+	if s.Hot() {
+		flag2 |= goobj.SymFlagHot
+	}
+	if s.Specialized() {
+		flag2 |= goobj.SymFlagSpecialized
+	}
 	name := s.Name
 	if strings.HasPrefix(name, "gofile..") {
 		name = filepath.ToSlash(name)
@@ -332,6 +338,9 @@ func (w *writer) Sym(s *LSym) {
 	if fn := s.Func(); fn != nil {
 		align = uint32(fn.Align)
 	}
+	if v := s.Var(); v != nil {
+		align = uint32(v.Align)
+	}
 	if s.ContentAddressable() && s.Size != 0 {
 		// We generally assume data symbols are natually aligned
 		// (e.g. integer constants), except for strings and a few
@@ -348,7 +357,8 @@ func (w *writer) Sym(s *LSym) {
 			strings.HasSuffix(name, ".opendefer"),
 			strings.HasSuffix(name, ".arginfo0"),
 			strings.HasSuffix(name, ".arginfo1"),
-			strings.HasSuffix(name, ".argliveinfo"):
+			strings.HasSuffix(name, ".argliveinfo"),
+			strings.HasSuffix(name, ".panicinfo"):
 			// These are just bytes, or varints.
 			align = 1
 		case strings.HasPrefix(name, "gclocals·"):
@@ -417,6 +427,7 @@ func contentHashSection(s *LSym) byte {
 		strings.HasSuffix(name, ".arginfo0") ||
 		strings.HasSuffix(name, ".arginfo1") ||
 		strings.HasSuffix(name, ".argliveinfo") ||
+		strings.HasSuffix(name, ".panicinfo") ||
 		strings.HasSuffix(name, ".args_stackmap") ||
 		strings.HasSuffix(name, ".stkobj") {
 		return 'F' // go.func.* or go.funcrel.*
@@ -48,7 +48,7 @@ func funcpctab(ctxt *Link, func_ *LSym, desc string, valfunc func(*Link, *LSym,
 		ctxt.Logf("%6x %6d %v\n", uint64(pc), val, fn.Text)
 	}
 
-	buf := make([]byte, binary.MaxVarintLen32)
+	var buf [binary.MaxVarintLen32]byte
 	started := false
 	for p := fn.Text; p != nil; p = p.Link {
 		// Update val. If it's not changing, keep going.
@@ -94,13 +94,13 @@ func funcpctab(ctxt *Link, func_ *LSym, desc string, valfunc func(*Link, *LSym,
 
 		if started {
 			pcdelta := (p.Pc - pc) / int64(ctxt.Arch.MinLC)
-			n := binary.PutUvarint(buf, uint64(pcdelta))
+			n := binary.PutUvarint(buf[:], uint64(pcdelta))
 			dst = append(dst, buf[:n]...)
 			pc = p.Pc
 		}
 
 		delta := val - oldval
-		n := binary.PutVarint(buf, int64(delta))
+		n := binary.PutVarint(buf[:], int64(delta))
 		dst = append(dst, buf[:n]...)
 		oldval = val
 		started = true
@@ -115,7 +115,7 @@ func funcpctab(ctxt *Link, func_ *LSym, desc string, valfunc func(*Link, *LSym,
 		if v < 0 {
 			ctxt.Diag("negative pc offset: %v", v)
 		}
-		n := binary.PutUvarint(buf, uint64(v))
+		n := binary.PutUvarint(buf[:], uint64(v))
 		dst = append(dst, buf[:n]...)
 		// add terminating varint-encoded 0, which is just 0
 		dst = append(dst, 0)
@@ -242,6 +242,7 @@ const (
 	AMULHD
 	AMULHDU
 	AMLGR
+	ADLGR
 	ASUB
 	ASUBC
 	ASUBV
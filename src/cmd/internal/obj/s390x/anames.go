@@ -22,6 +22,7 @@ var Anames = []string{
 	"MULHD",
 	"MULHDU",
 	"MLGR",
+	"DLGR",
 	"SUB",
 	"SUBC",
 	"SUBV",
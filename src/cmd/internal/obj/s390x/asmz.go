@@ -175,6 +175,7 @@ var optab = []Optab{
 	{i: 4, as: AMULHD, a1: C_REG, a6: C_REG},
 	{i: 4, as: AMULHD, a1: C_REG, a2: C_REG, a6: C_REG},
 	{i: 62, as: AMLGR, a1: C_REG, a6: C_REG},
+	{i: 62, as: ADLGR, a1: C_REG, a6: C_REG},
 	{i: 2, as: ADIVW, a1: C_REG, a2: C_REG, a6: C_REG},
 	{i: 2, as: ADIVW, a1: C_REG, a6: C_REG},
 	{i: 10, as: ASUB, a1: C_REG, a2: C_REG, a6: C_REG},
@@ -3507,8 +3508,12 @@ func (c *ctxtz) asmout(p *obj.Prog, asm *[]byte) {
 		d2 := c.regoff(&p.To)
 		zRXE(opcode, uint32(p.From.Reg), 0, 0, uint32(d2), 0, asm)
 
-	case 62: // equivalent of Mul64 in math/bits
-		zRRE(op_MLGR, uint32(p.To.Reg), uint32(p.From.Reg), asm)
+	case 62: // equivalent of Mul64 and Div64 in math/bits
+		opcode := op_MLGR
+		if p.As == ADLGR {
+			opcode = op_DLGR
+		}
+		zRRE(opcode, uint32(p.To.Reg), uint32(p.From.Reg), asm)
 
 	case 66:
 		zRR(op_BCR, uint32(Never), 0, asm)
@@ -11,10 +11,11 @@ package objabi
 // ../../../runtime/symtab.go.
 
 const (
-	PCDATA_UnsafePoint   = 0
-	PCDATA_StackMapIndex = 1
-	PCDATA_InlTreeIndex  = 2
-	PCDATA_ArgLiveIndex  = 3
+	PCDATA_UnsafePoint    = 0
+	PCDATA_StackMapIndex  = 1
+	PCDATA_InlTreeIndex   = 2
+	PCDATA_ArgLiveIndex   = 3
+	PCDATA_PanicInfoIndex = 4
 
 	FUNCDATA_ArgsPointerMaps    = 0
 	FUNCDATA_LocalsPointerMaps  = 1
@@ -23,6 +24,7 @@ const (
 	FUNCDATA_OpenCodedDeferInfo = 4
 	FUNCDATA_ArgInfo            = 5
 	FUNCDATA_ArgLiveInfo        = 6
+	FUNCDATA_PanicInfo          = 7
 
 	// ArgsSizeUnknown is set in Func.argsize to mark all functions
 	// whose argument size is unknown (C vararg functions, and
@@ -31,6 +33,23 @@ const (
 	ArgsSizeUnknown = -0x80000000
 )
 
+// Stack map encoding.
+const (
+	// StackMapCompressed is set in the bit count of a stack map
+	// whose bitmaps are delta-encoded instead of stored one after
+	// another. See runtime/symtab.go:stackmap for the encoding.
+	StackMapCompressed = 1 << 30
+
+	// StackMapBlock is the number of bitmaps in each block of a
+	// compressed stack map. Decoding a bitmap starts at its block.
+	StackMapBlock = 16
+
+	// StackMapMaxCompressedBits is the largest number of bits in
+	// the bitmaps of a compressed stack map, which the runtime
+	// decodes into a buffer of this size.
+	StackMapMaxCompressedBits = 2048
+)
+
 // Special PCDATA values.
 const (
 	// PCDATA_UnsafePoint values.
@@ -128,7 +128,8 @@ func (p Pos) String() string {
 // controlled by the showCol flag and if the column is known (!= 0).
 // For positions relative to line directives, the original position is
 // shown as well, as in "filename:line[origfile:origline:origcolumn] if
-// showOrig is set.
+// showOrig is set, followed by how origfile was generated if known, as in
+// "filename:line[origfile:origline:origcolumn, generated by goyacc]".
 func (p Pos) Format(showCol, showOrig bool) string {
 	buf := new(bytes.Buffer)
 	p.WriteTo(buf, showCol, showOrig)
@@ -160,6 +161,10 @@ func (p Pos) WriteTo(w io.Writer, showCol, showOrig bool) {
 	if showOrig {
 		io.WriteString(w, "[")
 		format(w, p.Filename(), p.Line(), p.Col(), showCol)
+		if gen := p.base.Generated(); gen != "" {
+			io.WriteString(w, ", generated ")
+			io.WriteString(w, gen)
+		}
 		io.WriteString(w, "]")
 	}
 }
@@ -192,8 +197,9 @@ func formatstr(filename string, line, col uint, showCol bool) string {
 type PosBase struct {
 	pos         Pos    // position at which the relative position is (line, col)
 	filename    string // file name used to open source file, for error messages
-	absFilename string // absolute file name, for PC-Line tables
+	absFilename string // absolute file name after -trimpath rewriting, for PC-Line tables, DWARF and export data
 	symFilename string // cached symbol file name, to avoid repeated string concatenation
+	generated   string // for file bases, see Generated
 	line, col   uint   // relative line, column number at pos
 	inl         int    // inlining index (see cmd/internal/obj/inl.go)
 }
@@ -218,7 +224,7 @@ func NewFileBase(filename, absFilename string) *PosBase {
 //      /*line filename:line:col*/
 // at position pos.
 func NewLinePragmaBase(pos Pos, filename, absFilename string, line, col uint) *PosBase {
-	return &PosBase{pos, filename, absFilename, FileSymPrefix + absFilename, line, col, -1}
+	return &PosBase{pos, filename, absFilename, FileSymPrefix + absFilename, "", line, col, -1}
 }
 
 // NewInliningBase returns a copy of the old PosBase with the given inlining
@@ -279,6 +285,21 @@ func (b *PosBase) SymFilename() string {
 	return FileSymPrefix + "??"
 }
 
+// Generated returns the description of how the file containing b was
+// generated, such as "by goyacc", from its "// Code generated ...
+// DO NOT EDIT." header, or "" if it was not or is not known to be.
+func (b *PosBase) Generated() string {
+	if b != nil && b.pos.base != nil {
+		return b.pos.base.generated
+	}
+	return ""
+}
+
+// SetGenerated records how the file of the file base b was generated.
+func (b *PosBase) SetGenerated(generated string) {
+	b.generated = generated
+}
+
 // Line returns the line number recorded with the base.
 // If b == nil, the result is 0.
 func (b *PosBase) Line() uint {
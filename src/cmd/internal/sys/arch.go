@@ -52,6 +52,11 @@ type Arch struct {
 	// can combine adjacent loads into a single larger, possibly unaligned, load.
 	// Note that currently the optimizations must be able to handle little endian byte order.
 	CanMergeLoads bool
+
+	// CacheLineSize is the size in bytes of a cache line, used to
+	// lay out values marked //go:cachealign. It matches
+	// internal/cpu.CacheLinePadSize for the architecture.
+	CacheLineSize int
 }
 
 // InFamily reports whether a is a member of any of the specified
@@ -74,6 +79,7 @@ var Arch386 = &Arch{
 	MinLC:         1,
 	Alignment:     1,
 	CanMergeLoads: true,
+	CacheLineSize: 64,
 }
 
 var ArchAMD64 = &Arch{
@@ -85,6 +91,7 @@ var ArchAMD64 = &Arch{
 	MinLC:         1,
 	Alignment:     1,
 	CanMergeLoads: true,
+	CacheLineSize: 64,
 }
 
 var ArchARM = &Arch{
@@ -96,6 +103,7 @@ var ArchARM = &Arch{
 	MinLC:         4,
 	Alignment:     4, // TODO: just for arm5?
 	CanMergeLoads: false,
+	CacheLineSize: 32,
 }
 
 var ArchARM64 = &Arch{
@@ -107,6 +115,7 @@ var ArchARM64 = &Arch{
 	MinLC:         4,
 	Alignment:     1,
 	CanMergeLoads: true,
+	CacheLineSize: 64,
 }
 
 var ArchLoong64 = &Arch{
@@ -118,6 +127,7 @@ var ArchLoong64 = &Arch{
 	MinLC:         4,
 	Alignment:     8, // Unaligned accesses are not guaranteed to be fast
 	CanMergeLoads: false,
+	CacheLineSize: 64,
 }
 
 var ArchMIPS = &Arch{
@@ -129,6 +139,7 @@ var ArchMIPS = &Arch{
 	MinLC:         4,
 	Alignment:     4,
 	CanMergeLoads: false,
+	CacheLineSize: 32,
 }
 
 var ArchMIPSLE = &Arch{
@@ -140,6 +151,7 @@ var ArchMIPSLE = &Arch{
 	MinLC:         4,
 	Alignment:     4,
 	CanMergeLoads: false,
+	CacheLineSize: 32,
 }
 
 var ArchMIPS64 = &Arch{
@@ -151,6 +163,7 @@ var ArchMIPS64 = &Arch{
 	MinLC:         4,
 	Alignment:     8,
 	CanMergeLoads: false,
+	CacheLineSize: 32,
 }
 
 var ArchMIPS64LE = &Arch{
@@ -162,6 +175,7 @@ var ArchMIPS64LE = &Arch{
 	MinLC:         4,
 	Alignment:     8,
 	CanMergeLoads: false,
+	CacheLineSize: 32,
 }
 
 var ArchPPC64 = &Arch{
@@ -173,6 +187,7 @@ var ArchPPC64 = &Arch{
 	MinLC:         4,
 	Alignment:     1,
 	CanMergeLoads: false,
+	CacheLineSize: 128,
 }
 
 var ArchPPC64LE = &Arch{
@@ -184,6 +199,7 @@ var ArchPPC64LE = &Arch{
 	MinLC:         4,
 	Alignment:     1,
 	CanMergeLoads: true,
+	CacheLineSize: 128,
 }
 
 var ArchRISCV64 = &Arch{
@@ -195,6 +211,7 @@ var ArchRISCV64 = &Arch{
 	MinLC:         4,
 	Alignment:     8, // riscv unaligned loads work, but are really slow (trap + simulated by OS)
 	CanMergeLoads: false,
+	CacheLineSize: 32,
 }
 
 var ArchS390X = &Arch{
@@ -206,6 +223,7 @@ var ArchS390X = &Arch{
 	MinLC:         2,
 	Alignment:     1,
 	CanMergeLoads: true,
+	CacheLineSize: 256,
 }
 
 var ArchWasm = &Arch{
@@ -217,6 +235,7 @@ var ArchWasm = &Arch{
 	MinLC:         1,
 	Alignment:     1,
 	CanMergeLoads: false,
+	CacheLineSize: 64,
 }
 
 var Archs = [...]*Arch{
@@ -49,6 +49,12 @@ Flags:
 		Link with C/C++ address sanitizer support.
 	-buildid id
 		Record id as Go toolchain build id.
+	-buildinputs file
+		Write to file the import path of each Go package linked and
+		the digest of the compiler inputs recorded in its object file:
+		the toolchain version and configuration, the flags, the source
+		and embedded files, and the fingerprints of the imported packages.
+		Packages built from the same inputs have the same digest.
 	-buildmode mode
 		Set build mode (default exe).
 	-c
@@ -64,6 +70,9 @@ Flags:
 		The dynamic header is on by default, even without any
 		references to dynamic libraries, because many common
 		system tools now assume the presence of the header.
+	-debughot int
+		Report the number of pages spanned by hot functions
+		before and after placing them together.
 	-debugtramp int
 		Debug trampolines.
 	-dumpdep
@@ -64,6 +64,9 @@ func ldpkg(ctxt *Link, f *bio.Reader, lib *sym.Library, length int64, filename s
 		if line == "main" {
 			lib.Main = true
 		}
+		if strings.HasPrefix(line, "inputs ") {
+			lib.Inputs = line[len("inputs "):]
+		}
 		if line == "" {
 			break
 		}
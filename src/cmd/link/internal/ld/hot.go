@@ -0,0 +1,90 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ld
+
+import (
+	"cmd/link/internal/loader"
+	"cmd/link/internal/sym"
+)
+
+// hotPageSize is the page size used to report how many pages the hot
+// functions occupy, as a proxy for their instruction TLB footprint.
+const hotPageSize = 4096
+
+// orderHotText moves the functions that the compiler marked as hot,
+// from a CPU profile given to it with -pgoprofile, to the start of
+// the text section, keeping the order of the hot functions among
+// themselves and of everything else. Placing them together packs the
+// code that runs most into fewer cache lines and pages.
+//
+// The functions of each compilation unit are reordered the same way,
+// so that each unit's first function is still its lowest addressed.
+func (ctxt *Link) orderHotText() {
+	if thearch.Trampoline != nil {
+		// The trampoline pass expects packages in dependency order.
+		return
+	}
+	ldr := ctxt.loader
+
+	var before int
+	if *flagDebugHot > 0 {
+		before = ctxt.hotPages()
+	}
+	var hot, cold []loader.Sym
+	for _, s := range ctxt.Textp {
+		if ldr.IsHot(s) {
+			hot = append(hot, s)
+		} else {
+			cold = append(cold, s)
+		}
+	}
+	if len(hot) == 0 {
+		return
+	}
+	ctxt.Textp = append(hot, cold...)
+	for _, lib := range ctxt.Library {
+		for _, unit := range lib.Units {
+			var hot, cold []sym.LoaderSym
+			for _, s := range unit.Textp {
+				if ldr.IsHot(loader.Sym(s)) {
+					hot = append(hot, s)
+				} else {
+					cold = append(cold, s)
+				}
+			}
+			unit.Textp = append(hot, cold...)
+		}
+	}
+	if *flagDebugHot > 0 {
+		var size int64
+		for _, s := range hot {
+			size += ldr.SymSize(s)
+		}
+		ctxt.Logf("hot text: %d functions, %d bytes, %d pages before ordering, %d after\n", len(hot), size, before, ctxt.hotPages())
+	}
+}
+
+// hotPages returns the number of hotPageSize pages that contain hot
+// functions if the text symbols are laid out in their current order.
+func (ctxt *Link) hotPages() int {
+	ldr := ctxt.loader
+	pages := make(map[int64]bool)
+	var off int64
+	for _, s := range ctxt.Textp {
+		align := int64(ldr.SymAlign(s))
+		if align == 0 {
+			align = int64(Funcalign)
+		}
+		off = (off + align - 1) &^ (align - 1)
+		size := ldr.SymSize(s)
+		if ldr.IsHot(s) && size > 0 {
+			for p := off / hotPageSize; p <= (off+size-1)/hotPageSize; p++ {
+				pages[p] = true
+			}
+		}
+		off += size
+	}
+	return len(pages)
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ld
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// writeBuildInputs writes, for -buildinputs, the import path of each
+// Go package being linked and the digest of the inputs it was compiled
+// from, as recorded by the compiler in its object header, one package
+// per line, sorted by path. A package rebuilt from the same source
+// files, imports, flags and toolchain has the same digest, so comparing
+// the files of two links tells which packages, if any, were built
+// differently.
+func (ctxt *Link) writeBuildInputs(file string) {
+	var lines []string
+	for _, lib := range ctxt.Library {
+		if lib.Inputs != "" {
+			lines = append(lines, fmt.Sprintf("%s %s\n", lib.Pkg, lib.Inputs))
+		}
+	}
+	sort.Strings(lines)
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0666); err != nil {
+		Exitf("cannot write build inputs: %v", err)
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"cmd/internal/goobj"
 	"cmd/internal/obj"
 	"cmd/internal/objabi"
+	"cmd/internal/src"
 	"cmd/internal/sys"
 	"cmd/link/internal/loadelf"
 	"cmd/link/internal/loader"
@@ -817,6 +818,7 @@ func (ctxt *Link) linksetup() {
 		intlibs = append(intlibs, isRuntimeDepPkg(lib.Pkg))
 	}
 	ctxt.Textp = ctxt.loader.AssignTextSymbolOrder(ctxt.Library, intlibs, ctxt.Textp)
+	ctxt.orderHotText()
 }
 
 // mangleTypeSym shortens the names of symbols that represent Go types
@@ -2209,6 +2211,7 @@ type chain struct {
 	sym   loader.Sym
 	up    *chain
 	limit int // limit on entry to sym
+	off   int // offset of the call to sym in up.sym, or -1
 }
 
 func haslinkregister(ctxt *Link) bool {
@@ -2222,6 +2225,15 @@ func callsize(ctxt *Link) int {
 	return ctxt.Arch.RegSize
 }
 
+// framePointerSize returns the number of bytes a function may use
+// below SP on entry to save the frame pointer.
+func framePointerSize() int {
+	if buildcfg.GOARCH == "arm64" {
+		return 8
+	}
+	return 0
+}
+
 type stkChk struct {
 	ldr       *loader.Loader
 	ctxt      *Link
@@ -2248,11 +2260,8 @@ func (ctxt *Link) dostkcheck() {
 	// of stack, following direct calls in order to piece together chains
 	// of non-splitting functions.
 	var ch chain
-	ch.limit = objabi.StackLimit - callsize(ctxt)
-	if buildcfg.GOARCH == "arm64" {
-		// need extra 8 bytes below SP to save FP
-		ch.limit -= 8
-	}
+	ch.limit = objabi.StackLimit - callsize(ctxt) - framePointerSize()
+	ch.off = -1
 
 	// Check every function, but do the nosplit functions in a first pass,
 	// to make the printed failure chains as short as possible.
@@ -2329,6 +2338,7 @@ func (sc *stkChk) check(up *chain, depth int) int {
 		// Ensure we have enough stack to call morestack.
 		ch.limit = limit - callsize(ctxt)
 		ch.sym = sc.morestack
+		ch.off = -1
 		if sc.check(&ch, depth+1) < 0 {
 			return -1
 		}
@@ -2365,6 +2375,7 @@ func (sc *stkChk) check(up *chain, depth int) int {
 			case t.IsDirectCall():
 				ch.limit = int(int32(limit) - pcsp.Value - int32(callsize(ctxt)))
 				ch.sym = r.Sym()
+				ch.off = int(r.Off())
 				if sc.check(&ch, depth+1) < 0 {
 					return -1
 				}
@@ -2376,9 +2387,11 @@ func (sc *stkChk) check(up *chain, depth int) int {
 			case t == objabi.R_CALLIND:
 				ch.limit = int(int32(limit) - pcsp.Value - int32(callsize(ctxt)))
 				ch.sym = 0
+				ch.off = int(r.Off())
 				ch1.limit = ch.limit - callsize(ctxt) // for morestack in called prologue
 				ch1.up = &ch
 				ch1.sym = sc.morestack
+				ch1.off = -1
 				if sc.check(&ch1, depth+2) < 0 {
 					return -1
 				}
@@ -2392,6 +2405,10 @@ func (sc *stkChk) check(up *chain, depth int) int {
 func (sc *stkChk) broke(ch *chain, limit int) {
 	sc.ctxt.Errorf(ch.sym, "nosplit stack overflow")
 	sc.print(ch, limit)
+	sc.printLimit()
+	if limit < 0 {
+		fmt.Printf("\toverflows by %d bytes\n", -limit)
+	}
 }
 
 func (sc *stkChk) print(ch *chain, limit int) {
@@ -2406,18 +2423,28 @@ func (sc *stkChk) print(ch *chain, limit int) {
 	} else {
 		name = "function pointer"
 	}
+	entry := name
+	if ch.sym != 0 {
+		if frame := sc.frameSize(ch.sym); frame >= 0 {
+			entry += fmt.Sprintf(", frame %d", frame)
+		}
+	}
 
 	if ch.up == nil {
 		// top of chain. ch.sym != 0.
 		if ldr.IsNoSplit(ch.sym) {
-			fmt.Printf("\t%d\tassumed on entry to %s\n", ch.limit, name)
+			fmt.Printf("\t%d\tassumed on entry to %s\n", ch.limit, entry)
 		} else {
-			fmt.Printf("\t%d\tguaranteed after split check in %s\n", ch.limit, name)
+			fmt.Printf("\t%d\tguaranteed after split check in %s\n", ch.limit, entry)
 		}
 	} else {
 		sc.print(ch.up, ch.limit+callsize(ctxt))
-		if !haslinkregister(ctxt) {
-			fmt.Printf("\t%d\ton entry to %s\n", ch.limit, name)
+		pos := sc.callPos(ch)
+		switch {
+		case pos != "":
+			fmt.Printf("\t%d\ton entry to %s, called at %s\n", ch.limit, entry, pos)
+		case !haslinkregister(ctxt):
+			fmt.Printf("\t%d\ton entry to %s\n", ch.limit, entry)
 		}
 	}
 
@@ -2426,6 +2453,74 @@ func (sc *stkChk) print(ch *chain, limit int) {
 	}
 }
 
+// printLimit explains where the stack limit assumed on entry
+// to a nosplit function comes from.
+func (sc *stkChk) printLimit() {
+	fmt.Printf("\tlimit %d = StackGuard %d", objabi.StackLimit-callsize(sc.ctxt)-framePointerSize(), objabi.StackGuard)
+	if objabi.StackSystem != 0 {
+		fmt.Printf(" - StackSystem %d", objabi.StackSystem)
+	}
+	fmt.Printf(" - StackSmall %d", objabi.StackSmall)
+	if n := callsize(sc.ctxt); n != 0 {
+		fmt.Printf(" - call %d", n)
+	}
+	if n := framePointerSize(); n != 0 {
+		fmt.Printf(" - frame pointer %d", n)
+	}
+	fmt.Printf("\n")
+}
+
+// frameSize returns the largest amount of stack s uses below its
+// entry SP, or -1 if s has no stack information.
+func (sc *stkChk) frameSize(s loader.Sym) int {
+	if info := sc.ldr.FuncInfo(s); !info.Valid() {
+		return -1
+	}
+	frame := 0
+	pcsp := obj.NewPCIter(uint32(sc.ctxt.Arch.MinLC))
+	for pcsp.Init(sc.ldr.Data(sc.ldr.Pcsp(s))); !pcsp.Done; pcsp.Next() {
+		if int(pcsp.Value) > frame {
+			frame = int(pcsp.Value)
+		}
+	}
+	return frame
+}
+
+// callPos returns the file:line of the call to ch.sym in its caller,
+// or "" if it is not known.
+func (sc *stkChk) callPos(ch *chain) string {
+	ldr := sc.ldr
+	if ch.off < 0 || ch.up == nil || ch.up.sym == 0 {
+		return ""
+	}
+	s := ch.up.sym
+	if info := ldr.FuncInfo(s); !info.Valid() {
+		return ""
+	}
+	_, pcfile, pcline, _, _ := ldr.PcdataAuxs(s, nil)
+	if pcfile == 0 || pcline == 0 {
+		return ""
+	}
+	file := pcValue(sc.ctxt, ldr.Data(pcfile), ch.off)
+	line := pcValue(sc.ctxt, ldr.Data(pcline), ch.off)
+	cu := ldr.SymUnit(s)
+	if cu == nil || file < 0 || int(file) >= len(cu.FileTable) {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", strings.TrimPrefix(cu.FileTable[file], src.FileSymPrefix), line)
+}
+
+// pcValue returns the value the pc-value table data has at offset off.
+func pcValue(ctxt *Link, data []byte, off int) int32 {
+	it := obj.NewPCIter(uint32(ctxt.Arch.MinLC))
+	for it.Init(data); !it.Done; it.Next() {
+		if uint32(off) < it.NextPC {
+			return it.Value
+		}
+	}
+	return -1
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, "usage: link [options] main.o\n")
 	objabi.Flagprint(os.Stderr)
@@ -60,7 +60,8 @@ func init() {
 
 // Flags used by the linker. The exported flags are used by the architecture-specific packages.
 var (
-	flagBuildid = flag.String("buildid", "", "record `id` as Go toolchain build id")
+	flagBuildid     = flag.String("buildid", "", "record `id` as Go toolchain build id")
+	flagBuildInputs = flag.String("buildinputs", "", "write the build inputs digest of each package linked to `file`")
 
 	flagOutfile    = flag.String("o", "", "write output to `file`")
 	flagPluginPath = flag.String("pluginpath", "", "full path name for plugin")
@@ -93,6 +94,7 @@ var (
 	flagInterpreter   = flag.String("I", "", "use `linker` as ELF dynamic linker")
 	FlagDebugTramp    = flag.Int("debugtramp", 0, "debug trampolines")
 	FlagDebugTextSize = flag.Int("debugtextsize", 0, "debug text section max size")
+	flagDebugHot      = flag.Int("debughot", 0, "report placement of hot functions")
 	FlagStrictDups    = flag.Int("strictdups", 0, "sanity check duplicate symbol contents during object file reading (1=warn 2=err).")
 	FlagRound         = flag.Int("R", -1, "set address rounding `quantum`")
 	FlagTextAddr      = flag.Int64("T", -1, "set text segment `address`")
@@ -260,6 +262,11 @@ func Main(arch *sys.Arch, theArch Arch) {
 	bench.Start("loadlib")
 	ctxt.loadlib()
 
+	if *flagBuildInputs != "" {
+		bench.Start("buildinputs")
+		ctxt.writeBuildInputs(*flagBuildInputs)
+	}
+
 	bench.Start("deadcode")
 	deadcode(ctxt)
 
@@ -19,6 +19,8 @@ import (
 
 const funcSize = 10 * 4 // funcSize is the size of the _func object in runtime/runtime2.go
 
+const inlinedCallSize = 3 * 4 // inlinedCallSize is the size of the inlinedCall object in runtime/symtab.go
+
 // pclntab holds the state needed for pclntab generation.
 type pclntab struct {
 	// The first and last functions found.
@@ -159,29 +161,29 @@ func genInlTreeSym(ctxt *Link, cu *sym.CompilationUnit, fi loader.FuncInfo, arch
 	inlTreeSym.SetType(sym.SGOFUNC)
 	ldr.SetAttrReachable(its, true)
 	ldr.SetSymAlign(its, 4) // it has 32-bit fields
-	ninl := fi.NumInlTree()
-	for i := 0; i < int(ninl); i++ {
+	ninl := int(fi.NumInlTree())
+	size := int64(ninl * inlinedCallSize)
+	inlTreeSym.SetSize(size)
+	inlTreeSym.Grow(size)
+	for i := 0; i < ninl; i++ {
 		call := fi.InlTree(i)
-		val := call.File
 		nameoff, ok := nameOffsets[call.Func]
 		if !ok {
 			panic("couldn't find function name offset")
 		}
 
-		inlTreeSym.SetUint16(arch, int64(i*20+0), uint16(call.Parent))
 		inlFunc := ldr.FuncInfo(call.Func)
-
 		var funcID objabi.FuncID
 		if inlFunc.Valid() {
 			funcID = inlFunc.FuncID()
 		}
-		inlTreeSym.SetUint8(arch, int64(i*20+2), uint8(funcID))
 
-		// byte 3 is unused
-		inlTreeSym.SetUint32(arch, int64(i*20+4), uint32(val))
-		inlTreeSym.SetUint32(arch, int64(i*20+8), uint32(call.Line))
-		inlTreeSym.SetUint32(arch, int64(i*20+12), uint32(nameoff))
-		inlTreeSym.SetUint32(arch, int64(i*20+16), uint32(call.ParentPC))
+		// Keep in sync with runtime/symtab.go:inlinedCall.
+		off := int64(i * inlinedCallSize)
+		inlTreeSym.SetUint8(arch, off+0, uint8(funcID))
+		// bytes 1-3 are unused
+		inlTreeSym.SetUint32(arch, off+4, uint32(nameoff))
+		inlTreeSym.SetUint32(arch, off+8, uint32(call.ParentPC))
 	}
 	return its
 }
@@ -298,11 +300,21 @@ func (state *pclntab) generateFuncnametab(ctxt *Link, funcs []loader.Sym) map[lo
 		return name[:i], "[...]", name[j+1:]
 	}
 
+	// A function specialized by the compiler is reported by the name
+	// of the function it copies.
+	funcName := func(s loader.Sym) string {
+		name := ctxt.loader.SymName(s)
+		if ctxt.loader.IsSpecialized(s) {
+			name = name[:strings.LastIndexByte(name, '.')]
+		}
+		return name
+	}
+
 	// Write the null terminated strings.
 	writeFuncNameTab := func(ctxt *Link, s loader.Sym) {
 		symtab := ctxt.loader.MakeSymbolUpdater(s)
 		for s, off := range nameOffsets {
-			a, b, c := nameParts(ctxt.loader.SymName(s))
+			a, b, c := nameParts(funcName(s))
 			o := int64(off)
 			o = symtab.AddStringAt(o, a)
 			o = symtab.AddStringAt(o, b)
@@ -314,7 +326,7 @@ func (state *pclntab) generateFuncnametab(ctxt *Link, funcs []loader.Sym) map[lo
 	var size int64
 	walkFuncs(ctxt, funcs, func(s loader.Sym) {
 		nameOffsets[s] = uint32(size)
-		a, b, c := nameParts(ctxt.loader.SymName(s))
+		a, b, c := nameParts(funcName(s))
 		size += int64(len(a) + len(b) + len(c) + 1) // NULL terminate
 	})
 
@@ -323,7 +335,9 @@ func (state *pclntab) generateFuncnametab(ctxt *Link, funcs []loader.Sym) map[lo
 }
 
 // walkFilenames walks funcs, calling a function for each filename used in each
-// function's line table.
+// function's line table. The files of inlined calls are not walked: the
+// runtime finds the position of an inlined call from its parent PC, so they
+// need no entries in runtime.cutab.
 func walkFilenames(ctxt *Link, funcs []loader.Sym, f func(*sym.CompilationUnit, goobj.CUFileIndex)) {
 	ldr := ctxt.loader
 
@@ -339,10 +353,6 @@ func walkFilenames(ctxt *Link, funcs []loader.Sym, f func(*sym.CompilationUnit,
 		for i, nf := 0, int(fi.NumFile()); i < nf; i++ {
 			f(cu, fi.File(i))
 		}
-		for i, ninl := 0, int(fi.NumInlTree()); i < ninl; i++ {
-			call := fi.InlTree(i)
-			f(cu, call.File)
-		}
 	}
 }
 
@@ -462,7 +472,7 @@ func (state *pclntab) generatePctab(ctxt *Link, funcs []loader.Sym) {
 	size := int64(1)
 
 	// Walk the functions, finding offset to store each pcdata.
-	seen := make(map[loader.Sym]struct{})
+	seen := make(map[loader.Sym]struct{}, state.nfunc)
 	saveOffset := func(pcSym loader.Sym) {
 		if _, ok := seen[pcSym]; !ok {
 			datSize := ldr.SymSize(pcSym)
@@ -486,10 +496,9 @@ func (state *pclntab) generatePctab(ctxt *Link, funcs []loader.Sym) {
 		fi.Preload()
 		pcsp, pcfile, pcline, pcinline, pcdata = ldr.PcdataAuxs(s, pcdata)
 
-		pcSyms := []loader.Sym{pcsp, pcfile, pcline}
-		for _, pcSym := range pcSyms {
-			saveOffset(pcSym)
-		}
+		saveOffset(pcsp)
+		saveOffset(pcfile)
+		saveOffset(pcline)
 		for _, pcSym := range pcdata {
 			saveOffset(pcSym)
 		}
@@ -677,7 +686,8 @@ func writeFuncs(ctxt *Link, sb *loader.SymbolBuilder, funcs []loader.Sym, inlSym
 		} else {
 			off += 12
 		}
-		off = sb.SetUint32(ctxt.Arch, off, uint32(numPCData(ldr, s, fi)))
+		npcdata := numPCData(ldr, s, fi)
+		off = sb.SetUint32(ctxt.Arch, off, npcdata)
 
 		// Store the offset to compilation unit's file table.
 		cuIdx := ^uint32(0)
@@ -719,7 +729,7 @@ func writeFuncs(ctxt *Link, sb *loader.SymbolBuilder, funcs []loader.Sym, inlSym
 		// Write funcdata refs as offsets from go.func.* and go.funcrel.*.
 		funcdata = funcData(ldr, s, fi, inlSyms[s], funcdata)
 		// Missing funcdata will be ^0. See runtime/symtab.go:funcdata.
-		off = int64(startLocations[i] + funcSize + numPCData(ldr, s, fi)*4)
+		off = int64(startLocations[i] + funcSize + npcdata*4)
 		for j := range funcdata {
 			dataoff := off + int64(4*j)
 			fdsym := funcdata[j]
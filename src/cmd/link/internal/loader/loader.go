@@ -1201,6 +1201,27 @@ func (l *Loader) IsDict(i Sym) bool {
 	return r.Sym(li).IsDict()
 }
 
+// Returns whether this symbol is a function that the compiler's
+// profile (-pgoprofile) marked as hot.
+func (l *Loader) IsHot(i Sym) bool {
+	if l.IsExternal(i) {
+		return false
+	}
+	r, li := l.toLocal(i)
+	return r.Sym(li).Hot()
+}
+
+// Returns whether this symbol is a function that the compiler
+// specialized for constant arguments. Its name is that of the
+// function it copies, followed by a "." and a suffix.
+func (l *Loader) IsSpecialized(i Sym) bool {
+	if l.IsExternal(i) {
+		return false
+	}
+	r, li := l.toLocal(i)
+	return r.Sym(li).Specialized()
+}
+
 // Return whether this is a trampoline of a deferreturn call.
 func (l *Loader) IsDeferReturnTramp(i Sym) bool {
 	return l.deferReturnTramp[i]
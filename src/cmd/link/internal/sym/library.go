@@ -16,6 +16,7 @@ type Library struct {
 	Autolib     []goobj.ImportedPkg
 	Imports     []*Library
 	Main        bool
+	Inputs      string // digest of the compiler inputs, from the object header
 	Units       []*CompilationUnit
 
 	Textp       []LoaderSym // text syms defined in this library
@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"strings"
@@ -1059,3 +1060,112 @@ func TestLargeReloc(t *testing.T) {
 		}
 	}
 }
+
+const testNosplitOverflowSrc = `
+package main
+
+//go:nosplit
+func a(x int) int {
+	var buf [60]int
+	buf[x] = x
+	return b(x) + buf[x+1]
+}
+
+//go:nosplit
+func b(x int) int {
+	var buf [60]int
+	buf[x] = x
+	return buf[x+1]
+}
+
+func main() { println(a(1)) }
+`
+
+func TestNosplitOverflowReport(t *testing.T) {
+	// Test that a nosplit stack overflow reports each function in the
+	// chain with its frame size and call site, and how the limit is
+	// computed.
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	tmpdir := t.TempDir()
+
+	src := filepath.Join(tmpdir, "x.go")
+	err := ioutil.WriteFile(src, []byte(testNosplitOverflowSrc), 0666)
+	if err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+	cmd := exec.Command(testenv.GoToolPath(t), "build", "-gcflags=-l", "-o", filepath.Join(tmpdir, "x.exe"), src)
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("build succeeded unexpectedly")
+	}
+
+	for _, want := range []string{
+		`main\.b: nosplit stack overflow`,
+		`\n\t\d+\tassumed on entry to main\.a<\d+> \(nosplit\), frame \d+\n`,
+		`\n\t-?\d+\ton entry to main\.b<\d+> \(nosplit\), frame \d+, called at .*x\.go:8\n`,
+		`\n\t-\d+\tafter main\.b<\d+> \(nosplit\) uses \d+\n`,
+		`\n\tlimit \d+ = StackGuard \d+ .*- StackSmall 128`,
+		`\n\toverflows by \d+ bytes\n`,
+	} {
+		if !regexp.MustCompile(want).Match(out) {
+			t.Errorf("build output does not match %#q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBuildInputs(t *testing.T) {
+	// Test that -buildinputs lists the digest of the compiler inputs
+	// of each package linked, and that it changes with the source
+	// of the package only.
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	tmpdir := t.TempDir()
+
+	src := filepath.Join(tmpdir, "x.go")
+	inputs := filepath.Join(tmpdir, "inputs.txt")
+	build := func(source string) map[string]string {
+		t.Helper()
+		if err := ioutil.WriteFile(src, []byte(source), 0666); err != nil {
+			t.Fatalf("failed to write source file: %v", err)
+		}
+		cmd := exec.Command(testenv.GoToolPath(t), "build", "-ldflags=-buildinputs="+inputs, "-o", filepath.Join(tmpdir, "x.exe"), src)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("build failed: %v\n%s", err, out)
+		}
+		data, err := ioutil.ReadFile(inputs)
+		if err != nil {
+			t.Fatalf("failed to read build inputs: %v", err)
+		}
+		digests := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			f := strings.Fields(line)
+			if len(f) != 2 || len(f[1]) != 64 {
+				t.Fatalf("malformed build inputs line %q", line)
+			}
+			digests[f[0]] = f[1]
+		}
+		return digests
+	}
+
+	const hello = "package main\n\nfunc main() { println(\"hello\") }\n"
+	first := build(hello)
+	for _, pkg := range []string{"main", "runtime"} {
+		if first[pkg] == "" {
+			t.Errorf("no build inputs digest for %s", pkg)
+		}
+	}
+	if second := build(hello); !reflect.DeepEqual(first, second) {
+		t.Errorf("build inputs digests changed on rebuild:\n%v\n%v", first, second)
+	}
+
+	changed := build(strings.Replace(hello, "hello", "goodbye", 1))
+	if changed["main"] == first["main"] {
+		t.Errorf("build inputs digest of main did not change with its source")
+	}
+	if changed["runtime"] != first["runtime"] {
+		t.Errorf("build inputs digest of runtime changed with the source of main")
+	}
+}
@@ -164,11 +164,20 @@ func TestStdTest(t *testing.T) {
 	}
 
 	testTestDir(t, filepath.Join(runtime.GOROOT(), "test"),
-		"cmplxdivide.go", // also needs file cmplxdivide1.go - ignore
-		"directive.go",   // tests compiler rejection of bad directive placement - ignore
-		"embedfunc.go",   // tests //go:embed
-		"embedvers.go",   // tests //go:embed
-		"linkname2.go",   // go/types doesn't check validity of //go:xxx directives
+		"cachealign3.go",    // go/types doesn't check validity of //go:xxx directives
+		"cmplxdivide.go",    // also needs file cmplxdivide1.go - ignore
+		"cpudispatch2.go",   // tests //go:cpu
+		"cpudispatch3.go",   // tests //go:cpu
+		"directive.go",      // tests compiler rejection of bad directive placement - ignore
+		"embedfunc.go",      // tests //go:embed
+		"embedvers.go",      // tests //go:embed
+		"linkname2.go",      // go/types doesn't check validity of //go:xxx directives
+		"linkname3.go",      // go/types doesn't check validity of //go:xxx directives
+		"noalloc.go",        // tests //go:noalloc
+		"noallocpackage.go", // errors are reported by escape analysis
+		"nocompare.go",      // tests //go:nocompare and //go:nohash
+		"noheaplocals.go",   // tests //go:noheaplocals
+		"pure.go",           // tests //go:pure
 	)
 }
 
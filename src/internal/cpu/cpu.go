@@ -21,7 +21,8 @@ var CacheLineSize uintptr = CacheLinePadSize
 
 // The booleans in X86 contain the correspondingly named cpuid feature bit.
 // HasAVX and HasAVX2 are only set if the OS does support XMM and YMM registers
-// in addition to the cpuid feature bit being set.
+// in addition to the cpuid feature bit being set. The HasAVX512 booleans
+// additionally require OS support for the opmask and ZMM registers.
 // The struct is padded to avoid false sharing.
 var X86 struct {
 	_            CacheLinePad
@@ -29,10 +30,20 @@ var X86 struct {
 	HasADX       bool
 	HasAVX       bool
 	HasAVX2      bool
+	HasAVX512BW  bool
+	HasAVX512CD  bool
+	HasAVX512DQ  bool
+	HasAVX512F   bool
+	HasAVX512VL  bool
 	HasBMI1      bool
 	HasBMI2      bool
+	HasCX16      bool
 	HasERMS      bool
+	HasF16C      bool
 	HasFMA       bool
+	HasLAHF      bool
+	HasLZCNT     bool
+	HasMOVBE     bool
 	HasOSXSAVE   bool
 	HasPCLMULQDQ bool
 	HasPOPCNT    bool
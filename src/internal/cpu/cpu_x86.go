@@ -23,19 +23,31 @@ const (
 	cpuid_PCLMULQDQ = 1 << 1
 	cpuid_SSSE3     = 1 << 9
 	cpuid_FMA       = 1 << 12
+	cpuid_CX16      = 1 << 13
 	cpuid_SSE41     = 1 << 19
 	cpuid_SSE42     = 1 << 20
+	cpuid_MOVBE     = 1 << 22
 	cpuid_POPCNT    = 1 << 23
 	cpuid_AES       = 1 << 25
 	cpuid_OSXSAVE   = 1 << 27
 	cpuid_AVX       = 1 << 28
+	cpuid_F16C      = 1 << 29
 
 	// ebx bits
-	cpuid_BMI1 = 1 << 3
-	cpuid_AVX2 = 1 << 5
-	cpuid_BMI2 = 1 << 8
-	cpuid_ERMS = 1 << 9
-	cpuid_ADX  = 1 << 19
+	cpuid_BMI1     = 1 << 3
+	cpuid_AVX2     = 1 << 5
+	cpuid_BMI2     = 1 << 8
+	cpuid_ERMS     = 1 << 9
+	cpuid_AVX512F  = 1 << 16
+	cpuid_AVX512DQ = 1 << 17
+	cpuid_ADX      = 1 << 19
+	cpuid_AVX512CD = 1 << 28
+	cpuid_AVX512BW = 1 << 30
+	cpuid_AVX512VL = 1 << 31
+
+	// ecx bits for CPUID 0x80000001
+	cpuid_LAHF  = 1 << 0
+	cpuid_LZCNT = 1 << 5
 
 	// edx bits for CPUID 0x80000001
 	cpuid_RDTSCP = 1 << 27
@@ -49,10 +61,20 @@ func doinit() {
 		{Name: "aes", Feature: &X86.HasAES},
 		{Name: "avx", Feature: &X86.HasAVX},
 		{Name: "avx2", Feature: &X86.HasAVX2},
+		{Name: "avx512bw", Feature: &X86.HasAVX512BW},
+		{Name: "avx512cd", Feature: &X86.HasAVX512CD},
+		{Name: "avx512dq", Feature: &X86.HasAVX512DQ},
+		{Name: "avx512f", Feature: &X86.HasAVX512F},
+		{Name: "avx512vl", Feature: &X86.HasAVX512VL},
 		{Name: "bmi1", Feature: &X86.HasBMI1},
 		{Name: "bmi2", Feature: &X86.HasBMI2},
+		{Name: "cx16", Feature: &X86.HasCX16},
 		{Name: "erms", Feature: &X86.HasERMS},
+		{Name: "f16c", Feature: &X86.HasF16C},
 		{Name: "fma", Feature: &X86.HasFMA},
+		{Name: "lahf", Feature: &X86.HasLAHF},
+		{Name: "lzcnt", Feature: &X86.HasLZCNT},
+		{Name: "movbe", Feature: &X86.HasMOVBE},
 		{Name: "pclmulqdq", Feature: &X86.HasPCLMULQDQ},
 		{Name: "popcnt", Feature: &X86.HasPOPCNT},
 		{Name: "rdtscp", Feature: &X86.HasRDTSCP},
@@ -75,8 +97,10 @@ func doinit() {
 	X86.HasSSE3 = isSet(ecx1, cpuid_SSE3)
 	X86.HasPCLMULQDQ = isSet(ecx1, cpuid_PCLMULQDQ)
 	X86.HasSSSE3 = isSet(ecx1, cpuid_SSSE3)
+	X86.HasCX16 = isSet(ecx1, cpuid_CX16)
 	X86.HasSSE41 = isSet(ecx1, cpuid_SSE41)
 	X86.HasSSE42 = isSet(ecx1, cpuid_SSE42)
+	X86.HasMOVBE = isSet(ecx1, cpuid_MOVBE)
 	X86.HasPOPCNT = isSet(ecx1, cpuid_POPCNT)
 	X86.HasAES = isSet(ecx1, cpuid_AES)
 
@@ -91,15 +115,18 @@ func doinit() {
 	// Section 2.4 "AVX and SSE Instruction Exception Specification"
 	X86.HasFMA = isSet(ecx1, cpuid_FMA) && X86.HasOSXSAVE
 
-	osSupportsAVX := false
+	osSupportsAVX, osSupportsAVX512 := false, false
 	// For XGETBV, OSXSAVE bit is required and sufficient.
 	if X86.HasOSXSAVE {
 		eax, _ := xgetbv()
 		// Check if XMM and YMM registers have OS support.
 		osSupportsAVX = isSet(eax, 1<<1) && isSet(eax, 1<<2)
+		// Check if opmask and ZMM registers have OS support.
+		osSupportsAVX512 = osSupportsAVX && isSet(eax, 1<<5) && isSet(eax, 1<<6) && isSet(eax, 1<<7)
 	}
 
 	X86.HasAVX = isSet(ecx1, cpuid_AVX) && osSupportsAVX
+	X86.HasF16C = isSet(ecx1, cpuid_F16C) && osSupportsAVX
 
 	if maxID < 7 {
 		return
@@ -111,6 +138,11 @@ func doinit() {
 	X86.HasBMI2 = isSet(ebx7, cpuid_BMI2)
 	X86.HasERMS = isSet(ebx7, cpuid_ERMS)
 	X86.HasADX = isSet(ebx7, cpuid_ADX)
+	X86.HasAVX512F = isSet(ebx7, cpuid_AVX512F) && osSupportsAVX512
+	X86.HasAVX512BW = isSet(ebx7, cpuid_AVX512BW) && osSupportsAVX512
+	X86.HasAVX512CD = isSet(ebx7, cpuid_AVX512CD) && osSupportsAVX512
+	X86.HasAVX512DQ = isSet(ebx7, cpuid_AVX512DQ) && osSupportsAVX512
+	X86.HasAVX512VL = isSet(ebx7, cpuid_AVX512VL) && osSupportsAVX512
 
 	var maxExtendedInformation uint32
 	maxExtendedInformation, _, _, _ = cpuid(0x80000000, 0)
@@ -119,7 +151,9 @@ func doinit() {
 		return
 	}
 
-	_, _, _, edxExt1 := cpuid(0x80000001, 0)
+	_, _, ecxExt1, edxExt1 := cpuid(0x80000001, 0)
+	X86.HasLAHF = isSet(ecxExt1, cpuid_LAHF)
+	X86.HasLZCNT = isSet(ecxExt1, cpuid_LZCNT)
 	X86.HasRDTSCP = isSet(edxExt1, cpuid_RDTSCP)
 }
 
@@ -138,10 +138,20 @@ func full(c *hchan) bool {
 	return c.qcount == c.dataqsiz
 }
 
+// chanFlags are the facts about a channel that compiled code can
+// prove for an operation on it, to skip checking them at run time.
+// Keep in sync with cmd/compile/internal/chanflags.
+type chanFlags uint8
+
+const (
+	chanNotNil    chanFlags = 1 << iota // the channel is not nil
+	chanNotClosed                       // the channel is never closed
+)
+
 // entry point for c <- x from compiled code
 //go:nosplit
-func chansend1(c *hchan, elem unsafe.Pointer) {
-	chansend(c, elem, true, getcallerpc())
+func chansend1(c *hchan, elem unsafe.Pointer, flags chanFlags) {
+	chansend(c, elem, true, flags, getcallerpc())
 }
 
 /*
@@ -156,8 +166,8 @@ func chansend1(c *hchan, elem unsafe.Pointer) {
  * been closed.  it is easiest to loop and re-run
  * the operation; we'll see that it's now closed.
  */
-func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
-	if c == nil {
+func chansend(c *hchan, ep unsafe.Pointer, block bool, flags chanFlags, callerpc uintptr) bool {
+	if flags&chanNotNil == 0 && c == nil {
 		if !block {
 			return false
 		}
@@ -200,7 +210,7 @@ func chansend(c *hchan, ep unsafe.Pointer, block bool, callerpc uintptr) bool {
 
 	lock(&c.lock)
 
-	if c.closed != 0 {
+	if flags&chanNotClosed == 0 && c.closed != 0 {
 		unlock(&c.lock)
 		panic(plainError("send on closed channel"))
 	}
@@ -436,23 +446,24 @@ func empty(c *hchan) bool {
 
 // entry points for <- c from compiled code
 //go:nosplit
-func chanrecv1(c *hchan, elem unsafe.Pointer) {
-	chanrecv(c, elem, true)
+func chanrecv1(c *hchan, elem unsafe.Pointer, flags chanFlags) {
+	chanrecv(c, elem, true, flags)
 }
 
 //go:nosplit
 func chanrecv2(c *hchan, elem unsafe.Pointer) (received bool) {
-	_, received = chanrecv(c, elem, true)
+	_, received = chanrecv(c, elem, true, 0)
 	return
 }
 
 // chanrecv receives on channel c and writes the received data to ep.
 // ep may be nil, in which case received data is ignored.
+// flags are the facts the caller knows about c, whose checks are skipped.
 // If block == false and no elements are available, returns (false, false).
 // Otherwise, if c is closed, zeros *ep and returns (true, false).
 // Otherwise, fills in *ep with an element and returns (true, true).
 // A non-nil ep must point to the heap or the caller's stack.
-func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool) {
+func chanrecv(c *hchan, ep unsafe.Pointer, block bool, flags chanFlags) (selected, received bool) {
 	// raceenabled: don't need to check ep, as it is always on the stack
 	// or is new memory allocated by reflect.
 
@@ -460,7 +471,7 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 		print("chanrecv: chan=", c, "\n")
 	}
 
-	if c == nil {
+	if flags&chanNotNil == 0 && c == nil {
 		if !block {
 			return
 		}
@@ -508,7 +519,7 @@ func chanrecv(c *hchan, ep unsafe.Pointer, block bool) (selected, received bool)
 
 	lock(&c.lock)
 
-	if c.closed != 0 && c.qcount == 0 {
+	if flags&chanNotClosed == 0 && c.closed != 0 && c.qcount == 0 {
 		if raceenabled {
 			raceacquire(c.raceaddr())
 		}
@@ -685,7 +696,7 @@ func chanparkcommit(gp *g, chanLock unsafe.Pointer) bool {
 //	}
 //
 func selectnbsend(c *hchan, elem unsafe.Pointer) (selected bool) {
-	return chansend(c, elem, false, getcallerpc())
+	return chansend(c, elem, false, 0, getcallerpc())
 }
 
 // compiler implements
@@ -706,17 +717,17 @@ func selectnbsend(c *hchan, elem unsafe.Pointer) (selected bool) {
 //	}
 //
 func selectnbrecv(elem unsafe.Pointer, c *hchan) (selected, received bool) {
-	return chanrecv(c, elem, false)
+	return chanrecv(c, elem, false, 0)
 }
 
 //go:linkname reflect_chansend reflect.chansend
 func reflect_chansend(c *hchan, elem unsafe.Pointer, nb bool) (selected bool) {
-	return chansend(c, elem, !nb, getcallerpc())
+	return chansend(c, elem, !nb, 0, getcallerpc())
 }
 
 //go:linkname reflect_chanrecv reflect.chanrecv
 func reflect_chanrecv(c *hchan, nb bool, elem unsafe.Pointer) (selected bool, received bool) {
-	return chanrecv(c, elem, !nb)
+	return chanrecv(c, elem, !nb, 0)
 }
 
 //go:linkname reflect_chanlen reflect.chanlen
@@ -27,8 +27,29 @@ var (
 	x86HasPOPCNT bool
 	x86HasSSE41  bool
 	x86HasFMA    bool
+	x86Level     uint8 // highest GOAMD64 level supported, see x86level
 
 	armHasVFPv4 bool
 
 	arm64HasATOMICS bool
 )
+
+// x86level returns the highest GOAMD64 microarchitecture level
+// (1 through 4) whose features the CPU supports. Functions marked
+// //go:cpu compare it against the levels they were compiled for.
+func x86level() uint8 {
+	x := &cpu.X86
+	if !(x.HasCX16 && x.HasLAHF && x.HasPOPCNT && x.HasSSE3 &&
+		x.HasSSSE3 && x.HasSSE41 && x.HasSSE42) {
+		return 1
+	}
+	if !(x.HasAVX && x.HasAVX2 && x.HasBMI1 && x.HasBMI2 && x.HasF16C &&
+		x.HasFMA && x.HasLZCNT && x.HasMOVBE && x.HasOSXSAVE) {
+		return 2
+	}
+	if !(x.HasAVX512F && x.HasAVX512BW && x.HasAVX512CD &&
+		x.HasAVX512DQ && x.HasAVX512VL) {
+		return 3
+	}
+	return 4
+}
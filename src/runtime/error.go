@@ -118,6 +118,9 @@ type boundsError struct {
 	// y is known to be nonnegative and to fit in an int.
 	signed bool
 	code   boundsErrorCode
+	// what is the compiler's description of the failing expression,
+	// such as "in s.buf[i + 1]", or "" if unknown.
+	what string
 }
 
 type boundsErrorCode uint8
@@ -200,6 +203,10 @@ func (e boundsError) Error() string {
 			b = appendIntStr(b, int64(e.y), true)
 		}
 	}
+	if e.what != "" {
+		b = append(b, ' ')
+		b = append(b, e.what...)
+	}
 	return string(b)
 }
 
@@ -520,6 +520,14 @@ func Getg() *G {
 	return getg()
 }
 
+func GetGLS() unsafe.Pointer {
+	return getgls()
+}
+
+func SetGLS(p unsafe.Pointer) {
+	setgls(p)
+}
+
 //go:noinline
 func PanicForTesting(b []byte, i int) byte {
 	return unexportedPanicForTesting(b, i)
@@ -12,6 +12,7 @@
 #define PCDATA_StackMapIndex 1
 #define PCDATA_InlTreeIndex 2
 #define PCDATA_ArgLiveIndex 3
+#define PCDATA_PanicInfoIndex 4
 
 #define FUNCDATA_ArgsPointerMaps 0 /* garbage collector blocks */
 #define FUNCDATA_LocalsPointerMaps 1
@@ -20,6 +21,7 @@
 #define FUNCDATA_OpenCodedDeferInfo 4 /* info for func with open-coded defers */
 #define FUNCDATA_ArgInfo 5
 #define FUNCDATA_ArgLiveInfo 6
+#define FUNCDATA_PanicInfo 7 /* descriptions of panic sites */
 
 // Pseudo-assembly statements.
 
@@ -0,0 +1,59 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+import (
+	"internal/goarch"
+	"unsafe"
+)
+
+// Goroutine-local storage.
+//
+// Each goroutine has a single pointer-sized slot, g.gls, for the
+// runtime and the packages closely tied to it, such as sync, to keep
+// per-goroutine state in without a map keyed by goroutine. It is nil
+// when a goroutine starts, and is cleared when it exits. Nothing else
+// may use it, and the users must agree on what it points to.
+//
+// getgls and setgls are compiler intrinsics, which load and store the
+// slot at its fixed offset from the g register directly. The compiler
+// knows that offset, glsOffset, so the slot must not move in g.
+
+// glsOffset is the offset of g.gls. Keep in sync with
+// cmd/compile/internal/ssagen.glsOffset.
+const glsOffset = 7 * goarch.PtrSize
+
+// checkgls checks that glsOffset is right.
+func checkgls() {
+	if unsafe.Offsetof(g{}.gls) != glsOffset {
+		throw("bad offsetof g.gls")
+	}
+}
+
+// getgls returns the goroutine-local storage of the current goroutine.
+//
+//go:nosplit
+func getgls() unsafe.Pointer {
+	return getg().gls
+}
+
+// setgls sets the goroutine-local storage of the current goroutine to p.
+//
+//go:nosplit
+func setgls(p unsafe.Pointer) {
+	getg().gls = p
+}
+
+//go:linkname sync_runtime_getgls sync.runtime_getgls
+//go:nosplit
+func sync_runtime_getgls() unsafe.Pointer {
+	return getgls()
+}
+
+//go:linkname sync_runtime_setgls sync.runtime_setgls
+//go:nosplit
+func sync_runtime_setgls(p unsafe.Pointer) {
+	setgls(p)
+}
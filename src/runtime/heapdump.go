@@ -272,8 +272,13 @@ func dumpframe(s *stkframe, arg unsafe.Pointer) bool {
 	stkmap := (*stackmap)(funcdata(f, _FUNCDATA_LocalsPointerMaps))
 
 	var bv bitvector
+	var buf stackmapBuf
 	if stkmap != nil && stkmap.n > 0 {
-		bv = stackmapdata(stkmap, pcdata)
+		if stkmap.nbit&_StackMapCompressed != 0 {
+			bv = stackmapdecode(stkmap, pcdata, &buf)
+		} else {
+			bv = stackmapdata(stkmap, pcdata)
+		}
 	} else {
 		bv.n = -1
 	}
@@ -299,6 +299,7 @@ func TestZeroConvT2x(t *testing.T) {
 		{name: "E32", fn: func() { e = zero32 }},
 		{name: "E64", fn: func() { e = zero64 }},
 		{name: "Estr", fn: func() { e = zerostr }},
+		{name: "Estr1", fn: func() { e = onestr }}, // single-byte strings do not allocate
 		{name: "Eslice", fn: func() { e = zeroslice }},
 		{name: "Econstflt", fn: func() { e = 99.0 }}, // constants do not allocate
 		{name: "Econststr", fn: func() { e = "change" }},
@@ -342,6 +343,7 @@ var (
 
 	zerostr  string = ""
 	zerostrI Tstr   = ""
+	onestr   string = "a"
 	nzstr    string = "abc"
 
 	zeroslice  []byte = nil
@@ -354,3 +354,37 @@ func TestStorepNoWB(t *testing.T) {
 		t.Error("Bad escape analysis of StorepNoWB")
 	}
 }
+
+func TestRelaxed(t *testing.T) {
+	var x uint32
+	atomic.StoreRelaxed(&x, 1)
+	atomic.StoreRelaxed(&x, 2)
+	if v := atomic.LoadRelaxed(&x); v != 2 {
+		t.Errorf("LoadRelaxed after two StoreRelaxed: want 2, got %d", v)
+	}
+	var y uintptr
+	atomic.StoreRelaxeduintptr(&y, 3)
+	if v := atomic.LoadRelaxeduintptr(&y); v != 3 {
+		t.Errorf("LoadRelaxeduintptr: want 3, got %d", v)
+	}
+
+	// Relaxed operations must still not tear values.
+	var z uint64
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100000; i++ {
+			atomic.StoreRelaxed64(&z, -uint64(i&1))
+		}
+		done <- true
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if v := atomic.LoadRelaxed64(&z); v != 0 && v != ^uint64(0) {
+			t.Fatalf("LoadRelaxed64 observed torn value %#x", v)
+		}
+	}
+}
@@ -14,5 +14,15 @@ Unless otherwise noted, operations defined in this package are sequentially
 consistent across threads with respect to the values they manipulate. More
 specifically, operations that happen in a specific order on one thread,
 will always be observed to happen in exactly that order by another thread.
+
+The relaxed loads and stores (LoadRelaxed, StoreRelaxed and their variants)
+are the exception: they are atomic with respect to the value they access,
+so a load never observes a torn value, but they don't order any other memory
+accesses, and the compiler may coalesce consecutive ones. They may only be
+used where no other memory access depends on their order, such as for
+statistics counters, hints and heuristics read without synchronization, or
+fields whose readers and writers are already ordered by other operations. Code
+outside the runtime must not use them; sync may only reach them by linkname
+under the same rules.
 */
 package atomic
@@ -0,0 +1,50 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atomic
+
+// The relaxed loads and stores are atomic with respect to the value
+// they access, but unlike the other operations of this package, they
+// don't order any other memory accesses. See the package documentation
+// for when they may be used.
+//
+// They are intrinsics on most platforms. The memory models of amd64
+// and s390x only let the hardware reorder a store with a later load,
+// so there they compile to plain loads and stores without the fence
+// that Store needs to prevent that, and the compiler may coalesce
+// them: a relaxed store overwritten by the next one is dropped, and a
+// relaxed load of a value just stored with a relaxed store uses that
+// value.
+// Elsewhere they are the same as Load and Store, and these bodies are
+// only used where those aren't intrinsics either.
+
+//go:nosplit
+func LoadRelaxed(ptr *uint32) uint32 {
+	return Load(ptr)
+}
+
+//go:nosplit
+func LoadRelaxed64(ptr *uint64) uint64 {
+	return Load64(ptr)
+}
+
+//go:nosplit
+func LoadRelaxeduintptr(ptr *uintptr) uintptr {
+	return Loaduintptr(ptr)
+}
+
+//go:nosplit
+func StoreRelaxed(ptr *uint32, val uint32) {
+	Store(ptr, val)
+}
+
+//go:nosplit
+func StoreRelaxed64(ptr *uint64, val uint64) {
+	Store64(ptr, val)
+}
+
+//go:nosplit
+func StoreRelaxeduintptr(ptr *uintptr, val uintptr) {
+	Storeuintptr(ptr, val)
+}
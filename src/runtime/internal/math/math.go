@@ -38,3 +38,36 @@ func Mul64(x, y uint64) (hi, lo uint64) {
 	lo = x * y
 	return
 }
+
+// AddInt64 returns x + y and whether the addition overflowed.
+// On supported platforms this is an intrinsic lowered by the compiler.
+func AddInt64(x, y int64) (int64, bool) {
+	sum := x + y
+	return sum, (x^sum)&(y^sum) < 0
+}
+
+// SubInt64 returns x - y and whether the subtraction overflowed.
+// On supported platforms this is an intrinsic lowered by the compiler.
+func SubInt64(x, y int64) (int64, bool) {
+	diff := x - y
+	return diff, (x^y)&(x^diff) < 0
+}
+
+// AddSat64 returns x + y, saturated to the maximum uint64 on overflow.
+// On supported platforms this is an intrinsic lowered by the compiler.
+func AddSat64(x, y uint64) uint64 {
+	sum := x + y
+	if sum < x {
+		return 1<<64 - 1
+	}
+	return sum
+}
+
+// SubSat64 returns x - y, saturated to zero on underflow.
+// On supported platforms this is an intrinsic lowered by the compiler.
+func SubSat64(x, y uint64) uint64 {
+	if y > x {
+		return 0
+	}
+	return x - y
+}
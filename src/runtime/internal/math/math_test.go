@@ -50,6 +50,80 @@ func TestMulUintptr(t *testing.T) {
 	}
 }
 
+func TestAddSubInt64(t *testing.T) {
+	const (
+		maxInt64 = 1<<63 - 1
+		minInt64 = -1 << 63
+	)
+	tests := []struct {
+		x, y     int64
+		overflow bool
+	}{
+		{0, 0, false},
+		{1, -1, false},
+		{maxInt64, 0, false},
+		{maxInt64, 1, true},
+		{maxInt64, maxInt64, true},
+		{minInt64, 0, false},
+		{minInt64, -1, true},
+		{minInt64, minInt64, true},
+		{minInt64, maxInt64, false},
+	}
+	for _, test := range tests {
+		x, y := test.x, test.y
+		for i := 0; i < 2; i++ {
+			sum, overflow := AddInt64(x, y)
+			if sum != x+y || overflow != test.overflow {
+				t.Errorf("AddInt64(%v, %v) = %v, %v want %v, %v",
+					x, y, sum, overflow, x+y, test.overflow)
+			}
+			x, y = y, x
+		}
+		// x - (-y) overflows exactly when x + y does, unless -y does.
+		if y != minInt64 {
+			diff, overflow := SubInt64(x, -y)
+			if diff != x+y || overflow != test.overflow {
+				t.Errorf("SubInt64(%v, %v) = %v, %v want %v, %v",
+					x, -y, diff, overflow, x+y, test.overflow)
+			}
+		}
+	}
+	if diff, overflow := SubInt64(0, minInt64); diff != minInt64 || !overflow {
+		t.Errorf("SubInt64(0, %v) = %v, %v want %v, true", int64(minInt64), diff, overflow, int64(minInt64))
+	}
+	if diff, overflow := SubInt64(-1, minInt64); diff != maxInt64 || overflow {
+		t.Errorf("SubInt64(-1, %v) = %v, %v want %v, false", int64(minInt64), diff, overflow, int64(maxInt64))
+	}
+}
+
+func TestAddSubSat64(t *testing.T) {
+	const maxUint64 = 1<<64 - 1
+	tests := []struct {
+		x, y, sum, diff uint64
+	}{
+		{0, 0, 0, 0},
+		{1, 2, 3, 0},
+		{2, 1, 3, 1},
+		{maxUint64, 0, maxUint64, maxUint64},
+		{maxUint64, 1, maxUint64, maxUint64 - 1},
+		{maxUint64 - 1, 1, maxUint64, maxUint64 - 2},
+		{maxUint64, maxUint64, maxUint64, 0},
+		{1 << 63, 1 << 63, maxUint64, 0},
+		{0, maxUint64, maxUint64, 0},
+	}
+	for _, test := range tests {
+		if sum := AddSat64(test.x, test.y); sum != test.sum {
+			t.Errorf("AddSat64(%v, %v) = %v want %v", test.x, test.y, sum, test.sum)
+		}
+		if sum := AddSat64(test.y, test.x); sum != test.sum {
+			t.Errorf("AddSat64(%v, %v) = %v want %v", test.y, test.x, sum, test.sum)
+		}
+		if diff := SubSat64(test.x, test.y); diff != test.diff {
+			t.Errorf("SubSat64(%v, %v) = %v want %v", test.x, test.y, diff, test.diff)
+		}
+	}
+}
+
 var SinkUintptr uintptr
 var SinkBool bool
 
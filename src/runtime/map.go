@@ -987,6 +987,17 @@ next:
 	goto next
 }
 
+// mapinitbulk adds the n keys in the array at keys, each with the elem
+// at the same index in the array at elems, to h. The compiler calls it
+// to fill in a large map literal from static data.
+func mapinitbulk(t *maptype, h *hmap, keys, elems unsafe.Pointer, n int) {
+	for i := 0; i < n; i++ {
+		k := add(keys, uintptr(i)*t.key.size)
+		elem := mapassign(t, h, k)
+		typedmemmove(t.elem, elem, add(elems, uintptr(i)*t.elem.size))
+	}
+}
+
 // mapclear deletes all keys from a map.
 func mapclear(t *maptype, h *hmap) {
 	if raceenabled && h != nil {
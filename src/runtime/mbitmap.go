@@ -2021,7 +2021,8 @@ func getgcmask(ep interface{}) (mask []byte) {
 		_g_ := getg()
 		gentraceback(_g_.m.curg.sched.pc, _g_.m.curg.sched.sp, 0, _g_.m.curg, 0, nil, 1000, getgcmaskcb, noescape(unsafe.Pointer(&frame)), 0)
 		if frame.fn.valid() {
-			locals, _, _ := getStackMap(&frame, nil, false)
+			var buf stackmapBuf
+			locals, _, _ := getStackMap(&frame, nil, &buf, false)
 			if locals.n == 0 {
 				return
 			}
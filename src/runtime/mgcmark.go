@@ -942,7 +942,7 @@ func scanframeworker(frame *stkframe, state *stackScanState, gcw *gcWork) {
 		return
 	}
 
-	locals, args, objs := getStackMap(frame, &state.cache, false)
+	locals, args, objs := getStackMap(frame, &state.cache, &state.stackmapBuf, false)
 
 	// Scan local variables if stack frame has been allocated.
 	if locals.n > 0 {
@@ -170,6 +170,10 @@ func (obj *stackObject) setRecord(r *stackObjectRecord) {
 type stackScanState struct {
 	cache pcvalueCache
 
+	// stackmapBuf holds the locals bitmap of the frame being
+	// scanned if it is compressed.
+	stackmapBuf stackmapBuf
+
 	// stack limits
 	stack stack
 
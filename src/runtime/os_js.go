@@ -56,7 +56,7 @@ func sigpanic() {
 
 	// js only invokes the exception handler for memory faults.
 	g.sig = _SIGSEGV
-	panicmem()
+	panicmem(0)
 }
 
 type sigset struct{}
@@ -89,12 +89,12 @@ func sigpanic() {
 		} else if i = indexNoFloat(note, "va="); i >= 0 {
 			i += 3
 		} else {
-			panicmem()
+			panicmem(g.sigpc)
 		}
 		addr := note[i:]
 		g.sigcode1 = uintptr(atolwhex(addr))
 		if g.sigcode1 < 0x1000 {
-			panicmem()
+			panicmem(g.sigpc)
 		}
 		if g.paniconfault {
 			panicmemAddr(g.sigcode1)
@@ -103,7 +103,7 @@ func sigpanic() {
 		throw("fault")
 	case _SIGTRAP:
 		if g.paniconfault {
-			panicmem()
+			panicmem(g.sigpc)
 		}
 		throw(note)
 	case _SIGINTDIV:
@@ -86,83 +86,83 @@ func panicCheck2(err string) {
 // failures in the comparisons for s[x], 0 <= x < y (y == len(s))
 func goPanicIndex(x int, y int) {
 	panicCheck1(getcallerpc(), "index out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsIndex})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsIndex, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicIndexU(x uint, y int) {
 	panicCheck1(getcallerpc(), "index out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsIndex})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsIndex, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[:x], 0 <= x <= y (y == len(s) or cap(s))
 func goPanicSliceAlen(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSliceAlen})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSliceAlen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSliceAlenU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSliceAlen})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSliceAlen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSliceAcap(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSliceAcap})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSliceAcap, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSliceAcapU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSliceAcap})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSliceAcap, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[x:y], 0 <= x <= y
 func goPanicSliceB(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSliceB})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSliceB, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSliceBU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSliceB})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSliceB, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[::x], 0 <= x <= y (y == len(s) or cap(s))
 func goPanicSlice3Alen(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3Alen})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3Alen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSlice3AlenU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3Alen})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3Alen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSlice3Acap(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3Acap})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3Acap, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSlice3AcapU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3Acap})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3Acap, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[:x:y], 0 <= x <= y
 func goPanicSlice3B(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3B})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3B, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSlice3BU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3B})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3B, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[x:y:], 0 <= x <= y
 func goPanicSlice3C(x int, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3C})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsSlice3C, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicSlice3CU(x uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3C})
+	panic(boundsError{x: int64(x), signed: false, y: y, code: boundsSlice3C, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the conversion (*[x]T)s, 0 <= x <= y, x == cap(s)
 func goPanicSliceConvert(x int, y int) {
 	panicCheck1(getcallerpc(), "slice length too short to convert to pointer to array")
-	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsConvert})
+	panic(boundsError{x: int64(x), signed: true, y: y, code: boundsConvert, what: panicInfo(getcallerpc() - 1)})
 }
 
 // Implemented in assembly, as they take arguments in registers.
@@ -215,11 +215,41 @@ func panicfloat() {
 
 var memoryError = error(errorString("invalid memory address or nil pointer dereference"))
 
-func panicmem() {
+// panicmem panics for a nil pointer dereference. pc is the PC of
+// the faulting instruction, used to describe the dereference, or 0
+// if it is not known.
+func panicmem(pc uintptr) {
 	panicCheck2("invalid memory address or nil pointer dereference")
+	if what := panicInfo(pc); what != "" {
+		panic(errorString("invalid memory address or nil pointer dereference " + what))
+	}
 	panic(memoryError)
 }
 
+// panicInfo returns the compiler's description of the panic site at pc,
+// such as "reading field c.mu" for an implicit nil check or "in s[i]"
+// for a bounds check, or "" if there is none.
+func panicInfo(pc uintptr) string {
+	f := findfunc(pc)
+	if !f.valid() {
+		return ""
+	}
+	off := pcdatavalue(f, _PCDATA_PanicInfoIndex, pc, nil)
+	if off < 0 {
+		return ""
+	}
+	p := funcdata(f, _FUNCDATA_PanicInfo)
+	if p == nil {
+		return ""
+	}
+	// See cmd/compile/internal/ssagen.(*ssafn).panicInfoIndex.
+	var s string
+	ss := stringStructOf(&s)
+	ss.str = add(p, uintptr(off)+1)
+	ss.len = int(*(*uint8)(add(p, uintptr(off))))
+	return s
+}
+
 func panicmemAddr(addr uintptr) {
 	panicCheck2("invalid memory address or nil pointer dereference")
 	panic(errorAddressString{msg: "invalid memory address or nil pointer dereference", addr: addr})
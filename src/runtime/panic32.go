@@ -12,77 +12,77 @@ package runtime
 // failures in the comparisons for s[x], 0 <= x < y (y == len(s))
 func goPanicExtendIndex(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "index out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsIndex})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsIndex, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendIndexU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "index out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsIndex})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsIndex, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[:x], 0 <= x <= y (y == len(s) or cap(s))
 func goPanicExtendSliceAlen(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSliceAlen})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSliceAlen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSliceAlenU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSliceAlen})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSliceAlen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSliceAcap(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSliceAcap})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSliceAcap, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSliceAcapU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSliceAcap})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSliceAcap, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[x:y], 0 <= x <= y
 func goPanicExtendSliceB(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSliceB})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSliceB, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSliceBU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSliceB})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSliceB, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[::x], 0 <= x <= y (y == len(s) or cap(s))
 func goPanicExtendSlice3Alen(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3Alen})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3Alen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSlice3AlenU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3Alen})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3Alen, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSlice3Acap(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3Acap})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3Acap, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSlice3AcapU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3Acap})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3Acap, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[:x:y], 0 <= x <= y
 func goPanicExtendSlice3B(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3B})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3B, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSlice3BU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3B})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3B, what: panicInfo(getcallerpc() - 1)})
 }
 
 // failures in the comparisons for s[x:y:], 0 <= x <= y
 func goPanicExtendSlice3C(hi int, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3C})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: true, y: y, code: boundsSlice3C, what: panicInfo(getcallerpc() - 1)})
 }
 func goPanicExtendSlice3CU(hi uint, lo uint, y int) {
 	panicCheck1(getcallerpc(), "slice bounds out of range")
-	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3C})
+	panic(boundsError{x: int64(hi)<<32 + int64(lo), signed: false, y: y, code: boundsSlice3C, what: panicInfo(getcallerpc() - 1)})
 }
 
 // Implemented in assembly, as they take arguments in registers.
@@ -641,6 +641,7 @@ func cpuinit() {
 		x86HasPOPCNT = cpu.X86.HasPOPCNT
 		x86HasSSE41 = cpu.X86.HasSSE41
 		x86HasFMA = cpu.X86.HasFMA
+		x86Level = x86level()
 
 	case "arm":
 		armHasVFPv4 = cpu.ARM.HasVFPv4
@@ -3660,6 +3661,7 @@ func goexit0(gp *g) {
 	gp.waitreason = 0
 	gp.param = nil
 	gp.labels = nil
+	gp.gls = nil
 	gp.timer = nil
 
 	if gcBlackenEnabled != 0 && gp.gcAssistBytes > 0 {
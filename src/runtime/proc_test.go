@@ -18,6 +18,7 @@ import (
 	"syscall"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 var stop = make(chan bool, 1)
@@ -1137,3 +1138,29 @@ func TestBigGOMAXPROCS(t *testing.T) {
 		t.Errorf("output:\n%s\nwanted:\nunknown function: NonexistentTest", output)
 	}
 }
+
+func TestGLS(t *testing.T) {
+	x, y := new(int), new(int)
+	runtime.SetGLS(unsafe.Pointer(x))
+	defer runtime.SetGLS(nil)
+
+	// New goroutines, including ones reusing the g of a goroutine that
+	// set its storage, start with none.
+	for i := 0; i < 100; i++ {
+		done := make(chan unsafe.Pointer)
+		go func() {
+			p := runtime.GetGLS()
+			runtime.SetGLS(unsafe.Pointer(y))
+			if runtime.GetGLS() != unsafe.Pointer(y) {
+				p = unsafe.Pointer(x)
+			}
+			done <- p
+		}()
+		if p := <-done; p != nil {
+			t.Fatalf("goroutine-local storage of new goroutine is %p, want nil", p)
+		}
+	}
+	if p := runtime.GetGLS(); p != unsafe.Pointer(x) {
+		t.Fatalf("goroutine-local storage is %p, want %p", p, x)
+	}
+}
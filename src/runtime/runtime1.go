@@ -205,6 +205,7 @@ func check() {
 	if unsafe.Sizeof(y1) != 2 {
 		throw("bad unsafe.Sizeof y1")
 	}
+	checkgls()
 
 	if timediv(12345*1000000000+54321, 1000000000, &e) != 12345 || e != 54321 {
 		throw("bad timediv")
@@ -412,9 +412,10 @@ type g struct {
 	stackguard0 uintptr // offset known to liblink
 	stackguard1 uintptr // offset known to liblink
 
-	_panic    *_panic // innermost panic - offset known to liblink
-	_defer    *_defer // innermost defer
-	m         *m      // current m; offset known to arm liblink
+	_panic    *_panic        // innermost panic - offset known to liblink
+	_defer    *_defer        // innermost defer
+	m         *m             // current m; offset known to arm liblink
+	gls       unsafe.Pointer // goroutine-local storage; offset known to the compiler, see gls.go
 	sched     gobuf
 	syscallsp uintptr // if status==Gsyscall, syscallsp = sched.sp to use during gc
 	syscallpc uintptr // if status==Gsyscall, syscallpc = sched.pc to use during gc
@@ -784,7 +784,7 @@ func sigpanic() {
 	switch g.sig {
 	case _SIGBUS:
 		if g.sigcode0 == _BUS_ADRERR && g.sigcode1 < 0x1000 {
-			panicmem()
+			panicmem(g.sigpc)
 		}
 		// Support runtime/debug.SetPanicOnFault.
 		if g.paniconfault {
@@ -794,7 +794,7 @@ func sigpanic() {
 		throw("fault")
 	case _SIGSEGV:
 		if (g.sigcode0 == 0 || g.sigcode0 == _SEGV_MAPERR || g.sigcode0 == _SEGV_ACCERR) && g.sigcode1 < 0x1000 {
-			panicmem()
+			panicmem(g.sigpc)
 		}
 		// Support runtime/debug.SetPanicOnFault.
 		if g.paniconfault {
@@ -252,7 +252,7 @@ func sigpanic() {
 	switch g.sig {
 	case _EXCEPTION_ACCESS_VIOLATION:
 		if g.sigcode1 < 0x1000 {
-			panicmem()
+			panicmem(g.sigpc)
 		}
 		if g.paniconfault {
 			panicmemAddr(g.sigcode1)
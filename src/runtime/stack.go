@@ -558,6 +558,10 @@ type adjustinfo struct {
 	delta uintptr // ptr distance from old to new stack (newbase - oldbase)
 	cache pcvalueCache
 
+	// stackmapBuf holds the locals bitmap of the frame being
+	// adjusted if it is compressed.
+	stackmapBuf stackmapBuf
+
 	// sghi is the highest sudog.elem on the stack.
 	sghi uintptr
 }
@@ -662,7 +666,7 @@ func adjustframe(frame *stkframe, arg unsafe.Pointer) bool {
 		return true
 	}
 
-	locals, args, objs := getStackMap(frame, &adjinfo.cache, true)
+	locals, args, objs := getStackMap(frame, &adjinfo.cache, &adjinfo.stackmapBuf, true)
 
 	// Adjust local variables if stack frame has been allocated.
 	if locals.n > 0 {
@@ -1246,8 +1250,9 @@ func freeStackSpans() {
 }
 
 // getStackMap returns the locals and arguments live pointer maps, and
-// stack object list for frame.
-func getStackMap(frame *stkframe, cache *pcvalueCache, debug bool) (locals, args bitvector, objs []stackObjectRecord) {
+// stack object list for frame. A compressed locals map is decoded
+// into buf.
+func getStackMap(frame *stkframe, cache *pcvalueCache, buf *stackmapBuf, debug bool) (locals, args bitvector, objs []stackObjectRecord) {
 	targetpc := frame.continpc
 	if targetpc == 0 {
 		// Frame is dead. Return empty bitvectors.
@@ -1294,7 +1299,11 @@ func getStackMap(frame *stkframe, cache *pcvalueCache, debug bool) (locals, args
 				print("runtime: pcdata is ", stackid, " and ", stkmap.n, " locals stack map entries for ", funcname(f), " (targetpc=", hex(targetpc), ")\n")
 				throw("bad symbol table")
 			}
-			locals = stackmapdata(stkmap, stackid)
+			if stkmap.nbit&_StackMapCompressed != 0 {
+				locals = stackmapdecode(stkmap, stackid, buf)
+			} else {
+				locals = stackmapdata(stkmap, stackid)
+			}
 			if stackDebug >= 3 && debug {
 				print("      locals ", stackid, "/", stkmap.n, " ", locals.n, " words ", locals.bytedata, "\n")
 			}
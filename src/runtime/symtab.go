@@ -298,10 +298,11 @@ func (f *_func) funcInfo() funcInfo {
 //
 // See funcdata.h and ../cmd/internal/objabi/funcdata.go.
 const (
-	_PCDATA_UnsafePoint   = 0
-	_PCDATA_StackMapIndex = 1
-	_PCDATA_InlTreeIndex  = 2
-	_PCDATA_ArgLiveIndex  = 3
+	_PCDATA_UnsafePoint    = 0
+	_PCDATA_StackMapIndex  = 1
+	_PCDATA_InlTreeIndex   = 2
+	_PCDATA_ArgLiveIndex   = 3
+	_PCDATA_PanicInfoIndex = 4
 
 	_FUNCDATA_ArgsPointerMaps    = 0
 	_FUNCDATA_LocalsPointerMaps  = 1
@@ -310,10 +311,20 @@ const (
 	_FUNCDATA_OpenCodedDeferInfo = 4
 	_FUNCDATA_ArgInfo            = 5
 	_FUNCDATA_ArgLiveInfo        = 6
+	_FUNCDATA_PanicInfo          = 7
 
 	_ArgsSizeUnknown = -0x80000000
 )
 
+// Stack map encoding.
+//
+// See ../cmd/internal/objabi/funcdata.go.
+const (
+	_StackMapCompressed        = 1 << 30
+	_StackMapBlock             = 16
+	_StackMapMaxCompressedBits = 2048
+)
+
 const (
 	// PCDATA_UnsafePoint values.
 	_PCDATA_UnsafePointSafe   = -1 // Safe for async preemption
@@ -1150,12 +1161,25 @@ func readvarint(p []byte) (read uint32, val uint32) {
 	return n, v
 }
 
+// A stackmap holds the pointer bitmaps of a function's arguments or
+// locals, one for each stack map index.
+//
+// If nbit has the _StackMapCompressed bit set, the compiler found it
+// smaller to delta-encode the bitmaps. Then bytedata starts with a
+// uint32 offset for each block of _StackMapBlock bitmaps, relative to
+// the end of the offsets. A block starts with its first bitmap as is.
+// Each other bitmap in it is a uvarint count of the bits that differ
+// from the bitmap before it, followed by a uvarint for each of those
+// bits, which is its index minus that of the bit before it minus one.
 type stackmap struct {
 	n        int32   // number of bitmaps
 	nbit     int32   // number of bits in each bitmap
 	bytedata [1]byte // bitmaps, each starting on a byte boundary
 }
 
+// A stackmapBuf holds a bitmap decoded from a compressed stackmap.
+type stackmapBuf [_StackMapMaxCompressedBits / 8]uint8
+
 //go:nowritebarrier
 func stackmapdata(stkmap *stackmap, n int32) bitvector {
 	// Check this invariant only when stackDebug is on at all.
@@ -1167,13 +1191,49 @@ func stackmapdata(stkmap *stackmap, n int32) bitvector {
 	return bitvector{stkmap.nbit, addb(&stkmap.bytedata[0], uintptr(n*((stkmap.nbit+7)>>3)))}
 }
 
+// stackmapdecode is like stackmapdata for a compressed stkmap. It
+// decodes the bitmap into buf, so it is only valid until buf is used
+// again.
+//
+//go:nowritebarrier
+func stackmapdecode(stkmap *stackmap, n int32, buf *stackmapBuf) bitvector {
+	nbit := stkmap.nbit &^ _StackMapCompressed
+	if nbit > _StackMapMaxCompressedBits {
+		throw("stackmapdecode: bitmap too large")
+	}
+	nbyte := uintptr((nbit + 7) >> 3)
+	nblock := (stkmap.n + _StackMapBlock - 1) / _StackMapBlock
+	block := n / _StackMapBlock
+	offsets := unsafe.Pointer(&stkmap.bytedata[0])
+	p := add(offsets, uintptr(nblock)*4+uintptr(*(*uint32)(add(offsets, uintptr(block)*4))))
+	bits := buf[:nbyte]
+	memmove(unsafe.Pointer(&bits[0]), p, nbyte)
+	p = add(p, nbyte)
+	for i := block*_StackMapBlock + 1; i <= n; i++ {
+		var count uint32
+		count, p = readvarintUnsafe(p)
+		bit := ^uint32(0)
+		for ; count > 0; count-- {
+			var delta uint32
+			delta, p = readvarintUnsafe(p)
+			bit += delta + 1
+			bits[bit/8] ^= 1 << (bit % 8)
+		}
+	}
+	// Hide the result's reference to buf from escape analysis. The
+	// callers only use the bitmap while buf is live in their frame,
+	// but on some platforms, such as plan9, getStackMap and this
+	// function are analyzed as one recursive cycle, in which buf
+	// flowing to getStackMap's results is taken to escape.
+	return bitvector{nbit, (*uint8)(noescape(unsafe.Pointer(&buf[0])))}
+}
+
 // inlinedCall is the encoding of entries in the FUNCDATA_InlTree table.
+// Tracebacks find the position of the call site from parentPc, so the
+// entries need not record it. See cmd/link/internal/ld/pcln.go:genInlTreeSym.
 type inlinedCall struct {
-	parent   int16  // index of parent in the inltree, or < 0
 	funcID   funcID // type of the called function
-	_        byte
-	file     int32 // perCU file index for inlined call. See cmd/link:pcln.go
-	line     int32 // line number of the call site
+	_        [3]byte
 	func_    int32 // offset into pclntab for name of called function
 	parentPc int32 // position of an instruction whose source position is the call site (offset from entry)
 }
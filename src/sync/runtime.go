@@ -55,3 +55,9 @@ func runtime_canSpin(i int) bool
 func runtime_doSpin()
 
 func runtime_nanotime() int64
+
+// runtime_getgls and runtime_setgls get and set the goroutine-local
+// storage slot of the current goroutine. See runtime/gls.go.
+// The compiler knows to intrinsify both.
+func runtime_getgls() unsafe.Pointer
+func runtime_setgls(p unsafe.Pointer)
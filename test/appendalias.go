@@ -0,0 +1,76 @@
+// errorcheck -0 -d=appendalias
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the diagnostics for appends that may overwrite elements added
+// by another append to the same slice, and for surprising copies.
+
+package p
+
+func pair(a []int) ([]int, []int) {
+	b := append(a, 1)
+	c := append(a, 2) // ERROR "append to a may overwrite the elements of b, appended to a at"
+	return b, c
+}
+
+func reassigned(a []int) []int {
+	b := append(a, 1)
+	a = b[:1]
+	c := append(a, 2)
+	return c
+}
+
+func self(a []int) []int {
+	a = append(a, 1)
+	a = append(a, 2)
+	return a
+}
+
+func literal() ([]int, []int) {
+	a := []int{1, 2}
+	b := append(a, 3)
+	c := append(a, 4)
+	return b, c
+}
+
+func loop(prefix []int, xs []int) [][]int {
+	var all [][]int
+	for _, x := range xs {
+		all = append(all, append(prefix, x)) // ERROR "append to prefix in loop may reuse the same array in every iteration"
+	}
+	return all
+}
+
+func loopLocal(xs []int) [][]int {
+	var all [][]int
+	for _, x := range xs {
+		prefix := make([]int, 0, 4)
+		all = append(all, append(prefix, x))
+	}
+	return all
+}
+
+func loopSend(prefix []int, xs []int, c chan []int) {
+	for _, x := range xs {
+		c <- append(prefix, x) // ERROR "append to prefix in loop may reuse the same array in every iteration"
+	}
+}
+
+func copies(s []int, a [8]int) {
+	copy(s[1:], s)    // ERROR "copy shifts the elements of s right by 1"
+	copy(s[2:], s[:]) // ERROR "copy shifts the elements of s right by 2"
+	copy(s, s[1:])
+	copy(s, s) // ERROR "copy of s onto itself does nothing"
+	copy(a[4:], a[:4])
+	copy(a[3:], a[:4]) // ERROR "copy shifts the elements of a right by 3"
+}
+
+func closure(a []int) func() []int {
+	b := append(a, 1)
+	return func() []int {
+		c := append(a, 2)
+		return append(b, c...)
+	}
+}
@@ -0,0 +1,93 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that loops appending one element per iteration to an empty
+// local slice preallocate it, only when that cannot be observed.
+
+package p
+
+func squares(s []int) []int { // ERROR "s does not escape"
+	var r []int
+	for _, v := range s { // ERROR "append to r in loop preallocated with make" "make\(\[\]int, len\(s\)\) escapes to heap"
+		r = append(r, v*v)
+	}
+	return r
+}
+
+func indexes(a [8]string) []int { // ERROR "a does not escape"
+	r := []int{}       // ERROR "\[\]int{} escapes to heap"
+	for i := range a { // ERROR "append to r in loop preallocated with make" "make\(\[\]int, 8\) escapes to heap"
+		r = append(r, i)
+	}
+	return r
+}
+
+func made(s []string) []string { // ERROR "leaking param content: s"
+	r := make([]string, 0, 4) // ERROR "make\(\[\]string, 0, 4\) escapes to heap"
+	for i, v := range s {     // ERROR "append to r in loop preallocated with make" "make\(\[\]string, len\(s\)\) escapes to heap"
+		r = append(r, v[i:])
+	}
+	return r
+}
+
+func notEmpty(s []int) []int { // ERROR "s does not escape"
+	r := []int{0} // ERROR "\[\]int{...} escapes to heap"
+	for _, v := range s {
+		r = append(r, v)
+	}
+	return r
+}
+
+func notAdjacent(s []int) []int { // ERROR "s does not escape"
+	var r []int
+	r = append(r, 1)
+	for _, v := range s {
+		r = append(r, v)
+	}
+	return r
+}
+
+func usesLen(s []int) []int { // ERROR "s does not escape"
+	var r []int
+	for _, v := range s {
+		r = append(r, v+len(r))
+	}
+	return r
+}
+
+func twoPerIteration(s []int) []int { // ERROR "s does not escape"
+	var r []int
+	for _, v := range s {
+		r = append(r, v, v)
+	}
+	return r
+}
+
+func conditional(s []int) []int { // ERROR "s does not escape"
+	var r []int
+	for _, v := range s {
+		if v > 0 {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+func captured(s []int) func() []int { // ERROR "s does not escape"
+	var r []int                    // ERROR "moved to heap: r"
+	f := func() []int { return r } // ERROR "func literal escapes to heap"
+	for _, v := range s {
+		r = append(r, v)
+	}
+	return f
+}
+
+func result(s []int) (r []int) { // ERROR "s does not escape"
+	for _, v := range s {
+		r = append(r, v)
+	}
+	return
+}
@@ -0,0 +1,76 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that loops appending to a preallocated slice behave the same,
+// including when there is nothing to append.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//go:noinline
+func squares(s []int) []int {
+	var r []int
+	for _, v := range s {
+		r = append(r, v*v)
+	}
+	return r
+}
+
+//go:noinline
+func indexes(s []int) []int {
+	r := []int{}
+	for i := range s {
+		r = append(r, i)
+	}
+	return r
+}
+
+//go:noinline
+func exclaim(a [4]string) []string {
+	r := make([]string, 0, 10)
+	for _, v := range a {
+		r = append(r, v+"!")
+	}
+	return r
+}
+
+var calls int
+
+//go:noinline
+func get() []int {
+	calls++
+	return []int{1, 2, 3}
+}
+
+//go:noinline
+func sums() []int {
+	var r []int
+	for i, v := range get() {
+		r = append(r, i+v)
+	}
+	return r
+}
+
+func check(name string, got, want interface{}) {
+	if !reflect.DeepEqual(got, want) {
+		panic(fmt.Sprintf("%s = %#v, want %#v", name, got, want))
+	}
+}
+
+func main() {
+	check("squares(nil)", squares(nil), []int(nil))
+	check("squares([]int{})", squares([]int{}), []int(nil))
+	check("squares([]int{1, 2, 3})", squares([]int{1, 2, 3}), []int{1, 4, 9})
+	check("indexes(nil)", indexes(nil), []int{})
+	check("indexes([]int{5, 6})", indexes([]int{5, 6}), []int{0, 1})
+	check("exclaim", exclaim([4]string{"a", "b", "c", "d"}), []string{"a!", "b!", "c!", "d!"})
+	check("sums()", sums(), []int{1, 3, 5})
+	check("calls", calls, 1)
+}
@@ -0,0 +1,39 @@
+// errorcheck -0 -d=argliveness
+
+//go:build amd64
+// +build amd64
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=argliveness reports which register argument spill
+// slots tracebacks print as valid at calls. (This test relies on
+// the register ABI on amd64.)
+
+package p
+
+//go:noinline
+func sink(*int) {}
+
+//go:noinline
+func stack() int { return 0 }
+
+func f(a int64, b int32, c int16, d int8, x [2]int, y int) int {
+	if a < 0 {
+		sink(&y) // ERROR "args at call to sink: a b\? c d\? y$"
+	}
+	n := stack() // ERROR "args at call to stack: a b\? c d\? y$"
+	if a < 0 {
+		return int(a) + int(c)
+	}
+	return n
+}
+
+func g(a, b, c int32) int {
+	if a < 0 {
+		sink(nil) // ERROR "args at call to sink: a b c$"
+		return int(a + b + c)
+	}
+	return stack() // ERROR "args at call to stack: a\? b\? c\?$"
+}
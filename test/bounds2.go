@@ -0,0 +1,114 @@
+// run
+
+//go:build !wasm
+// +build !wasm
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that bounds check failures name the expression involved.
+
+package main
+
+import "runtime"
+
+type buffer struct {
+	buf []byte
+}
+
+//go:noinline
+func index(b *buffer, i int) byte {
+	return b.buf[i+1]
+}
+
+//go:noinline
+func slice(s string, i, j int) string {
+	return s[i:j]
+}
+
+//go:noinline
+func sliceArray(a *[8]int, i, j int) []int {
+	return a[i:j]
+}
+
+//go:noinline
+func slice3(s []int, i, j, k int) []int {
+	return s[i:j:k]
+}
+
+//go:noinline
+func sliceFrom(s []byte, off int) []byte {
+	return s[off:]
+}
+
+//go:noinline
+func store(a *[4]int, i int) {
+	a[i] = 1
+}
+
+//go:noinline
+func convert(s []int) *[4]int {
+	return (*[4]int)(s)
+}
+
+//go:noinline
+func results() []int {
+	return nil
+}
+
+//go:noinline
+func temp(i int) int {
+	return results()[i]
+}
+
+func expect(want string, f func()) {
+	defer func() {
+		err, ok := recover().(runtime.Error)
+		if !ok {
+			panic("no runtime error for " + want)
+		}
+		if got := err.Error(); got != want {
+			panic("got " + got + ", want " + want)
+		}
+	}()
+	f()
+}
+
+func main() {
+	expect("runtime error: index out of range [3] with length 3 in b.buf[i + 1]", func() {
+		index(&buffer{buf: make([]byte, 3)}, 2)
+	})
+	expect("runtime error: slice bounds out of range [2:1] in s[i:j]", func() {
+		slice("abc", 2, 1)
+	})
+	expect("runtime error: slice bounds out of range [:4] with length 3 in s[i:j]", func() {
+		slice("abc", 0, 4)
+	})
+	expect("runtime error: slice bounds out of range [5:4] in a[i:j] (length 8)", func() {
+		sliceArray(new([8]int), 5, 4)
+	})
+	expect("runtime error: slice bounds out of range [::5] with capacity 4 in s[i:j:k]", func() {
+		slice3(make([]int, 4), 0, 1, 5)
+	})
+	expect("runtime error: slice bounds out of range [:3:2] in s[i:j:k]", func() {
+		slice3(make([]int, 4), 0, 3, 2)
+	})
+	expect("runtime error: slice bounds out of range [3:2:] in s[i:j:k]", func() {
+		slice3(make([]int, 4), 3, 2, 4)
+	})
+	expect("runtime error: slice bounds out of range [3:2] in s[off:]", func() {
+		sliceFrom(make([]byte, 2), 3)
+	})
+	expect("runtime error: index out of range [4] with length 4 in a[i]", func() {
+		store(new([4]int), 4)
+	})
+	expect("runtime error: cannot convert slice with length 2 to pointer to array with length 4 in (*[4]int)(s)", func() {
+		convert(make([]int, 2))
+	})
+
+	// Compiler temporaries are not exposed.
+	expect("runtime error: index out of range [0] with length 0", func() {
+		temp(0)
+	})
+}
@@ -0,0 +1,137 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test the layout of go:cachealign types and variables.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// line is the cache line size on each architecture,
+// as in internal/cpu.CacheLinePadSize.
+var line = map[string]uintptr{
+	"386":      64,
+	"amd64":    64,
+	"arm":      32,
+	"arm64":    64,
+	"loong64":  64,
+	"mips":     32,
+	"mipsle":   32,
+	"mips64":   32,
+	"mips64le": 32,
+	"ppc64":    128,
+	"ppc64le":  128,
+	"riscv64":  32,
+	"s390x":    256,
+	"wasm":     64,
+}[runtime.GOARCH]
+
+//go:cachealign
+type counter struct {
+	n uint64
+}
+
+//go:cachealign
+type empty struct{}
+
+type shards struct {
+	flag    bool
+	counter counter
+	rest    [3]counter
+	after   uint32
+}
+
+type plain counter
+
+type unmarked struct {
+	n uint64
+}
+
+var (
+	c1 counter
+	c2 counter
+	b  byte
+	cs [4]counter
+
+	//go:cachealign
+	hot uint64
+	//go:cachealign
+	hot2 uint64
+)
+
+func check(what string, got, want uintptr) {
+	if got != want {
+		panic(fmt.Sprintf("%s = %d, want %d", what, got, want))
+	}
+}
+
+func aligned(what string, p unsafe.Pointer) {
+	if uintptr(p)%line != 0 {
+		panic(fmt.Sprintf("%s at %#x is not aligned to %d bytes", what, p, line))
+	}
+}
+
+func main() {
+	// The padding is outside the values themselves.
+	check("unsafe.Sizeof(counter{})", unsafe.Sizeof(counter{}), 8)
+	check("unsafe.Alignof(counter{})", unsafe.Alignof(counter{}), unsafe.Alignof(uint64(0)))
+	check("unsafe.Sizeof(empty{})", unsafe.Sizeof(empty{}), 0)
+	check("unsafe.Sizeof(plain{})", unsafe.Sizeof(plain{}), 8)
+	check("unsafe.Sizeof(cs)", unsafe.Sizeof(cs), 4*8)
+
+	var s shards
+	check("unsafe.Offsetof(s.counter)", unsafe.Offsetof(s.counter), line)
+	check("unsafe.Offsetof(s.rest)", unsafe.Offsetof(s.rest), 2*line)
+	check("unsafe.Offsetof(s.after)", unsafe.Offsetof(s.after), 3*line)
+	check("unsafe.Sizeof(s)", unsafe.Sizeof(s), 4*line)
+
+	aligned("c1", unsafe.Pointer(&c1))
+	aligned("c2", unsafe.Pointer(&c2))
+	aligned("cs", unsafe.Pointer(&cs))
+	aligned("hot", unsafe.Pointer(&hot))
+	aligned("hot2", unsafe.Pointer(&hot2))
+	b++
+
+	// Heap objects whose size is a multiple of the cache line
+	// size start on a cache line.
+	if line <= 64 {
+		for i := 0; i < 100; i++ {
+			aligned("new(shards)", unsafe.Pointer(new(shards)))
+		}
+	}
+
+	// Values are still copied, compared and used as map keys
+	// and channel elements normally.
+	m := map[counter]int{{1}: 1, {2}: 2}
+	ch := make(chan counter, 2)
+	ch <- counter{2}
+	if c := <-ch; m[c] != 2 || c != (counter{2}) {
+		panic("bad map or channel")
+	}
+	s.rest[2].n = 7
+	t := s
+	if t.rest[2].n != 7 || t != s {
+		panic("bad copy")
+	}
+	p := plain{n: 3}
+	if p.n != 3 {
+		panic("bad plain")
+	}
+
+	// Values convert to and from types with the same underlying
+	// type, and so do pointers to them.
+	u := unmarked(s.rest[2])
+	s.counter = counter(u)
+	pu := (*unmarked)(&s.counter)
+	pu.n++
+	if u.n != 7 || s.counter.n != 8 || plain(s.counter).n != 8 {
+		panic("bad conversion")
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+//go:cachealign
+type Counter struct {
+	N uint64
+}
+
+func (c *Counter) Inc() { c.N++ }
+
+type Stats struct {
+	Name  string
+	Count Counter
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"a"
+	"unsafe"
+)
+
+type local struct {
+	x     byte
+	stats a.Stats
+}
+
+var counters [2]a.Counter
+
+func main() {
+	line := unsafe.Offsetof(a.Stats{}.Count)
+	if line < 32 || line&(line-1) != 0 {
+		panic("a.Stats.Count is not padded")
+	}
+	var s local
+	if unsafe.Offsetof(s.stats) != line || unsafe.Offsetof(s.stats.Count) != line || unsafe.Sizeof(s) != 3*line {
+		panic("bad layout of local")
+	}
+	if uintptr(unsafe.Pointer(&counters))%line != 0 {
+		panic("counters is not aligned")
+	}
+	counters[1].Inc()
+	s.stats.Count.Inc()
+	if counters[1].N != 1 || s.stats.Count.N != 1 {
+		panic("bad Inc")
+	}
+}
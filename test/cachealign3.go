@@ -0,0 +1,55 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test errors for misuse of go:cachealign.
+
+package p
+
+//go:cachealign
+type T struct {
+	x int64
+}
+
+type U struct {
+	x int64
+}
+
+type V T
+
+//go:cachealign
+type N int // ERROR "go:cachealign only applies to struct types"
+
+//go:cachealign
+type G[P any] struct { // ERROR "go:cachealign only applies to struct types"
+	x P
+}
+
+//go:cachealign // ERROR "misplaced compiler directive"
+type A = struct{}
+
+//go:cachealign // ERROR "misplaced compiler directive"
+func f() {
+	//go:cachealign // ERROR "misplaced compiler directive"
+	type L struct{}
+
+	//go:cachealign // ERROR "misplaced compiler directive"
+	var x int
+	_ = x
+}
+
+// The padding does not change which conversions are allowed.
+func conv(t T, u U, v V, pt *T) {
+	_ = U(t)
+	_ = T(u)
+	_ = V(t)
+	_ = (*U)(pt)
+	_ = struct{ x int64 }(t)
+
+	var s struct{ x int64 } = t
+	_ = s
+	_ = T(t)
+	_ = V(v)
+}
@@ -0,0 +1,124 @@
+// errorcheck -0 -d=cgocheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=cgocheck reports obvious violations of the cgo pointer
+// passing rules. The code is written the way cmd/cgo translates it.
+
+package p
+
+import "unsafe"
+
+type _Ctype_int int32
+
+type _Ctype_size_t uint64
+
+type _Ctype_struct_cs struct {
+	x _Ctype_int
+	p *_Ctype_int
+}
+
+func _cgoCheckPointer(interface{}, interface{}) {}
+
+func _CMalloc(n _Ctype_size_t) unsafe.Pointer { return nil }
+
+func _Cfunc_f(p unsafe.Pointer) {}
+
+func _Cfunc_g(p *_Ctype_int) {}
+
+func _Cfunc_h(p *_Ctype_struct_cs) {}
+
+type S struct {
+	p *int
+	n _Ctype_int
+	q []int
+	c _Ctype_struct_cs
+}
+
+type P struct {
+	cs *_Ctype_struct_cs
+	s  []byte
+}
+
+// C.f(unsafe.Pointer(&s.p))
+func f1(s *S) {
+	func() {
+		_cgoBase0 := &s.p // ERROR "cgo argument has Go pointer to Go pointer: it points to \*int, which contains Go pointers"
+		_cgo0 := unsafe.Pointer(_cgoBase0)
+		_cgoCheckPointer(_cgoBase0, 0 == 0)
+		_Cfunc_f(_cgo0)
+	}()
+}
+
+// C.f(unsafe.Pointer(s))
+func f2(s *S) {
+	func() {
+		_cgo0 := unsafe.Pointer(s) // ERROR "cgo argument has Go pointer to Go pointer: it points to S, which contains Go pointers"
+		_cgoCheckPointer(_cgo0, nil)
+		_Cfunc_f(_cgo0)
+	}()
+}
+
+// C.f(unsafe.Pointer(&s.q[0]))
+func f3(s *S) {
+	func() {
+		_cgoIndex0 := &s.q
+		_cgo0 := unsafe.Pointer(&(*_cgoIndex0)[0])
+		_cgoCheckPointer(_cgo0, *_cgoIndex0)
+		_Cfunc_f(_cgo0)
+	}()
+}
+
+// C.g(&s.n), C.h(&s.c)
+func f4(s *S) {
+	func() {
+		_cgoBase0 := &s.n
+		_cgoCheckPointer(_cgoBase0, 0 == 0)
+		_Cfunc_g(_cgoBase0)
+	}()
+	func() {
+		_cgoBase0 := &s.c
+		_cgoCheckPointer(_cgoBase0, 0 == 0)
+		_Cfunc_h(_cgoBase0)
+	}()
+}
+
+// C.f(unsafe.Pointer(&ps)), where ps is a []*P.
+func f5(ps []*P) {
+	func() {
+		_cgo0 := unsafe.Pointer(&ps) // ERROR "it points to \[\]\*P, which contains Go pointers"
+		_cgoCheckPointer(_cgo0, nil)
+		_Cfunc_f(_cgo0)
+	}()
+}
+
+// Stores into memory from C.malloc.
+func f6(x *int, b []byte) {
+	cs := (*_Ctype_struct_cs)(_CMalloc(_Ctype_size_t(unsafe.Sizeof(_Ctype_struct_cs{}))))
+	cs.x = 1
+	cs.p = nil
+	cs.p = (*_Ctype_int)(_CMalloc(4))
+
+	p := (*P)(_CMalloc(_Ctype_size_t(unsafe.Sizeof(P{}))))
+	p.cs = cs
+	p.s = b // ERROR "Go pointer stored into C memory: b has type \[\]byte, which contains Go pointers"
+	p.s = nil
+
+	pp := (**int)(_CMalloc(8))
+	*pp = x // ERROR "Go pointer stored into C memory: x has type \*int"
+
+	var arr *[4]*int
+	arr = (*[4]*int)(_CMalloc(32))
+	arr[1] = x // ERROR "Go pointer stored into C memory"
+}
+
+// Variables also assigned Go memory are not known to point to C memory.
+func f7(x *int, q **int) {
+	pp := (**int)(_CMalloc(8))
+	if x == nil {
+		pp = q
+	}
+	*pp = x
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+func Produce(ch chan<- int, n int) {
+	for i := 0; i < n; i++ {
+		ch <- i
+	}
+}
+
+func Forward(ch chan<- int, n int) {
+	Produce(ch, n)
+}
+
+func Close(ch chan int) {
+	close(ch)
+}
+
+func Drain(ch chan int) {
+	for len(ch) > 0 {
+		<-ch
+	}
+	close(ch)
+}
+
+var saved chan int
+
+func Save(ch chan int) {
+	saved = ch
+}
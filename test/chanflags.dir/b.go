@@ -0,0 +1,105 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+func Local() int {
+	ch := make(chan int, 1)
+	ch <- 1     // ERROR "send on ch skips nil and closed checks"
+	return <-ch // ERROR "receive from ch skips nil and closed checks"
+}
+
+func Goroutine(n int) int {
+	ch := make(chan int)
+	go a.Produce(ch, n)
+	sum := 0
+	for i := 0; i < n; i++ {
+		sum += <-ch // ERROR "receive from ch skips nil and closed checks"
+	}
+	return sum
+}
+
+func Range(n int) int {
+	ch := make(chan int)
+	go a.Forward(ch, n)
+	sum := 0
+	for x := range ch { // ERROR "range over ch skips nil and closed checks"
+		if x == n-1 {
+			break
+		}
+		sum += x
+	}
+	return sum
+}
+
+func Copy() (int, bool) {
+	ch := make(chan int, 1)
+	c := ch
+	c <- 1        // ERROR "send on c skips nil and closed checks"
+	x, ok := <-ch // ERROR "receive from ch skips nil and closed checks"
+	return x, ok
+}
+
+func Closure() int {
+	ch := make(chan int, 1)
+	func() {
+		ch <- 1 // ERROR "send on ch skips nil and closed checks"
+	}()
+	return <-ch // ERROR "receive from ch skips nil and closed checks"
+}
+
+func Closed() int {
+	ch := make(chan int, 1)
+	ch <- 1
+	a.Close(ch)
+	return <-ch
+}
+
+func ClosedLater() int {
+	ch := make(chan int, 1)
+	ch <- 1
+	a.Drain(ch)
+	return <-ch
+}
+
+func Saved() int {
+	ch := make(chan int, 1)
+	a.Save(ch)
+	ch <- 1
+	return <-ch
+}
+
+func Returned() chan int {
+	ch := make(chan int, 1)
+	ch <- 1
+	return ch
+}
+
+func Interface() interface{} {
+	ch := make(chan int, 1)
+	ch <- 1
+	return ch
+}
+
+func Reassigned(c chan int) int {
+	ch := make(chan int, 1)
+	ch <- 1
+	ch = c
+	return <-ch
+}
+
+func Param(ch chan int) int {
+	return <-ch
+}
+
+func Select() int {
+	ch := make(chan int, 1)
+	select {
+	case ch <- 1:
+	default:
+	}
+	return <-ch // ERROR "receive from ch skips nil and closed checks"
+}
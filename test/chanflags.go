@@ -0,0 +1,11 @@
+// errorcheckdir -0 -l -d=chanflags
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that operations on channels made locally and never closed skip
+// the nil and closed checks, also when the channels are passed to
+// functions of other packages that keep them open.
+
+package ignored
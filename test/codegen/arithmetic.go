@@ -181,8 +181,8 @@ func MergeMuls2(n int) int {
 }
 
 func MergeMuls3(a, n int) int {
-	// amd64:"ADDQ\t[$]19",-"IMULQ\t[$]19"
-	// 386:"ADDL\t[$]19",-"IMULL\t[$]19"
+	// amd64:"ADDQ\t[$]19",-"IMULQ\t[$]19","IMULQ\t{gpr}, {gpr}",-"IMULQ"{2}
+	// 386:"ADDL\t[$]19",-"IMULL\t[$]19","IMULL\t{gpr}, {gpr}",-"IMULL"{2}
 	// ppc64:"ADD\t[$]19",-"MULLD\t[$]19"
 	// ppc64le:"ADD\t[$]19",-"MULLD\t[$]19"
 	return a*n + 19*n // (a+19)n
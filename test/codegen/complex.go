@@ -0,0 +1,29 @@
+// asmcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+// Complex division is compiled inline, calling the runtime
+// only to correct NaN results.
+
+func div128(a, b complex128) complex128 {
+	// amd64:"DIVSD","MAXSD"
+	// arm64:"FDIVD","FABSD"
+	// 386:"CALL\t.*complex128div"
+	return a / b
+}
+
+func div64(a, b complex64) complex64 {
+	// amd64:"CVTSS2SD","DIVSD","CVTSD2SS"
+	// arm64:"FDIVD"
+	return a / b
+}
+
+func mul128(a, b complex128) complex128 {
+	// amd64:"MULSD",-"CALL"
+	// arm64:"FMULD",-"CALL"
+	return a * b
+}
@@ -0,0 +1,27 @@
+// asmcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import "math/bits"
+
+// A //go:cpu function is compiled once for the package's GOAMD64
+// level and once for each higher level it names. With GOAMD64=v1
+// both versions of the body appear in the output; with GOAMD64=v3
+// only the one the package is compiled for does.
+
+//go:cpu amd64.v3
+func cpuTrailingZeros(x uint64) int { // amd64/v1:"CMPB\truntime.x86Level" amd64/v3:-"CMPB\truntime.x86Level"
+	// amd64/v1:"TZCNTQ","BSFQ"
+	// amd64/v3:"TZCNTQ",-"BSFQ"
+	return bits.TrailingZeros64(x)
+}
+
+//go:cpu amd64.v2
+func cpuOnesCount(x uint64) int { // amd64/v1:"CMPB\truntime.x86Level" amd64/v2:-"CMPB\truntime.x86Level"
+	// amd64:"POPCNTQ"
+	return bits.OnesCount64(x)
+}
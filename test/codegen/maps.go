@@ -122,3 +122,25 @@ func MapClearSideEffect(m map[int]int) int {
 	}
 	return k
 }
+
+// -------------------- //
+//     Map Literal      //
+// -------------------- //
+
+// Large map literals are filled in from static data by one runtime call.
+
+func MapLiteralLarge() map[int]string {
+	// amd64:`.*runtime\.mapinitbulk`
+	// amd64:-`.*runtime\.mapassign`
+	return map[int]string{
+		1: "a", 2: "b", 3: "c", 4: "d", 5: "e", 6: "f", 7: "g", 8: "h", 9: "i", 10: "j",
+		11: "k", 12: "l", 13: "m", 14: "n", 15: "o", 16: "p", 17: "q", 18: "r", 19: "s", 20: "t",
+		21: "u", 22: "v", 23: "w", 24: "x", 25: "y", 26: "z",
+	}
+}
+
+func MapLiteralSmall() map[int]string {
+	// amd64:`.*runtime\.mapassign_fast64`
+	// amd64:-`.*runtime\.mapinitbulk`
+	return map[int]string{1: "a", 2: "b"}
+}
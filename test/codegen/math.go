@@ -11,7 +11,7 @@ import "math"
 var sink64 [8]float64
 
 func approx(x float64) {
-	// amd64/v2:-".*x86HasSSE41" amd64/v3:-".*x86HasSSE41"
+	// amd64/v2+:-".*x86HasSSE41"
 	// amd64:"ROUNDSD\t[$]2"
 	// s390x:"FIDBR\t[$]6"
 	// arm64:"FRINTPD"
@@ -20,7 +20,7 @@ func approx(x float64) {
 	// wasm:"F64Ceil"
 	sink64[0] = math.Ceil(x)
 
-	// amd64/v2:-".*x86HasSSE41" amd64/v3:-".*x86HasSSE41"
+	// amd64/v2+:-".*x86HasSSE41"
 	// amd64:"ROUNDSD\t[$]1"
 	// s390x:"FIDBR\t[$]7"
 	// arm64:"FRINTMD"
@@ -35,7 +35,7 @@ func approx(x float64) {
 	// ppc64le:"FRIN"
 	sink64[2] = math.Round(x)
 
-	// amd64/v2:-".*x86HasSSE41" amd64/v3:-".*x86HasSSE41"
+	// amd64/v2+:-".*x86HasSSE41"
 	// amd64:"ROUNDSD\t[$]3"
 	// s390x:"FIDBR\t[$]5"
 	// arm64:"FRINTZD"
@@ -44,7 +44,7 @@ func approx(x float64) {
 	// wasm:"F64Trunc"
 	sink64[3] = math.Trunc(x)
 
-	// amd64/v2:-".*x86HasSSE41" amd64/v3:-".*x86HasSSE41"
+	// amd64/v2+:-".*x86HasSSE41"
 	// amd64:"ROUNDSD\t[$]0"
 	// s390x:"FIDBR\t[$]4"
 	// arm64:"FRINTND"
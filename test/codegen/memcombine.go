@@ -447,6 +447,14 @@ func store_be64_load(b, x *[8]byte) {
 	binary.BigEndian.PutUint64(b[:], binary.BigEndian.Uint64(x[:]))
 }
 
+func store_le64_local(x uint64) byte {
+	var b [8]byte
+	// amd64:-`MOVQ\s[$]0`
+	// arm64:-`MOVD\sZR`
+	binary.LittleEndian.PutUint64(b[:], x)
+	return b[0]
+}
+
 func store_be32_load(b, x *[8]byte) {
 	// arm64:-`REVW`
 	// amd64:-`BSWAPL`
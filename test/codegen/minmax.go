@@ -0,0 +1,81 @@
+// asmcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+// Float min/max and clamp written as compare-and-select are
+// compiled without branches.
+
+func min64(x, y float64) float64 {
+	if x < y {
+		// amd64:"MINSD",-"J[A-Z]"
+		// arm64:"FCSELD\tMI",-"FMIND",-"B[A-Z]"
+		return x
+	}
+	return y
+}
+
+func max64(x, y float64) float64 {
+	if x > y {
+		// amd64:"MAXSD",-"J[A-Z]"
+		// arm64:"FCSELD\tGT",-"FMAXD",-"B[A-Z]"
+		return x
+	}
+	return y
+}
+
+func min32(x, y float32) float32 {
+	m := y
+	if x < y {
+		m = x
+	}
+	// amd64:"MINSS",-"J[A-Z]"
+	// arm64:"FCSELS\tMI",-"B[A-Z]"
+	return m
+}
+
+func clamp(x, lo, hi float64) float64 {
+	if x < lo {
+		x = lo
+	}
+	if x > hi {
+		x = hi
+	}
+	// amd64:"MAXSD","MINSD",-"J[A-Z]"
+	// arm64:"FCSELD",-"B[A-Z]"
+	return x
+}
+
+// With <=, the result for NaNs and zeros of different sign differs
+// from MINSD and FCSEL with the same condition, so the branch is kept.
+func minLeq(x, y float64) float64 {
+	if x <= y {
+		// amd64:-"MINSD"
+		// arm64:-"FCSELD",-"FMIND"
+		return x
+	}
+	return y
+}
+
+//go:fastminmax
+func minLeqFast(x, y float64) float64 {
+	if x <= y {
+		// amd64:"MINSD",-"J[A-Z]"
+		// arm64:"FMIND",-"B[A-Z]"
+		return x
+	}
+	return y
+}
+
+//go:fastminmax
+func maxFast(x, y float32) float32 {
+	if x > y {
+		// amd64:"MAXSS"
+		// arm64:"FMAXS"
+		return x
+	}
+	return y
+}
@@ -0,0 +1,27 @@
+// asmcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import "reflect"
+
+type T struct{ a, b int }
+
+func reflectTypeOfStruct(x T) reflect.Type {
+	// amd64:-`CALL\truntime\.convT`,`LEAQ\ttype\."".T\(SB\)`
+	return reflect.TypeOf(x)
+}
+
+func reflectTypeOfConst() reflect.Type {
+	// amd64:`LEAQ\ttype\.int\(SB\)`
+	// arm64:-`CALL`
+	return reflect.TypeOf(0)
+}
+
+func reflectTypeOfIface(x interface{}) reflect.Type {
+	// Interface arguments still need a dynamic lookup.
+	return reflect.TypeOf(x)
+}
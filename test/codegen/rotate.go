@@ -178,6 +178,44 @@ func rot8nc(x uint8, z uint) uint8 {
 	return a
 }
 
+// rotates written with masked shift counts
+func rot64masked(x uint64, y, z uint) uint64 {
+	var a uint64
+
+	// amd64:"ROLQ"
+	// arm64:"ROR"
+	// ppc64le:"ROTL"
+	// s390x:"RLLG"
+	a += x<<(z&63) | x>>(-z&63)
+
+	// amd64:"ROLQ"
+	// s390x:"RLLG"
+	a += x<<(y&63) | x>>((64-y)&63)
+
+	// amd64:"RORQ"
+	// arm64:"ROR"
+	// s390x:"RLLG"
+	a += x>>(z&63) | x<<(-z&63)
+
+	return a
+}
+
+func rot32masked(x uint32, z uint32) uint32 {
+	var a uint32
+
+	// amd64:"ROLL"
+	// arm64:"RORW"
+	// ppc64le:"ROTLW"
+	// s390x:"RLL"
+	a += x<<(z&31) | x>>(-z&31)
+
+	// amd64:"RORL"
+	// arm64:"RORW"
+	a += x>>(z&31) | x<<(-z&31)
+
+	return a
+}
+
 // Issue 18254: rotate after inlining
 func f32(x uint32) uint32 {
 	// amd64:"ROLL\t[$]7"
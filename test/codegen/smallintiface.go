@@ -20,3 +20,13 @@ func smalluint8iface() interface{} {
 	// amd64:`LEAQ\truntime.staticuint64s\+24\(SB\)`
 	return uint8(3)
 }
+
+func zeroarrayiface() interface{} {
+	// amd64:`LEAQ\tgo.map.zero\(SB\)`
+	return [4]int{}
+}
+
+func zerostringiface() interface{} {
+	// amd64:`LEAQ\tgo.map.zero\(SB\)`
+	return ""
+}
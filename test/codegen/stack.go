@@ -89,6 +89,37 @@ func ArrayInit(i, j int) [4]int {
 	return [4]int{i, 0, j, 0}
 }
 
+// Check that local structs and arrays too large to be SSA-able are
+// split into their fields, also when passed to inlined functions.
+
+type Vec6 struct{ X, Y, Z, U, V, W float64 }
+
+func (v Vec6) add(w Vec6) Vec6 {
+	return Vec6{v.X + w.X, v.Y + w.Y, v.Z + w.Z, v.U + w.U, v.V + w.V, v.W + w.W}
+}
+
+func (v Vec6) sum() float64 {
+	return v.X + v.Y + v.Z + v.U + v.V + v.W
+}
+
+// amd64:"TEXT\t.*, [$]0-"
+// arm64:"TEXT\t.*, [$]0-"
+func SplitStruct(x, y float64) float64 {
+	v := Vec6{X: x, W: y}
+	return v.add(v).sum()
+}
+
+// amd64:"TEXT\t.*, [$]0-"
+// arm64:"TEXT\t.*, [$]0-"
+func SplitArray(n int) int {
+	var a [8]int
+	for i := 0; i < n; i++ {
+		a[0] += i
+		a[7] ^= a[0]
+	}
+	return a[0] + a[7]
+}
+
 // Check that assembly output has matching offset and base register
 // (issue #21064).
 
@@ -118,3 +149,32 @@ func Defer() {
 	// amd64:`CALL\truntime\.deferprocStack`
 	defer func() {}()
 }
+
+// Check that ranging over a local array the loop does not change
+// reads the array in place, rather than a copy of it.
+
+// amd64:"TEXT\t.*, [$]0-"
+// arm64:"TEXT\t.*, [$]0-"
+func RangeArray(a [16]int) (s int) {
+	for _, v := range a {
+		s += v
+	}
+	return s
+}
+
+// Check that multiple call results are stored straight into the
+// local variables they are assigned to, without temporaries.
+
+type Pair [16]int
+
+//go:noinline
+func pair() (Pair, Pair) {
+	return Pair{}, Pair{}
+}
+
+// amd64:"TEXT\t.*, [$]520-"
+// arm64:"TEXT\t.*, [$]520-"
+func CallResults(i int) int {
+	x, y := pair()
+	return x[i] + y[i]
+}
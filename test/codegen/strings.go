@@ -6,6 +6,8 @@
 
 package codegen
 
+import "strings"
+
 // This file contains code generation tests related to the handling of
 // string types.
 
@@ -21,6 +23,18 @@ func ToByteSlice() []byte { // Issue #24698
 	return []byte("foo")
 }
 
+// Operations on constant strings that are not constant expressions
+// should be folded.
+func FoldRepeat() string {
+	// amd64:`LEAQ\tgo\.string\."ababab"`,-`CALL`
+	return strings.Repeat("ab", 3)
+}
+
+func FoldConversions() string {
+	// amd64:`LEAQ\tgo\.string\."abc-"`,-`CALL`
+	return string([]byte("abc")) + string("x-y"[1])
+}
+
 // Loading from read-only symbols should get transformed into constants.
 func ConstantLoad() {
 	// 12592 = 0x3130
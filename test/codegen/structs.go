@@ -44,3 +44,71 @@ func Init1(p *I1) { // Issue #18872
 	// amd64:`MOVQ\t[$]1`,`MOVQ\t[$]2`,`MOVQ\t[$]3`,`MOVQ\t[$]4`
 	*p = I1{1, 2, 3, 4}
 }
+
+type I2 struct {
+	a *int
+	n int
+	b *int
+	m int
+	c *int
+}
+
+func Init2(p *I2, x, y, z *int) {
+	// One write barrier test covers all the pointer stores.
+	// amd64:`.*runtime[.]writeBarrier\(SB\)`
+	p.a = x
+	p.n = 1
+	// amd64:-`.*runtime[.]writeBarrier\(SB\)`
+	p.b = y
+	p.m = 2
+	// amd64:-`.*runtime[.]writeBarrier\(SB\)`
+	p.c = z
+}
+
+// --------------- //
+//    Returning    //
+// --------------- //
+
+// Fields of zero size don't count against the number of fields a
+// struct can have and still be kept in registers.
+
+type R1 struct {
+	a, b int
+	_    struct{}
+	c, d int
+}
+
+//go:noinline
+func newR1(x int) R1 {
+	// amd64:-`\(SP\)`
+	// arm64:-`\(RSP\)`
+	return R1{a: x, d: x}
+}
+
+func ReturnR1(x int) int {
+	// amd64:-`\(SP\)`
+	// arm64:-`\(RSP\)`
+	r := newR1(x)
+	return r.a + r.d
+}
+
+type R2 struct {
+	_    [0]func()
+	p    *int
+	n, m int
+	_    struct{}
+}
+
+//go:noinline
+func newR2(p *int) R2 {
+	// amd64:-`\(SP\)`
+	// arm64:-`\(RSP\)`
+	return R2{p: p, m: 1}
+}
+
+func ReturnR2(p *int) int {
+	// amd64:-`\(SP\)`
+	// arm64:-`\(RSP\)`
+	r := newR2(p)
+	return *r.p + r.m
+}
@@ -20,3 +20,19 @@ func f(x string) int {
 		return -3
 	}
 }
+
+// Same-length string cases are told apart by one of their bytes
+// before whole strings are compared for equality.
+func keyword(x string) int {
+	// amd64:-`cmpstring`
+	// arm64:-`cmpstring`
+	switch x {
+	case "chan", "else", "func", "goto", "type":
+		return 1
+	case "break", "const", "defer", "range":
+		return 2
+	case "import", "return", "select", "struct", "switch":
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+//go:pure
+//go:noalloc
+//go:noinline
+func Add(x, y int) int { return x + y }
+
+//go:noinline
+func Sub(x, y int) int { return x - y }
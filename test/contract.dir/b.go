@@ -0,0 +1,17 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+//go:pure
+func F(x int) int {
+	return a.Add(x, 1)
+}
+
+//go:noalloc
+func G(x int) int {
+	return a.Add(x, 1)
+}
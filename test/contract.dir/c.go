@@ -0,0 +1,12 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package c
+
+import "./a"
+
+//go:pure
+func F(x int) int {
+	return a.Add(x, 1) + a.Sub(x, 1) // ERROR "//go:pure function F calls a.Sub, which is not //go:pure"
+}
@@ -30,7 +30,7 @@ func main() {
 		func() {
 			_ = (*[9]byte)(s)
 		},
-		"runtime error: cannot convert slice with length 8 to pointer to array with length 9",
+		"runtime error: cannot convert slice with length 8 to pointer to array with length 9 in (*[9]byte)(s)",
 	)
 
 	var n []byte
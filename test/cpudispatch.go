@@ -0,0 +1,88 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that functions marked //go:cpu compute the same results
+// whichever of their variants the CPU runs.
+
+package main
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+//go:cpu amd64.v2 amd64.v3 amd64.v4
+func count(x uint64) int {
+	return bits.TrailingZeros64(x) + bits.OnesCount64(x) + bits.LeadingZeros64(x)
+}
+
+//go:cpu amd64.v3
+func sum(_ string, xs ...uint64) (n int, err error) {
+	defer func() { n++ }()
+	for _, x := range xs {
+		n += count(x)
+	}
+	if n == 0 {
+		err = fmt.Errorf("no bits")
+	}
+	return n, err
+}
+
+//go:cpu amd64.v3
+func clear(p *uint64) {
+	*p = bits.RotateLeft64(*p, 3) &^ *p
+}
+
+//go:cpu amd64.v3
+func pick(uint64, bool) int {
+	return 1
+}
+
+//go:cpu amd64.v2
+func fact(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n * fact(n-1)
+}
+
+func main() {
+	for _, tc := range []struct {
+		x    uint64
+		want int
+	}{
+		{0, 128},
+		{1, 64},
+		{0x80, 64},
+		{1 << 63, 64},
+		{^uint64(0), 64},
+	} {
+		if got := count(tc.x); got != tc.want {
+			panic(fmt.Sprintf("count(%#x) = %d, want %d", tc.x, got, tc.want))
+		}
+	}
+
+	if n, err := sum("", 1, 0x80); n != 129 || err != nil {
+		panic(fmt.Sprintf("sum = %d, %v, want 129, nil", n, err))
+	}
+	if n, err := sum(""); n != 1 || err == nil {
+		panic(fmt.Sprintf("sum() = %d, %v, want 1, error", n, err))
+	}
+
+	x := uint64(0x11)
+	clear(&x)
+	if x != 0x88 {
+		panic(fmt.Sprintf("clear = %#x, want 0x88", x))
+	}
+
+	if got := pick(1, true); got != 1 {
+		panic(fmt.Sprintf("pick = %d, want 1", got))
+	}
+
+	if got := fact(10); got != 3628800 {
+		panic(fmt.Sprintf("fact(10) = %d, want 3628800", got))
+	}
+}
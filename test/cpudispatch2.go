@@ -0,0 +1,18 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that //go:cpu rejects unknown feature levels.
+
+package p
+
+//go:cpu amd64.v2 amd64.v3 amd64.v4
+func f() {}
+
+//go:cpu amd64.v1 // ERROR "invalid //go:cpu level .amd64.v1."
+func g() {}
+
+//go:cpu arm64.v8.1 // ERROR "invalid //go:cpu level .arm64.v8.1."
+func h() {}
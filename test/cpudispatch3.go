@@ -0,0 +1,20 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that //go:cpu is only allowed on functions with bodies.
+
+package p
+
+//go:cpu amd64.v3
+func f() {}
+
+//go:cpu amd64.v3
+func external() // ERROR "go:cpu requires a function body"
+
+type T int
+
+//go:cpu amd64.v3
+func (T) m() {} // ERROR "go:cpu cannot be used on methods"
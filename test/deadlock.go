@@ -0,0 +1,136 @@
+// errorcheck -0 -d=deadlock
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=deadlock reports channel operations and mutex locks
+// that block forever along straight-line code.
+
+package p
+
+import "sync"
+
+func send() {
+	ch := make(chan int)
+	ch <- 1 // ERROR "send on unbuffered channel ch blocks forever: no other goroutine can receive from it"
+}
+
+func sendBuffered() {
+	ch := make(chan int, 1)
+	ch <- 1
+}
+
+func recv() {
+	var ch = make(chan int, 10)
+	x := 0
+	x++
+	<-ch // ERROR "receive from channel ch blocks forever: no other goroutine can send on it"
+}
+
+func recvStmt() int {
+	ch := make(chan int, 10)
+	x, ok := <-ch // ERROR "receive from channel ch blocks forever: no other goroutine can send on it"
+	if ok {
+		return x
+	}
+	<-ch
+	return 0
+}
+
+func recvAfterSend() int {
+	ch := make(chan int, 1)
+	ch <- 1
+	return <-ch
+}
+
+func goroutine() int {
+	ch := make(chan int)
+	go func() {
+		ch <- 1
+	}()
+	return <-ch
+}
+
+func passed(f func(chan int)) {
+	ch := make(chan int)
+	f(ch)
+	ch <- 1
+}
+
+func sendSelf() {
+	ch := make(chan interface{})
+	ch <- ch
+}
+
+func branch(b bool) {
+	ch := make(chan int)
+	if b {
+		return
+	}
+	ch <- 1 // ERROR "send on unbuffered channel ch blocks forever"
+}
+
+func label(b bool) {
+	ch := make(chan int)
+L:
+	if b {
+		b = false
+		go func() { <-ch }()
+		goto L
+	}
+	ch <- 1
+}
+
+type T struct {
+	mu sync.Mutex
+	rw sync.RWMutex
+	n  int
+}
+
+func lockTwice(t *T) {
+	t.mu.Lock()
+	t.n++
+	t.mu.Lock() // ERROR "lock of t.mu blocks forever: it is already locked at .*deadlock.go:92"
+}
+
+func lockUnlock(t *T) {
+	t.mu.Lock()
+	t.mu.Unlock()
+	t.mu.Lock()
+}
+
+func lockCall(t *T, f func()) {
+	t.mu.Lock()
+	f()
+	t.mu.Lock()
+}
+
+func lockReassigned(t, u *T) {
+	t.mu.Lock()
+	t = u
+	t.mu.Lock()
+}
+
+func lockOther(t, u *T) {
+	t.mu.Lock()
+	u.mu.Lock()
+}
+
+func rlock(t *T) {
+	t.rw.RLock()
+	t.rw.RLock()
+	t.rw.Lock() // ERROR "lock of t.rw blocks forever"
+}
+
+func lockRLock(mu *sync.RWMutex) {
+	mu.Lock()
+	mu.RLock() // ERROR "lock of mu blocks forever"
+}
+
+func local() int {
+	var mu sync.Mutex
+	mu.Lock()
+	mu.Lock() // ERROR "lock of mu blocks forever"
+	return 0
+}
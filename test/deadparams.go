@@ -0,0 +1,78 @@
+// errorcheck -0 -m=2 -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that unused parameters and unread results of unexported
+// functions are removed.
+
+package p
+
+var sink int
+
+func work(x int, debug bool, name string) (n int, err error) { // ERROR "name does not escape" "removing unused parameter debug of work" "removing unused parameter name of work" "removing unread result err of work"
+	for i := 0; i < x; i++ {
+		n += i
+	}
+	if n > 100 {
+		err = errTooBig
+	}
+	return
+}
+
+type bigError struct{}
+
+func (bigError) Error() string { return "too big" }
+
+var errTooBig error = bigError{}
+
+func unnamed(int, string) int { // ERROR "removing unused parameter #1 of unnamed" "removing unused parameter #2 of unnamed" "removing unread result #1 of unnamed"
+	sink++
+	return sink
+}
+
+func effect(x, y int) int { // x is always passed the result of a call
+	return y
+}
+
+func next() int {
+	sink++
+	return sink
+}
+
+func read(x int) (int, int) { // both results are read somewhere
+	return x, x + 1
+}
+
+func Exported(x, y int) int { // may be called from other packages
+	return x
+}
+
+func taken(x, y int) int { // used as a value
+	return x
+}
+
+var f = taken
+
+//go:noinline
+func noinline(x, y int) int { // calls must reach it as written
+	return x
+}
+
+// A deferred call may read r.
+func deferred(x int) (r int) { // ERROR "deferred capturing by ref: r"
+	defer func() { sink = r }() // ERROR "func literal does not escape"
+	r = x
+	return
+}
+
+func A(x int) int {
+	n, _ := work(x, true, "a")
+	work(x, false, "b")
+	unnamed(1, "x")
+	a, _ := read(x)
+	_, b := read(x)
+	deferred(x)
+	return n + a + b + effect(next(), x) + effect(read(x)) + Exported(x, 1) + taken(x, 1) + noinline(x, 1)
+}
@@ -0,0 +1,84 @@
+// run -gcflags=-l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that removing unused parameters and unread results
+// preserves the evaluation of side effects.
+
+package main
+
+import "fmt"
+
+var trace []string
+
+func note(s string) int {
+	trace = append(trace, s)
+	return len(trace)
+}
+
+// The second result is never read, but it still has a side effect.
+func pair(x int) (int, int) {
+	return note("a"), note("b") + x
+}
+
+// The named result err is never read by callers but is used as a local.
+func sum(xs []int, verbose bool) (n int, err error) {
+	for _, x := range xs {
+		if x < 0 && err == nil {
+			err = fmt.Errorf("negative %d", x)
+		}
+		n += x
+	}
+	if err != nil {
+		n = -n
+	}
+	return n, err
+}
+
+func unused(a int, _ string, c int) int {
+	return a + c
+}
+
+func stmt(x int) int {
+	note(fmt.Sprint("stmt", x))
+	return x
+}
+
+func closure(x, y int) int {
+	f := func() int { return x * 2 }
+	return f()
+}
+
+func main() {
+	a, _ := pair(10)
+	if a != 1 || fmt.Sprint(trace) != "[a b]" {
+		panic(fmt.Sprint(a, trace))
+	}
+
+	if n, _ := sum([]int{1, -2, 3}, true); n != -2 {
+		panic(n)
+	}
+	if n, _ := sum([]int{1, 2, 3}, false); n != 6 {
+		panic(n)
+	}
+
+	if got := unused(1, "x", 2); got != 3 {
+		panic(got)
+	}
+
+	trace = nil
+	stmt(1)
+	_ = stmt(2)
+	for i := 0; i < 2; stmt(i + 3) {
+		i++
+	}
+	if fmt.Sprint(trace) != "[stmt1 stmt2 stmt4 stmt5]" {
+		panic(fmt.Sprint(trace))
+	}
+
+	if got := closure(4, 5); got != 8 {
+		panic(got)
+	}
+}
@@ -0,0 +1,61 @@
+// errorcheck -0 -d=dedupfuncs=2
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that functions with identical code are reported.
+
+package p
+
+type A struct{ x, y int }
+type B struct{ u, v int }
+type C struct {
+	x int
+	p *int
+}
+
+//go:noinline
+func (a *A) Y() int { return a.y }
+
+//go:noinline
+func (b *B) V() int { return b.v } // ERROR "\(\*B\).V has the same code as \(\*A\).Y"
+
+//go:noinline
+func (c *C) X() int { return c.x } // not the same field
+
+//go:noinline
+func Add(x, y int) int { return x + y }
+
+//go:noinline
+func Plus(a, b int) int { return a + b } // ERROR "Plus has the same code as Add"
+
+//go:noinline
+func AddF(x, y float64) float64 { return x + y }
+
+//go:noinline
+func Sub(x, y int) int { return x - y }
+
+//go:noinline
+func Sum(s []int) int {
+	t := 0
+	for _, x := range s {
+		t += x
+	}
+	return t
+}
+
+//go:noinline
+func Total(v []int) int { // ERROR "Total has the same code as Sum"
+	n := 0
+	for _, x := range v {
+		n += x
+	}
+	return n
+}
+
+//go:noinline
+func StoreP(p **int, q *int) { *p = q }
+
+//go:noinline
+func StoreI(p *int, q int) { *p = q }
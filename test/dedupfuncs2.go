@@ -0,0 +1,75 @@
+// run -gcflags=-d=dedupfuncs
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that calls to functions emitted as jumps to identical
+// functions still work, including through method values,
+// interfaces and deferred calls.
+
+package main
+
+type A struct{ x, y int }
+type B struct{ u, v int }
+
+//go:noinline
+func (a *A) Y() int { return a.y }
+
+//go:noinline
+func (b *B) V() int { return b.v }
+
+type Ver interface{ V() int }
+
+//go:noinline
+func Sum(s []int) int {
+	t := 0
+	for _, x := range s {
+		t += x
+	}
+	return t
+}
+
+//go:noinline
+func Total(v []int) int {
+	n := 0
+	for _, x := range v {
+		n += x
+	}
+	return n
+}
+
+func main() {
+	a := &A{1, 2}
+	b := &B{3, 4}
+	if got := a.Y(); got != 2 {
+		panic(got)
+	}
+	if got := b.V(); got != 4 {
+		panic(got)
+	}
+	var v Ver = b
+	if got := v.V(); got != 4 {
+		panic(got)
+	}
+	f := b.V
+	if got := f(); got != 4 {
+		panic(got)
+	}
+	if got := Total([]int{1, 2, 3}); got != 6 {
+		panic(got)
+	}
+	g := Total
+	if got := g([]int{4, 5}); got != 9 || Sum([]int{4, 5}) != 9 {
+		panic(got)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				panic("no panic")
+			}
+		}()
+		var p *B
+		p.V()
+	}()
+}
@@ -84,3 +84,41 @@ label:
 		fmt.Println("defer")
 	}()
 }
+
+func f7(xs []int) int {
+	for _, x := range xs {
+		if x > glob {
+			// The defer executes at most once, since the loop is
+			// always exited by the return that follows it.
+			defer func() { // ERROR "open-coded defer"
+				fmt.Println("defer", x)
+			}()
+			fmt.Println("found")
+			return x
+		}
+	}
+	return 0
+}
+
+func f8(xs []int) {
+	for _, x := range xs {
+		defer func() { // ERROR "heap-allocated defer"
+			fmt.Println("defer", x)
+		}()
+		if x > glob {
+			continue
+		}
+		return
+	}
+}
+
+func f9(xs []int) {
+	for _, x := range xs {
+		if x < 0 {
+			defer func() { // ERROR "open-coded defer"
+				fmt.Println("defer", x)
+			}()
+			panic("negative")
+		}
+	}
+}
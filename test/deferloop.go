@@ -0,0 +1,56 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test defers within loops that execute at most once per call,
+// which may be open-coded.
+
+package main
+
+var log []int
+
+func find(xs []int, want int) (r int) {
+	for i, x := range xs {
+		if x == want {
+			defer func() {
+				log = append(log, i)
+				r++
+			}()
+			return i * 10
+		}
+	}
+	return -1
+}
+
+func mustPanic(xs []int) (err interface{}) {
+	defer func() {
+		err = recover()
+	}()
+	for _, x := range xs {
+		if x < 0 {
+			p := &x
+			defer func() {
+				log = append(log, *p)
+			}()
+			panic("negative")
+		}
+	}
+	return nil
+}
+
+func main() {
+	if r := find([]int{1, 2, 3}, 3); r != 21 {
+		panic(r)
+	}
+	if r := find([]int{1, 2, 3}, 4); r != -1 {
+		panic(r)
+	}
+	if err := mustPanic([]int{1, -2, -3}); err != "negative" {
+		panic(err)
+	}
+	if len(log) != 2 || log[0] != 2 || log[1] != -2 {
+		panic(log)
+	}
+}
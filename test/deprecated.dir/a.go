@@ -0,0 +1,73 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+// Old returns 1.
+//
+// Deprecated: Old is slow;
+// use New instead.
+//
+// Old calls New.
+func Old() int {
+	return New()
+}
+
+func New() int {
+	return 1
+}
+
+// Deprecated: Use Limit.
+const Max = 10
+
+const Limit = 10
+
+var (
+	// Deprecated: Use Default.
+	Std = T{}
+
+	Default = T{}
+)
+
+type (
+	// T is a type.
+	T struct {
+		// Deprecated: Use Y.
+		X int
+		Y int
+	}
+
+	// Deprecated:
+	U int
+
+	V interface {
+		// Deprecated: Use N.
+		M()
+		N()
+	}
+
+	W int
+)
+
+// Get returns t.Y.
+//
+// Deprecated: Read t.Y directly.
+func (t T) Get() int {
+	return t.Y
+}
+
+func (t T) Set(y int) {
+	t.Y = y
+}
+
+func F() {
+	// Deprecated: Not a doc comment.
+}
+
+func G() {}
+
+func uses() {
+	// Uses in the same package are not reported.
+	_ = Old() + Max + Std.Get()
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+func f(v a.V) int {
+	_ = a.Old() // ERROR "a.Old is deprecated: Old is slow; use New instead.$"
+	_ = a.New()
+	_ = a.Max // ERROR "a.Max is deprecated: Use Limit."
+	_ = a.Limit
+	_ = a.Std.Y // ERROR "a.Std is deprecated: Use Default."
+	_ = a.Default.X
+	var u a.U // ERROR "a.U is deprecated$"
+	var w a.W
+	v.M()
+	a.F()
+	a.G()
+	t := a.T{}
+	t.Set(int(u) + int(w))
+	return t.Get() // ERROR "a.T.Get is deprecated: Read t.Y directly."
+}
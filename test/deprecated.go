@@ -0,0 +1,12 @@
+// errorcheckdir -deprecated=2
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that uses of declarations of other packages whose doc
+// comments have a "Deprecated:" paragraph are reported, with
+// the notice, and that other comments don't mark declarations
+// deprecated.
+
+package ignored
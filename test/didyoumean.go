@@ -0,0 +1,48 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that errors for undefined names and selectors suggest the
+// name that was probably meant.
+
+package p
+
+import (
+	"fmt"
+	"strings"
+)
+
+type T struct {
+	Count int
+	inner
+}
+
+type inner struct {
+	label string
+}
+
+func (T) Reset() {}
+
+func f() {
+	var total int
+	_ = total
+	_ = totl  // ERROR "undefined: totl \(did you mean total\?\)"
+	_ = xyz   // ERROR "undefined: xyz$"
+	_ = Total // ERROR "undefined: Total \(did you mean total\?\)"
+
+	fmt.Prinln("x")  // ERROR "undefined: fmt.Prinln \(did you mean fmt.Println\?\)"
+	fmt.Nothing("x") // ERROR "undefined: fmt.Nothing$"
+
+	var t T
+	_ = t.Cuont  // ERROR "t.Cuont undefined \(type T has no field or method Cuont, did you mean Count\?\)"
+	_ = t.lable  // ERROR "t.lable undefined \(type T has no field or method lable, did you mean label\?\)"
+	t.Rest()     // ERROR "t.Rest undefined \(type T has no field or method Rest, did you mean Reset\?\)"
+	_ = t.count  // ERROR "t.count undefined \(type T has no field or method count, but does have Count\)"
+	_ = t.Weight // ERROR "t.Weight undefined \(type T has no field or method Weight\)$"
+
+	var b strings.Builder
+	_ = b.buf // ERROR "b.buf undefined \(type strings.Builder has no field or method buf, but does have unexported field buf\)"
+	_ = b.Buf // ERROR "b.Buf undefined \(type strings.Builder has no field or method Buf, but does have unexported field buf\)"
+}
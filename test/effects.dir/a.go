@@ -0,0 +1,68 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+var G int
+
+//go:noinline
+func Sum(x, y int) int { return x + y } // ERROR "Sum effects: none"
+
+//go:noinline
+func Get() int { return G } // ERROR "Get effects: reads"
+
+//go:noinline
+func Set(x int) { G = x } // ERROR "Set effects: writes"
+
+//go:noinline
+func Loop(n int) int { // ERROR "Loop effects: blocks"
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+
+//go:noinline
+func Index(s []int, i int) int { return s[i] } // ERROR "Index effects: reads, panics"
+
+//go:noinline
+func New(x int) *int { // ERROR "New effects: allocates"
+	p := new(int)
+	*p = x
+	return p
+}
+
+//go:noinline
+func CallsSum(x int) int { return Sum(x, x) } // ERROR "CallsSum effects: none"
+
+//go:noinline
+func CallsSet(x int) { Set(x) } // ERROR "CallsSet effects: writes"
+
+//go:noinline
+func Recv(c chan int) int { return <-c } // ERROR "Recv effects: reads, writes, panics, blocks"
+
+//go:noinline
+func Recurse(n int) int { // ERROR "Recurse effects: blocks"
+	if n == 0 {
+		return 0
+	}
+	return Recurse(n - 1)
+}
+
+//go:noinline
+func Even(n uint) bool { // ERROR "Even effects: blocks"
+	if n == 0 {
+		return true
+	}
+	return Odd(n - 1)
+}
+
+//go:noinline
+func Odd(n uint) bool { // ERROR "Odd effects: blocks"
+	if n == 0 {
+		return false
+	}
+	return Even(n - 1)
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+func F(x int, s []int, c chan int) { // ERROR "F effects: reads, writes, panics, blocks"
+	a.Sum(x, 1)   // ERROR "removed call to a.Sum, which has no effects"
+	a.Get()       // ERROR "removed call to a.Get, which has no effects"
+	a.New(x + 1)  // ERROR "removed call to a.New, which has no effects"
+	a.CallsSum(x) // ERROR "removed call to a.CallsSum, which has no effects"
+	a.Set(x)
+	a.CallsSet(x)
+	a.Loop(x)
+	a.Index(s, x)
+	a.Recv(c)
+	a.Recurse(x)
+	a.Even(uint(x))
+	a.Sum(s[x], 1) // ERROR "removed call to a.Sum, which has no effects"
+}
+
+func G(x int) int { // ERROR "G effects: none"
+	return a.Sum(x, 1)
+}
+
+func H(p *int) { // ERROR "H effects: writes"
+	*p = 1
+}
+
+func K(x int) *int { // ERROR "K effects: allocates"
+	return a.New(x)
+}
@@ -0,0 +1,10 @@
+// errorcheckdir -0 -d=effects
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that function effect summaries are computed, written to
+// export data, and used to remove calls that have no effect.
+
+package ignored
@@ -0,0 +1,55 @@
+// errorcheck -0 -d=eqsize=128
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Check reporting of expensive equality algorithms.
+
+package p
+
+type big struct {
+	a [256]byte
+	s string
+}
+
+type mem [512]byte
+
+type strs [16]string
+
+type small struct {
+	a, b int
+	s    string
+}
+
+func f1(x, y big) bool {
+	return x == y // ERROR "comparison of big compares 272 bytes \(generated eq func, 3 comparisons\)"
+}
+
+func f2(x, y *mem) bool {
+	return *x != *y // ERROR "comparison of mem compares 512 bytes \(memequal\)"
+}
+
+func f3(x, y small) bool {
+	return x == y
+}
+
+func f4(x, y [16]int) bool {
+	return x == y // ERROR "comparison of \[16\]int compares 128 bytes \(memequal\)"
+}
+
+func f5() map[big]bool {
+	return make(map[big]bool) // ERROR "map key big compares 272 bytes \(generated eq func, 3 comparisons\)"
+}
+
+func f6() map[mem]int {
+	return map[mem]int{} // ERROR "map key mem compares 512 bytes \(memequal\)"
+}
+
+func f7() map[small]int {
+	return make(map[small]int, 10)
+}
+
+func f8(x, y strs) bool {
+	return x == y // ERROR "comparison of strs compares 256 bytes \(generated eq func, 32 comparisons in a loop\)"
+}
@@ -0,0 +1,59 @@
+// errorcheck -0 -d=errchecks
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=errchecks reports nil error checks removed
+// after inlining.
+
+package p
+
+import "errors"
+
+var errNeg = errors.New("negative")
+
+func double(x int) (int, error) {
+	return x * 2, nil
+}
+
+func parse(x int) (int, error) {
+	if x < 0 {
+		return 0, errNeg
+	}
+	return x, nil
+}
+
+func f1(x int) int {
+	v, err := double(x)
+	if err != nil { // ERROR "err != nil is always false; branch eliminated"
+		return -1
+	}
+	return v
+}
+
+func f2(x int) int {
+	if _, err := double(x); err == nil { // ERROR "err == nil is always true; branch eliminated"
+		return 1
+	}
+	return 0
+}
+
+func f3(x int) int {
+	v, err := parse(x)
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+func f4() error {
+	var err error
+	if err == nil { // ERROR "err == nil is always true; branch eliminated"
+		err = errNeg
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
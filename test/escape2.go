@@ -1651,13 +1651,13 @@ func fieldFlowTracking() {
 
 func slicebytetostring0() {
 	b := make([]byte, 20) // ERROR "make\(\[\]byte, 20\) does not escape$"
-	s := string(b)        // ERROR "string\(b\) does not escape$"
+	s := string(b)        // ERROR "string\(b\) does not copy: b is not used afterwards$"
 	_ = s
 }
 
 func slicebytetostring1() {
 	b := make([]byte, 20) // ERROR "make\(\[\]byte, 20\) does not escape$"
-	s := string(b)        // ERROR "string\(b\) does not escape$"
+	s := string(b)        // ERROR "string\(b\) does not copy: b is not used afterwards$"
 	s1 := s[0:1]
 	_ = s1
 }
@@ -1694,7 +1694,7 @@ func addstr1() {
 func addstr2() {
 	b := make([]byte, 20) // ERROR "make\(\[\]byte, 20\) does not escape$"
 	s0 := "a"
-	s := string(b) + s0 // ERROR "string\(b\) \+ s0 does not escape$" "string\(b\) does not escape$"
+	s := string(b) + s0 // ERROR "string\(b\) \+ s0 does not escape$" "string\(b\) does not copy: b is not used afterwards$"
 	_ = s
 }
 
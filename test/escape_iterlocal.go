@@ -0,0 +1,118 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test escape analysis for variables declared outside a loop whose
+// values never survive an iteration.
+
+package escape
+
+type T struct{ x int }
+
+func (t *T) get() int { return t.x } // ERROR "t does not escape"
+
+func addrOf(xs []int) int { // ERROR "xs does not escape"
+	var p *T
+	s := 0
+	for _, v := range xs {
+		t := T{v}
+		p = &t
+		s += p.get()
+	}
+	return s
+}
+
+func alloc(n int) int {
+	var p *T
+	s := 0
+	for i := 0; i < n; i++ {
+		p = &T{i} // ERROR "&T{...} does not escape"
+		s += p.x
+	}
+	return s
+}
+
+func nested(n int) int {
+	var p, q *T
+	s := 0
+	for i := 0; i < n; i++ {
+		p = &T{i} // ERROR "&T{...} does not escape"
+		for j := 0; j < n; j++ {
+			q = &T{j} // ERROR "&T{...} does not escape"
+			s += p.x + q.x
+		}
+	}
+	return s
+}
+
+func inner(n int) int {
+	var p *T
+	s := 0
+	for i := 0; i < n; i++ {
+		p = nil
+		for j := 0; j < n; j++ {
+			p = &T{j} // ERROR "&T{...} escapes to heap"
+		}
+		s += p.x
+	}
+	return s
+}
+
+func usedAfter(xs []int) *T { // ERROR "xs does not escape"
+	var p *T
+	for _, v := range xs {
+		p = &T{v} // ERROR "&T{...} escapes to heap"
+	}
+	return p
+}
+
+func usedBefore(xs []int) int { // ERROR "xs does not escape"
+	var p *T
+	s := 0
+	for _, v := range xs {
+		if p != nil {
+			s += p.x
+		}
+		p = &T{v} // ERROR "&T{...} escapes to heap"
+	}
+	return s
+}
+
+func conditional(xs []int) int { // ERROR "xs does not escape"
+	var p *T
+	s := 0
+	for _, v := range xs {
+		if v > 0 {
+			p = &T{v} // ERROR "&T{...} escapes to heap"
+		}
+		s += p.x
+	}
+	return s
+}
+
+func captured(xs []int) int { // ERROR "xs does not escape"
+	var p *T
+	s := 0
+	get := func() int { return p.x } // ERROR "func literal does not escape"
+	for _, v := range xs {
+		p = &T{v} // ERROR "&T{...} escapes to heap"
+		s += get()
+	}
+	return s
+}
+
+func jumped(xs []int) int { // ERROR "xs does not escape"
+	var p *T
+	s := 0
+	for _, v := range xs {
+		if v < 0 {
+			goto use
+		}
+		p = &T{v} // ERROR "&T{...} escapes to heap"
+	use:
+		s += p.x
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+func Count(format string, args ...interface{}) int { // ERROR "format does not escape" "args does not escape"
+	n := len(format)
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case int:
+			n += arg
+		case string:
+			n += len(arg)
+		}
+	}
+	return n
+}
+
+var sink []interface{}
+
+func Keep(args ...interface{}) { // ERROR "leaking param: args"
+	sink = args
+}
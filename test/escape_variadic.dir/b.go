@@ -0,0 +1,15 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+func F(i int, s string, p [4]int) int { // ERROR "s does not escape"
+	return a.Count("%d %s %v", i, s, p) // ERROR "... argument does not escape" "i does not escape" "s does not escape" "p does not escape"
+}
+
+func G(i int) {
+	a.Keep(i) // ERROR "... argument escapes to heap" "i escapes to heap"
+}
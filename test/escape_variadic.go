@@ -0,0 +1,12 @@
+// errorcheckdir -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that the arguments of a call to an imported variadic
+// ...interface{} function, and their backing array, are stack
+// allocated when the function's export data says it doesn't retain
+// them.
+
+package ignored
@@ -0,0 +1,107 @@
+// errorcheck -0 -d=exhaustive
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=exhaustive reports switches on enum-like types that
+// are missing some of the type's constants.
+
+package p
+
+import "time"
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+	Crimson = Red
+)
+
+type Op string
+
+const (
+	Add Op = "+"
+	Sub Op = "-"
+)
+
+func missing(c Color) int {
+	switch c { // ERROR "switch on Color is missing cases for Blue"
+	case Red:
+		return 1
+	case Green:
+		return 2
+	}
+	return 0
+}
+
+func complete(c Color) int {
+	switch c {
+	case Crimson, Green:
+		return 1
+	case Blue:
+		return 2
+	}
+	return 0
+}
+
+func withDefault(c Color) int {
+	switch c {
+	case Red:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func literal(c Color) int {
+	switch c {
+	case 0, 1, 2:
+		return 1
+	}
+	return 0
+}
+
+func nonConstant(c, d Color) int {
+	switch c {
+	case d:
+		return 1
+	}
+	return 0
+}
+
+func strings(op Op) int {
+	switch op { // ERROR "switch on Op is missing cases for Add, Sub"
+	}
+	switch op {
+	case Add, Sub:
+		return 1
+	}
+	return 0
+}
+
+func imported(d time.Duration) int {
+	switch d { // ERROR "switch on time.Duration is missing cases for time.Hour, time.Microsecond, time.Millisecond, time.Minute, time.Nanosecond"
+	case time.Second:
+		return 1
+	}
+	return 0
+}
+
+func untyped(i int) int {
+	switch i {
+	case 1:
+		return 1
+	}
+	return 0
+}
+
+func tagless(c Color) int {
+	switch {
+	case c == Red:
+		return 1
+	}
+	return 0
+}
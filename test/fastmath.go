@@ -0,0 +1,84 @@
+// errorcheck -0 -d=ssa/fastmath/debug=1
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that //go:fastmath rewrites are applied and reported
+// only in functions marked with the directive.
+
+package p
+
+//go:fastmath
+func div(x float64) float64 {
+	return x / 3 // ERROR "fastmath: replaced division by constant with multiplication by reciprocal"
+}
+
+//go:fastmath
+func div32(x float32) float32 {
+	return x / 10 // ERROR "fastmath: replaced division by constant with multiplication by reciprocal"
+}
+
+//go:fastmath
+func divExact(x float64) float64 {
+	return x / 4 // exact without fastmath, so not reported
+}
+
+//go:fastmath
+func divZero(x float64) float64 {
+	return x / 0
+}
+
+//go:fastmath
+func addConst(x float64) float64 {
+	return x + 1.5 + 2.25 // ERROR "fastmath: reassociated constant addition"
+}
+
+//go:fastmath
+func mulConst(x float32) float32 {
+	return 2.5 * x * 4 // ERROR "fastmath: reassociated constant multiplication"
+}
+
+//go:fastmath
+func mulOverflow(x float64) float64 {
+	return x * 1e300 * 1e300
+}
+
+//go:fastmath
+func factor(a, b, c float64) float64 {
+	return a*b + c*a // ERROR "fastmath: factored common multiplicand out of sum"
+}
+
+//go:fastmath
+func factorShared(a, b, c float64) (float64, float64) {
+	ab := a * b
+	return ab + a*c, ab
+}
+
+//go:fastmath
+func addZero(x float64) float64 {
+	return x + 0 // ERROR "fastmath: dropped addition of zero"
+}
+
+//go:fastmath
+func subFromZero(x float64) float64 {
+	return 0 - x // ERROR "fastmath: replaced subtraction from zero with negation"
+}
+
+//go:fastmath
+func negSub(a, b float64) float64 {
+	return -(a - b) // ERROR "fastmath: replaced negated difference with reversed difference"
+}
+
+func strict(x float64) float64 {
+	return (x+1.5)/3 + 0
+}
+
+func inlined(x float64) float64 {
+	return x / 7
+}
+
+//go:fastmath
+func caller(x float64) float64 {
+	return inlined(x)
+}
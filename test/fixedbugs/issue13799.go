@@ -156,13 +156,14 @@ func recur1(j int, s *str) { // ERROR "s does not escape"
 func test5(iter int) {
 
 	const maxI = 500
-	var x int // ERROR "moved to heap: x$"
+	var x int
 	m := &x
 
 	var fn *str
 	for i := 0; i < maxI; i++ {
-		// var fn *str // this makes it work, because fn stays off heap
-		fn = &str{m} // ERROR "&str{...} escapes to heap"
+		// fn is assigned before it is used in each iteration, so
+		// it stays off heap as if declared here (see test6).
+		fn = &str{m} // ERROR "&str{...} does not escape"
 		recur1(0, fn)
 	}
 
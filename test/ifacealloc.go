@@ -0,0 +1,47 @@
+// errorcheck -0 -d=ifacealloc
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=ifacealloc reports the interface conversions that
+// allocate, and how many each function has.
+
+package p
+
+var sink interface{}
+
+type point struct{ x, y int }
+
+type stringer interface{ String() string }
+
+func (p point) String() string { return "" }
+
+var global = point{1, 2}
+
+func f(i int, s string, b []byte, p point, q *point) { // ERROR "f has 4 interface conversions that allocate"
+	sink = i // ERROR "interface conversion of int allocates 8 bytes"
+	sink = s // ERROR "interface conversion of string allocates 16 bytes"
+	sink = b // ERROR "interface conversion of \[\]byte allocates 24 bytes"
+	sink = p // ERROR "interface conversion of point allocates 16 bytes"
+
+	// Pointers, zero-sized and byte-sized values and read-only
+	// data don't allocate.
+	sink = q
+	sink = struct{}{}
+	sink = true
+	sink = byte(i)
+	sink = "constant"
+	sink = 42
+}
+
+func g(p point) string {
+	// Conversions that don't escape use the stack.
+	var s stringer = p
+	return s.String()
+}
+
+func h(p point) { // ERROR "h has 1 interface conversions that allocate"
+	var s stringer = p // ERROR "interface conversion of point allocates 16 bytes"
+	sink = s
+}
@@ -0,0 +1,24 @@
+// errorcheck -0 -m -O1
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -O1 only inlines functions little bigger than a call.
+
+package foo
+
+func small(x int) int { // ERROR "can inline small"
+	return x + 1
+}
+
+func medium(x, y int) int {
+	if x > y {
+		return x*y + x - y
+	}
+	return (x + y) * (x - y) / (x | 1)
+}
+
+func f(x, y int) int {
+	return small(x) + medium(x, y) // ERROR "inlining call to small"
+}
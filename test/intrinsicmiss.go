@@ -0,0 +1,38 @@
+// errorcheck -0 -d=intrinsics
+
+//go:build amd64
+// +build amd64
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Check reporting of calls that are intrinsified only elsewhere.
+
+package p
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+func f1(x uint64) uint64 {
+	return bits.Reverse64(x) // ERROR "call to math/bits.Reverse64 not intrinsified: intrinsic only on arm64"
+}
+
+func f2(x, y float64) float64 {
+	return math.Copysign(x, y) // ERROR "call to math.Copysign not intrinsified: intrinsic only on ppc64, ppc64le, riscv64, wasm"
+}
+
+func f3() func(uint64) int {
+	return bits.TrailingZeros64 // ERROR "function value math/bits.TrailingZeros64 is called out of line, not intrinsified"
+}
+
+func f4(x uint64) int {
+	return bits.TrailingZeros64(x) // intrinsified, not reported
+}
+
+func f5(b []byte) uint32 {
+	return binary.BigEndian.Uint32(b) // intrinsified, not reported
+}
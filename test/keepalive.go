@@ -0,0 +1,84 @@
+// errorcheck -0 -d=keepalive
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=keepalive reports calls passed a uintptr derived
+// from a pointer that is dead during the call.
+
+package p
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+type T struct{ buf [64]byte }
+
+//go:noinline
+func use(u uintptr) {}
+
+//go:noinline
+func newT() *T { return new(T) }
+
+func f1(p *T) {
+	u := uintptr(unsafe.Pointer(p))
+	use(u) // ERROR "p may be garbage collected during call to use that is passed a uintptr derived from it; call runtime.KeepAlive\(p\) after the call"
+}
+
+func f2(p *T) {
+	u := uintptr(unsafe.Pointer(&p.buf[8])) + 4
+	syscall.Syscall(0, u, 0, 0) // ERROR "p may be garbage collected during call to syscall.Syscall"
+}
+
+func f3() {
+	q := newT()
+	u := uintptr(unsafe.Pointer(q))
+	use(u) // ERROR "q may be garbage collected during call to use"
+}
+
+func f4(p *T) {
+	u := uintptr(unsafe.Pointer(p))
+	use(u)
+	runtime.KeepAlive(p)
+}
+
+func f5(p *T) byte {
+	u := uintptr(unsafe.Pointer(p))
+	use(u)
+	return p.buf[0]
+}
+
+func f6(p *T) {
+	// The compiler keeps p alive for calls to assembly functions.
+	syscall.Syscall(0, uintptr(unsafe.Pointer(p)), 0, 0)
+}
+
+func f7(p *T, n int) {
+	for i := 0; i < n; i++ {
+		use(uintptr(unsafe.Pointer(p)))
+	}
+}
+
+func f8(p *T, c bool) {
+	u := uintptr(unsafe.Pointer(p))
+	use(u)
+	if c {
+		println(p)
+	}
+}
+
+var global *T
+
+func f9(p *T) {
+	global = p
+	use(uintptr(unsafe.Pointer(p)))
+}
+
+func f10() {
+	// The address of a variable: t's own liveness keeps it alive.
+	var t T
+	use(uintptr(unsafe.Pointer(&t)))
+}
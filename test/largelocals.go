@@ -0,0 +1,32 @@
+// errorcheck -0 -d=largelocals
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=largelocals reports values moved to the heap
+// because they are too large for the stack.
+
+package p
+
+type big [1 << 21]int64
+
+//go:noinline
+func use(p *big) {}
+
+func f() {
+	var x big // ERROR "x moved to heap: too large for stack \(16777216 bytes\)"
+	use(&x)
+	p := new(big) // ERROR "new\(big\) moved to heap: too large for stack \(16777216 bytes\)"
+	use(p)
+	s := make([]byte, 100000) // ERROR "make\(\[\]byte, 100000\) moved to heap: too large for stack \(100000 bytes\)"
+	_ = s
+	t := make([]byte, 1000)
+	_ = t
+}
+
+//go:stacklocals
+func g() {
+	var x big
+	use(&x)
+}
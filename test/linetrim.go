@@ -0,0 +1,30 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Verify that a line directive without a file name, which keeps the
+// current one, reports the same file as the positions before it.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func file() string {
+	_, f, _, ok := runtime.Caller(1)
+	if !ok {
+		panic("runtime.Caller(1) failed")
+	}
+	return f
+}
+
+func main() {
+	want := file()
+	/*line :100:1*/ if got := file(); got != want {
+		panic(fmt.Sprintf("got %s; want %s", got, want))
+	}
+}
@@ -0,0 +1,38 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests that //go:linkname targets in imported packages are checked
+// against the local declarations.
+
+package p
+
+import (
+	"strings"
+	_ "unsafe"
+)
+
+var _ strings.Builder
+
+//go:linkname compare strings.Compare
+func compare(a, b string) int
+
+//go:linkname reset strings.(*Builder).Reset
+func reset(b *strings.Builder)
+
+//go:linkname badCompare strings.Compare
+func badCompare(a, b string) bool // ERROR "//go:linkname badCompare refers to strings.Compare, which has type func\(a string, b string\) int, not func\(a string, b string\) bool"
+
+//go:linkname badGrow strings.(*Builder).Grow
+func badGrow(b strings.Builder, n int) // ERROR "//go:linkname badGrow refers to strings.\(\*Builder\).Grow, which has type func\(b \*strings.Builder, n int\), not func\(b strings.Builder, n int\)"
+
+//go:linkname compareVar strings.Compare
+var compareVar func(a, b string) int // ERROR "//go:linkname compareVar refers to strings.Compare, which is not a variable"
+
+//go:linkname missing strings.Missing
+func missing() // ERROR "//go:linkname missing refers to strings.Missing, which is not declared"
+
+//go:linkname unexported strings.notExported
+func unexported()
@@ -0,0 +1,78 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test elision of the atomic operations of mutexes that only the
+// function declaring them can use.
+
+package p
+
+import "sync"
+
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func count(xs []int) int { // ERROR "xs does not escape"
+	var c counter
+	for _, x := range xs {
+		c.mu.Lock() // ERROR "elided atomic operations of local mutex c.mu"
+		c.n += x
+		c.mu.Unlock() // ERROR "elided atomic operations of local mutex c.mu"
+	}
+	return c.n
+}
+
+func plain() int {
+	var mu sync.Mutex
+	mu.Lock() // ERROR "elided atomic operations of local mutex mu"
+	n := 1
+	mu.Unlock() // ERROR "elided atomic operations of local mutex mu"
+	return n
+}
+
+func deferred() int {
+	var mu sync.Mutex // ERROR "moved to heap: mu"
+	mu.Lock()
+	defer mu.Unlock()
+	return 1
+}
+
+var sink *counter
+
+func shared() {
+	var c counter // ERROR "moved to heap: c"
+	sink = &c
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func lockAll(mus []*sync.Mutex) { // ERROR "leaking param content: mus"
+	for _, mu := range mus {
+		mu.Lock()
+	}
+}
+
+func passed() {
+	var mu sync.Mutex           // ERROR "moved to heap: mu"
+	lockAll([]*sync.Mutex{&mu}) // ERROR "\[\]\*sync.Mutex{...} does not escape"
+	mu.Unlock()
+}
+
+func captured() {
+	var mu sync.Mutex // ERROR "moved to heap: mu"
+	mu.Lock()
+	func() { // ERROR "func literal does not escape"
+		mu.Unlock()
+	}()
+}
+
+func goroutine() {
+	var mu sync.Mutex // ERROR "moved to heap: mu"
+	mu.Lock()
+	go mu.Unlock()
+}
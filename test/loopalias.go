@@ -0,0 +1,83 @@
+// errorcheck -0 -d=loopalias
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=loopalias reports references to loop variables that
+// outlive an iteration, and appends to a slice being ranged over.
+
+package p
+
+func rangeAppend(xs []int) []*int {
+	var ps []*int
+	for _, v := range xs {
+		ps = append(ps, &v) // ERROR "loop variable v is shared by all iterations, but a reference to it outlives the iteration"
+	}
+	return ps
+}
+
+func rangeOuter(xs []int) int {
+	var p *int
+	for _, v := range xs {
+		p = &v // ERROR "loop variable v is shared by all iterations"
+	}
+	return *p
+}
+
+func rangeGo(xs []int) {
+	for i := range xs {
+		go func() {
+			println(i) // ERROR "loop variable i is shared by all iterations"
+		}()
+	}
+}
+
+var sink *int
+
+func forClause(n int) {
+	for i := 0; i < n; i++ {
+		if i == 3 {
+			sink = &i // ERROR "loop variable i is shared by all iterations"
+		}
+	}
+}
+
+func local(xs []int) {
+	for _, v := range xs {
+		p := &v
+		println(*p)
+	}
+}
+
+func localClosure(xs []int) {
+	for _, v := range xs {
+		f := func() int { return v }
+		println(f())
+	}
+}
+
+func copied(xs []int) []*int {
+	var ps []*int
+	for _, v := range xs {
+		v := v
+		ps = append(ps, &v)
+	}
+	return ps
+}
+
+func grow(s []int) []int {
+	for _, x := range s {
+		if x > 0 {
+			s = append(s, x-1) // ERROR "append to s, which is being ranged over; the loop does not visit the appended elements"
+		}
+	}
+	return s
+}
+
+func other(s, t []int) []int {
+	for _, x := range s {
+		t = append(t, x)
+	}
+	return t
+}
@@ -0,0 +1,58 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that string concatenation loops are rewritten
+// to use a growable buffer.
+
+package p
+
+func f1(xs []string) string { // ERROR "xs does not escape"
+	s := ""
+	for _, x := range xs { // ERROR "string concatenation to s in loop uses a growable buffer" "string\(.autotmp_[0-9]+\) escapes to heap"
+		s += x
+	}
+	return s
+}
+
+func f2(n int) string {
+	s := "a"
+	for i := 0; i < n; i++ { // ERROR "string concatenation to s in loop uses a growable buffer" "string\(.autotmp_[0-9]+\) escapes to heap"
+		if i%2 == 0 {
+			s = s + "x" + "y"
+		}
+	}
+	return s
+}
+
+func f3(xs []string) string { // ERROR "leaking param: xs to result ~r0 level=1"
+	s := ""
+	for _, x := range xs {
+		s += x
+		println(len(s)) // s is read within the loop
+	}
+	return s
+}
+
+func f4(xs []string) (s string) { // ERROR "leaking param: xs to result s level=1"
+	for _, x := range xs {
+		s += x // s is a result parameter
+	}
+	return
+}
+
+func f5(xs []string) string { // ERROR "leaking param: xs to result ~r0 level=1"
+	s := ""
+outer:
+	for _, x := range xs {
+		for i := 0; i < 2; i++ {
+			if x == "" {
+				break outer
+			}
+			s += x
+		}
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test string concatenation loops, which the compiler
+// rewrites to use a growable buffer.
+
+package main
+
+type name string
+
+func join(xs []string) string {
+	s := "<"
+	for _, x := range xs {
+		s += x
+	}
+	return s
+}
+
+func repeat(x name, n int) name {
+	var s name
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			s = s + x + "-"
+		} else {
+			s += x
+		}
+		if i > 100 {
+			break
+		}
+	}
+	return s
+}
+
+func main() {
+	if got := join(nil); got != "<" {
+		panic(got)
+	}
+	if got := join([]string{"", ""}); got != "<" {
+		panic(got)
+	}
+	if got := join([]string{"a", "", "bc"}); got != "<abc" {
+		panic(got)
+	}
+	if got := repeat("x", 0); got != "" {
+		panic(got)
+	}
+	if got := repeat("x", 3); got != "x-xx-" {
+		panic(got)
+	}
+	if got := repeat("", 3); got != "--" {
+		panic(got)
+	}
+	if got := repeat("ab", 1000); len(got) != 255 {
+		panic(len(got))
+	}
+}
@@ -0,0 +1,117 @@
+// errorcheck -0 -d=loopfusion=2
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that adjacent loops are fused, and loop nests interchanged,
+// only when it is safe.
+
+package p
+
+func pipeline(a, b []float64) []float64 {
+	t := make([]float64, len(a))
+	out := make([]float64, len(a))
+	for i := range a {
+		t[i] = a[i] * 2
+	}
+	for i, v := range t { // ERROR "fused loop into loop at .*:15"
+		out[i] = v + b[i]
+	}
+	return out
+}
+
+func counted(a []int, n int) {
+	u := make([]int, n)
+	for i := 0; i < n; i++ {
+		u[i] = a[i] + 1
+	}
+	for j := 0; j < n; j++ { // ERROR "fused loop into loop at .*:26"
+		if u[j] > 0 {
+			a[j] = u[j] * u[j]
+		}
+	}
+	for k, x := range u { // ERROR "fused loop into loop at .*:26"
+		a[k] -= x
+	}
+}
+
+func overlap(a, b []int) {
+	for i := range a {
+		a[i]++
+	}
+	for i := range b { // ERROR "cannot fuse loop with loop at .*: a and b may overlap"
+		b[i] = a[i]
+	}
+}
+
+func aliased(a []int) []int {
+	t := make([]int, len(a))
+	u := t
+	for i := range a {
+		t[i] = a[i]
+	}
+	for i := range a { // ERROR "cannot fuse loop with loop at .*: t and u may overlap"
+		a[i] = t[i] + u[i]
+	}
+	return u
+}
+
+func call(a []int) {
+	for i := range a {
+		a[i]++
+	}
+	for i := range a { // ERROR "cannot fuse loop: body contains a function call"
+		use(a[i])
+	}
+}
+
+func use(int)
+
+func shift(a []int, s int) {
+	for i := range a {
+		a[i] *= 3
+	}
+	for i := range a { // ERROR "cannot fuse loop: body contains a shift that may panic"
+		a[i] <<= s
+	}
+	for i := range a {
+		a[i] /= 2
+	}
+}
+
+func other(a []int) {
+	for len(a) > 0 {
+		a = a[1:]
+	}
+	for i := range a {
+		a[i] = 0
+	}
+}
+
+func transpose(a [4][4]float64, s float64) [4][4]float64 {
+	var r [4][4]float64
+	for i := 0; i < 4; i++ { // ERROR "interchanged loops"
+		for j := 0; j < 4; j++ {
+			r[j][i] = a[j][i] * s
+		}
+	}
+	return r
+}
+
+func rows(a [4][4]float64) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			a[i][j] = a[j][i]
+		}
+	}
+}
+
+func conflict(a [4][4]float64) [4][4]float64 {
+	for i := 0; i < 4; i++ { // ERROR "cannot interchange loops: a is accessed by more than one iteration"
+		for j := 0; j < 4; j++ {
+			a[j][i] = a[j][i] + a[i][j]
+		}
+	}
+	return a
+}
@@ -0,0 +1,151 @@
+// run -gcflags=-d=loopfusion
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that fused and interchanged loops compute the same results
+// as the original ones, including when the fused loop's guard fails.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func pipeline(a, b []float64) []float64 {
+	t := make([]float64, len(a))
+	out := make([]float64, len(a))
+	for i := range a {
+		t[i] = a[i] * 2
+	}
+	for i, v := range t {
+		out[i] = v + b[i]
+	}
+	return out
+}
+
+func counted(a []int, n int) []int {
+	u := make([]int, n)
+	for i := 0; i < n; i++ {
+		u[i] = a[i] + 1
+	}
+	for j := 0; j < n; j++ {
+		if u[j] > 2 {
+			a[j] = u[j] * u[j]
+		}
+	}
+	for k, x := range u {
+		a[k] -= x
+	}
+	return a
+}
+
+func arrays(a [5]int) [5]int {
+	var t [5]int
+	for i := range a {
+		t[i] = a[i] * 10
+	}
+	for i, x := range t {
+		a[i] += x
+	}
+	for i := 0; i < 5; i++ {
+		a[i] = t[i] - a[i]
+	}
+	return a
+}
+
+func noKey(a []int) []int {
+	t := make([]int, len(a))
+	for _, x := range a {
+		y := x * 2
+		_ = y
+	}
+	for i := range t {
+		t[i] = a[i] + 1
+	}
+	return t
+}
+
+func short(a []int) (t []int, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = e.(error)
+		}
+	}()
+	t = make([]int, len(a))
+	u := make([]int, 2)
+	for i := range a {
+		t[i] = a[i] + 1
+	}
+	for i := range a {
+		u[i] = t[i] * 2
+	}
+	return
+}
+
+func transpose(a [3][4]float64, s float64) [4][3]float64 {
+	var r [4][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 4; j++ {
+			r[j][i] = a[i][j] * s
+		}
+	}
+	return r
+}
+
+func scale(a [4][4]int) [4][4]int {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			a[j][i] = a[j][i]*int(i) + j
+		}
+	}
+	return a
+}
+
+func check(name string, got interface{}, want string) {
+	if s := fmt.Sprint(got); s != want {
+		panic(fmt.Sprintf("%s: got %s, want %s", name, s, want))
+	}
+}
+
+func main() {
+	check("pipeline", pipeline([]float64{1, 2, 3}, []float64{10, 20, 30}), "[12 24 36]")
+	check("pipeline long b", pipeline([]float64{1, 2}, []float64{10, 20, 30}), "[12 24]")
+	func() {
+		defer func() {
+			if e := recover(); e == nil || !strings.Contains(fmt.Sprint(e), "index out of range") {
+				panic(fmt.Sprint("pipeline short b: ", e))
+			}
+		}()
+		pipeline([]float64{1, 2, 3}, []float64{10})
+	}()
+
+	check("counted", counted([]int{1, 2, 3, 4}, 4), "[-1 6 12 20]")
+	check("counted n=2", counted([]int{1, 2, 3, 4}, 2), "[-1 6 3 4]")
+	check("arrays", arrays([5]int{1, 2, 3, 4, 5}), "[-1 -2 -3 -4 -5]")
+	check("noKey", noKey([]int{1, 2, 3}), "[2 3 4]")
+
+	t, err := short([]int{1, 2, 3})
+	check("short", t, "[2 3 4]")
+	if err == nil || !strings.Contains(err.Error(), "index out of range [2]") {
+		panic(fmt.Sprint("short: ", err))
+	}
+
+	var a [3][4]float64
+	for i := range a {
+		for j := range a[i] {
+			a[i][j] = float64(i*4 + j)
+		}
+	}
+	check("transpose", transpose(a, 2), "[[0 8 16] [2 10 18] [4 12 20] [6 14 22]]")
+
+	var m [4][4]int
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = i*4 + j
+		}
+	}
+	check("scale", scale(m), "[[0 1 4 9] [1 6 13 22] [2 11 22 35] [3 16 31 48]]")
+}
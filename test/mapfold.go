@@ -0,0 +1,45 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that constant-key lookups into read-only package-level
+// maps are folded.
+
+package p
+
+var names = map[string]int{"a": 1, "b": 2} // ERROR "read-only map names: constant-key lookups folded" "map\[string\]int{...} escapes to heap"
+
+var written = map[string]int{"a": 1} // ERROR "map\[string\]int{...} escapes to heap"
+
+var Exported = map[string]int{"a": 1} // ERROR "map\[string\]int{...} escapes to heap"
+
+var nonconst = map[string][]int{"a": nil} // ERROR "map\[string\]\[\]int{...} escapes to heap"
+
+var varkeys = map[string]int{"a": 1} // ERROR "map\[string\]int{...} escapes to heap"
+
+func f1() int {
+	return names["a"] + len(names)
+}
+
+func f2(k string) int { // ERROR "k does not escape"
+	return names[k] + names["zz"]
+}
+
+func f3() bool {
+	_, ok := names["b"]
+	return ok
+}
+
+func f4() {
+	written["x"] = 2
+}
+
+func f5() int {
+	return written["a"] + Exported["a"] + len(nonconst["a"])
+}
+
+func f6(k string) int { // ERROR "k does not escape"
+	return varkeys[k]
+}
@@ -0,0 +1,44 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that folding lookups into read-only maps
+// preserves their results.
+
+package main
+
+type kind string
+
+var sizes = map[kind]int{"small": 1, "large": 3}
+var names = map[int]string{1: "one", 2: "two"}
+var funcs = map[string]func() int{"one": one, "nil": nil}
+
+func one() int { return 1 }
+
+func main() {
+	if sizes["small"] != 1 || sizes["large"] != 3 || sizes["medium"] != 0 || len(sizes) != 2 {
+		panic("bad sizes")
+	}
+	if v, ok := sizes["medium"]; ok || v != 0 {
+		panic("bad sizes lookup")
+	}
+	if v, ok := names[2]; !ok || v != "two" {
+		panic("bad names lookup")
+	}
+	k := 1
+	if names[k] != "one" {
+		panic("bad dynamic lookup")
+	}
+	if funcs["one"]() != 1 || funcs["nil"] != nil || funcs["none"] != nil {
+		panic("bad funcs")
+	}
+	n := 0
+	for k, v := range names {
+		n += k + len(v)
+	}
+	if n != 9 {
+		panic("bad range")
+	}
+}
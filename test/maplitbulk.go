@@ -0,0 +1,64 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that large map literals, which are filled in from static
+// data all at once, hold all their entries.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var ints = map[int]string{
+	26: "z", 1: "a", 2: "b", 3: "c", 4: "d", 5: "e", 6: "f", 7: "g", 8: "h", 9: "i", 10: "j",
+	11: "k", 12: "l", 13: "m", 14: "n", 15: "o", 16: "p", 17: "q", 18: "r", 19: "s", 20: "t",
+	21: "u", 22: "v", 23: "w", 24: "x", 25: "y",
+}
+
+// Keys and elems larger than 128 bytes are stored indirectly.
+type big struct {
+	n int
+	s string
+	_ [128]byte
+}
+
+func bigs() map[big][20]string {
+	return map[big][20]string{
+		{n: 1}: {"1"}, {n: 2}: {"2"}, {n: 3}: {"3"}, {n: 4}: {"4"}, {n: 5}: {"5"},
+		{n: 6}: {"6"}, {n: 7}: {"7"}, {n: 8}: {"8"}, {n: 9}: {"9"}, {n: 10}: {"10"},
+		{n: 11}: {"11"}, {n: 12}: {"12"}, {n: 13}: {"13"}, {n: 14}: {"14"}, {n: 15}: {"15"},
+		{n: 16}: {"16"}, {n: 17}: {"17"}, {n: 18}: {"18"}, {n: 19}: {"19"}, {n: 20}: {"20"},
+		{n: 21}: {"21"}, {n: 22}: {"22"}, {n: 23}: {"23"}, {n: 24}: {"24"}, {n: 25}: {"25"},
+		{s: "x"}: {19: "x"},
+	}
+}
+
+func main() {
+	if len(ints) != 26 {
+		panic(fmt.Sprintf("len(ints) = %d, want 26", len(ints)))
+	}
+	for k, v := range ints {
+		if want := string(rune('a' + k - 1)); v != want {
+			panic(fmt.Sprintf("ints[%d] = %q, want %q", k, v, want))
+		}
+	}
+
+	m := bigs()
+	runtime.GC()
+	if len(m) != 26 {
+		panic(fmt.Sprintf("len(bigs()) = %d, want 26", len(m)))
+	}
+	for i := 1; i <= 25; i++ {
+		if v, want := m[big{n: i}][0], fmt.Sprint(i); v != want {
+			panic(fmt.Sprintf("bigs()[%d] = %q, want %q", i, v, want))
+		}
+	}
+	if v := m[big{s: "x"}]; v[0] != "" || v[19] != "x" {
+		panic(fmt.Sprintf("bigs()[x] = %q", v))
+	}
+}
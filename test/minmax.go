@@ -0,0 +1,121 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that float min/max and clamp compiled without branches
+// keep the NaN and signed zero behavior of the source.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+//go:noinline
+func min64(x, y float64) float64 {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+//go:noinline
+func max64(x, y float64) float64 {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+//go:noinline
+func min32(x, y float32) float32 {
+	m := y
+	if x < y {
+		m = x
+	}
+	return m
+}
+
+//go:noinline
+func max32(x, y float32) float32 {
+	m := x
+	if y > x {
+		m = y
+	}
+	return m
+}
+
+//go:noinline
+func clamp(x, lo, hi float64) float64 {
+	if x < lo {
+		x = lo
+	}
+	if x > hi {
+		x = hi
+	}
+	return x
+}
+
+//go:noinline
+//go:fastminmax
+func minFast(x, y float64) float64 {
+	if x <= y {
+		return x
+	}
+	return y
+}
+
+// sel returns c ? x : y without a float comparison
+// that could itself be compiled as a min or max.
+//go:noinline
+func sel(c bool, x, y float64) float64 {
+	if c {
+		return x
+	}
+	return y
+}
+
+func same(x, y float64) bool {
+	return math.Float64bits(x) == math.Float64bits(y)
+}
+
+func main() {
+	nan := math.NaN()
+	inf := math.Inf(1)
+	negz := math.Copysign(0, -1)
+	vals := []float64{0, negz, 1, -1, 2.5, inf, -inf, nan, math.MaxFloat64, math.SmallestNonzeroFloat64}
+
+	failed := false
+	check := func(what string, got, want float64) {
+		if !same(got, want) {
+			fmt.Printf("%s = %v (%#x), want %v (%#x)\n", what, got, math.Float64bits(got), want, math.Float64bits(want))
+			failed = true
+		}
+	}
+	for _, x := range vals {
+		for _, y := range vals {
+			args := fmt.Sprintf("(%v, %v)", x, y)
+			check("min64"+args, min64(x, y), sel(x < y, x, y))
+			check("max64"+args, max64(x, y), sel(x > y, x, y))
+			check("min32"+args, float64(min32(float32(x), float32(y))), float64(float32(sel(float32(x) < float32(y), x, y))))
+			check("max32"+args, float64(max32(float32(x), float32(y))), float64(float32(sel(float32(y) > float32(x), y, x))))
+			for _, z := range vals {
+				c := sel(x < y, y, x)
+				check(fmt.Sprintf("clamp(%v, %v, %v)", x, y, z), clamp(x, y, z), sel(c > z, z, c))
+			}
+			// Fast min/max only promises one of the arguments,
+			// and the ordinary result for ordered nonzero values.
+			if m := minFast(x, y); !same(m, x) && !same(m, y) {
+				check("minFast"+args, m, x)
+			} else if x != 0 && !math.IsNaN(x) && !math.IsNaN(y) {
+				check("minFast"+args, m, sel(x <= y, x, y))
+			}
+		}
+	}
+	if failed {
+		panic("FAIL")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+//go:mustuse
+func Check() error {
+	return nil
+}
+
+//go:mustuse
+type Builder struct {
+	parts []string
+}
+
+func (b Builder) Add(s string) Builder {
+	b.parts = append(b.parts, s)
+	return b
+}
+
+func (b *Builder) Ptr() *Builder {
+	return b
+}
+
+func (b *Builder) Reset() {
+	b.parts = nil
+}
+
+//go:mustuse
+func (b Builder) Len() int {
+	return len(b.parts)
+}
+
+func Plain() error {
+	return nil
+}
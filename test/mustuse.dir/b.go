@@ -0,0 +1,53 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+//go:mustuse
+func local() (int, error) {
+	return 0, nil
+}
+
+type named a.Builder
+
+func mk() named {
+	return named{}
+}
+
+type I interface {
+	Build() a.Builder
+}
+
+func F(b a.Builder, i I) {
+	a.Check() // ERROR "result of call to a.Check is not used \(a.Check is marked go:mustuse\)"
+	_ = a.Check()
+	if err := a.Check(); err != nil {
+		return
+	}
+	a.Plain()
+
+	b.Add("x") // ERROR "result of call to a.Builder.Add is not used \(a.Builder is marked go:mustuse\)"
+	b = b.Add("x")
+	b.Ptr() // ERROR "a.Builder is marked go:mustuse"
+	b.Reset()
+	b.Len()   // ERROR "a.Builder.Len is marked go:mustuse"
+	i.Build() // ERROR "a.Builder is marked go:mustuse"
+	mk()      // ERROR "named is marked go:mustuse"
+
+	local()                      // ERROR "local is marked go:mustuse"
+	defer local()                // ERROR "local is marked go:mustuse"
+	go local()                   // ERROR "local is marked go:mustuse"
+	for i := 0; i < 1; local() { // ERROR "local is marked go:mustuse"
+		i++
+	}
+	if true {
+		local() // ERROR "local is marked go:mustuse"
+	}
+	func() {
+		local() // ERROR "local is marked go:mustuse"
+	}()
+	_, _ = local()
+}
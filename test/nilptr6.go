@@ -0,0 +1,64 @@
+// run
+
+//go:build !wasm && !aix
+// +build !wasm,!aix
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that nil pointer dereference panics name the field involved.
+
+package main
+
+import (
+	"runtime"
+	"strings"
+)
+
+type T struct {
+	x  int
+	mu int
+}
+
+type W struct {
+	t *T
+}
+
+//go:noinline
+func read(conn *T) int {
+	return conn.mu
+}
+
+//go:noinline
+func write(w *W) {
+	w.t.mu = 3
+}
+
+//go:noinline
+func deref(p *int) int {
+	return *p
+}
+
+func check(name string, f func(), want string) {
+	defer func() {
+		err, ok := recover().(runtime.Error)
+		if !ok {
+			panic(name + ": expected runtime error")
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "runtime error: invalid memory address or nil pointer dereference") {
+			panic(name + ": unexpected error: " + msg)
+		}
+		if !strings.HasSuffix(msg, want) {
+			panic(name + ": got " + msg + ", want suffix " + want)
+		}
+	}()
+	f()
+}
+
+func main() {
+	check("read", func() { read(nil) }, "nil pointer dereference reading field conn.mu")
+	check("write", func() { write(&W{}) }, "nil pointer dereference writing field w.t.mu")
+	check("deref", func() { deref(nil) }, "nil pointer dereference")
+}
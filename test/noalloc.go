@@ -0,0 +1,49 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that the compiler verifies //go:noalloc functions.
+
+package p
+
+var sink interface{}
+
+//go:noinline
+func allocates() *int { return new(int) }
+
+//go:noalloc
+//go:noinline
+func sum(s []int) (n int) {
+	for _, x := range s {
+		n += x
+	}
+	return n
+}
+
+func inlinable(x int) *int {
+	p := new(int)
+	*p = x
+	return p
+}
+
+//go:noalloc
+func bad(s []int, f func(), x int) []int {
+	sink = new(int)     // ERROR "//go:noalloc function bad allocates: calls runtime.newobject"
+	_ = allocates()     // ERROR "//go:noalloc function bad calls allocates, which is not //go:noalloc"
+	f()                 // ERROR "//go:noalloc function bad makes an indirect call"
+	sink = x            // ERROR "//go:noalloc function bad allocates: calls runtime.convT64"
+	return append(s, 1) // ERROR "//go:noalloc function bad allocates: calls runtime.growslice"
+}
+
+//go:noalloc
+func good(s []int) int {
+	var buf [16]int
+	t := buf[:0]
+	t = append(t, s[0])
+	p := inlinable(sum(t))
+	m := make(map[int]int)
+	_ = m[*p]
+	return *p
+}
@@ -0,0 +1,39 @@
+// errorcheck -l -d=noallocpackage
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=noallocpackage makes any value escaping to the heap
+// an error, explaining why it escapes.
+
+package p
+
+type T struct{ a, b int }
+
+var sink interface{}
+
+var global *T
+
+func leak(t *T) {
+	global = t
+}
+
+func f(n int) {
+	x := 0            // ERROR "moved to heap: x, not allowed with -d=noallocpackage\n\tflow: \{heap\} = &x:\n\t  from &x \(address-of\).*\n.*\n\t  from sink = &x \(assign\)"
+	global = &T{a: x} // ERROR "&T{...} escapes to heap, not allowed with -d=noallocpackage"
+	sink = &x
+	_ = make([]int, n) // ERROR "make\(\[\]int, n\) escapes to heap, not allowed with -d=noallocpackage\n\tflow: \{heap\} = &\{storage for make\(\[\]int, n\)\}:\n\t  from make\(\[\]int, n\) \(non-constant size\)"
+	p := &T{}          // ERROR "&T{} escapes to heap, not allowed with -d=noallocpackage\n\tflow: p = &\{storage for &T{}\}:\n.*\n.*\n\tflow: q = p:\n.*\n\tflow: \{heap\} = q:"
+	q := p
+	global = q
+	leak(&T{}) // ERROR "&T{} escapes to heap, not allowed with -d=noallocpackage\n\tflow: \{heap\} = &\{storage for &T{}\}:\n\t  from &T{} \(spill\).*\n\t  from leak\(&T{}\) \(call parameter\)"
+}
+
+func g() int {
+	var buf [8]int
+	t := &T{1, 2}
+	s := buf[:0]
+	s = append(s, t.a)
+	return s[0]
+}
@@ -0,0 +1,73 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test type-checking errors for go:nocompare and go:nohash.
+
+package p
+
+//go:nocompare
+type nc struct {
+	x int
+}
+
+//go:nohash
+type nh struct {
+	x int
+}
+
+type embed struct {
+	a  int
+	nc nc
+}
+
+type arr [2]nc
+
+type ok struct {
+	x int
+}
+
+var a, b nc
+var c, d nh
+var e, f embed
+var g, h arr
+
+var x1 = a == b // ERROR "invalid operation: .*nc is marked go:nocompare"
+var x2 = a != b // ERROR "invalid operation: .*nc is marked go:nocompare"
+var x3 = c == d // nh values can still be compared
+var x4 = e == f // ERROR "invalid operation: .*embed contains nc, which is marked go:nocompare"
+var x5 = g != h // ERROR "invalid operation: .*arr contains nc, which is marked go:nocompare"
+var x6 = interface{}(a) == interface{}(b)
+
+type m1 map[nc]int    // ERROR "invalid map key type nc \(nc is marked go:nocompare\)"
+type m2 map[nh]int    // ERROR "invalid map key type nh \(nh is marked go:nohash\)"
+type m3 map[embed]int // ERROR "invalid map key type embed \(embed contains nc, which is marked go:nocompare\)"
+type m4 map[ok]nh
+type m5 map[int]nc
+
+func f1() {
+	_ = make(map[[1]nh]bool) // ERROR "invalid map key type \[1\]nh \(\[1\]nh contains nh, which is marked go:nohash\)"
+	switch a {               // ERROR "cannot switch on a \(nc is marked go:nocompare\)"
+	}
+	switch c {
+	case d:
+	}
+}
+
+// A type argument for a comparable type parameter may be compared and
+// used as a map key.
+
+func eq[T comparable](x, y T) bool { return x == y }
+
+func keep[T any](x T) T { return x }
+
+type set[K comparable] map[K]bool
+
+var y1 = eq(a, b)       // ERROR "nc does not implement comparable \(nc is marked go:nocompare\)"
+var y2 = eq[nh](c, d)   // ERROR "nh does not implement comparable \(nh is marked go:nohash\)"
+var y3 = eq(ok{}, ok{}) // ok is not marked
+var y4 = keep(a)        // any doesn't allow comparisons
+var y5 set[embed]       // ERROR "embed does not implement comparable \(embed contains nc, which is marked go:nocompare\)"
+var y6 = eq(&a, &b)     // pointers to marked types may be compared
@@ -0,0 +1,28 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that //go:noheaplocals rejects values moved to the heap
+// because they are too large for the stack.
+
+package p
+
+type big [1 << 21]int64
+
+//go:noinline
+func use(p *big) {}
+
+//go:noheaplocals
+func f() {
+	var x big // ERROR "x is too large for stack \(16777216 bytes\) in //go:noheaplocals function f"
+	use(&x)
+	var y [100]int
+	_ = y
+}
+
+//go:noheaplocals
+func g() *big {
+	return new(big) // escapes anyway
+}
@@ -0,0 +1,61 @@
+// errorcheck -0 -d=prefetch=2
+
+//go:build amd64 || arm64 || ppc64 || ppc64le
+// +build amd64 arm64 ppc64 ppc64le
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that loops following a chain of pointers prefetch the next
+// element.
+
+package p
+
+type node struct {
+	val  int
+	next *node
+	prev *node
+}
+
+func sum(l *node) int {
+	s := 0
+	for p := l; p != nil; p = p.next { // ERROR "prefetching p.next"
+		s += p.val
+	}
+	return s
+}
+
+func count(p *node) int {
+	n := 0
+	for p != nil { // ERROR "prefetching p.prev"
+		n++
+		p = p.prev
+	}
+	return n
+}
+
+func last(p *node) *node {
+	for p.next != nil { // not a nil check of the loop variable
+		p = p.next
+	}
+	return p
+}
+
+func other(p, q *node) int {
+	n := 0
+	for p != nil { // follows q, not p
+		n++
+		p = q.next
+	}
+	return n
+}
+
+func skip(p *node) int {
+	n := 0
+	for p != nil { // not updated at the end
+		p = p.next
+		n++
+	}
+	return n
+}
@@ -0,0 +1,55 @@
+// run -gcflags=-d=prefetch
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that loops with prefetches of the next element behave the
+// same, including at the end of the chain, where the next element
+// is nil.
+
+package main
+
+type node struct {
+	val  int
+	next *node
+}
+
+func sum(l *node) int {
+	s := 0
+	for p := l; p != nil; p = p.next {
+		s += p.val
+	}
+	return s
+}
+
+func reverse(p *node) *node {
+	var r *node
+	for p != nil {
+		q := p
+		p = p.next
+		q.next = r
+		r = q
+	}
+	return r
+}
+
+func main() {
+	var l *node
+	if sum(l) != 0 {
+		panic("empty list")
+	}
+	for i := 1; i <= 100; i++ {
+		l = &node{i, l}
+	}
+	if got := sum(l); got != 5050 {
+		panic(got)
+	}
+	l = reverse(l)
+	if l.val != 1 || l.next.val != 2 {
+		panic("reverse")
+	}
+	if got := sum(l); got != 5050 {
+		panic(got)
+	}
+}
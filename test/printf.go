@@ -0,0 +1,71 @@
+// errorcheck -0 -d=printf
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=printf reports Printf-like calls whose format does
+// not match their arguments.
+
+package p
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+type Celsius float64
+
+func (c Celsius) String() string { return fmt.Sprint(float64(c)) }
+
+type Point struct{ X, Y int }
+
+type Custom int
+
+func (Custom) Format(fmt.State, rune) {}
+
+func basic(s string, i int, f float64, b []byte, p *Point, err error) {
+	fmt.Printf("%s %d %g %x %v %+v %t", s, i, f, b, p, *p, true)
+	fmt.Printf("%d", s)     // ERROR "fmt.Printf format %d has arg s of wrong type string"
+	fmt.Printf("%s %d", s)  // ERROR "fmt.Printf format %d reads arg #2, but call has 1 args"
+	fmt.Printf("%s", s, i)  // ERROR "fmt.Printf call needs 1 args but has 2 args"
+	fmt.Printf("%z", i)     // ERROR "fmt.Printf format %z has unknown verb z"
+	fmt.Printf("%*d", s, i) // ERROR "fmt.Printf format %\*d uses non-int s as argument of \*"
+	fmt.Printf("%[2]d %[1]s", s, i)
+	fmt.Printf("%[3]d", s, i) // ERROR "fmt.Printf format %\[3\]d reads arg #3, but call has 2 args"
+	fmt.Printf("%d", p)
+	fmt.Printf("%s", p)     // ERROR "fmt.Printf format %s has arg p of wrong type \*Point"
+	fmt.Printf("%s", basic) // ERROR "fmt.Printf format %s arg basic is a func value, not called"
+	fmt.Printf("100%%")
+	_ = fmt.Sprintf("%s", Celsius(1))
+	_ = fmt.Sprintf("%d %z", Custom(1), Custom(2))
+	_ = fmt.Errorf("wrapped: %w", err)
+	_ = fmt.Errorf("wrapped: %w", s) // ERROR "fmt.Errorf format %w has arg s of wrong type string"
+	fmt.Printf("%w", err)            // ERROR "fmt.Printf does not support error-wrapping directive %w"
+	log.Printf("%s", errors.New("x"))
+	var x int
+	fmt.Sscanf(s, "%d", &x)
+}
+
+func nonConstant(format string, i int) {
+	fmt.Printf(format, i)
+}
+
+func forward(format string, args ...interface{}) {
+	fmt.Printf(format, args...)
+}
+
+func Debugf(format string, args ...interface{}) {}
+
+func wrapper(i int) {
+	Debugf("%s", i) // ERROR "Debugf format %s has arg i of wrong type int"
+}
+
+func viaInterface(l Logger, s string) {
+	l.Logf("%d", s) // ERROR "l.Logf format %d has arg s of wrong type string"
+}
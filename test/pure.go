@@ -0,0 +1,70 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that the compiler verifies //go:pure functions.
+
+package p
+
+var g int
+
+//go:noinline
+func impure() int { return g }
+
+//go:pure
+//go:noinline
+func add(a, b int) int { return a + b }
+
+func inlinable(x int) int { return x * 2 }
+
+//go:pure
+func bad(p *int, s []int, m map[int]int, c chan int, f func()) {
+	g = 1            // ERROR "//go:pure function bad assigns to package-level variable g"
+	*p = 2           // ERROR "//go:pure function bad stores through p, which it did not allocate"
+	s[0] = 3         // ERROR "//go:pure function bad stores through s, which it did not allocate"
+	m[1] = 4         // ERROR "//go:pure function bad stores through m, which it did not allocate"
+	c <- 5           // ERROR "//go:pure function bad sends on a channel"
+	_ = impure()     // ERROR "//go:pure function bad calls impure, which is not //go:pure"
+	f()              // ERROR "//go:pure function bad makes an indirect call"
+	go add(1, 2)     // ERROR "//go:pure function bad starts a goroutine"
+	_ = append(s, 6) // ERROR "//go:pure function bad appends to s, which it did not allocate"
+	copy(s, s[1:])   // ERROR "//go:pure function bad copies to s, which it did not allocate"
+	print(1)         // ERROR "//go:pure function bad writes to standard error"
+	println(2)       // ERROR "//go:pure function bad writes to standard error"
+}
+
+type T struct{ x, y int }
+
+//go:pure
+func (t *T) set(x int) {
+	t.x = x // ERROR "//go:pure function \(\*T\).set stores through t, which it did not allocate"
+}
+
+//go:pure
+func escapes() *int {
+	var x int
+	x = 1 // ERROR "//go:pure function escapes assigns to x, which escapes to the heap"
+	return &x
+}
+
+//go:pure
+func good(n int, s []int) ([]int, *T) {
+	r := make([]int, 0, n)
+	for _, x := range s {
+		r = append(r, add(x, inlinable(x)))
+	}
+	t := &T{x: 1}
+	t.y = 2
+	var a [4]int
+	p := &a
+	p[1] = len(r)
+	m := map[int]int{}
+	m[1] = p[1]
+	delete(m, 2)
+	b := []byte("hello")
+	b[0] = 'H'
+	copy(r, s)
+	return r, t
+}
@@ -236,6 +236,31 @@ func testarray2() {
 	}
 }
 
+// test that changes made to an array variable during the loop
+// are not seen by the range, whichever way they are made
+func testarray3() {
+	a := makearray()
+	s := 0
+	for i, v := range a {
+		a[4-i] = 0
+		s += v
+	}
+	b := makearray()
+	for _, v := range b {
+		b = [5]int{}
+		s += v
+	}
+	c := makearray()
+	i := 0
+	for i, c[0] = range c {
+		s += c[0]
+	}
+	if s != 45 || i != 4 || c[0] != 5 {
+		println("wrong sum ranging over changed arrays", s, i, c[0])
+		panic("fail")
+	}
+}
+
 func makearrayptr() *[5]int {
 	nmake++
 	return &[5]int{1, 2, 3, 4, 5}
@@ -477,6 +502,7 @@ func main() {
 	testarray()
 	testarray1()
 	testarray2()
+	testarray3()
 	testarrayptr()
 	testarrayptr1()
 	testarrayptr2()
@@ -0,0 +1,35 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that reflect.TypeOf on values of concrete type, which the
+// compiler resolves statically, still evaluates its argument.
+
+package main
+
+import "reflect"
+
+type T struct{ a, b int }
+
+var calls int
+
+func f() T {
+	calls++
+	return T{1, 2}
+}
+
+func main() {
+	if reflect.TypeOf(f()) != reflect.TypeOf(T{}) || calls != 1 {
+		panic("bad TypeOf of call")
+	}
+	var p *T
+	if reflect.TypeOf(p).Elem().Name() != "T" {
+		panic("bad TypeOf of nil pointer")
+	}
+	var x interface{} = 1.5
+	if reflect.TypeOf(x).Kind() != reflect.Float64 {
+		panic("bad TypeOf of interface")
+	}
+}
@@ -22,6 +22,7 @@ func main() {
 	p9()
 	p10()
 	p11()
+	p12()
 }
 
 var gx []int
@@ -165,3 +166,16 @@ func p11() {
 	p := new(bool)
 	p, *p = i.(*bool)
 }
+
+//go:noinline
+func swap(x, y [4]int) ([4]int, [4]int) { return y, x }
+
+// Results stored straight into the variables they are assigned to.
+func p12() {
+	x, y := [4]int{1}, [4]int{2}
+	x, y = swap(x, y)
+	if x[0] != 2 || y[0] != 1 {
+		fmt.Printf("x, y = swap(x, y); got=(%v, %v); want=([2 0 0 0], [1 0 0 0])\n", x, y)
+		panic("failed")
+	}
+}
@@ -1725,9 +1725,9 @@ func (t *test) wantedErrors(file, short string) (errs []wantedError) {
 
 const (
 	// Regexp to match a single opcode check: optionally begin with "-" (to indicate
-	// a negative check), followed by a string literal enclosed in "" or ``. For "",
-	// backslashes must be handled.
-	reMatchCheck = `-?(?:\x60[^\x60]*\x60|"(?:[^"\\]|\\.)*")`
+	// a negative check), followed by a string literal enclosed in "" or ``, and
+	// optionally followed by a count in braces. For "", backslashes must be handled.
+	reMatchCheck = `-?(?:\x60[^\x60]*\x60|"(?:[^"\\]|\\.)*")(?:\{\d+\})?`
 )
 
 var (
@@ -1737,7 +1737,8 @@ var (
 	// Regexp to extract an architecture check: architecture name (or triplet),
 	// followed by semi-colon, followed by a comma-separated list of opcode checks.
 	// Extraneous spaces are ignored.
-	rxAsmPlatform = regexp.MustCompile(`(\w+)(/\w+)?(/\w*)?\s*:\s*(` + reMatchCheck + `(?:\s*,\s*` + reMatchCheck + `)*)`)
+	// A variant followed by "+" stands for it and the later variants.
+	rxAsmPlatform = regexp.MustCompile(`(\w+)(/\w+\+?)?(/\w*\+?)?\s*:\s*(` + reMatchCheck + `(?:\s*,\s*` + reMatchCheck + `)*)`)
 
 	// Regexp to extract a single opcoded check
 	rxAsmCheck = regexp.MustCompile(reMatchCheck)
@@ -1758,6 +1759,55 @@ var (
 		"wasm":    {},
 		"riscv64": {},
 	}
+
+	// Register classes that can be matched by name in braces in the
+	// opcode checks, like {gpr} for any general purpose register.
+	// Key is the GOARCH architecture.
+	regClasses = map[string]map[string]string{
+		"386": {
+			"gpr": `(?:AX|BX|CX|DX|SI|DI|BP)`,
+			"fpr": `X[0-7]`,
+		},
+		"amd64": {
+			"gpr": `(?:AX|BX|CX|DX|SI|DI|BP|R(?:8|9|1[0-5]))`,
+			"fpr": `X(?:1[0-5]|[0-9])`,
+		},
+		"arm": {
+			"gpr": `R(?:1[0-2]|[0-9])`,
+			"fpr": `F(?:1[0-5]|[0-9])`,
+		},
+		"arm64": {
+			"gpr": `R(?:[12][0-9]|3[01]|[0-9])`,
+			"fpr": `F(?:[12][0-9]|3[01]|[0-9])`,
+		},
+		"mips": {
+			"gpr": `R(?:[12][0-9]|3[01]|[0-9])`,
+			"fpr": `F(?:[12][0-9]|3[01]|[0-9])`,
+		},
+		"mips64": {
+			"gpr": `R(?:[12][0-9]|3[01]|[0-9])`,
+			"fpr": `F(?:[12][0-9]|3[01]|[0-9])`,
+		},
+		"ppc64": {
+			"gpr": `R(?:[12][0-9]|3[01]|[0-9])`,
+			"fpr": `F(?:[12][0-9]|3[01]|[0-9])`,
+		},
+		"ppc64le": {
+			"gpr": `R(?:[12][0-9]|3[01]|[0-9])`,
+			"fpr": `F(?:[12][0-9]|3[01]|[0-9])`,
+		},
+		"riscv64": {
+			"gpr": `X(?:[12][0-9]|3[01]|[0-9])`,
+			"fpr": `F(?:[12][0-9]|3[01]|[0-9])`,
+		},
+		"s390x": {
+			"gpr": `R(?:1[0-5]|[0-9])`,
+			"fpr": `F(?:1[0-5]|[0-9])`,
+		},
+	}
+
+	// Regexp to match a register class in an opcode check
+	rxRegClass = regexp.MustCompile(`\{([a-z]+)\}`)
 )
 
 // wantedAsmOpcode is a single asmcheck check
@@ -1766,7 +1816,8 @@ type wantedAsmOpcode struct {
 	line     int            // original source line
 	opcode   *regexp.Regexp // opcode check to be performed on assembly output
 	negative bool           // true if the check is supposed to fail rather than pass
-	found    bool           // true if the opcode check matched at least one in the output
+	count    int            // number of matches for the check to pass, or fail if negative
+	found    int            // number of lines of the output the opcode check matched
 }
 
 // A build environment triplet separated by slashes (eg: linux/386/sse2).
@@ -1828,6 +1879,12 @@ func (t *test) wantedAsmOpcodes(fn string) asmChecks {
 			archspec, allchecks := ac[1:4], ac[4]
 
 			var arch, subarch, os string
+			var later bool
+			if strings.HasSuffix(archspec[2], "+") {
+				archspec[2], later = strings.TrimSuffix(archspec[2], "+"), true
+			} else if archspec[2] == "" && strings.HasSuffix(archspec[1], "+") {
+				archspec[1], later = strings.TrimSuffix(archspec[1], "+"), true
+			}
 			switch {
 			case archspec[2] != "": // 3 components: "linux/386/sse2"
 				os, arch, subarch = archspec[0], archspec[1][1:], archspec[2][1:]
@@ -1846,7 +1903,18 @@ func (t *test) wantedAsmOpcodes(fn string) asmChecks {
 
 			// Create the build environments corresponding the above specifiers
 			envs := make([]buildEnv, 0, 4)
-			if subarch != "" {
+			if later {
+				found := false
+				for _, sa := range archVariants[arch][1:] {
+					found = found || sa == subarch
+					if found {
+						envs = append(envs, buildEnv(os+"/"+arch+"/"+sa))
+					}
+				}
+				if !found {
+					log.Fatalf("%s:%d: unsupported variant of %v: %v", t.goFileName(), i+1, arch, subarch)
+				}
+			} else if subarch != "" {
 				envs = append(envs, buildEnv(os+"/"+arch+"/"+subarch))
 			} else {
 				subarchs := archVariants[arch]
@@ -1865,11 +1933,27 @@ func (t *test) wantedAsmOpcodes(fn string) asmChecks {
 					negative = true
 					m = m[1:]
 				}
+				count := 1
+				if strings.HasSuffix(m, "}") {
+					j := strings.LastIndex(m, "{")
+					count, _ = strconv.Atoi(m[j+1 : len(m)-1])
+					m = m[:j]
+					if count == 0 {
+						log.Fatalf("%s:%d: check counts must be positive", t.goFileName(), i+1)
+					}
+				}
 
 				rxsrc, err := strconv.Unquote(m)
 				if err != nil {
 					log.Fatalf("%s:%d: error unquoting string: %v", t.goFileName(), i+1, err)
 				}
+				rxsrc = rxRegClass.ReplaceAllStringFunc(rxsrc, func(c string) string {
+					class, ok := regClasses[arch][c[1:len(c)-1]]
+					if !ok {
+						log.Fatalf("%s:%d: unknown register class for %v: %v", t.goFileName(), i+1, arch, c)
+					}
+					return class
+				})
 
 				// Compile the checks as regular expressions. Notice that we
 				// consider checks as matching from the beginning of the actual
@@ -1890,6 +1974,7 @@ func (t *test) wantedAsmOpcodes(fn string) asmChecks {
 					}
 					ops[env][lnum] = append(ops[env][lnum], wantedAsmOpcode{
 						negative: negative,
+						count:    count,
 						fileline: lnum,
 						line:     i + 1,
 						opcode:   oprx,
@@ -1937,8 +2022,8 @@ func (t *test) asmCheck(outStr string, fn string, env buildEnv, fullops map[stri
 		// run the checks.
 		if ops, found := fullops[srcFileLine]; found {
 			for i := range ops {
-				if !ops[i].found && ops[i].opcode.FindString(asm) != "" {
-					ops[i].found = true
+				if ops[i].opcode.FindString(asm) != "" {
+					ops[i].found++
 				}
 			}
 		}
@@ -1948,9 +2033,9 @@ func (t *test) asmCheck(outStr string, fn string, env buildEnv, fullops map[stri
 	var failed []wantedAsmOpcode
 	for _, ops := range fullops {
 		for _, o := range ops {
-			// There's a failure if a negative match was found,
-			// or a positive match was not found.
-			if o.negative == o.found {
+			// There's a failure if a negative match was found
+			// count times, or a positive match was not.
+			if o.negative == (o.found >= o.count) {
 				failed = append(failed, o)
 			}
 		}
@@ -1977,9 +2062,14 @@ func (t *test) asmCheck(outStr string, fn string, env buildEnv, fullops map[stri
 			lastFunction = funcIdx // avoid printing same function twice
 		}
 
-		if o.negative {
+		switch {
+		case o.count > 1 && o.negative:
+			fmt.Fprintf(&errbuf, "%s:%d: %s: opcode found %d times, want fewer than %d: %q\n", t.goFileName(), o.line, env, o.found, o.count, o.opcode.String())
+		case o.count > 1:
+			fmt.Fprintf(&errbuf, "%s:%d: %s: opcode found %d times, want at least %d: %q\n", t.goFileName(), o.line, env, o.found, o.count, o.opcode.String())
+		case o.negative:
 			fmt.Fprintf(&errbuf, "%s:%d: %s: wrong opcode found: %q\n", t.goFileName(), o.line, env, o.opcode.String())
-		} else {
+		default:
 			fmt.Fprintf(&errbuf, "%s:%d: %s: opcode not found: %q\n", t.goFileName(), o.line, env, o.opcode.String())
 		}
 	}
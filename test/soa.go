@@ -0,0 +1,86 @@
+// errorcheck -0
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test which //go:soa slices of structs are converted to a slice per
+// field.
+
+package p
+
+type P struct {
+	x, y, z float64
+	_       int
+	n       int
+}
+
+func sum(n int) float64 {
+	//go:soa
+	var ps []P
+	ps = make([]P, 0, n)
+	for i := 0; i < n; i++ {
+		ps = append(ps, P{x: float64(i), n: i})
+	}
+	ps = ps[1:]
+	s := 0.0
+	for i := range ps {
+		ps[i].y = ps[i].x * 2
+		s += ps[i].y
+	}
+	for i := 0; i < len(ps); i++ {
+		p := &ps[i].z
+		*p = s
+	}
+	ps = nil
+	return s
+}
+
+func whole(n int) P {
+	//go:soa
+	var ps = make([]P, n)
+	return ps[0] // ERROR "ps not converted to struct of slices: one of its elements is used as a whole"
+}
+
+func ranged(n int) (s float64) {
+	//go:soa
+	var ps = make([]P, n)
+	for _, p := range ps { // ERROR "ps not converted to struct of slices: its elements are ranged over"
+		s += p.x
+	}
+	return s
+}
+
+func passed(f func([]P), n int) {
+	//go:soa
+	var ps = make([]P, n)
+	f(ps) // ERROR "ps not converted to struct of slices: it is used as a whole"
+}
+
+func captured(n int) func() float64 {
+	//go:soa
+	var ps = make([]P, n)
+	return func() float64 { // ERROR "ps not converted to struct of slices: it is captured by a closure"
+		return ps[0].x
+	}
+}
+
+func capacity(n int) int {
+	//go:soa
+	var ps = make([]P, n)
+	return cap(ps) // ERROR "ps not converted to struct of slices: its capacity is used"
+}
+
+func resliced(n, j int) float64 {
+	//go:soa
+	var ps = make([]P, n)
+	ps = ps[:0]
+	ps = ps[:j] // ERROR "ps not converted to struct of slices: it may be resliced past its length"
+	return ps[0].x
+}
+
+func notStructs(n int) int {
+	//go:soa
+	var xs = make([]int, n) // ERROR "xs not converted to struct of slices: it is not a slice of structs"
+	return len(xs)
+}
@@ -0,0 +1,83 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that //go:soa slices of structs behave as before conversion.
+
+package main
+
+type Vec struct {
+	X, Y float64
+	N    int
+	_    [4]byte
+	S    string
+}
+
+func mk(i int) Vec { return Vec{X: float64(i), Y: float64(-i), N: i, S: "v"} }
+
+func run(n int) (sum float64, names string) {
+	//go:soa
+	var vs []Vec
+	for i := 0; i < n; i++ {
+		vs = append(vs, mk(i), Vec{X: 1})
+	}
+	if len(vs) != 2*n {
+		panic("bad len")
+	}
+	vs = vs[2 : len(vs)-2]
+	for i := range vs {
+		vs[i].Y += vs[i].X
+		vs[i].N *= 2
+		sum += vs[i].Y + float64(vs[i].N)
+		names += vs[i].S
+	}
+	vs = make([]Vec, 3, 10)
+	vs[2].S = "z"
+	names += vs[2].S
+	return sum, names
+}
+
+func oob(n int) (err interface{}) {
+	defer func() { err = recover() }()
+	//go:soa
+	var vs = make([]Vec, n)
+	return vs[n].X
+}
+
+// grow reslices vs past its length, which an ordinary slice allows
+// up to its capacity, so it is not converted.
+func grow(n int) (sum int) {
+	//go:soa
+	var vs = make([]Vec, n, 2*n)
+	for i := range vs {
+		vs[i].N = i + 1
+	}
+	vs = vs[:0]
+	vs = vs[:2*n]
+	for i := range vs {
+		sum += vs[i].N
+	}
+	return sum
+}
+
+func main() {
+	sum, names := run(4)
+	// Elements 2..5 are mk(1), {X: 1}, mk(2), {X: 1}:
+	// Y+X is 0, 1, 0, 1 and 2*N is 2, 0, 4, 0.
+	if sum != 8 {
+		println("sum", sum)
+		panic("bad sum")
+	}
+	if names != "vv"+"z" {
+		panic("bad names " + names)
+	}
+	if oob(3) == nil {
+		panic("no index panic")
+	}
+	if s := grow(3); s != 6 {
+		println("grow", s)
+		panic("bad grow")
+	}
+}
@@ -0,0 +1,69 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that unexported functions always called with constant
+// arguments are specialized.
+
+package p
+
+func shift(x int, n uint, neg bool) int { // ERROR "specializing shift for n = 3, neg = false" "specializing shift for n = 8, neg = true"
+	x <<= n
+	if neg {
+		x = -x
+	}
+	return x
+}
+
+func mode(x, m int) int { // not always a constant
+	if m == 0 {
+		return x
+	}
+	return x * m
+}
+
+func unused(x, m int) int { // m is not used
+	return x + 1
+}
+
+func Exported(x, m int) int { // may be called from other packages
+	if m == 0 {
+		return x
+	}
+	return x * m
+}
+
+func taken(x, m int) int { // used as a value
+	if m == 0 {
+		return x
+	}
+	return x * m
+}
+
+var f = taken
+
+//go:noinline
+func noinline(x, m int) int { // calls must reach it as written
+	if m == 0 {
+		return x
+	}
+	return x * m
+}
+
+// fail only passes its arguments on, so there is nothing to fold.
+func fail(msg string, code int) { // ERROR "msg does not escape"
+	println(msg, code)
+}
+
+func A(x int) int {
+	return shift(x, 3, false) + shift(x, 8, true) + shift(x+1, 3, false)
+}
+
+func B(x, y int) int {
+	if x < 0 {
+		fail("negative", 1)
+	}
+	return noinline(x, 2) + mode(x, 2) + mode(x, y) + unused(x, 5) + Exported(x, 2) + taken(x, 2)
+}
@@ -0,0 +1,92 @@
+// run -gcflags=-l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that functions specialized for constant arguments compute
+// the same results as the functions themselves.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+type vec struct{ x, y, z, w, u, v float64 }
+
+func scale(v vec, k float64, round bool) vec {
+	v = vec{v.x * k, v.y * k, v.z * k, v.w * k, v.u * k, v.v * k}
+	if round {
+		v.x = float64(int(v.x))
+	}
+	return v
+}
+
+func repeat(s, sep string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 && sep != "" {
+			b.WriteString(sep)
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+func get(m map[string]int, p *int, key string) int {
+	if p == nil {
+		return m[key]
+	}
+	return *p
+}
+
+type celsius int
+
+func (c celsius) String() string { return fmt.Sprintf("%d°C", int(c)) }
+
+// describe is reachable only through its specialized copies, which
+// must keep celsius.String reachable for fmt.
+func describe(c celsius, verbose bool) string {
+	if verbose {
+		return fmt.Sprint("temperature ", c)
+	}
+	return fmt.Sprint(c)
+}
+
+func caller(skip int) string {
+	if skip < 0 {
+		return ""
+	}
+	pc, _, _, _ := runtime.Caller(skip)
+	return runtime.FuncForPC(pc).Name()
+}
+
+func check(what string, got, want interface{}) {
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		panic(fmt.Sprintf("%s = %v, want %v", what, got, want))
+	}
+}
+
+func main() {
+	v := vec{1.5, 2, 3, 4, 5, 6}
+	check("scale(v, 2, true)", scale(v, 2, true), vec{3, 4, 6, 8, 10, 12})
+	check("scale(v, 0.5, false)", scale(v, 0.5, false), vec{0.75, 1, 1.5, 2, 2.5, 3})
+
+	check("repeat(ab, -, 3)", repeat("ab", "-", 3), "ab-ab-ab")
+	check("repeat(x, , 2)", repeat("x", "", 2), "xx")
+
+	m := map[string]int{"a": 1}
+	check("get(m, nil, a)", get(m, nil, "a"), 1)
+	check("get(nil, nil, b)", get(nil, nil, "b"), 0)
+
+	check("describe(20, true)", describe(20, true), "temperature 20°C")
+	check("describe(-3, false)", describe(-3, false), "-3°C")
+
+	// Specialized copies are reported by the name of the function
+	// they copy.
+	check("caller(1)", caller(1), "main.main")
+	check("caller(0)", caller(0), "main.caller")
+}
@@ -0,0 +1,23 @@
+// errorcheck -0 -m -l -Os
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -Os doesn't specialize functions for constant arguments.
+
+package p
+
+func shift(x int, n uint, neg bool) int {
+	for i := uint(0); i < n; i++ {
+		x = x*2 + x>>60
+	}
+	if neg {
+		x = -x
+	}
+	return x
+}
+
+func A(x int) int {
+	return shift(x, 3, false) + shift(x, 8, true)
+}
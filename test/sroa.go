@@ -0,0 +1,138 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that local structs and arrays split into their parts
+// by the compiler keep their values.
+
+package main
+
+import "fmt"
+
+type vec struct{ x, y, z, w float64 }
+
+func (v vec) add(u vec) vec       { return vec{v.x + u.x, v.y + u.y, v.z + u.z, v.w + u.w} }
+func (v vec) scale(k float64) vec { return vec{v.x * k, v.y * k, v.z * k, v.w * k} }
+func (v vec) dot(u vec) float64   { return v.x*u.x + v.y*u.y + v.z*u.z + v.w*u.w }
+
+type inner struct {
+	a int
+	s string
+}
+
+type outer struct {
+	in   inner
+	p    *int
+	arr  [3]int
+	one  [1]string
+	_    struct{}
+	tail inner
+}
+
+//go:noinline
+func sum(n int) float64 {
+	acc := vec{}
+	for i := 0; i < n; i++ {
+		v := vec{float64(i), 1, 2, 3}
+		acc = acc.add(v.scale(2))
+	}
+	return acc.dot(vec{1, 1, 1, 1})
+}
+
+//go:noinline
+func fields(n int) outer {
+	var o outer
+	x := 7
+	o.p = &x
+	o.in.a = n
+	o.in.s = "in"
+	o.arr[0] = n
+	o.arr[2] = n * 2
+	o.one[0] = "one"
+	o.tail = o.in
+	o.tail.a++
+	if n > 3 {
+		o.arr[1] = o.arr[0] + o.arr[2]
+	} else {
+		o.arr = [3]int{-1, -2, -3}
+	}
+	return o
+}
+
+//go:noinline
+func index(o outer, i int) int {
+	c := o
+	c.in.a = 100
+	return c.arr[0] + o.in.a + c.in.a
+}
+
+var sink *outer
+
+//go:noinline
+func store(n int) {
+	o := fields(n)
+	c := o
+	c.one[0] += "!"
+	sink = new(outer)
+	*sink = c
+}
+
+//go:noinline
+func load(p *outer) string {
+	o := *p
+	o2 := o
+	o2.tail.s = "changed"
+	return fmt.Sprint(o.tail.s, o2.tail.s, o.arr[2])
+}
+
+//go:noinline
+func iface(n int) string {
+	var a [4]int
+	a[0], a[3] = n, -n
+	return fmt.Sprint(a)
+}
+
+//go:noinline
+func closure(n int) int {
+	var a [5]int
+	a[4] = n
+	f := func() int { return a[4] + 1 }
+	a[4] = 0
+	return f()
+}
+
+func main() {
+	if got, want := sum(4), 2*(0+1+2+3+4*(1+2+3)); got != float64(want) {
+		panic(fmt.Sprintf("sum: got %v, want %v", got, want))
+	}
+
+	o := fields(5)
+	if *o.p != 7 || o.in != (inner{5, "in"}) || o.arr != [3]int{5, 15, 10} || o.one[0] != "one" || o.tail != (inner{6, "in"}) {
+		panic(fmt.Sprintf("fields(5): got %+v", o))
+	}
+	o = fields(1)
+	if o.arr != [3]int{-1, -2, -3} {
+		panic(fmt.Sprintf("fields(1): got %+v", o))
+	}
+
+	if got := index(fields(5), 0); got != 5+5+100 {
+		panic(fmt.Sprintf("index: got %v", got))
+	}
+
+	store(4)
+	if sink.one[0] != "one!" || sink.arr != [3]int{4, 12, 8} || *sink.p != 7 {
+		panic(fmt.Sprintf("store: got %+v", *sink))
+	}
+	if got, want := load(sink), "inchanged8"; got != want {
+		panic(fmt.Sprintf("load: got %q, want %q", got, want))
+	}
+
+	if got, want := iface(3), "[3 0 0 -3]"; got != want {
+		panic(fmt.Sprintf("iface: got %q, want %q", got, want))
+	}
+	if got := closure(3); got != 1 {
+		panic(fmt.Sprintf("closure: got %v", got))
+	}
+}
@@ -0,0 +1,50 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that //go:stacklocals keeps large non-escaping values
+// on the stack, growing it as needed.
+
+package main
+
+import "runtime"
+
+type big [1 << 21]int64
+
+//go:noinline
+func fill(p *big, v int64) {
+	for i := range p {
+		p[i] = v + int64(i)
+	}
+}
+
+//go:stacklocals
+func sum(n int) int64 {
+	var x big
+	fill(&x, 1)
+	p := new(big)
+	fill(p, 2)
+	s := make([]int64, 100000)
+	s[n] = 3
+	if n > 0 {
+		// Recurse to grow and copy the stack with x and p on it.
+		return sum(n-1) + x[n] + p[n] + s[n]
+	}
+	return x[n] + p[n] + s[n]
+}
+
+func main() {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	got := sum(3)
+	runtime.ReadMemStats(&after)
+	if want := int64(4*(1+2+3) + 2*(0+1+2+3)); got != want {
+		panic(got)
+	}
+	if n := after.HeapAlloc - before.HeapAlloc; n > 1<<20 {
+		println("heap grew by", n, "bytes")
+		panic("large locals were heap allocated")
+	}
+}
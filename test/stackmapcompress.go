@@ -0,0 +1,98 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that the garbage collector and stack copying find the live
+// pointers of a function with many stack maps, whose locals bitmaps
+// the compiler delta-encodes.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type T struct {
+	x int
+	_ [8]uintptr // so freed objects get reused by garbage
+}
+
+var sink []*T
+
+//go:noinline
+func use(n int) {
+	// Grow the stack, and collect garbage allocated in between.
+	if n > 0 {
+		var buf [256]byte
+		use(n - 1)
+		_ = buf
+		return
+	}
+	for i := 0; i < 1000; i++ {
+		sink = append(sink[:0], &T{x: -1})
+	}
+	runtime.GC()
+}
+
+//go:noinline
+func newT(x int) *T {
+	return &T{x: x}
+}
+
+//go:noinline
+func f() int {
+	var keep [200]*T // makes bitmaps large, but changes little
+	for i := range keep {
+		keep[i] = newT(i)
+	}
+	a, b, c, d := 1, 2, 3, 4
+	pa, pb, pc, pd := &a, &b, &c, &d // pointers to the stack
+	p1 := newT(1)
+	use(10)
+	p2 := newT(2)
+	use(20)
+	check(p1.x, 1)
+	p3 := newT(3)
+	use(40)
+	check(*pa, 1)
+	p4 := newT(4)
+	use(80)
+	check(p2.x, 2)
+	p5 := newT(5)
+	use(160)
+	check(*pb, 2)
+	p6 := newT(6)
+	use(320)
+	check(p3.x+p4.x, 7)
+	p7 := newT(7)
+	use(640)
+	check(*pc, 3)
+	p8 := newT(8)
+	use(1280)
+	check(p5.x+p6.x, 11)
+	p9 := newT(9)
+	use(10)
+	check(*pd, 4)
+	p10 := newT(10)
+	use(2560)
+	check(p7.x, 7)
+	use(20)
+	check(p8.x+p9.x+p10.x, 27)
+	for i, p := range keep {
+		check(p.x, i)
+	}
+	return *pa + *pb + *pc + *pd
+}
+
+func check(got, want int) {
+	if got != want {
+		panic(fmt.Sprintf("got %d, want %d", got, want))
+	}
+}
+
+func main() {
+	check(f(), 10)
+}
@@ -0,0 +1,70 @@
+// errorcheck -0 -d=staticpanic
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=staticpanic reports writes to maps that are certainly
+// nil and array indexes that are certainly out of range.
+
+package p
+
+func nilMaps(c bool) {
+	var m map[int]int
+	m[1] = 2 // ERROR "assignment to entry in nil map m always panics"
+
+	n := map[string]int(nil)
+	if c {
+		n["a"]++ // ERROR "assignment to entry in nil map n always panics"
+	}
+
+	var o map[int]int
+	_ = o[1]
+	delete(o, 1)
+	for range o {
+	}
+	o = make(map[int]int)
+	o[1] = 2
+
+	var p map[int]int
+	if c {
+		p = map[int]int{}
+	}
+	p[1] = 2
+
+	var q map[int]int
+	f := func() { q = map[int]int{} }
+	f()
+	q[1] = 2
+
+	var r map[int]int
+	g(&r)
+	r[1] = 2
+
+	var s map[int]int
+	goto L
+L:
+	s[1] = 2
+}
+
+func g(*map[int]int)
+
+func indexes(c bool) int {
+	var a [3]int
+	i := 5
+	a[i] = 1 // ERROR "index i \(= 5\) out of bounds \[0:3\] always panics"
+
+	j := -1
+	if c {
+		return a[j] // ERROR "index j \(= -1\) out of bounds \[0:3\] always panics"
+	}
+
+	k := 2
+	a[k] = 1
+
+	l := 3
+	for l >= 3 {
+		l--
+	}
+	return a[l]
+}
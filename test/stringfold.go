@@ -0,0 +1,67 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that operations on constant strings the compiler folds
+// give the same results as at run time.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	ab, abc, bad = "ab", "abc", "h\xffllo"
+	one, three   = 1, 3
+)
+
+var (
+	repeat  = strings.Repeat("ab", 3)
+	repeat0 = strings.Repeat("ab", 0)
+	index   = "abc"[1]
+	slice   = "abcdef"[1:4]
+	length  = len("abc"[one:]) + len("abc"[1:])
+	concat  = strings.Repeat("-", 3) + "|" + "abc"[2:]
+	less    = "abc"[1:] < "abd"
+	bytes   = string([]byte("xyz"))
+	runes   = string([]rune("h\xffllo"))
+	lit     = string([]rune{3: -1, 0: 'a', 'b', 'c'})
+	char    = string("abc"[0]) + string(rune("abc"[1]))
+	huge    = strings.Repeat("0123456789abcdef", 1<<13)
+)
+
+type S string
+
+var named = S("xyz"[1:])
+
+func check(name string, got, want interface{}) {
+	if got != want {
+		panic(fmt.Sprintf("%s = %#v, want %#v", name, got, want))
+	}
+}
+
+func main() {
+	check("repeat", repeat, strings.Repeat(ab, three))
+	check("repeat0", repeat0, "")
+	check("index", index, abc[one])
+	check("slice", slice, "abcdef"[one:three+one])
+	check("length", length, 4)
+	check("concat", concat, "---|c")
+	check("less", less, abc[one:] < "abd")
+	check("bytes", bytes, string([]byte("xyz"[one-1:])))
+	check("runes", runes, string([]rune(bad)))
+	check("lit", lit, "abc�")
+	check("char", char, "ab")
+	check("huge", len(huge), 1<<17)
+	check("named", named, S("yz"))
+
+	// The same, in a function.
+	check("repeat", strings.Repeat("ab", 3), strings.Repeat(ab, three))
+	check("index", "abc"[1], abc[one])
+	check("runes", string([]rune("h\xffllo")), string([]rune(bad)))
+	check("char", string("abc"[0])+string(rune("abc"[1])), "ab")
+}
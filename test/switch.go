@@ -356,6 +356,22 @@ func main() {
 		assert(false, "c1 did not match itself")
 	}
 
+	// switch on many strings of the same length
+	for s, want := range map[string]int{"chan": 1, "goto": 1, "else": 2, "type": 2, "func": 3, "cham": 0, "go": 0, "elsf": 0, "": 0} {
+		got := 0
+		switch s {
+		case "chan", "goto":
+			got = 1
+		case "else", "type":
+			got = 2
+		case "func":
+			got = 3
+		case "fund", "gott":
+			got = 4
+		}
+		assert(got == want, s)
+	}
+
 	// empty switch
 	switch {
 	}
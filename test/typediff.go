@@ -0,0 +1,57 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that assignment errors between mismatched struct types list
+// the differing fields, and those between a type and an interface it
+// does not implement list every missing method.
+
+package p
+
+type S struct {
+	A int
+	B string
+	C bool
+}
+
+type ReadWriteCloser interface {
+	Read([]byte) (int, error)
+	Write([]byte) (int, error)
+	Close() error
+	Flush()
+}
+
+type T struct{}
+
+func (T) Read(int) (int, error)      { return 0, nil }
+func (*T) Write([]byte) (int, error) { return 0, nil }
+func (T) close() error               { return nil }
+
+func f() {
+	var x struct {
+		A int
+		B []byte
+		D bool
+	}
+	var s S = x // ERROR "struct fields differ:\n\t\tfield B has type \[\]byte, want string\n\t\tmissing field C bool\n\t\textra field D bool$"
+	_ = s
+
+	var y struct {
+		B string
+		A int
+		C bool
+	}
+	s = y // ERROR "struct fields are in a different order"
+
+	var z struct {
+		A int
+		B string `json:"b"`
+		C bool
+	}
+	s = z // ERROR "field B has tag \x22json:\\\x22b\\\x22\x22, want \x22\x22"
+
+	var rwc ReadWriteCloser = T{} // ERROR "T does not implement ReadWriteCloser \(missing Close method\)\n.*\n.*\n\t\talso missing Flush method\n\t\talso wrong type for Read method\n\t\t\thave Read\(int\) \(int, error\)\n\t\t\twant Read\(\[\]byte\) \(int, error\)\n\t\talso Write method has pointer receiver$"
+	_ = rwc
+}
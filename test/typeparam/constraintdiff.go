@@ -0,0 +1,58 @@
+// errorcheck
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that errors for type arguments not satisfying their constraint
+// explain which element of the constraint is not satisfied, and why.
+
+package p
+
+type Stringer interface{ String() string }
+
+type Number interface{ ~int | ~float64 }
+
+type C interface {
+	Number
+	Stringer
+}
+
+type K interface {
+	comparable
+	Stringer
+}
+
+type PtrString int
+
+func (*PtrString) String() string { return "" }
+
+type Plain int
+
+type Str string
+
+func (Str) String() string { return "" }
+
+type Wrong int
+
+func (Wrong) String() int { return 0 }
+
+type S struct{ f []int }
+
+func F[T C](T)          {}
+func G[T comparable](T) {}
+func H[T Stringer](T)   {}
+func J[T K](T)          {}
+func N[T Number](T)     {}
+
+var (
+	_ = F[PtrString] // ERROR "PtrString does not implement C \(String method has pointer receiver\)\n\t\tString method required by constraint element Stringer$"
+	_ = F[Plain]     // ERROR "Plain does not implement C \(missing String method\)\n\t\tString method required by constraint element Stringer$"
+	_ = F[Str]       // ERROR "Str does not implement C \(Str missing in ~int \| ~float64\)\n\t\tStr excluded by constraint element Number$"
+	_ = F[Wrong]     // ERROR "Wrong does not implement C \(wrong type for String method\)\n\t\thave String\(\) int\n\t\twant String\(\) string\n\t\tString method required by constraint element Stringer$"
+	_ = H[PtrString] // ERROR "PtrString does not implement Stringer \(String method has pointer receiver\)\n\t\t\*PtrString implements Stringer$"
+	_ = G[S]         // ERROR "S does not implement comparable \(struct containing \[\]int cannot be compared\)$"
+	_ = G[func()]    // ERROR "func\(\) does not implement comparable \(func can only be compared to nil\)$"
+	_ = J[[2][]int]  // ERROR "\[2\]\[\]int does not implement comparable \(array of \[\]int cannot be compared\)$"
+	_ = N[S]         // ERROR "S does not implement Number \(S missing in ~int \| ~float64\)$"
+)
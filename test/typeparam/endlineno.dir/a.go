@@ -0,0 +1,20 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package a
+
+import "runtime"
+
+var Line int
+
+func record() {
+	_, _, Line, _ = runtime.Caller(1)
+}
+
+func Imported[T comparable](x, y T) {
+	defer record()
+	if x == y {
+		Line = -1
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"./a"
+)
+
+func record() {
+	a.Line = 0
+	_, _, a.Line, _ = runtime.Caller(1)
+}
+
+func Local[T comparable](x, y T) {
+	defer record()
+	if x == y {
+		a.Line = -1
+	}
+}
+
+func main() {
+	Local(1, 2)
+	if a.Line != 24 {
+		panic(fmt.Sprintf("deferred call in Local[int] at line %d, want 24", a.Line))
+	}
+	a.Imported("x", "y")
+	if a.Line != 20 {
+		panic(fmt.Sprintf("deferred call in a.Imported[string] at line %d, want 20", a.Line))
+	}
+}
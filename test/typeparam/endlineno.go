@@ -0,0 +1,12 @@
+// rundir -G=3
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that code run when instantiations of generic functions return,
+// such as deferred calls, is attributed to their closing brace, as it
+// is for other functions, whether the generic function is declared in
+// the same package or another one.
+
+package ignored
@@ -0,0 +1,22 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "./a"
+
+func min[T a.Number](x, y T) T { // ERROR "can inline min\[go.shape.int_0\]"
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func F(x, y int) int { // ERROR "can inline F"
+	return a.Max(x, y)
+}
+
+func G(x, y int) int { // ERROR "can inline G"
+	return min(x, y) // ERROR "inlining call to min\[go.shape.int_0\]"
+}
@@ -0,0 +1,10 @@
+// errorcheckdir -0 -m -d=inlimported=0
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=inlimported=0 keeps instantiations of generic functions
+// from other packages from being inlined, but not local ones.
+
+package ignored
@@ -0,0 +1,27 @@
+// errorcheck -0 -G=3 -d=printf
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=printf checks arguments whose type is a type
+// parameter against every type in its constraint.
+
+package p
+
+import "fmt"
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+func generic[T Number, S fmt.Stringer, A any](t T, s S, a A) {
+	fmt.Printf("%v %s %v", t, s, a)
+	fmt.Printf("%d", t) // ERROR "fmt.Printf format %d has arg t of wrong type T"
+	fmt.Printf("%g", t) // ERROR "fmt.Printf format %g has arg t of wrong type T"
+	fmt.Printf("%d", a)
+}
+
+func genericInts[T ~int | ~uint8](t T) {
+	fmt.Printf("%d %x %c", t, t, t)
+}
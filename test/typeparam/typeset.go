@@ -0,0 +1,24 @@
+// errorcheck -0 -d=typeset=Sum.T
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=typeset prints the type set and core type of a
+// type parameter's constraint.
+
+package p
+
+import "fmt"
+
+type Number interface {
+	~int | ~int64 | ~float64
+	fmt.Stringer
+}
+
+func Sum[T Number](s []T) (t T) { // ERROR "type set of Sum.T: types: ~int \| ~int64 \| ~float64\n\tmethods: String\(\) string\n\tcomparable: yes\n\tordered: yes\n\tcore type: none \(underlying types int and int64 differ\)$"
+	for _, v := range s {
+		t += v
+	}
+	return t
+}
@@ -0,0 +1,54 @@
+// errorcheck -0 -m -l -d=typeswitch=linear
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -m reports the -d=typeswitch lowering of type switches
+// on concrete types.
+
+package p
+
+type I interface{ M() }
+
+type T int
+
+func (T) M() {}
+
+func f(x interface{}) int { // ERROR "x does not escape"
+	switch x.(type) { // ERROR "type switch on 5 concrete types lowered to linear search"
+	case int:
+		return 1
+	case string:
+		return 2
+	case float64:
+		return 3
+	case []byte:
+		return 4
+	case T:
+		return 5
+	}
+	return 0
+}
+
+func g(x interface{}) int { // ERROR "x does not escape"
+	switch x.(type) { // ERROR "type switch on 2 concrete types lowered to linear search" "type switch on 1 concrete types lowered to linear search"
+	case int, string:
+		return 1
+	case I:
+		return 2
+	case bool:
+		return 3
+	}
+	return 0
+}
+
+func h(x I) int { // ERROR "x does not escape"
+	switch x.(type) {
+	case I:
+		return 1
+	case nil:
+		return 2
+	}
+	return 0
+}
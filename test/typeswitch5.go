@@ -0,0 +1,80 @@
+// run -gcflags=-d=typeswitch=linear
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that type switches lowered to a linear chain of
+// compares pick the same cases as the default binary search.
+
+package main
+
+import "fmt"
+
+type I interface{ M() int }
+
+type A int
+type B string
+type C struct{ x, y int }
+type D [2]float64
+
+func (a A) M() int { return int(a) }
+func (B) M() int   { return -1 }
+
+func class(x interface{}) string {
+	switch x := x.(type) {
+	case nil:
+		return "nil"
+	case int, int8, int16:
+		return fmt.Sprint("small int ", x)
+	case int32:
+		return "int32"
+	case int64:
+		return "int64"
+	case string:
+		return "string " + x
+	case A:
+		return fmt.Sprint("A ", x.M())
+	case I:
+		return fmt.Sprint("I ", x.M())
+	case C:
+		return fmt.Sprint("C ", x.x+x.y)
+	case D:
+		return "D"
+	case []byte, []int:
+		return "slice"
+	case float32:
+		return "float32"
+	default:
+		return "default"
+	}
+}
+
+func main() {
+	tests := []struct {
+		x    interface{}
+		want string
+	}{
+		{nil, "nil"},
+		{1, "small int 1"},
+		{int8(2), "small int 2"},
+		{int16(3), "small int 3"},
+		{int32(4), "int32"},
+		{int64(5), "int64"},
+		{"s", "string s"},
+		{A(6), "A 6"},
+		{B("b"), "I -1"},
+		{C{3, 4}, "C 7"},
+		{D{}, "D"},
+		{[]byte{}, "slice"},
+		{[]int{}, "slice"},
+		{float32(1), "float32"},
+		{float64(1), "default"},
+		{struct{}{}, "default"},
+	}
+	for _, tt := range tests {
+		if got := class(tt.x); got != tt.want {
+			panic(fmt.Sprintf("class(%#v) = %q, want %q", tt.x, got, tt.want))
+		}
+	}
+}
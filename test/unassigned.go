@@ -0,0 +1,172 @@
+// errorcheck -0 -d=unassigned=64
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=unassigned reports reads of large variables that
+// may not have been assigned since their declaration.
+
+package p
+
+type Big struct {
+	buf [16]int
+	n   int
+}
+
+type Small struct {
+	a, b int
+}
+
+func sink(Big)
+
+func never() Big {
+	var b Big
+	return b // ERROR "b \(136 bytes\) is read before it is assigned"
+}
+
+func small() Small {
+	var s Small
+	return s
+}
+
+func assigned(x Big) Big {
+	var b Big
+	b = x
+	return b
+}
+
+func explicitZero() Big {
+	b := Big{}
+	return b
+}
+
+func someBranch(c bool, x Big) {
+	var b Big
+	if c {
+		b = x
+	}
+	sink(b) // ERROR "b \(136 bytes\) may be read before it is assigned"
+}
+
+func bothBranches(c bool, x Big) {
+	var b Big
+	if c {
+		b = x
+	} else {
+		b.n = 1
+	}
+	sink(b)
+}
+
+func field() int {
+	var b Big
+	return b.buf[3] // ERROR "b \(136 bytes\) is read before it is assigned"
+}
+
+func counters(keys []int) [64]int {
+	var counts [64]int
+	for _, k := range keys {
+		counts[k%64]++
+	}
+	return counts
+}
+
+func rangeKeys() {
+	var b [64]int
+	for i := range b {
+		b[i] = i
+	}
+	_ = b
+}
+
+func address(init func(*Big)) Big {
+	var b Big
+	init(&b)
+	return b
+}
+
+func method() int {
+	var b Big
+	b.set()
+	return b.n
+}
+
+func (b *Big) set() { b.n = 1 }
+
+func loop(xs []Big) Big {
+	var b Big
+	for _, x := range xs {
+		if x.n > 0 {
+			b = x
+			break
+		}
+	}
+	return b // ERROR "b \(136 bytes\) may be read before it is assigned"
+}
+
+func forever(next func() (Big, bool)) Big {
+	var b Big
+	for {
+		x, ok := next()
+		if ok {
+			b = x
+			break
+		}
+	}
+	return b
+}
+
+func switchAll(k int, x Big) Big {
+	var b Big
+	switch k {
+	case 0:
+		b = x
+	default:
+		b.n = k
+	}
+	return b
+}
+
+func switchSome(k int, x Big) Big {
+	var b Big
+	switch k {
+	case 0:
+		b = x
+	case 1:
+		return x
+	}
+	return b // ERROR "b \(136 bytes\) may be read before it is assigned"
+}
+
+func earlyReturn(c bool, x Big) Big {
+	var b Big
+	if !c {
+		return x
+	}
+	b = x
+	return b
+}
+
+func captured() Big {
+	var b Big
+	f := func() { b.n = 1 }
+	f()
+	return b
+}
+
+func counted(p []int) [16]int {
+	var w [16]int
+	for i := 0; i < 16; i++ {
+		w[i] = p[i]
+	}
+	return w
+}
+
+func maybeCounted(p []int) [16]int {
+	var w [16]int
+	for i := 0; i < len(p); i++ {
+		w[i] = p[i]
+	}
+	return w // ERROR "w \(128 bytes\) may be read before it is assigned"
+}
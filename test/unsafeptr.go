@@ -0,0 +1,87 @@
+// errorcheck -0 -d=unsafeptr
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=unsafeptr reports conversions between unsafe.Pointer
+// and uintptr that may violate the unsafe package's rules.
+
+package p
+
+import (
+	"reflect"
+	"syscall"
+	"unsafe"
+)
+
+type T struct {
+	a, b int
+}
+
+func arith(p *T) *int {
+	return (*int)(unsafe.Pointer(uintptr(unsafe.Pointer(p)) + unsafe.Offsetof(p.b)))
+}
+
+func round(p unsafe.Pointer) unsafe.Pointer {
+	return unsafe.Pointer((uintptr(p) + 7) &^ 7)
+}
+
+func fixed() unsafe.Pointer {
+	return unsafe.Pointer(uintptr(0x1000))
+}
+
+func roundTrip(p *T) *T {
+	u := uintptr(unsafe.Pointer(p))
+	return (*T)(unsafe.Pointer(u)) // ERROR "possible misuse of unsafe.Pointer: uintptr variable u does not keep its referent alive"
+}
+
+func offset(p *T, u uintptr) *int {
+	return (*int)(unsafe.Pointer(u + uintptr(unsafe.Pointer(p))))
+}
+
+func noBase(u, off uintptr) *int {
+	return (*int)(unsafe.Pointer(u + off)) // ERROR "possible misuse of unsafe.Pointer: conversion of uintptr u \+ off to unsafe.Pointer"
+}
+
+func constOffset(u uintptr) (*int, *int, *int) {
+	return (*int)(unsafe.Pointer(u + 8)), // ERROR "possible misuse of unsafe.Pointer: conversion of uintptr u \+ 8 to unsafe.Pointer"
+		(*int)(unsafe.Pointer(8 + u)), // ERROR "possible misuse of unsafe.Pointer: conversion of uintptr 8 \+ u to unsafe.Pointer"
+		(*int)(unsafe.Pointer(0x1000 - u)) // ERROR "possible misuse of unsafe.Pointer: conversion of uintptr 0x1000 - u to unsafe.Pointer"
+}
+
+func fromCall(f func() uintptr) *int {
+	return (*int)(unsafe.Pointer(f())) // ERROR "possible misuse of unsafe.Pointer: conversion of uintptr"
+}
+
+func fromReflect(v reflect.Value) (unsafe.Pointer, unsafe.Pointer) {
+	return unsafe.Pointer(v.Pointer()), unsafe.Pointer(v.UnsafeAddr())
+}
+
+func fromHeader(s []byte) (unsafe.Pointer, unsafe.Pointer) {
+	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&s))
+	val := *hdr
+	return unsafe.Pointer(hdr.Data), unsafe.Pointer(val.Data) // ERROR "possible misuse of unsafe.Pointer: conversion of uintptr val.Data to unsafe.Pointer"
+}
+
+func use(uintptr)
+
+func goUse(u uintptr) {
+	println(u)
+}
+
+//go:uintptrescapes
+func escapes(u uintptr) {
+	println(u)
+}
+
+func calls(p *T, f func(uintptr)) {
+	syscall.Syscall(0, uintptr(unsafe.Pointer(p)), 0, 0)
+	use(uintptr(unsafe.Pointer(p)))
+	escapes(uintptr(unsafe.Pointer(p)))
+	goUse(uintptr(unsafe.Pointer(p))) // ERROR "pointer converted to uintptr is not kept alive during call to goUse \(not //go:uintptrescapes\)"
+	f(uintptr(unsafe.Pointer(p)))     // ERROR "pointer converted to uintptr is not kept alive during indirect call"
+	func() {
+		goUse(uintptr(unsafe.Pointer(p))) // ERROR "pointer converted to uintptr is not kept alive during call to goUse"
+	}()
+}
@@ -0,0 +1,32 @@
+// errorcheck -0 -d=whyasm=f
+
+//go:build amd64 || arm64
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that -d=whyasm explains the calls in a function's code.
+
+package p
+
+import "math/bits"
+
+func small(x int) int { return x + 1 }
+
+//go:noinline
+func big(x int) int { return x * 2 }
+
+var fp func(int) int
+
+func f(xs []int, x uint64) []int {
+	n := bits.TrailingZeros64(x) // ERROR "intrinsified call to math/bits.TrailingZeros64"
+	n = small(n)                 // ERROR "inlining call to small"
+	n = big(n)                   // ERROR "not inlining call to big: marked go:noinline" "call to .*big"
+	n = fp(n)                    // ERROR "not inlining call to fp: callee is not statically known" "indirect call"
+	return append(xs, n)         // ERROR "runtime call to runtime.growslice"
+}
+
+func g(x int) int {
+	return big(x) // no messages outside f
+}
@@ -303,3 +303,37 @@ func f28() []interface{} {
 		&g28[5],    // no write barrier
 	}
 }
+
+type T29 struct {
+	next *T29
+	p    *int
+	a, b int
+}
+
+var g29 int
+
+func f29() *T29 {
+	n := new(T29)
+	n.next = n // no write barrier: zeroed memory, pointer to itself
+	return n
+}
+
+func f30() (*T29, *T29) {
+	m := new(T29)
+	n := new(T29)
+	m.a = 1    // no write barrier
+	n.p = &g29 // no write barrier: store to m can't touch n
+	return m, n
+}
+
+var sink31 [8]int
+
+func f31(x [8]int) *T29 {
+	n := new(T29)
+	sink31 = x // no write barrier
+	n.p = &g29 // no write barrier: store to a global can't touch n
+	n.next = n // no write barrier
+	m := new(T29)
+	m.next = n // ERROR "write barrier"
+	return m
+}
@@ -0,0 +1,76 @@
+// errorcheck -0 -m -l
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that []byte/string conversions that can't be observed
+// don't copy.
+
+package p
+
+func f1(n int) string {
+	b := make([]byte, n) // ERROR "make\(\[\]byte, n\) escapes to heap"
+	for i := range b {
+		b[i] = 'a'
+	}
+	return string(b) // ERROR "string\(b\) does not copy: b is not used afterwards"
+}
+
+func f2(xs []string) string { // ERROR "xs does not escape"
+	var b []byte
+	for _, x := range xs {
+		b = append(b, x...)
+	}
+	return string(b) // ERROR "string\(b\) does not copy: b is not used afterwards"
+}
+
+func f3(n int) (string, int) {
+	b := make([]byte, n) // ERROR "make\(\[\]byte, n\) escapes to heap"
+	s := string(b)       // ERROR "string\(b\) escapes to heap"
+	b[0] = 'x'           // b is modified after the conversion
+	return s, len(b)
+}
+
+var sink []byte
+
+func f4(n int) string {
+	b := make([]byte, n) // ERROR "make\(\[\]byte, n\) escapes to heap"
+	sink = b             // b's backing array is shared
+	return string(b)     // ERROR "string\(b\) escapes to heap"
+}
+
+func f5(s string) int { // ERROR "s does not escape"
+	b := []byte(s) // ERROR "\(\[\]byte\)\(s\) does not copy: b is never modified"
+	n := 0
+	for _, c := range b {
+		if c == 'a' {
+			n++
+		}
+	}
+	return n + len(b)
+}
+
+func f6(s string) byte { // ERROR "s does not escape"
+	b := []byte(s) // ERROR "\(\[\]byte\)\(s\) does not escape"
+	b[0]++
+	return b[0]
+}
+
+func f7(s string) *byte { // ERROR "s does not escape"
+	b := []byte(s) // ERROR "\(\[\]byte\)\(s\) escapes to heap"
+	return &b[0]
+}
+
+func f8(n int) func() string {
+	b := make([]byte, n)   // ERROR "make\(\[\]byte, n\) escapes to heap"
+	return func() string { // ERROR "func literal escapes to heap"
+		return string(b) // ERROR "string\(b\) escapes to heap"
+	}
+}
+
+func f9() string {
+	b := make([]byte, 0, 64) // ERROR "make\(\[\]byte, 0, 64\) does not escape"
+	b = append(b, "abc"...)
+	return string(b) // ERROR "string\(b\) escapes to heap"
+}
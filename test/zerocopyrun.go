@@ -0,0 +1,71 @@
+// run
+
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Test that zero-copy []byte/string conversions
+// produce the same results as copying ones.
+
+package main
+
+//go:noinline
+func fill(n int, c byte) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = c
+	}
+	return string(b)
+}
+
+//go:noinline
+func join(xs []string) string {
+	var b []byte
+	for _, x := range xs {
+		b = append(b, x...)
+	}
+	return string(b)
+}
+
+//go:noinline
+func modifiedAfter(n int) (string, byte) {
+	b := make([]byte, n)
+	s := string(b)
+	b[0] = 'x'
+	return s, b[0]
+}
+
+//go:noinline
+func count(s string, c byte) int {
+	b := []byte(s)
+	n := 0
+	for _, x := range b {
+		if x == c {
+			n++
+		}
+	}
+	return n
+}
+
+func main() {
+	s1 := fill(3, 'a')
+	s2 := fill(3, 'b')
+	if s1 != "aaa" || s2 != "bbb" {
+		panic("fill: " + s1 + " " + s2)
+	}
+	if s := join([]string{"ab", "", "cd"}); s != "abcd" {
+		panic("join: " + s)
+	}
+	if s := join(nil); s != "" {
+		panic("join(nil): " + s)
+	}
+	if s, c := modifiedAfter(2); s != "\x00\x00" || c != 'x' {
+		panic("modifiedAfter: " + s)
+	}
+	if n := count("banana", 'a'); n != 3 {
+		panic("count")
+	}
+	if n := count("", 'a'); n != 0 {
+		panic("count empty")
+	}
+}